@@ -0,0 +1,83 @@
+DROP INDEX user_types_tenant_id_name_idx;
+DROP INDEX users_tenant_id_username_idx;
+DROP INDEX users_tenant_id_email_idx;
+DROP INDEX audit_events_entity_idx;
+
+-- SQLite can't drop columns, so the tables are created again without them
+
+CREATE TABLE users_without_tenant (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(50) NOT NULL,
+    user_type_id INTEGER NOT NULL,
+    disabled TINYINT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    email VARCHAR(254) NOT NULL DEFAULT '',
+    display_name VARCHAR(100) NOT NULL DEFAULT '',
+    locale VARCHAR(10) NOT NULL DEFAULT '',
+    timezone VARCHAR(50) NOT NULL DEFAULT '',
+    email_verified_at DATETIME NULL,
+    password_hash VARCHAR(255) NOT NULL DEFAULT '',
+    password_changed_at DATETIME NULL,
+    failed_login_attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until DATETIME NULL,
+    totp_secret VARCHAR(64) NOT NULL DEFAULT '',
+    totp_enabled_at DATETIME NULL,
+    totp_last_used_step INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO users_without_tenant (
+    id, username, user_type_id, disabled, created_at, updated_at, email, display_name, locale, timezone, email_verified_at,
+    password_hash, password_changed_at, failed_login_attempts, locked_until, totp_secret, totp_enabled_at, totp_last_used_step
+)
+SELECT id, username, user_type_id, disabled, created_at, updated_at, email, display_name, locale, timezone, email_verified_at,
+    password_hash, password_changed_at, failed_login_attempts, locked_until, totp_secret, totp_enabled_at, totp_last_used_step
+FROM users;
+
+DROP TABLE users;
+
+ALTER TABLE users_without_tenant RENAME TO users;
+
+CREATE UNIQUE INDEX users_email_idx ON users (email) WHERE email != '';
+
+CREATE TABLE user_types_without_tenant (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(50) NOT NULL,
+    disabled TINYINT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    requires_mfa TINYINT NOT NULL DEFAULT 0
+);
+
+INSERT INTO user_types_without_tenant (id, name, disabled, created_at, updated_at, requires_mfa)
+SELECT id, name, disabled, created_at, updated_at, requires_mfa
+FROM user_types;
+
+DROP TABLE user_types;
+
+ALTER TABLE user_types_without_tenant RENAME TO user_types;
+
+CREATE TABLE audit_events_without_tenant (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    actor VARCHAR(100) NOT NULL,
+    request_id VARCHAR(100) NOT NULL DEFAULT '',
+    entity_type VARCHAR(50) NOT NULL,
+    entity_key VARCHAR(100) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    changes TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+INSERT INTO audit_events_without_tenant (id, actor, request_id, entity_type, entity_key, action, changes, created_at)
+SELECT id, actor, request_id, entity_type, entity_key, action, changes, created_at
+FROM audit_events;
+
+DROP TABLE audit_events;
+
+ALTER TABLE audit_events_without_tenant RENAME TO audit_events;
+
+CREATE INDEX audit_events_entity_idx ON audit_events (entity_type, entity_key);
+CREATE INDEX audit_events_actor_idx ON audit_events (actor);
+CREATE INDEX audit_events_created_at_idx ON audit_events (created_at);
+
+DROP TABLE tenants;
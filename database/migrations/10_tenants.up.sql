@@ -0,0 +1,30 @@
+-- Tenants. Users, user types and audit events belong to one. Existing rows are moved to the default tenant
+
+CREATE TABLE tenants (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(63) NOT NULL,
+    disabled TINYINT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE UNIQUE INDEX tenants_name_idx ON tenants (name);
+
+INSERT INTO tenants (id, name, disabled, created_at, updated_at) VALUES (1, 'default', 0, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+ALTER TABLE user_types ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE users ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE audit_events ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+
+-- Names, usernames and emails are unique per tenant
+
+CREATE UNIQUE INDEX user_types_tenant_id_name_idx ON user_types (tenant_id, name);
+CREATE UNIQUE INDEX users_tenant_id_username_idx ON users (tenant_id, username);
+
+DROP INDEX users_email_idx;
+
+CREATE UNIQUE INDEX users_tenant_id_email_idx ON users (tenant_id, email) WHERE email != '';
+
+DROP INDEX audit_events_entity_idx;
+
+CREATE INDEX audit_events_entity_idx ON audit_events (tenant_id, entity_type, entity_key);
@@ -0,0 +1,28 @@
+DROP INDEX audit_events_entity_id_idx;
+DROP INDEX audit_events_entity_idx;
+
+-- SQLite can't drop columns, so the table is created again without it
+
+CREATE TABLE audit_events_without_entity_id (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    actor VARCHAR(100) NOT NULL,
+    request_id VARCHAR(100) NOT NULL DEFAULT '',
+    entity_type VARCHAR(50) NOT NULL,
+    entity_key VARCHAR(100) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    changes TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    tenant_id INTEGER NOT NULL DEFAULT 1
+);
+
+INSERT INTO audit_events_without_entity_id (id, actor, request_id, entity_type, entity_key, action, changes, created_at, tenant_id)
+SELECT id, actor, request_id, entity_type, entity_key, action, changes, created_at, tenant_id
+FROM audit_events;
+
+DROP TABLE audit_events;
+
+ALTER TABLE audit_events_without_entity_id RENAME TO audit_events;
+
+CREATE INDEX audit_events_entity_idx ON audit_events (tenant_id, entity_type, entity_key);
+CREATE INDEX audit_events_actor_idx ON audit_events (actor);
+CREATE INDEX audit_events_created_at_idx ON audit_events (created_at);
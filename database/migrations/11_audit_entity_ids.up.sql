@@ -0,0 +1,21 @@
+-- Audit events reference the ID of their entity, so renames don't split its history and entities created again with
+-- the same key don't inherit the history of the deleted ones. Existing events are linked to the current entity with
+-- their key, if there's one
+
+ALTER TABLE audit_events ADD COLUMN entity_id INTEGER NOT NULL DEFAULT 0;
+
+UPDATE audit_events
+SET entity_id = COALESCE(
+    (SELECT u.id FROM users u WHERE u.tenant_id = audit_events.tenant_id AND u.username = audit_events.entity_key),
+    0
+)
+WHERE entity_type = 'user';
+
+UPDATE audit_events
+SET entity_id = COALESCE(
+    (SELECT ut.id FROM user_types ut WHERE ut.tenant_id = audit_events.tenant_id AND ut.name = audit_events.entity_key),
+    0
+)
+WHERE entity_type = 'user_type';
+
+CREATE INDEX audit_events_entity_id_idx ON audit_events (tenant_id, entity_type, entity_id);
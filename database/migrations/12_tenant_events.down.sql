@@ -0,0 +1,46 @@
+DROP INDEX webhook_subscriptions_tenant_id_idx;
+
+-- SQLite can't drop columns, so the tables are created again without them
+
+CREATE TABLE outbox_events_without_tenant (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL,
+    payload TEXT NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    request_id VARCHAR(100) NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at DATETIME NOT NULL,
+    delivered_at DATETIME NULL,
+    created_at DATETIME NOT NULL
+);
+
+INSERT INTO outbox_events_without_tenant (
+    id, name, payload, actor, request_id, attempts, last_error, next_attempt_at, delivered_at, created_at
+)
+SELECT id, name, payload, actor, request_id, attempts, last_error, next_attempt_at, delivered_at, created_at
+FROM outbox_events;
+
+DROP TABLE outbox_events;
+
+ALTER TABLE outbox_events_without_tenant RENAME TO outbox_events;
+
+CREATE INDEX outbox_events_pending_idx ON outbox_events (delivered_at, next_attempt_at);
+
+CREATE TABLE webhook_subscriptions_without_tenant (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    url VARCHAR(500) NOT NULL,
+    event_types TEXT NOT NULL,
+    secret VARCHAR(100) NOT NULL,
+    disabled TINYINT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+INSERT INTO webhook_subscriptions_without_tenant (id, url, event_types, secret, disabled, created_at, updated_at)
+SELECT id, url, event_types, secret, disabled, created_at, updated_at
+FROM webhook_subscriptions;
+
+DROP TABLE webhook_subscriptions;
+
+ALTER TABLE webhook_subscriptions_without_tenant RENAME TO webhook_subscriptions;
@@ -0,0 +1,7 @@
+-- Events and webhook subscriptions belong to a tenant, so events are only streamed and delivered to the tenant which
+-- generated them. Existing rows are moved to the default tenant
+
+ALTER TABLE outbox_events ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE webhook_subscriptions ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1;
+
+CREATE INDEX webhook_subscriptions_tenant_id_idx ON webhook_subscriptions (tenant_id);
@@ -0,0 +1,16 @@
+-- Audit Events
+
+CREATE TABLE audit_events (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    actor VARCHAR(100) NOT NULL,
+    request_id VARCHAR(100) NOT NULL DEFAULT '',
+    entity_type VARCHAR(50) NOT NULL,
+    entity_key VARCHAR(100) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    changes TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX audit_events_entity_idx ON audit_events (entity_type, entity_key);
+CREATE INDEX audit_events_actor_idx ON audit_events (actor);
+CREATE INDEX audit_events_created_at_idx ON audit_events (created_at);
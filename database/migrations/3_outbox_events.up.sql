@@ -0,0 +1,16 @@
+-- Outbox Events
+
+CREATE TABLE outbox_events (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL,
+    payload TEXT NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    request_id VARCHAR(100) NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at DATETIME NOT NULL,
+    delivered_at DATETIME NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX outbox_events_pending_idx ON outbox_events (delivered_at, next_attempt_at);
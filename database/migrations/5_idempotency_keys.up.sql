@@ -0,0 +1,18 @@
+-- Idempotency Keys
+
+CREATE TABLE idempotency_keys (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    idempotency_key VARCHAR(255) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    caller VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    response_status INTEGER NOT NULL DEFAULT 0,
+    response_content_type VARCHAR(255) NOT NULL DEFAULT '',
+    response_body BLOB,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    UNIQUE (idempotency_key, route, caller)
+);
+
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
@@ -0,0 +1,19 @@
+DROP INDEX users_email_idx;
+
+-- SQLite can't drop columns, so the table is created again without them
+
+CREATE TABLE users_without_profiles (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(50) NOT NULL,
+    user_type_id INTEGER NOT NULL,
+    disabled TINYINT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+INSERT INTO users_without_profiles (id, username, user_type_id, disabled, created_at, updated_at)
+SELECT id, username, user_type_id, disabled, created_at, updated_at FROM users;
+
+DROP TABLE users;
+
+ALTER TABLE users_without_profiles RENAME TO users;
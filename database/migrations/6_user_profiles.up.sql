@@ -0,0 +1,9 @@
+-- User Profiles
+
+ALTER TABLE users ADD COLUMN email VARCHAR(254) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN display_name VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN locale VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN timezone VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN email_verified_at DATETIME NULL;
+
+CREATE UNIQUE INDEX users_email_idx ON users (email) WHERE email != '';
@@ -0,0 +1,31 @@
+DROP TABLE password_reset_tokens;
+
+DROP INDEX users_email_idx;
+
+-- SQLite can't drop columns, so the table is created again without them
+
+CREATE TABLE users_without_credentials (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(50) NOT NULL,
+    user_type_id INTEGER NOT NULL,
+    disabled TINYINT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    email VARCHAR(254) NOT NULL DEFAULT '',
+    display_name VARCHAR(100) NOT NULL DEFAULT '',
+    locale VARCHAR(10) NOT NULL DEFAULT '',
+    timezone VARCHAR(50) NOT NULL DEFAULT '',
+    email_verified_at DATETIME NULL
+);
+
+INSERT INTO users_without_credentials (
+    id, username, user_type_id, disabled, created_at, updated_at, email, display_name, locale, timezone, email_verified_at
+)
+SELECT id, username, user_type_id, disabled, created_at, updated_at, email, display_name, locale, timezone, email_verified_at
+FROM users;
+
+DROP TABLE users;
+
+ALTER TABLE users_without_credentials RENAME TO users;
+
+CREATE UNIQUE INDEX users_email_idx ON users (email) WHERE email != '';
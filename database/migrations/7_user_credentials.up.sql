@@ -0,0 +1,20 @@
+-- User Credentials
+
+ALTER TABLE users ADD COLUMN password_hash VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN password_changed_at DATETIME NULL;
+ALTER TABLE users ADD COLUMN failed_login_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN locked_until DATETIME NULL;
+
+-- Password Reset Tokens. Only the hash of the tokens is stored
+
+CREATE TABLE password_reset_tokens (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE UNIQUE INDEX password_reset_tokens_token_hash_idx ON password_reset_tokens (token_hash);
+CREATE INDEX password_reset_tokens_user_id_idx ON password_reset_tokens (user_id);
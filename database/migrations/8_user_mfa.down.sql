@@ -0,0 +1,53 @@
+DROP TABLE recovery_codes;
+
+DROP INDEX users_email_idx;
+
+-- SQLite can't drop columns, so the tables are created again without them
+
+CREATE TABLE users_without_mfa (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(50) NOT NULL,
+    user_type_id INTEGER NOT NULL,
+    disabled TINYINT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    email VARCHAR(254) NOT NULL DEFAULT '',
+    display_name VARCHAR(100) NOT NULL DEFAULT '',
+    locale VARCHAR(10) NOT NULL DEFAULT '',
+    timezone VARCHAR(50) NOT NULL DEFAULT '',
+    email_verified_at DATETIME NULL,
+    password_hash VARCHAR(255) NOT NULL DEFAULT '',
+    password_changed_at DATETIME NULL,
+    failed_login_attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until DATETIME NULL
+);
+
+INSERT INTO users_without_mfa (
+    id, username, user_type_id, disabled, created_at, updated_at, email, display_name, locale, timezone, email_verified_at,
+    password_hash, password_changed_at, failed_login_attempts, locked_until
+)
+SELECT id, username, user_type_id, disabled, created_at, updated_at, email, display_name, locale, timezone, email_verified_at,
+    password_hash, password_changed_at, failed_login_attempts, locked_until
+FROM users;
+
+DROP TABLE users;
+
+ALTER TABLE users_without_mfa RENAME TO users;
+
+CREATE UNIQUE INDEX users_email_idx ON users (email) WHERE email != '';
+
+CREATE TABLE user_types_without_mfa (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(50) NOT NULL,
+    disabled TINYINT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+INSERT INTO user_types_without_mfa (id, name, disabled, created_at, updated_at)
+SELECT id, name, disabled, created_at, updated_at
+FROM user_types;
+
+DROP TABLE user_types;
+
+ALTER TABLE user_types_without_mfa RENAME TO user_types;
@@ -0,0 +1,19 @@
+-- Multi-factor authentication. User types can require their users to use a second factor
+
+ALTER TABLE user_types ADD COLUMN requires_mfa TINYINT NOT NULL DEFAULT 0;
+
+ALTER TABLE users ADD COLUMN totp_secret VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN totp_enabled_at DATETIME NULL;
+ALTER TABLE users ADD COLUMN totp_last_used_step INTEGER NOT NULL DEFAULT 0;
+
+-- Recovery Codes. Only the hash of the codes is stored
+
+CREATE TABLE recovery_codes (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at DATETIME NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX recovery_codes_user_id_idx ON recovery_codes (user_id);
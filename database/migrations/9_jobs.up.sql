@@ -0,0 +1,28 @@
+-- Jobs
+
+CREATE TABLE jobs (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL,
+    payload TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    run_at DATETIME NOT NULL,
+    locked_by VARCHAR(100) NOT NULL DEFAULT '',
+    locked_until DATETIME NULL,
+    finished_at DATETIME NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX jobs_status_run_at_idx ON jobs (status, run_at);
+CREATE INDEX jobs_finished_at_idx ON jobs (finished_at);
+
+-- Job Locks
+
+CREATE TABLE job_locks (
+    name VARCHAR(100) NOT NULL PRIMARY KEY,
+    owner VARCHAR(100) NOT NULL,
+    expires_at DATETIME NOT NULL
+);
@@ -4,29 +4,45 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"net"
 	"net/http"
+	"net/http/pprof"
 	"os"
-	"os/signal"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	_ "github.com/comfortablynumb/goginrestapi/docs"
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/cache"
 	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
 	context2 "github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/controller"
 	"github.com/comfortablynumb/goginrestapi/internal/errorhandler"
+	"github.com/comfortablynumb/goginrestapi/internal/errorreport"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
 	hooks2 "github.com/comfortablynumb/goginrestapi/internal/hooks"
+	"github.com/comfortablynumb/goginrestapi/internal/i18n"
+	"github.com/comfortablynumb/goginrestapi/internal/jobs"
+	"github.com/comfortablynumb/goginrestapi/internal/mailer"
+	"github.com/comfortablynumb/goginrestapi/internal/metrics"
 	"github.com/comfortablynumb/goginrestapi/internal/middleware"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
 	"github.com/comfortablynumb/goginrestapi/internal/module"
+	"github.com/comfortablynumb/goginrestapi/internal/ratelimit"
+	"github.com/comfortablynumb/goginrestapi/internal/redis"
+	"github.com/comfortablynumb/goginrestapi/internal/repository"
+	server2 "github.com/comfortablynumb/goginrestapi/internal/server"
 	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/comfortablynumb/goginrestapi/internal/token"
+	"github.com/comfortablynumb/goginrestapi/internal/validation"
+	"github.com/comfortablynumb/goginrestapi/internal/worker"
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/locales/en"
-	"github.com/go-playground/locales/es"
-	ut "github.com/go-playground/universal-translator"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/sqlite3"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
-	"github.com/kelseyhightower/envconfig"
 	"github.com/rs/zerolog"
 	_ "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -41,81 +57,364 @@ import (
 // Constants
 
 const (
-	DbDriverName = "sqlite3"
+	DbDriverName        = "sqlite3"
+	DbTimeLocationParam = "_loc"
+
+	ConfigReloadWorkerName    = "ConfigReloadWorker"
+	ConfigReloadsMetricName   = "config_reloads_total"
+	ConfigReloadSuccessResult = "success"
+	ConfigReloadFailureResult = "failure"
+
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+
+	JobsPurgeJobName = "jobs.purge_finished"
 )
 
 // Interfaces
 
 type App interface {
+	GetConfig() *config.AppConfig
 	GetRouter() *gin.Engine
-	SetUp()
-	Run() error
-	ExecuteDbMigrationsUp()
-	ExecuteDbMigrationsDown()
+	GetAdminRouter() *gin.Engine
+	GetComponentRegistry() *componentregistry.ComponentRegistry
+	SetUp() error
+	Run(ctx context.Context) error
+	Close() error
+	ReloadConfig() error
+	ExecuteDbMigrationsUp() error
+	ExecuteDbMigrationsDown() error
 }
 
 // Structs
 
 type app struct {
 	config            *config.AppConfig
+	runtimeConfig     *config.Runtime
+	rateLimiters      atomic.Value
 	componentRegistry *componentregistry.ComponentRegistry
 	hooks             *hooks2.Hooks
+	timeService       service.TimeService
 	errorHandler      *errorhandler.ErrorHandler
 	router            *gin.Engine
+	adminRouter       *gin.Engine
 	logger            *zerolog.Logger
-	translator        *ut.UniversalTranslator
+	translator        *i18n.Translator
 	moduleManager     *module.ModuleManager
+	modules           []module.Module
+	startedModules    []module.Module
+	isSetUp           bool
+	isRunning         bool
+	isClosed          bool
+}
+
+// GetConfig Returns the current config, which includes the changes applied by the last reload.
+func (a *app) GetConfig() *config.AppConfig {
+	if a.runtimeConfig != nil {
+		return a.runtimeConfig.Get()
+	}
+
+	return a.config
 }
 
 func (a *app) GetRouter() *gin.Engine {
 	return a.router
 }
 
-func (a *app) Run() error {
-	a.SetUp()
+// GetAdminRouter Returns the router of the admin listener. If the admin listener is disabled, the router is nil and
+// the admin routes (except pprof) are served by the main router.
+func (a *app) GetAdminRouter() *gin.Engine {
+	return a.adminRouter
+}
+
+func (a *app) GetComponentRegistry() *componentregistry.ComponentRegistry {
+	return a.componentRegistry
+}
 
-	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", a.config.Port),
-		Handler: a.router,
+// Run Sets up the app (unless it's already set up), starts the modules, the background workers and the servers, and
+// serves requests until the context is cancelled or a server fails. Then, the servers are shut down gracefully and the
+// modules and workers are stopped. Resources (like the DB) are kept open until the app is closed.
+func (a *app) Run(ctx context.Context) error {
+	if !a.isSetUp {
+		if err := a.SetUp(); err != nil {
+			return err
+		}
 	}
 
-	go func() {
-		// service connections
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			a.errorHandler.HandleFatal(err, "There was an error while starting listening for incoming requests on the web server.")
+	serverConfig := a.config.Server
+	servers, err := a.createServers(serverConfig)
+
+	if err != nil {
+		return err
+	}
+
+	if err := a.start(ctx); err != nil {
+		for _, srv := range servers {
+			_ = srv.listener.Close()
 		}
-	}()
 
-	quit := make(chan os.Signal, 1)
+		return err
+	}
+
+	serveErrors := make(chan error, len(servers))
+
+	for _, srv := range servers {
+		go func(srv *httpServer) {
+			a.logger.Info().Msgf("[app] Listening for %s on '%s' (TLS: %t).", srv.name, srv.listener.Addr(), srv.server.TLSConfig != nil)
 
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+			if err := srv.serve(); err != nil && err != http.ErrServerClosed {
+				serveErrors <- fmt.Errorf("could NOT serve %s: %s", srv.name, err)
+			}
+		}(srv)
+	}
+
+	var errs []error
+
+	select {
+	case <-ctx.Done():
+		a.logger.Debug().Msg("[app] Context cancelled.")
+	case err := <-serveErrors:
+		a.logger.Error().Msgf("[app] %s", err)
 
-	a.logger.Debug().Msg("[app] Shutdown Server. Waiting a maximum of 15 seconds to finish pending work...")
+		errs = append(errs, err)
+	}
+
+	a.logger.Debug().Msgf("[app] Shutdown Server. Waiting a maximum of %s to finish pending work...", serverConfig.ShutdownTimeout)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serverConfig.ShutdownTimeout)
 	defer cancel()
 
-	if err := server.Shutdown(ctx); err != nil {
-		a.errorHandler.HandleFatal(err, "There was an error while shutting down the web server.")
+	for _, srv := range servers {
+		if err := srv.server.Shutdown(shutdownCtx); err != nil {
+			errs = append(errs, fmt.Errorf("could NOT shut down %s: %s", srv.name, err))
+		}
+	}
+
+	if err := a.stop(shutdownCtx); err != nil {
+		errs = append(errs, err)
 	}
 
 	a.logger.Debug().Msg("[app] Server exiting.")
 
+	return newLifecycleError("run", errs)
+}
+
+// Close Stops the app (if it's running) and releases its resources: the database migrations, the DB and the Redis
+// client. It can be called more than once, and after SetUp failed.
+func (a *app) Close() error {
+	var errs []error
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.config.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := a.stop(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	if a.componentRegistry != nil && !a.isClosed {
+		a.logger.Debug().Msg("[app] Closing database migrations, the DB and the Redis client.")
+
+		if a.componentRegistry.Migrations != nil {
+			sourceErr, dbErr := a.componentRegistry.Migrations.Close()
+
+			for _, err := range []error{sourceErr, dbErr} {
+				if err != nil {
+					errs = append(errs, fmt.Errorf("could NOT close the database migrations: %s", err))
+				}
+			}
+		}
+
+		if a.componentRegistry.Db != nil {
+			if err := a.componentRegistry.Db.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("could NOT close the DB: %s", err))
+			}
+		}
+
+		if a.componentRegistry.Redis != nil {
+			if err := a.componentRegistry.Redis.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("could NOT close the Redis client: %s", err))
+			}
+		}
+
+		a.isClosed = true
+	}
+
+	return newLifecycleError("close", errs)
+}
+
+// start Starts the background workers and then the modules, in order. If a module fails to start, the ones already
+// started are stopped.
+func (a *app) start(ctx context.Context) error {
+	a.logger.Debug().Msg("[app] Starting background workers.")
+
+	for _, w := range a.componentRegistry.Workers {
+		w.Start()
+	}
+
+	a.isRunning = true
+
+	for _, m := range a.moduleManager.GetModules() {
+		a.logger.Debug().Msgf("[app] Starting module '%s'...", m.GetName())
+
+		if err := m.OnStart(ctx, a.componentRegistry); err != nil {
+			err = fmt.Errorf("could NOT start module '%s': %s", m.GetName(), err)
+
+			if stopErr := a.stop(ctx); stopErr != nil {
+				a.logger.Error().Msgf("[app] %s", stopErr)
+			}
+
+			return err
+		}
+
+		a.startedModules = append(a.startedModules, m)
+	}
+
 	return nil
 }
 
-func (a *app) SetUp() {
+// stop Stops the started modules and then the background workers, in reverse order. Every module is stopped, even if
+// others fail.
+func (a *app) stop(ctx context.Context) error {
+	if !a.isRunning {
+		return nil
+	}
+
+	var errs []error
+
+	for i := len(a.startedModules) - 1; i >= 0; i-- {
+		m := a.startedModules[i]
+
+		a.logger.Debug().Msgf("[app] Stopping module '%s'...", m.GetName())
+
+		if err := m.OnStop(ctx, a.componentRegistry); err != nil {
+			errs = append(errs, fmt.Errorf("could NOT stop module '%s': %s", m.GetName(), err))
+		}
+	}
+
+	a.logger.Debug().Msg("[app] Stopping background workers.")
+
+	for i := len(a.componentRegistry.Workers) - 1; i >= 0; i-- {
+		a.componentRegistry.Workers[i].Stop()
+	}
+
+	a.startedModules = nil
+	a.isRunning = false
+
+	return newLifecycleError("stop", errs)
+}
+
+// createServers Creates the servers and their listeners, so errors like ports already in use are returned before
+// anything starts.
+func (a *app) createServers(serverConfig config.ServerConfig) ([]*httpServer, error) {
+	server, err := server2.NewHttpServer(serverConfig, a.router)
+
+	if err != nil {
+		return nil, fmt.Errorf("could NOT create the web server: %s", err)
+	}
+
+	for _, hook := range a.componentRegistry.ShutdownHooks {
+		server.RegisterOnShutdown(hook)
+	}
+
+	servers := []*httpServer{{name: "incoming requests", server: server}}
+
+	if serverConfig.Admin.IsEnabled() {
+		servers = append(servers, &httpServer{name: "admin requests", server: server2.NewAdminHttpServer(serverConfig, a.adminRouter)})
+	}
+
+	for i, srv := range servers {
+		listener, err := net.Listen("tcp", srv.server.Addr)
+
+		if err != nil {
+			for _, started := range servers[:i] {
+				_ = started.listener.Close()
+			}
+
+			return nil, fmt.Errorf("could NOT listen for %s on '%s': %s", srv.name, srv.server.Addr, err)
+		}
+
+		srv.listener = listener
+	}
+
+	return servers, nil
+}
+
+// SetUp Creates the components of the app and its modules, and executes the database migrations (if enabled). Panics
+// (for example, raised by the hooks) are returned as errors too. On error, the resources created so far are
+// released.
+func (a *app) SetUp() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+
+		if err != nil {
+			err = fmt.Errorf("could NOT set up the app: %s", err)
+
+			if a.logger != nil {
+				a.logger.Error().Msgf("[app] %s", err)
+			}
+
+			_ = a.Close()
+		}
+	}()
+
+	a.runtimeConfig = config.NewRuntime(a.config)
 	a.logger = a.createLogger()
-	a.translator = a.createTranslator()
+	a.translator, err = a.createTranslator()
+
+	if err != nil {
+		return err
+	}
+
 	a.errorHandler = a.createErrorHandler()
-	a.moduleManager = a.createModuleManager()
-	a.componentRegistry = a.createComponentRegistry()
-	a.router = a.createRouter()
+	a.moduleManager, err = a.createModuleManager()
+
+	if err != nil {
+		return err
+	}
 
-	a.setUpValidator(a.componentRegistry.Validator)
+	if err := a.loadModuleConfigs(); err != nil {
+		return err
+	}
+
+	if err := a.createComponentRegistry(); err != nil {
+		return err
+	}
 
-	a.ExecuteDbMigrationsUp()
+	a.router, err = a.createRouter()
+
+	if err != nil {
+		return err
+	}
+
+	a.adminRouter = a.createAdminRouter()
+
+	if err := a.setUpValidator(a.componentRegistry.Validator); err != nil {
+		return err
+	}
+
+	if err := a.setUpEventSubscribers(a.componentRegistry.EventBus); err != nil {
+		return err
+	}
+
+	if err := a.setUpJobs(a.componentRegistry.JobScheduler); err != nil {
+		return err
+	}
+
+	a.setUpConfigReload()
+
+	if a.config.Db.AutoMigrate {
+		if err := a.ExecuteDbMigrationsUp(); err != nil {
+			return err
+		}
+	} else {
+		a.logger.Debug().Msg("[app] Database auto-migration is disabled. Skipping database migrations.")
+	}
+
+	a.isSetUp = true
+
+	return nil
 }
 
 func (a *app) createLogger() *zerolog.Logger {
@@ -134,114 +433,510 @@ func (a *app) createLogger() *zerolog.Logger {
 		return strings.ToUpper(fmt.Sprintf("%s", i))
 	}
 
+	a.applyLogLevel(a.config)
+
 	logger := zerolog.New(output).With().Timestamp().Logger()
 
 	return a.hooks.SetupLogger(&logger)
 }
 
-func (a *app) createDb() *sql.DB {
+// applyLogLevel The level is set globally, so it can be changed while the app runs.
+func (a *app) applyLogLevel(appConfig *config.AppConfig) {
+	level, err := zerolog.ParseLevel(strings.ToLower(appConfig.Log.Level))
+
+	if err != nil || appConfig.Log.Level == "" {
+		level = zerolog.DebugLevel
+	}
+
+	zerolog.SetGlobalLevel(level)
+}
+
+// ReloadConfig Loads the config again and applies the settings which can be changed while the app runs. If the new
+// config is not valid, the current one is kept.
+func (a *app) ReloadConfig() error {
+	reloads := a.componentRegistry.Metrics.Counter(ConfigReloadsMetricName, "Config reloads, by result.", "result")
+	res, err := a.runtimeConfig.Reload()
+
+	if err != nil {
+		reloads.Inc(ConfigReloadFailureResult)
+
+		a.logger.Error().Msgf("[app] Could NOT reload the config. Keeping the current one. Error: %s", err)
+
+		return err
+	}
+
+	reloads.Inc(ConfigReloadSuccessResult)
+
+	for _, key := range res.Ignored {
+		a.logger.Warn().Msgf("[app] Config setting '%s' changed, but it can't be reloaded. Restart the app to apply it.", key)
+	}
+
+	if len(res.Applied) < 1 {
+		a.logger.Info().Msg("[app] Config reloaded. No reloadable setting changed.")
+	} else {
+		a.logger.Info().Msgf("[app] Config reloaded. Applied settings: %s.", strings.Join(res.Applied, ", "))
+	}
+
+	return nil
+}
+
+// setUpConfigReload The config is reloaded when its files change, or when the process receives SIGHUP.
+func (a *app) setUpConfigReload() {
+	a.runtimeConfig.OnReload(a.applyLogLevel)
+	a.runtimeConfig.OnReload(func(appConfig *config.AppConfig) {
+		rateLimiters, err := a.createRateLimiters(a.componentRegistry, appConfig.RateLimits)
+
+		if err != nil {
+			a.logger.Error().Msgf("[app] Could NOT apply the reloaded rate limits: %s", err)
+
+			return
+		}
+
+		a.rateLimiters.Store(rateLimiters)
+	})
+
+	a.componentRegistry.AddWorker(worker.NewFileWatcherWorker(
+		ConfigReloadWorkerName,
+		a.config.GetFiles(),
+		[]os.Signal{syscall.SIGHUP},
+		func() error {
+			// Errors are already logged
+
+			_ = a.ReloadConfig()
+
+			return nil
+		},
+		a.logger,
+	))
+}
+
+func (a *app) createDb() (*sql.DB, error) {
 	a.logger.Debug().Msgf("[app] Creating DB instance for driver: %s", DbDriverName)
 
-	db, err := sql.Open(DbDriverName, a.config.DbUri)
+	db, err := sql.Open(DbDriverName, withDbTimeLocation(a.config.Db.Uri))
 
-	a.errorHandler.HandleFatalIfError(err, "Could NOT create a DB instance.")
+	if err != nil {
+		return nil, fmt.Errorf("could NOT create a DB instance: %s", err)
+	}
 
 	a.logger.Debug().Msg("[app] Executing ping on the database.")
 
-	err = db.Ping()
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
 
-	a.errorHandler.HandleFatalIfError(err, "There was an error while trying to ping the database.")
+		return nil, fmt.Errorf("could NOT ping the database: %s", err)
+	}
 
-	return db
+	return db, nil
 }
 
-func (a *app) createModuleManager() *module.ModuleManager {
+// createModuleManager Modules are set up in the order of their dependencies.
+func (a *app) createModuleManager() (*module.ModuleManager, error) {
 	moduleManager := module.NewModuleManager()
 
+	moduleManager.AddModule(&module.AuditModule{})
+	moduleManager.AddModule(&module.CacheModule{})
+	moduleManager.AddModule(&module.IdempotencyModule{})
 	moduleManager.AddModule(&module.UserTypeModule{})
 	moduleManager.AddModule(&module.UserModule{})
+	moduleManager.AddModule(&module.AuthModule{})
+	moduleManager.AddModule(&module.FixturesModule{})
+	moduleManager.AddModule(&module.WebhookModule{})
+	moduleManager.AddModule(&module.StreamModule{})
 
-	return moduleManager
+	for _, m := range a.modules {
+		moduleManager.AddModule(m)
+	}
+
+	if err := moduleManager.Sort(); err != nil {
+		return nil, err
+	}
+
+	return moduleManager, nil
+}
+
+// loadModuleConfigs Loads the config section of every module which declares one. Top level keys which belong to no
+// module are rejected, as they are usually typos.
+func (a *app) loadModuleConfigs() error {
+	for _, m := range a.moduleManager.GetModules() {
+		configurableModule, ok := m.(module.ConfigurableModule)
+
+		if !ok {
+			continue
+		}
+
+		a.logger.Debug().Msgf("[app] Loading config section of module '%s'...", m.GetName())
+
+		if err := a.config.LoadSection(m.GetName(), configurableModule.GetConfig()); err != nil {
+			return err
+		}
+	}
+
+	if unknownSections := a.config.GetUnknownSections(); len(unknownSections) > 0 {
+		return fmt.Errorf("unknown config sections: %s", strings.Join(unknownSections, ", "))
+	}
+
+	return nil
 }
 
-func (a *app) setUpValidator(validator *validator.Validate) {
+// setUpValidator Sets up the validator with the modules. The validation messages (including the ones added by the
+// modules) are registered on it afterwards, and on the validator used by gin for bindings, which reports the names of
+// the fields like the one of the app.
+func (a *app) setUpValidator(validate *validator.Validate) error {
 	for _, m := range a.moduleManager.GetModules() {
 		a.logger.Debug().Msgf("[app] Setting up validator for module '%s'...", m.GetName())
 
-		m.SetUpValidator(a.errorHandler, a.componentRegistry, a.componentRegistry.Validator)
+		if err := m.SetUpValidator(a.errorHandler, a.componentRegistry, validate); err != nil {
+			return fmt.Errorf("could NOT set up the validator of module '%s': %s", m.GetName(), err)
+		}
+	}
+
+	if err := a.translator.RegisterValidator(validate); err != nil {
+		return err
+	}
+
+	if bindingValidate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		bindingValidate.RegisterTagNameFunc(validation.GetFieldName)
+
+		if err := a.translator.RegisterValidator(bindingValidate); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *app) setUpEventSubscribers(bus *events.Bus) error {
+	for _, m := range a.moduleManager.GetModules() {
+		a.logger.Debug().Msgf("[app] Setting up event subscribers for module '%s'...", m.GetName())
+
+		if err := m.SetUpEventSubscribers(a.errorHandler, a.componentRegistry, bus); err != nil {
+			return fmt.Errorf("could NOT set up the event subscribers of module '%s': %s", m.GetName(), err)
+		}
 	}
+
+	return nil
 }
 
-func (a *app) createDbMigrationsInstance(db *sql.DB) *migrate.Migrate {
+// setUpJobs Registers the jobs of the app (like the one purging the finished jobs) and the ones of the modules.
+func (a *app) setUpJobs(scheduler *jobs.Scheduler) error {
+	purgeSchedule, err := jobs.ParseSchedule(a.config.Jobs.PurgeSchedule)
+
+	if err != nil {
+		return fmt.Errorf("invalid jobs.purge_schedule: %s", err)
+	}
+
+	err = scheduler.Register(jobs.Definition{
+		Name:     JobsPurgeJobName,
+		Schedule: purgeSchedule,
+		Handler: func(ctx *context2.RequestContext, job *model.Job) error {
+			before := a.componentRegistry.TimeService.GetCurrentUtcTime().Add(-a.config.Jobs.Retention)
+
+			if _, err := scheduler.DeleteFinishedBefore(ctx, before); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, m := range a.moduleManager.GetModules() {
+		a.logger.Debug().Msgf("[app] Setting up jobs for module '%s'...", m.GetName())
+
+		if err := m.SetUpJobs(a.errorHandler, a.componentRegistry, scheduler); err != nil {
+			return fmt.Errorf("could NOT set up the jobs of module '%s': %s", m.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+func (a *app) createDbMigrationsInstance(db *sql.DB) (*migrate.Migrate, error) {
 	a.logger.Debug().Msg("[app] Creating database migrations driver.")
 
 	driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
 
-	a.errorHandler.HandleFatalIfError(err, "Could NOT create database migrations driver.")
+	if err != nil {
+		return nil, fmt.Errorf("could NOT create the database migrations driver: %s", err)
+	}
 
 	a.logger.Debug().Msg("[app] Creating database migrations instance.")
 
 	databaseMigrations, err := migrate.NewWithDatabaseInstance(
-		a.config.DbMigrationsPath,
+		a.config.Db.MigrationsPath,
 		DbDriverName,
 		driver,
 	)
 
-	a.errorHandler.HandleFatalIfError(err, "Could NOT create database migrations instance.")
+	if err != nil {
+		return nil, fmt.Errorf("could NOT create the database migrations instance: %s", err)
+	}
 
-	return databaseMigrations
+	return databaseMigrations, nil
 }
 
-func (a *app) ExecuteDbMigrationsUp() {
+func (a *app) ExecuteDbMigrationsUp() error {
 	a.logger.Debug().Msg("[app] Executing database migrations UP.")
 
 	err := a.componentRegistry.Migrations.Up()
 
 	if err != nil && err != migrate.ErrNoChange {
-		a.errorHandler.HandleFatalIfError(err, "There was an error while trying to execute the database migrations.")
+		return fmt.Errorf("could NOT execute the database migrations: %s", err)
 	}
 
 	a.logger.Debug().Msg("[app] Database migrations UP executed SUCCESSFULLY!")
+
+	return nil
 }
 
-func (a *app) ExecuteDbMigrationsDown() {
+func (a *app) ExecuteDbMigrationsDown() error {
 	a.logger.Debug().Msg("[app] Executing database migrations DOWN.")
 
 	err := a.componentRegistry.Migrations.Down()
 
 	if err != nil && err != migrate.ErrNoChange {
-		a.errorHandler.HandleFatalIfError(err, "There was an error while trying to execute the database migrations.")
+		return fmt.Errorf("could NOT execute the database migrations: %s", err)
 	}
 
 	a.logger.Debug().Msg("[app] Database migrations DOWN executed SUCCESSFULLY!")
+
+	return nil
 }
 
 func (a *app) createErrorHandler() *errorhandler.ErrorHandler {
-	return errorhandler.NewErrorHandler(a.logger, a.hooks)
+	return errorhandler.NewErrorHandler(a.logger, a.hooks, a.config.Errors)
 }
 
+// createValidator Creates the validator of the app. Errors are reported with the names of the fields seen by the API
+// clients.
 func (a *app) createValidator() *validator.Validate {
-	return a.hooks.SetupValidator(validator.New())
+	validate := validator.New()
+
+	validate.RegisterTagNameFunc(validation.GetFieldName)
+
+	return a.hooks.SetupValidator(validate)
 }
 
-func (a *app) createTranslator() *ut.UniversalTranslator {
-	enLocale := en.New()
-	esLocale := es.New()
+// createTranslator Creates the translator of the supported locales, with the messages of the error codes.
+func (a *app) createTranslator() (*i18n.Translator, error) {
+	translator, err := i18n.NewTranslator(a.config.DefaultLocale)
+
+	if err != nil {
+		return nil, fmt.Errorf("could NOT create the translator: %s", err)
+	}
 
-	return ut.New(enLocale, esLocale)
+	for locale, messages := range apperror.Messages {
+		if err := translator.AddMessages(locale, messages); err != nil {
+			return nil, fmt.Errorf("could NOT add the error messages: %s", err)
+		}
+	}
+
+	return translator, nil
+}
+
+// createErrorReporter Creates the reporter of unexpected errors, with a file sink if it's configured. More sinks can
+// be added by the modules or the hooks.
+func (a *app) createErrorReporter() *errorreport.Reporter {
+	reporter := errorreport.NewReporter(a.logger)
+
+	if a.config.Errors.ReportFilePath != "" {
+		reporter.AddSink(errorreport.NewFileSink(a.config.Errors.ReportFilePath))
+	}
+
+	return reporter
+}
+
+// createMailer The mailers of the app are meant for development and testing. To actually send the emails, set your
+// own mailer from the SetupComponentRegistry hook.
+func (a *app) createMailer() mailer.Mailer {
+	if a.config.Mail.Mailer == config.MailerOutbox {
+		return mailer.NewOutboxMailer(a.config.Mail.OutboxDir)
+	}
+
+	return mailer.NewLogMailer(a.logger)
+}
+
+// createTokenSigner Without a configured secret, tokens are signed with a random one, so they are no longer valid
+// once the app is restarted.
+func (a *app) createTokenSigner() (*token.Signer, error) {
+	if a.config.Auth.TokenSecret != "" {
+		return token.NewSigner([]byte(a.config.Auth.TokenSecret)), nil
+	}
+
+	a.logger.Warn().Msg("[app] auth.token_secret is not set. Using a random one: issued tokens will not be valid after a restart.")
+
+	secret, err := token.NewRandomSecret()
+
+	if err != nil {
+		return nil, fmt.Errorf("could NOT create the token secret: %s", err)
+	}
+
+	return token.NewSigner(secret), nil
 }
 
 func (a *app) createRequestContextFactory() *context2.RequestContextFactory {
-	return context2.NewRequestContextFactory(a.translator)
+	return context2.NewRequestContextFactory(a.translator, a.config.Auth.ActorHeader, a.config.Auth.TrustActorHeader)
 }
 
 func (a *app) createTimeService() service.TimeService {
+	if a.timeService != nil {
+		return a.timeService
+	}
+
 	return service.NewTimeService()
 }
 
-func (a *app) createComponentRegistry() *componentregistry.ComponentRegistry {
+func (a *app) createTransactionService(db *sql.DB) service.TransactionService {
+	return service.NewTransactionService(db, a.logger)
+}
+
+// createRedisClient The client is only created (and Redis checked to be reachable) if a backend is set to "redis".
+func (a *app) createRedisClient() (*redis.Client, error) {
+	if a.config.Cache.Backend != config.RedisBackend && a.config.RateLimitStore != config.RedisBackend {
+		return nil, nil
+	}
+
+	client := redis.NewClient(a.config.Redis)
+
+	if err := client.Ping(); err != nil {
+		_ = client.Close()
+
+		return nil, fmt.Errorf("could NOT connect to Redis on '%s': %s", a.config.Redis.Address, err)
+	}
+
+	return client, nil
+}
+
+// createCacheManager Caches are kept in memory by default, or on Redis (shared between instances) if the cache
+// backend is "redis".
+func (a *app) createCacheManager(timeService service.TimeService, redisClient *redis.Client) *cache.Manager {
+	if a.config.Cache.Backend == config.RedisBackend {
+		return cache.NewManager(func(name string) cache.Cache {
+			return cache.NewRedisCache(name, redisClient, a.logger)
+		})
+	}
+
+	return cache.NewManager(func(name string) cache.Cache {
+		return cache.NewMemoryCache(name, a.config.Cache.MaxEntries, timeService.GetCurrentUtcTime)
+	})
+}
+
+// createRateLimitStore Rate limits are counted in memory by default, or on Redis (shared between instances) if the rate
+// limit store is "redis".
+func (a *app) createRateLimitStore(timeService service.TimeService, redisClient *redis.Client) ratelimit.Store {
+	if a.config.RateLimitStore == config.RedisBackend {
+		return ratelimit.NewRedisStore(redisClient, ratelimit.RedisStorePrefix)
+	}
+
+	return ratelimit.NewMemoryStore(timeService.GetCurrentUtcTime)
+}
+
+// createRateLimiterFactory Policies are read from the config, keyed by the name of the module which registers the
+// route group. They can be reloaded, so the middleware of every group looks up the current limiter on every request.
+// The limiters are created after the SetupComponentRegistry hook, so the store can be replaced from it.
+func (a *app) createRateLimiterFactory() func(group string) gin.HandlerFunc {
+	return func(group string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			rateLimiter, found := a.rateLimiters.Load().(map[string]gin.HandlerFunc)[group]
+
+			if !found {
+				c.Next()
+
+				return
+			}
+
+			rateLimiter(c)
+		}
+	}
+}
+
+func (a *app) createRateLimiters(
+	componentRegistry *componentregistry.ComponentRegistry,
+	rateLimits map[string]string,
+) (map[string]gin.HandlerFunc, error) {
+	res := make(map[string]gin.HandlerFunc)
+
+	for group, spec := range rateLimits {
+		policy, err := ratelimit.ParsePolicy(spec)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit for route group '%s': %s", group, err)
+		}
+
+		a.logger.Debug().Msgf("[app] Limiting requests of route group '%s' to %d every %s.", group, policy.Limit, policy.Period)
+
+		res[group] = middleware.RateLimit(
+			componentRegistry.RequestContextFactory,
+			group,
+			policy,
+			a.config.Auth.APIKeys,
+			ratelimit.NewLimiter(policy, componentRegistry.RateLimitStore, componentRegistry.TimeService.GetCurrentUtcTime),
+			a.logger,
+		)
+	}
+
+	return res, nil
+}
+
+func (a *app) createEventDispatcher(
+	componentRegistry *componentregistry.ComponentRegistry,
+	outboxEventRepository repository.OutboxEventRepository,
+) *events.Dispatcher {
+	dispatcher := events.NewDispatcher(
+		outboxEventRepository,
+		componentRegistry.RequestContextFactory,
+		a.logger,
+		componentRegistry.TimeService.GetCurrentUtcTime,
+		events.DispatcherOptions{
+			Interval:    a.config.Events.DispatchInterval,
+			MaxAttempts: a.config.Events.MaxAttempts,
+		},
+	)
+
+	dispatcher.AddSink(componentRegistry.EventBus)
+
+	if a.config.Events.FileSinkPath != "" {
+		dispatcher.AddSink(events.NewFileSink(a.config.Events.FileSinkPath))
+	}
+
+	if a.config.Events.WebhookSinkUrl != "" {
+		dispatcher.AddSink(events.NewWebhookSink(a.config.Events.WebhookSinkUrl, a.config.Events.WebhookTimeout))
+	}
+
+	return dispatcher
+}
+
+func (a *app) createJobScheduler(componentRegistry *componentregistry.ComponentRegistry) *jobs.Scheduler {
+	return jobs.NewScheduler(
+		repository.NewJobRepository(*a.config, componentRegistry.Db, a.logger),
+		repository.NewJobLockRepository(*a.config, componentRegistry.Db, a.logger),
+		componentRegistry.RequestContextFactory,
+		componentRegistry.TimeService,
+		a.logger,
+		componentRegistry.Metrics,
+		jobs.Options{
+			PollInterval:   a.config.Jobs.PollInterval,
+			Concurrency:    a.config.Jobs.Concurrency,
+			MaxAttempts:    a.config.Jobs.MaxAttempts,
+			RetryBaseDelay: a.config.Jobs.RetryBaseDelay,
+			RetryMaxDelay:  a.config.Jobs.RetryMaxDelay,
+			LockTimeout:    a.config.Jobs.LockTimeout,
+			LeaderLockTTL:  a.config.Jobs.LeaderLockTTL,
+		},
+	)
+}
+
+// createComponentRegistry Creates the components and sets the registry on the app as soon as possible, so the
+// resources created so far can be released if anything fails.
+func (a *app) createComponentRegistry() error {
 	componentRegistry := componentregistry.NewComponentRegistry()
 
+	a.componentRegistry = componentRegistry
+
 	// Logger
 
 	componentRegistry.Logger = a.logger
@@ -258,38 +953,163 @@ func (a *app) createComponentRegistry() *componentregistry.ComponentRegistry {
 
 	componentRegistry.RequestContextFactory = a.createRequestContextFactory()
 
+	// Config and metrics
+
+	componentRegistry.RuntimeConfig = a.runtimeConfig
+	componentRegistry.Metrics = metrics.NewRegistry()
+
+	// Error Reporter
+
+	componentRegistry.ErrorReporter = a.createErrorReporter()
+
+	// Mailer and tokens
+
+	componentRegistry.Mailer = a.createMailer()
+
+	tokenSigner, err := a.createTokenSigner()
+
+	if err != nil {
+		return err
+	}
+
+	componentRegistry.TokenSigner = tokenSigner
+
 	// Time Service
 
 	componentRegistry.TimeService = a.createTimeService()
 
 	// Db
 
-	componentRegistry.Db = a.createDb()
+	db, err := a.createDb()
+
+	if err != nil {
+		return err
+	}
+
+	componentRegistry.Db = db
+
+	// Transaction Service
+
+	componentRegistry.TransactionService = a.createTransactionService(componentRegistry.Db)
+
+	// Tenants
+
+	componentRegistry.TenantService = service.NewTenantService(
+		*a.config,
+		a.logger,
+		componentRegistry.Validator,
+		componentRegistry.TimeService,
+		componentRegistry.TokenSigner,
+		repository.NewTenantRepository(*a.config, componentRegistry.Db, a.logger),
+	)
+
+	// Redis
+
+	redisClient, err := a.createRedisClient()
+
+	if err != nil {
+		return err
+	}
+
+	componentRegistry.Redis = redisClient
+
+	// Cache
+
+	componentRegistry.CacheManager = a.createCacheManager(componentRegistry.TimeService, componentRegistry.Redis)
+	componentRegistry.CacheService = service.NewCacheService(a.logger, componentRegistry.TransactionService, componentRegistry.CacheManager)
+
+	// Rate limits
+
+	componentRegistry.RateLimitStore = a.createRateLimitStore(componentRegistry.TimeService, componentRegistry.Redis)
+	componentRegistry.SetRateLimiterFactory(a.createRateLimiterFactory())
+
+	// Events
+
+	outboxEventRepository := repository.NewOutboxEventRepository(*a.config, componentRegistry.Db, a.logger)
+
+	componentRegistry.EventService = service.NewEventService(a.logger, componentRegistry.TimeService, outboxEventRepository)
+	componentRegistry.EventBus = events.NewBus()
+	componentRegistry.EventDispatcher = a.createEventDispatcher(componentRegistry, outboxEventRepository)
+
+	componentRegistry.AddWorker(componentRegistry.EventDispatcher)
+
+	// Jobs
+
+	componentRegistry.JobScheduler = a.createJobScheduler(componentRegistry)
+
+	if a.config.Jobs.Enabled {
+		componentRegistry.AddWorker(componentRegistry.JobScheduler)
+	} else {
+		a.logger.Debug().Msg("[app] Jobs are disabled. The job scheduler will NOT be started.")
+	}
 
 	// Migrations
 
-	componentRegistry.Migrations = a.createDbMigrationsInstance(componentRegistry.Db)
+	migrations, err := a.createDbMigrationsInstance(componentRegistry.Db)
+
+	if err != nil {
+		return err
+	}
+
+	componentRegistry.Migrations = migrations
+
+	// Customizations made before the modules create their components (like replacing the cache factory)
+
+	componentRegistry = a.hooks.SetupComponentRegistry(componentRegistry)
+	a.componentRegistry = componentRegistry
+
+	rateLimiters, err := a.createRateLimiters(componentRegistry, a.config.RateLimits)
+
+	if err != nil {
+		return err
+	}
+
+	a.rateLimiters.Store(rateLimiters)
 
 	// Register modules components
 
 	for _, m := range a.moduleManager.GetModules() {
 		a.logger.Debug().Msgf("[app] Registering module '%s' components...", m.GetName())
 
-		m.SetUpComponents(*a.config, a.errorHandler, componentRegistry)
+		if err := m.SetUpComponents(*a.config, a.errorHandler, componentRegistry); err != nil {
+			return fmt.Errorf("could NOT set up the components of module '%s': %s", m.GetName(), err)
+		}
 	}
 
-	return componentRegistry
+	return nil
 }
 
-func (a *app) createRouter() *gin.Engine {
-	router := gin.Default()
+func (a *app) createRouter() (*gin.Engine, error) {
+	router := gin.New()
 
+	router.Use(gin.Logger())
+	router.Use(middleware.RequestID())
+	router.Use(a.createRecovery())
+	router.Use(middleware.ClientIdentity(a.config.Server.TLS.ClientIdentity))
+	router.Use(middleware.Cors(a.runtimeConfig))
 	router.Use(middleware.ErrorHandler(a.componentRegistry.RequestContextFactory, gin.ErrorTypeAny, a.errorHandler))
 
 	// Swagger
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Error catalog
+
+	errorController := controller.NewErrorController(a.config.Errors, a.componentRegistry.RequestContextFactory)
+
+	router.GET("/errors", errorController.Find)
+	router.GET("/errors/:code", errorController.FindOneByCode)
+
+	// Admin routes are served here only if the admin listener is disabled
+
+	if !a.config.Server.Admin.IsEnabled() {
+		a.setUpAdminRoutes(router, false)
+	}
+
+	// Routes registered from here on belong to the tenant of the request
+
+	router.Use(middleware.Tenant(a.config.Tenancy, a.componentRegistry.RequestContextFactory, a.componentRegistry.TenantService))
+
 	router = a.hooks.SetupRouter(router)
 
 	// Setup modules routes
@@ -297,28 +1117,149 @@ func (a *app) createRouter() *gin.Engine {
 	for _, m := range a.moduleManager.GetModules() {
 		a.logger.Debug().Msgf("[app] Registering module '%s' routes...", m.GetName())
 
-		m.SetUpRouter(a.errorHandler, a.componentRegistry, router)
+		if err := m.SetUpRouter(a.errorHandler, a.componentRegistry, router); err != nil {
+			return nil, fmt.Errorf("could NOT set up the routes of module '%s': %s", m.GetName(), err)
+		}
 	}
 
+	return router, nil
+}
+
+func (a *app) createRecovery() gin.HandlerFunc {
+	return middleware.Recovery(
+		a.componentRegistry.RequestContextFactory,
+		a.errorHandler,
+		a.logger,
+		a.componentRegistry.Metrics,
+		a.componentRegistry.ErrorReporter,
+		a.componentRegistry.TimeService,
+	)
+}
+
+// createAdminRouter Creates the router of the admin listener, if it's enabled.
+func (a *app) createAdminRouter() *gin.Engine {
+	if !a.config.Server.Admin.IsEnabled() {
+		return nil
+	}
+
+	router := gin.New()
+
+	router.Use(middleware.RequestID())
+	router.Use(a.createRecovery())
+
+	a.setUpAdminRoutes(router, a.config.Server.Admin.Pprof)
+
 	return router
 }
 
+func (a *app) setUpAdminRoutes(router *gin.Engine, pprofEnabled bool) {
+	// Metrics
+
+	router.GET("/metrics", gin.WrapH(a.componentRegistry.Metrics.Handler()))
+
+	// Health
+
+	router.GET("/health", func(c *gin.Context) {
+		if err := a.componentRegistry.Db.PingContext(c); err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": HealthStatusDown, "error": err.Error()})
+
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"status": HealthStatusUp})
+	})
+
+	// Profiling
+
+	if pprofEnabled {
+		router.Any("/debug/pprof/*profile", func(c *gin.Context) {
+			switch profile := strings.TrimPrefix(c.Param("profile"), "/"); profile {
+			case "":
+				pprof.Index(c.Writer, c.Request)
+			case "cmdline":
+				pprof.Cmdline(c.Writer, c.Request)
+			case "profile":
+				pprof.Profile(c.Writer, c.Request)
+			case "symbol":
+				pprof.Symbol(c.Writer, c.Request)
+			case "trace":
+				pprof.Trace(c.Writer, c.Request)
+			default:
+				pprof.Handler(profile).ServeHTTP(c.Writer, c.Request)
+			}
+		})
+	}
+}
+
+// httpServer Server started by Run, with its listener.
+type httpServer struct {
+	name     string
+	server   *http.Server
+	listener net.Listener
+}
+
+func (s *httpServer) serve() error {
+	if s.server.TLSConfig != nil {
+		return s.server.ServeTLS(s.listener, "", "")
+	}
+
+	return s.server.Serve(s.listener)
+}
+
 // Static functions
 
-func NewApp(appConfig *config.AppConfig) App {
-	return &app{
-		config: appConfig,
-		hooks:  hooks2.NewHooks(),
+// NewApp Creates the app with the built-in modules, plus the modules and hooks set on the options.
+func NewApp(appConfig *config.AppConfig, options ...Option) App {
+	res := &app{
+		config:  appConfig,
+		hooks:   hooks2.NewHooks(),
+		modules: make([]module.Module, 0),
+	}
+
+	for _, option := range options {
+		option(res)
+	}
+
+	return res
+}
+
+// withDbTimeLocation Adds the "_loc" parameter to the DB URI (unless it's already set), so the driver reads times back
+// in UTC, like they are stored.
+func withDbTimeLocation(uri string) string {
+	if strings.Contains(uri, DbTimeLocationParam+"=") {
+		return uri
+	}
+
+	separator := "?"
+
+	if strings.Contains(uri, "?") {
+		separator = "&"
+	}
+
+	return uri + separator + DbTimeLocationParam + "=UTC"
+}
+
+// newLifecycleError Returns an error including every error of the given action, or nil if there are none.
+func newLifecycleError(action string, errs []error) error {
+	if len(errs) < 1 {
+		return nil
+	}
+
+	messages := make([]string, len(errs))
+
+	for i, err := range errs {
+		messages[i] = err.Error()
 	}
+
+	return fmt.Errorf("could NOT %s the app: %s", action, strings.Join(messages, "; "))
 }
 
-func NewAppFromEnv() (App, error) {
-	appConfig := config.NewAppConfig()
-	err := envconfig.Process("MYAPP", appConfig)
+func NewAppFromEnv(options ...Option) (App, error) {
+	appConfig, err := config.NewAppConfigFromEnv()
 
 	if err != nil {
 		return nil, err
 	}
 
-	return NewApp(appConfig), nil
+	return NewApp(appConfig, options...), nil
 }
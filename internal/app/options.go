@@ -0,0 +1,57 @@
+package app
+
+import (
+	"github.com/comfortablynumb/goginrestapi/internal/hooks"
+	"github.com/comfortablynumb/goginrestapi/internal/module"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+)
+
+// Structs
+
+// Option Customizes the app created by NewApp.
+type Option func(a *app)
+
+// Static functions
+
+// WithModules Adds modules to the built-in ones. Like them, they are set up after the modules they depend on.
+func WithModules(modules ...module.Module) Option {
+	return func(a *app) {
+		a.modules = append(a.modules, modules...)
+	}
+}
+
+// WithRouterHook Adds a hook called once the router is created, before the modules register their routes.
+func WithRouterHook(hook hooks.RouterHook) Option {
+	return func(a *app) {
+		a.hooks.AddRouterHook(hook)
+	}
+}
+
+// WithComponentRegistryHook Adds a hook called once the core components are created, before the modules register
+// theirs.
+func WithComponentRegistryHook(hook hooks.ComponentRegistryHook) Option {
+	return func(a *app) {
+		a.hooks.AddComponentRegistryHook(hook)
+	}
+}
+
+// WithValidatorHook Adds a hook called once the validator is created, before the modules register their validations.
+func WithValidatorHook(hook hooks.ValidatorHook) Option {
+	return func(a *app) {
+		a.hooks.AddValidatorHook(hook)
+	}
+}
+
+// WithLoggerHook Adds a hook called once the logger is created.
+func WithLoggerHook(hook hooks.LoggerHook) Option {
+	return func(a *app) {
+		a.hooks.AddLoggerHook(hook)
+	}
+}
+
+// WithTimeService Replaces the time service used by every component, like a fake clock in the tests.
+func WithTimeService(timeService service.TimeService) Option {
+	return func(a *app) {
+		a.timeService = timeService
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/validation"
 )
 
 // Structs
@@ -32,6 +33,46 @@ func NewModelNotFoundAppError(ctx *context.RequestContext, err error, source str
 	return NewAppError(ctx, err, source, ModelNotFoundErrorCode, ModelNotFoundErrorMessage, nil)
 }
 
+func NewIdempotencyKeyMismatchAppError(ctx *context.RequestContext, err error, source string) *AppError {
+	return NewAppError(ctx, err, source, IdempotencyKeyMismatchErrorCode, IdempotencyKeyMismatchErrorMessage, nil)
+}
+
+func NewIdempotencyKeyInProgressAppError(ctx *context.RequestContext, err error, source string) *AppError {
+	return NewAppError(ctx, err, source, IdempotencyKeyInProgressErrorCode, IdempotencyKeyInProgressErrorMessage, nil)
+}
+
+func NewInvalidTokenAppError(ctx *context.RequestContext, err error, source string) *AppError {
+	return NewAppError(ctx, err, source, InvalidTokenErrorCode, InvalidTokenErrorMessage, nil)
+}
+
+func NewInvalidCredentialsAppError(ctx *context.RequestContext, err error, source string) *AppError {
+	return NewAppError(ctx, err, source, InvalidCredentialsErrorCode, InvalidCredentialsErrorMessage, nil)
+}
+
+func NewAccountLockedAppError(ctx *context.RequestContext, err error, source string, data map[string]interface{}) *AppError {
+	return NewAppError(ctx, err, source, AccountLockedErrorCode, AccountLockedErrorMessage, data)
+}
+
+func NewMfaRequiredAppError(ctx *context.RequestContext, err error, source string) *AppError {
+	return NewAppError(ctx, err, source, MfaRequiredErrorCode, MfaRequiredErrorMessage, nil)
+}
+
+func NewMfaEnrollmentRequiredAppError(ctx *context.RequestContext, err error, source string) *AppError {
+	return NewAppError(ctx, err, source, MfaEnrollmentRequiredErrorCode, MfaEnrollmentRequiredErrorMessage, nil)
+}
+
+func NewInvalidMfaCodeAppError(ctx *context.RequestContext, err error, source string) *AppError {
+	return NewAppError(ctx, err, source, InvalidMfaCodeErrorCode, InvalidMfaCodeErrorMessage, nil)
+}
+
+func NewMfaAlreadyEnabledAppError(ctx *context.RequestContext, err error, source string) *AppError {
+	return NewAppError(ctx, err, source, MfaAlreadyEnabledErrorCode, MfaAlreadyEnabledErrorMessage, nil)
+}
+
+func NewInvalidTenantAppError(ctx *context.RequestContext, err error, source string) *AppError {
+	return NewAppError(ctx, err, source, InvalidTenantErrorCode, InvalidTenantErrorMessage, nil)
+}
+
 func NewAppError(ctx *context.RequestContext, err error, source string, code string, message string, data map[string]interface{}) *AppError {
 	if data == nil {
 		data = make(map[string]interface{})
@@ -46,6 +87,15 @@ func NewAppError(ctx *context.RequestContext, err error, source string, code str
 	}
 }
 
+// GetErrors Returns the validation errors of the error, if any.
+func (e *AppError) GetErrors() []*validation.ValidationError {
+	if validationErrors, ok := e.Data["errors"].([]*validation.ValidationError); ok {
+		return validationErrors
+	}
+
+	return make([]*validation.ValidationError, 0)
+}
+
 func (e *AppError) Error() string {
 	return fmt.Sprintf("[%s] Code: %s - Message: %s - Error: %s", e.Source, e.Code, e.Message, e.Err)
 }
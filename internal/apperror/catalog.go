@@ -0,0 +1,128 @@
+package apperror
+
+import "net/http"
+
+// Structs
+
+// ErrorCode Documentation of an error code, served by the error catalog of the app.
+type ErrorCode struct {
+	Code        string
+	HttpStatus  int
+	Message     string
+	Description string
+}
+
+// Variables
+
+// ErrorCodes Every error code returned by the app, documented.
+var ErrorCodes = []*ErrorCode{
+	{
+		Code:        InternalErrorCode,
+		HttpStatus:  http.StatusInternalServerError,
+		Message:     InternalErrorMessage,
+		Description: "An unexpected error occurred while processing the request. It's logged with the request id.",
+	},
+	{
+		Code:        BindingErrorCode,
+		HttpStatus:  http.StatusBadRequest,
+		Message:     BindingErrorMessage,
+		Description: "The request could not be read: its body, query or path parameters are malformed or miss required fields. Invalid fields are listed on the errors.",
+	},
+	{
+		Code:        ValidationErrorCode,
+		HttpStatus:  http.StatusBadRequest,
+		Message:     ValidationErrorMessage,
+		Description: "The request was read, but some of its fields are not valid. Invalid fields are listed on the errors, by their JSON path.",
+	},
+	{
+		Code:        DbErrorCode,
+		HttpStatus:  http.StatusInternalServerError,
+		Message:     DbErrorMessage,
+		Description: "The database failed while processing the request. It may be retried later.",
+	},
+	{
+		Code:        ModelNotFoundErrorCode,
+		HttpStatus:  http.StatusNotFound,
+		Message:     ModelNotFoundErrorMessage,
+		Description: "The element referenced by the path of the request does not exist.",
+	},
+	{
+		Code:        TooManyRequestsErrorCode,
+		HttpStatus:  http.StatusTooManyRequests,
+		Message:     TooManyRequestsErrorMessage,
+		Description: "The rate limit of the client was exceeded. The Retry-After header tells when to try again.",
+	},
+	{
+		Code:        IdempotencyKeyMismatchErrorCode,
+		HttpStatus:  http.StatusUnprocessableEntity,
+		Message:     IdempotencyKeyMismatchErrorMessage,
+		Description: "The Idempotency-Key header was already used with a request with a different method, path or body.",
+	},
+	{
+		Code:        IdempotencyKeyInProgressErrorCode,
+		HttpStatus:  http.StatusConflict,
+		Message:     IdempotencyKeyInProgressErrorMessage,
+		Description: "A request with the same Idempotency-Key header is still being processed. It may be retried later.",
+	},
+	{
+		Code:        InvalidTokenErrorCode,
+		HttpStatus:  http.StatusUnprocessableEntity,
+		Message:     InvalidTokenErrorMessage,
+		Description: "The token sent (like an email verification one) was not issued for this purpose or user, or it has expired. A new one must be requested.",
+	},
+	{
+		Code:        InvalidCredentialsErrorCode,
+		HttpStatus:  http.StatusUnauthorized,
+		Message:     InvalidCredentialsErrorMessage,
+		Description: "The login failed. It counts as a failed login of the user, if it exists: too many of them in a row lock the account for a while.",
+	},
+	{
+		Code:        AccountLockedErrorCode,
+		HttpStatus:  http.StatusLocked,
+		Message:     AccountLockedErrorMessage,
+		Description: "The user failed to log in too many times in a row. The account is unlocked once locked_until is reached, by an admin, or by resetting its password.",
+	},
+	{
+		Code:        MfaRequiredErrorCode,
+		HttpStatus:  http.StatusUnauthorized,
+		Message:     MfaRequiredErrorMessage,
+		Description: "The password is valid, but the user has a second factor. The login must be retried with a TOTP code or a recovery code.",
+	},
+	{
+		Code:        MfaEnrollmentRequiredErrorCode,
+		HttpStatus:  http.StatusForbidden,
+		Message:     MfaEnrollmentRequiredErrorMessage,
+		Description: "The type of the user requires a second factor, and the user has none yet. It must be enrolled through /auth/mfa/enroll and /auth/mfa/confirm.",
+	},
+	{
+		Code:        InvalidMfaCodeErrorCode,
+		HttpStatus:  http.StatusUnauthorized,
+		Message:     InvalidMfaCodeErrorMessage,
+		Description: "The TOTP code is wrong, expired or was already used, or the recovery code is wrong or was already used. It counts as a failed login of the user.",
+	},
+	{
+		Code:        MfaAlreadyEnabledErrorCode,
+		HttpStatus:  http.StatusConflict,
+		Message:     MfaAlreadyEnabledErrorMessage,
+		Description: "The user already has a second factor. An admin must disable it before a new one can be enrolled.",
+	},
+	{
+		Code:        InvalidTenantErrorCode,
+		HttpStatus:  http.StatusBadRequest,
+		Message:     InvalidTenantErrorMessage,
+		Description: "The request has no tenant and there is no default one, its tenant doesn't exist or is disabled, its tenant token is not valid, or the tenants sent on the token, the header and the subdomain don't match.",
+	},
+}
+
+// Static functions
+
+// GetErrorCode Returns the documentation of an error code, or nil if it's unknown.
+func GetErrorCode(code string) *ErrorCode {
+	for _, errorCode := range ErrorCodes {
+		if errorCode.Code == code {
+			return errorCode
+		}
+	}
+
+	return nil
+}
@@ -8,6 +8,8 @@ import (
 
 // Static functions
 
+// AddValidationErrorsToMap Adds the errors of a validation to data, with the path of their fields and their messages
+// translated to the locale of the request.
 func AddValidationErrorsToMap(ctx *context.RequestContext, err error, data map[string]interface{}) {
 	fieldErrors, ok := err.(validator.ValidationErrors)
 
@@ -16,7 +18,7 @@ func AddValidationErrorsToMap(ctx *context.RequestContext, err error, data map[s
 		trans := ctx.GetTranslator()
 
 		for _, fieldError := range fieldErrors {
-			errors = append(errors, validation.NewValidationError(fieldError.Namespace(), fieldError.Tag(), fieldError.Translate(*trans)))
+			errors = append(errors, validation.NewValidationError(validation.GetFieldPath(fieldError.Namespace()), fieldError.Tag(), fieldError.Translate(*trans)))
 		}
 
 		data["errors"] = errors
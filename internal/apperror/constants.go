@@ -17,4 +17,37 @@ const (
 
 	ModelNotFoundErrorCode    = "000005"
 	ModelNotFoundErrorMessage = "The element you referenced was not found"
+
+	TooManyRequestsErrorCode    = "000006"
+	TooManyRequestsErrorMessage = "Too many requests. Please, try again later"
+
+	IdempotencyKeyMismatchErrorCode    = "000007"
+	IdempotencyKeyMismatchErrorMessage = "The idempotency key was already used with a different request"
+
+	IdempotencyKeyInProgressErrorCode    = "000008"
+	IdempotencyKeyInProgressErrorMessage = "A request with the same idempotency key is still being processed"
+
+	InvalidTokenErrorCode    = "000009"
+	InvalidTokenErrorMessage = "The token is not valid or has expired"
+
+	InvalidCredentialsErrorCode    = "000010"
+	InvalidCredentialsErrorMessage = "The username or the password are not valid"
+
+	AccountLockedErrorCode    = "000011"
+	AccountLockedErrorMessage = "The account is temporarily locked due to too many failed logins"
+
+	MfaRequiredErrorCode    = "000012"
+	MfaRequiredErrorMessage = "A one-time code of the second factor is required"
+
+	MfaEnrollmentRequiredErrorCode    = "000013"
+	MfaEnrollmentRequiredErrorMessage = "The user must set up a second factor before logging in"
+
+	InvalidMfaCodeErrorCode    = "000014"
+	InvalidMfaCodeErrorMessage = "The one-time code is not valid"
+
+	MfaAlreadyEnabledErrorCode    = "000015"
+	MfaAlreadyEnabledErrorMessage = "The second factor of the user is already enabled"
+
+	InvalidTenantErrorCode    = "000016"
+	InvalidTenantErrorMessage = "The tenant is missing, unknown or disabled"
 )
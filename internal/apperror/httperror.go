@@ -40,6 +40,10 @@ func (e *HttpError) GetErrors() []*validation.ValidationError {
 		return res
 	}
 
+	if validationErrors, ok := errors.([]*validation.ValidationError); ok {
+		return validationErrors
+	}
+
 	errorArray, ok := errors.([]interface{})
 
 	if !ok {
@@ -109,6 +113,52 @@ func NewNotFoundHttpError(ctx *context.RequestContext, err error, source string,
 	return NewHttpError(ctx, err, source, http.StatusNotFound, ModelNotFoundErrorCode, ModelNotFoundErrorMessage, data)
 }
 
+func NewTooManyRequestsHttpError(ctx *context.RequestContext, err error, source string, data map[string]interface{}) *HttpError {
+	return NewHttpError(ctx, err, source, http.StatusTooManyRequests, TooManyRequestsErrorCode, TooManyRequestsErrorMessage, data)
+}
+
+func NewIdempotencyKeyMismatchHttpError(ctx *context.RequestContext, err error, source string, data map[string]interface{}) *HttpError {
+	return NewHttpError(ctx, err, source, http.StatusUnprocessableEntity, IdempotencyKeyMismatchErrorCode, IdempotencyKeyMismatchErrorMessage, data)
+}
+
+func NewIdempotencyKeyInProgressHttpError(ctx *context.RequestContext, err error, source string, data map[string]interface{}) *HttpError {
+	return NewHttpError(ctx, err, source, http.StatusConflict, IdempotencyKeyInProgressErrorCode, IdempotencyKeyInProgressErrorMessage, data)
+}
+
+func NewInvalidTokenHttpError(ctx *context.RequestContext, err error, source string, data map[string]interface{}) *HttpError {
+	return NewHttpError(ctx, err, source, http.StatusUnprocessableEntity, InvalidTokenErrorCode, InvalidTokenErrorMessage, data)
+}
+
+func NewInvalidCredentialsHttpError(ctx *context.RequestContext, err error, source string, data map[string]interface{}) *HttpError {
+	return NewHttpError(ctx, err, source, http.StatusUnauthorized, InvalidCredentialsErrorCode, InvalidCredentialsErrorMessage, data)
+}
+
+func NewAccountLockedHttpError(ctx *context.RequestContext, err error, source string, data map[string]interface{}) *HttpError {
+	return NewHttpError(ctx, err, source, http.StatusLocked, AccountLockedErrorCode, AccountLockedErrorMessage, data)
+}
+
+func NewMfaRequiredHttpError(ctx *context.RequestContext, err error, source string, data map[string]interface{}) *HttpError {
+	return NewHttpError(ctx, err, source, http.StatusUnauthorized, MfaRequiredErrorCode, MfaRequiredErrorMessage, data)
+}
+
+func NewMfaEnrollmentRequiredHttpError(ctx *context.RequestContext, err error, source string, data map[string]interface{}) *HttpError {
+	return NewHttpError(ctx, err, source, http.StatusForbidden, MfaEnrollmentRequiredErrorCode, MfaEnrollmentRequiredErrorMessage, data)
+}
+
+func NewInvalidMfaCodeHttpError(ctx *context.RequestContext, err error, source string, data map[string]interface{}) *HttpError {
+	return NewHttpError(ctx, err, source, http.StatusUnauthorized, InvalidMfaCodeErrorCode, InvalidMfaCodeErrorMessage, data)
+}
+
+func NewMfaAlreadyEnabledHttpError(ctx *context.RequestContext, err error, source string, data map[string]interface{}) *HttpError {
+	return NewHttpError(ctx, err, source, http.StatusConflict, MfaAlreadyEnabledErrorCode, MfaAlreadyEnabledErrorMessage, data)
+}
+
+func NewInvalidTenantHttpError(ctx *context.RequestContext, err error, source string, data map[string]interface{}) *HttpError {
+	return NewHttpError(ctx, err, source, http.StatusBadRequest, InvalidTenantErrorCode, InvalidTenantErrorMessage, data)
+}
+
+// NewHttpError The message is translated to the locale of the request, by code. message is used when the code has no
+// message for that locale.
 func NewHttpError(ctx *context.RequestContext, err error, source string, httpStatus int, code string, message string, data map[string]interface{}) *HttpError {
 	if data == nil {
 		data = make(map[string]interface{})
@@ -119,7 +169,7 @@ func NewHttpError(ctx *context.RequestContext, err error, source string, httpSta
 		HttpStatus: httpStatus,
 		Source:     source,
 		Code:       code,
-		Message:    message,
+		Message:    ctx.Translate(code, message),
 		Data:       data,
 	}
 }
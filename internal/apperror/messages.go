@@ -0,0 +1,44 @@
+package apperror
+
+// Variables
+
+// Messages Messages of the error codes, by locale. The English ones are the messages of the constants, which are used
+// when a code has no message for the locale of a request.
+var Messages = map[string]map[string]string{
+	"en": {
+		InternalErrorCode:                 InternalErrorMessage,
+		BindingErrorCode:                  BindingErrorMessage,
+		ValidationErrorCode:               ValidationErrorMessage,
+		DbErrorCode:                       DbErrorMessage,
+		ModelNotFoundErrorCode:            ModelNotFoundErrorMessage,
+		TooManyRequestsErrorCode:          TooManyRequestsErrorMessage,
+		IdempotencyKeyMismatchErrorCode:   IdempotencyKeyMismatchErrorMessage,
+		IdempotencyKeyInProgressErrorCode: IdempotencyKeyInProgressErrorMessage,
+		InvalidTokenErrorCode:             InvalidTokenErrorMessage,
+		InvalidCredentialsErrorCode:       InvalidCredentialsErrorMessage,
+		AccountLockedErrorCode:            AccountLockedErrorMessage,
+		MfaRequiredErrorCode:              MfaRequiredErrorMessage,
+		MfaEnrollmentRequiredErrorCode:    MfaEnrollmentRequiredErrorMessage,
+		InvalidMfaCodeErrorCode:           InvalidMfaCodeErrorMessage,
+		MfaAlreadyEnabledErrorCode:        MfaAlreadyEnabledErrorMessage,
+		InvalidTenantErrorCode:            InvalidTenantErrorMessage,
+	},
+	"es": {
+		InternalErrorCode:                 "Error interno del servidor.",
+		BindingErrorCode:                  "Error al leer la petición",
+		ValidationErrorCode:               "Error de validación de la petición",
+		DbErrorCode:                       "Ocurrió un error en nuestra base de datos",
+		ModelNotFoundErrorCode:            "No se encontró el elemento referenciado",
+		TooManyRequestsErrorCode:          "Demasiadas peticiones. Por favor, inténtelo de nuevo más tarde",
+		IdempotencyKeyMismatchErrorCode:   "La clave de idempotencia ya fue usada con una petición diferente",
+		IdempotencyKeyInProgressErrorCode: "Una petición con la misma clave de idempotencia todavía se está procesando",
+		InvalidTokenErrorCode:             "El token no es válido o ha expirado",
+		InvalidCredentialsErrorCode:       "El usuario o la contraseña no son válidos",
+		AccountLockedErrorCode:            "La cuenta está bloqueada temporalmente por demasiados inicios de sesión fallidos",
+		MfaRequiredErrorCode:              "Se requiere un código de un solo uso del segundo factor",
+		MfaEnrollmentRequiredErrorCode:    "El usuario debe configurar un segundo factor antes de iniciar sesión",
+		InvalidMfaCodeErrorCode:           "El código de un solo uso no es válido",
+		MfaAlreadyEnabledErrorCode:        "El segundo factor del usuario ya está habilitado",
+		InvalidTenantErrorCode:            "Falta el inquilino, es desconocido o está deshabilitado",
+	},
+}
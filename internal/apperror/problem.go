@@ -0,0 +1,73 @@
+package apperror
+
+import (
+	"strings"
+
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+)
+
+// Constants
+
+const (
+	ProblemContentType = "application/problem+json"
+)
+
+// Structs
+
+// ProblemDetails RFC 7807 representation of an HttpError. The code, the request id, the invalid params and the rest
+// of the data of the error are extension members.
+type ProblemDetails struct {
+	Type          string                 `json:"type"`
+	Title         string                 `json:"title"`
+	Status        int                    `json:"status"`
+	Detail        string                 `json:"detail,omitempty"`
+	Instance      string                 `json:"instance,omitempty"`
+	Code          string                 `json:"code"`
+	RequestID     string                 `json:"request_id,omitempty"`
+	InvalidParams []*InvalidParam        `json:"invalid_params,omitempty"`
+	Data          map[string]interface{} `json:"data,omitempty"`
+}
+
+// InvalidParam Validation error of a field, identified by its JSON path.
+type InvalidParam struct {
+	Name      string `json:"name"`
+	Validator string `json:"validator"`
+	Reason    string `json:"reason"`
+}
+
+// Static functions
+
+// NewProblemDetails Creates the problem details of an error. Its type is typeBaseUrl followed by its code, and its
+// detail the reasons of its invalid params, if it has any.
+func NewProblemDetails(ctx *context.RequestContext, httpError *HttpError, typeBaseUrl string, instance string) *ProblemDetails {
+	invalidParams := make([]*InvalidParam, 0)
+	reasons := make([]string, 0)
+	data := make(map[string]interface{})
+
+	for _, validationError := range httpError.GetErrors() {
+		invalidParams = append(invalidParams, &InvalidParam{
+			Name:      validationError.Field,
+			Validator: validationError.Validator,
+			Reason:    validationError.Message,
+		})
+		reasons = append(reasons, validationError.Message)
+	}
+
+	for key, value := range httpError.Data {
+		if key != "errors" {
+			data[key] = value
+		}
+	}
+
+	return &ProblemDetails{
+		Type:          typeBaseUrl + httpError.Code,
+		Title:         httpError.Message,
+		Status:        httpError.HttpStatus,
+		Detail:        strings.Join(reasons, "; "),
+		Instance:      instance,
+		Code:          httpError.Code,
+		RequestID:     ctx.GetRequestID(),
+		InvalidParams: invalidParams,
+		Data:          data,
+	}
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Constants
+
+const (
+	// KeySeparator Separates the tag of a key from the rest of it. Every key must start with a tag, so every entry
+	// related to a given entity type can be invalidated at once.
+	KeySeparator = ":"
+
+	UserTag     = "user"
+	UserTypeTag = "user_type"
+
+	RepositoryCacheName = "repository"
+	ResponseCacheName   = "response"
+)
+
+// Interfaces
+
+// Cache Stores values for a limited time. Implementations must be safe for concurrent use. Failures of the underlying
+// storage are not returned: a cache which can't be read behaves as an empty one.
+type Cache interface {
+	GetName() string
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(key string)
+	DeleteByTag(tag string)
+	GetStats() Stats
+}
+
+// Structs
+
+type Stats struct {
+	Name    string `json:"name"`
+	Hits    int64  `json:"hits"`
+	Misses  int64  `json:"misses"`
+	Entries int64  `json:"entries"`
+}
+
+// HitRatio Returns the hits over the total of lookups, or 0 if there weren't any.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+
+	if total == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(total)
+}
+
+type counters struct {
+	hits   int64
+	misses int64
+}
+
+func (c *counters) record(hit bool) {
+	if hit {
+		atomic.AddInt64(&c.hits, 1)
+	} else {
+		atomic.AddInt64(&c.misses, 1)
+	}
+}
+
+func (c *counters) stats(name string, entries int64) Stats {
+	return Stats{
+		Name:    name,
+		Hits:    atomic.LoadInt64(&c.hits),
+		Misses:  atomic.LoadInt64(&c.misses),
+		Entries: entries,
+	}
+}
+
+// Manager Keeps the named caches of the app, creating them on first use with the configured factory.
+type Manager struct {
+	mutex   sync.Mutex
+	factory func(name string) Cache
+	caches  map[string]Cache
+}
+
+func (m *Manager) Get(name string) Cache {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	c, found := m.caches[name]
+
+	if !found {
+		c = m.factory(name)
+
+		m.caches[name] = c
+	}
+
+	return c
+}
+
+// SetFactory Replaces the function used to create the caches which were not requested yet (for example, to use Redis
+// instead of memory).
+func (m *Manager) SetFactory(factory func(name string) Cache) *Manager {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.factory = factory
+
+	return m
+}
+
+// Invalidate Deletes the entries with any of the given tags from every cache.
+func (m *Manager) Invalidate(tags ...string) {
+	m.mutex.Lock()
+	caches := make([]Cache, 0, len(m.caches))
+
+	for _, c := range m.caches {
+		caches = append(caches, c)
+	}
+
+	m.mutex.Unlock()
+
+	for _, c := range caches {
+		for _, tag := range tags {
+			c.DeleteByTag(tag)
+		}
+	}
+}
+
+// GetStats Returns the stats of every cache, sorted by name.
+func (m *Manager) GetStats() []Stats {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	res := make([]Stats, 0, len(m.caches))
+
+	for _, c := range m.caches {
+		res = append(res, c.GetStats())
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+
+	return res
+}
+
+// Static functions
+
+// Key Builds a cache key from a tag and the parts identifying the entry.
+func Key(tag string, parts ...string) string {
+	key := tag
+
+	for _, part := range parts {
+		key += KeySeparator + part
+	}
+
+	return key
+}
+
+func NewManager(factory func(name string) Cache) *Manager {
+	return &Manager{
+		factory: factory,
+		caches:  make(map[string]Cache),
+	}
+}
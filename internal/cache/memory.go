@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Structs
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryCache LRU cache with a TTL per entry. Once it's full, the least recently used entry is evicted to make room
+// for a new one. Expired entries are removed when they are found.
+type MemoryCache struct {
+	counters
+
+	name       string
+	maxEntries int
+	now        func() time.Time
+	mutex      sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+func (c *MemoryCache) GetName() string {
+	return c.name
+}
+
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, found := c.entries[key]
+
+	if found && !c.now().Before(element.Value.(*memoryEntry).expiresAt) {
+		c.remove(element)
+
+		found = false
+	}
+
+	c.record(found)
+
+	if !found {
+		return nil, false
+	}
+
+	c.lru.MoveToFront(element)
+
+	return element.Value.(*memoryEntry).value, true
+}
+
+func (c *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, found := c.entries[key]; found {
+		element.Value.(*memoryEntry).value = value
+		element.Value.(*memoryEntry).expiresAt = c.now().Add(ttl)
+
+		c.lru.MoveToFront(element)
+
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&memoryEntry{key: key, value: value, expiresAt: c.now().Add(ttl)})
+
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *MemoryCache) Delete(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, found := c.entries[key]; found {
+		c.remove(element)
+	}
+}
+
+func (c *MemoryCache) DeleteByTag(tag string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	prefix := tag + KeySeparator
+
+	for key, element := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.remove(element)
+		}
+	}
+}
+
+func (c *MemoryCache) GetStats() Stats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.stats(c.name, int64(c.lru.Len()))
+}
+
+func (c *MemoryCache) remove(element *list.Element) {
+	c.lru.Remove(element)
+
+	delete(c.entries, element.Value.(*memoryEntry).key)
+}
+
+// Static functions
+
+// NewMemoryCache Creates a memory cache. The now function is used to check expirations, so tests can control time.
+func NewMemoryCache(name string, maxEntries int, now func() time.Time) *MemoryCache {
+	if maxEntries < 1 {
+		maxEntries = 1
+	}
+
+	if now == nil {
+		now = time.Now
+	}
+
+	return &MemoryCache{
+		name:       name,
+		maxEntries: maxEntries,
+		now:        now,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
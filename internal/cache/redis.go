@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Interfaces
+
+// RedisClient The subset of a Redis client used by RedisCache, so the app doesn't depend on a specific client library.
+// Get must return false (and no error) if the key doesn't exist.
+type RedisClient interface {
+	Get(key string) ([]byte, bool, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	Del(keys ...string) error
+	Scan(pattern string) ([]string, error)
+}
+
+// Structs
+
+// RedisCache Stores the entries on Redis, so they are shared between instances of the app. Expiration and eviction are
+// left to Redis. Every key is prefixed with the name of the cache, so several caches can share a database.
+type RedisCache struct {
+	counters
+
+	name   string
+	client RedisClient
+	logger *zerolog.Logger
+}
+
+func (c *RedisCache) GetName() string {
+	return c.name
+}
+
+func (c *RedisCache) Get(key string) ([]byte, bool) {
+	value, found, err := c.client.Get(c.key(key))
+
+	if err != nil {
+		c.logger.Warn().Msgf("[RedisCache] Could NOT get key '%s' from cache '%s': %s", key, c.name, err)
+
+		found = false
+	}
+
+	c.record(found)
+
+	return value, found
+}
+
+func (c *RedisCache) Set(key string, value []byte, ttl time.Duration) {
+	if err := c.client.Set(c.key(key), value, ttl); err != nil {
+		c.logger.Warn().Msgf("[RedisCache] Could NOT set key '%s' on cache '%s': %s", key, c.name, err)
+	}
+}
+
+func (c *RedisCache) Delete(key string) {
+	if err := c.client.Del(c.key(key)); err != nil {
+		c.logger.Warn().Msgf("[RedisCache] Could NOT delete key '%s' from cache '%s': %s", key, c.name, err)
+	}
+}
+
+func (c *RedisCache) DeleteByTag(tag string) {
+	keys, err := c.client.Scan(c.key(tag + KeySeparator + "*"))
+
+	if err == nil && len(keys) > 0 {
+		err = c.client.Del(keys...)
+	}
+
+	if err != nil {
+		c.logger.Warn().Msgf("[RedisCache] Could NOT delete tag '%s' from cache '%s': %s", tag, c.name, err)
+	}
+}
+
+// GetStats Entries are not counted, as it would require scanning the keys of the cache.
+func (c *RedisCache) GetStats() Stats {
+	return c.stats(c.name, -1)
+}
+
+func (c *RedisCache) key(key string) string {
+	return c.name + KeySeparator + key
+}
+
+// Static functions
+
+func NewRedisCache(name string, client RedisClient, logger *zerolog.Logger) *RedisCache {
+	return &RedisCache{
+		name:   name,
+		client: client,
+		logger: logger,
+	}
+}
@@ -0,0 +1,307 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/comfortablynumb/goginrestapi/internal/app"
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// Constants
+
+const (
+	Name = "goginrestapi"
+)
+
+// Variables
+
+var (
+	ErrUsage = errors.New("invalid usage")
+)
+
+// Structs
+
+type command struct {
+	name        string
+	usage       string
+	description string
+	run         func(args []string) error
+}
+
+// stringsFlag Flag which can be set several times.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+
+	return nil
+}
+
+// CLI Entry point of the binary. Every subcommand loads the config, creates its own app and sets it up, so they all
+// work with the same components as the server.
+type CLI struct {
+	newLoader   func() *config.Loader
+	out         io.Writer
+	appOptions  []app.Option
+	configFiles stringsFlag
+	overrides   stringsFlag
+	tenant      string
+}
+
+// Run Executes the subcommand named on the first argument, after the global flags. Without a subcommand, the server
+// is started.
+func (c *CLI) Run(args []string) error {
+	flags := c.newFlagSet(Name)
+
+	flags.Var(&c.configFiles, "config", "Loads a config file (YAML, TOML or JSON). Can be repeated, later files take precedence")
+	flags.Var(&c.overrides, "set", "Sets a config value, like -set server.port=9090. Takes precedence over files and environment variables. Can be repeated")
+	flags.StringVar(&c.tenant, "tenant", "", "Tenant the users and user types commands work on. Default: the default tenant of the config")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	args = flags.Args()
+
+	if len(args) < 1 {
+		return c.serve(args)
+	}
+
+	return c.runCommand(Name, args, []*command{
+		{name: "serve", usage: "[-skip-migrations]", description: "Starts the server", run: c.serve},
+		{name: "migrate", usage: "up|down|goto N|force N|version|create NAME", description: "Manages the database migrations", run: c.migrate},
+		{name: "seed", usage: "[-env NAME] [FILE...]", description: "Loads the fixtures of an environment, or the given fixture files", run: c.seed},
+		{name: "user", usage: "create|disable|list", description: "Manages users", run: c.user},
+		{name: "user-type", usage: "create|disable|list", description: "Manages user types", run: c.userType},
+		{name: "tenant", usage: "create|disable|list|token", description: "Manages tenants", run: c.tenants},
+		{name: "routes", usage: "", description: "Prints every route registered by the modules", run: c.routes},
+		{name: "config", usage: "print", description: "Prints the effective config, with secrets redacted", run: c.config},
+		{name: "generate", usage: "[-dir DIR] [-force] FILE", description: "Generates a CRUD module from an entity definition", run: c.generate},
+	})
+}
+
+func (c *CLI) serve(args []string) error {
+	flags := c.newFlagSet("serve")
+	skipMigrations := flags.Bool("skip-migrations", false, "Do not execute the database migrations on startup")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	appConfig, err := c.loadConfig()
+
+	if err != nil {
+		return err
+	}
+
+	if *skipMigrations {
+		appConfig.Db.AutoMigrate = false
+	}
+
+	application := app.NewApp(appConfig, c.appOptions...)
+
+	defer application.Close()
+
+	ctx, cancel := newSignalContext(syscall.SIGINT, syscall.SIGTERM)
+
+	defer cancel()
+
+	return application.Run(ctx)
+}
+
+func (c *CLI) routes(args []string) error {
+	if err := c.newFlagSet("routes").Parse(args); err != nil {
+		return err
+	}
+
+	application, err := c.createApp(false)
+
+	if err != nil {
+		return err
+	}
+
+	defer application.Close()
+
+	routes := application.GetRouter().Routes()
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+
+		return routes[i].Path < routes[j].Path
+	})
+
+	writer := c.newTabWriter()
+
+	fmt.Fprintln(writer, "METHOD\tPATH\tHANDLER")
+
+	for _, route := range routes {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", route.Method, route.Path, route.Handler[strings.LastIndex(route.Handler, "/")+1:])
+	}
+
+	return writer.Flush()
+}
+
+func (c *CLI) config(args []string) error {
+	return c.runCommand("config", args, []*command{
+		{name: "print", usage: "", description: "Prints the effective config, with secrets redacted", run: func(args []string) error {
+			if err := c.newFlagSet("config print").Parse(args); err != nil {
+				return err
+			}
+
+			// The app loads the config sections of the modules
+
+			application, err := c.createApp(false)
+
+			if err != nil {
+				return err
+			}
+
+			defer application.Close()
+
+			for _, setting := range application.GetConfig().GetSettings(true) {
+				fmt.Fprintf(c.out, "%s=%s\n", setting.Key, setting.Value)
+			}
+
+			return nil
+		}},
+	})
+}
+
+// createApp Creates an app which is set up, but not started. It must be closed once the command finishes. Migrations are only executed if autoMigrate is true and
+// the config allows it.
+func (c *CLI) createApp(autoMigrate bool) (app.App, error) {
+	appConfig, err := c.loadConfig()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !autoMigrate {
+		appConfig.Db.AutoMigrate = false
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+
+	application := app.NewApp(appConfig, c.appOptions...)
+
+	if err := application.SetUp(); err != nil {
+		return nil, err
+	}
+
+	return application, nil
+}
+
+func (c *CLI) loadConfig() (*config.AppConfig, error) {
+	return c.newLoader().WithFiles(c.configFiles...).WithOverrides(c.overrides...).Load()
+}
+
+func (c *CLI) runCommand(parent string, args []string, commands []*command) error {
+	if len(args) > 0 {
+		for _, cmd := range commands {
+			if cmd.name == args[0] {
+				return cmd.run(args[1:])
+			}
+		}
+
+		fmt.Fprintf(c.out, "Unknown command: %s\n\n", args[0])
+	}
+
+	usage := parent
+
+	if parent == Name {
+		usage += " [-config FILE]... [-set KEY=VALUE]... [-tenant NAME]"
+	}
+
+	fmt.Fprintf(c.out, "Usage: %s <command>\n\nCommands:\n", usage)
+
+	writer := c.newTabWriter()
+
+	for _, cmd := range commands {
+		fmt.Fprintf(writer, "  %s %s\t%s\n", cmd.name, cmd.usage, cmd.description)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	return ErrUsage
+}
+
+func (c *CLI) newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+
+	flags.SetOutput(c.out)
+
+	return flags
+}
+
+func (c *CLI) newTabWriter() *tabwriter.Writer {
+	return tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+}
+
+// Static functions
+
+// NewCLI Creates the CLI. The config is loaded with the loaders returned by newLoader, adding the files and overrides
+// set on the global flags. Every app created by the subcommands gets the given options.
+func NewCLI(newLoader func() *config.Loader, out io.Writer, appOptions ...app.Option) *CLI {
+	return &CLI{
+		newLoader:   newLoader,
+		out:         out,
+		appOptions:  appOptions,
+		configFiles: make(stringsFlag, 0),
+		overrides:   make(stringsFlag, 0),
+	}
+}
+
+// newSignalContext Returns a context which is cancelled when the process receives any of the signals.
+func newSignalContext(signals ...os.Signal) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	received := make(chan os.Signal, 1)
+
+	signal.Notify(received, signals...)
+
+	go func() {
+		defer signal.Stop(received)
+
+		select {
+		case <-received:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// newAppError Turns the error into one suitable to be shown to the user, including the validation errors, if any.
+func newAppError(appErr *apperror.AppError) error {
+	messages := []string{appErr.Message}
+
+	for _, validationError := range appErr.GetErrors() {
+		messages = append(messages, fmt.Sprintf("%s: %s", validationError.Field, validationError.Message))
+	}
+
+	if len(messages) < 2 && appErr.Err != nil {
+		messages = append(messages, appErr.Err.Error())
+	}
+
+	return errors.New(strings.Join(messages, "\n  - "))
+}
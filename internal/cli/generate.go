@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/comfortablynumb/goginrestapi/internal/generator"
+)
+
+// generate Writes a CRUD module for the entity defined on the given file. It doesn't need the database. Relative
+// migration paths are resolved from the directory of the project.
+func (c *CLI) generate(args []string) error {
+	flags := c.newFlagSet("generate")
+	dir := flags.String("dir", ".", "Directory of the project, the one with the go.mod file")
+	force := flags.Bool("force", false, "Overwrites the files if they already exist")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(c.out, "Usage: generate [-dir DIR] [-force] FILE")
+
+		return ErrUsage
+	}
+
+	definition, err := generator.LoadDefinition(flags.Arg(0))
+
+	if err != nil {
+		return err
+	}
+
+	appConfig, err := c.loadConfig()
+
+	if err != nil {
+		return err
+	}
+
+	migrationsDir := strings.TrimPrefix(appConfig.Db.MigrationsPath, "file://")
+
+	if !filepath.IsAbs(migrationsDir) {
+		migrationsDir = filepath.Join(*dir, migrationsDir)
+	}
+
+	paths, err := generator.NewGenerator(*dir, migrationsDir, *force).Generate(definition)
+
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		fmt.Fprintf(c.out, "Created %s\n", path)
+	}
+
+	fmt.Fprintf(c.out, "\nNext steps:\n")
+	fmt.Fprintf(c.out, "  1. Add the module of internal/module/%s.go to the app, on createModuleManager or with app.WithModules\n", definition.Name)
+	fmt.Fprintf(c.out, "  2. Run \"swag init\" to add its routes to the docs\n")
+	fmt.Fprintf(c.out, "  3. Run \"go test ./...\"\n")
+
+	return nil
+}
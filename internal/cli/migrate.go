@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/comfortablynumb/goginrestapi/internal/generator"
+	"github.com/golang-migrate/migrate/v4"
+)
+
+// Variables
+
+var (
+	migrationNameRegexp = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+func (c *CLI) migrate(args []string) error {
+	return c.runCommand("migrate", args, []*command{
+		{name: "up", usage: "", description: "Executes every pending migration", run: func(args []string) error {
+			return c.runMigration(args, 0, func(m *migrate.Migrate, _ int) error {
+				return m.Up()
+			})
+		}},
+		{name: "down", usage: "[N]", description: "Reverts the last N migrations, or every migration if N is not set", run: func(args []string) error {
+			if len(args) < 1 {
+				return c.runMigration(args, 0, func(m *migrate.Migrate, _ int) error {
+					return m.Down()
+				})
+			}
+
+			return c.runMigration(args, 1, func(m *migrate.Migrate, steps int) error {
+				return m.Steps(-steps)
+			})
+		}},
+		{name: "goto", usage: "N", description: "Migrates up or down to version N", run: func(args []string) error {
+			return c.runMigration(args, 1, func(m *migrate.Migrate, version int) error {
+				return m.Migrate(uint(version))
+			})
+		}},
+		{name: "force", usage: "N", description: "Sets version N without executing migrations, clearing the dirty flag", run: func(args []string) error {
+			return c.runMigration(args, 1, func(m *migrate.Migrate, version int) error {
+				return m.Force(version)
+			})
+		}},
+		{name: "version", usage: "", description: "Prints the current version", run: func(args []string) error {
+			return c.runMigration(args, 0, func(m *migrate.Migrate, _ int) error {
+				return nil
+			})
+		}},
+		{name: "create", usage: "NAME", description: "Creates the up and down files of a new migration", run: c.createMigration},
+	})
+}
+
+// runMigration Executes the migration and prints the resulting version. If argCount is 1, the argument is parsed as
+// an integer and passed to the migration.
+func (c *CLI) runMigration(args []string, argCount int, fn func(m *migrate.Migrate, arg int) error) error {
+	if len(args) != argCount {
+		return ErrUsage
+	}
+
+	arg := 0
+
+	if argCount > 0 {
+		parsed, err := strconv.Atoi(args[0])
+
+		if err != nil || parsed < 0 {
+			return fmt.Errorf("invalid number: %s", args[0])
+		}
+
+		arg = parsed
+	}
+
+	application, err := c.createApp(false)
+
+	if err != nil {
+		return err
+	}
+
+	defer application.Close()
+
+	migrations := application.GetComponentRegistry().Migrations
+
+	if err := fn(migrations, arg); err != nil && err != migrate.ErrNoChange {
+		return err
+	}
+
+	version, dirty, err := migrations.Version()
+
+	if err == migrate.ErrNilVersion {
+		fmt.Fprintln(c.out, "No migrations applied")
+
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		fmt.Fprintf(c.out, "Version: %d (dirty)\n", version)
+	} else {
+		fmt.Fprintf(c.out, "Version: %d\n", version)
+	}
+
+	return nil
+}
+
+// createMigration Creates the files of the next version on the migrations directory. It doesn't need the database.
+func (c *CLI) createMigration(args []string) error {
+	if len(args) != 1 || !migrationNameRegexp.MatchString(args[0]) {
+		fmt.Fprintln(c.out, "Usage: migrate create NAME (lowercase letters, numbers and underscores)")
+
+		return ErrUsage
+	}
+
+	appConfig, err := c.loadConfig()
+
+	if err != nil {
+		return err
+	}
+
+	dir := strings.TrimPrefix(appConfig.Db.MigrationsPath, "file://")
+	version, err := generator.NextMigrationVersion(dir)
+
+	if err != nil {
+		return err
+	}
+
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%d_%s.%s.sql", version, args[0], direction))
+
+		if err := ioutil.WriteFile(path, []byte{}, os.FileMode(0644)); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(c.out, "Created %s\n", path)
+	}
+
+	return nil
+}
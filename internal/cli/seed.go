@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/fixtures"
+	"github.com/comfortablynumb/goginrestapi/internal/module"
+)
+
+// seed Loads the fixtures of an environment (by default, the one set on the config), or the given files. Fixtures are
+// upserted, so it can be executed many times.
+func (c *CLI) seed(args []string) error {
+	flags := c.newFlagSet("seed")
+	environment := flags.String("env", "", "Environment whose fixtures are loaded. Default: the environment of the config")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	application, err := c.createApp(true)
+
+	if err != nil {
+		return err
+	}
+
+	defer application.Close()
+
+	loader, err := componentregistry.Get[fixtures.Loader](application.GetComponentRegistry(), module.FixturesLoaderComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	files := flags.Args()
+
+	if len(files) < 1 {
+		if *environment == "" {
+			*environment = application.GetConfig().Environment
+		}
+
+		files, err = loader.GetEnvironmentFiles(*environment)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, file := range files {
+		result, err := loader.LoadFile(file)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(c.out, "Loaded %s: %s\n", file, result)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+)
+
+func (c *CLI) tenants(args []string) error {
+	return c.runCommand("tenant", args, []*command{
+		{name: "create", usage: "NAME [-disabled]", description: "Creates a tenant", run: c.createTenant},
+		{name: "disable", usage: "NAME", description: "Disables a tenant. Its requests are rejected from then on", run: c.disableTenant},
+		{name: "list", usage: "", description: "Lists tenants", run: c.listTenants},
+		{name: "token", usage: "NAME [-ttl DURATION]", description: "Issues a token which resolves the tenant, to be sent as \"Authorization: Bearer <token>\"", run: c.issueTenantToken},
+	})
+}
+
+func (c *CLI) createTenant(args []string) error {
+	if len(args) < 1 {
+		return ErrUsage
+	}
+
+	flags := c.newFlagSet("tenant create")
+	disabled := flags.Bool("disabled", false, "Creates the tenant disabled")
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	application, err := c.createApp(true)
+
+	if err != nil {
+		return err
+	}
+
+	defer application.Close()
+
+	componentRegistry := application.GetComponentRegistry()
+	ctx := componentRegistry.RequestContextFactory.NewBackgroundRequestContext()
+
+	tenant, appErr := componentRegistry.TenantService.Create(ctx, &resource.TenantCreateResource{Name: args[0], Disabled: *disabled})
+
+	if appErr != nil {
+		return newAppError(appErr)
+	}
+
+	return c.printTenants([]*resource.TenantResource{tenant})
+}
+
+func (c *CLI) disableTenant(args []string) error {
+	if len(args) != 1 {
+		return ErrUsage
+	}
+
+	application, err := c.createApp(true)
+
+	if err != nil {
+		return err
+	}
+
+	defer application.Close()
+
+	componentRegistry := application.GetComponentRegistry()
+	ctx := componentRegistry.RequestContextFactory.NewBackgroundRequestContext()
+
+	tenant, appErr := componentRegistry.TenantService.Update(ctx, &resource.TenantUpdateResource{Name: args[0], Disabled: true})
+
+	if appErr != nil {
+		return newAppError(appErr)
+	}
+
+	return c.printTenants([]*resource.TenantResource{tenant})
+}
+
+func (c *CLI) listTenants(args []string) error {
+	if err := c.newFlagSet("tenant list").Parse(args); err != nil {
+		return err
+	}
+
+	application, err := c.createApp(true)
+
+	if err != nil {
+		return err
+	}
+
+	defer application.Close()
+
+	componentRegistry := application.GetComponentRegistry()
+	ctx := componentRegistry.RequestContextFactory.NewBackgroundRequestContext()
+
+	tenants, appErr := componentRegistry.TenantService.Find(ctx)
+
+	if appErr != nil {
+		return newAppError(appErr)
+	}
+
+	return c.printTenants(tenants)
+}
+
+// issueTenantToken Tokens are signed with auth.token_secret, so the server only accepts them if it's configured with
+// the same one.
+func (c *CLI) issueTenantToken(args []string) error {
+	if len(args) < 1 {
+		return ErrUsage
+	}
+
+	flags := c.newFlagSet("tenant token")
+	ttl := flags.Duration("ttl", 30*24*time.Hour, "How long the token is valid for")
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	application, err := c.createApp(true)
+
+	if err != nil {
+		return err
+	}
+
+	defer application.Close()
+
+	componentRegistry := application.GetComponentRegistry()
+	ctx := componentRegistry.RequestContextFactory.NewBackgroundRequestContext()
+
+	tenantToken, appErr := componentRegistry.TenantService.IssueToken(ctx, args[0], *ttl)
+
+	if appErr != nil {
+		return newAppError(appErr)
+	}
+
+	fmt.Fprintln(c.out, tenantToken)
+
+	return nil
+}
+
+func (c *CLI) printTenants(tenants []*resource.TenantResource) error {
+	writer := c.newTabWriter()
+
+	fmt.Fprintln(writer, "NAME\tDISABLED\tCREATED AT\tUPDATED AT")
+
+	for _, tenant := range tenants {
+		fmt.Fprintf(writer, "%s\t%t\t%s\t%s\n", tenant.Name, tenant.Disabled, tenant.CreatedAt.Format(time.RFC3339), tenant.UpdatedAt.Format(time.RFC3339))
+	}
+
+	return writer.Flush()
+}
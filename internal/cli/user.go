@@ -0,0 +1,403 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/app"
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/module"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+)
+
+func (c *CLI) user(args []string) error {
+	return c.runCommand("user", args, []*command{
+		{name: "create", usage: "-username NAME -user-type NAME [-disabled] [-email EMAIL] [-display-name NAME] [-locale LOCALE] [-timezone TIMEZONE] [-password PASSWORD]", description: "Creates a user", run: c.createUser},
+		{name: "disable", usage: "USERNAME", description: "Disables a user", run: c.disableUser},
+		{name: "list", usage: "[-username NAME] [-offset N] [-limit N]", description: "Lists users", run: c.listUsers},
+		{name: "unlock", usage: "USERNAME", description: "Unlocks a user locked out by failed logins", run: c.unlockUser},
+		{name: "disable-mfa", usage: "USERNAME", description: "Disables the second factor of a user", run: c.disableUserMfa},
+	})
+}
+
+func (c *CLI) userType(args []string) error {
+	return c.runCommand("user-type", args, []*command{
+		{name: "create", usage: "NAME [-disabled] [-requires-mfa]", description: "Creates a user type", run: c.createUserType},
+		{name: "disable", usage: "NAME", description: "Disables a user type", run: c.disableUserType},
+		{name: "list", usage: "[-name NAME] [-offset N] [-limit N]", description: "Lists user types", run: c.listUserTypes},
+	})
+}
+
+func (c *CLI) createUser(args []string) error {
+	flags := c.newFlagSet("user create")
+	username := flags.String("username", "", "Username")
+	userTypeName := flags.String("user-type", "", "Name of the user type")
+	disabled := flags.Bool("disabled", false, "Creates the user disabled")
+	email := flags.String("email", "", "Email of the user. A verification email is sent to it")
+	displayName := flags.String("display-name", "", "Display name of the user")
+	locale := flags.String("locale", "", "Locale of the user. Default: the default locale of the app")
+	timezone := flags.String("timezone", "", "Timezone of the user. Default: UTC")
+	password := flags.String("password", "", "Password of the user. Without it, the user can't log in")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	application, err := c.createApp(true)
+
+	if err != nil {
+		return err
+	}
+
+	defer application.Close()
+
+	ctx, userService, _, err := c.getUserServices(application)
+
+	if err != nil {
+		return err
+	}
+
+	user, appErr := userService.Create(ctx, &resource.UserCreateResource{
+		Username:     *username,
+		UserTypeName: *userTypeName,
+		Disabled:     *disabled,
+		Email:        *email,
+		DisplayName:  *displayName,
+		Locale:       *locale,
+		Timezone:     *timezone,
+		Password:     *password,
+	})
+
+	if appErr != nil {
+		return newAppError(appErr)
+	}
+
+	return c.printUsers([]*resource.UserResource{user})
+}
+
+func (c *CLI) disableUser(args []string) error {
+	if len(args) != 1 {
+		return ErrUsage
+	}
+
+	application, err := c.createApp(true)
+
+	if err != nil {
+		return err
+	}
+
+	defer application.Close()
+
+	ctx, userService, _, err := c.getUserServices(application)
+
+	if err != nil {
+		return err
+	}
+
+	users, appErr := userService.Find(ctx, &resource.UserFindResource{Username: &args[0]})
+
+	if appErr != nil {
+		return newAppError(appErr)
+	}
+
+	if len(users) < 1 {
+		return newAppError(apperror.NewModelNotFoundAppError(ctx, nil, Name))
+	}
+
+	user, appErr := userService.Update(ctx, &resource.UserUpdateResource{
+		Username:     users[0].Username,
+		UserTypeName: users[0].UserType.Name,
+		Disabled:     true,
+		Email:        users[0].Email,
+		DisplayName:  users[0].DisplayName,
+		Locale:       users[0].Locale,
+		Timezone:     users[0].Timezone,
+	})
+
+	if appErr != nil {
+		return newAppError(appErr)
+	}
+
+	return c.printUsers([]*resource.UserResource{user})
+}
+
+func (c *CLI) unlockUser(args []string) error {
+	if len(args) != 1 {
+		return ErrUsage
+	}
+
+	application, err := c.createApp(true)
+
+	if err != nil {
+		return err
+	}
+
+	defer application.Close()
+
+	ctx, userService, _, err := c.getUserServices(application)
+
+	if err != nil {
+		return err
+	}
+
+	user, appErr := userService.Unlock(ctx, &resource.UserUnlockResource{Username: args[0]})
+
+	if appErr != nil {
+		return newAppError(appErr)
+	}
+
+	return c.printUsers([]*resource.UserResource{user})
+}
+
+func (c *CLI) disableUserMfa(args []string) error {
+	if len(args) != 1 {
+		return ErrUsage
+	}
+
+	application, err := c.createApp(true)
+
+	if err != nil {
+		return err
+	}
+
+	defer application.Close()
+
+	ctx, userService, _, err := c.getUserServices(application)
+
+	if err != nil {
+		return err
+	}
+
+	user, appErr := userService.DisableMfa(ctx, &resource.UserDisableMfaResource{Username: args[0]})
+
+	if appErr != nil {
+		return newAppError(appErr)
+	}
+
+	return c.printUsers([]*resource.UserResource{user})
+}
+
+func (c *CLI) listUsers(args []string) error {
+	flags := c.newFlagSet("user list")
+	username := flags.String("username", "", "Only list the user with this username")
+	offset := flags.Int("offset", 0, "Starts results from this offset")
+	limit := flags.Int("limit", 50, "Limits the amount of results")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	application, err := c.createApp(true)
+
+	if err != nil {
+		return err
+	}
+
+	defer application.Close()
+
+	ctx, userService, _, err := c.getUserServices(application)
+
+	if err != nil {
+		return err
+	}
+
+	userFindResource := &resource.UserFindResource{}
+
+	userFindResource.Offset = offset
+	userFindResource.Limit = limit
+
+	if *username != "" {
+		userFindResource.Username = username
+	}
+
+	users, appErr := userService.Find(ctx, userFindResource)
+
+	if appErr != nil {
+		return newAppError(appErr)
+	}
+
+	return c.printUsers(users)
+}
+
+func (c *CLI) createUserType(args []string) error {
+	if len(args) < 1 {
+		return ErrUsage
+	}
+
+	flags := c.newFlagSet("user-type create")
+	disabled := flags.Bool("disabled", false, "Creates the user type disabled")
+	requiresMfa := flags.Bool("requires-mfa", false, "Users of the type must log in with a second factor")
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	application, err := c.createApp(true)
+
+	if err != nil {
+		return err
+	}
+
+	defer application.Close()
+
+	ctx, _, userTypeService, err := c.getUserServices(application)
+
+	if err != nil {
+		return err
+	}
+
+	userType, appErr := userTypeService.Create(ctx, &resource.UserTypeCreateResource{Name: args[0], Disabled: *disabled, RequiresMfa: *requiresMfa})
+
+	if appErr != nil {
+		return newAppError(appErr)
+	}
+
+	return c.printUserTypes([]*resource.UserTypeResource{userType})
+}
+
+func (c *CLI) disableUserType(args []string) error {
+	if len(args) != 1 {
+		return ErrUsage
+	}
+
+	application, err := c.createApp(true)
+
+	if err != nil {
+		return err
+	}
+
+	defer application.Close()
+
+	ctx, _, userTypeService, err := c.getUserServices(application)
+
+	if err != nil {
+		return err
+	}
+
+	userType, appErr := userTypeService.FindOneByName(ctx, args[0])
+
+	if appErr != nil {
+		return newAppError(appErr)
+	}
+
+	userType, appErr = userTypeService.Update(ctx, &resource.UserTypeUpdateResource{
+		OriginalName: userType.Name,
+		Name:         userType.Name,
+		Disabled:     true,
+		RequiresMfa:  userType.RequiresMfa,
+	})
+
+	if appErr != nil {
+		return newAppError(appErr)
+	}
+
+	return c.printUserTypes([]*resource.UserTypeResource{userType})
+}
+
+func (c *CLI) listUserTypes(args []string) error {
+	flags := c.newFlagSet("user-type list")
+	name := flags.String("name", "", "Only list the user type with this name")
+	offset := flags.Int("offset", 0, "Starts results from this offset")
+	limit := flags.Int("limit", 50, "Limits the amount of results")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	application, err := c.createApp(true)
+
+	if err != nil {
+		return err
+	}
+
+	defer application.Close()
+
+	ctx, _, userTypeService, err := c.getUserServices(application)
+
+	if err != nil {
+		return err
+	}
+
+	userTypeFindResource := &resource.UserTypeFindResource{}
+
+	userTypeFindResource.Offset = offset
+	userTypeFindResource.Limit = limit
+
+	if *name != "" {
+		userTypeFindResource.Name = name
+	}
+
+	userTypes, appErr := userTypeService.Find(ctx, userTypeFindResource)
+
+	if appErr != nil {
+		return newAppError(appErr)
+	}
+
+	return c.printUserTypes(userTypes.Data)
+}
+
+// getUserServices Changes made from the CLI are recorded as made by the system actor, on the tenant set with the
+// -tenant flag (or the default one of the config).
+func (c *CLI) getUserServices(application app.App) (*context.RequestContext, service.UserService, service.UserTypeService, error) {
+	componentRegistry := application.GetComponentRegistry()
+	ctx := componentRegistry.RequestContextFactory.NewBackgroundRequestContext()
+	name := c.tenant
+
+	if name == "" {
+		name = application.GetConfig().Tenancy.DefaultTenant
+	}
+
+	if name == "" {
+		return nil, nil, nil, errors.New("there's no default tenant, so it must be set with -tenant")
+	}
+
+	tenant, appErr := componentRegistry.TenantService.Resolve(ctx, name)
+
+	if appErr != nil {
+		return nil, nil, nil, newAppError(appErr)
+	}
+
+	ctx.SetTenant(tenant.ID, tenant.Name)
+
+	userService, err := componentregistry.Get[service.UserService](componentRegistry, module.UserServiceComponentName)
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	userTypeService, err := componentregistry.Get[service.UserTypeService](componentRegistry, module.UserTypeServiceComponentName)
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return ctx, userService, userTypeService, nil
+}
+
+func (c *CLI) printUsers(users []*resource.UserResource) error {
+	writer := c.newTabWriter()
+
+	fmt.Fprintln(writer, "USERNAME\tUSER TYPE\tDISABLED\tCREATED AT\tUPDATED AT")
+
+	for _, user := range users {
+		fmt.Fprintf(writer, "%s\t%s\t%t\t%s\t%s\n", user.Username, user.UserType.Name, user.Disabled, user.CreatedAt.Format(time.RFC3339), user.UpdatedAt.Format(time.RFC3339))
+	}
+
+	return writer.Flush()
+}
+
+func (c *CLI) printUserTypes(userTypes []*resource.UserTypeResource) error {
+	writer := c.newTabWriter()
+
+	fmt.Fprintln(writer, "NAME\tDISABLED\tCREATED AT\tUPDATED AT")
+
+	for _, userType := range userTypes {
+		fmt.Fprintf(writer, "%s\t%t\t%s\t%s\n", userType.Name, userType.Disabled, userType.CreatedAt.Format(time.RFC3339), userType.UpdatedAt.Format(time.RFC3339))
+	}
+
+	return writer.Flush()
+}
@@ -2,12 +2,25 @@ package componentregistry
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
+	"reflect"
 
+	"github.com/comfortablynumb/goginrestapi/internal/cache"
+
+	"github.com/comfortablynumb/goginrestapi/internal/config"
 	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/errorreport"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/i18n"
+	"github.com/comfortablynumb/goginrestapi/internal/jobs"
+	"github.com/comfortablynumb/goginrestapi/internal/mailer"
+	"github.com/comfortablynumb/goginrestapi/internal/metrics"
+	"github.com/comfortablynumb/goginrestapi/internal/ratelimit"
+	"github.com/comfortablynumb/goginrestapi/internal/redis"
 	"github.com/comfortablynumb/goginrestapi/internal/service"
-	ut "github.com/go-playground/universal-translator"
+	"github.com/comfortablynumb/goginrestapi/internal/token"
+	"github.com/comfortablynumb/goginrestapi/internal/worker"
+	"github.com/gin-gonic/gin"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/rs/zerolog"
 	"gopkg.in/go-playground/validator.v9"
@@ -18,14 +31,73 @@ import (
 type ComponentRegistry struct {
 	Db                    *sql.DB
 	Migrations            *migrate.Migrate
+	Redis                 *redis.Client
 	Validator             *validator.Validate
 	Logger                *zerolog.Logger
-	Translator            *ut.UniversalTranslator
+	Translator            *i18n.Translator
 	RequestContextFactory *context.RequestContextFactory
+	RuntimeConfig         *config.Runtime
+	Metrics               *metrics.Registry
+	ErrorReporter         *errorreport.Reporter
+	Mailer                mailer.Mailer
+	TokenSigner           *token.Signer
+
+	TimeService        service.TimeService
+	TransactionService service.TransactionService
+	EventService       service.EventService
+	CacheService       service.CacheService
+	TenantService      service.TenantService
+
+	CacheManager   *cache.Manager
+	RateLimitStore ratelimit.Store
+
+	EventBus        *events.Bus
+	EventDispatcher *events.Dispatcher
+
+	JobScheduler *jobs.Scheduler
+
+	Components    map[string]interface{}
+	Workers       []worker.Worker
+	ShutdownHooks []func()
+
+	rateLimiterFactory func(group string) gin.HandlerFunc
+}
+
+// AddWorker Registers a background worker. Workers are started in order when the app starts running, and stopped in
+// reverse order when it shuts down.
+func (c *ComponentRegistry) AddWorker(w worker.Worker) *ComponentRegistry {
+	c.Workers = append(c.Workers, w)
+
+	return c
+}
+
+// AddShutdownHook Registers a function called as soon as the server starts shutting down, before waiting for the
+// active connections to finish. Use it to end long-lived connections, like streams.
+func (c *ComponentRegistry) AddShutdownHook(hook func()) *ComponentRegistry {
+	c.ShutdownHooks = append(c.ShutdownHooks, hook)
+
+	return c
+}
+
+// SetRateLimiterFactory Sets the function which creates the rate limiting middleware of a route group.
+func (c *ComponentRegistry) SetRateLimiterFactory(factory func(group string) gin.HandlerFunc) *ComponentRegistry {
+	c.rateLimiterFactory = factory
+
+	return c
+}
 
-	TimeService service.TimeService
+// GetRateLimiter Returns the middleware limiting the requests of a route group, to be used by modules when they set up
+// their routes. If the group has no limits, the middleware does nothing.
+func (c *ComponentRegistry) GetRateLimiter(group string) gin.HandlerFunc {
+	if c.rateLimiterFactory != nil {
+		if handler := c.rateLimiterFactory(group); handler != nil {
+			return handler
+		}
+	}
 
-	Components map[string]interface{}
+	return func(c *gin.Context) {
+		c.Next()
+	}
 }
 
 func (c *ComponentRegistry) Set(name string, component interface{}) *ComponentRegistry {
@@ -38,26 +110,37 @@ func (c *ComponentRegistry) Get(name string) (interface{}, error) {
 	component, found := c.Components[name]
 
 	if !found {
-		return nil, errors.New(fmt.Sprintf("Component '%s' is not registered in the component registry.", name))
+		return nil, fmt.Errorf("component '%s' is not registered in the component registry", name)
 	}
 
 	return component, nil
 }
 
-func (c *ComponentRegistry) GetOrPanic(name string) interface{} {
+// Static functions
+
+// Get Returns the component registered with the given name, which must be of type T.
+func Get[T any](c *ComponentRegistry, name string) (T, error) {
+	var res T
+
 	component, err := c.Get(name)
 
 	if err != nil {
-		panic(err)
+		return res, err
 	}
 
-	return component
-}
+	res, ok := component.(T)
 
-// Static functions
+	if !ok {
+		return res, fmt.Errorf("component '%s' is a %T, not a %s", name, component, reflect.TypeOf(&res).Elem())
+	}
+
+	return res, nil
+}
 
 func NewComponentRegistry() *ComponentRegistry {
 	return &ComponentRegistry{
-		Components: make(map[string]interface{}),
+		Components:    make(map[string]interface{}),
+		Workers:       make([]worker.Worker, 0),
+		ShutdownHooks: make([]func(), 0),
 	}
 }
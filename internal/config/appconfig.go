@@ -1,14 +1,371 @@
 package config
 
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Constants
+
+const (
+	EnvPrefix = "MYAPP"
+
+	RedactedValue = "******"
+
+	ErrorsDefaultFormat = "default"
+	ErrorsProblemFormat = "problem"
+
+	MailerOutbox = "outbox"
+	MailerLog    = "log"
+
+	MemoryBackend = "memory"
+	RedisBackend  = "redis"
+)
+
 // Structs
 
+// AppConfig Configuration of the app. Every field is read, from lowest to highest precedence, from the default tag,
+// the config files, the environment variables and the CLI flags. See Loader for details. Fields tagged with
+// `reload:"true"` can be changed while the app runs. See Runtime for details. Rate limits are counted on each instance of
+// the app if RateLimitStore is "memory", and between every instance (through the Redis server set on Redis) if it's
+// "redis".
 type AppConfig struct {
-	Port             int    `default:"8080"`
-	LogLevel         string `default:"DEBUG"`
-	DbUri            string `default:"file:test.db?cache=shared&mode=memory"`
-	DbMigrationsPath string `default:"file://database/migrations"`
-	DefaultLocale    string `default:"en"`
-	DefaultLimit     int    `default:"50"`
+	Environment    string            `yaml:"environment" default:"development" validate:"required"`
+	DefaultLocale  string            `yaml:"default_locale" default:"en" validate:"required"`
+	DefaultLimit   int               `yaml:"default_limit" default:"50" validate:"min=1"`
+	Server         ServerConfig      `yaml:"server"`
+	Db             DbConfig          `yaml:"db"`
+	Log            LogConfig         `yaml:"log"`
+	Errors         ErrorsConfig      `yaml:"errors"`
+	Auth           AuthConfig        `yaml:"auth"`
+	Mail           MailConfig        `yaml:"mail"`
+	Cors           CorsConfig        `yaml:"cors"`
+	Cache          CacheConfig       `yaml:"cache"`
+	Redis          RedisConfig       `yaml:"redis"`
+	Events         EventsConfig      `yaml:"events"`
+	Jobs           JobsConfig        `yaml:"jobs"`
+	Tenancy        TenancyConfig     `yaml:"tenancy"`
+	Fixtures       FixturesConfig    `yaml:"fixtures"`
+	RateLimits     map[string]string `yaml:"rate_limits" default:"user:120/1m" validate:"dive,rate_limit" reload:"true"`
+	RateLimitStore string            `yaml:"rate_limit_store" default:"memory" validate:"oneof=memory redis"`
+	Features       map[string]bool   `yaml:"features" reload:"true"`
+
+	sources  *sources
+	sections map[string]interface{}
+}
+
+// ServerConfig Write timeout is disabled by default, as it would end the streams. Requests are served over TLS if
+// the certificate and key files are set, and over HTTP/2 without TLS (h2c) if H2C is true.
+type ServerConfig struct {
+	Host              string        `yaml:"host"`
+	Port              int           `yaml:"port" default:"8080" validate:"min=1,max=65535"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" default:"30s" validate:"min=0"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" default:"10s" validate:"min=0"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" default:"0s" validate:"min=0"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" default:"2m" validate:"min=0"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" default:"1048576" validate:"min=4096"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" default:"15s" validate:"gt=0"`
+	H2C               bool          `yaml:"h2c"`
+	TLS               TLSConfig     `yaml:"tls"`
+	Admin             AdminConfig   `yaml:"admin"`
+}
+
+// TLSConfig The certificate files are checked for changes every ReloadInterval, so renewed certificates are used
+// without restarting the app. Client certificates are requested and verified depending on ClientAuth. The identity
+// of the verified ones is read from the field set on ClientIdentity.
+type TLSConfig struct {
+	CertFile       string        `yaml:"cert_file"`
+	KeyFile        string        `yaml:"key_file"`
+	ClientCAFile   string        `yaml:"client_ca_file"`
+	ClientAuth     string        `yaml:"client_auth" default:"none" validate:"oneof=none request require verify_if_given require_and_verify"`
+	ClientIdentity string        `yaml:"client_identity" default:"common_name" validate:"oneof=common_name email uri dns"`
+	MinVersion     string        `yaml:"min_version" default:"1.2" validate:"oneof=1.2 1.3"`
+	ReloadInterval time.Duration `yaml:"reload_interval" default:"1m" validate:"gt=0"`
+}
+
+// IsEnabled Returns whether requests must be served over TLS.
+func (c TLSConfig) IsEnabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// AdminConfig If Port is set, metrics, health checks and (if Pprof is true) profiling are served on their own
+// listener, instead of the public one.
+type AdminConfig struct {
+	Host  string `yaml:"host"`
+	Port  int    `yaml:"port" validate:"min=0,max=65535"`
+	Pprof bool   `yaml:"pprof"`
+}
+
+// IsEnabled Returns whether the admin listener must be started.
+func (c AdminConfig) IsEnabled() bool {
+	return c.Port > 0
+}
+
+type DbConfig struct {
+	Uri            string `yaml:"uri" default:"file:test.db?cache=shared&mode=memory" validate:"required" secret:"true"`
+	MigrationsPath string `yaml:"migrations_path" default:"file://database/migrations" validate:"required"`
+	AutoMigrate    bool   `yaml:"auto_migrate" default:"true"`
+}
+
+type LogConfig struct {
+	Level string `yaml:"level" default:"debug" validate:"log_level" reload:"true"`
+}
+
+// ErrorsConfig Errors are rendered as RFC 7807 problem details if Format is "problem", or if the request accepts
+// "application/problem+json". The type of a problem is TypeBaseUrl followed by its error code, which is documented
+// by the error catalog of the app. Unexpected errors (like panics) are appended to ReportFilePath, if it's set.
+type ErrorsConfig struct {
+	Format         string `yaml:"format" default:"default" validate:"oneof=default problem"`
+	TypeBaseUrl    string `yaml:"type_base_url" default:"/errors/" validate:"required"`
+	ReportFilePath string `yaml:"report_file_path"`
+}
+
+// IsProblemFormat Returns whether errors must always be rendered as problem details.
+func (c ErrorsConfig) IsProblemFormat() bool {
+	return c.Format == ErrorsProblemFormat
+}
+
+// AuthConfig The actor of each request (recorded on the audit log, among others) is the identity of its verified client
+// certificate or, if there's none, the value of ActorHeader. That header is sent by the client, so anyone can forge
+// it: only trust it (TrustActorHeader) if the app is behind a gateway which authenticates the users and sets it.
+// Otherwise, requests without a verified client certificate are made by the anonymous actor. Clients can also identify
+// themselves (for example, to get their own rate limits) sending one of the APIKeys, keyed by the name of the client,
+// on the X-API-Key header. Keys which are not on it are ignored.
+//
+// Tokens sent to the users (like the email verification ones) are signed with TokenSecret. If it's not
+// set, a random one is used, so the tokens are no longer valid once the app is restarted. Users are locked out for
+// LockoutDuration after MaxFailedLogins failed logins in a row. TOTP codes of up to MfaSkew time steps before or after
+// the current one are accepted, and RecoveryCodes codes are created when users enable their second factor. Expired
+// and used password reset tokens are deleted on PurgeSchedule.
+type AuthConfig struct {
+	ActorHeader          string            `yaml:"actor_header" default:"X-Actor" validate:"required"`
+	TrustActorHeader     bool              `yaml:"trust_actor_header" default:"true"`
+	APIKeys              map[string]string `yaml:"api_keys" validate:"dive,keys,required,endkeys,required" secret:"true"`
+	TokenSecret          string            `yaml:"token_secret" secret:"true"`
+	EmailVerificationTTL time.Duration     `yaml:"email_verification_ttl" default:"24h" validate:"gt=0"`
+	ResetTokenTTL        time.Duration     `yaml:"reset_token_ttl" default:"1h" validate:"gt=0"`
+	MaxFailedLogins      int               `yaml:"max_failed_logins" default:"5" validate:"min=1"`
+	LockoutDuration      time.Duration     `yaml:"lockout_duration" default:"15m" validate:"gt=0"`
+	MfaIssuer            string            `yaml:"mfa_issuer" default:"goginrestapi" validate:"required"`
+	MfaSkew              int               `yaml:"mfa_skew" default:"1" validate:"min=0,max=10"`
+	RecoveryCodes        int               `yaml:"recovery_codes" default:"10" validate:"min=1,max=50"`
+	PurgeSchedule        string            `yaml:"purge_schedule" default:"@hourly" validate:"required"`
+}
+
+// MailConfig The "outbox" mailer writes every message as a file on OutboxDir, and the "log" one logs them. Both are
+// meant for development and testing: set your own mailer on the component registry to actually send them.
+type MailConfig struct {
+	Mailer    string `yaml:"mailer" default:"log" validate:"oneof=outbox log"`
+	OutboxDir string `yaml:"outbox_dir" default:"var/outbox" validate:"required"`
+	From      string `yaml:"from" default:"no-reply@example.com" validate:"required,email"`
+}
+
+// CorsConfig Cross-origin requests are only allowed from AllowedOrigins. "*" allows any origin.
+type CorsConfig struct {
+	AllowedOrigins []string      `yaml:"allowed_origins" validate:"dive,required" reload:"true"`
+	AllowedHeaders []string      `yaml:"allowed_headers" default:"Authorization,Content-Type,Idempotency-Key,X-Actor,X-API-Key,X-Request-ID,X-Tenant"`
+	MaxAge         time.Duration `yaml:"max_age" default:"12h" validate:"min=0"`
+}
+
+// CacheConfig The "memory" backend keeps up to MaxEntries entries on each instance of the app, while the "redis" one
+// shares them between every instance through the Redis server set on RedisConfig.
+type CacheConfig struct {
+	Backend       string        `yaml:"backend" default:"memory" validate:"oneof=memory redis"`
+	MaxEntries    int           `yaml:"max_entries" default:"10000" validate:"min=1"`
+	RepositoryTTL time.Duration `yaml:"repository_ttl" default:"5m" validate:"gt=0"`
+	ResponseTTL   time.Duration `yaml:"response_ttl" default:"30s" validate:"gt=0"`
+}
+
+// RedisConfig Redis server used by the backends set to "redis". Every key is prefixed with Prefix, so several apps can
+// share the same database.
+type RedisConfig struct {
+	Address     string        `yaml:"address" default:"localhost:6379" validate:"required"`
+	Password    string        `yaml:"password" secret:"true"`
+	DB          int           `yaml:"db" validate:"min=0"`
+	Prefix      string        `yaml:"prefix" default:"goginrestapi:"`
+	Timeout     time.Duration `yaml:"timeout" default:"5s" validate:"gt=0"`
+	PoolSize    int           `yaml:"pool_size" default:"10" validate:"min=1"`
+	IdleTimeout time.Duration `yaml:"idle_timeout" default:"5m" validate:"gt=0"`
+}
+
+type EventsConfig struct {
+	DispatchInterval time.Duration `yaml:"dispatch_interval" default:"1s" validate:"gt=0"`
+	MaxAttempts      int           `yaml:"max_attempts" default:"10" validate:"min=1"`
+	FileSinkPath     string        `yaml:"file_sink_path"`
+	WebhookSinkUrl   string        `yaml:"webhook_sink_url" validate:"omitempty,url" secret:"true"`
+	WebhookTimeout   time.Duration `yaml:"webhook_timeout" default:"10s" validate:"gt=0"`
+}
+
+// JobsConfig The scheduler runs the due jobs every PollInterval, up to Concurrency at the same time. Failed jobs are
+// retried with exponential backoff up to MaxAttempts times. Jobs locked for longer than LockTimeout (usually because
+// the instance running them died) are run again, and the leader lock is taken over by another instance after
+// LeaderLockTTL. Finished jobs are deleted after Retention. If Enabled is false, this instance doesn't run any job.
+type JobsConfig struct {
+	Enabled        bool          `yaml:"enabled" default:"true"`
+	PollInterval   time.Duration `yaml:"poll_interval" default:"1s" validate:"gt=0"`
+	Concurrency    int           `yaml:"concurrency" default:"4" validate:"min=1"`
+	MaxAttempts    int           `yaml:"max_attempts" default:"5" validate:"min=1"`
+	RetryBaseDelay time.Duration `yaml:"retry_base_delay" default:"10s" validate:"gt=0"`
+	RetryMaxDelay  time.Duration `yaml:"retry_max_delay" default:"1h" validate:"gt=0"`
+	LockTimeout    time.Duration `yaml:"lock_timeout" default:"5m" validate:"gt=0"`
+	LeaderLockTTL  time.Duration `yaml:"leader_lock_ttl" default:"30s" validate:"gt=0"`
+	Retention      time.Duration `yaml:"retention" default:"168h" validate:"gt=0"`
+	PurgeSchedule  string        `yaml:"purge_schedule" default:"@daily" validate:"required"`
+}
+
+// TenancyConfig The tenant of each request is resolved from Sources, in order: "token" (a tenant token sent as
+// "Authorization: Bearer <token>"), "header" (the tenant name sent on Header) and "subdomain" (the label right before
+// BaseDomain on the host, like "acme" on "acme.example.com", ignored while BaseDomain is empty). Every source present
+// on the request must resolve the same tenant. Requests without any get DefaultTenant. If it's empty, requests must
+// send a tenant. Only the token is authenticated: anyone can send the header or use the subdomain of any tenant, so
+// those sources are disabled by default, and should only be enabled behind a gateway which sets them after
+// authenticating the caller.
+type TenancyConfig struct {
+	Sources       []string `yaml:"sources" default:"token" validate:"dive,oneof=token header subdomain"`
+	Header        string   `yaml:"header" default:"X-Tenant" validate:"required"`
+	BaseDomain    string   `yaml:"base_domain" validate:"omitempty,hostname_rfc1123"`
+	DefaultTenant string   `yaml:"default_tenant" default:"default"`
+}
+
+type FixturesConfig struct {
+	Path string `yaml:"path" default:"database/fixtures" validate:"required"`
+}
+
+// LoadSection Fills the typed config section of a module, declared with the same tags as AppConfig, from the top
+// level key with its name. Values set on the config files, the environment variables (for example,
+// MYAPP_<SECTION>_<KEY>) or the CLI flags override the defaults of the section, which is validated afterwards.
+func (c *AppConfig) LoadSection(name string, section interface{}) error {
+	if _, found := getSectionKeys(reflect.TypeOf(c).Elem())[name]; found {
+		return fmt.Errorf("config section '%s' is already used by the app", name)
+	}
+
+	if err := c.getSources().decode(name, section); err != nil {
+		return err
+	}
+
+	if c.sections == nil {
+		c.sections = make(map[string]interface{})
+	}
+
+	c.sections[name] = section
+
+	return nil
+}
+
+// GetSection Returns the config section loaded with LoadSection, or nil if it was not loaded.
+func (c *AppConfig) GetSection(name string) interface{} {
+	return c.sections[name]
+}
+
+// Set Sets a value using its dotted key (for example, "webhook.max_attempts"), with the highest precedence. It only
+// affects sections loaded afterwards, so it must be used before the app is set up.
+func (c *AppConfig) Set(key string, value interface{}) *AppConfig {
+	setValue(c.getSources().overrides, strings.Split(key, "."), value)
+
+	return c
+}
+
+// GetUnknownSections Returns the top level keys which are neither part of the app config nor a loaded section, which
+// usually means there's a typo on them.
+func (c *AppConfig) GetUnknownSections() []string {
+	known := getSectionKeys(reflect.TypeOf(c).Elem())
+	res := make([]string, 0)
+
+	for _, key := range c.getSources().getTopLevelKeys() {
+		if _, found := known[key]; found {
+			continue
+		}
+
+		if _, found := c.sections[key]; !found {
+			res = append(res, key)
+		}
+	}
+
+	sort.Strings(res)
+
+	return res
+}
+
+// GetSettings Returns every config value, including the loaded sections, keyed by its dotted key. Values of fields
+// tagged with `secret:"true"` are replaced by RedactedValue when redactSecrets is true, unless they are empty.
+func (c *AppConfig) GetSettings(redactSecrets bool) []*Setting {
+	res := make([]*Setting, 0)
+
+	res = appendSettings(res, "", reflect.ValueOf(c).Elem(), redactSecrets)
+
+	names := make([]string, 0, len(c.sections))
+
+	for name := range c.sections {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		res = appendSettings(res, name+".", reflect.ValueOf(c.sections[name]).Elem(), redactSecrets)
+	}
+
+	return res
+}
+
+// GetFiles Returns the config files it was loaded from, if any.
+func (c *AppConfig) GetFiles() []string {
+	if loader := c.getSources().loader; loader != nil {
+		return loader.GetFiles()
+	}
+
+	return []string{}
+}
+
+// Clone Returns a copy of the config, which can be changed without affecting this one.
+func (c *AppConfig) Clone() *AppConfig {
+	res := *c
+
+	res.RateLimits = make(map[string]string, len(c.RateLimits))
+
+	for group, spec := range c.RateLimits {
+		res.RateLimits[group] = spec
+	}
+
+	res.Features = make(map[string]bool, len(c.Features))
+
+	for feature, enabled := range c.Features {
+		res.Features[feature] = enabled
+	}
+
+	res.Auth.APIKeys = make(map[string]string, len(c.Auth.APIKeys))
+
+	for client, apiKey := range c.Auth.APIKeys {
+		res.Auth.APIKeys[client] = apiKey
+	}
+
+	res.Cors.AllowedOrigins = append([]string{}, c.Cors.AllowedOrigins...)
+	res.Cors.AllowedHeaders = append([]string{}, c.Cors.AllowedHeaders...)
+	res.Tenancy.Sources = append([]string{}, c.Tenancy.Sources...)
+
+	res.sources = c.getSources().clone()
+	res.sections = nil
+
+	return &res
+}
+
+func (c *AppConfig) getSources() *sources {
+	if c.sources == nil {
+		c.sources = newSources()
+	}
+
+	return c.sources
+}
+
+// Setting
+
+type Setting struct {
+	Key        string
+	Value      string
+	Reloadable bool
 }
 
 // Static functions
@@ -16,3 +373,72 @@ type AppConfig struct {
 func NewAppConfig() *AppConfig {
 	return &AppConfig{}
 }
+
+// NewAppConfigFromEnv Returns the config set on the environment variables prefixed with EnvPrefix, using the defaults
+// for the ones which are not set. If MYAPP_CONFIG_FILE is set, the file is loaded too.
+func NewAppConfigFromEnv() (*AppConfig, error) {
+	return NewLoader().Load()
+}
+
+func appendSettings(res []*Setting, prefix string, value reflect.Value, redactSecrets bool) []*Setting {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		key := getKey(field)
+
+		if key == "" {
+			continue
+		}
+
+		if isSection(field.Type) {
+			res = appendSettings(res, prefix+key+".", value.Field(i), redactSecrets)
+
+			continue
+		}
+
+		setting := &Setting{
+			Key:        prefix + key,
+			Value:      formatSettingValue(value.Field(i).Interface()),
+			Reloadable: field.Tag.Get("reload") == "true",
+		}
+
+		if redactSecrets && field.Tag.Get("secret") == "true" && setting.Value != "" {
+			setting.Value = RedactedValue
+		}
+
+		res = append(res, setting)
+	}
+
+	return res
+}
+
+// formatSettingValue Maps and slices are formatted as they are set on the environment variables.
+func formatSettingValue(value interface{}) string {
+	if duration, ok := value.(time.Duration); ok {
+		return duration.String()
+	}
+
+	v := reflect.ValueOf(value)
+
+	switch v.Kind() {
+	case reflect.Map:
+		pairs := make([]string, 0, v.Len())
+
+		for _, key := range v.MapKeys() {
+			pairs = append(pairs, fmt.Sprintf("%v:%v", key.Interface(), v.MapIndex(key).Interface()))
+		}
+
+		sort.Strings(pairs)
+
+		return strings.Join(pairs, ",")
+	case reflect.Slice:
+		elements := make([]string, 0, v.Len())
+
+		for i := 0; i < v.Len(); i++ {
+			elements = append(elements, fmt.Sprintf("%v", v.Index(i).Interface()))
+		}
+
+		return strings.Join(elements, ",")
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
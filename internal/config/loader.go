@@ -0,0 +1,562 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v2"
+)
+
+// Constants
+
+const (
+	ConfigFileEnvVar = EnvPrefix + "_CONFIG_FILE"
+	FileEnvVarSuffix = "_FILE"
+)
+
+// Variables
+
+// legacyEnvVars Environment variables read by the app before the config was split in sections, with the ones which
+// replaced them. They are still read, unless the new one is set too.
+var legacyEnvVars = map[string]string{
+	EnvPrefix + "_PORT":             EnvPrefix + "_SERVER_PORT",
+	EnvPrefix + "_LOGLEVEL":         EnvPrefix + "_LOG_LEVEL",
+	EnvPrefix + "_DBURI":            EnvPrefix + "_DB_URI",
+	EnvPrefix + "_DBMIGRATIONSPATH": EnvPrefix + "_DB_MIGRATIONS_PATH",
+	EnvPrefix + "_DEFAULTLOCALE":    EnvPrefix + "_DEFAULT_LOCALE",
+	EnvPrefix + "_DEFAULTLIMIT":     EnvPrefix + "_DEFAULT_LIMIT",
+}
+
+// Structs
+
+// Loader Loads the config from several sources. From lowest to highest precedence:
+//
+//  1. The default tag of every field.
+//  2. The config files (YAML, TOML or JSON, depending on their extension), in the order they were added. If none was
+//     added, the one set on MYAPP_CONFIG_FILE (if any).
+//  3. The environment variables, named after the dotted key of every field: "db.migrations_path" is read from
+//     MYAPP_DB_MIGRATIONS_PATH. If MYAPP_DB_MIGRATIONS_PATH_FILE is set instead, the value is read from that file.
+//     The names used by older versions of the app (MYAPP_PORT, MYAPP_LOGLEVEL, MYAPP_DBURI, MYAPP_DBMIGRATIONSPATH,
+//     MYAPP_DEFAULTLOCALE and MYAPP_DEFAULTLIMIT) are still read, with lower precedence than the new ones.
+//  4. The overrides ("key=value" pairs, usually from the CLI flags).
+type Loader struct {
+	files     []string
+	environ   []string
+	overrides []string
+}
+
+func (l *Loader) WithFiles(files ...string) *Loader {
+	l.files = append(l.files, files...)
+
+	return l
+}
+
+func (l *Loader) WithEnviron(environ []string) *Loader {
+	l.environ = environ
+
+	return l
+}
+
+func (l *Loader) WithOverrides(overrides ...string) *Loader {
+	l.overrides = append(l.overrides, overrides...)
+
+	return l
+}
+
+// GetFiles Returns the config files which are loaded.
+func (l *Loader) GetFiles() []string {
+	if len(l.files) > 0 {
+		return l.files
+	}
+
+	if file := parseEnviron(l.environ)[ConfigFileEnvVar]; file != "" {
+		return []string{file}
+	}
+
+	return []string{}
+}
+
+func (l *Loader) Load() (*AppConfig, error) {
+	src, err := l.loadSources()
+
+	if err != nil {
+		return nil, err
+	}
+
+	appConfig := &AppConfig{sources: src}
+
+	if err := src.decode("", appConfig); err != nil {
+		return nil, err
+	}
+
+	return appConfig, nil
+}
+
+// loadSources Reads the config files, and parses the environment variables and the overrides.
+func (l *Loader) loadSources() (*sources, error) {
+	src := newSources()
+
+	src.loader = l
+	src.env = parseEnviron(l.environ)
+
+	for _, file := range l.GetFiles() {
+		tree, err := parseFile(file)
+
+		if err != nil {
+			return nil, err
+		}
+
+		mergeTrees(src.files, tree)
+	}
+
+	for _, override := range l.overrides {
+		parts := strings.SplitN(override, "=", 2)
+
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid config override '%s': it must be KEY=VALUE", override)
+		}
+
+		setValue(src.overrides, strings.Split(parts[0], "."), parts[1])
+	}
+
+	return src, nil
+}
+
+// sources
+
+type sources struct {
+	loader    *Loader
+	files     map[string]interface{}
+	env       map[string]string
+	overrides map[string]interface{}
+}
+
+// decode Fills the target with the values of its section (or of the whole config, if section is empty), and then
+// validates it.
+func (s *sources) decode(section string, target interface{}) error {
+	targetType := reflect.TypeOf(target).Elem()
+	path := make([]string, 0)
+
+	if section != "" {
+		path = append(path, section)
+	}
+
+	tree := getDefaults(targetType)
+
+	mergeTrees(tree, getSubtree(s.files, path))
+
+	envTree, err := s.getEnvTree(targetType, path)
+
+	if err != nil {
+		return err
+	}
+
+	mergeTrees(tree, envTree)
+	mergeTrees(tree, getSubtree(s.overrides, path))
+
+	// The rest of the top level keys belong to the module sections
+
+	if section == "" {
+		known := getSectionKeys(targetType)
+
+		for key := range tree {
+			if _, found := known[key]; !found {
+				delete(tree, key)
+			}
+		}
+	}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+			stringToMapHookFunc,
+		),
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		TagName:          "yaml",
+		Result:           target,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if err := decoder.Decode(tree); err != nil {
+		if decodeErr, ok := err.(*mapstructure.Error); ok {
+			return newValidationError(section, decodeErr.Errors)
+		}
+
+		return newValidationError(section, []string{err.Error()})
+	}
+
+	return validate(section, target)
+}
+
+// getEnvTree Returns the values of the environment variables of every field of the type.
+func (s *sources) getEnvTree(targetType reflect.Type, path []string) (map[string]interface{}, error) {
+	res := make(map[string]interface{})
+
+	for _, leaf := range getLeaves(targetType, nil) {
+		name := getEnvVarName(append(append([]string{}, path...), leaf...))
+		value, found := s.env[name]
+		file, fileFound := s.env[name+FileEnvVarSuffix]
+
+		if found && fileFound {
+			return nil, fmt.Errorf("only one of %s and %s can be set", name, name+FileEnvVarSuffix)
+		}
+
+		if fileFound {
+			content, err := ioutil.ReadFile(file)
+
+			if err != nil {
+				return nil, fmt.Errorf("could NOT read %s: %s", name+FileEnvVarSuffix, err)
+			}
+
+			value = strings.TrimRight(string(content), "\r\n")
+			found = true
+		}
+
+		if found {
+			setValue(res, leaf, value)
+		}
+	}
+
+	return res, nil
+}
+
+func (s *sources) getTopLevelKeys() []string {
+	res := make([]string, 0)
+
+	for _, tree := range []map[string]interface{}{s.files, s.overrides} {
+		for key := range tree {
+			res = append(res, key)
+		}
+	}
+
+	return res
+}
+
+func (s *sources) clone() *sources {
+	res := newSources()
+
+	res.loader = s.loader
+
+	mergeTrees(res.files, s.files)
+	mergeTrees(res.overrides, s.overrides)
+
+	for key, value := range s.env {
+		res.env[key] = value
+	}
+
+	return res
+}
+
+// Static functions
+
+func NewLoader() *Loader {
+	return &Loader{
+		files:     make([]string, 0),
+		environ:   os.Environ(),
+		overrides: make([]string, 0),
+	}
+}
+
+func newSources() *sources {
+	return &sources{
+		files:     make(map[string]interface{}),
+		env:       make(map[string]string),
+		overrides: make(map[string]interface{}),
+	}
+}
+
+func parseEnviron(environ []string) map[string]string {
+	res := make(map[string]string)
+
+	for _, entry := range environ {
+		parts := strings.SplitN(entry, "=", 2)
+
+		if len(parts) == 2 && strings.HasPrefix(parts[0], EnvPrefix+"_") {
+			res[parts[0]] = parts[1]
+		}
+	}
+
+	for legacyName, name := range legacyEnvVars {
+		value, found := res[legacyName]
+
+		if !found {
+			continue
+		}
+
+		delete(res, legacyName)
+
+		_, newFound := res[name]
+		_, newFileFound := res[name+FileEnvVarSuffix]
+
+		if !newFound && !newFileFound {
+			res[name] = value
+		}
+	}
+
+	return res
+}
+
+func parseFile(file string) (map[string]interface{}, error) {
+	content, err := ioutil.ReadFile(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var tree interface{}
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &tree)
+	case ".toml":
+		tomlTree := make(map[string]interface{})
+
+		_, err = toml.Decode(string(content), &tomlTree)
+		tree = tomlTree
+	case ".json":
+		err = json.Unmarshal(content, &tree)
+	default:
+		return nil, fmt.Errorf("%s: unsupported config file format, it must be YAML, TOML or JSON", file)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+
+	if tree == nil {
+		return make(map[string]interface{}), nil
+	}
+
+	res, ok := normalizeValue(tree).(map[string]interface{})
+
+	if !ok {
+		return nil, fmt.Errorf("%s: the config must be a map of sections", file)
+	}
+
+	return res, nil
+}
+
+// normalizeValue YAML maps can have keys of any type. We need string keys to merge them with the rest of the sources.
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(v))
+
+		for key, element := range v {
+			res[fmt.Sprintf("%v", key)] = normalizeValue(element)
+		}
+
+		return res
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+
+		for key, element := range v {
+			res[key] = normalizeValue(element)
+		}
+
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+
+		for i, element := range v {
+			res[i] = normalizeValue(element)
+		}
+
+		return res
+	default:
+		return value
+	}
+}
+
+func mergeTrees(dst map[string]interface{}, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+
+		if srcIsMap && dstIsMap {
+			mergeTrees(dstMap, srcMap)
+
+			continue
+		}
+
+		if srcIsMap {
+			copied := make(map[string]interface{})
+
+			mergeTrees(copied, srcMap)
+
+			value = copied
+		}
+
+		dst[key] = value
+	}
+}
+
+func getSubtree(tree map[string]interface{}, path []string) map[string]interface{} {
+	for _, key := range path {
+		subtree, ok := tree[key].(map[string]interface{})
+
+		if !ok {
+			return make(map[string]interface{})
+		}
+
+		tree = subtree
+	}
+
+	return tree
+}
+
+func setValue(tree map[string]interface{}, path []string, value interface{}) {
+	for _, key := range path[:len(path)-1] {
+		subtree, ok := tree[key].(map[string]interface{})
+
+		if !ok {
+			subtree = make(map[string]interface{})
+			tree[key] = subtree
+		}
+
+		tree = subtree
+	}
+
+	tree[path[len(path)-1]] = value
+}
+
+// getDefaults Returns the values of the default tags of the type. Defaults of maps are parsed, so other sources can
+// override some of their keys while keeping the rest.
+func getDefaults(targetType reflect.Type) map[string]interface{} {
+	res := make(map[string]interface{})
+
+	for i := 0; i < targetType.NumField(); i++ {
+		field := targetType.Field(i)
+		key := getKey(field)
+
+		if key == "" {
+			continue
+		}
+
+		if isSection(field.Type) {
+			res[key] = getDefaults(field.Type)
+
+			continue
+		}
+
+		defaultValue, found := field.Tag.Lookup("default")
+
+		if !found {
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Map {
+			defaults := make(map[string]interface{})
+
+			for mapKey, mapValue := range parseMap(defaultValue) {
+				defaults[mapKey] = mapValue
+			}
+
+			res[key] = defaults
+
+			continue
+		}
+
+		res[key] = defaultValue
+	}
+
+	return res
+}
+
+// getLeaves Returns the path of every field of the type which is not a section.
+func getLeaves(targetType reflect.Type, path []string) [][]string {
+	res := make([][]string, 0)
+
+	for i := 0; i < targetType.NumField(); i++ {
+		field := targetType.Field(i)
+		key := getKey(field)
+
+		if key == "" {
+			continue
+		}
+
+		fieldPath := append(append([]string{}, path...), key)
+
+		if isSection(field.Type) {
+			res = append(res, getLeaves(field.Type, fieldPath)...)
+		} else {
+			res = append(res, fieldPath)
+		}
+	}
+
+	return res
+}
+
+func getSectionKeys(targetType reflect.Type) map[string]struct{} {
+	res := make(map[string]struct{})
+
+	for i := 0; i < targetType.NumField(); i++ {
+		if key := getKey(targetType.Field(i)); key != "" {
+			res[key] = struct{}{}
+		}
+	}
+
+	return res
+}
+
+// getKey Returns the key of the field, from its yaml tag. Unexported fields, and fields tagged with "-", have no key.
+func getKey(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		return ""
+	}
+
+	key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+
+	if key == "-" {
+		return ""
+	}
+
+	if key == "" {
+		return strings.ToLower(field.Name)
+	}
+
+	return key
+}
+
+func getEnvVarName(path []string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.Join(path, "_"))
+}
+
+func isSection(fieldType reflect.Type) bool {
+	return fieldType.Kind() == reflect.Struct
+}
+
+// stringToMapHookFunc Maps can be set as a string, like "key1:value1,key2:value2".
+func stringToMapHookFunc(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to.Kind() != reflect.Map {
+		return data, nil
+	}
+
+	return parseMap(data.(string)), nil
+}
+
+func parseMap(value string) map[string]string {
+	res := make(map[string]string)
+
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(pair, ":", 2)
+
+		if len(parts) == 2 && strings.TrimSpace(parts[0]) != "" {
+			res[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Structs
+
+// Runtime Holds the config of a running app. Reloads replace the whole config at once, so readers never see a mix of
+// old and new settings. Only fields tagged with `reload:"true"` are changed. Changes to the rest of them (like the
+// port or the DB URI) are ignored until the app is restarted.
+type Runtime struct {
+	mutex     sync.Mutex
+	current   atomic.Value
+	listeners []func(appConfig *AppConfig)
+}
+
+// Get Returns the current config, which must not be changed.
+func (r *Runtime) Get() *AppConfig {
+	return r.current.Load().(*AppConfig)
+}
+
+// IsFeatureEnabled Returns whether the feature flag is enabled. Unknown features are disabled.
+func (r *Runtime) IsFeatureEnabled(feature string) bool {
+	return r.Get().Features[feature]
+}
+
+// OnReload Registers a function called with the new config every time a reload changes any setting.
+func (r *Runtime) OnReload(listener func(appConfig *AppConfig)) *Runtime {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.listeners = append(r.listeners, listener)
+
+	return r
+}
+
+// Reload Loads the config again, from the same sources it was first loaded from, and applies its reloadable settings.
+// If the new config is not valid, nothing is applied and the error is returned.
+func (r *Runtime) Reload() (*ReloadResult, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	current := r.Get()
+	currentSources := current.getSources()
+
+	if currentSources.loader == nil {
+		return nil, errors.New("the config was not created by a Loader, so it can't be reloaded")
+	}
+
+	src, err := currentSources.loader.loadSources()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Values set with AppConfig.Set are kept
+
+	src.overrides = currentSources.clone().overrides
+
+	next := &AppConfig{sources: src}
+
+	if err := src.decode("", next); err != nil {
+		return nil, err
+	}
+
+	// Config sections of the modules are validated too, although they are never reloaded
+
+	for name, section := range current.sections {
+		if err := next.LoadSection(name, reflect.New(reflect.TypeOf(section).Elem()).Interface()); err != nil {
+			return nil, err
+		}
+	}
+
+	if unknownSections := next.GetUnknownSections(); len(unknownSections) > 0 {
+		return nil, newValidationError("", []string{"unknown config sections: " + strings.Join(unknownSections, ", ")})
+	}
+
+	res := &ReloadResult{Applied: make([]string, 0), Ignored: make([]string, 0)}
+	currentSettings := make(map[string]*Setting)
+
+	for _, setting := range current.GetSettings(false) {
+		currentSettings[setting.Key] = setting
+	}
+
+	for _, setting := range next.GetSettings(false) {
+		if currentSetting, found := currentSettings[setting.Key]; !found || currentSetting.Value == setting.Value {
+			continue
+		}
+
+		if setting.Reloadable {
+			res.Applied = append(res.Applied, setting.Key)
+		} else {
+			res.Ignored = append(res.Ignored, setting.Key)
+		}
+	}
+
+	sort.Strings(res.Applied)
+	sort.Strings(res.Ignored)
+
+	if len(res.Applied) < 1 {
+		return res, nil
+	}
+
+	applied := current.Clone()
+
+	applied.sources = src
+	applied.sections = current.sections
+
+	copyReloadableFields(reflect.ValueOf(applied).Elem(), reflect.ValueOf(next).Elem())
+
+	r.current.Store(applied)
+
+	for _, listener := range r.listeners {
+		listener(applied)
+	}
+
+	return res, nil
+}
+
+// ReloadResult
+
+// ReloadResult Dotted keys of the settings changed by a reload.
+type ReloadResult struct {
+	Applied []string
+	Ignored []string
+}
+
+// Static functions
+
+func NewRuntime(appConfig *AppConfig) *Runtime {
+	res := &Runtime{
+		listeners: make([]func(appConfig *AppConfig), 0),
+	}
+
+	res.current.Store(appConfig)
+
+	return res
+}
+
+func copyReloadableFields(dst reflect.Value, src reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Type().Field(i)
+
+		if getKey(field) == "" {
+			continue
+		}
+
+		if isSection(field.Type) {
+			copyReloadableFields(dst.Field(i), src.Field(i))
+
+			continue
+		}
+
+		if field.Tag.Get("reload") == "true" {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+}
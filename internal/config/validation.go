@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/comfortablynumb/goginrestapi/internal/ratelimit"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// Constants
+
+const (
+	LogLevelValidation  = "log_level"
+	RateLimitValidation = "rate_limit"
+)
+
+// Variables
+
+var (
+	LogLevels = []string{"trace", "debug", "info", "warn", "error", "fatal", "panic"}
+
+	configValidator = newValidator()
+)
+
+// Structs
+
+// ValidationError Every problem found on the config, with one readable message per field, like
+// "server.port: must be at most 65535".
+type ValidationError struct {
+	Errors []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid config:\n  - " + strings.Join(e.Errors, "\n  - ")
+}
+
+// Static functions
+
+func newValidationError(section string, messages []string) *ValidationError {
+	res := &ValidationError{Errors: make([]string, 0, len(messages))}
+
+	for _, message := range messages {
+		if section != "" {
+			message = section + ": " + message
+		}
+
+		res.Errors = append(res.Errors, message)
+	}
+
+	return res
+}
+
+func newValidator() *validator.Validate {
+	res := validator.New()
+
+	res.RegisterTagNameFunc(getKey)
+
+	_ = res.RegisterValidation(LogLevelValidation, func(fl validator.FieldLevel) bool {
+		level := strings.ToLower(fl.Field().String())
+
+		for _, logLevel := range LogLevels {
+			if level == logLevel {
+				return true
+			}
+		}
+
+		return false
+	})
+
+	_ = res.RegisterValidation(RateLimitValidation, func(fl validator.FieldLevel) bool {
+		_, err := ratelimit.ParsePolicy(fl.Field().String())
+
+		return err == nil
+	})
+
+	return res
+}
+
+// validate Validates the target, which belongs to the given section (or is the whole config, if section is empty).
+func validate(section string, target interface{}) error {
+	err := configValidator.Struct(target)
+
+	if err == nil {
+		return nil
+	}
+
+	fieldErrors, ok := err.(validator.ValidationErrors)
+
+	if !ok {
+		return err
+	}
+
+	res := &ValidationError{Errors: make([]string, 0, len(fieldErrors))}
+
+	for _, fieldError := range fieldErrors {
+		// The namespace starts with the name of the struct
+
+		key := fieldError.Namespace()
+		key = key[strings.Index(key, ".")+1:]
+
+		if section != "" {
+			key = section + "." + key
+		}
+
+		res.Errors = append(res.Errors, fmt.Sprintf("%s: %s", key, getValidationMessage(fieldError)))
+	}
+
+	return res
+}
+
+func getValidationMessage(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "min", "gte":
+		if fieldError.Kind() == reflect.String {
+			return fmt.Sprintf("must have at least %s characters", fieldError.Param())
+		}
+
+		return fmt.Sprintf("must be at least %s", fieldError.Param())
+	case "max", "lte":
+		if fieldError.Kind() == reflect.String {
+			return fmt.Sprintf("must have at most %s characters", fieldError.Param())
+		}
+
+		return fmt.Sprintf("must be at most %s", fieldError.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fieldError.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.Join(strings.Fields(fieldError.Param()), ", "))
+	case "url":
+		return "must be a valid URL"
+	case LogLevelValidation:
+		return fmt.Sprintf("must be one of: %s", strings.Join(LogLevels, ", "))
+	case RateLimitValidation:
+		return fmt.Sprintf("must be a rate limit policy like '100/1m [algorithm] [key]', got '%v'", fieldError.Value())
+	default:
+		return fmt.Sprintf("failed on the '%s' validation", fieldError.Tag())
+	}
+}
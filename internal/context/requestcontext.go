@@ -1,32 +1,191 @@
 package context
 
 import (
+	"database/sql"
 	"time"
 
+	"github.com/comfortablynumb/goginrestapi/internal/i18n"
 	"github.com/gin-gonic/gin"
 	ut "github.com/go-playground/universal-translator"
 )
 
+// Constants
+
+const (
+	RequestIDHeader   = "X-Request-ID"
+	RequestIDKey      = "request_id"
+	ClientIdentityKey = "client_identity"
+	ActorHeader       = "X-Actor"
+	AnonymousActor    = "anonymous"
+	SystemActor       = "system"
+	TenantIDKey       = "tenant_id"
+	TenantKey         = "tenant"
+
+	// DefaultTenantID The tenant which existing data was moved to when tenants were added. Contexts without a tenant
+	// (like the background ones) belong to it.
+	DefaultTenantID = int64(1)
+	DefaultTenant   = "default"
+)
+
 // Structs
 
 type RequestContext struct {
-	ginContext *gin.Context
-	translator *ut.UniversalTranslator
-	data       map[string]interface{}
+	ginContext         *gin.Context
+	translator         *i18n.Translator
+	actorHeader        string
+	trustedActorHeader bool
+	tenantID           int64
+	tenant             string
+	tx                 *sql.Tx
+	afterTx            []func()
+	data               map[string]interface{}
 }
 
 func (r *RequestContext) GetAcceptLanguage() string {
+	if r.ginContext == nil {
+		return ""
+	}
+
 	return r.ginContext.GetHeader("Accept-Language")
 }
 
+// GetTranslator Returns the translator of the locale negotiated from the Accept-Language header of the request, or
+// the one of the default locale if none of its languages is supported.
 func (r *RequestContext) GetTranslator() *ut.Translator {
-	trans, found := r.translator.GetTranslator(r.GetAcceptLanguage())
+	trans := r.translator.GetTranslator(r.GetAcceptLanguage())
 
-	if !found {
-		trans = r.translator.GetFallback()
+	return &trans
+}
+
+// GetLocale Returns the locale negotiated for the request.
+func (r *RequestContext) GetLocale() string {
+	return (*r.GetTranslator()).Locale()
+}
+
+// Translate Returns the message of key in the locale of the request, or fallback if it has no message for it.
+func (r *RequestContext) Translate(key string, fallback string, params ...string) string {
+	if r == nil || r.translator == nil {
+		return fallback
 	}
 
-	return &trans
+	message, err := (*r.GetTranslator()).T(key, params...)
+
+	if err != nil {
+		return fallback
+	}
+
+	return message
+}
+
+func (r *RequestContext) GetRequestID() string {
+	if r.ginContext == nil {
+		return ""
+	}
+
+	return r.ginContext.GetString(RequestIDKey)
+}
+
+// GetClientIdentity Returns the identity of the verified TLS client certificate of the request (if any).
+func (r *RequestContext) GetClientIdentity() string {
+	if r.ginContext == nil {
+		return ""
+	}
+
+	return r.ginContext.GetString(ClientIdentityKey)
+}
+
+// GetActor Returns the identity of the verified client certificate of the request. If there isn't one, the actor
+// header is used if it's trusted. As clients can send anything on it, use GetClientIdentity where only identities
+// verified by the app are acceptable.
+func (r *RequestContext) GetActor() string {
+	if r.ginContext == nil {
+		return SystemActor
+	}
+
+	if clientIdentity := r.GetClientIdentity(); clientIdentity != "" {
+		return clientIdentity
+	}
+
+	if !r.trustedActorHeader {
+		return AnonymousActor
+	}
+
+	actor := r.ginContext.GetHeader(r.actorHeader)
+
+	if actor == "" {
+		return AnonymousActor
+	}
+
+	return actor
+}
+
+// GetTenantID Returns the ID of the tenant of the request, resolved by the tenant middleware. Repositories scope every
+// query by it. Contexts without a tenant belong to the default one.
+func (r *RequestContext) GetTenantID() int64 {
+	if r.tenantID != 0 {
+		return r.tenantID
+	}
+
+	if r.ginContext != nil {
+		if tenantID, ok := r.ginContext.Get(TenantIDKey); ok {
+			return tenantID.(int64)
+		}
+	}
+
+	return DefaultTenantID
+}
+
+// GetTenant Returns the name of the tenant of the request.
+func (r *RequestContext) GetTenant() string {
+	if r.tenant != "" {
+		return r.tenant
+	}
+
+	if r.ginContext != nil {
+		if tenant := r.ginContext.GetString(TenantKey); tenant != "" {
+			return tenant
+		}
+	}
+
+	return DefaultTenant
+}
+
+// SetTenant Makes the context belong to the given tenant, like background work done on behalf of one.
+func (r *RequestContext) SetTenant(ID int64, name string) *RequestContext {
+	r.tenantID = ID
+	r.tenant = name
+
+	return r
+}
+
+func (r *RequestContext) GetTx() *sql.Tx {
+	return r.tx
+}
+
+func (r *RequestContext) SetTx(tx *sql.Tx) *RequestContext {
+	r.tx = tx
+
+	return r
+}
+
+func (r *RequestContext) HasTx() bool {
+	return r.tx != nil
+}
+
+// AddAfterTx Stores a function to be called once the current transaction ends.
+func (r *RequestContext) AddAfterTx(fn func()) *RequestContext {
+	r.afterTx = append(r.afterTx, fn)
+
+	return r
+}
+
+// TakeAfterTx Returns the functions stored with AddAfterTx, removing them from the context.
+func (r *RequestContext) TakeAfterTx() []func() {
+	res := r.afterTx
+
+	r.afterTx = nil
+
+	return res
 }
 
 func (r *RequestContext) Set(key string, value interface{}) *RequestContext {
@@ -45,18 +204,38 @@ func (r *RequestContext) Get(key string) interface{} {
 	return val
 }
 
+func (r *RequestContext) IsBackground() bool {
+	return r.ginContext == nil
+}
+
 func (r *RequestContext) Deadline() (deadline time.Time, ok bool) {
+	if r.ginContext == nil {
+		return
+	}
+
 	return r.ginContext.Deadline()
 }
 
 func (r *RequestContext) Done() <-chan struct{} {
+	if r.ginContext == nil {
+		return nil
+	}
+
 	return r.ginContext.Done()
 }
 
 func (r *RequestContext) Err() error {
+	if r.ginContext == nil {
+		return nil
+	}
+
 	return r.ginContext.Err()
 }
 
 func (r *RequestContext) Value(key interface{}) interface{} {
-	return r.Value(key)
+	if r.ginContext == nil {
+		return nil
+	}
+
+	return r.ginContext.Value(key)
 }
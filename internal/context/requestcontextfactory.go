@@ -1,19 +1,33 @@
 package context
 
 import (
+	"github.com/comfortablynumb/goginrestapi/internal/i18n"
 	"github.com/gin-gonic/gin"
-	ut "github.com/go-playground/universal-translator"
 )
 
 // Structs
 
 type RequestContextFactory struct {
-	translator *ut.UniversalTranslator
+	translator         *i18n.Translator
+	actorHeader        string
+	trustedActorHeader bool
 }
 
 func (r *RequestContextFactory) NewRequestContext(ginContext *gin.Context) *RequestContext {
 	return &RequestContext{
-		ginContext: ginContext,
+		ginContext:         ginContext,
+		translator:         r.translator,
+		actorHeader:        r.actorHeader,
+		trustedActorHeader: r.trustedActorHeader,
+		data:               make(map[string]interface{}),
+	}
+}
+
+// NewBackgroundRequestContext Creates a request context which is not bound to an HTTP request, for work done by
+// background processes. Its actor is always SystemActor.
+func (r *RequestContextFactory) NewBackgroundRequestContext() *RequestContext {
+	return &RequestContext{
+		ginContext: nil,
 		translator: r.translator,
 		data:       make(map[string]interface{}),
 	}
@@ -21,8 +35,16 @@ func (r *RequestContextFactory) NewRequestContext(ginContext *gin.Context) *Requ
 
 // Static functions
 
-func NewRequestContextFactory(translator *ut.UniversalTranslator) *RequestContextFactory {
+// NewRequestContextFactory The actor of the requests without a verified client certificate is read from actorHeader
+// (or from ActorHeader if it's empty) only if trustedActorHeader is true.
+func NewRequestContextFactory(translator *i18n.Translator, actorHeader string, trustedActorHeader bool) *RequestContextFactory {
+	if actorHeader == "" {
+		actorHeader = ActorHeader
+	}
+
 	return &RequestContextFactory{
-		translator: translator,
+		translator:         translator,
+		actorHeader:        actorHeader,
+		trustedActorHeader: trustedActorHeader,
 	}
 }
@@ -0,0 +1,148 @@
+package controller_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/app"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppRunAndClose(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	assert.Nil(t, err)
+
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	assert.Nil(t, listener.Close())
+
+	appConfig, err := mock.NewDefaultConfigLoader().
+		WithOverrides("server.host=127.0.0.1", fmt.Sprintf("server.port=%d", port)).
+		Load()
+
+	assert.Nil(t, err)
+
+	application := app.NewApp(appConfig)
+
+	assert.Nil(t, application.SetUp())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- application.Run(ctx)
+	}()
+
+	// The app serves requests until the context is cancelled
+
+	healthy := false
+
+	for i := 0; i < 50 && !healthy; i++ {
+		response, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/health", port))
+
+		if err == nil {
+			healthy = response.StatusCode == http.StatusOK
+
+			response.Body.Close()
+		}
+
+		if !healthy {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	assert.True(t, healthy)
+
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.Nil(t, err)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "The app did not stop after the context was cancelled.")
+	}
+
+	_, err = http.Get(fmt.Sprintf("http://127.0.0.1:%d/health", port))
+
+	assert.NotNil(t, err)
+
+	// Closing the app closes the DB, and it can be done more than once
+
+	assert.Nil(t, application.ExecuteDbMigrationsDown())
+	assert.Nil(t, application.Close())
+	assert.NotNil(t, application.GetComponentRegistry().Db.Ping())
+	assert.Nil(t, application.Close())
+}
+
+func TestAppRunErrors(t *testing.T) {
+	// Listening errors are returned
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	assert.Nil(t, err)
+
+	defer listener.Close()
+
+	appConfig, err := mock.NewDefaultConfigLoader().
+		WithOverrides("server.host=127.0.0.1", fmt.Sprintf("server.port=%d", listener.Addr().(*net.TCPAddr).Port)).
+		Load()
+
+	assert.Nil(t, err)
+
+	application := app.NewApp(appConfig)
+
+	err = application.Run(context.Background())
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "could NOT listen for incoming requests")
+	assert.Nil(t, application.ExecuteDbMigrationsDown())
+	assert.Nil(t, application.Close())
+
+	// Invalid TLS settings are returned
+
+	appConfig, err = mock.NewDefaultConfigLoader().WithOverrides("server.tls.cert_file=/nonexistent/server.crt").Load()
+
+	assert.Nil(t, err)
+
+	application = app.NewApp(appConfig)
+
+	err = application.Run(context.Background())
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "could NOT create the web server")
+	assert.Nil(t, application.ExecuteDbMigrationsDown())
+	assert.Nil(t, application.Close())
+}
+
+func TestAppSetUpErrors(t *testing.T) {
+	for override, expectedError := range map[string]string{
+		"db.migrations_path=file:///nonexistent":        "could NOT create the database migrations instance",
+		"unknown_section.key=value":                     "unknown config sections: unknown_section",
+		"webhook.delivery_interval=not-a-duration":      "invalid duration",
+		"cache.backend=redis redis.address=127.0.0.1:1": "could NOT connect to Redis",
+	} {
+		appConfig, err := mock.NewDefaultConfigLoader().WithOverrides(strings.Fields(override)...).Load()
+
+		assert.Nil(t, err, override)
+
+		application := app.NewApp(appConfig)
+
+		err = application.SetUp()
+
+		assert.NotNil(t, err, override)
+
+		if err != nil {
+			assert.Contains(t, err.Error(), "could NOT set up the app", override)
+			assert.Contains(t, err.Error(), expectedError, override)
+		}
+
+		assert.Nil(t, application.Close(), override)
+	}
+}
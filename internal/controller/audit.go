@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Constants
+
+const (
+	AuditControllerSourceName = "AuditController"
+)
+
+// Structs
+
+type AuditController struct {
+	auditService          service.AuditService
+	requestContextFactory *context.RequestContextFactory
+}
+
+// Find Search for audit events.
+// @Summary Search for audit events.
+// @Description Allows you to search the audit log of every mutation using different filters and options.
+// @Produce json
+// @Param entity_type query string false "Entity type. Allowed values: user, user_type"
+// @Param entity_key query string false "Entity key (username, user type name)"
+// @Param actor query string false "Actor who made the change"
+// @Param from query string false "Only events created at or after this time (RFC 3339)"
+// @Param to query string false "Only events created at or before this time (RFC 3339)"
+// @Param sort_by query string false "Field to sort by. Allowed fields: id, created_at"
+// @Param sort_dir query string false "Direction to sort by. Allowed values: asc, desc. Default: asc"
+// @Param offset query int false "Starts results from this offset. Default: 0"
+// @Param limit query int false "Limits the amount of results to return. Default: 50"
+// @Success 200 {object} resource.AuditEventResourceList
+// @Failure 400 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags audit
+// @Router /audit [get]
+func (ctrl *AuditController) Find(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	var req resource.AuditEventFindResource
+
+	if err := c.ShouldBind(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, AuditControllerSourceName, nil))
+
+		return
+	}
+
+	auditEventResourceList, err := ctrl.auditService.Find(requestContext, &req)
+
+	if err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, auditEventResourceList)
+}
+
+// Static functions
+
+func NewAuditController(auditService service.AuditService, requestContextFactory *context.RequestContextFactory) *AuditController {
+	return &AuditController{
+		auditService:          auditService,
+		requestContextFactory: requestContextFactory,
+	}
+}
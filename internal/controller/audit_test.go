@@ -0,0 +1,294 @@
+package controller_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditUserTypeHistory(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+
+	req := resource.UserTypeUpdateResource{
+		Name:     userTypeReq.Name,
+		Disabled: true,
+	}
+
+	response, err := mockApp.NewPutRequest(
+		"/user_type/"+userTypeReq.Name,
+		mock.NewMockAppOptions().WithBody(req).WithHeader("X-Actor", "admin").WithHeader("X-Request-ID", "req-1"),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	res := &resource.AuditEventResourceList{}
+
+	response, err = mockApp.NewGetRequest("/user_type/"+userTypeReq.Name+"/history", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, int64(2), res.TotalCount)
+	assert.Equal(t, 2, len(res.Data))
+
+	assert.Equal(t, model.AuditActionCreate, res.Data[0].Action)
+	assert.Equal(t, "anonymous", res.Data[0].Actor)
+	assert.NotEmpty(t, res.Data[0].RequestID)
+	assert.Nil(t, res.Data[0].Changes["name"].Before)
+	assert.Equal(t, userTypeReq.Name, res.Data[0].Changes["name"].After)
+
+	assert.Equal(t, model.AuditActionUpdate, res.Data[1].Action)
+	assert.Equal(t, "admin", res.Data[1].Actor)
+	assert.Equal(t, "req-1", res.Data[1].RequestID)
+	assert.Equal(t, model.AuditEntityTypeUserType, res.Data[1].EntityType)
+	assert.Equal(t, userTypeReq.Name, res.Data[1].EntityKey)
+	assert.Equal(t, false, res.Data[1].Changes["disabled"].Before)
+	assert.Equal(t, true, res.Data[1].Changes["disabled"].After)
+
+	_, found := res.Data[1].Changes["name"]
+
+	assert.False(t, found)
+}
+
+func TestAuditUserHistory(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+	userReq := CreateUser(t, mockApp, "test-user-1", userTypeReq.Name)
+
+	response, err := mockApp.NewDeleteRequest("/user/"+userReq.Username, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	res := &resource.AuditEventResourceList{}
+
+	response, err = mockApp.NewGetRequest("/user/"+userReq.Username+"/history", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, 2, len(res.Data))
+	assert.Equal(t, model.AuditActionCreate, res.Data[0].Action)
+	assert.Equal(t, model.AuditActionDelete, res.Data[1].Action)
+	assert.Equal(t, userReq.Username, res.Data[1].Changes["username"].Before)
+	assert.Nil(t, res.Data[1].Changes["username"].After)
+}
+
+func TestAuditFindWithFilters(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+
+	CreateUserType(t, mockApp, "test-user-type-2")
+	CreateUser(t, mockApp, "test-user-1", userTypeReq.Name)
+
+	res := &resource.AuditEventResourceList{}
+
+	// All events
+
+	response, err := mockApp.NewGetRequest("/audit", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, int64(3), res.TotalCount)
+
+	// By entity
+
+	response, err = mockApp.NewGetRequest("/audit?entity_type=user_type", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, int64(2), res.TotalCount)
+
+	// By actor
+
+	response, err = mockApp.NewGetRequest("/audit?actor=someone-else", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, int64(0), res.TotalCount)
+
+	// By time range
+
+	future := url.QueryEscape(time.Now().UTC().Add(time.Hour).Format(time.RFC3339))
+	past := url.QueryEscape(time.Now().UTC().Add(-time.Hour).Format(time.RFC3339))
+
+	response, err = mockApp.NewGetRequest("/audit?from="+future, mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, int64(0), res.TotalCount)
+
+	response, err = mockApp.NewGetRequest("/audit?from="+past+"&to="+future, mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, int64(3), res.TotalCount)
+}
+
+func TestAuditHistoryFollowsTheEntity(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	CreateUserType(t, mockApp, "test-user-type-1")
+
+	// Renamed entities keep their history
+
+	response, err := mockApp.NewPutRequest(
+		"/user_type/test-user-type-1",
+		mock.NewMockAppOptions().WithBody(resource.UserTypeUpdateResource{Name: "test-user-type-2"}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	res := &resource.AuditEventResourceList{}
+
+	response, err = mockApp.NewGetRequest("/user_type/test-user-type-2/history", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, int64(2), res.TotalCount)
+	assert.Equal(t, "test-user-type-1", res.Data[0].EntityKey)
+	assert.Equal(t, "test-user-type-1", res.Data[1].Changes["name"].Before)
+	assert.Equal(t, "test-user-type-2", res.Data[1].Changes["name"].After)
+
+	// Entities created again with the key of a deleted one don't inherit its history, which can still be read while
+	// the key is not used again
+
+	response, err = mockApp.NewDeleteRequest("/user_type/test-user-type-2", nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	response, err = mockApp.NewGetRequest("/user_type/test-user-type-2/history", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, int64(3), res.TotalCount)
+	assert.Equal(t, model.AuditActionDelete, res.Data[2].Action)
+
+	CreateUserType(t, mockApp, "test-user-type-2")
+
+	response, err = mockApp.NewGetRequest("/user_type/test-user-type-2/history", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, int64(1), res.TotalCount)
+	assert.Equal(t, model.AuditActionCreate, res.Data[0].Action)
+
+	// Keys which were never used have no history
+
+	response, err = mockApp.NewGetRequest("/user_type/unknown/history", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, int64(0), res.TotalCount)
+}
+
+func TestAuditFindSortValidation(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	CreateUserType(t, mockApp, "test-user-type-1")
+	CreateUserType(t, mockApp, "test-user-type-2")
+
+	res := &resource.AuditEventResourceList{}
+
+	response, err := mockApp.NewGetRequest("/audit?sort_by=id&sort_dir=desc", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, 2, len(res.Data))
+	assert.Equal(t, "test-user-type-2", res.Data[0].EntityKey)
+
+	for _, sortBy := range []string{"actor", "(SELECT 1)", "a.id; DROP TABLE users"} {
+		httpError := &apperror.HttpError{}
+
+		response, err = mockApp.NewGetRequest(
+			"/audit?sort_dir=asc&sort_by="+url.QueryEscape(sortBy),
+			mock.NewMockAppOptions().WithExpectedResponse(httpError),
+		)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, response.Code, sortBy)
+		assert.True(t, httpError.HasErrorCountByNameAndType(1, "sort_by", "oneof"), sortBy)
+	}
+}
+
+func TestAuditActorHeaderCanBeUntrusted(t *testing.T) {
+	appConfig, err := mock.NewDefaultConfigLoader().WithOverrides("auth.trust_actor_header=false").Load()
+
+	assert.Nil(t, err)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	response, err := mockApp.NewPostRequest(
+		"/user_type",
+		mock.NewMockAppOptions().WithBody(resource.UserTypeCreateResource{Name: "test-user-type-1"}).WithHeader("X-Actor", "admin"),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	res := &resource.AuditEventResourceList{}
+
+	response, err = mockApp.NewGetRequest("/user_type/test-user-type-1/history", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, 1, len(res.Data))
+	assert.Equal(t, context.AnonymousActor, res.Data[0].Actor)
+}
+
+// Helper methods
+
+func CreateUser(t *testing.T, mockApp *mock.MockApp, username string, userTypeName string) *resource.UserCreateResource {
+	req := resource.UserCreateResource{
+		Username:     username,
+		UserTypeName: userTypeName,
+		Disabled:     false,
+	}
+	res := &resource.UserResource{}
+
+	response, err := mockApp.NewPostRequest("/user", mock.NewMockAppOptions().WithBody(req).WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.Equal(t, req.Username, res.Username)
+	assert.Equal(t, req.UserTypeName, res.UserType.Name)
+
+	return &req
+}
@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Constants
+
+const (
+	AuthControllerSourceName = "AuthController"
+)
+
+// Structs
+
+type AuthController struct {
+	authService           service.AuthService
+	requestContextFactory *context.RequestContextFactory
+}
+
+// Login Checks the credentials of a user.
+// @Summary Checks the credentials of a user.
+// @Description Checks the username and password of a user and, if the user has or must have a second factor, its TOTP code or one of its recovery codes. After too many failed logins in a row, the user is locked out for a while.
+// @Accept json
+// @Produce json
+// @Param credentials body resource.LoginResource true "Credentials"
+// @Success 200 {object} resource.UserResource
+// @Failure 400 {object} apperror.HttpError
+// @Failure 401 {object} apperror.HttpError
+// @Failure 403 {object} apperror.HttpError
+// @Failure 423 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags auth
+// @Router /auth/login [post]
+func (ctrl *AuthController) Login(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	var req resource.LoginResource
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, AuthControllerSourceName, nil))
+
+		return
+	}
+
+	userResource, err := ctrl.authService.Login(requestContext, &req)
+
+	if err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, userResource)
+}
+
+// RequestPasswordReset Sends a password reset token to a user.
+// @Summary Sends a password reset token to a user.
+// @Description Sends a single-use token to the email of the user, found by username or email. The request is accepted even if the user doesn't exist.
+// @Accept json
+// @Produce json
+// @Param user body resource.PasswordResetRequestResource true "Username or email of the user"
+// @Success 202
+// @Failure 400 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags auth
+// @Router /auth/password-reset/request [post]
+func (ctrl *AuthController) RequestPasswordReset(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	var req resource.PasswordResetRequestResource
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, AuthControllerSourceName, nil))
+
+		return
+	}
+
+	if err := ctrl.authService.RequestPasswordReset(requestContext, &req); err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// ConfirmPasswordReset Sets a new password using a password reset token.
+// @Summary Sets a new password using a password reset token.
+// @Description Sets the new password of the user the token was sent to. The token, and any other pending one of the user, can't be used again. The lockout of the user ends.
+// @Accept json
+// @Produce json
+// @Param reset body resource.PasswordResetConfirmResource true "Token and new password"
+// @Success 200 {object} resource.UserResource
+// @Failure 400 {object} apperror.HttpError
+// @Failure 422 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags auth
+// @Router /auth/password-reset/confirm [post]
+func (ctrl *AuthController) ConfirmPasswordReset(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	var req resource.PasswordResetConfirmResource
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, AuthControllerSourceName, nil))
+
+		return
+	}
+
+	userResource, err := ctrl.authService.ConfirmPasswordReset(requestContext, &req)
+
+	if err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, userResource)
+}
+
+// EnrollMfa Starts the enrollment of a TOTP second factor.
+// @Summary Starts the enrollment of a TOTP second factor.
+// @Description Creates a new TOTP secret for the user, to be added to an authenticator app. The second factor is enabled once the first code generated with it is confirmed.
+// @Accept json
+// @Produce json
+// @Param credentials body resource.MfaEnrollResource true "Credentials"
+// @Success 200 {object} resource.MfaEnrollmentResource
+// @Failure 400 {object} apperror.HttpError
+// @Failure 401 {object} apperror.HttpError
+// @Failure 409 {object} apperror.HttpError
+// @Failure 423 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags auth
+// @Router /auth/mfa/enroll [post]
+func (ctrl *AuthController) EnrollMfa(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	var req resource.MfaEnrollResource
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, AuthControllerSourceName, nil))
+
+		return
+	}
+
+	mfaEnrollmentResource, err := ctrl.authService.EnrollMfa(requestContext, &req)
+
+	if err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, mfaEnrollmentResource)
+}
+
+// ConfirmMfa Enables the enrolled TOTP second factor.
+// @Summary Enables the enrolled TOTP second factor.
+// @Description Enables the second factor of the user with the first code generated by the authenticator app. Returns the recovery codes of the user, which are shown only once.
+// @Accept json
+// @Produce json
+// @Param credentials body resource.MfaConfirmResource true "Credentials and TOTP code"
+// @Success 200 {object} resource.MfaRecoveryCodesResource
+// @Failure 400 {object} apperror.HttpError
+// @Failure 401 {object} apperror.HttpError
+// @Failure 409 {object} apperror.HttpError
+// @Failure 423 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags auth
+// @Router /auth/mfa/confirm [post]
+func (ctrl *AuthController) ConfirmMfa(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	var req resource.MfaConfirmResource
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, AuthControllerSourceName, nil))
+
+		return
+	}
+
+	mfaRecoveryCodesResource, err := ctrl.authService.ConfirmMfa(requestContext, &req)
+
+	if err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, mfaRecoveryCodesResource)
+}
+
+// Static functions
+
+func NewAuthController(authService service.AuthService, requestContextFactory *context.RequestContextFactory) *AuthController {
+	return &AuthController{
+		authService:           authService,
+		requestContextFactory: requestContextFactory,
+	}
+}
@@ -0,0 +1,647 @@
+package controller_test
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/app"
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/mailer"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/module"
+	"github.com/comfortablynumb/goginrestapi/internal/repository"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthLogin(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+	userRes := CreateUserWithPassword(t, mockApp, "test-user-1", userTypeReq.Name, "jane@example.com", "test-password")
+
+	assert.True(t, userRes.HasPassword)
+	assert.NotNil(t, userRes.PasswordChangedAt)
+	assert.False(t, userRes.Locked)
+
+	// Valid credentials
+
+	res := &resource.UserResource{}
+
+	response, err := mockApp.NewPostRequest(
+		"/auth/login",
+		mock.NewMockAppOptions().
+			WithBody(resource.LoginResource{Username: "test-user-1", Password: "test-password"}).
+			WithExpectedResponse(res),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "test-user-1", res.Username)
+
+	// Wrong passwords, unknown users and users without password fail the same way
+
+	CreateUser(t, mockApp, "test-user-2", userTypeReq.Name)
+
+	for _, loginResource := range []resource.LoginResource{
+		{Username: "test-user-1", Password: "wrong-password"},
+		{Username: "unknown-user", Password: "test-password"},
+		{Username: "test-user-2", Password: "test-password"},
+	} {
+		httpError := &apperror.HttpError{}
+
+		response, err = mockApp.NewPostRequest("/auth/login", mock.NewMockAppOptions().WithBody(loginResource).WithExpectedResponse(httpError))
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+		assert.Equal(t, apperror.InvalidCredentialsErrorCode, httpError.Code)
+	}
+
+	// Failed logins are counted, and reset by a successful one
+
+	users := []*resource.UserResource{}
+
+	response, err = mockApp.NewGetRequest("/user?username=test-user-1", mock.NewMockAppOptions().WithExpectedResponse(&users))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Len(t, users, 1)
+	assert.Equal(t, 1, users[0].FailedLoginAttempts)
+
+	res = &resource.UserResource{}
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/login",
+		mock.NewMockAppOptions().
+			WithBody(resource.LoginResource{Username: "test-user-1", Password: "test-password"}).
+			WithExpectedResponse(res),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, 0, res.FailedLoginAttempts)
+
+	// Missing fields
+
+	httpError := &apperror.HttpError{}
+
+	response, err = mockApp.NewPostRequest("/auth/login", mock.NewMockAppOptions().WithBody(resource.LoginResource{}).WithExpectedResponse(httpError))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	// Passwords are validated
+
+	httpError = &apperror.HttpError{}
+
+	response, err = mockApp.NewPostRequest(
+		"/user",
+		mock.NewMockAppOptions().
+			WithBody(resource.UserCreateResource{Username: "test-user-3", UserTypeName: userTypeReq.Name, Password: "short"}).
+			WithExpectedResponse(httpError),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.True(t, httpError.HasErrorCountByNameAndType(1, "password", "min"))
+}
+
+func TestAuthLockout(t *testing.T) {
+	appConfig, err := mock.NewDefaultConfigLoader().WithOverrides("auth.max_failed_logins=3").Load()
+
+	assert.Nil(t, err)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+
+	CreateUserWithPassword(t, mockApp, "test-user-1", userTypeReq.Name, "", "test-password")
+
+	// The last allowed failed login locks the user out
+
+	for i := 1; i <= 3; i++ {
+		httpError := &apperror.HttpError{}
+
+		response, err := mockApp.NewPostRequest(
+			"/auth/login",
+			mock.NewMockAppOptions().
+				WithBody(resource.LoginResource{Username: "test-user-1", Password: "wrong-password"}).
+				WithExpectedResponse(httpError),
+		)
+
+		assert.Nil(t, err)
+
+		if i < 3 {
+			assert.Equal(t, http.StatusUnauthorized, response.Code)
+			assert.Equal(t, apperror.InvalidCredentialsErrorCode, httpError.Code)
+		} else {
+			assert.Equal(t, http.StatusLocked, response.Code)
+			assert.Equal(t, apperror.AccountLockedErrorCode, httpError.Code)
+		}
+	}
+
+	// Even the right password is rejected while locked out
+
+	httpError := &apperror.HttpError{}
+
+	response, err := mockApp.NewPostRequest(
+		"/auth/login",
+		mock.NewMockAppOptions().
+			WithBody(resource.LoginResource{Username: "test-user-1", Password: "test-password"}).
+			WithExpectedResponse(httpError),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusLocked, response.Code)
+	assert.Equal(t, apperror.AccountLockedErrorCode, httpError.Code)
+
+	users := []*resource.UserResource{}
+
+	response, err = mockApp.NewGetRequest("/user?username=test-user-1", mock.NewMockAppOptions().WithExpectedResponse(&users))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Len(t, users, 1)
+	assert.True(t, users[0].Locked)
+	assert.NotNil(t, users[0].LockedUntil)
+
+	// The lockout is audited
+
+	history := &resource.AuditEventResourceList{}
+
+	response, err = mockApp.NewGetRequest("/user/test-user-1/history", mock.NewMockAppOptions().WithExpectedResponse(history))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, 2, len(history.Data))
+	assert.Equal(t, model.AuditActionUpdate, history.Data[1].Action)
+	assert.Equal(t, false, history.Data[1].Changes["locked"].Before)
+	assert.Equal(t, true, history.Data[1].Changes["locked"].After)
+
+	// Admins can unlock users
+
+	res := &resource.UserResource{}
+
+	response, err = mockApp.NewPostRequest("/user/test-user-1/unlock", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.False(t, res.Locked)
+	assert.Nil(t, res.LockedUntil)
+	assert.Equal(t, 0, res.FailedLoginAttempts)
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/login",
+		mock.NewMockAppOptions().WithBody(resource.LoginResource{Username: "test-user-1", Password: "test-password"}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	history = &resource.AuditEventResourceList{}
+
+	response, err = mockApp.NewGetRequest("/user/test-user-1/history", mock.NewMockAppOptions().WithExpectedResponse(history))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, 3, len(history.Data))
+	assert.Equal(t, true, history.Data[2].Changes["locked"].Before)
+	assert.Equal(t, false, history.Data[2].Changes["locked"].After)
+
+	// Unknown users can't be unlocked
+
+	response, err = mockApp.NewPostRequest("/user/unknown-user/unlock", mock.NewMockAppOptions())
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestAuthLockoutCountsConcurrentFailedLogins(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+
+	CreateUserWithPassword(t, mockApp, "test-user-1", userTypeReq.Name, "", "test-password")
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	requestContext := componentRegistry.RequestContextFactory.NewBackgroundRequestContext()
+	userRepository, err := componentregistry.Get[repository.UserRepository](componentRegistry, module.UserRepositoryComponentName)
+
+	assert.Nil(t, err)
+
+	// Both failed logins read the user before any of them is recorded, so both see no failed logins
+
+	first, appErr := userRepository.FindOneByUsername(requestContext, "test-user-1")
+
+	assert.Nil(t, appErr)
+
+	second, appErr := userRepository.FindOneByUsername(requestContext, "test-user-1")
+
+	assert.Nil(t, appErr)
+
+	now := time.Now().UTC()
+	recordFailedLogin := func(user *model.User) bool {
+		locked := false
+
+		appErr := componentRegistry.TransactionService.RunInTransaction(requestContext, func() *apperror.AppError {
+			var appErr *apperror.AppError
+
+			locked, appErr = userRepository.RecordFailedLogin(requestContext, user, 2, now, now.Add(time.Hour))
+
+			return appErr
+		})
+
+		assert.Nil(t, appErr)
+
+		return locked
+	}
+
+	assert.False(t, recordFailedLogin(first))
+	assert.Equal(t, 1, first.FailedLoginAttempts)
+	assert.Nil(t, first.LockedUntil)
+
+	// The second one is counted on top of the first one, and locks the user out
+
+	assert.True(t, recordFailedLogin(second))
+	assert.Equal(t, 2, second.FailedLoginAttempts)
+	assert.NotNil(t, second.LockedUntil)
+
+	// Further failed logins are counted, but don't lock the user out again
+
+	assert.False(t, recordFailedLogin(first))
+	assert.Equal(t, 3, first.FailedLoginAttempts)
+	assert.NotNil(t, first.LockedUntil)
+}
+
+func TestAuthPasswordResetTokensAreUsedOnce(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+
+	CreateUserWithPassword(t, mockApp, "test-user-1", userTypeReq.Name, "", "test-password")
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	requestContext := componentRegistry.RequestContextFactory.NewBackgroundRequestContext()
+	userRepository, err := componentregistry.Get[repository.UserRepository](componentRegistry, module.UserRepositoryComponentName)
+
+	assert.Nil(t, err)
+
+	passwordResetTokenRepository, err := componentregistry.Get[repository.PasswordResetTokenRepository](
+		componentRegistry,
+		module.PasswordResetTokenRepositoryComponentName,
+	)
+
+	assert.Nil(t, err)
+
+	user, appErr := userRepository.FindOneByUsername(requestContext, "test-user-1")
+
+	assert.Nil(t, appErr)
+
+	now := time.Now().UTC()
+	passwordResetToken := model.NewPasswordResetTokenBuilder().
+		WithUserID(user.ID).
+		WithTokenHash("test-token-hash").
+		WithExpiresAt(now.Add(time.Hour)).
+		WithCreatedAt(now).
+		Build()
+
+	assert.Nil(t, passwordResetTokenRepository.Create(requestContext, passwordResetToken))
+
+	// Both requests read the token before any of them uses it, so both see it unused
+
+	first, appErr := passwordResetTokenRepository.FindOneByTokenHash(requestContext, "test-token-hash")
+
+	assert.Nil(t, appErr)
+	assert.True(t, first.IsUsable(now))
+
+	second, appErr := passwordResetTokenRepository.FindOneByTokenHash(requestContext, "test-token-hash")
+
+	assert.Nil(t, appErr)
+	assert.True(t, second.IsUsable(now))
+
+	// Only the first one can use it
+
+	used, appErr := passwordResetTokenRepository.MarkUsed(requestContext, first, now)
+
+	assert.Nil(t, appErr)
+	assert.True(t, used)
+
+	used, appErr = passwordResetTokenRepository.MarkUsed(requestContext, second, now)
+
+	assert.Nil(t, appErr)
+	assert.False(t, used)
+	assert.Nil(t, second.UsedAt)
+}
+
+func TestAuthLockoutExpires(t *testing.T) {
+	appConfig, err := mock.NewDefaultConfigLoader().WithOverrides("auth.max_failed_logins=1", "auth.lockout_duration=1ns").Load()
+
+	assert.Nil(t, err)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+
+	CreateUserWithPassword(t, mockApp, "test-user-1", userTypeReq.Name, "", "test-password")
+
+	response, err := mockApp.NewPostRequest(
+		"/auth/login",
+		mock.NewMockAppOptions().WithBody(resource.LoginResource{Username: "test-user-1", Password: "wrong-password"}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusLocked, response.Code)
+
+	// Expired lockouts are not shown, and don't prevent logins
+
+	users := []*resource.UserResource{}
+
+	response, err = mockApp.NewGetRequest("/user?username=test-user-1", mock.NewMockAppOptions().WithExpectedResponse(&users))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Len(t, users, 1)
+	assert.False(t, users[0].Locked)
+	assert.Nil(t, users[0].LockedUntil)
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/login",
+		mock.NewMockAppOptions().WithBody(resource.LoginResource{Username: "test-user-1", Password: "test-password"}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+}
+
+func TestAuthLockoutExpiryRestartsTheFailedLogins(t *testing.T) {
+	appConfig, err := mock.NewDefaultConfigLoader().WithOverrides("auth.max_failed_logins=3", "auth.lockout_duration=15m").Load()
+
+	assert.Nil(t, err)
+
+	clock := mock.NewFakeTimeService(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	mockApp := mock.NewMockApp(appConfig, app.WithTimeService(clock))
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+
+	CreateUserWithPassword(t, mockApp, "test-user-1", userTypeReq.Name, "", "test-password")
+
+	login := func(password string) int {
+		response, err := mockApp.NewPostRequest(
+			"/auth/login",
+			mock.NewMockAppOptions().WithBody(resource.LoginResource{Username: "test-user-1", Password: password}),
+		)
+
+		assert.Nil(t, err)
+
+		return response.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, login("wrong-password"))
+	assert.Equal(t, http.StatusUnauthorized, login("wrong-password"))
+	assert.Equal(t, http.StatusLocked, login("wrong-password"))
+	assert.Equal(t, http.StatusLocked, login("test-password"))
+
+	// Once the lockout expires, the user gets every attempt again
+
+	clock.Advance(16 * time.Minute)
+
+	assert.Equal(t, http.StatusUnauthorized, login("wrong-password"))
+	assert.Equal(t, http.StatusUnauthorized, login("wrong-password"))
+	assert.Equal(t, http.StatusOK, login("test-password"))
+}
+
+func TestAuthPasswordReset(t *testing.T) {
+	appConfig, err := mock.NewDefaultConfigLoader().WithOverrides("auth.max_failed_logins=1").Load()
+
+	assert.Nil(t, err)
+
+	memoryMailer := mailer.NewMemoryMailer()
+	mockApp := mock.NewMockApp(appConfig, withMailer(memoryMailer))
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+
+	CreateUserWithPassword(t, mockApp, "test-user-1", userTypeReq.Name, "jane@example.com", "test-password")
+
+	// The user is locked out
+
+	response, err := mockApp.NewPostRequest(
+		"/auth/login",
+		mock.NewMockAppOptions().WithBody(resource.LoginResource{Username: "test-user-1", Password: "wrong-password"}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusLocked, response.Code)
+
+	// Requests for unknown users are accepted, but nothing is sent
+
+	messageCount := len(memoryMailer.GetMessages())
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/password-reset/request",
+		mock.NewMockAppOptions().WithBody(resource.PasswordResetRequestResource{Email: "unknown@example.com"}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusAccepted, response.Code)
+	assert.Len(t, memoryMailer.GetMessages(), messageCount)
+
+	// Users can be found by username or email
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/password-reset/request",
+		mock.NewMockAppOptions().WithBody(resource.PasswordResetRequestResource{Username: "test-user-1"}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusAccepted, response.Code)
+
+	firstToken := getPasswordResetToken(t, memoryMailer.GetLastMessageTo("jane@example.com"))
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/password-reset/request",
+		mock.NewMockAppOptions().WithBody(resource.PasswordResetRequestResource{Email: "JANE@example.com"}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusAccepted, response.Code)
+
+	message := memoryMailer.GetLastMessageTo("jane@example.com")
+	secondToken := getPasswordResetToken(t, message)
+
+	assert.Equal(t, "Reset your password", message.Subject)
+	assert.NotEqual(t, firstToken, secondToken)
+
+	// Invalid tokens
+
+	httpError := &apperror.HttpError{}
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/password-reset/confirm",
+		mock.NewMockAppOptions().
+			WithBody(resource.PasswordResetConfirmResource{Token: "invalid-token", Password: "new-password"}).
+			WithExpectedResponse(httpError),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	assert.Equal(t, apperror.InvalidTokenErrorCode, httpError.Code)
+
+	// The new password is set, and the lockout ends
+
+	res := &resource.UserResource{}
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/password-reset/confirm",
+		mock.NewMockAppOptions().
+			WithBody(resource.PasswordResetConfirmResource{Token: secondToken, Password: "new-password"}).
+			WithExpectedResponse(res),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.False(t, res.Locked)
+	assert.Equal(t, 0, res.FailedLoginAttempts)
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/login",
+		mock.NewMockAppOptions().WithBody(resource.LoginResource{Username: "test-user-1", Password: "new-password"}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	// Tokens are single-use, and the other pending tokens of the user are used up too
+
+	for _, token := range []string{secondToken, firstToken} {
+		httpError = &apperror.HttpError{}
+
+		response, err = mockApp.NewPostRequest(
+			"/auth/password-reset/confirm",
+			mock.NewMockAppOptions().
+				WithBody(resource.PasswordResetConfirmResource{Token: token, Password: "other-password"}).
+				WithExpectedResponse(httpError),
+		)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+		assert.Equal(t, apperror.InvalidTokenErrorCode, httpError.Code)
+	}
+
+	// Either the username or the email is required
+
+	httpError = &apperror.HttpError{}
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/password-reset/request",
+		mock.NewMockAppOptions().WithBody(resource.PasswordResetRequestResource{}).WithExpectedResponse(httpError),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.True(t, httpError.HasErrorCountByNameAndType(1, "username", "required_without"))
+}
+
+func TestAuthPasswordResetExpiredToken(t *testing.T) {
+	appConfig, err := mock.NewDefaultConfigLoader().WithOverrides("auth.reset_token_ttl=1ns").Load()
+
+	assert.Nil(t, err)
+
+	memoryMailer := mailer.NewMemoryMailer()
+	mockApp := mock.NewMockApp(appConfig, withMailer(memoryMailer))
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+
+	CreateUserWithPassword(t, mockApp, "test-user-1", userTypeReq.Name, "jane@example.com", "test-password")
+
+	response, err := mockApp.NewPostRequest(
+		"/auth/password-reset/request",
+		mock.NewMockAppOptions().WithBody(resource.PasswordResetRequestResource{Username: "test-user-1"}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusAccepted, response.Code)
+
+	httpError := &apperror.HttpError{}
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/password-reset/confirm",
+		mock.NewMockAppOptions().
+			WithBody(resource.PasswordResetConfirmResource{
+				Token:    getPasswordResetToken(t, memoryMailer.GetLastMessageTo("jane@example.com")),
+				Password: "new-password",
+			}).
+			WithExpectedResponse(httpError),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	assert.Equal(t, apperror.InvalidTokenErrorCode, httpError.Code)
+}
+
+var passwordResetTokenRegexp = regexp.MustCompile(`: ([A-Za-z0-9_-]{43})\n`)
+
+func getPasswordResetToken(t *testing.T, message *mailer.Message) string {
+	if !assert.NotNil(t, message) {
+		return ""
+	}
+
+	matches := passwordResetTokenRegexp.FindStringSubmatch(message.Body)
+
+	if !assert.Len(t, matches, 2, message.Body) {
+		return ""
+	}
+
+	return matches[1]
+}
+
+func CreateUserWithPassword(t *testing.T, mockApp *mock.MockApp, username string, userTypeName string, email string, password string) *resource.UserResource {
+	res := &resource.UserResource{}
+
+	response, err := mockApp.NewPostRequest(
+		"/user",
+		mock.NewMockAppOptions().
+			WithBody(resource.UserCreateResource{Username: username, UserTypeName: userTypeName, Email: email, Password: password}).
+			WithExpectedResponse(res),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	return res
+}
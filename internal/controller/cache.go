@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Structs
+
+type CacheController struct {
+	cacheService          service.CacheService
+	requestContextFactory *context.RequestContextFactory
+}
+
+// Stats Returns the hit / miss stats of the caches.
+// @Summary Returns the hit / miss stats of the caches.
+// @Description Allows you to see how effective every cache of the app is.
+// @Produce json
+// @Success 200 {object} resource.CacheStatsResourceList
+// @Failure 500 {object} apperror.HttpError
+// @Tags cache
+// @Router /cache/stats [get]
+func (ctrl *CacheController) Stats(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+
+	c.JSON(http.StatusOK, ctrl.cacheService.GetStats(requestContext))
+}
+
+// Static functions
+
+func NewCacheController(cacheService service.CacheService, requestContextFactory *context.RequestContextFactory) *CacheController {
+	return &CacheController{
+		cacheService:          cacheService,
+		requestContextFactory: requestContextFactory,
+	}
+}
@@ -0,0 +1,277 @@
+package controller_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/cache"
+	"github.com/comfortablynumb/goginrestapi/internal/middleware"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/comfortablynumb/goginrestapi/internal/redis"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheUserTypeLookupsAreInvalidatedByMutations(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	CreateUserType(t, mockApp, "test-user-type-1")
+	CreateUser(t, mockApp, "test-user-1", "test-user-type-1")
+	CreateUser(t, mockApp, "test-user-2", "test-user-type-1")
+
+	repositoryCache := mockApp.App.GetComponentRegistry().CacheManager.Get(cache.RepositoryCacheName)
+
+	assert.Equal(t, int64(1), repositoryCache.GetStats().Hits)
+
+	// Once the user type is renamed, the old name must not be found on the cache anymore
+
+	response, err := mockApp.NewPutRequest(
+		"/user_type/test-user-type-1",
+		mock.NewMockAppOptions().WithBody(resource.UserTypeUpdateResource{Name: "test-user-type-2"}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, int64(0), repositoryCache.GetStats().Entries)
+
+	res := &apperror.HttpError{}
+	req := resource.UserCreateResource{
+		Username:     "test-user-3",
+		UserTypeName: "test-user-type-1",
+	}
+
+	response, err = mockApp.NewPostRequest("/user", mock.NewMockAppOptions().WithBody(req).WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.True(t, res.HasErrorCountByNameAndType(1, "user_type_name", "user_type"))
+
+	CreateUser(t, mockApp, "test-user-3", "test-user-type-2")
+}
+
+func TestCacheListResponsesAreInvalidatedByMutations(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	CreateUserType(t, mockApp, "test-user-type-1")
+
+	res := &resource.UserTypeResourceList{}
+
+	response, err := mockApp.NewGetRequest("/user_type", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, middleware.CacheStatusMiss, response.Header().Get(middleware.CacheStatusHeader))
+	assert.Equal(t, int64(1), res.TotalCount)
+
+	response, err = mockApp.NewGetRequest("/user_type", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, middleware.CacheStatusHit, response.Header().Get(middleware.CacheStatusHeader))
+	assert.Equal(t, int64(1), res.TotalCount)
+
+	// Different query strings are cached separately
+
+	response, err = mockApp.NewGetRequest("/user_type?name=test-user-type-1", nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, middleware.CacheStatusMiss, response.Header().Get(middleware.CacheStatusHeader))
+
+	CreateUserType(t, mockApp, "test-user-type-2")
+
+	response, err = mockApp.NewGetRequest("/user_type", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, middleware.CacheStatusMiss, response.Header().Get(middleware.CacheStatusHeader))
+	assert.Equal(t, int64(2), res.TotalCount)
+
+	// Stats
+
+	statsRes := &resource.CacheStatsResourceList{}
+
+	response, err = mockApp.NewGetRequest("/cache/stats", mock.NewMockAppOptions().WithExpectedResponse(statsRes))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	found := false
+
+	for _, stats := range statsRes.Data {
+		if stats.Name == cache.ResponseCacheName {
+			found = true
+
+			assert.Equal(t, int64(1), stats.Hits)
+			assert.Equal(t, int64(3), stats.Misses)
+			assert.Equal(t, 0.25, stats.HitRatio)
+		}
+	}
+
+	assert.True(t, found)
+}
+
+func TestCacheMemoryCacheEvictsLeastRecentlyUsedAndExpiredEntries(t *testing.T) {
+	now := time.Now()
+	memoryCache := cache.NewMemoryCache("test", 2, func() time.Time { return now })
+
+	memoryCache.Set(cache.Key("tag", "1"), []byte("1"), time.Minute)
+	memoryCache.Set(cache.Key("tag", "2"), []byte("2"), time.Hour)
+
+	// Reading the first entry makes the second one the least recently used
+
+	_, found := memoryCache.Get(cache.Key("tag", "1"))
+
+	assert.True(t, found)
+
+	memoryCache.Set(cache.Key("other", "3"), []byte("3"), time.Hour)
+
+	_, found = memoryCache.Get(cache.Key("tag", "2"))
+
+	assert.False(t, found)
+
+	// Expired entries are not returned
+
+	now = now.Add(time.Minute)
+
+	_, found = memoryCache.Get(cache.Key("tag", "1"))
+
+	assert.False(t, found)
+
+	value, found := memoryCache.Get(cache.Key("other", "3"))
+
+	assert.True(t, found)
+	assert.Equal(t, []byte("3"), value)
+
+	memoryCache.DeleteByTag("other")
+
+	_, found = memoryCache.Get(cache.Key("other", "3"))
+
+	assert.False(t, found)
+	assert.Equal(t, cache.Stats{Name: "test", Hits: 2, Misses: 3, Entries: 0}, memoryCache.GetStats())
+}
+
+func TestCacheRedisBackendStoresTheResponsesOnRedis(t *testing.T) {
+	redisServer, err := miniredis.Run()
+
+	assert.Nil(t, err)
+
+	defer redisServer.Close()
+
+	appConfig, err := mock.NewDefaultConfigLoader().
+		WithOverrides("cache.backend=redis", "redis.address="+redisServer.Addr(), "redis.prefix=test:").
+		Load()
+
+	assert.Nil(t, err)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+		mockApp.App.Close()
+	}()
+
+	assert.IsType(t, &cache.RedisCache{}, mockApp.App.GetComponentRegistry().CacheManager.Get(cache.ResponseCacheName))
+
+	CreateUserType(t, mockApp, "test-user-type-1")
+
+	response, err := mockApp.NewGetRequest("/user_type", nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, middleware.CacheStatusMiss, response.Header().Get(middleware.CacheStatusHeader))
+
+	keys := redisServer.Keys()
+
+	assert.NotEmpty(t, keys)
+
+	for _, key := range keys {
+		assert.Regexp(t, "^test:", key)
+	}
+
+	response, err = mockApp.NewGetRequest("/user_type", nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, middleware.CacheStatusHit, response.Header().Get(middleware.CacheStatusHeader))
+
+	// Mutations invalidate the responses stored on Redis too
+
+	CreateUserType(t, mockApp, "test-user-type-2")
+
+	res := &resource.UserTypeResourceList{}
+
+	response, err = mockApp.NewGetRequest("/user_type", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, middleware.CacheStatusMiss, response.Header().Get(middleware.CacheStatusHeader))
+	assert.Equal(t, int64(2), res.TotalCount)
+}
+
+func TestCacheRedisCacheExpiresAndDeletesEntries(t *testing.T) {
+	redisServer, err := miniredis.Run()
+
+	assert.Nil(t, err)
+
+	defer redisServer.Close()
+
+	appConfig := mock.NewDefaultConfig()
+	appConfig.Redis.Address = redisServer.Addr()
+	appConfig.Redis.Prefix = "test:"
+
+	client := redis.NewClient(appConfig.Redis)
+
+	defer client.Close()
+
+	logger := zerolog.Nop()
+	redisCache := cache.NewRedisCache("test", client, &logger)
+
+	redisCache.Set(cache.Key("tag", "1"), []byte("1"), time.Minute)
+	redisCache.Set(cache.Key("tag", "2"), []byte("2"), time.Hour)
+	redisCache.Set(cache.Key("other", "3"), []byte("3"), time.Hour)
+
+	assert.True(t, redisServer.Exists("test:test:tag:1"))
+
+	value, found := redisCache.Get(cache.Key("tag", "1"))
+
+	assert.True(t, found)
+	assert.Equal(t, []byte("1"), value)
+
+	// Expired entries are not returned
+
+	redisServer.FastForward(time.Minute)
+
+	_, found = redisCache.Get(cache.Key("tag", "1"))
+
+	assert.False(t, found)
+
+	redisCache.DeleteByTag("tag")
+
+	_, found = redisCache.Get(cache.Key("tag", "2"))
+
+	assert.False(t, found)
+
+	redisCache.Delete(cache.Key("other", "3"))
+
+	_, found = redisCache.Get(cache.Key("other", "3"))
+
+	assert.False(t, found)
+
+	// Redis errors are treated as misses
+
+	redisServer.SetError("unavailable")
+
+	_, found = redisCache.Get(cache.Key("tag", "2"))
+
+	assert.False(t, found)
+	assert.Equal(t, cache.Stats{Name: "test", Hits: 1, Misses: 4, Entries: -1}, redisCache.GetStats())
+}
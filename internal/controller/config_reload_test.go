@@ -0,0 +1,250 @@
+package controller_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/middleware"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/comfortablynumb/goginrestapi/internal/worker"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigReload(t *testing.T) {
+	dir := createConfigTestDir(t)
+
+	defer os.RemoveAll(dir)
+
+	configFile := writeConfigTestFile(t, dir, "config.yaml", `
+server:
+  port: 8080
+rate_limits:
+  user_type: 1/1m
+cors:
+  allowed_origins: [https://a.example.com]
+features:
+  beta: false
+`)
+
+	appConfig, err := config.NewLoader().
+		WithEnviron([]string{}).
+		WithFiles(configFile).
+		WithOverrides(
+			"environment=test",
+			fmt.Sprintf("db.migrations_path=file://%s", mock.GetMigrationsAbsolutePath()),
+		).
+		Load()
+
+	assert.Nil(t, err)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	runtimeConfig := mockApp.App.GetComponentRegistry().RuntimeConfig
+
+	assert.False(t, runtimeConfig.IsFeatureEnabled("beta"))
+
+	for _, expectedStatus := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		response, err := mockApp.NewGetRequest("/user_type", nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expectedStatus, response.Code)
+	}
+
+	response, err := mockApp.NewGetRequest("/user_type/test", mock.NewMockAppOptions().WithHeader("Origin", "https://a.example.com"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://a.example.com", response.Header().Get("Access-Control-Allow-Origin"))
+
+	response, err = mockApp.NewGetRequest("/user", mock.NewMockAppOptions().WithHeader("Origin", "https://b.example.com"))
+
+	assert.Nil(t, err)
+	assert.Empty(t, response.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "120", response.Header().Get(middleware.RateLimitLimitHeader))
+
+	// Reloadable settings are applied, and the rest are ignored
+
+	writeConfigTestFile(t, dir, "config.yaml", `
+server:
+  port: 9090
+log:
+  level: info
+rate_limits:
+  user_type: 5/1m
+  user: 10/1m
+cors:
+  allowed_origins: [https://b.example.com]
+features:
+  beta: true
+`)
+
+	assert.Nil(t, mockApp.App.ReloadConfig())
+	assert.Equal(t, 8080, mockApp.App.GetConfig().Server.Port)
+	assert.Equal(t, "info", mockApp.App.GetConfig().Log.Level)
+	assert.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+	assert.True(t, runtimeConfig.IsFeatureEnabled("beta"))
+
+	response, err = mockApp.NewGetRequest("/user_type", nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "5", response.Header().Get(middleware.RateLimitLimitHeader))
+
+	response, err = mockApp.NewGetRequest("/user", mock.NewMockAppOptions().WithHeader("Origin", "https://b.example.com"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://b.example.com", response.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "10", response.Header().Get(middleware.RateLimitLimitHeader))
+
+	response, err = mockApp.NewRequest(
+		http.MethodOptions,
+		"/user",
+		mock.NewMockAppOptions().
+			WithHeader("Origin", "https://b.example.com").
+			WithHeader("Access-Control-Request-Method", http.MethodPost),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Contains(t, response.Header().Get("Access-Control-Allow-Headers"), "Idempotency-Key")
+	assert.Equal(t, "43200", response.Header().Get("Access-Control-Max-Age"))
+
+	// Invalid configs are rejected as a whole
+
+	writeConfigTestFile(t, dir, "config.yaml", `
+log:
+  level: warn
+rate_limits:
+  user_type: invalid
+`)
+
+	assert.NotNil(t, mockApp.App.ReloadConfig())
+	assert.Equal(t, "info", mockApp.App.GetConfig().Log.Level)
+	assert.Equal(t, "5/1m", mockApp.App.GetConfig().RateLimits["user_type"])
+
+	// Reloads are counted
+
+	response, err = mockApp.NewGetRequest("/metrics", nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "# TYPE config_reloads_total counter\n")
+	assert.Contains(t, response.Body.String(), "config_reloads_total{result=\"success\"} 1\n")
+	assert.Contains(t, response.Body.String(), "config_reloads_total{result=\"failure\"} 1\n")
+}
+
+func TestConfigReloadWorker(t *testing.T) {
+	dir := createConfigTestDir(t)
+
+	defer os.RemoveAll(dir)
+
+	configFile := writeConfigTestFile(t, dir, "config.yaml", "log:\n  level: debug\n")
+	reloads := make(chan struct{}, 10)
+	logger := zerolog.Nop()
+
+	reloadWorker := worker.NewFileWatcherWorker(
+		"TestConfigReloadWorker",
+		[]string{configFile},
+		[]os.Signal{syscall.SIGHUP},
+		func() error {
+			reloads <- struct{}{}
+
+			return nil
+		},
+		&logger,
+	)
+
+	reloadWorker.Start()
+
+	defer reloadWorker.Stop()
+
+	// Changes to the file trigger a reload
+
+	writeConfigTestFile(t, dir, "config.yaml", "log:\n  level: info\n")
+
+	assertConfigReloaded(t, reloads)
+
+	// Changes to other files of the directory don't
+
+	writeConfigTestFile(t, dir, "other.yaml", "log:\n  level: info\n")
+
+	select {
+	case <-reloads:
+		assert.Fail(t, "Changes to other files must not trigger a reload.")
+	case <-time.After(3 * worker.FileWatcherDebounce):
+	}
+
+	// SIGHUP triggers a reload
+
+	process, err := os.FindProcess(os.Getpid())
+
+	assert.Nil(t, err)
+	assert.Nil(t, process.Signal(syscall.SIGHUP))
+
+	assertConfigReloaded(t, reloads)
+}
+
+func TestConfigReloadWorkerDetectsSwappedSymlinks(t *testing.T) {
+	dir := createConfigTestDir(t)
+
+	defer os.RemoveAll(dir)
+
+	// Same layout as a mounted Kubernetes ConfigMap: the file links to "..data/config.yaml", and "..data" links to the
+	// directory of the current version, which is replaced by renaming a new link over it.
+
+	writeConfigMapVersion(t, dir, "..version_1", "log:\n  level: debug\n")
+
+	assert.Nil(t, os.Symlink("..version_1", filepath.Join(dir, "..data")))
+	assert.Nil(t, os.Symlink(filepath.Join("..data", "config.yaml"), filepath.Join(dir, "config.yaml")))
+
+	reloads := make(chan struct{}, 10)
+	logger := zerolog.Nop()
+
+	reloadWorker := worker.NewFileWatcherWorker(
+		"TestConfigReloadWorkerDetectsSwappedSymlinks",
+		[]string{filepath.Join(dir, "config.yaml")},
+		nil,
+		func() error {
+			reloads <- struct{}{}
+
+			return nil
+		},
+		&logger,
+	)
+
+	reloadWorker.Start()
+
+	defer reloadWorker.Stop()
+
+	writeConfigMapVersion(t, dir, "..version_2", "log:\n  level: info\n")
+
+	assert.Nil(t, os.Symlink("..version_2", filepath.Join(dir, "..data_tmp")))
+	assert.Nil(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+
+	assertConfigReloaded(t, reloads)
+}
+
+func writeConfigMapVersion(t *testing.T, dir string, version string, content string) {
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, version), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, version, "config.yaml"), []byte(content), 0644))
+}
+
+func assertConfigReloaded(t *testing.T, reloads chan struct{}) {
+	select {
+	case <-reloads:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "The config was not reloaded.")
+	}
+}
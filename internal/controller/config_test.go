@@ -0,0 +1,212 @@
+package controller_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/comfortablynumb/goginrestapi/internal/module"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigPrecedence(t *testing.T) {
+	dir := createConfigTestDir(t)
+
+	defer os.RemoveAll(dir)
+
+	yamlFile := writeConfigTestFile(t, dir, "config.yaml", `
+server:
+  port: 8081
+log:
+  level: info
+db:
+  migrations_path: file://from-yaml
+rate_limits:
+  user_type: 5/1m
+`)
+	tomlFile := writeConfigTestFile(t, dir, "config.toml", `
+[server]
+port = 8082
+
+[cache]
+response_ttl = "10s"
+`)
+	secretFile := writeConfigTestFile(t, dir, "db_uri", "file:secret.db?mode=memory\n")
+
+	appConfig, err := config.NewLoader().
+		WithFiles(yamlFile, tomlFile).
+		WithEnviron([]string{
+			"MYAPP_SERVER_PORT=8083",
+			"MYAPP_LOG_LEVEL=warn",
+			"MYAPP_DB_URI_FILE=" + secretFile,
+			"OTHER_SERVER_PORT=1",
+		}).
+		WithOverrides("server.port=8084").
+		Load()
+
+	assert.Nil(t, err)
+
+	// Overrides > environment variables > later files > earlier files > defaults
+
+	assert.Equal(t, 8084, appConfig.Server.Port)
+	assert.Equal(t, "warn", appConfig.Log.Level)
+	assert.Equal(t, "file:secret.db?mode=memory", appConfig.Db.Uri)
+	assert.Equal(t, "file://from-yaml", appConfig.Db.MigrationsPath)
+	assert.Equal(t, 10*time.Second, appConfig.Cache.ResponseTTL)
+	assert.Equal(t, 5*time.Minute, appConfig.Cache.RepositoryTTL)
+	assert.Equal(t, "development", appConfig.Environment)
+
+	// Maps are merged key by key
+
+	assert.Equal(t, map[string]string{"user": "120/1m", "user_type": "5/1m"}, appConfig.RateLimits)
+
+	// The config file can be set on an environment variable too
+
+	appConfig, err = config.NewLoader().WithEnviron([]string{config.ConfigFileEnvVar + "=" + yamlFile}).Load()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 8081, appConfig.Server.Port)
+
+	// The environment variables of older versions are still read, unless the new ones are set
+
+	appConfig, err = config.NewLoader().
+		WithEnviron([]string{
+			"MYAPP_PORT=8085",
+			"MYAPP_LOGLEVEL=error",
+			"MYAPP_DBURI=file:legacy.db?mode=memory",
+			"MYAPP_DBMIGRATIONSPATH=file://legacy",
+			"MYAPP_DEFAULTLOCALE=es",
+			"MYAPP_DEFAULTLIMIT=20",
+			"MYAPP_DB_MIGRATIONS_PATH=file://new",
+			"MYAPP_DB_URI_FILE=" + secretFile,
+		}).
+		Load()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 8085, appConfig.Server.Port)
+	assert.Equal(t, "error", appConfig.Log.Level)
+	assert.Equal(t, "file:secret.db?mode=memory", appConfig.Db.Uri)
+	assert.Equal(t, "file://new", appConfig.Db.MigrationsPath)
+	assert.Equal(t, "es", appConfig.DefaultLocale)
+	assert.Equal(t, 20, appConfig.DefaultLimit)
+}
+
+func TestConfigValidationErrors(t *testing.T) {
+	_, err := config.NewLoader().
+		WithEnviron([]string{}).
+		WithOverrides(
+			"server.port=70000",
+			"log.level=verbose",
+			"cache.repository_ttl=0s",
+			"rate_limits.user=abc",
+			"auth.actor_header=",
+		).
+		Load()
+
+	validationErr, ok := err.(*config.ValidationError)
+
+	assert.True(t, ok)
+	assert.ElementsMatch(t, []string{
+		"server.port: must be at most 65535",
+		"log.level: must be one of: trace, debug, info, warn, error, fatal, panic",
+		"cache.repository_ttl: must be greater than 0",
+		"rate_limits[user]: must be a rate limit policy like '100/1m [algorithm] [key]', got 'abc'",
+		"auth.actor_header: is required",
+	}, validationErr.Errors)
+
+	// Unknown keys and invalid values are rejected too
+
+	_, err = config.NewLoader().WithEnviron([]string{}).WithOverrides("server.prot=8080").Load()
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "prot")
+
+	_, err = config.NewLoader().WithEnviron([]string{"MYAPP_SERVER_PORT=abc"}).Load()
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "server.port")
+
+	// A value can't be set both directly and from a file
+
+	_, err = config.NewLoader().WithEnviron([]string{"MYAPP_DB_URI=file:a.db", "MYAPP_DB_URI_FILE=/tmp/uri"}).Load()
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "only one of MYAPP_DB_URI and MYAPP_DB_URI_FILE can be set")
+
+	_, err = config.NewLoader().WithEnviron([]string{}).WithOverrides("server.port").Load()
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "it must be KEY=VALUE")
+}
+
+func TestConfigModuleSections(t *testing.T) {
+	dir := createConfigTestDir(t)
+
+	defer os.RemoveAll(dir)
+
+	configFile := writeConfigTestFile(t, dir, "config.json", `{"webhook": {"delivery_interval": "5s", "retry_max_delay": "2h"}}`)
+
+	appConfig, err := mock.NewDefaultConfigLoader().
+		WithFiles(configFile).
+		WithEnviron([]string{"MYAPP_IDEMPOTENCY_GC_INTERVAL=2h"}).
+		Load()
+
+	assert.Nil(t, err)
+
+	appConfig.Set("idempotency.lock_timeout", 5*time.Second)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	webhookConfig := mockApp.App.GetConfig().GetSection(module.WebhookModuleName).(*service.WebhookConfig)
+	streamConfig := mockApp.App.GetConfig().GetSection(module.StreamModuleName).(*service.StreamConfig)
+	idempotencyConfig := mockApp.App.GetConfig().GetSection(module.IdempotencyModuleName).(*service.IdempotencyConfig)
+
+	assert.Equal(t, 5*time.Second, webhookConfig.DeliveryInterval)
+	assert.Equal(t, 2*time.Hour, webhookConfig.RetryMaxDelay)
+	assert.Equal(t, 3, webhookConfig.MaxAttempts)
+	assert.Equal(t, 100, streamConfig.ReplayBufferSize)
+	assert.Equal(t, 2*time.Hour, idempotencyConfig.GCInterval)
+	assert.Equal(t, 5*time.Second, idempotencyConfig.LockTimeout)
+	assert.Equal(t, 24*time.Hour, idempotencyConfig.KeyTTL)
+
+	// Invalid sections, and sections which belong to no module, prevent the app from being set up
+
+	appConfig = mock.NewDefaultConfig().Set("webhook.max_attempts", 0)
+
+	assert.Panics(t, func() {
+		mock.NewMockApp(appConfig)
+	})
+
+	appConfig = mock.NewDefaultConfig().Set("webhooks.max_attempts", 1)
+
+	assert.Panics(t, func() {
+		mock.NewMockApp(appConfig)
+	})
+
+	assert.Equal(t, []string{"webhooks"}, appConfig.GetUnknownSections())
+}
+
+func createConfigTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "config")
+
+	assert.Nil(t, err)
+
+	return dir
+}
+
+func writeConfigTestFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+	return path
+}
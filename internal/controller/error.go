@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/gin-gonic/gin"
+)
+
+// Constants
+
+const (
+	ErrorControllerSourceName = "ErrorController"
+)
+
+// Structs
+
+// ErrorController Serves the catalog of error codes, which documents the types of the problem details.
+type ErrorController struct {
+	errorsConfig          config.ErrorsConfig
+	requestContextFactory *context.RequestContextFactory
+}
+
+// Find Returns the catalog of error codes.
+// @Summary Returns the catalog of error codes.
+// @Description Documents every error code returned by the app. The type of the problem details of an error links to its code here.
+// @Produce json
+// @Success 200 {object} resource.ErrorCodeResourceList
+// @Tags errors
+// @Router /errors [get]
+func (ctrl *ErrorController) Find(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	errorCodeResourceList := &resource.ErrorCodeResourceList{
+		Data: make([]*resource.ErrorCodeResource, 0, len(apperror.ErrorCodes)),
+	}
+
+	for _, errorCode := range apperror.ErrorCodes {
+		errorCodeResourceList.Data = append(errorCodeResourceList.Data, ctrl.toResource(requestContext, errorCode))
+	}
+
+	c.JSON(http.StatusOK, errorCodeResourceList)
+}
+
+// FindOneByCode Returns an error code of the catalog.
+// @Summary Returns an error code of the catalog.
+// @Description Documents an error code returned by the app. It's the type of the problem details of its errors.
+// @Produce json
+// @Param code path string true "Error code"
+// @Success 200 {object} resource.ErrorCodeResource
+// @Failure 404 {object} apperror.HttpError
+// @Tags errors
+// @Router /errors/{code} [get]
+func (ctrl *ErrorController) FindOneByCode(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	errorCode := apperror.GetErrorCode(c.Param("code"))
+
+	if errorCode == nil {
+		c.Error(apperror.NewNotFoundHttpError(requestContext, fmt.Errorf("error code '%s' not found", c.Param("code")), ErrorControllerSourceName, nil))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, ctrl.toResource(requestContext, errorCode))
+}
+
+func (ctrl *ErrorController) toResource(requestContext *context.RequestContext, errorCode *apperror.ErrorCode) *resource.ErrorCodeResource {
+	return &resource.ErrorCodeResource{
+		Code:        errorCode.Code,
+		Type:        ctrl.errorsConfig.TypeBaseUrl + errorCode.Code,
+		Title:       requestContext.Translate(errorCode.Code, errorCode.Message),
+		Status:      errorCode.HttpStatus,
+		Description: errorCode.Description,
+	}
+}
+
+// Static functions
+
+func NewErrorController(errorsConfig config.ErrorsConfig, requestContextFactory *context.RequestContextFactory) *ErrorController {
+	return &ErrorController{
+		errorsConfig:          errorsConfig,
+		requestContextFactory: requestContextFactory,
+	}
+}
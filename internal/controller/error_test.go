@@ -0,0 +1,182 @@
+package controller_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorProblemDetails(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	// Errors keep their format unless the request accepts problem details
+
+	res := &apperror.HttpError{}
+
+	response, err := mockApp.NewGetRequest("/user_type/nonexistent", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.Equal(t, "application/json; charset=utf-8", response.Header().Get("Content-Type"))
+	assert.Equal(t, apperror.ModelNotFoundErrorCode, res.Code)
+
+	problem := &apperror.ProblemDetails{}
+
+	response, err = mockApp.NewGetRequest(
+		"/user_type/nonexistent?offset=1",
+		mock.NewMockAppOptions().
+			WithHeader("Accept", "application/json;q=0.5, application/problem+json").
+			WithHeader(context.RequestIDHeader, "test-request-id").
+			WithExpectedResponse(problem),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.Equal(t, apperror.ProblemContentType, response.Header().Get("Content-Type"))
+	assert.Equal(t, &apperror.ProblemDetails{
+		Type:      "/errors/" + apperror.ModelNotFoundErrorCode,
+		Title:     apperror.ModelNotFoundErrorMessage,
+		Status:    http.StatusNotFound,
+		Instance:  "/user_type/nonexistent?offset=1",
+		Code:      apperror.ModelNotFoundErrorCode,
+		RequestID: "test-request-id",
+	}, problem)
+
+	// Not acceptable problem details are not used
+
+	res = &apperror.HttpError{}
+
+	response, err = mockApp.NewGetRequest(
+		"/user_type/nonexistent",
+		mock.NewMockAppOptions().WithHeader("Accept", "application/problem+json;q=0, application/json").WithExpectedResponse(res),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.Equal(t, apperror.ModelNotFoundErrorCode, res.Code)
+
+	// Validation errors are reported as invalid params, by their JSON path
+
+	problem = &apperror.ProblemDetails{}
+
+	response, err = mockApp.NewPostRequest(
+		"/user",
+		mock.NewMockAppOptions().
+			WithHeader("Accept", apperror.ProblemContentType).
+			WithBody(resource.UserCreateResource{Username: "test-problem-user", UserTypeName: "nonexistent"}).
+			WithExpectedResponse(problem),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, "/errors/"+apperror.ValidationErrorCode, problem.Type)
+	assert.Equal(t, apperror.ValidationErrorMessage, problem.Title)
+	assert.Equal(t, "user_type_name must be an existing user type", problem.Detail)
+	assert.Equal(t, []*apperror.InvalidParam{
+		{Name: "user_type_name", Validator: "user_type", Reason: "user_type_name must be an existing user type"},
+	}, problem.InvalidParams)
+	assert.NotEmpty(t, problem.RequestID)
+
+	// Update resources report the fields read from the path by their uri name
+
+	res = &apperror.HttpError{}
+
+	response, err = mockApp.NewPutRequest(
+		"/user/"+strings.Repeat("a", 51),
+		mock.NewMockAppOptions().WithBody(resource.UserUpdateResource{}).WithExpectedResponse(res),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.True(t, res.HasErrorCountByNameAndType(1, "username", "max"))
+	assert.True(t, res.HasErrorCountByNameAndType(1, "user_type_name", "required"))
+}
+
+func TestErrorProblemDetailsFormatConfig(t *testing.T) {
+	appConfig, err := mock.NewDefaultConfigLoader().
+		WithOverrides("errors.format=problem", "errors.type_base_url=https://api.example.com/errors/").
+		Load()
+
+	assert.Nil(t, err)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	problem := &apperror.ProblemDetails{}
+
+	response, err := mockApp.NewGetRequest(
+		"/user_type/nonexistent",
+		mock.NewMockAppOptions().WithHeader("Accept-Language", "es").WithExpectedResponse(problem),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.Equal(t, apperror.ProblemContentType, response.Header().Get("Content-Type"))
+	assert.Equal(t, "https://api.example.com/errors/"+apperror.ModelNotFoundErrorCode, problem.Type)
+	assert.Equal(t, "No se encontró el elemento referenciado", problem.Title)
+
+	// The catalog links the types of the problems to their documentation
+
+	errorCode := &resource.ErrorCodeResource{}
+
+	response, err = mockApp.NewGetRequest("/errors/"+apperror.ModelNotFoundErrorCode, mock.NewMockAppOptions().WithExpectedResponse(errorCode))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, problem.Type, errorCode.Type)
+	assert.Equal(t, http.StatusNotFound, errorCode.Status)
+	assert.Equal(t, apperror.ModelNotFoundErrorMessage, errorCode.Title)
+}
+
+func TestErrorCatalog(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	res := &resource.ErrorCodeResourceList{}
+
+	response, err := mockApp.NewGetRequest("/errors", mock.NewMockAppOptions().WithHeader("Accept-Language", "es").WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Len(t, res.Data, len(apperror.ErrorCodes))
+
+	// Every error code is documented, with a message for every locale
+
+	for _, errorCode := range res.Data {
+		assert.NotEmpty(t, errorCode.Description, errorCode.Code)
+		assert.Equal(t, "/errors/"+errorCode.Code, errorCode.Type)
+		assert.Equal(t, apperror.Messages["es"][errorCode.Code], errorCode.Title)
+	}
+
+	for locale, messages := range apperror.Messages {
+		for code := range messages {
+			assert.NotNil(t, apperror.GetErrorCode(code), locale+": "+code)
+		}
+	}
+
+	// Unknown codes are not found
+
+	httpError := &apperror.HttpError{}
+
+	response, err = mockApp.NewGetRequest("/errors/999999", mock.NewMockAppOptions().WithExpectedResponse(httpError))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.Equal(t, apperror.ModelNotFoundErrorCode, httpError.Code)
+}
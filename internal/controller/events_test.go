@@ -0,0 +1,162 @@
+package controller_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/comfortablynumb/goginrestapi/internal/repository"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventsArePublishedThroughTheOutbox(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	memorySink := events.NewMemorySink()
+	disabledUserTypes := make([]string, 0)
+
+	componentRegistry.EventDispatcher.AddSink(memorySink)
+	componentRegistry.EventBus.Subscribe(events.UserTypeDisabledEventName, func(envelope *events.Envelope, event events.Event) error {
+		disabledUserTypes = append(disabledUserTypes, event.(*events.UserTypeDisabled).UserType.Name)
+
+		return nil
+	})
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+
+	req := resource.UserTypeUpdateResource{
+		Name:     userTypeReq.Name,
+		Disabled: true,
+	}
+
+	response, err := mockApp.NewPutRequest("/user_type/"+userTypeReq.Name, mock.NewMockAppOptions().WithBody(req))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	// Nothing is delivered until the dispatcher runs
+
+	assert.Equal(t, 0, len(memorySink.GetEnvelopes()))
+
+	delivered, err := componentRegistry.EventDispatcher.DispatchPending()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, delivered)
+	assert.Equal(t, 1, len(memorySink.GetEnvelopesByName(events.UserTypeCreatedEventName)))
+	assert.Equal(t, 1, len(memorySink.GetEnvelopesByName(events.UserTypeUpdatedEventName)))
+	assert.Equal(t, 1, len(memorySink.GetEnvelopesByName(events.UserTypeDisabledEventName)))
+	assert.Equal(t, []string{userTypeReq.Name}, disabledUserTypes)
+
+	event, err := events.Decode(memorySink.GetEnvelopesByName(events.UserTypeUpdatedEventName)[0])
+
+	assert.Nil(t, err)
+	assert.False(t, event.(*events.UserTypeUpdated).Before.Disabled)
+	assert.True(t, event.(*events.UserTypeUpdated).UserType.Disabled)
+
+	// Delivered events are not delivered again
+
+	delivered, err = componentRegistry.EventDispatcher.DispatchPending()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, delivered)
+}
+
+func TestEventsAreRetriedWhenASinkFails(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	CreateUserType(t, mockApp, "test-user-type-1")
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	now := time.Now().UTC()
+	failingSink := &failingSink{failures: 2}
+	memorySink := events.NewMemorySink()
+	dispatcher := events.NewDispatcher(
+		repository.NewOutboxEventRepository(config.AppConfig{}, componentRegistry.Db, componentRegistry.Logger),
+		componentRegistry.RequestContextFactory,
+		componentRegistry.Logger,
+		func() time.Time { return now },
+		events.DispatcherOptions{RetryBaseDelay: time.Minute, MaxAttempts: 3},
+	)
+
+	dispatcher.AddSink(failingSink).AddSink(memorySink)
+
+	// First attempt fails
+
+	delivered, err := dispatcher.DispatchPending()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, delivered)
+
+	// The retry is not due yet
+
+	delivered, err = dispatcher.DispatchPending()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Equal(t, 1, failingSink.calls)
+
+	// Second attempt (1 minute later) fails too, third one (2 minutes after the second one) succeeds
+
+	now = now.Add(time.Minute)
+
+	delivered, err = dispatcher.DispatchPending()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, delivered)
+
+	now = now.Add(time.Minute)
+
+	delivered, err = dispatcher.DispatchPending()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, delivered)
+
+	now = now.Add(time.Minute)
+
+	delivered, err = dispatcher.DispatchPending()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, 3, failingSink.calls)
+
+	// At-least-once: the memory sink received the event on every attempt
+
+	assert.Equal(t, 3, len(memorySink.GetEnvelopes()))
+	assert.Equal(t, memorySink.GetEnvelopes()[0].ID, memorySink.GetEnvelopes()[2].ID)
+}
+
+// Helper types
+
+type failingSink struct {
+	failures int
+	calls    int
+}
+
+func (s *failingSink) GetName() string {
+	return "failing"
+}
+
+func (s *failingSink) Deliver(ctx context.Context, envelope *events.Envelope) error {
+	s.calls++
+
+	if s.calls <= s.failures {
+		return errors.New("sink is down")
+	}
+
+	return nil
+}
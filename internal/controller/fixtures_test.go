@@ -0,0 +1,157 @@
+package controller_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/fixtures"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/comfortablynumb/goginrestapi/internal/module"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixturesAreUpserted(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	loader, err := componentregistry.Get[fixtures.Loader](mockApp.App.GetComponentRegistry(), module.FixturesLoaderComponentName)
+
+	assert.Nil(t, err)
+	fixturesPath := mockApp.App.GetConfig().Fixtures.Path
+
+	result, err := loader.LoadFile(fixturesPath + "/test/users.yaml")
+
+	assert.Nil(t, err)
+	assert.Equal(t, &fixtures.Result{Created: 5}, result)
+
+	users := make([]*resource.UserResource, 0)
+
+	response, err := mockApp.NewGetRequest("/user", mock.NewMockAppOptions().WithExpectedResponse(&users))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Len(t, users, 3)
+	assert.Equal(t, "test-admin-1", users[0].Username)
+	assert.Equal(t, "test-admin", users[0].UserType.Name)
+
+	// Loading the same file again changes nothing
+
+	result, err = loader.LoadFile(fixturesPath + "/test/users.yaml")
+
+	assert.Nil(t, err)
+	assert.Equal(t, &fixtures.Result{Unchanged: 5}, result)
+
+	// JSON files are supported too, and existing entities are updated to match them
+
+	result, err = loader.LoadFile(fixturesPath + "/test/users_disabled.json")
+
+	assert.Nil(t, err)
+	assert.Equal(t, &fixtures.Result{Updated: 1, Unchanged: 2}, result)
+
+	users = make([]*resource.UserResource, 0)
+
+	response, err = mockApp.NewGetRequest("/user?username=test-user-2", mock.NewMockAppOptions().WithExpectedResponse(&users).WithHeader("Cache-Control", "no-cache"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Len(t, users, 1)
+	assert.Equal(t, "test-admin", users[0].UserType.Name)
+	assert.True(t, users[0].Disabled)
+}
+
+func TestFixturesOfAnEnvironment(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	loader, err := componentregistry.Get[fixtures.Loader](mockApp.App.GetComponentRegistry(), module.FixturesLoaderComponentName)
+
+	assert.Nil(t, err)
+	fixturesPath := mockApp.App.GetConfig().Fixtures.Path
+
+	files, err := loader.GetEnvironmentFiles("test")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		fixturesPath + "/test/user_types.yaml",
+		fixturesPath + "/test/users.yaml",
+		fixturesPath + "/test/users_disabled.json",
+	}, files)
+
+	result, err := loader.LoadEnvironment("test")
+
+	assert.Nil(t, err)
+	assert.Equal(t, &fixtures.Result{Created: 8, Updated: 1, Unchanged: 2}, result)
+
+	_, err = loader.GetEnvironmentFiles("../test")
+
+	assert.NotNil(t, err)
+
+	_, err = loader.GetEnvironmentFiles("unknown")
+
+	assert.NotNil(t, err)
+}
+
+func TestFixturesAreLoadedByTheMockApp(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	assert.Nil(t, mockApp.LoadFixtures("user_types.yaml"))
+
+	res := &resource.UserTypeResourceList{}
+
+	response, err := mockApp.NewGetRequest("/user_type?sort_by=id&sort_dir=asc", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Len(t, res.Data, 3)
+	assert.Equal(t, "test-user-type-1", res.Data[0].Name)
+	assert.Equal(t, "test-user-type-3", res.Data[2].Name)
+}
+
+func TestFixturesWithErrorsAreNotLoaded(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	// Validation rules apply, and nothing of the file is loaded if any fixture fails
+
+	err := mockApp.LoadFixtures("invalid/unknown_user_type.yaml")
+
+	assert.NotNil(t, err)
+	assert.IsType(t, &fixtures.Error{}, err)
+	assert.Contains(t, err.Error(), "user 'test-user-2'")
+
+	validationErrors := err.(*fixtures.Error).AppErr.GetErrors()
+
+	assert.Len(t, validationErrors, 1)
+	assert.Equal(t, "user_type_name", validationErrors[0].Field)
+	assert.Equal(t, "user_type", validationErrors[0].Validator)
+
+	res := &resource.UserTypeResourceList{}
+
+	response, err := mockApp.NewGetRequest("/user_type", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Len(t, res.Data, 0)
+
+	// Unknown fields are rejected
+
+	err = mockApp.LoadFixtures("invalid/unknown_field.yaml")
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "usertype")
+}
@@ -0,0 +1,212 @@
+package controller_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/comfortablynumb/goginrestapi/pkg/framework"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// pluginConfig Config section of pluginModule.
+type pluginConfig struct {
+	Message string `yaml:"message" default:"pong" validate:"required"`
+}
+
+// pluginModule Module written as a downstream project would, with the public API only.
+type pluginModule struct {
+	framework.BaseModule
+	name         string
+	dependencies []string
+	config       pluginConfig
+	lifecycle    *[]string
+}
+
+func (m *pluginModule) GetName() string {
+	return m.name
+}
+
+func (m *pluginModule) GetDependencies() []string {
+	return m.dependencies
+}
+
+func (m *pluginModule) GetConfig() interface{} {
+	return &m.config
+}
+
+func (m *pluginModule) SetUpComponents(
+	appConfig framework.AppConfig,
+	errorHandler *framework.ErrorHandler,
+	componentRegistry *framework.ComponentRegistry,
+) error {
+	greeting, err := framework.Get[string](componentRegistry, "Greeting")
+
+	if err != nil {
+		return err
+	}
+
+	componentRegistry.Set(m.name+"Message", greeting+" "+m.config.Message)
+
+	return nil
+}
+
+func (m *pluginModule) SetUpRouter(
+	errorHandler *framework.ErrorHandler,
+	componentRegistry *framework.ComponentRegistry,
+	router *gin.Engine,
+) error {
+	message, err := framework.Get[string](componentRegistry, m.name+"Message")
+
+	if err != nil {
+		return err
+	}
+
+	router.GET("/"+m.name, func(c *gin.Context) {
+		c.String(http.StatusOK, message)
+	})
+
+	return nil
+}
+
+func (m *pluginModule) OnStart(ctx context.Context, componentRegistry *framework.ComponentRegistry) error {
+	*m.lifecycle = append(*m.lifecycle, "start:"+m.name)
+
+	return nil
+}
+
+func (m *pluginModule) OnStop(ctx context.Context, componentRegistry *framework.ComponentRegistry) error {
+	*m.lifecycle = append(*m.lifecycle, "stop:"+m.name)
+
+	return nil
+}
+
+func TestFrameworkPlugins(t *testing.T) {
+	lifecycle := make([]string, 0)
+	loggerHookCalls := 0
+	options := []framework.Option{
+		framework.WithModules(
+			&pluginModule{name: "ping", dependencies: []string{"pong"}, lifecycle: &lifecycle},
+			&pluginModule{name: "pong", lifecycle: &lifecycle},
+		),
+		framework.WithComponentRegistryHook(framework.ComponentRegistryHookFunc(
+			func(componentRegistry *framework.ComponentRegistry) *framework.ComponentRegistry {
+				return componentRegistry.Set("Greeting", "hello")
+			},
+		)),
+		framework.WithRouterHook(framework.RouterHookFunc(func(router *gin.Engine) *gin.Engine {
+			router.Use(func(c *gin.Context) {
+				c.Header("X-Plugin", "true")
+			})
+
+			return router
+		})),
+		framework.WithValidatorHook(framework.ValidatorHookFunc(func(v *validator.Validate) *validator.Validate {
+			assert.Nil(t, v.RegisterValidation("even", func(fl validator.FieldLevel) bool {
+				return fl.Field().Int()%2 == 0
+			}))
+
+			return v
+		})),
+		framework.WithLoggerHook(framework.LoggerHookFunc(func(logger *zerolog.Logger) *zerolog.Logger {
+			loggerHookCalls++
+
+			return logger
+		})),
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	assert.Nil(t, err)
+
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	assert.Nil(t, listener.Close())
+
+	appConfig, err := mock.NewDefaultConfigLoader().
+		WithOverrides("ping.message=world", "server.host=127.0.0.1", fmt.Sprintf("server.port=%d", port)).
+		Load()
+
+	assert.Nil(t, err)
+
+	mockApp := mock.NewMockApp(appConfig, options...)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	// Modules get their config sections, and the components set by the hooks
+
+	response, err := mockApp.NewGetRequest("/ping", nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "hello world", response.Body.String())
+	assert.Equal(t, "true", response.Header().Get("X-Plugin"))
+
+	response, err = mockApp.NewGetRequest("/pong", nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "hello pong", response.Body.String())
+
+	// Hooks customize the components of the app
+
+	assert.NotNil(t, mockApp.App.GetComponentRegistry().Validator.Var(3, "even"))
+	assert.Nil(t, mockApp.App.GetComponentRegistry().Validator.Var(4, "even"))
+	assert.Equal(t, 1, loggerHookCalls)
+
+	// Modules are started after their dependencies, and stopped before them
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- mockApp.App.Run(ctx)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.Nil(t, err)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "The app did not stop after the context was cancelled.")
+	}
+
+	assert.Equal(t, []string{"start:pong", "start:ping", "stop:ping", "stop:pong"}, lifecycle)
+
+	// Binaries built with the CLI get the modules too
+
+	out := &bytes.Buffer{}
+
+	assert.Nil(t, framework.NewCLI(mock.NewDefaultConfigLoader, out, options...).Run([]string{"routes"}))
+	assert.Contains(t, out.String(), "/ping")
+	assert.Contains(t, out.String(), "/pong")
+
+	// Modules fail to start if their config is invalid
+
+	appConfig, err = mock.NewDefaultConfigLoader().WithOverrides("ping.message=").Load()
+
+	assert.Nil(t, err)
+
+	application := framework.NewApp(appConfig, options...)
+	err = application.SetUp()
+
+	assert.NotNil(t, err)
+
+	if err != nil {
+		assert.Contains(t, err.Error(), "ping.message")
+	}
+
+	assert.Nil(t, application.Close())
+}
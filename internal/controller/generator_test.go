@@ -0,0 +1,247 @@
+package controller_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/comfortablynumb/goginrestapi/internal/cli"
+	"github.com/comfortablynumb/goginrestapi/internal/generator"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testEntityDefinition = `
+name: product
+key: sku
+fields:
+  - name: sku
+    type: string
+    validate: min=1,max=50
+  - name: title
+    type: string
+    validate: required,max=100
+    filter: true
+  - name: price
+    type: float64
+    example: "9.99"
+  - name: user_type
+    type: relation
+    relation:
+      entity: user_type
+unique_keys:
+  - [title, user_type]
+`
+)
+
+func TestGeneratorDefinitionValidation(t *testing.T) {
+	for definition, expectedError := range map[string]string{
+		"name: Product\nkey: sku\nfields: [{name: sku, type: string}]":                                           "name 'Product' must be snake case",
+		"name: product\nkey: sku\nfields: []":                                                                    "the entity must have at least one field",
+		"name: product\nkey: sku\nfields: [{name: sku, type: uuid}]":                                             "field 'sku' has type 'uuid'",
+		"name: product\nkey: sku\nfields: [{name: sku, type: string}, {name: id, type: int}]":                    "field 'id' is reserved",
+		"name: product\nkey: sku\nfields: [{name: sku, type: string}, {name: err, type: int}]":                   "field 'err' is reserved",
+		"name: product\nkey: price\nfields: [{name: price, type: float64}]":                                      "key 'price' must be a string",
+		"name: product\nkey: code\nfields: [{name: sku, type: string}]":                                          "key 'code' is not one of the fields",
+		"name: product\nkey: sku\nfields: [{name: sku, type: string}, {name: owner, type: relation}]":            "field 'owner' is a relation",
+		"name: product\nkey: sku\nfields: [{name: sku, type: string}]\nunique_keys: [[sku, stock]]":              "unique key (sku, stock) has field 'stock', which is not defined",
+		"name: product\nkey: sku\nfields: [{name: sku, type: string}, {name: a, type: int}]\nunique_keys: [[a]]": "unique key (a) must have at least one string field",
+		"name: product\nkey: sku\nfields: [{name: sku, type: string}]\nunknown: true":                            "could NOT parse entity definition",
+	} {
+		path := writeTempFile(t, "definition.yaml", definition)
+
+		_, err := generator.LoadDefinition(path)
+
+		assert.NotNil(t, err, definition)
+
+		if err != nil {
+			assert.Contains(t, err.Error(), expectedError, definition)
+		}
+	}
+}
+
+func TestGeneratorGenerate(t *testing.T) {
+	dir := createTestProject(t)
+	migrationsDir := filepath.Join(dir, "database", "migrations")
+	definition, err := generator.LoadDefinition(writeTempFile(t, "product.yaml", testEntityDefinition))
+
+	assert.Nil(t, err)
+
+	// Every layer is generated, importing the packages of the project
+
+	paths, err := generator.NewGenerator(dir, migrationsDir, false).Generate(definition)
+
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		[]string{
+			filepath.Join(dir, "internal/model/product.go"),
+			filepath.Join(dir, "internal/resource/product.go"),
+			filepath.Join(dir, "internal/repository/utils/product.go"),
+			filepath.Join(dir, "internal/repository/product.go"),
+			filepath.Join(dir, "internal/events/product.go"),
+			filepath.Join(dir, "internal/service/product.go"),
+			filepath.Join(dir, "internal/controller/product.go"),
+			filepath.Join(dir, "internal/module/product.go"),
+			filepath.Join(dir, "internal/controller/product_test.go"),
+			filepath.Join(migrationsDir, "2_create_products.up.sql"),
+			filepath.Join(migrationsDir, "2_create_products.down.sql"),
+		},
+		paths,
+	)
+
+	assert.Contains(t, readFile(t, paths[3]), `"example.com/shop/internal/model"`)
+	assert.Contains(t, readFile(t, paths[3]), `sb.JoinWithOption(sqlbuilder.LeftJoin, sb.As("user_types", "r1"), "r1.id = e.user_type_id AND r1.tenant_id = e.tenant_id")`)
+	assert.Contains(t, readFile(t, paths[3]), `Where(sb.Equal("e.tenant_id", ctx.GetTenantID()))`)
+	assert.Contains(t, readFile(t, paths[3]), `return execScoped(ctx, r.db, ProductRepositorySourceName, query, bindings...)`)
+	assert.Regexp(t, `UserTypeName\s+string\s+`+"`"+`json:"user_type_name" validate:"required,user_type" example:"test-user-type"`, readFile(t, paths[1]))
+	assert.Regexp(t, `Sku\s+string\s+`+"`"+`json:"sku" binding:"required" validate:"required,min=1,max=50" example:"test-product"`, readFile(t, paths[1]))
+	assert.Contains(t, readFile(t, paths[6]), "// @Router /product/{sku} [put]")
+	assert.Contains(t, readFile(t, paths[7]), "return []string{AuditModuleName, IdempotencyModuleName, UserTypeModuleName}")
+	assert.Contains(t, readFile(t, paths[9]), "sku VARCHAR(50) NOT NULL,")
+	assert.Contains(t, readFile(t, paths[9]), "tenant_id INTEGER NOT NULL,")
+	assert.Contains(t, readFile(t, paths[9]), "CREATE UNIQUE INDEX products_tenant_id_sku_idx ON products (tenant_id, sku);")
+	assert.Contains(t, readFile(t, paths[9]), "CREATE UNIQUE INDEX products_tenant_id_title_user_type_id_idx ON products (tenant_id, title, user_type_id);")
+	assert.Equal(t, "DROP TABLE products;\n", readFile(t, paths[10]))
+
+	// Existing files are only overwritten with force, and migrations keep their version
+
+	_, err = generator.NewGenerator(dir, migrationsDir, false).Generate(definition)
+
+	assert.NotNil(t, err)
+
+	if err != nil {
+		assert.Contains(t, err.Error(), "already exists")
+	}
+
+	forcedPaths, err := generator.NewGenerator(dir, migrationsDir, true).Generate(definition)
+
+	assert.Nil(t, err)
+	assert.Equal(t, paths, forcedPaths)
+
+	// The CLI resolves the migrations path from the config
+
+	dir = createTestProject(t)
+	out := &bytes.Buffer{}
+
+	err = cli.NewCLI(mock.NewDefaultConfigLoader, out).Run([]string{
+		"-set", "db.migrations_path=file://database/migrations",
+		"generate", "-dir", dir, writeTempFile(t, "product.yaml", testEntityDefinition),
+	})
+
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), "Created "+filepath.Join(dir, "database/migrations/2_create_products.up.sql"))
+	assert.Contains(t, out.String(), "Next steps:")
+}
+
+func TestGeneratorGeneratedCodeBuilds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a copy of the whole project")
+	}
+
+	dir := copyProject(t)
+	definition, err := generator.LoadDefinition(writeTempFile(t, "product.yaml", testEntityDefinition))
+
+	assert.Nil(t, err)
+
+	_, err = generator.NewGenerator(dir, filepath.Join(dir, "database", "migrations"), false).Generate(definition)
+
+	assert.Nil(t, err)
+
+	// The module is registered as the "Next steps" of the CLI explain, so the app must build and its tests pass
+
+	appPath := filepath.Join(dir, "internal", "app", "app.go")
+	streamModule := "moduleManager.AddModule(&module.StreamModule{})\n"
+	appSource := readFile(t, appPath)
+
+	assert.Contains(t, appSource, streamModule)
+	assert.Nil(t, ioutil.WriteFile(
+		appPath,
+		[]byte(strings.Replace(appSource, streamModule, streamModule+"\tmoduleManager.AddModule(&module.ProductModule{})\n", 1)),
+		os.FileMode(0644),
+	))
+
+	for _, args := range [][]string{
+		{"build", "./..."},
+		{"vet", "./..."},
+		{"test", "./internal/controller", "-run", "^TestProduct"},
+	} {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = dir
+
+		output, err := cmd.CombinedOutput()
+
+		assert.Nil(t, err, "go %s:\n%s", strings.Join(args, " "), output)
+	}
+}
+
+// createTestProject Creates a project with a go.mod file and a migration, for the generator to write into.
+func createTestProject(t *testing.T) string {
+	dir := t.TempDir()
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "database", "migrations"), os.FileMode(0755)))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/shop\n\ngo 1.18\n"), os.FileMode(0644)))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "database", "migrations", "1_init.up.sql"), []byte{}, os.FileMode(0644)))
+
+	return dir
+}
+
+// copyProject Copies the sources of this project, so code can be generated into them and built.
+func copyProject(t *testing.T) string {
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+
+	assert.Nil(t, err)
+
+	dir := t.TempDir()
+
+	for _, name := range []string{"go.mod", "go.sum", "main.go", "database", "docs", "internal", "pkg"} {
+		err := filepath.Walk(filepath.Join(root, name), func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			relPath, err := filepath.Rel(root, path)
+
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() {
+				return os.MkdirAll(filepath.Join(dir, relPath), os.FileMode(0755))
+			}
+
+			content, err := ioutil.ReadFile(path)
+
+			if err != nil {
+				return err
+			}
+
+			return ioutil.WriteFile(filepath.Join(dir, relPath), content, info.Mode())
+		})
+
+		assert.Nil(t, err)
+	}
+
+	return dir
+}
+
+func writeTempFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), os.FileMode(0644)))
+
+	return path
+}
+
+func readFile(t *testing.T, path string) string {
+	content, err := ioutil.ReadFile(path)
+
+	assert.Nil(t, err)
+
+	return string(content)
+}
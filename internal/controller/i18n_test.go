@@ -0,0 +1,198 @@
+package controller_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/comfortablynumb/goginrestapi/internal/app"
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/i18n"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestI18nAcceptLanguageNegotiation(t *testing.T) {
+	assert.Equal(t, []string{"es", "en-us", "fr"}, i18n.ParseAcceptLanguage("fr;q=0.5, EN-US;q=0.8, es, de;q=0"))
+	assert.Equal(t, []string{"es", "en"}, i18n.ParseAcceptLanguage("es;q=0.9,en;q=0.9,fr;q=invalid"))
+	assert.Equal(t, []string{}, i18n.ParseAcceptLanguage(""))
+
+	translator, err := i18n.NewTranslator(i18n.EnglishLocale)
+
+	assert.Nil(t, err)
+
+	for acceptLanguage, expectedLocale := range map[string]string{
+		"":                          i18n.EnglishLocale,
+		"fr":                        i18n.EnglishLocale,
+		"es":                        i18n.SpanishLocale,
+		"es-AR":                     i18n.SpanishLocale,
+		"fr;q=0.9, es;q=0.8":        i18n.SpanishLocale,
+		"es;q=0.5, en;q=0.8":        i18n.EnglishLocale,
+		"en;q=0, es":                i18n.SpanishLocale,
+		"*, es;q=0.5":               i18n.EnglishLocale,
+		"de-DE, es-MX;q=0.4, fr-FR": i18n.SpanishLocale,
+	} {
+		assert.Equal(t, expectedLocale, translator.GetTranslator(acceptLanguage).Locale(), acceptLanguage)
+	}
+}
+
+func TestI18nValidationMessages(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	// Binding errors, with the messages of the error codes
+
+	res := &apperror.HttpError{}
+
+	response, err := mockApp.NewPostRequest("/user_type", mock.NewMockAppOptions().WithBody(resource.UserTypeCreateResource{}).WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, apperror.BindingErrorMessage, res.Message)
+	assert.Equal(t, "name is a required field", res.GetErrors()[0].Message)
+
+	res = &apperror.HttpError{}
+
+	response, err = mockApp.NewPostRequest(
+		"/user_type",
+		mock.NewMockAppOptions().
+			WithHeader("Accept-Language", "fr;q=0.9, es;q=0.8").
+			WithBody(resource.UserTypeCreateResource{}).
+			WithExpectedResponse(res),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, "Error al leer la petición", res.Message)
+	assert.Equal(t, "name es un campo obligatorio", res.GetErrors()[0].Message)
+
+	// Custom validation tags
+
+	req := resource.UserTypeCreateResource{Name: "test-i18n-user-type"}
+
+	response, err = mockApp.NewPostRequest("/user_type", mock.NewMockAppOptions().WithBody(req))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	for acceptLanguage, expectedMessage := range map[string]string{
+		"en": "name is already in use",
+		"es": "name ya está en uso",
+	} {
+		res = &apperror.HttpError{}
+
+		response, err = mockApp.NewPostRequest(
+			"/user_type",
+			mock.NewMockAppOptions().WithHeader("Accept-Language", acceptLanguage).WithBody(req).WithExpectedResponse(res),
+		)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.True(t, res.HasErrorCountByNameAndType(1, "name", "unique"))
+		assert.Equal(t, expectedMessage, res.GetErrors()[0].Message)
+	}
+
+	res = &apperror.HttpError{}
+
+	response, err = mockApp.NewPostRequest(
+		"/user",
+		mock.NewMockAppOptions().
+			WithHeader("Accept-Language", "es-ES").
+			WithBody(resource.UserCreateResource{Username: strings.Repeat("a", 51), UserTypeName: "nonexistent"}).
+			WithExpectedResponse(res),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, "Error de validación de la petición", res.Message)
+
+	messages := make(map[string]string)
+
+	for _, validationError := range res.GetErrors() {
+		messages[validationError.Validator] = validationError.Message
+	}
+
+	assert.Equal(t, map[string]string{
+		"max":       "username debe tener como máximo 50 caracteres",
+		"user_type": "user_type_name debe ser un tipo de usuario existente",
+	}, messages)
+}
+
+func TestI18nErrorMessages(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	for acceptLanguage, expectedMessage := range map[string]string{
+		"":               apperror.ModelNotFoundErrorMessage,
+		"es-AR, en;q=.5": "No se encontró el elemento referenciado",
+	} {
+		res := &apperror.HttpError{}
+
+		response, err := mockApp.NewGetRequest(
+			"/user_type/nonexistent",
+			mock.NewMockAppOptions().WithHeader("Accept-Language", acceptLanguage).WithExpectedResponse(res),
+		)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+		assert.Equal(t, apperror.ModelNotFoundErrorCode, res.Code)
+		assert.Equal(t, expectedMessage, res.Message)
+	}
+
+	// Every error code has a message for every locale
+
+	for _, locale := range []string{i18n.EnglishLocale, i18n.SpanishLocale} {
+		assert.Len(t, apperror.Messages[locale], len(apperror.Messages[i18n.EnglishLocale]), locale)
+	}
+}
+
+func TestI18nDefaultLocale(t *testing.T) {
+	appConfig, err := mock.NewDefaultConfigLoader().WithOverrides("default_locale=es").Load()
+
+	assert.Nil(t, err)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	for acceptLanguage, expectedMessage := range map[string]string{
+		"":      "No se encontró el elemento referenciado",
+		"fr-FR": "No se encontró el elemento referenciado",
+		"*":     "No se encontró el elemento referenciado",
+		"en-GB": apperror.ModelNotFoundErrorMessage,
+	} {
+		res := &apperror.HttpError{}
+
+		response, err := mockApp.NewGetRequest(
+			"/user_type/nonexistent",
+			mock.NewMockAppOptions().WithHeader("Accept-Language", acceptLanguage).WithExpectedResponse(res),
+		)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+		assert.Equal(t, expectedMessage, res.Message, acceptLanguage)
+	}
+
+	// Unsupported default locales are rejected
+
+	appConfig, err = mock.NewDefaultConfigLoader().WithOverrides("default_locale=fr").Load()
+
+	assert.Nil(t, err)
+
+	err = app.NewApp(appConfig).SetUp()
+
+	assert.NotNil(t, err)
+
+	if err != nil {
+		assert.Contains(t, err.Error(), "default locale 'fr' is not supported. Supported locales: en, es")
+	}
+}
@@ -0,0 +1,426 @@
+package controller_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/app"
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/hooks"
+	"github.com/comfortablynumb/goginrestapi/internal/middleware"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/module"
+	"github.com/comfortablynumb/goginrestapi/internal/repository"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyReplaysTheFirstResponse(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	req := resource.UserTypeCreateResource{Name: "test-user-type"}
+
+	first, err := mockApp.NewPostRequest("/user_type", mock.NewMockAppOptions().WithBody(req).WithHeader(middleware.IdempotencyKeyHeader, "key-1"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(middleware.IdempotentReplayedHeader))
+
+	second, err := mockApp.NewPostRequest("/user_type", mock.NewMockAppOptions().WithBody(req).WithHeader(middleware.IdempotencyKeyHeader, "key-1"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, middleware.IdempotentReplayedHeaderValue, second.Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Equal(t, first.Header().Get("Content-Type"), second.Header().Get("Content-Type"))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+
+	// The user type was created only once
+
+	res := &resource.UserTypeResourceList{}
+
+	response, err := mockApp.NewGetRequest("/user_type", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Len(t, res.Data, 1)
+}
+
+func TestIdempotencyKeyReusedWithADifferentRequest(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	req := resource.UserTypeCreateResource{Name: "test-user-type"}
+
+	response, err := mockApp.NewPostRequest("/user_type", mock.NewMockAppOptions().WithBody(req).WithHeader(middleware.IdempotencyKeyHeader, "key-1"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	res := &apperror.HttpError{}
+	req = resource.UserTypeCreateResource{Name: "test-user-type-2"}
+
+	response, err = mockApp.NewPostRequest("/user_type", mock.NewMockAppOptions().WithBody(req).WithHeader(middleware.IdempotencyKeyHeader, "key-1").WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	assert.Equal(t, apperror.IdempotencyKeyMismatchErrorCode, res.Code)
+}
+
+func TestIdempotencyKeysAreScopedByRouteAndCaller(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	CreateUserType(t, mockApp, "test-user-type")
+
+	req := resource.UserTypeCreateResource{Name: "test-user-type-2"}
+
+	response, err := mockApp.NewPostRequest("/user_type", mock.NewMockAppOptions().WithBody(req).WithHeader(middleware.IdempotencyKeyHeader, "key-1").WithHeader("X-Actor", "admin"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	// Same key, different caller
+
+	req = resource.UserTypeCreateResource{Name: "test-user-type-3"}
+
+	response, err = mockApp.NewPostRequest("/user_type", mock.NewMockAppOptions().WithBody(req).WithHeader(middleware.IdempotencyKeyHeader, "key-1").WithHeader("X-Actor", "other-admin"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.Empty(t, response.Header().Get(middleware.IdempotentReplayedHeader))
+
+	// Same key and caller, different route
+
+	userReq := resource.UserCreateResource{Username: "test-user", UserTypeName: "test-user-type"}
+
+	response, err = mockApp.NewPostRequest("/user", mock.NewMockAppOptions().WithBody(userReq).WithHeader(middleware.IdempotencyKeyHeader, "key-1").WithHeader("X-Actor", "admin"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.Empty(t, response.Header().Get(middleware.IdempotentReplayedHeader))
+}
+
+func TestIdempotencyRequestsInProgressAreDetected(t *testing.T) {
+	appConfig := mock.NewDefaultConfig()
+	appConfig.Set("idempotency.lock_timeout", 100*time.Millisecond)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	body := `{"name":"test-user-type"}`
+	hash := sha256.Sum256([]byte(body))
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	idempotencyService, err := componentregistry.Get[service.IdempotencyService](componentRegistry, module.IdempotencyServiceComponentName)
+
+	assert.Nil(t, err)
+
+	_, appErr := idempotencyService.Begin(
+		componentRegistry.RequestContextFactory.NewBackgroundRequestContext(),
+		"key-1",
+		"POST /user_type",
+		"default/anonymous",
+		hex.EncodeToString(hash[:]),
+	)
+
+	assert.Nil(t, appErr)
+
+	res := &apperror.HttpError{}
+
+	response, err := mockApp.NewPostRequest("/user_type", mock.NewMockAppOptions().WithBody(body).WithHeader(middleware.IdempotencyKeyHeader, "key-1").WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusConflict, response.Code)
+	assert.Equal(t, apperror.IdempotencyKeyInProgressErrorCode, res.Code)
+
+	// Requests which did not finish within the lock timeout are taken over
+
+	time.Sleep(150 * time.Millisecond)
+
+	response, err = mockApp.NewPostRequest("/user_type", mock.NewMockAppOptions().WithBody(body).WithHeader(middleware.IdempotencyKeyHeader, "key-1"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+}
+
+func TestIdempotencyFailedRequestsAreNotStored(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	req := resource.UserTypeCreateResource{Name: "test-user-type"}
+	userReq := resource.UserCreateResource{Username: "test-user", UserTypeName: "test-user-type"}
+
+	// The user type does not exist yet
+
+	response, err := mockApp.NewPostRequest("/user", mock.NewMockAppOptions().WithBody(userReq).WithHeader(middleware.IdempotencyKeyHeader, "key-1"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	response, err = mockApp.NewPostRequest("/user_type", mock.NewMockAppOptions().WithBody(req))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	// The retry is executed again
+
+	response, err = mockApp.NewPostRequest("/user", mock.NewMockAppOptions().WithBody(userReq).WithHeader(middleware.IdempotencyKeyHeader, "key-1"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.Empty(t, response.Header().Get(middleware.IdempotentReplayedHeader))
+}
+
+func TestIdempotencyExpiredKeysAreDeleted(t *testing.T) {
+	appConfig := mock.NewDefaultConfig()
+	appConfig.Set("idempotency.key_ttl", 50*time.Millisecond)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	req := resource.UserTypeCreateResource{Name: "test-user-type"}
+
+	response, err := mockApp.NewPostRequest("/user_type", mock.NewMockAppOptions().WithBody(req).WithHeader(middleware.IdempotencyKeyHeader, "key-1"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	time.Sleep(100 * time.Millisecond)
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	idempotencyService, err := componentregistry.Get[service.IdempotencyService](componentRegistry, module.IdempotencyServiceComponentName)
+
+	assert.Nil(t, err)
+
+	deleted, appErr := idempotencyService.DeleteExpired(componentRegistry.RequestContextFactory.NewBackgroundRequestContext())
+
+	assert.Nil(t, appErr)
+	assert.Equal(t, int64(1), deleted)
+
+	// The response is not replayed anymore, so the request is executed again
+
+	response, err = mockApp.NewPostRequest("/user_type", mock.NewMockAppOptions().WithBody(req).WithHeader(middleware.IdempotencyKeyHeader, "key-1"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Empty(t, response.Header().Get(middleware.IdempotentReplayedHeader))
+}
+
+func TestIdempotencyKeysAreTakenOverOnce(t *testing.T) {
+	appConfig := mock.NewDefaultConfig()
+	appConfig.Set("idempotency.lock_timeout", 50*time.Millisecond)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	requestContext := componentRegistry.RequestContextFactory.NewBackgroundRequestContext()
+	idempotencyService, err := componentregistry.Get[service.IdempotencyService](componentRegistry, module.IdempotencyServiceComponentName)
+
+	assert.Nil(t, err)
+
+	idempotencyKeyRepository, err := componentregistry.Get[repository.IdempotencyKeyRepository](componentRegistry, module.IdempotencyKeyRepositoryComponentName)
+
+	assert.Nil(t, err)
+
+	_, appErr := idempotencyService.Begin(requestContext, "key-1", "POST /user_type", "default/anonymous", "hash")
+
+	assert.Nil(t, appErr)
+
+	// Two requests read the key once its lock timed out, but only the first one takes it over
+
+	time.Sleep(100 * time.Millisecond)
+
+	first, appErr := idempotencyKeyRepository.FindOne(requestContext, "key-1", "POST /user_type", "default/anonymous")
+
+	assert.Nil(t, appErr)
+
+	second, appErr := idempotencyKeyRepository.FindOne(requestContext, "key-1", "POST /user_type", "default/anonymous")
+
+	assert.Nil(t, appErr)
+
+	now := time.Now().UTC()
+
+	takenOver, appErr := idempotencyKeyRepository.TakeOver(requestContext, first, now, now.Add(time.Hour))
+
+	assert.Nil(t, appErr)
+	assert.True(t, takenOver)
+
+	takenOver, appErr = idempotencyKeyRepository.TakeOver(requestContext, second, now.Add(time.Second), now.Add(time.Hour))
+
+	assert.Nil(t, appErr)
+	assert.False(t, takenOver)
+
+	// Completed keys can't be taken over either
+
+	assert.Nil(t, idempotencyService.Complete(requestContext, first, http.StatusCreated, "application/json", []byte("{}")))
+
+	takenOver, appErr = idempotencyKeyRepository.TakeOver(requestContext, first, now.Add(time.Second), now.Add(time.Hour))
+
+	assert.Nil(t, appErr)
+	assert.False(t, takenOver)
+}
+
+func TestIdempotencyKeysTakenOverAreNotCompletedNorReleasedByLateRequests(t *testing.T) {
+	appConfig := mock.NewDefaultConfig()
+	appConfig.Set("idempotency.lock_timeout", 50*time.Millisecond)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	requestContext := componentRegistry.RequestContextFactory.NewBackgroundRequestContext()
+	idempotencyService, err := componentregistry.Get[service.IdempotencyService](componentRegistry, module.IdempotencyServiceComponentName)
+
+	assert.Nil(t, err)
+
+	idempotencyKeyRepository, err := componentregistry.Get[repository.IdempotencyKeyRepository](componentRegistry, module.IdempotencyKeyRepositoryComponentName)
+
+	assert.Nil(t, err)
+
+	late, appErr := idempotencyService.Begin(requestContext, "key-1", "POST /user_type", "default/anonymous", "hash")
+
+	assert.Nil(t, appErr)
+
+	// Its lock times out, so a retry takes the key over
+
+	time.Sleep(100 * time.Millisecond)
+
+	owner, appErr := idempotencyService.Begin(requestContext, "key-1", "POST /user_type", "default/anonymous", "hash")
+
+	assert.Nil(t, appErr)
+	assert.NotNil(t, owner)
+
+	// The late request finishes afterwards, either successfully or not, but the key is left untouched
+
+	failed := *late
+
+	assert.Nil(t, idempotencyService.Complete(requestContext, late, http.StatusCreated, "application/json", []byte(`{"late":true}`)))
+	assert.Nil(t, idempotencyService.Release(requestContext, &failed))
+
+	stored, appErr := idempotencyKeyRepository.FindOne(requestContext, "key-1", "POST /user_type", "default/anonymous")
+
+	assert.Nil(t, appErr)
+	assert.NotNil(t, stored)
+	assert.Equal(t, model.IdempotencyKeyStatusInProgress, stored.Status)
+	assert.True(t, owner.CreatedAt.Equal(stored.CreatedAt))
+	assert.Empty(t, stored.ResponseBody)
+
+	// Its new owner can still complete it
+
+	assert.Nil(t, idempotencyService.Complete(requestContext, owner, http.StatusCreated, "application/json", []byte(`{"owner":true}`)))
+
+	stored, appErr = idempotencyKeyRepository.FindOne(requestContext, "key-1", "POST /user_type", "default/anonymous")
+
+	assert.Nil(t, appErr)
+	assert.Equal(t, model.IdempotencyKeyStatusCompleted, stored.Status)
+	assert.Equal(t, []byte(`{"owner":true}`), stored.ResponseBody)
+}
+
+func TestIdempotencyKeysOfPanickedRequestsAreReleased(t *testing.T) {
+	var componentRegistry *componentregistry.ComponentRegistry
+
+	panics := true
+	mockApp := mock.NewMockApp(
+		mock.NewDefaultConfig(),
+		app.WithComponentRegistryHook(hooks.ComponentRegistryHookFunc(
+			func(registry *componentregistry.ComponentRegistry) *componentregistry.ComponentRegistry {
+				componentRegistry = registry
+
+				return registry
+			},
+		)),
+		app.WithRouterHook(hooks.RouterHookFunc(func(router *gin.Engine) *gin.Engine {
+			idempotency, err := componentregistry.Get[gin.HandlerFunc](componentRegistry, module.IdempotencyMiddlewareComponentName)
+
+			assert.Nil(t, err)
+
+			router.POST("/panic", idempotency, func(c *gin.Context) {
+				if panics {
+					panic("unexpected state")
+				}
+
+				c.String(http.StatusCreated, "created")
+			})
+
+			return router
+		})),
+	)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	response, err := mockApp.NewPostRequest("/panic", mock.NewMockAppOptions().WithBody("{}").WithHeader(middleware.IdempotencyKeyHeader, "key-1"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+
+	// The retry is executed right away, instead of waiting for the lock timeout
+
+	panics = false
+
+	response, err = mockApp.NewPostRequest("/panic", mock.NewMockAppOptions().WithBody("{}").WithHeader(middleware.IdempotencyKeyHeader, "key-1"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.Empty(t, response.Header().Get(middleware.IdempotentReplayedHeader))
+}
+
+func TestIdempotencyBodiesAreLimited(t *testing.T) {
+	appConfig := mock.NewDefaultConfig()
+	appConfig.Set("idempotency.max_body_bytes", 16)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	req := resource.UserTypeCreateResource{Name: "test-user-type-with-a-long-name"}
+	res := &apperror.HttpError{}
+
+	response, err := mockApp.NewPostRequest("/user_type", mock.NewMockAppOptions().WithBody(req).WithHeader(middleware.IdempotencyKeyHeader, "key-1").WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, apperror.BindingErrorCode, res.Code)
+
+	// Requests without a key are not limited by it
+
+	response, err = mockApp.NewPostRequest("/user_type", mock.NewMockAppOptions().WithBody(req))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+}
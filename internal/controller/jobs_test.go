@@ -0,0 +1,539 @@
+package controller_test
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/app"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/jobs"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/repository"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobSchedules(t *testing.T) {
+	after := time.Date(2026, 1, 1, 10, 7, 30, 0, time.UTC) // Thursday
+
+	for spec, expected := range map[string]time.Time{
+		"@every 90s":      after.Add(90 * time.Second),
+		"* * * * *":       time.Date(2026, 1, 1, 10, 8, 0, 0, time.UTC),
+		"*/15 * * * *":    time.Date(2026, 1, 1, 10, 15, 0, 0, time.UTC),
+		"0 9-17/4 * * *":  time.Date(2026, 1, 1, 13, 0, 0, 0, time.UTC),
+		"30 2 * * 1-5":    time.Date(2026, 1, 2, 2, 30, 0, 0, time.UTC),
+		"0 0 * * 7":       time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":      time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+		"0 0 15 * 1":      time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+		"5,10 10 1,2 1 *": time.Date(2026, 1, 1, 10, 10, 0, 0, time.UTC),
+		"@hourly":         time.Date(2026, 1, 1, 11, 0, 0, 0, time.UTC),
+		"@daily":          time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		"@weekly":         time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC),
+		"@monthly":        time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		"@yearly":         time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		schedule, err := jobs.ParseSchedule(spec)
+
+		if assert.Nil(t, err, spec) {
+			assert.Equal(t, expected, schedule.Next(after), spec)
+		}
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "@every", "@every -1m", "@sometimes"} {
+		_, err := jobs.ParseSchedule(spec)
+
+		assert.NotNil(t, err, spec)
+	}
+
+	// Impossible dates never come
+
+	schedule, err := jobs.ParseSchedule("0 0 31 2 *")
+
+	assert.Nil(t, err)
+	assert.True(t, schedule.Next(after).IsZero())
+}
+
+func TestJobSchedulerScheduledJobs(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 30, 0, time.UTC)
+	clock := mock.NewFakeTimeService(start)
+	mockApp := mock.NewMockApp(mock.NewDefaultConfig(), app.WithTimeService(clock))
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	scheduler := componentRegistry.JobScheduler
+	runs := int32(0)
+
+	err := scheduler.Register(jobs.Definition{
+		Name:     "test.scheduled",
+		Schedule: jobs.Every(time.Minute),
+		Handler: func(ctx *context.RequestContext, job *model.Job) error {
+			atomic.AddInt32(&runs, 1)
+
+			return nil
+		},
+	})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, scheduler.Register(jobs.Definition{Name: "test.scheduled", Handler: func(ctx *context.RequestContext, job *model.Job) error { return nil }}))
+
+	// The first tick takes the leader lock, and schedules the first run
+
+	tickAndWait(t, scheduler)
+
+	assert.True(t, scheduler.IsLeader())
+	assert.Equal(t, int32(0), atomic.LoadInt32(&runs))
+
+	clock.Advance(59 * time.Second)
+
+	tickAndWait(t, scheduler)
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&runs))
+
+	clock.Advance(time.Second)
+
+	tickAndWait(t, scheduler)
+	tickAndWait(t, scheduler)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+
+	// Other instances don't run the scheduled jobs while the leader lock is held
+
+	other := newTestScheduler(mockApp, clock, jobs.Options{})
+
+	assert.Nil(t, other.Register(jobs.Definition{
+		Name:     "test.scheduled",
+		Schedule: jobs.Every(time.Minute),
+		Handler: func(ctx *context.RequestContext, job *model.Job) error {
+			atomic.AddInt32(&runs, 100)
+
+			return nil
+		},
+	}))
+
+	tickAndWait(t, other)
+
+	assert.False(t, other.IsLeader())
+
+	for i := 0; i < 3; i++ {
+		clock.Advance(20 * time.Second)
+
+		tickAndWait(t, scheduler)
+		tickAndWait(t, other)
+	}
+
+	assert.False(t, other.IsLeader())
+	assert.True(t, scheduler.IsLeader())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&runs))
+
+	// They take over when the lock expires (because the leader died) or is released
+
+	clock.Advance(jobs.DefaultLeaderLockTTL + time.Second)
+
+	tickAndWait(t, other)
+
+	assert.True(t, other.IsLeader())
+
+	tickAndWait(t, scheduler)
+
+	assert.False(t, scheduler.IsLeader())
+
+	other.Stop()
+
+	tickAndWait(t, scheduler)
+
+	assert.True(t, scheduler.IsLeader())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&runs))
+}
+
+func TestJobSchedulerRetries(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := mock.NewFakeTimeService(start)
+	appConfig, err := mock.NewDefaultConfigLoader().
+		WithOverrides("jobs.retry_base_delay=10s", "jobs.retry_max_delay=15s").
+		Load()
+
+	assert.Nil(t, err)
+
+	mockApp := mock.NewMockApp(appConfig, app.WithTimeService(clock))
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	scheduler := componentRegistry.JobScheduler
+	jobRepository := repository.NewJobRepository(*appConfig, componentRegistry.Db, componentRegistry.Logger)
+	ctx := componentRegistry.RequestContextFactory.NewBackgroundRequestContext()
+	failures := int32(2)
+
+	type greeting struct {
+		Name string `json:"name"`
+	}
+
+	greeted := make(chan string, 10)
+
+	assert.Nil(t, scheduler.Register(jobs.Definition{
+		Name:        "test.flaky",
+		MaxAttempts: 3,
+		Handler: func(ctx *context.RequestContext, job *model.Job) error {
+			if atomic.AddInt32(&failures, -1) >= 0 {
+				return errors.New("receiver is down")
+			}
+
+			payload := &greeting{}
+
+			if err := jobs.UnmarshalPayload(job, payload); err != nil {
+				return err
+			}
+
+			greeted <- payload.Name
+
+			return nil
+		},
+	}))
+
+	assert.Nil(t, scheduler.Register(jobs.Definition{
+		Name:        "test.broken",
+		MaxAttempts: 2,
+		Handler: func(ctx *context.RequestContext, job *model.Job) error {
+			panic("unexpected state")
+		},
+	}))
+
+	_, appErr := scheduler.Enqueue(ctx, "test.unknown", nil)
+
+	assert.NotNil(t, appErr)
+
+	flaky, appErr := scheduler.Enqueue(ctx, "test.flaky", &greeting{Name: "Jane"})
+
+	assert.Nil(t, appErr)
+
+	broken, appErr := scheduler.Enqueue(ctx, "test.broken", nil)
+
+	assert.Nil(t, appErr)
+
+	// Failed jobs are retried after the backoff
+
+	tickAndWait(t, scheduler)
+
+	job := findJob(t, ctx, jobRepository, flaky.ID)
+
+	assert.Equal(t, model.JobStatusPending, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Equal(t, 3, job.MaxAttempts)
+	assert.Equal(t, "receiver is down", job.LastError)
+	assert.Equal(t, start.Add(10*time.Second), job.RunAt)
+	assert.Equal(t, "", job.LockedBy)
+	assert.Nil(t, job.LockedUntil)
+
+	job = findJob(t, ctx, jobRepository, broken.ID)
+
+	assert.Equal(t, model.JobStatusPending, job.Status)
+	assert.Equal(t, "job panicked: unexpected state", job.LastError)
+
+	clock.Advance(9 * time.Second)
+
+	tickAndWait(t, scheduler)
+
+	assert.Equal(t, 1, findJob(t, ctx, jobRepository, flaky.ID).Attempts)
+
+	clock.Advance(time.Second)
+
+	tickAndWait(t, scheduler)
+
+	// The backoff doubles, up to the max delay
+
+	job = findJob(t, ctx, jobRepository, flaky.ID)
+
+	assert.Equal(t, model.JobStatusPending, job.Status)
+	assert.Equal(t, 2, job.Attempts)
+	assert.Equal(t, start.Add(25*time.Second), job.RunAt)
+
+	// Jobs fail for good when they reach their max attempts
+
+	job = findJob(t, ctx, jobRepository, broken.ID)
+
+	assert.Equal(t, model.JobStatusFailed, job.Status)
+	assert.Equal(t, 2, job.Attempts)
+	assert.Equal(t, start.Add(10*time.Second), *job.FinishedAt)
+
+	clock.Advance(15 * time.Second)
+
+	tickAndWait(t, scheduler)
+
+	job = findJob(t, ctx, jobRepository, flaky.ID)
+
+	assert.Equal(t, model.JobStatusSucceeded, job.Status)
+	assert.Equal(t, 3, job.Attempts)
+	assert.Equal(t, "", job.LastError)
+	assert.Equal(t, start.Add(25*time.Second), *job.FinishedAt)
+	assert.Equal(t, "Jane", <-greeted)
+
+	assert.Equal(t, 2, findJob(t, ctx, jobRepository, broken.ID).Attempts)
+
+	// Jobs enqueued for later wait until their time comes
+
+	later, appErr := scheduler.EnqueueAt(ctx, "test.flaky", &greeting{Name: "John"}, clock.GetCurrentUtcTime().Add(time.Hour))
+
+	assert.Nil(t, appErr)
+
+	tickAndWait(t, scheduler)
+
+	assert.Equal(t, model.JobStatusPending, findJob(t, ctx, jobRepository, later.ID).Status)
+
+	clock.Advance(time.Hour)
+
+	tickAndWait(t, scheduler)
+
+	assert.Equal(t, model.JobStatusSucceeded, findJob(t, ctx, jobRepository, later.ID).Status)
+	assert.Equal(t, "John", <-greeted)
+}
+
+func TestJobSchedulerConcurrency(t *testing.T) {
+	clock := mock.NewFakeTimeService(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	appConfig, err := mock.NewDefaultConfigLoader().WithOverrides("jobs.concurrency=3").Load()
+
+	assert.Nil(t, err)
+
+	mockApp := mock.NewMockApp(appConfig, app.WithTimeService(clock))
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	scheduler := componentRegistry.JobScheduler
+	ctx := componentRegistry.RequestContextFactory.NewBackgroundRequestContext()
+	release := make(chan struct{})
+	handler := func(ctx *context.RequestContext, job *model.Job) error {
+		<-release
+
+		return nil
+	}
+
+	assert.Nil(t, scheduler.Register(jobs.Definition{Name: "test.serial", Handler: handler, Concurrency: 1}))
+	assert.Nil(t, scheduler.Register(jobs.Definition{Name: "test.parallel", Handler: handler}))
+
+	for i := 0; i < 3; i++ {
+		_, appErr := scheduler.Enqueue(ctx, "test.serial", nil)
+
+		assert.Nil(t, appErr)
+
+		_, appErr = scheduler.Enqueue(ctx, "test.parallel", nil)
+
+		assert.Nil(t, appErr)
+	}
+
+	// One serial job runs at a time, and three jobs at most
+
+	started, err := scheduler.Tick()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, started)
+
+	started, err = scheduler.Tick()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, started)
+
+	close(release)
+
+	scheduler.Wait()
+
+	started, err = scheduler.Tick()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, started)
+
+	scheduler.Wait()
+
+	started, err = scheduler.Tick()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, started)
+
+	scheduler.Wait()
+}
+
+func TestJobSchedulerReclaimsStaleJobs(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := mock.NewFakeTimeService(start)
+	mockApp := mock.NewMockApp(mock.NewDefaultConfig(), app.WithTimeService(clock))
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	scheduler := componentRegistry.JobScheduler
+	jobRepository := repository.NewJobRepository(*mockApp.App.GetConfig(), componentRegistry.Db, componentRegistry.Logger)
+	ctx := componentRegistry.RequestContextFactory.NewBackgroundRequestContext()
+	runs := int32(0)
+
+	assert.Nil(t, scheduler.Register(jobs.Definition{
+		Name: "test.stale",
+		Handler: func(ctx *context.RequestContext, job *model.Job) error {
+			atomic.AddInt32(&runs, 1)
+
+			return nil
+		},
+	}))
+
+	job, appErr := scheduler.Enqueue(ctx, "test.stale", nil)
+
+	assert.Nil(t, appErr)
+
+	// Another instance claims the job, and dies while running it
+
+	claimed, appErr := jobRepository.Claim(ctx, job, "dead-instance", start, start.Add(jobs.DefaultLockTimeout))
+
+	assert.Nil(t, appErr)
+	assert.True(t, claimed)
+
+	claimed, appErr = jobRepository.Claim(ctx, findJob(t, ctx, jobRepository, job.ID), scheduler.GetOwner(), start, start.Add(jobs.DefaultLockTimeout))
+
+	assert.Nil(t, appErr)
+	assert.False(t, claimed)
+
+	tickAndWait(t, scheduler)
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&runs))
+
+	// Its lock expires, so the job is run again
+
+	clock.Advance(jobs.DefaultLockTimeout + time.Second)
+
+	tickAndWait(t, scheduler)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+
+	stored := findJob(t, ctx, jobRepository, job.ID)
+
+	assert.Equal(t, model.JobStatusSucceeded, stored.Status)
+	assert.Equal(t, 2, stored.Attempts)
+
+	// The dead instance can't overwrite the result anymore
+
+	job.Status = model.JobStatusFailed
+
+	updated, appErr := jobRepository.Update(ctx, job, "dead-instance")
+
+	assert.Nil(t, appErr)
+	assert.False(t, updated)
+	assert.Equal(t, model.JobStatusSucceeded, findJob(t, ctx, jobRepository, job.ID).Status)
+}
+
+func TestJobPurges(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 30, 0, time.UTC)
+	clock := mock.NewFakeTimeService(start)
+	appConfig, err := mock.NewDefaultConfigLoader().
+		WithOverrides("jobs.retention=1h", "jobs.purge_schedule=@hourly", "auth.reset_token_ttl=1h").
+		Load()
+
+	assert.Nil(t, err)
+
+	mockApp := mock.NewMockApp(appConfig, app.WithTimeService(clock))
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	scheduler := componentRegistry.JobScheduler
+	jobRepository := repository.NewJobRepository(*appConfig, componentRegistry.Db, componentRegistry.Logger)
+	ctx := componentRegistry.RequestContextFactory.NewBackgroundRequestContext()
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+
+	CreateUserWithPassword(t, mockApp, "test-user-1", userTypeReq.Name, "jane@example.com", "test-password")
+
+	response, err := mockApp.NewPostRequest(
+		"/auth/password-reset/request",
+		mock.NewMockAppOptions().WithBody(resource.PasswordResetRequestResource{Username: "test-user-1"}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusAccepted, response.Code)
+	assert.Equal(t, 1, countRows(t, mockApp, "password_reset_tokens"))
+
+	assert.Nil(t, scheduler.Register(jobs.Definition{
+		Name:    "test.noop",
+		Handler: func(ctx *context.RequestContext, job *model.Job) error { return nil },
+	}))
+
+	job, appErr := scheduler.Enqueue(ctx, "test.noop", nil)
+
+	assert.Nil(t, appErr)
+
+	tickAndWait(t, scheduler)
+
+	assert.Equal(t, model.JobStatusSucceeded, findJob(t, ctx, jobRepository, job.ID).Status)
+
+	// Tokens and jobs are kept until they expire, or the retention ends
+
+	clock.Set(time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC))
+
+	tickAndWait(t, scheduler)
+
+	assert.Equal(t, 1, countRows(t, mockApp, "password_reset_tokens"))
+	assert.NotNil(t, findJob(t, ctx, jobRepository, job.ID))
+
+	clock.Set(time.Date(2026, 1, 1, 2, 0, 0, 0, time.UTC))
+
+	tickAndWait(t, scheduler)
+
+	assert.Equal(t, 0, countRows(t, mockApp, "password_reset_tokens"))
+
+	stored, appErr := jobRepository.FindOneByID(ctx, job.ID)
+
+	assert.Nil(t, appErr)
+	assert.Nil(t, stored)
+}
+
+func tickAndWait(t *testing.T, scheduler *jobs.Scheduler) {
+	_, err := scheduler.Tick()
+
+	assert.Nil(t, err)
+
+	scheduler.Wait()
+}
+
+func newTestScheduler(mockApp *mock.MockApp, clock *mock.FakeTimeService, options jobs.Options) *jobs.Scheduler {
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	appConfig := *mockApp.App.GetConfig()
+
+	return jobs.NewScheduler(
+		repository.NewJobRepository(appConfig, componentRegistry.Db, componentRegistry.Logger),
+		repository.NewJobLockRepository(appConfig, componentRegistry.Db, componentRegistry.Logger),
+		componentRegistry.RequestContextFactory,
+		clock,
+		componentRegistry.Logger,
+		componentRegistry.Metrics,
+		options,
+	)
+}
+
+func findJob(t *testing.T, ctx *context.RequestContext, jobRepository repository.JobRepository, ID int64) *model.Job {
+	job, appErr := jobRepository.FindOneByID(ctx, ID)
+
+	assert.Nil(t, appErr)
+
+	return job
+}
+
+func countRows(t *testing.T, mockApp *mock.MockApp, table string) int {
+	res := 0
+
+	err := mockApp.App.GetComponentRegistry().Db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&res)
+
+	assert.Nil(t, err)
+
+	return res
+}
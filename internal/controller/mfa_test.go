@@ -0,0 +1,421 @@
+package controller_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/app"
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/module"
+	"github.com/comfortablynumb/goginrestapi/internal/repository"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/comfortablynumb/goginrestapi/internal/totp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthMfa(t *testing.T) {
+	clock := mock.NewFakeTimeService(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	mockApp := mock.NewMockApp(mock.NewDefaultConfig(), app.WithTimeService(clock))
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	userTypeRes := &resource.UserTypeResource{}
+
+	response, err := mockApp.NewPostRequest(
+		"/user_type",
+		mock.NewMockAppOptions().
+			WithBody(resource.UserTypeCreateResource{Name: "test-admin", RequiresMfa: true}).
+			WithExpectedResponse(userTypeRes),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.True(t, userTypeRes.RequiresMfa)
+
+	CreateUserWithPassword(t, mockApp, "test-admin-1", userTypeRes.Name, "", "test-password")
+
+	// Users of the type must enroll a second factor before logging in
+
+	httpError := &apperror.HttpError{}
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/login",
+		mock.NewMockAppOptions().
+			WithBody(resource.LoginResource{Username: "test-admin-1", Password: "test-password"}).
+			WithExpectedResponse(httpError),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	assert.Equal(t, apperror.MfaEnrollmentRequiredErrorCode, httpError.Code)
+
+	// Enrolling requires the password
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/mfa/enroll",
+		mock.NewMockAppOptions().WithBody(resource.MfaEnrollResource{Username: "test-admin-1", Password: "wrong-password"}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+
+	enrollment := &resource.MfaEnrollmentResource{}
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/mfa/enroll",
+		mock.NewMockAppOptions().
+			WithBody(resource.MfaEnrollResource{Username: "test-admin-1", Password: "test-password"}).
+			WithExpectedResponse(enrollment),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NotEmpty(t, enrollment.Secret)
+	assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/goginrestapi:test-admin-1?"), enrollment.URI)
+	assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+
+	// The enrollment is confirmed with the first code
+
+	httpError = &apperror.HttpError{}
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/mfa/confirm",
+		mock.NewMockAppOptions().
+			WithBody(resource.MfaConfirmResource{Username: "test-admin-1", Password: "test-password", Code: getWrongTotpCode(t, enrollment.Secret, clock)}).
+			WithExpectedResponse(httpError),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+	assert.Equal(t, apperror.InvalidMfaCodeErrorCode, httpError.Code)
+
+	recoveryCodes := &resource.MfaRecoveryCodesResource{}
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/mfa/confirm",
+		mock.NewMockAppOptions().
+			WithBody(resource.MfaConfirmResource{Username: "test-admin-1", Password: "test-password", Code: getTotpCode(t, enrollment.Secret, clock)}).
+			WithExpectedResponse(recoveryCodes),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Len(t, recoveryCodes.RecoveryCodes, 10)
+
+	// A second factor can't be enrolled twice
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/mfa/enroll",
+		mock.NewMockAppOptions().WithBody(resource.MfaEnrollResource{Username: "test-admin-1", Password: "test-password"}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	// The password alone is not enough anymore
+
+	httpError = &apperror.HttpError{}
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/login",
+		mock.NewMockAppOptions().
+			WithBody(resource.LoginResource{Username: "test-admin-1", Password: "test-password"}).
+			WithExpectedResponse(httpError),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+	assert.Equal(t, apperror.MfaRequiredErrorCode, httpError.Code)
+
+	// The code used to confirm the enrollment can't be used again
+
+	httpError = &apperror.HttpError{}
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/login",
+		mock.NewMockAppOptions().
+			WithBody(resource.LoginResource{Username: "test-admin-1", Password: "test-password", Code: getTotpCode(t, enrollment.Secret, clock)}).
+			WithExpectedResponse(httpError),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+	assert.Equal(t, apperror.InvalidMfaCodeErrorCode, httpError.Code)
+
+	clock.Advance(totp.Period)
+
+	res := &resource.UserResource{}
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/login",
+		mock.NewMockAppOptions().
+			WithBody(resource.LoginResource{Username: "test-admin-1", Password: "test-password", Code: getTotpCode(t, enrollment.Secret, clock)}).
+			WithExpectedResponse(res),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.True(t, res.MfaEnabled)
+	assert.Equal(t, clock.GetCurrentUtcTime().Add(-totp.Period), *res.MfaEnabledAt)
+	assert.Equal(t, 0, res.FailedLoginAttempts)
+
+	// Recovery codes can be used once, typed in any case and without separators
+
+	recoveryCode := strings.ToUpper(strings.ReplaceAll(recoveryCodes.RecoveryCodes[0], "-", ""))
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/login",
+		mock.NewMockAppOptions().WithBody(resource.LoginResource{Username: "test-admin-1", Password: "test-password", RecoveryCode: recoveryCode}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	httpError = &apperror.HttpError{}
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/login",
+		mock.NewMockAppOptions().
+			WithBody(resource.LoginResource{Username: "test-admin-1", Password: "test-password", RecoveryCode: recoveryCode}).
+			WithExpectedResponse(httpError),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+	assert.Equal(t, apperror.InvalidMfaCodeErrorCode, httpError.Code)
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/login",
+		mock.NewMockAppOptions().WithBody(resource.LoginResource{Username: "test-admin-1", Password: "test-password", RecoveryCode: recoveryCodes.RecoveryCodes[1]}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	// Enabling the second factor is audited
+
+	history := &resource.AuditEventResourceList{}
+
+	response, err = mockApp.NewGetRequest("/user/test-admin-1/history", mock.NewMockAppOptions().WithExpectedResponse(history))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, 2, len(history.Data))
+	assert.Equal(t, model.AuditActionUpdate, history.Data[1].Action)
+	assert.Equal(t, false, history.Data[1].Changes["mfa_enabled"].Before)
+	assert.Equal(t, true, history.Data[1].Changes["mfa_enabled"].After)
+}
+
+func TestAuthMfaClockSkew(t *testing.T) {
+	clock := mock.NewFakeTimeService(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	mockApp := mock.NewMockApp(mock.NewDefaultConfig(), app.WithTimeService(clock))
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+
+	CreateUserWithPassword(t, mockApp, "test-user-1", userTypeReq.Name, "", "test-password")
+
+	secret := enrollMfa(t, mockApp, clock, "test-user-1", "test-password")
+
+	// Codes of the previous time step are still accepted, but not older ones
+
+	clock.Advance(3 * totp.Period)
+
+	for _, testCase := range []struct {
+		age          time.Duration
+		expectedCode int
+	}{
+		{age: 2 * totp.Period, expectedCode: http.StatusUnauthorized},
+		{age: totp.Period, expectedCode: http.StatusOK},
+	} {
+		code, err := totp.GenerateCode(secret, clock.GetCurrentUtcTime().Add(-testCase.age))
+
+		assert.Nil(t, err)
+
+		response, err := mockApp.NewPostRequest(
+			"/auth/login",
+			mock.NewMockAppOptions().WithBody(resource.LoginResource{Username: "test-user-1", Password: "test-password", Code: code}),
+		)
+
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.expectedCode, response.Code)
+	}
+}
+
+func TestAuthMfaFailedCodesAndDisable(t *testing.T) {
+	appConfig, err := mock.NewDefaultConfigLoader().WithOverrides("auth.max_failed_logins=2").Load()
+
+	assert.Nil(t, err)
+
+	clock := mock.NewFakeTimeService(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	mockApp := mock.NewMockApp(appConfig, app.WithTimeService(clock))
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+
+	CreateUserWithPassword(t, mockApp, "test-user-1", userTypeReq.Name, "", "test-password")
+
+	secret := enrollMfa(t, mockApp, clock, "test-user-1", "test-password")
+
+	clock.Advance(totp.Period)
+
+	// Users who enabled a second factor must use it, even if their type doesn't require it. Wrong codes count as
+	// failed logins
+
+	for i, expectedCode := range []int{http.StatusUnauthorized, http.StatusLocked} {
+		response, err := mockApp.NewPostRequest(
+			"/auth/login",
+			mock.NewMockAppOptions().WithBody(resource.LoginResource{Username: "test-user-1", Password: "test-password", Code: getWrongTotpCode(t, secret, clock)}),
+		)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCode, response.Code, "attempt %d", i+1)
+	}
+
+	response, err := mockApp.NewPostRequest("/user/test-user-1/unlock", mock.NewMockAppOptions())
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	// Admins can disable the second factor
+
+	res := &resource.UserResource{}
+
+	response, err = mockApp.NewDeleteRequest("/user/test-user-1/mfa", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.False(t, res.MfaEnabled)
+	assert.Nil(t, res.MfaEnabledAt)
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/login",
+		mock.NewMockAppOptions().WithBody(resource.LoginResource{Username: "test-user-1", Password: "test-password"}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	// A new second factor can be enrolled afterwards
+
+	enrollMfa(t, mockApp, clock, "test-user-1", "test-password")
+
+	response, err = mockApp.NewDeleteRequest("/user/unknown-user/mfa", mock.NewMockAppOptions())
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func enrollMfa(t *testing.T, mockApp *mock.MockApp, clock *mock.FakeTimeService, username string, password string) string {
+	enrollment := &resource.MfaEnrollmentResource{}
+
+	response, err := mockApp.NewPostRequest(
+		"/auth/mfa/enroll",
+		mock.NewMockAppOptions().
+			WithBody(resource.MfaEnrollResource{Username: username, Password: password}).
+			WithExpectedResponse(enrollment),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	response, err = mockApp.NewPostRequest(
+		"/auth/mfa/confirm",
+		mock.NewMockAppOptions().WithBody(resource.MfaConfirmResource{Username: username, Password: password, Code: getTotpCode(t, enrollment.Secret, clock)}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	return enrollment.Secret
+}
+
+func TestAuthMfaTotpStepsAreUsedOnce(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+
+	CreateUserWithPassword(t, mockApp, "test-user-1", userTypeReq.Name, "", "test-password")
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	requestContext := componentRegistry.RequestContextFactory.NewBackgroundRequestContext()
+	userRepository, err := componentregistry.Get[repository.UserRepository](componentRegistry, module.UserRepositoryComponentName)
+
+	assert.Nil(t, err)
+
+	// Both logins read the user before any of them stores the step of its code, so both see it as unused
+
+	first, appErr := userRepository.FindOneByUsername(requestContext, "test-user-1")
+
+	assert.Nil(t, appErr)
+
+	second, appErr := userRepository.FindOneByUsername(requestContext, "test-user-1")
+
+	assert.Nil(t, appErr)
+
+	used, appErr := userRepository.UseTotpStep(requestContext, first, 100)
+
+	assert.Nil(t, appErr)
+	assert.True(t, used)
+	assert.Equal(t, int64(100), first.TotpLastUsedStep)
+
+	// Only the first one can use it
+
+	used, appErr = userRepository.UseTotpStep(requestContext, second, 100)
+
+	assert.Nil(t, appErr)
+	assert.False(t, used)
+	assert.Equal(t, int64(0), second.TotpLastUsedStep)
+
+	// Older steps can't be used either, but newer ones can
+
+	used, appErr = userRepository.UseTotpStep(requestContext, second, 99)
+
+	assert.Nil(t, appErr)
+	assert.False(t, used)
+
+	used, appErr = userRepository.UseTotpStep(requestContext, second, 101)
+
+	assert.Nil(t, appErr)
+	assert.True(t, used)
+}
+
+func getTotpCode(t *testing.T, secret string, clock *mock.FakeTimeService) string {
+	code, err := totp.GenerateCode(secret, clock.GetCurrentUtcTime())
+
+	assert.Nil(t, err)
+
+	return code
+}
+
+// getWrongTotpCode Returns a code which is not valid for the secret around the current time.
+func getWrongTotpCode(t *testing.T, secret string, clock *mock.FakeTimeService) string {
+	code := getTotpCode(t, secret, clock)
+
+	for _, candidate := range []string{"000000", "111111", "222222", "333333"} {
+		if _, valid := totp.Validate(secret, candidate, clock.GetCurrentUtcTime(), 1); !valid && candidate != code {
+			return candidate
+		}
+	}
+
+	t.Fatal("could NOT find a wrong TOTP code")
+
+	return ""
+}
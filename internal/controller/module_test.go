@@ -0,0 +1,157 @@
+package controller_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/module"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// testModule Module which does nothing, with the given name and dependencies.
+type testModule struct {
+	module.CacheModule
+	name         string
+	dependencies []string
+}
+
+func (m *testModule) GetName() string {
+	return m.name
+}
+
+func (m *testModule) GetDependencies() []string {
+	return m.dependencies
+}
+
+func TestModuleManagerSort(t *testing.T) {
+	// Modules come after their dependencies, and the order in which they were added is kept otherwise
+
+	moduleManager := module.NewModuleManager().
+		AddModule(&testModule{name: "c", dependencies: []string{"b"}}).
+		AddModule(&testModule{name: "d"}).
+		AddModule(&testModule{name: "b", dependencies: []string{"a"}}).
+		AddModule(&testModule{name: "a"})
+
+	assert.Nil(t, moduleManager.Sort())
+	assert.Equal(t, []string{"a", "b", "c", "d"}, getModuleNames(moduleManager))
+
+	// The modules of the app don't depend on the order in which they're added
+
+	moduleManager = module.NewModuleManager().
+		AddModule(&module.FixturesModule{}).
+		AddModule(&module.UserModule{}).
+		AddModule(&module.WebhookModule{}).
+		AddModule(&module.UserTypeModule{}).
+		AddModule(&module.IdempotencyModule{}).
+		AddModule(&module.AuditModule{})
+
+	assert.Nil(t, moduleManager.Sort())
+	assert.Equal(
+		t,
+		[]string{
+			module.AuditModuleName,
+			module.IdempotencyModuleName,
+			module.UserTypeModuleName,
+			module.UserModuleName,
+			module.FixturesModuleName,
+			module.WebhookModuleName,
+		},
+		getModuleNames(moduleManager),
+	)
+
+	// Cycles, missing dependencies and duplicated modules are rejected
+
+	for _, testCase := range []struct {
+		modules       []module.Module
+		expectedError string
+	}{
+		{
+			modules: []module.Module{
+				&testModule{name: "a", dependencies: []string{"b"}},
+				&testModule{name: "b", dependencies: []string{"c"}},
+				&testModule{name: "c", dependencies: []string{"a"}},
+			},
+			expectedError: "modules have a dependency cycle: a -> b -> c -> a",
+		},
+		{
+			modules:       []module.Module{&testModule{name: "a", dependencies: []string{"a"}}},
+			expectedError: "modules have a dependency cycle: a -> a",
+		},
+		{
+			modules:       []module.Module{&module.UserModule{}, &module.AuditModule{}, &module.IdempotencyModule{}},
+			expectedError: "module 'user' depends on module 'user_type', which was not added",
+		},
+		{
+			modules:       []module.Module{&testModule{name: "a"}, &testModule{name: "a"}},
+			expectedError: "module 'a' was added more than once",
+		},
+	} {
+		moduleManager = module.NewModuleManager()
+
+		for _, m := range testCase.modules {
+			moduleManager.AddModule(m)
+		}
+
+		err := moduleManager.Sort()
+
+		assert.NotNil(t, err)
+
+		if err != nil {
+			assert.Equal(t, testCase.expectedError, err.Error())
+		}
+	}
+}
+
+func TestComponentRegistryGet(t *testing.T) {
+	componentRegistry := componentregistry.NewComponentRegistry()
+
+	componentRegistry.Set("timeout", 5*time.Second).
+		Set("time_service", service.NewTimeService())
+
+	timeout, err := componentregistry.Get[time.Duration](componentRegistry, "timeout")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 5*time.Second, timeout)
+
+	// Components can be read as any interface they implement
+
+	stringer, err := componentregistry.Get[fmt.Stringer](componentRegistry, "timeout")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "5s", stringer.String())
+
+	timeService, err := componentregistry.Get[service.TimeService](componentRegistry, "time_service")
+
+	assert.Nil(t, err)
+	assert.NotNil(t, timeService)
+
+	// Wrong types and missing components are returned as errors
+
+	_, err = componentregistry.Get[string](componentRegistry, "timeout")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "component 'timeout' is a time.Duration, not a string", err.Error())
+
+	_, err = componentregistry.Get[service.UserService](componentRegistry, "time_service")
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "not a service.UserService")
+
+	_, err = componentregistry.Get[time.Duration](componentRegistry, "unknown")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "component 'unknown' is not registered in the component registry", err.Error())
+}
+
+func getModuleNames(moduleManager *module.ModuleManager) []string {
+	res := make([]string, 0)
+
+	for _, m := range moduleManager.GetModules() {
+		res = append(res, m.GetName())
+	}
+
+	return res
+}
@@ -0,0 +1,281 @@
+package controller_test
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/middleware"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/comfortablynumb/goginrestapi/internal/ratelimit"
+	"github.com/comfortablynumb/goginrestapi/internal/redis"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitTokenBucketByIP(t *testing.T) {
+	appConfig := mock.NewDefaultConfig()
+	appConfig.RateLimits = map[string]string{"user_type": "2/1m"}
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	for i := 1; i <= 2; i++ {
+		req := resource.UserTypeCreateResource{Name: "test-user-type-" + strconv.Itoa(i)}
+
+		response, err := mockApp.NewPostRequest("/user_type", mock.NewMockAppOptions().WithBody(req))
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusCreated, response.Code)
+		assert.Equal(t, "2", response.Header().Get(middleware.RateLimitLimitHeader))
+		assert.Equal(t, strconv.Itoa(2-i), response.Header().Get(middleware.RateLimitRemainingHeader))
+	}
+
+	res := &apperror.HttpError{}
+	req := resource.UserTypeCreateResource{Name: "test-user-type-3"}
+
+	response, err := mockApp.NewPostRequest("/user_type", mock.NewMockAppOptions().WithBody(req).WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, response.Code)
+	assert.Equal(t, apperror.TooManyRequestsErrorCode, res.Code)
+	assert.Equal(t, "0", response.Header().Get(middleware.RateLimitRemainingHeader))
+
+	retryAfter, _ := strconv.Atoi(response.Header().Get(middleware.RetryAfterHeader))
+
+	assert.True(t, retryAfter > 0 && retryAfter <= 30)
+
+	// Other route groups are not limited
+
+	response, err = mockApp.NewGetRequest("/user", nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Empty(t, response.Header().Get(middleware.RateLimitLimitHeader))
+}
+
+func TestRateLimitSlidingWindowByAPIKey(t *testing.T) {
+	appConfig := mock.NewDefaultConfig()
+	appConfig.RateLimits = map[string]string{"user_type": "1/1m sliding_window api_key"}
+	appConfig.Auth.APIKeys = map[string]string{"client-1": "key-1", "client-2": "key-2"}
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	response, err := mockApp.NewGetRequest("/user_type", mock.NewMockAppOptions().WithHeader(middleware.APIKeyHeader, "key-1"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	response, err = mockApp.NewGetRequest("/user_type", mock.NewMockAppOptions().WithHeader(middleware.APIKeyHeader, "key-1"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, response.Code)
+	assert.NotEmpty(t, response.Header().Get(middleware.RetryAfterHeader))
+
+	// Every API key has its own quota
+
+	response, err = mockApp.NewGetRequest("/user_type", mock.NewMockAppOptions().WithHeader(middleware.APIKeyHeader, "key-2"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	// Unknown API keys can't be used to get a new quota: their requests are counted by IP
+
+	response, err = mockApp.NewGetRequest("/user_type", mock.NewMockAppOptions().WithHeader(middleware.APIKeyHeader, "forged-1"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	response, err = mockApp.NewGetRequest("/user_type", mock.NewMockAppOptions().WithHeader(middleware.APIKeyHeader, "forged-2"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, response.Code)
+}
+
+func TestRateLimitByUserIgnoresTheActorHeader(t *testing.T) {
+	appConfig := mock.NewDefaultConfig()
+	appConfig.RateLimits = map[string]string{"user_type": "1/1m token_bucket user"}
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	response, err := mockApp.NewGetRequest("/user_type", mock.NewMockAppOptions().WithHeader(context.ActorHeader, "user-1"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	response, err = mockApp.NewGetRequest("/user_type", mock.NewMockAppOptions().WithHeader(context.ActorHeader, "user-2"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, response.Code)
+}
+
+func TestRateLimitRedisStore(t *testing.T) {
+	redisServer, err := miniredis.Run()
+
+	assert.Nil(t, err)
+
+	defer redisServer.Close()
+
+	appConfig, err := mock.NewDefaultConfigLoader().
+		WithOverrides("rate_limit_store=redis", "redis.address="+redisServer.Addr(), "redis.prefix=test:").
+		Load()
+
+	assert.Nil(t, err)
+
+	appConfig.RateLimits = map[string]string{"user_type": "1/1m"}
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+		mockApp.App.Close()
+	}()
+
+	assert.IsType(t, &ratelimit.RedisStore{}, mockApp.App.GetComponentRegistry().RateLimitStore)
+
+	response, err := mockApp.NewGetRequest("/user_type", nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	response, err = mockApp.NewGetRequest("/user_type", nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, response.Code)
+
+	keys := redisServer.Keys()
+
+	assert.Len(t, keys, 1)
+	assert.Regexp(t, "^test:"+ratelimit.RedisStorePrefix+"user_type:ip:", keys[0])
+
+	// The state expires with the key
+
+	redisServer.FastForward(2 * time.Minute)
+
+	assert.Empty(t, redisServer.Keys())
+}
+
+func TestRateLimitRedisStoreUpdatesAreAtomic(t *testing.T) {
+	redisServer, err := miniredis.Run()
+
+	assert.Nil(t, err)
+
+	defer redisServer.Close()
+
+	appConfig := mock.NewDefaultConfig()
+	appConfig.Redis.Address = redisServer.Addr()
+
+	client := redis.NewClient(appConfig.Redis)
+
+	defer client.Close()
+
+	store := ratelimit.NewRedisStore(client, ratelimit.RedisStorePrefix)
+	increment := func(state *ratelimit.State) *ratelimit.State {
+		if state == nil {
+			return &ratelimit.State{Count: 1}
+		}
+
+		return &ratelimit.State{Count: state.Count + 1}
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := store.Update("key", time.Minute, increment)
+
+			assert.Nil(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	state, err := store.Update("key", time.Minute, increment)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(6), state.Count)
+
+	// Errors of Redis are returned, so the middleware can let the requests through
+
+	redisServer.SetError("unavailable")
+
+	_, err = store.Update("key", time.Minute, increment)
+
+	assert.NotNil(t, err)
+}
+
+func TestRateLimitSlidingWindowWeightsThePreviousWindow(t *testing.T) {
+	now := time.Now().Truncate(time.Minute)
+	nowFn := func() time.Time { return now }
+	limiter := ratelimit.NewSlidingWindowLimiter(ratelimit.NewMemoryStore(nowFn), 10, time.Minute, nowFn)
+
+	for i := 0; i < 10; i++ {
+		result, err := limiter.Allow("key")
+
+		assert.Nil(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 9-i, result.Remaining)
+	}
+
+	result, err := limiter.Allow("key")
+
+	assert.Nil(t, err)
+	assert.False(t, result.Allowed)
+
+	// Half way through the next window, half of the previous requests are still counted
+
+	now = now.Add(90 * time.Second)
+
+	for i := 0; i < 5; i++ {
+		result, err = limiter.Allow("key")
+
+		assert.Nil(t, err)
+		assert.True(t, result.Allowed)
+	}
+
+	result, err = limiter.Allow("key")
+
+	assert.Nil(t, err)
+	assert.False(t, result.Allowed)
+	assert.InDelta(t, float64(6*time.Second), float64(result.RetryAfter), float64(time.Millisecond))
+
+	now = now.Add(result.RetryAfter + time.Millisecond)
+
+	result, err = limiter.Allow("key")
+
+	assert.Nil(t, err)
+	assert.True(t, result.Allowed)
+}
+
+func TestRateLimitInvalidPolicies(t *testing.T) {
+	for _, spec := range []string{"", "10", "0/1m", "10/forever", "10/1m leaky_bucket", "10/1m token_bucket cookie"} {
+		_, err := ratelimit.ParsePolicy(spec)
+
+		assert.NotNil(t, err, spec)
+	}
+
+	policy, err := ratelimit.ParsePolicy("10/1m sliding_window user")
+
+	assert.Nil(t, err)
+	assert.Equal(t, ratelimit.Policy{Limit: 10, Period: time.Minute, Algorithm: ratelimit.SlidingWindowAlgorithm, Key: ratelimit.KeyByUser}, policy)
+}
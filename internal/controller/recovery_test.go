@@ -0,0 +1,174 @@
+package controller_test
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/comfortablynumb/goginrestapi/internal/app"
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/errorreport"
+	"github.com/comfortablynumb/goginrestapi/internal/hooks"
+	"github.com/comfortablynumb/goginrestapi/internal/middleware"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoveryConvertsPanicsIntoInternalErrors(t *testing.T) {
+	reportFilePath := filepath.Join(t.TempDir(), "errors.log")
+	appConfig, err := mock.NewDefaultConfigLoader().WithOverrides("errors.report_file_path=" + reportFilePath).Load()
+
+	assert.Nil(t, err)
+
+	memorySink := errorreport.NewMemorySink()
+	sentryClient := &mockSentryClient{}
+	mockApp := mock.NewMockApp(
+		appConfig,
+		app.WithComponentRegistryHook(hooks.ComponentRegistryHookFunc(
+			func(componentRegistry *componentregistry.ComponentRegistry) *componentregistry.ComponentRegistry {
+				componentRegistry.ErrorReporter.
+					AddSink(memorySink).
+					AddSink(errorreport.NewSentrySink(sentryClient)).
+					AddSink(&failingErrorReportSink{})
+
+				return componentRegistry
+			},
+		)),
+		app.WithRouterHook(hooks.RouterHookFunc(func(router *gin.Engine) *gin.Engine {
+			router.GET("/panic/:kind", func(c *gin.Context) {
+				if c.Param("kind") == "error" {
+					panic(errors.New("unexpected state"))
+				}
+
+				c.String(http.StatusOK, "partial")
+
+				panic("after writing")
+			})
+
+			return router
+		})),
+	)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	// The panic is responded as an internal error, with the request id
+
+	res := &apperror.HttpError{}
+
+	response, err := mockApp.NewGetRequest(
+		"/panic/error",
+		mock.NewMockAppOptions().WithHeader(context.RequestIDHeader, "test-panic-request").WithExpectedResponse(res),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+	assert.Equal(t, "test-panic-request", response.Header().Get(context.RequestIDHeader))
+	assert.Equal(t, apperror.InternalErrorCode, res.Code)
+	assert.Equal(t, apperror.InternalErrorMessage, res.Message)
+	assert.Equal(t, "test-panic-request", res.Data["request_id"])
+
+	// Problem details are rendered too, if accepted
+
+	problem := &apperror.ProblemDetails{}
+
+	response, err = mockApp.NewGetRequest(
+		"/panic/error",
+		mock.NewMockAppOptions().
+			WithHeader("Accept", apperror.ProblemContentType).
+			WithHeader("Accept-Language", "es").
+			WithExpectedResponse(problem),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+	assert.Equal(t, apperror.InternalErrorCode, problem.Code)
+	assert.Equal(t, "Error interno del servidor.", problem.Title)
+	assert.NotEmpty(t, problem.RequestID)
+
+	// Responses already written are just aborted
+
+	response, err = mockApp.NewGetRequest("/panic/written", mock.NewMockAppOptions())
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "partial", response.Body.String())
+
+	// Panics are counted by route
+
+	panics := mockApp.App.GetComponentRegistry().Metrics.Counter(middleware.PanicsMetricName, "")
+
+	assert.Equal(t, float64(3), panics.Get("/panic/:kind"))
+
+	response, err = mockApp.NewGetRequest("/metrics", mock.NewMockAppOptions())
+
+	assert.Nil(t, err)
+	assert.Contains(t, response.Body.String(), `http_panics_total{route="/panic/:kind"} 3`)
+
+	// And reported to every sink, with their stack. Failing sinks don't affect the rest
+
+	reports := memorySink.GetReports()
+
+	assert.Len(t, reports, 3)
+	assert.Equal(t, middleware.RecoverySourceName, reports[0].Source)
+	assert.Equal(t, "unexpected state", reports[0].Message)
+	assert.Equal(t, "test-panic-request", reports[0].RequestID)
+	assert.Equal(t, http.MethodGet, reports[0].Method)
+	assert.Equal(t, "/panic/:kind", reports[0].Route)
+	assert.Contains(t, reports[0].Stack, "recovery_test.go")
+	assert.False(t, reports[0].Time.IsZero())
+	assert.Equal(t, "after writing", reports[2].Message)
+
+	assert.Equal(t, []string{"unexpected state", "unexpected state", "after writing"}, sentryClient.messages)
+	assert.Equal(t, "test-panic-request", sentryClient.tags[0]["request_id"])
+
+	content, err := ioutil.ReadFile(reportFilePath)
+
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+
+	assert.Len(t, lines, 3)
+
+	fileReport := &errorreport.Report{}
+
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), fileReport))
+	assert.Equal(t, reports[0].Message, fileReport.Message)
+	assert.Equal(t, reports[0].Stack, fileReport.Stack)
+}
+
+// mockSentryClient
+
+type mockSentryClient struct {
+	messages []string
+	tags     []map[string]string
+}
+
+func (c *mockSentryClient) CaptureException(err error, tags map[string]string, extra map[string]interface{}) error {
+	c.messages = append(c.messages, err.Error())
+	c.tags = append(c.tags, tags)
+
+	return nil
+}
+
+// failingErrorReportSink
+
+type failingErrorReportSink struct {
+}
+
+func (s *failingErrorReportSink) GetName() string {
+	return "failing"
+}
+
+func (s *failingErrorReportSink) Report(ctx gocontext.Context, report *errorreport.Report) error {
+	return errors.New("sink is down")
+}
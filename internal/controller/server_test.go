@@ -0,0 +1,432 @@
+package controller_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/comfortablynumb/goginrestapi/internal/server"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
+)
+
+func TestServerMutualTLS(t *testing.T) {
+	dir := createConfigTestDir(t)
+
+	defer os.RemoveAll(dir)
+
+	ca, caKey := createTestCertificate(t, dir, "ca", "Test CA", nil, nil)
+	createTestCertificate(t, dir, "server", "localhost", ca, caKey)
+	clientCert, _ := createTestCertificate(t, dir, "client", "test-client", ca, caKey)
+
+	appConfig, err := mock.NewDefaultConfigLoader().
+		WithOverrides(
+			"server.tls.cert_file="+filepath.Join(dir, "server.crt"),
+			"server.tls.key_file="+filepath.Join(dir, "server.key"),
+			"server.tls.client_ca_file="+filepath.Join(dir, "ca.crt"),
+			"server.tls.client_auth=verify_if_given",
+		).
+		Load()
+
+	assert.Nil(t, err)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	baseUrl, shutdown := startTestServer(t, appConfig.Server, mockApp.App.GetRouter())
+
+	defer shutdown()
+
+	rootCAs := x509.NewCertPool()
+
+	rootCAs.AddCert(ca)
+
+	clientKeyPair, err := tls.LoadX509KeyPair(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "test-client", clientCert.Subject.CommonName)
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: rootCAs, Certificates: []tls.Certificate{clientKeyPair}},
+			ForceAttemptHTTP2: true,
+		},
+	}
+
+	// The client certificate is mapped to the actor, which can't be overridden with the header
+
+	body, err := json.Marshal(resource.UserTypeCreateResource{Name: "mtls-user-type"})
+
+	assert.Nil(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, baseUrl+"/user_type", bytes.NewReader(body))
+
+	assert.Nil(t, err)
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Actor", "spoofed")
+
+	response, err := client.Do(request)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	assert.Equal(t, 2, response.ProtoMajor)
+
+	response.Body.Close()
+
+	response, err = client.Get(baseUrl + "/user_type/mtls-user-type/history")
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	history := &resource.AuditEventResourceList{}
+
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(history))
+	assert.Equal(t, 1, len(history.Data))
+	assert.Equal(t, "test-client", history.Data[0].Actor)
+
+	response.Body.Close()
+
+	// Client certificates are optional with verify_if_given
+
+	anonymousClient := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: rootCAs}},
+	}
+
+	response, err = anonymousClient.Get(baseUrl + "/user_type/mtls-user-type")
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response.Body.Close()
+
+	// Certificates signed by other CAs are rejected (they're sent even if the server doesn't accept their CA)
+
+	otherCa, otherCaKey := createTestCertificate(t, dir, "other-ca", "Other CA", nil, nil)
+	createTestCertificate(t, dir, "other-client", "other-client", otherCa, otherCaKey)
+
+	otherKeyPair, err := tls.LoadX509KeyPair(filepath.Join(dir, "other-client.crt"), filepath.Join(dir, "other-client.key"))
+
+	assert.Nil(t, err)
+
+	otherClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs: rootCAs,
+				GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+					return &otherKeyPair, nil
+				},
+			},
+		},
+	}
+
+	_, err = otherClient.Get(baseUrl + "/user_type/mtls-user-type")
+
+	assert.NotNil(t, err)
+}
+
+func TestServerTLSConfigErrors(t *testing.T) {
+	dir := createConfigTestDir(t)
+
+	defer os.RemoveAll(dir)
+
+	ca, caKey := createTestCertificate(t, dir, "ca", "Test CA", nil, nil)
+	createTestCertificate(t, dir, "server", "localhost", ca, caKey)
+
+	tlsConfig := config.TLSConfig{
+		CertFile:       filepath.Join(dir, "server.crt"),
+		KeyFile:        filepath.Join(dir, "server.key"),
+		ClientAuth:     server.ClientAuthRequireAndVerify,
+		MinVersion:     "1.2",
+		ReloadInterval: time.Minute,
+	}
+
+	_, err := server.NewTLSConfig(tlsConfig)
+
+	assert.NotNil(t, err)
+
+	tlsConfig.ClientCAFile = filepath.Join(dir, "ca.crt")
+
+	res, err := server.NewTLSConfig(tlsConfig)
+
+	assert.Nil(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, res.ClientAuth)
+	assert.Equal(t, uint16(tls.VersionTLS12), res.MinVersion)
+
+	tlsConfig.KeyFile = filepath.Join(dir, "missing.key")
+
+	_, err = server.NewTLSConfig(tlsConfig)
+
+	assert.NotNil(t, err)
+}
+
+func TestServerCertificateReload(t *testing.T) {
+	dir := createConfigTestDir(t)
+
+	defer os.RemoveAll(dir)
+
+	ca, caKey := createTestCertificate(t, dir, "ca", "Test CA", nil, nil)
+	createTestCertificate(t, dir, "server", "first", ca, caKey)
+
+	certificateLoader, err := server.NewCertificateLoader(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), 0)
+
+	assert.Nil(t, err)
+
+	assertServedCertificate(t, certificateLoader, "first")
+
+	// Unchanged files are not loaded again
+
+	assertServedCertificate(t, certificateLoader, "first")
+
+	// Changed files are loaded
+
+	createTestCertificate(t, dir, "server", "second", ca, caKey)
+
+	future := time.Now().Add(time.Minute)
+
+	assert.Nil(t, os.Chtimes(filepath.Join(dir, "server.crt"), future, future))
+	assert.Nil(t, os.Chtimes(filepath.Join(dir, "server.key"), future, future))
+
+	assertServedCertificate(t, certificateLoader, "second")
+
+	// Invalid files keep the previous certificate
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "server.crt"), []byte("invalid"), 0600))
+
+	future = future.Add(time.Minute)
+
+	assert.Nil(t, os.Chtimes(filepath.Join(dir, "server.crt"), future, future))
+
+	assertServedCertificate(t, certificateLoader, "second")
+}
+
+func TestServerH2C(t *testing.T) {
+	appConfig, err := mock.NewDefaultConfigLoader().WithOverrides("server.h2c=true").Load()
+
+	assert.Nil(t, err)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	baseUrl, shutdown := startTestServer(t, appConfig.Server, mockApp.App.GetRouter())
+
+	defer shutdown()
+
+	client := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network string, addr string, cfg *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		},
+	}
+
+	response, err := client.Get(baseUrl + "/user_type")
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, 2, response.ProtoMajor)
+
+	response.Body.Close()
+}
+
+func TestServerSettings(t *testing.T) {
+	appConfig, err := mock.NewDefaultConfigLoader().
+		WithOverrides(
+			"server.host=127.0.0.1",
+			"server.port=9999",
+			"server.read_timeout=5s",
+			"server.write_timeout=1m",
+			"server.max_header_bytes=8192",
+		).
+		Load()
+
+	assert.Nil(t, err)
+
+	srv, err := server.NewHttpServer(appConfig.Server, http.NotFoundHandler())
+
+	assert.Nil(t, err)
+	assert.Equal(t, "127.0.0.1:9999", srv.Addr)
+	assert.Equal(t, 5*time.Second, srv.ReadTimeout)
+	assert.Equal(t, 10*time.Second, srv.ReadHeaderTimeout)
+	assert.Equal(t, time.Minute, srv.WriteTimeout)
+	assert.Equal(t, 2*time.Minute, srv.IdleTimeout)
+	assert.Equal(t, 8192, srv.MaxHeaderBytes)
+	assert.Nil(t, srv.TLSConfig)
+
+	_, err = mock.NewDefaultConfigLoader().WithOverrides("server.max_header_bytes=10").Load()
+
+	assert.NotNil(t, err)
+
+	_, err = mock.NewDefaultConfigLoader().WithOverrides("server.tls.client_auth=always").Load()
+
+	assert.NotNil(t, err)
+}
+
+func TestServerAdminRoutes(t *testing.T) {
+	// Without the admin listener, metrics and health are served by the main router, but pprof is never exposed there
+
+	mockApp := mock.NewMockApp(mock.NewDefaultConfig())
+
+	assert.Nil(t, mockApp.App.GetAdminRouter())
+
+	for uri, expectedStatus := range map[string]int{"/health": http.StatusOK, "/metrics": http.StatusOK, "/debug/pprof/": http.StatusNotFound} {
+		response, err := mockApp.NewGetRequest(uri, nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expectedStatus, response.Code, uri)
+	}
+
+	mockApp.App.ExecuteDbMigrationsDown()
+
+	// With the admin listener, they're served only by the admin router
+
+	appConfig, err := mock.NewDefaultConfigLoader().WithOverrides("server.admin.port=9091", "server.admin.pprof=true").Load()
+
+	assert.Nil(t, err)
+
+	mockApp = mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	adminRouter := mockApp.App.GetAdminRouter()
+
+	assert.NotNil(t, adminRouter)
+
+	for _, uri := range []string{"/health", "/metrics", "/debug/pprof/", "/debug/pprof/heap"} {
+		response, err := mockApp.NewGetRequest(uri, nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotFound, response.Code, uri)
+
+		request, err := http.NewRequest(http.MethodGet, uri, nil)
+
+		assert.Nil(t, err)
+
+		recorder := httptest.NewRecorder()
+
+		adminRouter.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code, uri)
+	}
+}
+
+func startTestServer(t *testing.T, serverConfig config.ServerConfig, handler http.Handler) (string, func()) {
+	srv, err := server.NewHttpServer(serverConfig, handler)
+
+	assert.Nil(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	assert.Nil(t, err)
+
+	scheme := "http"
+
+	if srv.TLSConfig != nil {
+		scheme = "https"
+
+		go srv.ServeTLS(listener, "", "")
+	} else {
+		go srv.Serve(listener)
+	}
+
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+
+	assert.Nil(t, err)
+
+	return scheme + "://localhost:" + port, func() {
+		srv.Close()
+	}
+}
+
+func assertServedCertificate(t *testing.T, certificateLoader *server.CertificateLoader, expectedCommonName string) {
+	certificate, err := certificateLoader.GetCertificate(nil)
+
+	assert.Nil(t, err)
+
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedCommonName, leaf.Subject.CommonName)
+}
+
+// createTestCertificate Creates a certificate (and its key) on the directory, signed by the given CA. Without a CA, a
+// self-signed CA certificate is created.
+func createTestCertificate(
+	t *testing.T,
+	dir string,
+	name string,
+	commonName string,
+	ca *x509.Certificate,
+	caKey *ecdsa.PrivateKey,
+) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	assert.Nil(t, err)
+
+	serialNumber, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+
+	assert.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{"localhost"},
+	}
+
+	if ca == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		template.ExtKeyUsage = nil
+		template.DNSNames = nil
+		ca = template
+		caKey = key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+
+	assert.Nil(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name+".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+
+	res, err := x509.ParseCertificate(der)
+
+	assert.Nil(t, err)
+
+	return res, key
+}
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Constants
+
+const (
+	StreamControllerSourceName = "StreamController"
+	LastEventIDHeader          = "Last-Event-ID"
+)
+
+// Structs
+
+type StreamController struct {
+	streamService         service.StreamService
+	requestContextFactory *context.RequestContextFactory
+	heartbeatInterval     time.Duration
+}
+
+// Stream Streams changes made to users and user types.
+// @Summary Streams changes made to users and user types.
+// @Description Opens a Server-Sent Events stream which receives a notification every time a user or a user type is created, updated, disabled or deleted. Only the changes of the entity types the caller has permission for are sent. Every notification carries its ID, so a client which reconnects sending the Last-Event-ID header receives the notifications it missed (as long as they are still in the replay buffer). A heartbeat comment is sent periodically to keep the connection alive.
+// @Produce text/event-stream
+// @Param entity_types query []string false "Entity types to receive. Allowed values: user, user_type. Default: all"
+// @Param last_event_id query int false "ID of the last notification received. The Last-Event-ID header takes precedence"
+// @Param Last-Event-ID header int false "ID of the last notification received"
+// @Success 200 {object} resource.ChangeNotificationResource
+// @Failure 400 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags stream
+// @Router /stream [get]
+func (ctrl *StreamController) Stream(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	var req resource.StreamSubscribeResource
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, StreamControllerSourceName, nil))
+
+		return
+	}
+
+	if lastEventID := c.GetHeader(LastEventIDHeader); lastEventID != "" {
+		ID, err := strconv.ParseInt(lastEventID, 10, 64)
+
+		if err != nil {
+			c.Error(apperror.NewBindingHttpError(requestContext, err, StreamControllerSourceName, nil))
+
+			return
+		}
+
+		req.LastEventID = ID
+	}
+
+	subscription, replay, err := ctrl.streamService.Subscribe(requestContext, &req)
+
+	if err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	defer ctrl.streamService.Unsubscribe(subscription)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	for _, notification := range replay {
+		if !ctrl.write(c, notification) {
+			return
+		}
+	}
+
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(ctrl.heartbeatInterval)
+
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case notification := <-subscription.Notifications():
+			if !ctrl.write(c, notification) {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+
+			c.Writer.Flush()
+		case <-subscription.Done():
+			return
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+func (ctrl *StreamController) write(c *gin.Context, notification *resource.ChangeNotificationResource) bool {
+	data, err := json.Marshal(notification)
+
+	if err != nil {
+		return false
+	}
+
+	if _, err := fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", notification.ID, notification.Event, data); err != nil {
+		return false
+	}
+
+	c.Writer.Flush()
+
+	return true
+}
+
+// Static functions
+
+func NewStreamController(
+	streamService service.StreamService,
+	requestContextFactory *context.RequestContextFactory,
+	heartbeatInterval time.Duration,
+) *StreamController {
+	return &StreamController{
+		streamService:         streamService,
+		requestContextFactory: requestContextFactory,
+		heartbeatInterval:     heartbeatInterval,
+	}
+}
@@ -0,0 +1,243 @@
+package controller_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/comfortablynumb/goginrestapi/internal/module"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamWithInvalidEntityType(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	res := &apperror.HttpError{}
+
+	response, err := mockApp.NewGetRequest("/stream?entity_types=unknown", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.True(t, res.HasErrorCountByNameAndType(1, "entity_types[0]", "oneof"))
+}
+
+func TestStreamReplaysMissedNotificationsAndStreamsNewOnes(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	server := httptest.NewServer(mockApp.App.GetRouter())
+
+	defer server.Close()
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	streamService, err := componentregistry.Get[service.StreamService](componentRegistry, module.StreamServiceComponentName)
+
+	assert.Nil(t, err)
+
+	CreateUserType(t, mockApp, "test-user-type-1")
+	CreateUser(t, mockApp, "test-user-1", "test-user-type-1")
+	CreateUserType(t, mockApp, "test-user-type-2")
+
+	_, err = componentRegistry.EventDispatcher.DispatchPending()
+
+	assert.Nil(t, err)
+
+	// The client already received the first notification, so only the user type ones published after it are replayed
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/stream?entity_types=user_type", nil)
+
+	assert.Nil(t, err)
+
+	req.Header.Set("Last-Event-ID", "1")
+
+	response, err := http.DefaultClient.Do(req)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	defer response.Body.Close()
+
+	reader := newStreamReader(response)
+	notification := reader.next(t)
+
+	assert.Equal(t, events.UserTypeCreatedEventName, notification.Event)
+	assert.Equal(t, "user_type", notification.EntityType)
+	assert.Equal(t, "test-user-type-2", notification.EntityKey)
+	assert.Equal(t, "created", notification.Action)
+
+	// New changes are streamed once they are committed and dispatched
+
+	response2, err := mockApp.NewPutRequest(
+		"/user_type/test-user-type-2",
+		mock.NewMockAppOptions().WithBody(resource.UserTypeUpdateResource{Name: "test-user-type-2", Disabled: true}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response2.Code)
+
+	_, err = componentRegistry.EventDispatcher.DispatchPending()
+
+	assert.Nil(t, err)
+
+	updated := reader.next(t)
+	disabled := reader.next(t)
+
+	assert.Equal(t, events.UserTypeUpdatedEventName, updated.Event)
+	assert.Equal(t, events.UserTypeDisabledEventName, disabled.Event)
+	assert.True(t, disabled.ID > updated.ID)
+	assert.True(t, updated.ID > notification.ID)
+
+	// Heartbeats keep the connection alive
+
+	time.Sleep(150 * time.Millisecond)
+
+	assert.True(t, atomic.LoadInt32(&reader.heartbeats) > 0)
+
+	// Streams are closed when the app shuts down
+
+	streamService.Close()
+
+	_, open := <-reader.notifications
+
+	assert.False(t, open)
+}
+
+func TestStreamIsFilteredByThePermissionsOfTheCaller(t *testing.T) {
+	appConfig, err := mock.NewDefaultConfigLoader().
+		WithOverrides("stream.permissions.admin=user_type,user", "stream.permissions.auditor=user_type", "stream.permissions.*=user").
+		Load()
+
+	assert.Nil(t, err)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	server := httptest.NewServer(mockApp.App.GetRouter())
+
+	defer server.Close()
+
+	CreateUserType(t, mockApp, "test-user-type-1")
+	CreateUser(t, mockApp, "test-user-1", "test-user-type-1")
+	CreateUserType(t, mockApp, "test-user-type-2")
+	CreateUser(t, mockApp, "test-user-2", "test-user-type-2")
+
+	_, err = mockApp.App.GetComponentRegistry().EventDispatcher.DispatchPending()
+
+	assert.Nil(t, err)
+
+	readers := make(map[string]*streamReader)
+
+	for actor, expectedKeys := range map[string][]string{
+		"admin":   {"test-user-1", "test-user-type-2", "test-user-2"},
+		"auditor": {"test-user-type-2"},
+		"":        {"test-user-1", "test-user-2"},
+	} {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/stream", nil)
+
+		assert.Nil(t, err)
+
+		req.Header.Set("Last-Event-ID", "1")
+		req.Header.Set("X-Actor", actor)
+
+		response, err := http.DefaultClient.Do(req)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		defer response.Body.Close()
+
+		readers[actor] = newStreamReader(response)
+
+		for _, expectedKey := range expectedKeys {
+			assert.Equal(t, expectedKey, readers[actor].next(t).EntityKey, actor)
+		}
+	}
+
+	// Changes published afterwards are filtered the same way
+
+	CreateUserType(t, mockApp, "test-user-type-3")
+
+	_, err = mockApp.App.GetComponentRegistry().EventDispatcher.DispatchPending()
+
+	assert.Nil(t, err)
+
+	assert.Equal(t, "test-user-type-3", readers["admin"].next(t).EntityKey)
+	assert.Equal(t, "test-user-type-3", readers["auditor"].next(t).EntityKey)
+
+	select {
+	case notification := <-readers[""].notifications:
+		t.Errorf("Unexpected notification: %v", notification)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// Helper types
+
+type streamReader struct {
+	notifications chan *resource.ChangeNotificationResource
+	heartbeats    int32
+}
+
+func (r *streamReader) next(t *testing.T) *resource.ChangeNotificationResource {
+	select {
+	case notification := <-r.notifications:
+		assert.NotNil(t, notification)
+
+		return notification
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a notification.")
+
+		return nil
+	}
+}
+
+func newStreamReader(response *http.Response) *streamReader {
+	reader := &streamReader{
+		notifications: make(chan *resource.ChangeNotificationResource, 100),
+	}
+
+	go func() {
+		defer close(reader.notifications)
+
+		scanner := bufio.NewScanner(response.Body)
+
+		for scanner.Scan() {
+			line := scanner.Text()
+
+			switch {
+			case strings.HasPrefix(line, ": heartbeat"):
+				atomic.AddInt32(&reader.heartbeats, 1)
+			case strings.HasPrefix(line, "data: "):
+				notification := &resource.ChangeNotificationResource{}
+
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), notification); err == nil {
+					reader.notifications <- notification
+				}
+			}
+		}
+	}()
+
+	return reader
+}
@@ -0,0 +1,715 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/middleware"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/module"
+	"github.com/comfortablynumb/goginrestapi/internal/repository"
+	"github.com/comfortablynumb/goginrestapi/internal/repository/utils"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantsAreIsolated(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	CreateTenant(t, mockApp, "acme")
+	CreateTenant(t, mockApp, "globex")
+
+	// The same names can be used on different tenants
+
+	for _, tenant := range []string{"acme", "globex"} {
+		response, err := mockApp.NewPostRequest(
+			"/user_type",
+			newTenantOptions(tenant).WithBody(resource.UserTypeCreateResource{Name: "staff"}),
+		)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusCreated, response.Code)
+
+		response, err = mockApp.NewPostRequest(
+			"/user",
+			newTenantOptions(tenant).WithBody(resource.UserCreateResource{Username: "jane", UserTypeName: "staff", Email: "jane@example.com"}),
+		)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusCreated, response.Code)
+	}
+
+	// But not twice on the same one
+
+	httpError := &apperror.HttpError{}
+
+	response, err := mockApp.NewPostRequest(
+		"/user",
+		newTenantOptions("acme").
+			WithBody(resource.UserCreateResource{Username: "jane", UserTypeName: "staff"}).
+			WithExpectedResponse(httpError),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.True(t, httpError.HasErrorCountByNameAndType(1, "username", "unique"))
+
+	httpError = &apperror.HttpError{}
+
+	response, err = mockApp.NewPostRequest(
+		"/user_type",
+		newTenantOptions("globex").WithBody(resource.UserTypeCreateResource{Name: "staff"}).WithExpectedResponse(httpError),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.True(t, httpError.HasErrorCountByNameAndType(1, "name", "unique"))
+
+	// Data of a tenant can't be seen, changed or used from another one
+
+	response, err = mockApp.NewPostRequest("/user_type", newTenantOptions("acme").WithBody(resource.UserTypeCreateResource{Name: "acme-only"}))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	response, err = mockApp.NewGetRequest("/user_type/acme-only", newTenantOptions("globex"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response, err = mockApp.NewPutRequest(
+		"/user_type/acme-only",
+		newTenantOptions("globex").WithBody(resource.UserTypeUpdateResource{Name: "acme-only", Disabled: true}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	httpError = &apperror.HttpError{}
+
+	response, err = mockApp.NewPostRequest(
+		"/user",
+		newTenantOptions("globex").
+			WithBody(resource.UserCreateResource{Username: "john", UserTypeName: "acme-only"}).
+			WithExpectedResponse(httpError),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.True(t, httpError.HasErrorCountByNameAndType(1, "user_type_name", "user_type"))
+
+	userType := &resource.UserTypeResource{}
+
+	response, err = mockApp.NewGetRequest("/user_type/acme-only", newTenantOptions("acme").WithExpectedResponse(userType))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.False(t, userType.Disabled)
+
+	// Lists (and their cached responses) only have the data of the tenant
+
+	for _, tenant := range []string{"acme", "globex", "acme", "globex"} {
+		users := make([]*resource.UserResource, 0)
+
+		response, err = mockApp.NewGetRequest("/user", newTenantOptions(tenant).WithExpectedResponse(&users))
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Len(t, users, 1)
+
+		userTypes := &resource.UserTypeResourceList{}
+
+		response, err = mockApp.NewGetRequest("/user_type", newTenantOptions(tenant).WithExpectedResponse(userTypes))
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.Code)
+
+		if tenant == "acme" {
+			assert.Equal(t, int64(2), userTypes.TotalCount)
+		} else {
+			assert.Equal(t, int64(1), userTypes.TotalCount)
+		}
+	}
+
+	// Requests without tenant belong to the default one, which has nothing
+
+	users := make([]*resource.UserResource, 0)
+
+	response, err = mockApp.NewGetRequest("/user", mock.NewMockAppOptions().WithExpectedResponse(&users))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Len(t, users, 0)
+
+	// Deleting a user on a tenant keeps the one with the same username on the other
+
+	response, err = mockApp.NewDeleteRequest("/user/jane", newTenantOptions("globex"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	response, err = mockApp.NewGetRequest("/user?username=jane", newTenantOptions("acme").WithExpectedResponse(&users))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Len(t, users, 1)
+
+	// So is their history
+
+	history := &resource.AuditEventResourceList{}
+
+	response, err = mockApp.NewGetRequest("/user/jane/history", newTenantOptions("acme").WithExpectedResponse(history))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, int64(1), history.TotalCount)
+}
+
+func TestTenantEventsOnlyReachTheirTenant(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	server := httptest.NewServer(mockApp.App.GetRouter())
+
+	defer server.Close()
+
+	CreateTenant(t, mockApp, "acme")
+	CreateTenant(t, mockApp, "globex")
+
+	receivers := make(map[string]*webhookReceiver)
+
+	for _, tenant := range []string{"acme", "globex"} {
+		receivers[tenant] = newWebhookReceiver(http.StatusOK)
+
+		defer receivers[tenant].Close()
+
+		response, err := mockApp.NewPostRequest("/webhooks", newTenantOptions(tenant).WithBody(resource.WebhookCreateResource{
+			URL:        receivers[tenant].URL,
+			EventTypes: []string{model.WebhookAllEventTypes},
+			Secret:     webhookTestSecret,
+		}))
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusCreated, response.Code)
+	}
+
+	// Subscriptions can't be seen from other tenants
+
+	webhooks := &resource.WebhookResourceList{}
+
+	response, err := mockApp.NewGetRequest("/webhooks", newTenantOptions("globex").WithExpectedResponse(webhooks))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, int64(1), webhooks.TotalCount)
+
+	response, err = mockApp.NewGetRequest(fmt.Sprintf("/webhooks/%d", webhooks.Data[0].ID), newTenantOptions("acme"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	// The replay buffer is shared by every tenant, but only the notifications of the tenant of the caller are replayed
+
+	CreateUserType(t, mockApp, "default-staff")
+
+	for _, tenant := range []string{"acme", "globex"} {
+		response, err := mockApp.NewPostRequest("/user_type", newTenantOptions(tenant).WithBody(resource.UserTypeCreateResource{Name: tenant + "-staff"}))
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusCreated, response.Code)
+	}
+
+	delivered := deliverWebhooks(t, mockApp)
+
+	assert.Equal(t, 2, delivered)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/stream", nil)
+
+	assert.Nil(t, err)
+
+	req.Header.Set("Last-Event-ID", "1")
+	req.Header.Set("X-Tenant", "globex")
+
+	streamResponse, err := http.DefaultClient.Do(req)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, streamResponse.StatusCode)
+
+	defer streamResponse.Body.Close()
+
+	reader := newStreamReader(streamResponse)
+
+	assert.Equal(t, "globex-staff", reader.next(t).EntityKey)
+
+	// So are the new ones
+
+	for _, tenant := range []string{"acme", "globex"} {
+		response, err := mockApp.NewPostRequest("/user_type", newTenantOptions(tenant).WithBody(resource.UserTypeCreateResource{Name: tenant + "-admin"}))
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusCreated, response.Code)
+	}
+
+	delivered = deliverWebhooks(t, mockApp)
+
+	assert.Equal(t, 2, delivered)
+	assert.Equal(t, "globex-admin", reader.next(t).EntityKey)
+
+	select {
+	case notification := <-reader.notifications:
+		t.Errorf("Unexpected notification: %v", notification)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Webhooks only receive the events of their tenant
+
+	for tenant, receiver := range receivers {
+		assert.Equal(t, 2, len(receiver.requests), tenant)
+
+		for _, request := range receiver.requests {
+			envelope := &events.Envelope{}
+
+			assert.Nil(t, json.Unmarshal(request.body, envelope))
+			assert.Equal(t, tenant, envelope.Tenant)
+		}
+	}
+}
+
+func TestTenantResolution(t *testing.T) {
+	appConfig, err := mock.NewDefaultConfigLoader().
+		WithOverrides("tenancy.sources=token,header,subdomain", "tenancy.base_domain=example.com").
+		Load()
+
+	assert.Nil(t, err)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+
+	CreateTenant(t, mockApp, "acme")
+	CreateTenant(t, mockApp, "globex")
+
+	response, err := mockApp.NewPostRequest("/user_type", newTenantOptions("acme").WithBody(resource.UserTypeCreateResource{Name: "staff"}))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	// Every source resolves the tenant
+
+	tenantToken, appErr := componentRegistry.TenantService.IssueToken(componentRegistry.RequestContextFactory.NewBackgroundRequestContext(), "acme", time.Hour)
+
+	assert.Nil(t, appErr)
+
+	for _, options := range []*mock.MockAppOptions{
+		newTenantOptions("acme"),
+		mock.NewMockAppOptions().WithHeader("Authorization", "Bearer "+tenantToken),
+		mock.NewMockAppOptions(),
+	} {
+		response, err = mockApp.NewGetRequest("http://acme.example.com:8080/user_type/staff", options)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.Code)
+
+		// Without subdomain, the request belongs to the default tenant unless another source is present
+
+		response, err = mockApp.NewGetRequest("http://example.com/user_type/staff", options)
+
+		assert.Nil(t, err)
+
+		if len(options.Headers) > 0 {
+			assert.Equal(t, http.StatusOK, response.Code)
+		} else {
+			assert.Equal(t, http.StatusNotFound, response.Code)
+		}
+	}
+
+	// Sources resolving different tenants, unknown or disabled tenants and invalid tokens are rejected
+
+	_, appErr = componentRegistry.TenantService.Update(
+		componentRegistry.RequestContextFactory.NewBackgroundRequestContext(),
+		&resource.TenantUpdateResource{Name: "globex", Disabled: true},
+	)
+
+	assert.Nil(t, appErr)
+
+	for uri, options := range map[string]*mock.MockAppOptions{
+		"http://acme.example.com/user_type/staff":   newTenantOptions("globex"),
+		"http://globex.example.com/user_type/staff": newTenantOptions("acme"),
+		"/user_type/staff?conflict=token":           newTenantOptions("globex").WithHeader("Authorization", "Bearer "+tenantToken),
+		"/user_type/staff?unknown":                  newTenantOptions("initech"),
+		"/user_type/staff?disabled":                 newTenantOptions("globex"),
+		"/user_type/staff?token":                    mock.NewMockAppOptions().WithHeader("Authorization", "Bearer "+tenantToken+"x"),
+	} {
+		httpError := &apperror.HttpError{}
+
+		response, err = mockApp.NewGetRequest(uri, options.WithExpectedResponse(httpError))
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, response.Code, uri)
+		assert.Equal(t, apperror.InvalidTenantErrorCode, httpError.Code, uri)
+	}
+
+	// Tenant tokens can't be issued for disabled tenants
+
+	_, appErr = componentRegistry.TenantService.IssueToken(componentRegistry.RequestContextFactory.NewBackgroundRequestContext(), "globex", time.Hour)
+
+	assert.NotNil(t, appErr)
+	assert.Equal(t, apperror.InvalidTenantErrorCode, appErr.Code)
+}
+
+func TestTenantHeaderIsIgnoredByDefault(t *testing.T) {
+	defaultConfig, err := config.NewLoader().WithEnviron([]string{}).Load()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{middleware.TenantSourceToken}, defaultConfig.Tenancy.Sources)
+
+	appConfig, err := mock.NewDefaultConfigLoader().WithOverrides("tenancy.sources=token").Load()
+
+	assert.Nil(t, err)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+
+	CreateTenant(t, mockApp, "acme")
+
+	tenantToken, appErr := componentRegistry.TenantService.IssueToken(componentRegistry.RequestContextFactory.NewBackgroundRequestContext(), "acme", time.Hour)
+
+	assert.Nil(t, appErr)
+
+	response, err := mockApp.NewPostRequest(
+		"/user_type",
+		mock.NewMockAppOptions().WithHeader("Authorization", "Bearer "+tenantToken).WithBody(resource.UserTypeCreateResource{Name: "staff"}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	// Anyone could send the header, so it doesn't give access to the tenant
+
+	response, err = mockApp.NewGetRequest("/user_type/staff", newTenantOptions("acme"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestTenantIsRequiredWithoutDefaultTenant(t *testing.T) {
+	appConfig, err := mock.NewDefaultConfigLoader().WithOverrides("tenancy.default_tenant=").Load()
+
+	assert.Nil(t, err)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	httpError := &apperror.HttpError{}
+
+	response, err := mockApp.NewGetRequest("/user_type", mock.NewMockAppOptions().WithExpectedResponse(httpError))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, apperror.InvalidTenantErrorCode, httpError.Code)
+
+	response, err = mockApp.NewGetRequest("/user_type", newTenantOptions(context.DefaultTenant))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	// Routes which don't belong to any tenant still work
+
+	response, err = mockApp.NewGetRequest("/errors", nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+}
+
+func TestTenantCreationValidation(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	ctx := componentRegistry.RequestContextFactory.NewBackgroundRequestContext()
+
+	for _, name := range []string{"", "Acme", "acme.corp", "acme_corp", "-acme", context.DefaultTenant} {
+		_, appErr := componentRegistry.TenantService.Create(ctx, &resource.TenantCreateResource{Name: name})
+
+		assert.NotNil(t, appErr, name)
+		assert.Equal(t, apperror.ValidationErrorCode, appErr.Code, name)
+	}
+
+	tenant, appErr := componentRegistry.TenantService.Create(ctx, &resource.TenantCreateResource{Name: "acme-corp"})
+
+	assert.Nil(t, appErr)
+	assert.Equal(t, "acme-corp", tenant.Name)
+
+	tenants, appErr := componentRegistry.TenantService.Find(ctx)
+
+	assert.Nil(t, appErr)
+	assert.Len(t, tenants, 2)
+}
+
+// REPOSITORY TESTS
+
+func TestTenantScopedRepositoriesCantReachOtherTenants(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	appConfig := *mockApp.App.GetConfig()
+	acme := newTenantContext(t, mockApp, "acme")
+	globex := newTenantContext(t, mockApp, "globex")
+	now := componentRegistry.TimeService.GetCurrentUtcTime()
+
+	userTypeRepository := repository.NewUserTypeRepository(appConfig, componentRegistry.Db, componentRegistry.Logger)
+	userRepository := repository.NewUserRepository(appConfig, componentRegistry.Db, componentRegistry.Logger)
+	passwordResetTokenRepository := repository.NewPasswordResetTokenRepository(appConfig, componentRegistry.Db, componentRegistry.Logger)
+	recoveryCodeRepository := repository.NewRecoveryCodeRepository(appConfig, componentRegistry.Db, componentRegistry.Logger)
+	auditEventRepository := repository.NewAuditEventRepository(appConfig, componentRegistry.Db, componentRegistry.Logger)
+
+	// User types
+
+	acmeUserType := model.NewUserTypeBuilder().WithName("staff").WithCreatedAt(now).WithUpdatedAt(now).Build()
+	globexUserType := model.NewUserTypeBuilder().WithName("staff").WithCreatedAt(now).WithUpdatedAt(now).Build()
+
+	assert.Nil(t, userTypeRepository.Create(acme, acmeUserType))
+	assert.Nil(t, userTypeRepository.Create(globex, globexUserType))
+	assert.Equal(t, acme.GetTenantID(), acmeUserType.TenantID)
+	assert.Equal(t, globex.GetTenantID(), globexUserType.TenantID)
+
+	found, appErr := userTypeRepository.FindOneByName(globex, "staff")
+
+	assert.Nil(t, appErr)
+	assert.Equal(t, globexUserType.ID, found.ID)
+
+	count, appErr := userTypeRepository.Count(globex, utils.NewUserTypeFindFilters(), utils.NewUserTypeFindOptions())
+
+	assert.Nil(t, appErr)
+	assert.Equal(t, int64(1), count)
+
+	acmeUserType.Disabled = true
+
+	appErr = userTypeRepository.Update(globex, acmeUserType)
+
+	assert.NotNil(t, appErr)
+	assert.Equal(t, apperror.ModelNotFoundErrorCode, appErr.Code)
+
+	appErr = userTypeRepository.Delete(globex, acmeUserType)
+
+	assert.NotNil(t, appErr)
+	assert.Equal(t, apperror.ModelNotFoundErrorCode, appErr.Code)
+
+	found, appErr = userTypeRepository.FindOneByName(acme, "staff")
+
+	assert.Nil(t, appErr)
+	assert.Equal(t, acmeUserType.ID, found.ID)
+	assert.False(t, found.Disabled)
+
+	// Users
+
+	acmeUser := model.NewUserBuilder().WithUsername("jane").WithUserType(*acmeUserType).WithCreatedAt(now).WithUpdatedAt(now).Build()
+	crossTenantUser := model.NewUserBuilder().WithUsername("jane").WithUserType(*acmeUserType).WithCreatedAt(now).WithUpdatedAt(now).Build()
+
+	assert.Nil(t, userRepository.Create(acme, acmeUser))
+	assert.Equal(t, acme.GetTenantID(), acmeUser.TenantID)
+
+	appErr = userRepository.Create(globex, crossTenantUser)
+
+	assert.NotNil(t, appErr)
+	assert.Equal(t, apperror.ModelNotFoundErrorCode, appErr.Code)
+
+	foundUser, appErr := userRepository.FindOneByID(globex, acmeUser.ID)
+
+	assert.Nil(t, appErr)
+	assert.Nil(t, foundUser)
+
+	foundUser, appErr = userRepository.FindOneByUsername(globex, "jane")
+
+	assert.Nil(t, appErr)
+	assert.Nil(t, foundUser)
+
+	acmeUser.Disabled = true
+
+	appErr = userRepository.Update(globex, acmeUser)
+
+	assert.NotNil(t, appErr)
+	assert.Equal(t, apperror.ModelNotFoundErrorCode, appErr.Code)
+
+	// Users can't be moved to a user type of another tenant either
+
+	acmeUser.Disabled = false
+	acmeUser.UserType = *globexUserType
+
+	appErr = userRepository.Update(acme, acmeUser)
+
+	assert.NotNil(t, appErr)
+	assert.Equal(t, apperror.ModelNotFoundErrorCode, appErr.Code)
+
+	acmeUser.UserType = *acmeUserType
+
+	// Tokens and codes of the users
+
+	resetToken := &model.PasswordResetToken{UserID: acmeUser.ID, TokenHash: "hash", ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+
+	appErr = passwordResetTokenRepository.Create(globex, resetToken)
+
+	assert.NotNil(t, appErr)
+	assert.Equal(t, apperror.ModelNotFoundErrorCode, appErr.Code)
+	assert.Nil(t, passwordResetTokenRepository.Create(acme, resetToken))
+
+	foundToken, appErr := passwordResetTokenRepository.FindOneByTokenHash(globex, "hash")
+
+	assert.Nil(t, appErr)
+	assert.Nil(t, foundToken)
+
+	recoveryCode := &model.RecoveryCode{UserID: acmeUser.ID, CodeHash: "hash", CreatedAt: now}
+
+	appErr = recoveryCodeRepository.Create(globex, recoveryCode)
+
+	assert.NotNil(t, appErr)
+	assert.Equal(t, apperror.ModelNotFoundErrorCode, appErr.Code)
+	assert.Nil(t, recoveryCodeRepository.Create(acme, recoveryCode))
+
+	marked, appErr := recoveryCodeRepository.MarkUsed(globex, recoveryCode, now)
+
+	assert.Nil(t, appErr)
+	assert.False(t, marked)
+	assert.Nil(t, recoveryCodeRepository.DeleteByUserID(globex, acmeUser.ID))
+
+	codes, appErr := recoveryCodeRepository.CountUnusedByUserID(acme, acmeUser.ID)
+
+	assert.Nil(t, appErr)
+	assert.Equal(t, int64(1), codes)
+
+	codes, appErr = recoveryCodeRepository.CountUnusedByUserID(globex, acmeUser.ID)
+
+	assert.Nil(t, appErr)
+	assert.Equal(t, int64(0), codes)
+
+	// Deleting a user from another tenant doesn't delete anything
+
+	appErr = userRepository.Delete(globex, acmeUser)
+
+	assert.NotNil(t, appErr)
+	assert.Equal(t, apperror.ModelNotFoundErrorCode, appErr.Code)
+
+	foundToken, appErr = passwordResetTokenRepository.FindOneByTokenHash(acme, "hash")
+
+	assert.Nil(t, appErr)
+	assert.NotNil(t, foundToken)
+
+	foundUser, appErr = userRepository.FindOneByID(acme, acmeUser.ID)
+
+	assert.Nil(t, appErr)
+	assert.NotNil(t, foundUser)
+	assert.Equal(t, acme.GetTenantID(), foundUser.TenantID)
+	assert.Equal(t, acme.GetTenantID(), foundUser.UserType.TenantID)
+
+	assert.Nil(t, userRepository.Delete(acme, acmeUser))
+
+	// Audit events
+
+	auditEvent := model.NewAuditEventBuilder().WithEntityType(model.AuditEntityTypeUser).WithEntityKey("jane").WithAction(model.AuditActionCreate).WithCreatedAt(now).Build()
+
+	assert.Nil(t, auditEventRepository.Create(acme, auditEvent))
+
+	for ctx, expected := range map[*context.RequestContext]int64{acme: 1, globex: 0} {
+		count, appErr = auditEventRepository.Count(ctx, utils.NewAuditEventFindFilters(), utils.NewAuditEventFindOptions())
+
+		assert.Nil(t, appErr)
+		assert.Equal(t, expected, count)
+	}
+}
+
+func TestTenantCachedUserTypesAreNotShared(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	acme := newTenantContext(t, mockApp, "acme")
+	globex := newTenantContext(t, mockApp, "globex")
+	now := componentRegistry.TimeService.GetCurrentUtcTime()
+
+	userTypeRepository, err := componentregistry.Get[repository.UserTypeRepository](componentRegistry, module.UserTypeRepositoryComponentName)
+
+	assert.Nil(t, err)
+
+	acmeUserType := model.NewUserTypeBuilder().WithName("staff").WithCreatedAt(now).WithUpdatedAt(now).Build()
+
+	assert.Nil(t, userTypeRepository.Create(acme, acmeUserType))
+
+	found, appErr := userTypeRepository.FindOneByName(acme, "staff")
+
+	assert.Nil(t, appErr)
+	assert.Equal(t, acmeUserType.ID, found.ID)
+
+	found, appErr = userTypeRepository.FindOneByName(globex, "staff")
+
+	assert.Nil(t, appErr)
+	assert.Nil(t, found)
+}
+
+func CreateTenant(t *testing.T, mockApp *mock.MockApp, name string) *resource.TenantResource {
+	componentRegistry := mockApp.App.GetComponentRegistry()
+
+	tenant, appErr := componentRegistry.TenantService.Create(
+		componentRegistry.RequestContextFactory.NewBackgroundRequestContext(),
+		&resource.TenantCreateResource{Name: name},
+	)
+
+	assert.Nil(t, appErr)
+
+	return tenant
+}
+
+func newTenantOptions(tenant string) *mock.MockAppOptions {
+	return mock.NewMockAppOptions().WithHeader("X-Tenant", tenant)
+}
+
+func newTenantContext(t *testing.T, mockApp *mock.MockApp, name string) *context.RequestContext {
+	CreateTenant(t, mockApp, name)
+
+	componentRegistry := mockApp.App.GetComponentRegistry()
+	ctx := componentRegistry.RequestContextFactory.NewBackgroundRequestContext()
+
+	tenant, appErr := componentRegistry.TenantService.Resolve(ctx, name)
+
+	assert.Nil(t, appErr)
+
+	return ctx.SetTenant(tenant.ID, tenant.Name)
+}
@@ -0,0 +1,129 @@
+package controller_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeTimeServiceTimers(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := mock.NewFakeTimeService(start)
+
+	timer := clock.NewTimer(time.Minute)
+
+	assert.Equal(t, 1, clock.GetTimerCount())
+
+	clock.Advance(59 * time.Second)
+
+	assertNotFired(t, timer.C())
+
+	clock.Advance(2 * time.Second)
+
+	assert.Equal(t, start.Add(time.Minute), assertFired(t, timer.C()))
+	assert.Equal(t, start.Add(61*time.Second), clock.GetCurrentUtcTime())
+	assert.Equal(t, 0, clock.GetTimerCount())
+	assert.False(t, timer.Stop())
+
+	// Reset timers fire again, stopped ones don't
+
+	assert.False(t, timer.Reset(time.Minute))
+	assert.True(t, timer.Stop())
+
+	clock.Advance(time.Hour)
+
+	assertNotFired(t, timer.C())
+
+	// Timers which are already due fire right away
+
+	timer = clock.NewTimer(0)
+
+	assert.Equal(t, clock.GetCurrentUtcTime(), assertFired(t, timer.C()))
+}
+
+func TestFakeTimeServiceTickers(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := mock.NewFakeTimeService(start)
+
+	ticker := clock.NewTicker(10 * time.Second)
+	timer := clock.NewTimer(15 * time.Second)
+
+	clock.Advance(10 * time.Second)
+
+	assert.Equal(t, start.Add(10*time.Second), assertFired(t, ticker.C()))
+	assertNotFired(t, timer.C())
+
+	// Ticks which aren't received are dropped, like time.Ticker does
+
+	clock.Set(start.Add(time.Minute))
+
+	assert.Equal(t, start.Add(20*time.Second), assertFired(t, ticker.C()))
+	assertNotFired(t, ticker.C())
+	assert.Equal(t, start.Add(15*time.Second), assertFired(t, timer.C()))
+	assert.Equal(t, 1, clock.GetTimerCount())
+
+	ticker.Reset(time.Minute)
+
+	clock.Advance(30 * time.Second)
+
+	assertNotFired(t, ticker.C())
+
+	clock.Advance(30 * time.Second)
+
+	assert.Equal(t, start.Add(2*time.Minute), assertFired(t, ticker.C()))
+
+	ticker.Stop()
+
+	assert.Equal(t, 0, clock.GetTimerCount())
+
+	clock.Advance(time.Hour)
+
+	assertNotFired(t, ticker.C())
+}
+
+func TestFakeTimeServiceWaitForTimers(t *testing.T) {
+	clock := mock.NewFakeTimeService(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	ticks := make(chan time.Time)
+
+	go func() {
+		ticker := clock.NewTicker(time.Second)
+
+		defer ticker.Stop()
+
+		ticks <- <-ticker.C()
+	}()
+
+	assert.True(t, clock.WaitForTimers(1, 5*time.Second))
+
+	clock.Advance(time.Second)
+
+	select {
+	case tick := <-ticks:
+		assert.Equal(t, clock.GetCurrentUtcTime(), tick)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the ticker did NOT fire")
+	}
+
+	assert.False(t, clock.WaitForTimers(2, 50*time.Millisecond))
+}
+
+func assertFired(t *testing.T, channel <-chan time.Time) time.Time {
+	select {
+	case now := <-channel:
+		return now
+	default:
+		t.Error("expected the timer to fire")
+
+		return time.Time{}
+	}
+}
+
+func assertNotFired(t *testing.T, channel <-chan time.Time) {
+	select {
+	case now := <-channel:
+		t.Errorf("expected the timer NOT to fire, but it fired at %s", now)
+	default:
+	}
+}
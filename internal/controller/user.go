@@ -64,8 +64,11 @@ func (ctrl *UserController) Find(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param user body resource.UserCreateResource true "User data"
+// @Param Idempotency-Key header string false "Makes the request safe to retry. The first response is replayed on retries with the same key"
 // @Success 201 {object} resource.UserResource
 // @Failure 400 {object} apperror.HttpError
+// @Failure 409 {object} apperror.HttpError
+// @Failure 422 {object} apperror.HttpError
 // @Failure 500 {object} apperror.HttpError
 // @Tags users
 // @Router /user [post]
@@ -165,6 +168,166 @@ func (ctrl *UserController) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, userResource)
 }
 
+// History Returns the audit history of a user.
+// @Summary Returns the audit history of a user.
+// @Description Allows you to see every change made to a user, who made it and when.
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} resource.AuditEventResourceList
+// @Failure 400 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags users
+// @Router /user/{username}/history [get]
+func (ctrl *UserController) History(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	auditEventResourceList, err := ctrl.userService.History(requestContext, c.Param("username"))
+
+	if err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, auditEventResourceList)
+}
+
+// VerifyEmail Verifies the email of a user.
+// @Summary Verifies the email of a user.
+// @Description Marks the email of the user as verified, using the token sent to it. Tokens expire, and are no longer valid once the email of the user changes.
+// @Accept json
+// @Produce json
+// @Param username path string true "Username"
+// @Param token body resource.UserVerifyEmailResource true "Verification token"
+// @Success 200 {object} resource.UserResource
+// @Failure 400 {object} apperror.HttpError
+// @Failure 404 {object} apperror.HttpError
+// @Failure 422 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags users
+// @Router /user/{username}/verify-email [post]
+func (ctrl *UserController) VerifyEmail(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	var req resource.UserVerifyEmailResource
+
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, UserControllerSourceName, nil))
+
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, UserControllerSourceName, nil))
+
+		return
+	}
+
+	userResource, err := ctrl.userService.VerifyEmail(requestContext, &req)
+
+	if err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, userResource)
+}
+
+// SendVerificationEmail Sends a new verification email to a user.
+// @Summary Sends a new verification email to a user.
+// @Description Sends a new verification token to the email of the user. Nothing is sent if the user has no email, or if it's already verified.
+// @Produce json
+// @Param username path string true "Username"
+// @Success 202 {object} resource.UserResource
+// @Failure 400 {object} apperror.HttpError
+// @Failure 404 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags users
+// @Router /user/{username}/verification-email [post]
+func (ctrl *UserController) SendVerificationEmail(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	var req resource.UserVerificationEmailResource
+
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, UserControllerSourceName, nil))
+
+		return
+	}
+
+	userResource, err := ctrl.userService.SendVerificationEmail(requestContext, &req)
+
+	if err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	c.JSON(http.StatusAccepted, userResource)
+}
+
+// Unlock Unlocks a user locked out by failed logins.
+// @Summary Unlocks a user locked out by failed logins.
+// @Description Ends the lockout of the user and resets its failed login counter, so it can log in right away.
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} resource.UserResource
+// @Failure 400 {object} apperror.HttpError
+// @Failure 404 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags users
+// @Router /user/{username}/unlock [post]
+func (ctrl *UserController) Unlock(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	var req resource.UserUnlockResource
+
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, UserControllerSourceName, nil))
+
+		return
+	}
+
+	userResource, err := ctrl.userService.Unlock(requestContext, &req)
+
+	if err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, userResource)
+}
+
+// DisableMfa Disables the second factor of a user.
+// @Summary Disables the second factor of a user.
+// @Description Disables the TOTP second factor of the user and its recovery codes, for instance if the user lost them. If the type of the user requires a second factor, the user must enroll a new one before logging in again.
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} resource.UserResource
+// @Failure 400 {object} apperror.HttpError
+// @Failure 404 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags users
+// @Router /user/{username}/mfa [delete]
+func (ctrl *UserController) DisableMfa(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	var req resource.UserDisableMfaResource
+
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, UserControllerSourceName, nil))
+
+		return
+	}
+
+	userResource, err := ctrl.userService.DisableMfa(requestContext, &req)
+
+	if err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, userResource)
+}
+
 // Static functions
 
 func NewUserController(userService service.UserService, requestContextFactory *context.RequestContextFactory) *UserController {
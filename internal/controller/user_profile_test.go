@@ -0,0 +1,377 @@
+package controller_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/comfortablynumb/goginrestapi/internal/app"
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/hooks"
+	"github.com/comfortablynumb/goginrestapi/internal/mailer"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserProfile(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+
+	// Locale and timezone have defaults
+
+	res := &resource.UserResource{}
+
+	response, err := mockApp.NewPostRequest(
+		"/user",
+		mock.NewMockAppOptions().
+			WithBody(resource.UserCreateResource{
+				Username:     "test-user-1",
+				UserTypeName: userTypeReq.Name,
+				Email:        "Jane@Example.com",
+				DisplayName:  "Jane Doe",
+			}).
+			WithExpectedResponse(res),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.Equal(t, "jane@example.com", res.Email)
+	assert.Equal(t, "Jane Doe", res.DisplayName)
+	assert.Equal(t, "en", res.Locale)
+	assert.Equal(t, "UTC", res.Timezone)
+	assert.False(t, res.EmailVerified)
+	assert.Nil(t, res.EmailVerifiedAt)
+
+	// Invalid fields
+
+	httpError := &apperror.HttpError{}
+
+	response, err = mockApp.NewPostRequest(
+		"/user",
+		mock.NewMockAppOptions().
+			WithBody(resource.UserCreateResource{
+				Username:     "test-user-2",
+				UserTypeName: userTypeReq.Name,
+				Email:        "not-an-email",
+				Locale:       "fr",
+				Timezone:     "Mars/Olympus_Mons",
+			}).
+			WithExpectedResponse(httpError),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.True(t, httpError.HasErrorCountByNameAndType(1, "email", "email"))
+	assert.True(t, httpError.HasErrorCountByNameAndType(1, "locale", "locale"))
+	assert.True(t, httpError.HasErrorCountByNameAndType(1, "timezone", "timezone"))
+
+	// Emails are unique, regardless of their case
+
+	httpError = &apperror.HttpError{}
+
+	response, err = mockApp.NewPostRequest(
+		"/user",
+		mock.NewMockAppOptions().
+			WithHeader("Accept-Language", "es").
+			WithBody(resource.UserCreateResource{Username: "test-user-2", UserTypeName: userTypeReq.Name, Email: "JANE@example.com"}).
+			WithExpectedResponse(httpError),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.True(t, httpError.HasErrorCountByNameAndType(1, "email", "unique"))
+	assert.Equal(t, "email ya está en uso", httpError.GetErrors()[0].Message)
+
+	// Users can keep their own email
+
+	res = &resource.UserResource{}
+
+	response, err = mockApp.NewPutRequest(
+		"/user/test-user-1",
+		mock.NewMockAppOptions().
+			WithBody(resource.UserUpdateResource{
+				UserTypeName: userTypeReq.Name,
+				Email:        "jane@example.com",
+				DisplayName:  "Jane",
+				Locale:       "es",
+				Timezone:     "America/Argentina/Buenos_Aires",
+			}).
+			WithExpectedResponse(res),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "jane@example.com", res.Email)
+	assert.Equal(t, "Jane", res.DisplayName)
+	assert.Equal(t, "es", res.Locale)
+	assert.Equal(t, "America/Argentina/Buenos_Aires", res.Timezone)
+
+	// Users without email don't collide
+
+	CreateUser(t, mockApp, "test-user-3", userTypeReq.Name)
+	CreateUser(t, mockApp, "test-user-4", userTypeReq.Name)
+}
+
+func TestUserEmailVerification(t *testing.T) {
+	memoryMailer := mailer.NewMemoryMailer()
+	mockApp := mock.NewMockApp(mock.NewDefaultConfig(), withMailer(memoryMailer))
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+
+	response, err := mockApp.NewPostRequest(
+		"/user",
+		mock.NewMockAppOptions().WithBody(resource.UserCreateResource{
+			Username:     "test-user-1",
+			UserTypeName: userTypeReq.Name,
+			Email:        "jane@example.com",
+			DisplayName:  "Jane",
+			Locale:       "es",
+			Timezone:     "Europe/Madrid",
+		}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	// The email is written in the locale of the user
+
+	message := memoryMailer.GetLastMessageTo("jane@example.com")
+
+	assert.NotNil(t, message)
+	assert.Equal(t, "no-reply@example.com", message.From)
+	assert.Equal(t, "Verifique su email", message.Subject)
+	assert.Contains(t, message.Body, "Hola Jane")
+	assert.Regexp(t, `Expira el \d{4}-\d{2}-\d{2} \d{2}:\d{2} CES?T\.`, message.Body)
+
+	firstToken := getVerificationToken(t, message)
+
+	// Invalid tokens are rejected
+
+	for _, invalidToken := range []string{"invalid", "1.invalid", firstToken + "x"} {
+		httpError := &apperror.HttpError{}
+
+		response, err = mockApp.NewPostRequest(
+			"/user/test-user-1/verify-email",
+			mock.NewMockAppOptions().WithBody(resource.UserVerifyEmailResource{Token: invalidToken}).WithExpectedResponse(httpError),
+		)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, response.Code, invalidToken)
+		assert.Equal(t, apperror.InvalidTokenErrorCode, httpError.Code)
+	}
+
+	// And so are the tokens of other users
+
+	CreateUser(t, mockApp, "test-user-2", userTypeReq.Name)
+
+	response, err = mockApp.NewPostRequest(
+		"/user/test-user-2/verify-email",
+		mock.NewMockAppOptions().WithBody(resource.UserVerifyEmailResource{Token: firstToken}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+
+	// A change of email resets the verification, and invalidates the previous tokens
+
+	response, err = mockApp.NewPutRequest(
+		"/user/test-user-1",
+		mock.NewMockAppOptions().WithBody(resource.UserUpdateResource{UserTypeName: userTypeReq.Name, Email: "jane.doe@example.com"}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	response, err = mockApp.NewPostRequest(
+		"/user/test-user-1/verify-email",
+		mock.NewMockAppOptions().WithBody(resource.UserVerifyEmailResource{Token: firstToken}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+
+	// New tokens can be requested
+
+	res := &resource.UserResource{}
+
+	response, err = mockApp.NewPostRequest("/user/test-user-1/verification-email", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusAccepted, response.Code)
+	assert.False(t, res.EmailVerified)
+	assert.Len(t, memoryMailer.GetMessages(), 3)
+
+	message = memoryMailer.GetLastMessageTo("jane.doe@example.com")
+
+	assert.NotNil(t, message)
+	assert.Equal(t, "Verify your email", message.Subject)
+
+	res = &resource.UserResource{}
+
+	response, err = mockApp.NewPostRequest(
+		"/user/test-user-1/verify-email",
+		mock.NewMockAppOptions().WithBody(resource.UserVerifyEmailResource{Token: getVerificationToken(t, message)}).WithExpectedResponse(res),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.True(t, res.EmailVerified)
+	assert.NotNil(t, res.EmailVerifiedAt)
+
+	// Verified emails are not sent again
+
+	response, err = mockApp.NewPostRequest("/user/test-user-1/verification-email", mock.NewMockAppOptions())
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusAccepted, response.Code)
+	assert.Len(t, memoryMailer.GetMessages(), 3)
+
+	// The verification is audited
+
+	history := &resource.AuditEventResourceList{}
+
+	response, err = mockApp.NewGetRequest("/user/test-user-1/history", mock.NewMockAppOptions().WithExpectedResponse(history))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, model.AuditActionUpdate, history.Data[len(history.Data)-1].Action)
+	assert.Equal(t, false, history.Data[len(history.Data)-1].Changes["email_verified"].Before)
+	assert.Equal(t, true, history.Data[len(history.Data)-1].Changes["email_verified"].After)
+
+	// Unknown users
+
+	response, err = mockApp.NewPostRequest(
+		"/user/nonexistent/verify-email",
+		mock.NewMockAppOptions().WithBody(resource.UserVerifyEmailResource{Token: firstToken}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestUserEmailVerificationExpiredToken(t *testing.T) {
+	appConfig, err := mock.NewDefaultConfigLoader().WithOverrides("auth.email_verification_ttl=1ns").Load()
+
+	assert.Nil(t, err)
+
+	memoryMailer := mailer.NewMemoryMailer()
+	mockApp := mock.NewMockApp(appConfig, withMailer(memoryMailer))
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+
+	response, err := mockApp.NewPostRequest(
+		"/user",
+		mock.NewMockAppOptions().WithBody(resource.UserCreateResource{Username: "test-user-1", UserTypeName: userTypeReq.Name, Email: "jane@example.com"}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	httpError := &apperror.HttpError{}
+
+	response, err = mockApp.NewPostRequest(
+		"/user/test-user-1/verify-email",
+		mock.NewMockAppOptions().
+			WithBody(resource.UserVerifyEmailResource{Token: getVerificationToken(t, memoryMailer.GetLastMessageTo("jane@example.com"))}).
+			WithExpectedResponse(httpError),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	assert.Equal(t, apperror.InvalidTokenErrorCode, httpError.Code)
+}
+
+func TestUserEmailVerificationOutbox(t *testing.T) {
+	outboxDir := t.TempDir()
+	appConfig, err := mock.NewDefaultConfigLoader().WithOverrides("mail.mailer=outbox", "mail.outbox_dir="+outboxDir).Load()
+
+	assert.Nil(t, err)
+
+	mockApp := mock.NewMockApp(appConfig)
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+
+	response, err := mockApp.NewPostRequest(
+		"/user",
+		mock.NewMockAppOptions().WithBody(resource.UserCreateResource{Username: "test-user-1", UserTypeName: userTypeReq.Name, Email: "jane@example.com"}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	files, err := filepath.Glob(filepath.Join(outboxDir, "*"+mailer.OutboxFileExtension))
+
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+
+	content, err := ioutil.ReadFile(files[0])
+
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "To: jane@example.com\r\n")
+	assert.Contains(t, string(content), "Subject: Verify your email\r\n")
+
+	// The token of the outbox verifies the email
+
+	matches := verificationTokenRegexp.FindStringSubmatch(string(content))
+
+	assert.Len(t, matches, 2)
+
+	res := &resource.UserResource{}
+
+	response, err = mockApp.NewPostRequest(
+		"/user/test-user-1/verify-email",
+		mock.NewMockAppOptions().WithBody(resource.UserVerifyEmailResource{Token: matches[1]}).WithExpectedResponse(res),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.True(t, res.EmailVerified)
+}
+
+var verificationTokenRegexp = regexp.MustCompile(`: ([0-9]+\.[A-Za-z0-9_-]+)`)
+
+func getVerificationToken(t *testing.T, message *mailer.Message) string {
+	if !assert.NotNil(t, message) {
+		return ""
+	}
+
+	matches := verificationTokenRegexp.FindStringSubmatch(message.Body)
+
+	if !assert.Len(t, matches, 2, message.Body) {
+		return ""
+	}
+
+	return matches[1]
+}
+
+func withMailer(m mailer.Mailer) app.Option {
+	return app.WithComponentRegistryHook(hooks.ComponentRegistryHookFunc(
+		func(componentRegistry *componentregistry.ComponentRegistry) *componentregistry.ComponentRegistry {
+			componentRegistry.Mailer = m
+
+			return componentRegistry
+		},
+	))
+}
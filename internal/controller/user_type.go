@@ -28,7 +28,7 @@ type UserTypeController struct {
 // @Description Allows you to search for user types using different filters and options.
 // @Produce json
 // @Param name query string false "User Type Name"
-// @Param sort_by query string false "Field to sort by. Allowed fields: name"
+// @Param sort_by query string false "Field to sort by. Allowed fields: id, name, created_at, updated_at"
 // @Param sort_dir query string false "Direction to sort by. Allowed values: asc, desc. Default: asc"
 // @Param offset query int false "Starts results from this offset. Default: 0"
 // @Param limit query int false "Limits the amount of results to return. Default: 50"
@@ -88,8 +88,11 @@ func (ctrl *UserTypeController) FindOneByName(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param user body resource.UserTypeCreateResource true "User Type data"
+// @Param Idempotency-Key header string false "Makes the request safe to retry. The first response is replayed on retries with the same key"
 // @Success 201 {object} resource.UserTypeResource
 // @Failure 400 {object} apperror.HttpError
+// @Failure 409 {object} apperror.HttpError
+// @Failure 422 {object} apperror.HttpError
 // @Failure 500 {object} apperror.HttpError
 // @Tags user types
 // @Router /user_type [post]
@@ -188,6 +191,29 @@ func (ctrl *UserTypeController) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, userResource)
 }
 
+// History Returns the audit history of a user type.
+// @Summary Returns the audit history of a user type.
+// @Description Allows you to see every change made to a user type, who made it and when.
+// @Produce json
+// @Param name path string true "Name"
+// @Success 200 {object} resource.AuditEventResourceList
+// @Failure 400 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags user types
+// @Router /user_type/{name}/history [get]
+func (ctrl *UserTypeController) History(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	auditEventResourceList, err := ctrl.userTypeService.History(requestContext, c.Param("name"))
+
+	if err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, auditEventResourceList)
+}
+
 // Static functions
 
 func NewUserTypeController(userTypeService service.UserTypeService, requestContextFactory *context.RequestContextFactory) *UserTypeController {
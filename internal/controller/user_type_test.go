@@ -2,8 +2,11 @@ package controller_test
 
 import (
 	"net/http"
+	"net/url"
 	"testing"
+	"time"
 
+	"github.com/comfortablynumb/goginrestapi/internal/app"
 	"github.com/comfortablynumb/goginrestapi/internal/apperror"
 	"github.com/comfortablynumb/goginrestapi/internal/mock"
 	"github.com/comfortablynumb/goginrestapi/internal/resource"
@@ -27,7 +30,7 @@ func TestUserTypeCreationNameIsRequired(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, http.StatusBadRequest, response.Code)
 	assert.True(t, res.HasErrorCount(1))
-	assert.True(t, res.HasErrorCountByNameAndType(1, "UserTypeCreateResource.Name", "required"))
+	assert.True(t, res.HasErrorCountByNameAndType(1, "name", "required"))
 }
 
 func TestUserTypeCreationInvalidBinding(t *testing.T) {
@@ -75,7 +78,7 @@ func TestUserTypeCreationUniqueValidation(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, http.StatusBadRequest, response.Code)
 	assert.True(t, invalidRes.HasErrorCount(1))
-	assert.True(t, invalidRes.HasErrorCountByNameAndType(1, "UserTypeCreateResource.Name", "unique"))
+	assert.True(t, invalidRes.HasErrorCountByNameAndType(1, "name", "unique"))
 }
 
 func TestUserTypeCreationOk(t *testing.T) {
@@ -148,7 +151,7 @@ func TestUserTypeUpdateUniqueValidation(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, http.StatusBadRequest, response.Code)
 	assert.True(t, invalidRes.HasErrorCount(1))
-	assert.True(t, invalidRes.HasErrorCountByNameAndType(1, "UserTypeUpdateResource.Name", "unique"))
+	assert.True(t, invalidRes.HasErrorCountByNameAndType(1, "name", "unique"))
 }
 
 func TestUserTypeUpdateOk(t *testing.T) {
@@ -172,6 +175,61 @@ func TestUserTypeUpdateOk(t *testing.T) {
 	assert.Equal(t, http.StatusOK, response.Code)
 	assert.Equal(t, userTypeReq.Name, res.Name)
 	assert.Equal(t, req.Disabled, res.Disabled)
+
+	// Changes must be persisted
+
+	res = &resource.UserTypeResource{}
+
+	response, err = mockApp.NewGetRequest("/user_type/"+userTypeReq.Name, mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, req.Disabled, res.Disabled)
+}
+
+func TestUserTypeTimestamps(t *testing.T) {
+	// Times are stored in UTC, with microsecond precision
+
+	clock := mock.NewFakeTimeService(time.Date(2026, 3, 1, 10, 30, 0, 123456789, time.FixedZone("UTC-3", -3*60*60)))
+	mockApp := mock.NewMockApp(mock.NewDefaultConfig(), app.WithTimeService(clock))
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	createdAt := time.Date(2026, 3, 1, 13, 30, 0, 123456000, time.UTC)
+
+	assert.Equal(t, createdAt, clock.GetCurrentUtcTime())
+
+	userTypeReq := CreateUserType(t, mockApp, "test-user-type-1")
+
+	res := &resource.UserTypeResource{}
+
+	response, err := mockApp.NewGetRequest("/user_type/"+userTypeReq.Name, mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, createdAt, res.CreatedAt)
+	assert.Equal(t, createdAt, res.UpdatedAt)
+
+	clock.Advance(time.Hour + time.Nanosecond)
+
+	response, err = mockApp.NewPutRequest(
+		"/user_type/"+userTypeReq.Name,
+		mock.NewMockAppOptions().WithBody(resource.UserTypeUpdateResource{Name: userTypeReq.Name, Disabled: true}),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	res = &resource.UserTypeResource{}
+
+	response, err = mockApp.NewGetRequest("/user_type/"+userTypeReq.Name, mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, createdAt, res.CreatedAt)
+	assert.Equal(t, createdAt.Add(time.Hour), res.UpdatedAt)
 }
 
 // DELETE TESTS
@@ -293,6 +351,39 @@ func TestUserTypeFindSeveralCases(t *testing.T) {
 	assert.Equal(t, userTypeReq2.Name, res.Data[0].Name)
 }
 
+func TestUserTypeFindOnlySortsBySortableFields(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	CreateUserType(t, mockApp, "test-user-type-1")
+	CreateUserType(t, mockApp, "test-user-type-2")
+
+	res := &resource.UserTypeResourceList{}
+
+	response, err := mockApp.NewGetRequest("/user_type?sort_by=name&sort_dir=desc", mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, 2, len(res.Data))
+	assert.Equal(t, "test-user-type-2", res.Data[0].Name)
+
+	for _, sortBy := range []string{"tenant_id", "(SELECT 1)", "u.id; DROP TABLE users"} {
+		httpError := &apperror.HttpError{}
+
+		response, err = mockApp.NewGetRequest(
+			"/user_type?sort_dir=asc&sort_by="+url.QueryEscape(sortBy),
+			mock.NewMockAppOptions().WithExpectedResponse(httpError),
+		)
+
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, response.Code, sortBy)
+		assert.True(t, httpError.HasErrorCountByNameAndType(1, "sort_by", "oneof"), sortBy)
+	}
+}
+
 // FIND ONE TESTS
 
 func TestUserTypeFindOneByNameSeveralCases(t *testing.T) {
@@ -0,0 +1,283 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Constants
+
+const (
+	WebhookControllerSourceName = "WebhookController"
+)
+
+// Structs
+
+type WebhookController struct {
+	webhookService        service.WebhookService
+	requestContextFactory *context.RequestContextFactory
+}
+
+// Find Search for webhook subscriptions.
+// @Summary Search for webhook subscriptions.
+// @Description Allows you to search for webhook subscriptions using different filters and options.
+// @Produce json
+// @Param disabled query bool false "Disabled"
+// @Param sort_by query string false "Field to sort by. Allowed fields: id, url, created_at"
+// @Param sort_dir query string false "Direction to sort by. Allowed values: asc, desc. Default: asc"
+// @Param offset query int false "Starts results from this offset. Default: 0"
+// @Param limit query int false "Limits the amount of results to return. Default: 50"
+// @Success 200 {object} resource.WebhookResourceList
+// @Failure 400 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags webhooks
+// @Router /webhooks [get]
+func (ctrl *WebhookController) Find(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	var req resource.WebhookFindResource
+
+	if err := c.ShouldBind(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, WebhookControllerSourceName, nil))
+
+		return
+	}
+
+	webhookResourceList, err := ctrl.webhookService.Find(requestContext, &req)
+
+	if err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookResourceList)
+}
+
+// FindOneByID Find a webhook subscription by its ID.
+// @Summary Find a webhook subscription by its ID.
+// @Description Allows you to search a webhook subscription by its ID.
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} resource.WebhookResource
+// @Failure 404 {object} apperror.HttpError
+// @Failure 400 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags webhooks
+// @Router /webhooks/{id} [get]
+func (ctrl *WebhookController) FindOneByID(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	ID, parseErr := strconv.ParseInt(c.Param("id"), 10, 64)
+
+	if parseErr != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, parseErr, WebhookControllerSourceName, nil))
+
+		return
+	}
+
+	webhookResource, err := ctrl.webhookService.FindOneByID(requestContext, ID)
+
+	if err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookResource)
+}
+
+// Create Create a new webhook subscription.
+// @Summary Create a new webhook subscription.
+// @Description Allows you to subscribe a URL to one or more event types ("*" subscribes to all of them). Every delivery is signed with the secret: the X-Webhook-Signature header contains "sha256=" followed by the hex encoded HMAC-SHA256 of the request body.
+// @Accept json
+// @Produce json
+// @Param webhook body resource.WebhookCreateResource true "Webhook data"
+// @Param Idempotency-Key header string false "Makes the request safe to retry. The first response is replayed on retries with the same key"
+// @Success 201 {object} resource.WebhookResource
+// @Failure 400 {object} apperror.HttpError
+// @Failure 409 {object} apperror.HttpError
+// @Failure 422 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags webhooks
+// @Router /webhooks [post]
+func (ctrl *WebhookController) Create(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	var req resource.WebhookCreateResource
+
+	if err := c.ShouldBind(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, WebhookControllerSourceName, nil))
+
+		return
+	}
+
+	webhookResource, err := ctrl.webhookService.Create(requestContext, &req)
+
+	if err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhookResource)
+}
+
+// Update Update a webhook subscription.
+// @Summary Update a webhook subscription.
+// @Description Allows you to update an existing webhook subscription. The secret is only rotated if a new one is sent.
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param webhook body resource.WebhookUpdateResource true "Webhook data"
+// @Success 200 {object} resource.WebhookResource
+// @Failure 400 {object} apperror.HttpError
+// @Failure 404 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags webhooks
+// @Router /webhooks/{id} [put]
+func (ctrl *WebhookController) Update(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	var req resource.WebhookUpdateResource
+
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, WebhookControllerSourceName, nil))
+
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, WebhookControllerSourceName, nil))
+
+		return
+	}
+
+	webhookResource, err := ctrl.webhookService.Update(requestContext, &req)
+
+	if err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookResource)
+}
+
+// Delete Delete a webhook subscription.
+// @Summary Delete a webhook subscription.
+// @Description Allows you to delete an existing webhook subscription, along with its deliveries.
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} resource.WebhookResource
+// @Failure 400 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags webhooks
+// @Router /webhooks/{id} [delete]
+func (ctrl *WebhookController) Delete(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	var req resource.WebhookDeleteResource
+
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, WebhookControllerSourceName, nil))
+
+		return
+	}
+
+	webhookResource, err := ctrl.webhookService.Delete(requestContext, &req)
+
+	if err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookResource)
+}
+
+// FindDeliveries Search for the deliveries of a webhook subscription.
+// @Summary Search for the deliveries of a webhook subscription.
+// @Description Allows you to see the deliveries of a webhook subscription, along with the log of every attempt.
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param status query string false "Status. Allowed values: pending, succeeded, failed"
+// @Param sort_by query string false "Field to sort by. Allowed fields: id, created_at"
+// @Param sort_dir query string false "Direction to sort by. Allowed values: asc, desc. Default: asc"
+// @Param offset query int false "Starts results from this offset. Default: 0"
+// @Param limit query int false "Limits the amount of results to return. Default: 50"
+// @Success 200 {object} resource.WebhookDeliveryResourceList
+// @Failure 400 {object} apperror.HttpError
+// @Failure 404 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags webhooks
+// @Router /webhooks/{id}/deliveries [get]
+func (ctrl *WebhookController) FindDeliveries(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	var req resource.WebhookDeliveryFindResource
+
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, WebhookControllerSourceName, nil))
+
+		return
+	}
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, WebhookControllerSourceName, nil))
+
+		return
+	}
+
+	webhookDeliveryResourceList, err := ctrl.webhookService.FindDeliveries(requestContext, &req)
+
+	if err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookDeliveryResourceList)
+}
+
+// Redeliver Deliver an event again.
+// @Summary Deliver an event again.
+// @Description Allows you to attempt a delivery again right away, whatever its current status is.
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param delivery_id path int true "Delivery ID"
+// @Success 200 {object} resource.WebhookDeliveryResource
+// @Failure 400 {object} apperror.HttpError
+// @Failure 404 {object} apperror.HttpError
+// @Failure 500 {object} apperror.HttpError
+// @Tags webhooks
+// @Router /webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (ctrl *WebhookController) Redeliver(c *gin.Context) {
+	requestContext := ctrl.requestContextFactory.NewRequestContext(c)
+	var req resource.WebhookRedeliverResource
+
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.Error(apperror.NewBindingHttpError(requestContext, err, WebhookControllerSourceName, nil))
+
+		return
+	}
+
+	webhookDeliveryResource, err := ctrl.webhookService.Redeliver(requestContext, &req)
+
+	if err != nil {
+		c.Error(err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookDeliveryResource)
+}
+
+// Static functions
+
+func NewWebhookController(webhookService service.WebhookService, requestContextFactory *context.RequestContextFactory) *WebhookController {
+	return &WebhookController{
+		webhookService:        webhookService,
+		requestContextFactory: requestContextFactory,
+	}
+}
@@ -0,0 +1,289 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/mock"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/module"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+const webhookTestSecret = "a-very-secret-webhook-key"
+
+func TestWebhookCreateWithInvalidData(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	req := resource.WebhookCreateResource{
+		URL:        "not-a-url",
+		EventTypes: []string{"unknown.event"},
+		Secret:     "short",
+	}
+
+	res := &apperror.HttpError{}
+
+	response, err := mockApp.NewPostRequest("/webhooks", mock.NewMockAppOptions().WithBody(req).WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.True(t, res.HasErrorCount(3))
+	assert.True(t, res.HasErrorCountByNameAndType(1, "url", "url"))
+	assert.True(t, res.HasErrorCountByNameAndType(1, "event_types[0]", "event_type"))
+	assert.True(t, res.HasErrorCountByNameAndType(1, "secret", "min"))
+}
+
+func TestWebhookCrud(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	webhook := CreateWebhook(t, mockApp, "http://localhost/hook", events.UserTypeCreatedEventName)
+
+	assert.Equal(t, []string{events.UserTypeCreatedEventName}, webhook.EventTypes)
+
+	updateReq := resource.WebhookUpdateResource{
+		URL:        "http://localhost/other-hook",
+		EventTypes: []string{model.WebhookAllEventTypes},
+		Disabled:   true,
+	}
+
+	response, err := mockApp.NewPutRequest(fmt.Sprintf("/webhooks/%d", webhook.ID), mock.NewMockAppOptions().WithBody(updateReq))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	res := &resource.WebhookResource{}
+
+	response, err = mockApp.NewGetRequest(fmt.Sprintf("/webhooks/%d", webhook.ID), mock.NewMockAppOptions().WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, updateReq.URL, res.URL)
+	assert.Equal(t, updateReq.EventTypes, res.EventTypes)
+	assert.True(t, res.Disabled)
+
+	listRes := &resource.WebhookResourceList{}
+
+	response, err = mockApp.NewGetRequest("/webhooks?disabled=true", mock.NewMockAppOptions().WithExpectedResponse(listRes))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, int64(1), listRes.TotalCount)
+
+	// Only the documented fields can be sorted by
+
+	httpError := &apperror.HttpError{}
+
+	response, err = mockApp.NewGetRequest("/webhooks?sort_by=secret&sort_dir=asc", mock.NewMockAppOptions().WithExpectedResponse(httpError))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.True(t, httpError.HasErrorCountByNameAndType(1, "sort_by", "oneof"))
+
+	httpError = &apperror.HttpError{}
+
+	response, err = mockApp.NewGetRequest(
+		fmt.Sprintf("/webhooks/%d/deliveries?sort_by=url&sort_dir=asc", webhook.ID),
+		mock.NewMockAppOptions().WithExpectedResponse(httpError),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.True(t, httpError.HasErrorCountByNameAndType(1, "sort_by", "oneof"))
+
+	response, err = mockApp.NewDeleteRequest(fmt.Sprintf("/webhooks/%d", webhook.ID), nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	response, err = mockApp.NewGetRequest(fmt.Sprintf("/webhooks/%d", webhook.ID), nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestWebhookDeliveriesAreSigned(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	receiver := newWebhookReceiver(http.StatusOK)
+
+	defer receiver.Close()
+
+	CreateWebhook(t, mockApp, receiver.URL, events.UserTypeCreatedEventName)
+	CreateUserType(t, mockApp, "test-user-type-1")
+
+	delivered := deliverWebhooks(t, mockApp)
+
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, 1, len(receiver.requests))
+
+	request := receiver.requests[0]
+	envelope := &events.Envelope{}
+
+	assert.Nil(t, json.Unmarshal(request.body, envelope))
+	assert.Equal(t, events.UserTypeCreatedEventName, envelope.Name)
+	assert.Equal(t, events.UserTypeCreatedEventName, request.header.Get(service.WebhookEventHeader))
+	assert.Equal(t, service.SignWebhookPayload(webhookTestSecret, request.body), request.header.Get(service.WebhookSignatureHeader))
+
+	// Events the subscription is not interested in are not delivered
+
+	CreateUser(t, mockApp, "test-user-1", "test-user-type-1")
+
+	delivered = deliverWebhooks(t, mockApp)
+
+	assert.Equal(t, 0, delivered)
+	assert.Equal(t, 1, len(receiver.requests))
+}
+
+func TestWebhookFailedDeliveriesCanBeRedelivered(t *testing.T) {
+	mockApp := mock.NewMockAppWithDefaultConfig()
+
+	defer func() {
+		mockApp.App.ExecuteDbMigrationsDown()
+	}()
+
+	receiver := newWebhookReceiver(http.StatusInternalServerError)
+
+	defer receiver.Close()
+
+	webhook := CreateWebhook(t, mockApp, receiver.URL, model.WebhookAllEventTypes)
+
+	CreateUserType(t, mockApp, "test-user-type-1")
+
+	delivered := deliverWebhooks(t, mockApp)
+
+	assert.Equal(t, 0, delivered)
+
+	res := &resource.WebhookDeliveryResourceList{}
+
+	response, err := mockApp.NewGetRequest(
+		fmt.Sprintf("/webhooks/%d/deliveries?status=pending", webhook.ID),
+		mock.NewMockAppOptions().WithExpectedResponse(res),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, int64(1), res.TotalCount)
+	assert.Equal(t, 1, res.Data[0].Attempts)
+	assert.Equal(t, 1, len(res.Data[0].AttemptLog))
+	assert.Equal(t, http.StatusInternalServerError, res.Data[0].AttemptLog[0].StatusCode)
+	assert.NotEmpty(t, res.Data[0].AttemptLog[0].Error)
+
+	// The retry is not due yet, but it can be redelivered manually
+
+	receiver.setStatus(http.StatusNoContent)
+
+	deliveryRes := &resource.WebhookDeliveryResource{}
+
+	response, err = mockApp.NewPostRequest(
+		fmt.Sprintf("/webhooks/%d/deliveries/%d/redeliver", webhook.ID, res.Data[0].ID),
+		mock.NewMockAppOptions().WithExpectedResponse(deliveryRes),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, model.WebhookDeliveryStatusSucceeded, deliveryRes.Status)
+	assert.Equal(t, 2, deliveryRes.Attempts)
+	assert.Equal(t, 2, len(deliveryRes.AttemptLog))
+	assert.Equal(t, http.StatusNoContent, deliveryRes.AttemptLog[1].StatusCode)
+	assert.Equal(t, 2, len(receiver.requests))
+	assert.Equal(t, receiver.requests[0].body, receiver.requests[1].body)
+}
+
+// Helper functions
+
+func CreateWebhook(t *testing.T, mockApp *mock.MockApp, url string, eventTypes ...string) *resource.WebhookResource {
+	req := resource.WebhookCreateResource{
+		URL:        url,
+		EventTypes: eventTypes,
+		Secret:     webhookTestSecret,
+	}
+
+	res := &resource.WebhookResource{}
+
+	response, err := mockApp.NewPostRequest("/webhooks", mock.NewMockAppOptions().WithBody(req).WithExpectedResponse(res))
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.NotZero(t, res.ID)
+
+	return res
+}
+
+// deliverWebhooks Moves pending events from the outbox to the webhook deliveries, and then sends the due deliveries.
+func deliverWebhooks(t *testing.T, mockApp *mock.MockApp) int {
+	componentRegistry := mockApp.App.GetComponentRegistry()
+
+	_, err := componentRegistry.EventDispatcher.DispatchPending()
+
+	assert.Nil(t, err)
+
+	webhookService, err := componentregistry.Get[service.WebhookService](componentRegistry, module.WebhookServiceComponentName)
+
+	assert.Nil(t, err)
+	delivered, appErr := webhookService.DeliverPending(componentRegistry.RequestContextFactory.NewBackgroundRequestContext())
+
+	assert.Nil(t, appErr)
+
+	return delivered
+}
+
+// Helper types
+
+type webhookReceiverRequest struct {
+	header http.Header
+	body   []byte
+}
+
+type webhookReceiver struct {
+	*httptest.Server
+
+	mutex    sync.Mutex
+	status   int
+	requests []*webhookReceiverRequest
+}
+
+func (r *webhookReceiver) setStatus(status int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.status = status
+}
+
+func newWebhookReceiver(status int) *webhookReceiver {
+	receiver := &webhookReceiver{status: status}
+
+	receiver.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+
+		receiver.mutex.Lock()
+		defer receiver.mutex.Unlock()
+
+		receiver.requests = append(receiver.requests, &webhookReceiverRequest{header: req.Header, body: body})
+
+		w.WriteHeader(receiver.status)
+	}))
+
+	return receiver
+}
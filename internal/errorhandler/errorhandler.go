@@ -1,17 +1,24 @@
 package errorhandler
 
 import (
+	"mime"
+	"strconv"
+	"strings"
+
 	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
 	"github.com/comfortablynumb/goginrestapi/internal/context"
 	hooks2 "github.com/comfortablynumb/goginrestapi/internal/hooks"
+	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
 )
 
 // Struct
 
 type ErrorHandler struct {
-	hooks  *hooks2.Hooks
-	logger *zerolog.Logger
+	hooks        *hooks2.Hooks
+	logger       *zerolog.Logger
+	errorsConfig config.ErrorsConfig
 }
 
 func (e *ErrorHandler) HandleFatal(err error, message string) {
@@ -38,6 +45,26 @@ func (e *ErrorHandler) MapAppErrorToHttpError(ctx *context.RequestContext, err *
 		return apperror.NewDbHttpError(ctx, err.Err, err.Source, err.Data)
 	case apperror.ModelNotFoundErrorCode:
 		return apperror.NewNotFoundHttpError(ctx, err.Err, err.Source, err.Data)
+	case apperror.IdempotencyKeyMismatchErrorCode:
+		return apperror.NewIdempotencyKeyMismatchHttpError(ctx, err.Err, err.Source, err.Data)
+	case apperror.IdempotencyKeyInProgressErrorCode:
+		return apperror.NewIdempotencyKeyInProgressHttpError(ctx, err.Err, err.Source, err.Data)
+	case apperror.InvalidTokenErrorCode:
+		return apperror.NewInvalidTokenHttpError(ctx, err.Err, err.Source, err.Data)
+	case apperror.InvalidCredentialsErrorCode:
+		return apperror.NewInvalidCredentialsHttpError(ctx, err.Err, err.Source, err.Data)
+	case apperror.AccountLockedErrorCode:
+		return apperror.NewAccountLockedHttpError(ctx, err.Err, err.Source, err.Data)
+	case apperror.MfaRequiredErrorCode:
+		return apperror.NewMfaRequiredHttpError(ctx, err.Err, err.Source, err.Data)
+	case apperror.MfaEnrollmentRequiredErrorCode:
+		return apperror.NewMfaEnrollmentRequiredHttpError(ctx, err.Err, err.Source, err.Data)
+	case apperror.InvalidMfaCodeErrorCode:
+		return apperror.NewInvalidMfaCodeHttpError(ctx, err.Err, err.Source, err.Data)
+	case apperror.MfaAlreadyEnabledErrorCode:
+		return apperror.NewMfaAlreadyEnabledHttpError(ctx, err.Err, err.Source, err.Data)
+	case apperror.InvalidTenantErrorCode:
+		return apperror.NewInvalidTenantHttpError(ctx, err.Err, err.Source, err.Data)
 	default:
 		return apperror.NewInternalServerHttpError(ctx, err.Err, err.Source, err.Data)
 	}
@@ -62,11 +89,49 @@ func (e *ErrorHandler) CreateHttpErrorFromErr(ctx *context.RequestContext, err e
 	return controllerError
 }
 
+// Render Writes the error as the response of the request. It's rendered as RFC 7807 problem details if the config
+// or the Accept header of the request ask for them, or with the fields of HttpError otherwise.
+func (e *ErrorHandler) Render(c *gin.Context, ctx *context.RequestContext, httpError *apperror.HttpError) {
+	if !e.errorsConfig.IsProblemFormat() && !acceptsProblemDetails(c.GetHeader("Accept")) {
+		c.AbortWithStatusJSON(httpError.HttpStatus, httpError)
+
+		return
+	}
+
+	problemDetails := apperror.NewProblemDetails(ctx, httpError, e.errorsConfig.TypeBaseUrl, c.Request.URL.RequestURI())
+
+	c.Header("Content-Type", apperror.ProblemContentType)
+	c.AbortWithStatusJSON(httpError.HttpStatus, problemDetails)
+}
+
 // Static functions
 
-func NewErrorHandler(logger *zerolog.Logger, hooks *hooks2.Hooks) *ErrorHandler {
+func NewErrorHandler(logger *zerolog.Logger, hooks *hooks2.Hooks, errorsConfig config.ErrorsConfig) *ErrorHandler {
 	return &ErrorHandler{
-		logger: logger,
-		hooks:  hooks,
+		logger:       logger,
+		hooks:        hooks,
+		errorsConfig: errorsConfig,
 	}
 }
+
+// acceptsProblemDetails Returns whether the problem details media type is one of the acceptable ones of an Accept
+// header.
+func acceptsProblemDetails(accept string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+
+		if err != nil || mediaType != apperror.ProblemContentType {
+			continue
+		}
+
+		if quality, found := params["q"]; found {
+			if value, err := strconv.ParseFloat(quality, 64); err != nil || value <= 0 {
+				continue
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
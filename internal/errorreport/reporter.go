@@ -0,0 +1,67 @@
+package errorreport
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Structs
+
+// Report Error reported by the app, like a panic recovered while serving a request.
+type Report struct {
+	Time      time.Time `json:"time"`
+	Source    string    `json:"source"`
+	Message   string    `json:"message"`
+	Stack     string    `json:"stack"`
+	RequestID string    `json:"request_id,omitempty"`
+	Method    string    `json:"method,omitempty"`
+	Route     string    `json:"route,omitempty"`
+}
+
+// Reporter Forwards the reported errors to its sinks. Failures of the sinks are logged, as there's nowhere else to
+// report them. Without sinks, reports are discarded.
+type Reporter struct {
+	mutex  sync.RWMutex
+	logger *zerolog.Logger
+	sinks  []Sink
+}
+
+func (r *Reporter) AddSink(sink Sink) *Reporter {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sinks = append(r.sinks, sink)
+
+	return r
+}
+
+func (r *Reporter) GetSinks() []Sink {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	res := make([]Sink, len(r.sinks))
+
+	copy(res, r.sinks)
+
+	return res
+}
+
+func (r *Reporter) Report(ctx context.Context, report *Report) {
+	for _, sink := range r.GetSinks() {
+		if err := sink.Report(ctx, report); err != nil {
+			r.logger.Error().Msgf("[ErrorReporter] Could NOT report the error to sink '%s': %s", sink.GetName(), err)
+		}
+	}
+}
+
+// Static functions
+
+func NewReporter(logger *zerolog.Logger) *Reporter {
+	return &Reporter{
+		logger: logger,
+		sinks:  make([]Sink, 0),
+	}
+}
@@ -0,0 +1,144 @@
+package errorreport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+)
+
+// Constants
+
+const (
+	MemorySinkName = "memory"
+	FileSinkName   = "file"
+	SentrySinkName = "sentry"
+)
+
+// Interfaces
+
+// Sink Destination of the errors reported by the app. Report must return an error if the report could not be sent.
+type Sink interface {
+	GetName() string
+	Report(ctx context.Context, report *Report) error
+}
+
+// SentryClient Minimal Sentry-compatible client used by the SentrySink. Adapt your client of choice to this interface.
+type SentryClient interface {
+	CaptureException(err error, tags map[string]string, extra map[string]interface{}) error
+}
+
+// Structs
+
+// MemorySink
+
+type MemorySink struct {
+	mutex   sync.RWMutex
+	reports []*Report
+}
+
+func (s *MemorySink) GetName() string {
+	return MemorySinkName
+}
+
+func (s *MemorySink) Report(ctx context.Context, report *Report) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.reports = append(s.reports, report)
+
+	return nil
+}
+
+func (s *MemorySink) GetReports() []*Report {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	res := make([]*Report, len(s.reports))
+
+	copy(res, s.reports)
+
+	return res
+}
+
+// FileSink Appends every report, as a JSON line, to a file.
+
+type FileSink struct {
+	mutex sync.Mutex
+	path  string
+}
+
+func (s *FileSink) GetName() string {
+	return FileSinkName
+}
+
+func (s *FileSink) Report(ctx context.Context, report *Report) error {
+	data, err := json.Marshal(report)
+
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(append(data, '\n'))
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// SentrySink Captures every report as an exception, tagged with its source, request id, method and route. The stack
+// is sent as extra data.
+
+type SentrySink struct {
+	client SentryClient
+}
+
+func (s *SentrySink) GetName() string {
+	return SentrySinkName
+}
+
+func (s *SentrySink) Report(ctx context.Context, report *Report) error {
+	tags := map[string]string{
+		"source": report.Source,
+	}
+
+	for key, value := range map[string]string{"request_id": report.RequestID, "method": report.Method, "route": report.Route} {
+		if value != "" {
+			tags[key] = value
+		}
+	}
+
+	return s.client.CaptureException(errors.New(report.Message), tags, map[string]interface{}{"stack": report.Stack})
+}
+
+// Static functions
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{
+		reports: make([]*Report, 0),
+	}
+}
+
+func NewFileSink(path string) *FileSink {
+	return &FileSink{
+		path: path,
+	}
+}
+
+func NewSentrySink(client SentryClient) *SentrySink {
+	return &SentrySink{
+		client: client,
+	}
+}
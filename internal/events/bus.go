@@ -0,0 +1,92 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Constants
+
+const (
+	BusSinkName = "bus"
+)
+
+// Types
+
+type Handler func(envelope *Envelope, event Event) error
+
+// Structs
+
+// Bus In-process event bus. It is registered as a sink on the Dispatcher, so subscribers only receive events once the
+// transaction which published them has been committed. Delivery is at-least-once: if any subscriber fails, every
+// subscriber of that event will receive it again on the next attempt.
+type Bus struct {
+	mutex       sync.RWMutex
+	handlers    map[string][]Handler
+	allHandlers []Handler
+}
+
+func (b *Bus) Subscribe(eventName string, handler Handler) *Bus {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.handlers[eventName] = append(b.handlers[eventName], handler)
+
+	return b
+}
+
+// SubscribeAll Subscribes the handler to every event.
+func (b *Bus) SubscribeAll(handler Handler) *Bus {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.allHandlers = append(b.allHandlers, handler)
+
+	return b
+}
+
+func (b *Bus) GetName() string {
+	return BusSinkName
+}
+
+func (b *Bus) Deliver(ctx context.Context, envelope *Envelope) error {
+	b.mutex.RLock()
+	handlers := append(append([]Handler{}, b.handlers[envelope.Name]...), b.allHandlers...)
+	b.mutex.RUnlock()
+
+	if len(handlers) < 1 {
+		return nil
+	}
+
+	event, err := Decode(envelope)
+
+	if err != nil {
+		return err
+	}
+
+	failures := make([]string, 0)
+
+	for _, handler := range handlers {
+		if err := handler(envelope, event); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.New(fmt.Sprintf("%d subscriber(s) failed: %s", len(failures), strings.Join(failures, "; ")))
+	}
+
+	return nil
+}
+
+// Static functions
+
+func NewBus() *Bus {
+	return &Bus{
+		handlers:    make(map[string][]Handler),
+		allHandlers: make([]Handler, 0),
+	}
+}
@@ -0,0 +1,243 @@
+package events
+
+import (
+	context2 "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+// Constants
+
+const (
+	DefaultDispatchInterval = time.Second
+	DefaultBatchSize        = 100
+	DefaultMaxAttempts      = 10
+	DefaultRetryBaseDelay   = time.Second
+	DefaultRetryMaxDelay    = 5 * time.Minute
+)
+
+// Structs
+
+type DispatcherOptions struct {
+	Interval       time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+}
+
+// Dispatcher Background process which reads the pending events from the outbox and delivers them to every sink. Events
+// are only marked as delivered once every sink accepted them, so delivery is at-least-once. Failed events are retried
+// with exponential backoff until MaxAttempts is reached.
+type Dispatcher struct {
+	outboxEventRepository repository.OutboxEventRepository
+	requestContextFactory *context.RequestContextFactory
+	logger                *zerolog.Logger
+	now                   func() time.Time
+	options               DispatcherOptions
+	sinks                 []Sink
+	mutex                 sync.Mutex
+	stop                  chan struct{}
+	done                  chan struct{}
+}
+
+func (d *Dispatcher) AddSink(sink Sink) *Dispatcher {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.sinks = append(d.sinks, sink)
+
+	return d
+}
+
+func (d *Dispatcher) GetSinks() []Sink {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.sinks
+}
+
+// Start Starts dispatching pending events every Interval, until Stop is called.
+func (d *Dispatcher) Start() {
+	d.stop = make(chan struct{})
+	d.done = make(chan struct{})
+
+	go func() {
+		defer close(d.done)
+
+		ticker := time.NewTicker(d.options.Interval)
+
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+				if _, err := d.DispatchPending(); err != nil {
+					d.logger.Error().Msgf("[Dispatcher] Could NOT dispatch pending events: %s", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop Stops the dispatcher, waiting for the batch being dispatched (if any) to finish.
+func (d *Dispatcher) Stop() {
+	if d.stop == nil {
+		return
+	}
+
+	close(d.stop)
+
+	<-d.done
+
+	d.stop = nil
+}
+
+// DispatchPending Delivers a batch of pending events and returns how many of them were delivered.
+func (d *Dispatcher) DispatchPending() (int, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	ctx := d.requestContextFactory.NewBackgroundRequestContext()
+	now := d.now()
+
+	outboxEvents, appErr := d.outboxEventRepository.FindPending(ctx, now, d.options.MaxAttempts, d.options.BatchSize)
+
+	if appErr != nil {
+		return 0, appErr
+	}
+
+	delivered := 0
+
+	for _, outboxEvent := range outboxEvents {
+		err := d.deliver(outboxEvent)
+
+		if err == nil {
+			deliveredAt := d.now()
+
+			outboxEvent.Attempts++
+			outboxEvent.LastError = ""
+			outboxEvent.DeliveredAt = &deliveredAt
+
+			delivered++
+		} else {
+			outboxEvent.Attempts++
+			outboxEvent.LastError = err.Error()
+			outboxEvent.NextAttemptAt = d.now().Add(d.backoff(outboxEvent.Attempts))
+
+			d.logger.Warn().Msgf(
+				"[Dispatcher] Could NOT deliver event %d (%s) on attempt %d: %s",
+				outboxEvent.ID,
+				outboxEvent.Name,
+				outboxEvent.Attempts,
+				err,
+			)
+		}
+
+		if appErr := d.outboxEventRepository.Update(ctx, outboxEvent); appErr != nil {
+			return delivered, appErr
+		}
+	}
+
+	return delivered, nil
+}
+
+func (d *Dispatcher) deliver(outboxEvent *model.OutboxEvent) error {
+	envelope := &Envelope{
+		ID:         outboxEvent.ID,
+		TenantID:   outboxEvent.TenantID,
+		Tenant:     outboxEvent.Tenant,
+		Name:       outboxEvent.Name,
+		Payload:    json.RawMessage(outboxEvent.Payload),
+		Actor:      outboxEvent.Actor,
+		RequestID:  outboxEvent.RequestID,
+		OccurredAt: outboxEvent.CreatedAt,
+	}
+	failures := make([]string, 0)
+
+	for _, sink := range d.sinks {
+		if err := d.deliverToSink(sink, envelope); err != nil {
+			failures = append(failures, fmt.Sprintf("[%s] %s", sink.GetName(), err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) deliverToSink(sink Sink, envelope *Envelope) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprintf("sink panicked: %v", r))
+		}
+	}()
+
+	return sink.Deliver(context2.Background(), envelope)
+}
+
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.options.RetryBaseDelay
+
+	for i := 1; i < attempts && delay < d.options.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > d.options.RetryMaxDelay {
+		delay = d.options.RetryMaxDelay
+	}
+
+	return delay
+}
+
+// Static functions
+
+func NewDispatcher(
+	outboxEventRepository repository.OutboxEventRepository,
+	requestContextFactory *context.RequestContextFactory,
+	logger *zerolog.Logger,
+	now func() time.Time,
+	options DispatcherOptions,
+) *Dispatcher {
+	if options.Interval <= 0 {
+		options.Interval = DefaultDispatchInterval
+	}
+
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultBatchSize
+	}
+
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = DefaultMaxAttempts
+	}
+
+	if options.RetryBaseDelay <= 0 {
+		options.RetryBaseDelay = DefaultRetryBaseDelay
+	}
+
+	if options.RetryMaxDelay <= 0 {
+		options.RetryMaxDelay = DefaultRetryMaxDelay
+	}
+
+	return &Dispatcher{
+		outboxEventRepository: outboxEventRepository,
+		requestContextFactory: requestContextFactory,
+		logger:                logger,
+		now:                   now,
+		options:               options,
+		sinks:                 make([]Sink, 0),
+	}
+}
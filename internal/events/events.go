@@ -0,0 +1,229 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+)
+
+// Constants
+
+const (
+	UserCreatedEventName  = "user.created"
+	UserUpdatedEventName  = "user.updated"
+	UserDisabledEventName = "user.disabled"
+	UserDeletedEventName  = "user.deleted"
+
+	UserEmailVerifiedEventName = "user.email_verified"
+	UserLockedEventName        = "user.locked"
+	UserMfaEnabledEventName    = "user.mfa_enabled"
+	UserMfaDisabledEventName   = "user.mfa_disabled"
+
+	UserTypeCreatedEventName  = "user_type.created"
+	UserTypeUpdatedEventName  = "user_type.updated"
+	UserTypeDisabledEventName = "user_type.disabled"
+	UserTypeDeletedEventName  = "user_type.deleted"
+)
+
+// Variables
+
+var (
+	factoriesMutex = sync.RWMutex{}
+	factories      = map[string]func() Event{
+		UserCreatedEventName:       func() Event { return &UserCreated{} },
+		UserUpdatedEventName:       func() Event { return &UserUpdated{} },
+		UserDisabledEventName:      func() Event { return &UserDisabled{} },
+		UserDeletedEventName:       func() Event { return &UserDeleted{} },
+		UserEmailVerifiedEventName: func() Event { return &UserEmailVerified{} },
+		UserLockedEventName:        func() Event { return &UserLocked{} },
+		UserMfaEnabledEventName:    func() Event { return &UserMfaEnabled{} },
+		UserMfaDisabledEventName:   func() Event { return &UserMfaDisabled{} },
+		UserTypeCreatedEventName:   func() Event { return &UserTypeCreated{} },
+		UserTypeUpdatedEventName:   func() Event { return &UserTypeUpdated{} },
+		UserTypeDisabledEventName:  func() Event { return &UserTypeDisabled{} },
+		UserTypeDeletedEventName:   func() Event { return &UserTypeDeleted{} },
+	}
+)
+
+// Interfaces
+
+type Event interface {
+	GetName() string
+}
+
+// Structs
+
+// Envelope An event as it was stored on the outbox, ready to be delivered. ID is stable across delivery attempts, so
+// consumers can use it to discard duplicates. Events must only reach the consumers of the tenant which generated them.
+type Envelope struct {
+	ID         int64           `json:"id"`
+	TenantID   int64           `json:"tenant_id"`
+	Tenant     string          `json:"tenant"`
+	Name       string          `json:"name"`
+	Payload    json.RawMessage `json:"payload"`
+	Actor      string          `json:"actor"`
+	RequestID  string          `json:"request_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// User events
+
+type UserCreated struct {
+	User *resource.UserResource `json:"user"`
+}
+
+func (e *UserCreated) GetName() string {
+	return UserCreatedEventName
+}
+
+type UserUpdated struct {
+	Before *resource.UserResource `json:"before"`
+	User   *resource.UserResource `json:"user"`
+}
+
+func (e *UserUpdated) GetName() string {
+	return UserUpdatedEventName
+}
+
+type UserDisabled struct {
+	User *resource.UserResource `json:"user"`
+}
+
+func (e *UserDisabled) GetName() string {
+	return UserDisabledEventName
+}
+
+type UserDeleted struct {
+	User *resource.UserResource `json:"user"`
+}
+
+func (e *UserDeleted) GetName() string {
+	return UserDeletedEventName
+}
+
+type UserEmailVerified struct {
+	User *resource.UserResource `json:"user"`
+}
+
+func (e *UserEmailVerified) GetName() string {
+	return UserEmailVerifiedEventName
+}
+
+type UserLocked struct {
+	User *resource.UserResource `json:"user"`
+}
+
+func (e *UserLocked) GetName() string {
+	return UserLockedEventName
+}
+
+type UserMfaEnabled struct {
+	User *resource.UserResource `json:"user"`
+}
+
+func (e *UserMfaEnabled) GetName() string {
+	return UserMfaEnabledEventName
+}
+
+type UserMfaDisabled struct {
+	User *resource.UserResource `json:"user"`
+}
+
+func (e *UserMfaDisabled) GetName() string {
+	return UserMfaDisabledEventName
+}
+
+// User Type events
+
+type UserTypeCreated struct {
+	UserType *resource.UserTypeResource `json:"user_type"`
+}
+
+func (e *UserTypeCreated) GetName() string {
+	return UserTypeCreatedEventName
+}
+
+type UserTypeUpdated struct {
+	Before   *resource.UserTypeResource `json:"before"`
+	UserType *resource.UserTypeResource `json:"user_type"`
+}
+
+func (e *UserTypeUpdated) GetName() string {
+	return UserTypeUpdatedEventName
+}
+
+type UserTypeDisabled struct {
+	UserType *resource.UserTypeResource `json:"user_type"`
+}
+
+func (e *UserTypeDisabled) GetName() string {
+	return UserTypeDisabledEventName
+}
+
+type UserTypeDeleted struct {
+	UserType *resource.UserTypeResource `json:"user_type"`
+}
+
+func (e *UserTypeDeleted) GetName() string {
+	return UserTypeDeletedEventName
+}
+
+// Static functions
+
+// RegisterEvent Allows modules to declare their own events, so they can be decoded from the outbox.
+func RegisterEvent(name string, factory func() Event) {
+	factoriesMutex.Lock()
+	defer factoriesMutex.Unlock()
+
+	factories[name] = factory
+}
+
+// IsRegistered Returns true if an event with the given name was registered.
+func IsRegistered(name string) bool {
+	factoriesMutex.RLock()
+	defer factoriesMutex.RUnlock()
+
+	_, found := factories[name]
+
+	return found
+}
+
+// GetEventNames Returns the names of every registered event, sorted.
+func GetEventNames() []string {
+	factoriesMutex.RLock()
+	defer factoriesMutex.RUnlock()
+
+	res := make([]string, 0, len(factories))
+
+	for name := range factories {
+		res = append(res, name)
+	}
+
+	sort.Strings(res)
+
+	return res
+}
+
+// Decode Returns the typed event stored on the envelope.
+func Decode(envelope *Envelope) (Event, error) {
+	factoriesMutex.RLock()
+	factory, found := factories[envelope.Name]
+	factoriesMutex.RUnlock()
+
+	if !found {
+		return nil, errors.New(fmt.Sprintf("Event '%s' is not registered.", envelope.Name))
+	}
+
+	event := factory()
+
+	if err := json.Unmarshal(envelope.Payload, event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
@@ -0,0 +1,249 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Constants
+
+const (
+	MemorySinkName  = "memory"
+	FileSinkName    = "file"
+	WebhookSinkName = "webhook"
+	NatsSinkName    = "nats"
+	KafkaSinkName   = "kafka"
+
+	EventIDHeader   = "X-Event-ID"
+	EventNameHeader = "X-Event-Name"
+)
+
+// Interfaces
+
+// Sink Destination of the events stored on the outbox. Deliver must return an error if the event could not be
+// delivered, so the Dispatcher retries it later. Sinks may receive the same event more than once.
+type Sink interface {
+	GetName() string
+	Deliver(ctx context.Context, envelope *Envelope) error
+}
+
+// NatsPublisher Subset of a NATS connection (i.e. *nats.Conn) used by the NatsSink.
+type NatsPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// KafkaProducer Minimal producer used by the KafkaSink. Adapt your Kafka client of choice to this interface.
+type KafkaProducer interface {
+	Produce(topic string, key []byte, value []byte) error
+}
+
+// Structs
+
+// MemorySink
+
+type MemorySink struct {
+	mutex     sync.RWMutex
+	envelopes []*Envelope
+}
+
+func (s *MemorySink) GetName() string {
+	return MemorySinkName
+}
+
+func (s *MemorySink) Deliver(ctx context.Context, envelope *Envelope) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.envelopes = append(s.envelopes, envelope)
+
+	return nil
+}
+
+func (s *MemorySink) GetEnvelopes() []*Envelope {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	res := make([]*Envelope, len(s.envelopes))
+
+	copy(res, s.envelopes)
+
+	return res
+}
+
+func (s *MemorySink) GetEnvelopesByName(name string) []*Envelope {
+	res := make([]*Envelope, 0)
+
+	for _, envelope := range s.GetEnvelopes() {
+		if envelope.Name == name {
+			res = append(res, envelope)
+		}
+	}
+
+	return res
+}
+
+// FileSink Appends every event, as a JSON line, to a file.
+
+type FileSink struct {
+	mutex sync.Mutex
+	path  string
+}
+
+func (s *FileSink) GetName() string {
+	return FileSinkName
+}
+
+func (s *FileSink) Deliver(ctx context.Context, envelope *Envelope) error {
+	data, err := json.Marshal(envelope)
+
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(append(data, '\n'))
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// WebhookSink POSTs every event, as JSON, to a URL. Any non 2xx response is considered a failure.
+
+type WebhookSink struct {
+	url        string
+	httpClient *http.Client
+}
+
+func (s *WebhookSink) GetName() string {
+	return WebhookSinkName
+}
+
+func (s *WebhookSink) Deliver(ctx context.Context, envelope *Envelope) error {
+	data, err := json.Marshal(envelope)
+
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(data))
+
+	if err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventIDHeader, strconv.FormatInt(envelope.ID, 10))
+	req.Header.Set(EventNameHeader, envelope.Name)
+
+	res, err := s.httpClient.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return errors.New(fmt.Sprintf("Webhook '%s' responded with status %d.", s.url, res.StatusCode))
+	}
+
+	return nil
+}
+
+// NatsSink Publishes every event on the subject "<prefix><event name>".
+
+type NatsSink struct {
+	publisher     NatsPublisher
+	subjectPrefix string
+}
+
+func (s *NatsSink) GetName() string {
+	return NatsSinkName
+}
+
+func (s *NatsSink) Deliver(ctx context.Context, envelope *Envelope) error {
+	data, err := json.Marshal(envelope)
+
+	if err != nil {
+		return err
+	}
+
+	return s.publisher.Publish(s.subjectPrefix+envelope.Name, data)
+}
+
+// KafkaSink Produces every event on a topic, keyed by the event ID.
+
+type KafkaSink struct {
+	producer KafkaProducer
+	topic    string
+}
+
+func (s *KafkaSink) GetName() string {
+	return KafkaSinkName
+}
+
+func (s *KafkaSink) Deliver(ctx context.Context, envelope *Envelope) error {
+	data, err := json.Marshal(envelope)
+
+	if err != nil {
+		return err
+	}
+
+	return s.producer.Produce(s.topic, []byte(strconv.FormatInt(envelope.ID, 10)), data)
+}
+
+// Static functions
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{
+		envelopes: make([]*Envelope, 0),
+	}
+}
+
+func NewFileSink(path string) *FileSink {
+	return &FileSink{
+		path: path,
+	}
+}
+
+func NewWebhookSink(url string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{
+		url:        url,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+func NewNatsSink(publisher NatsPublisher, subjectPrefix string) *NatsSink {
+	return &NatsSink{
+		publisher:     publisher,
+		subjectPrefix: subjectPrefix,
+	}
+}
+
+func NewKafkaSink(producer KafkaProducer, topic string) *KafkaSink {
+	return &KafkaSink{
+		producer: producer,
+		topic:    topic,
+	}
+}
@@ -0,0 +1,371 @@
+package fixtures
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v2"
+)
+
+// Interfaces
+
+// Loader Loads seed data through the services, so the same validation rules of the API apply. Loading is idempotent:
+// existing entities are updated to match the fixtures, and left untouched if they already do.
+type Loader interface {
+	GetEnvironmentFiles(environment string) ([]string, error)
+	LoadEnvironment(environment string) (*Result, error)
+	LoadFile(path string) (*Result, error)
+}
+
+// Structs
+
+// Fixtures Contents of a fixtures file. Users reference their user type by name. User types are loaded first, so
+// users can reference the ones defined on the same file.
+type Fixtures struct {
+	UserTypes []*UserTypeFixture `yaml:"user_types" json:"user_types"`
+	Users     []*UserFixture     `yaml:"users" json:"users"`
+}
+
+type UserTypeFixture struct {
+	Name        string `yaml:"name" json:"name"`
+	Disabled    bool   `yaml:"disabled" json:"disabled"`
+	RequiresMfa bool   `yaml:"requires_mfa" json:"requires_mfa"`
+}
+
+// UserFixture Users without locale or timezone get the defaults when they are created, and keep their current ones
+// when they are updated.
+type UserFixture struct {
+	Username    string `yaml:"username" json:"username"`
+	UserType    string `yaml:"user_type" json:"user_type"`
+	Disabled    bool   `yaml:"disabled" json:"disabled"`
+	Email       string `yaml:"email" json:"email"`
+	DisplayName string `yaml:"display_name" json:"display_name"`
+	Locale      string `yaml:"locale" json:"locale"`
+	Timezone    string `yaml:"timezone" json:"timezone"`
+}
+
+// Result
+
+type Result struct {
+	Created   int
+	Updated   int
+	Unchanged int
+}
+
+func (r *Result) Add(other *Result) *Result {
+	r.Created += other.Created
+	r.Updated += other.Updated
+	r.Unchanged += other.Unchanged
+
+	return r
+}
+
+func (r *Result) String() string {
+	return fmt.Sprintf("%d created, %d updated, %d unchanged", r.Created, r.Updated, r.Unchanged)
+}
+
+// Error
+
+type Error struct {
+	Path   string
+	Entry  string
+	AppErr *apperror.AppError
+}
+
+func (e *Error) Error() string {
+	messages := []string{e.AppErr.Message}
+
+	for _, validationError := range e.AppErr.GetErrors() {
+		messages = append(messages, fmt.Sprintf("%s: %s", validationError.Field, validationError.Message))
+	}
+
+	return fmt.Sprintf("%s: %s: %s", e.Path, e.Entry, strings.Join(messages, " - "))
+}
+
+// loader
+
+type loader struct {
+	appConfig             config.AppConfig
+	logger                *zerolog.Logger
+	requestContextFactory *context.RequestContextFactory
+	transactionService    service.TransactionService
+	userTypeService       service.UserTypeService
+	userService           service.UserService
+}
+
+// GetEnvironmentFiles Returns the fixture files of the environment, sorted by name. Every environment has its own
+// directory inside the fixtures path. Subdirectories are ignored.
+func (l *loader) GetEnvironmentFiles(environment string) ([]string, error) {
+	if environment == "" || strings.ContainsAny(environment, `/\.`) {
+		return nil, errors.New(fmt.Sprintf("Invalid environment: '%s'.", environment))
+	}
+
+	dir := filepath.Join(l.appConfig.Fixtures.Path, environment)
+	files, err := ioutil.ReadDir(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]string, 0)
+
+	for _, file := range files {
+		if file.IsDir() || !isFixturesFile(file.Name()) {
+			continue
+		}
+
+		res = append(res, filepath.Join(dir, file.Name()))
+	}
+
+	sort.Strings(res)
+
+	return res, nil
+}
+
+func (l *loader) LoadEnvironment(environment string) (*Result, error) {
+	files, err := l.GetEnvironmentFiles(environment)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{}
+
+	for _, file := range files {
+		fileResult, err := l.LoadFile(file)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res.Add(fileResult)
+	}
+
+	return res, nil
+}
+
+// LoadFile Loads every fixture of the file in a single transaction, so nothing is loaded if any of them fails.
+func (l *loader) LoadFile(path string) (*Result, error) {
+	fixtures, err := l.parseFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	l.logger.Debug().Msgf("[Fixtures] Loading file '%s'...", path)
+
+	ctx := l.requestContextFactory.NewBackgroundRequestContext()
+	res := &Result{}
+
+	var fixturesErr *Error
+
+	appErr := l.transactionService.RunInTransaction(ctx, func() *apperror.AppError {
+		for _, userTypeFixture := range fixtures.UserTypes {
+			if appErr := l.loadUserType(ctx, userTypeFixture, res); appErr != nil {
+				fixturesErr = &Error{Path: path, Entry: fmt.Sprintf("user type '%s'", userTypeFixture.Name), AppErr: appErr}
+
+				return appErr
+			}
+		}
+
+		for _, userFixture := range fixtures.Users {
+			if appErr := l.loadUser(ctx, userFixture, res); appErr != nil {
+				fixturesErr = &Error{Path: path, Entry: fmt.Sprintf("user '%s'", userFixture.Username), AppErr: appErr}
+
+				return appErr
+			}
+		}
+
+		return nil
+	})
+
+	if fixturesErr != nil {
+		return nil, fixturesErr
+	}
+
+	if appErr != nil {
+		return nil, &Error{Path: path, Entry: "transaction", AppErr: appErr}
+	}
+
+	return res, nil
+}
+
+func (l *loader) loadUserType(ctx *context.RequestContext, userTypeFixture *UserTypeFixture, res *Result) *apperror.AppError {
+	userType, appErr := l.userTypeService.FindOneByName(ctx, userTypeFixture.Name)
+
+	if appErr != nil && appErr.Code != apperror.ModelNotFoundErrorCode {
+		return appErr
+	}
+
+	if userType == nil {
+		_, appErr = l.userTypeService.Create(ctx, &resource.UserTypeCreateResource{
+			Name:        userTypeFixture.Name,
+			Disabled:    userTypeFixture.Disabled,
+			RequiresMfa: userTypeFixture.RequiresMfa,
+		})
+
+		if appErr == nil {
+			res.Created++
+		}
+
+		return appErr
+	}
+
+	if userType.Disabled == userTypeFixture.Disabled && userType.RequiresMfa == userTypeFixture.RequiresMfa {
+		res.Unchanged++
+
+		return nil
+	}
+
+	_, appErr = l.userTypeService.Update(ctx, &resource.UserTypeUpdateResource{
+		OriginalName: userType.Name,
+		Name:         userType.Name,
+		Disabled:     userTypeFixture.Disabled,
+		RequiresMfa:  userTypeFixture.RequiresMfa,
+	})
+
+	if appErr == nil {
+		res.Updated++
+	}
+
+	return appErr
+}
+
+func (l *loader) loadUser(ctx *context.RequestContext, userFixture *UserFixture, res *Result) *apperror.AppError {
+	users := make([]*resource.UserResource, 0)
+
+	if userFixture.Username != "" {
+		found, appErr := l.userService.Find(ctx, &resource.UserFindResource{Username: &userFixture.Username})
+
+		if appErr != nil {
+			return appErr
+		}
+
+		users = found
+	}
+
+	if len(users) < 1 {
+		_, appErr := l.userService.Create(ctx, &resource.UserCreateResource{
+			Username:     userFixture.Username,
+			UserTypeName: userFixture.UserType,
+			Disabled:     userFixture.Disabled,
+			Email:        userFixture.Email,
+			DisplayName:  userFixture.DisplayName,
+			Locale:       userFixture.Locale,
+			Timezone:     userFixture.Timezone,
+		})
+
+		if appErr == nil {
+			res.Created++
+		}
+
+		return appErr
+	}
+
+	locale := userFixture.Locale
+	timezone := userFixture.Timezone
+
+	if locale == "" {
+		locale = users[0].Locale
+	}
+
+	if timezone == "" {
+		timezone = users[0].Timezone
+	}
+
+	if users[0].UserType.Name == userFixture.UserType &&
+		users[0].Disabled == userFixture.Disabled &&
+		users[0].Email == strings.ToLower(userFixture.Email) &&
+		users[0].DisplayName == userFixture.DisplayName &&
+		users[0].Locale == locale &&
+		users[0].Timezone == timezone {
+		res.Unchanged++
+
+		return nil
+	}
+
+	_, appErr := l.userService.Update(ctx, &resource.UserUpdateResource{
+		Username:     users[0].Username,
+		UserTypeName: userFixture.UserType,
+		Disabled:     userFixture.Disabled,
+		Email:        userFixture.Email,
+		DisplayName:  userFixture.DisplayName,
+		Locale:       locale,
+		Timezone:     timezone,
+	})
+
+	if appErr == nil {
+		res.Updated++
+	}
+
+	return appErr
+}
+
+// parseFile Files are parsed as JSON or YAML depending on their extension. Unknown fields are rejected, so typos don't
+// go unnoticed.
+func (l *loader) parseFile(path string) (*Fixtures, error) {
+	content, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := &Fixtures{}
+
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		decoder := json.NewDecoder(bytes.NewReader(content))
+
+		decoder.DisallowUnknownFields()
+
+		err = decoder.Decode(fixtures)
+	} else {
+		err = yaml.UnmarshalStrict(content, fixtures)
+	}
+
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("%s: %s", path, err))
+	}
+
+	return fixtures, nil
+}
+
+// Static functions
+
+func NewLoader(
+	appConfig config.AppConfig,
+	logger *zerolog.Logger,
+	requestContextFactory *context.RequestContextFactory,
+	transactionService service.TransactionService,
+	userTypeService service.UserTypeService,
+	userService service.UserService,
+) Loader {
+	return &loader{
+		appConfig:             appConfig,
+		logger:                logger,
+		requestContextFactory: requestContextFactory,
+		transactionService:    transactionService,
+		userTypeService:       userTypeService,
+		userService:           userService,
+	}
+}
+
+func isFixturesFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,291 @@
+package generator
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Constants
+
+const (
+	FieldTypeString   = "string"
+	FieldTypeInt      = "int"
+	FieldTypeInt64    = "int64"
+	FieldTypeFloat64  = "float64"
+	FieldTypeBool     = "bool"
+	FieldTypeTime     = "time"
+	FieldTypeRelation = "relation"
+
+	DefaultRelationKey = "name"
+)
+
+// Variables
+
+var (
+	nameRegexp     = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+	fieldTypes     = []string{FieldTypeString, FieldTypeInt, FieldTypeInt64, FieldTypeFloat64, FieldTypeBool, FieldTypeTime, FieldTypeRelation}
+	reservedFields = []string{"id", "created_at", "updated_at"}
+
+	// reservedVariables Variables of the generated code, which can't be used as field names
+	reservedVariables = []string{"builder", "bindings", "count", "ctx", "err", "filters", "options", "qb", "query", "res", "row", "rows", "sb"}
+)
+
+// Structs
+
+// Definition Entity to generate a CRUD module for. It's loaded from a YAML or JSON file, like:
+//
+//	name: product
+//	key: sku
+//	fields:
+//	  - name: sku
+//	    type: string
+//	    validate: required,min=1,max=50
+//	  - name: price
+//	    type: float64
+//	    validate: min=0
+//	    example: "9.99"
+//	  - name: user_type
+//	    type: relation
+//	    filter: true
+//	    relation:
+//	      entity: user_type
+//	unique_keys:
+//	  - [sku, user_type]
+//
+// The key is the field used to find the entities on the URLs, like the name of the user types. It must be a string,
+// and it's always unique.
+type Definition struct {
+	Name       string             `yaml:"name"`
+	Table      string             `yaml:"table"`
+	Key        string             `yaml:"key"`
+	Fields     []*FieldDefinition `yaml:"fields"`
+	UniqueKeys [][]string         `yaml:"unique_keys"`
+}
+
+// GetTable Returns the table of the entity, which is the plural of its name if it was not set.
+func (d *Definition) GetTable() string {
+	if d.Table != "" {
+		return d.Table
+	}
+
+	return pluralize(d.Name)
+}
+
+// GetField Returns the field with the given name, or nil if the entity doesn't have it.
+func (d *Definition) GetField(name string) *FieldDefinition {
+	for _, field := range d.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+
+	return nil
+}
+
+// GetUniqueKeys Returns every unique key of the entity: the key, the fields marked as unique and the unique keys, in
+// that order and without duplicates.
+func (d *Definition) GetUniqueKeys() [][]string {
+	res := [][]string{{d.Key}}
+	seen := map[string]bool{d.Key: true}
+
+	add := func(fields []string) {
+		id := strings.Join(fields, ",")
+
+		if !seen[id] {
+			seen[id] = true
+			res = append(res, fields)
+		}
+	}
+
+	for _, field := range d.Fields {
+		if field.Unique {
+			add([]string{field.Name})
+		}
+	}
+
+	for _, uniqueKey := range d.UniqueKeys {
+		add(uniqueKey)
+	}
+
+	return res
+}
+
+// Validate Returns an error describing every problem of the definition, or nil if it's valid.
+func (d *Definition) Validate() error {
+	problems := make([]string, 0)
+
+	if !nameRegexp.MatchString(d.Name) {
+		problems = append(problems, fmt.Sprintf("name '%s' must be snake case, like user_type", d.Name))
+	}
+
+	if d.Table != "" && !nameRegexp.MatchString(d.Table) {
+		problems = append(problems, fmt.Sprintf("table '%s' must be snake case", d.Table))
+	}
+
+	if len(d.Fields) < 1 {
+		problems = append(problems, "the entity must have at least one field")
+	}
+
+	names := make(map[string]bool, len(d.Fields))
+	relations := make(map[string]bool)
+
+	for _, field := range d.Fields {
+		if !nameRegexp.MatchString(field.Name) {
+			problems = append(problems, fmt.Sprintf("field name '%s' must be snake case", field.Name))
+		}
+
+		if contains(reservedFields, field.Name) {
+			problems = append(problems, fmt.Sprintf("field '%s' is reserved, it's added to every entity", field.Name))
+		}
+
+		if contains(reservedVariables, field.Name) {
+			problems = append(problems, fmt.Sprintf("field '%s' is reserved, it's used by the generated code", field.Name))
+		}
+
+		if names[field.Name] {
+			problems = append(problems, fmt.Sprintf("field '%s' is defined more than once", field.Name))
+		}
+
+		names[field.Name] = true
+
+		if !contains(fieldTypes, field.Type) {
+			problems = append(problems, fmt.Sprintf("field '%s' has type '%s', which is not one of: %s", field.Name, field.Type, strings.Join(fieldTypes, ", ")))
+		}
+
+		if field.Type == FieldTypeTime && field.Filter {
+			problems = append(problems, fmt.Sprintf("field '%s' is a time, which can't be used as a filter", field.Name))
+		}
+
+		if field.Type == FieldTypeRelation {
+			if field.Relation == nil || !nameRegexp.MatchString(field.Relation.Entity) {
+				problems = append(problems, fmt.Sprintf("field '%s' is a relation, so it must have a relation with a snake case entity", field.Name))
+			} else if relations[field.Relation.Entity] {
+				problems = append(problems, fmt.Sprintf("entity '%s' is related more than once", field.Relation.Entity))
+			} else if field.Relation.Entity == d.Name {
+				problems = append(problems, fmt.Sprintf("field '%s' relates the entity with itself", field.Name))
+			} else {
+				relations[field.Relation.Entity] = true
+			}
+		} else if field.Relation != nil {
+			problems = append(problems, fmt.Sprintf("field '%s' has a relation, so its type must be '%s'", field.Name, FieldTypeRelation))
+		}
+	}
+
+	key := d.GetField(d.Key)
+
+	if key == nil {
+		problems = append(problems, fmt.Sprintf("key '%s' is not one of the fields", d.Key))
+	} else if key.Type != FieldTypeString {
+		problems = append(problems, fmt.Sprintf("key '%s' must be a string", d.Key))
+	}
+
+	for _, uniqueKey := range d.UniqueKeys {
+		hasString := false
+
+		for _, name := range uniqueKey {
+			field := d.GetField(name)
+
+			if field == nil {
+				problems = append(problems, fmt.Sprintf("unique key (%s) has field '%s', which is not defined", strings.Join(uniqueKey, ", "), name))
+			} else if field.Type == FieldTypeString {
+				hasString = true
+			} else if field.Type == FieldTypeTime {
+				problems = append(problems, fmt.Sprintf("unique key (%s) has field '%s', which is a time", strings.Join(uniqueKey, ", "), name))
+			}
+		}
+
+		// The generated tests create several entities, which only differ on their string fields
+
+		if !hasString {
+			problems = append(problems, fmt.Sprintf("unique key (%s) must have at least one string field", strings.Join(uniqueKey, ", ")))
+		}
+	}
+
+	for _, field := range d.Fields {
+		if field.Unique && field.Type != FieldTypeString {
+			problems = append(problems, fmt.Sprintf("field '%s' is unique, so it must be a string", field.Name))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid entity definition: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// FieldDefinition Field of an entity. Validate holds the tags of the validator, and Example is used on the swagger docs
+// and the generated tests.
+type FieldDefinition struct {
+	Name     string              `yaml:"name"`
+	Type     string              `yaml:"type"`
+	Validate string              `yaml:"validate"`
+	Example  string              `yaml:"example"`
+	Unique   bool                `yaml:"unique"`
+	Filter   bool                `yaml:"filter"`
+	Relation *RelationDefinition `yaml:"relation"`
+}
+
+// RelationDefinition Entity a field points to. The entity is set by its key on the resources, and it must register a
+// validation with its name, which stores the entity on the request context, like the user type module does.
+type RelationDefinition struct {
+	Entity string `yaml:"entity"`
+	Key    string `yaml:"key"`
+	Table  string `yaml:"table"`
+}
+
+// GetKey Returns the key of the related entity, which is "name" if it was not set.
+func (r *RelationDefinition) GetKey() string {
+	if r.Key != "" {
+		return r.Key
+	}
+
+	return DefaultRelationKey
+}
+
+// GetTable Returns the table of the related entity, which is the plural of its name if it was not set.
+func (r *RelationDefinition) GetTable() string {
+	if r.Table != "" {
+		return r.Table
+	}
+
+	return pluralize(r.Entity)
+}
+
+// Static functions
+
+// LoadDefinition Loads and validates the definition of the given file. JSON files can be loaded too, since JSON is
+// valid YAML.
+func LoadDefinition(path string) (*Definition, error) {
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	definition := &Definition{}
+
+	if err := yaml.UnmarshalStrict(data, definition); err != nil {
+		return nil, fmt.Errorf("could NOT parse entity definition '%s': %s", path, err)
+	}
+
+	if err := definition.Validate(); err != nil {
+		return nil, err
+	}
+
+	return definition, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,504 @@
+package generator
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Constants
+
+const (
+	DefaultStringLength = 255
+)
+
+// Variables
+
+var (
+	//go:embed templates
+	templates embed.FS
+
+	migrationFileRegexp = regexp.MustCompile(`^(\d+)_.+\.(up|down)\.sql$`)
+	moduleRegexp        = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+	maxLengthRegexp     = regexp.MustCompile(`(?:^|,)max=(\d+)`)
+
+	// files Templates of the Go files, and the file they're written to. %s is replaced by the name of the entity.
+	files = []struct {
+		template string
+		path     string
+	}{
+		{template: "model.go.tmpl", path: "internal/model/%s.go"},
+		{template: "resource.go.tmpl", path: "internal/resource/%s.go"},
+		{template: "find.go.tmpl", path: "internal/repository/utils/%s.go"},
+		{template: "repository.go.tmpl", path: "internal/repository/%s.go"},
+		{template: "events.go.tmpl", path: "internal/events/%s.go"},
+		{template: "service.go.tmpl", path: "internal/service/%s.go"},
+		{template: "controller.go.tmpl", path: "internal/controller/%s.go"},
+		{template: "module.go.tmpl", path: "internal/module/%s.go"},
+		{template: "controller_test.go.tmpl", path: "internal/controller/%s_test.go"},
+	}
+)
+
+// Structs
+
+// Generator Writes every layer of a CRUD module, the same way the user type module is written: model, resource,
+// find filters and options, repository, events, service, controller, module, migrations and controller tests.
+type Generator struct {
+	dir           string
+	migrationsDir string
+	force         bool
+}
+
+// Generate Writes the files of the given entity and returns their paths. Existing files are not overwritten unless
+// the generator was created with force, in which case the migrations of the entity keep their version.
+func (g *Generator) Generate(definition *Definition) ([]string, error) {
+	if err := definition.Validate(); err != nil {
+		return nil, err
+	}
+
+	modulePath, err := g.getModulePath()
+
+	if err != nil {
+		return nil, err
+	}
+
+	data := newEntityData(definition, modulePath)
+	contents := make(map[string][]byte)
+	paths := make([]string, 0, len(files)+2)
+
+	for _, file := range files {
+		content, err := render(file.template, data)
+
+		if err != nil {
+			return nil, err
+		}
+
+		formatted, err := format.Source(content)
+
+		if err != nil {
+			return nil, fmt.Errorf("could NOT format the code generated from template '%s': %s", file.template, err)
+		}
+
+		path := filepath.Join(g.dir, fmt.Sprintf(file.path, definition.Name))
+		contents[path] = formatted
+		paths = append(paths, path)
+	}
+
+	migrationName := "create_" + data.Table
+	version, err := g.findMigrationVersion(migrationName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, direction := range []string{"up", "down"} {
+		content, err := render("migration."+direction+".sql.tmpl", data)
+
+		if err != nil {
+			return nil, err
+		}
+
+		path := filepath.Join(g.migrationsDir, fmt.Sprintf("%d_%s.%s.sql", version, migrationName, direction))
+		contents[path] = content
+		paths = append(paths, path)
+	}
+
+	if !g.force {
+		for _, path := range paths {
+			if _, err := os.Stat(path); err == nil {
+				return nil, fmt.Errorf("file '%s' already exists", path)
+			}
+		}
+	}
+
+	for _, path := range paths {
+		if err := os.MkdirAll(filepath.Dir(path), os.FileMode(0755)); err != nil {
+			return nil, err
+		}
+
+		if err := ioutil.WriteFile(path, contents[path], os.FileMode(0644)); err != nil {
+			return nil, err
+		}
+	}
+
+	return paths, nil
+}
+
+// getModulePath Returns the path of the Go module the files are generated for, so they can import each other.
+func (g *Generator) getModulePath() (string, error) {
+	goMod, err := ioutil.ReadFile(filepath.Join(g.dir, "go.mod"))
+
+	if err != nil {
+		return "", fmt.Errorf("could NOT read the go.mod file of '%s': %s", g.dir, err)
+	}
+
+	matches := moduleRegexp.FindSubmatch(goMod)
+
+	if matches == nil {
+		return "", fmt.Errorf("could NOT find the module path on the go.mod file of '%s'", g.dir)
+	}
+
+	return string(matches[1]), nil
+}
+
+// findMigrationVersion Returns the version of the migration with the given name if it exists and the generator was
+// created with force. Otherwise, it returns the next version.
+func (g *Generator) findMigrationVersion(name string) (int, error) {
+	if g.force {
+		matches, err := filepath.Glob(filepath.Join(g.migrationsDir, fmt.Sprintf("*_%s.up.sql", name)))
+
+		if err != nil {
+			return 0, err
+		}
+
+		for _, match := range matches {
+			if version, err := strconv.Atoi(strings.SplitN(filepath.Base(match), "_", 2)[0]); err == nil {
+				return version, nil
+			}
+		}
+	}
+
+	return NextMigrationVersion(g.migrationsDir)
+}
+
+// entityData Definition of an entity, with everything the templates need already computed.
+type entityData struct {
+	ModulePath   string
+	Name         string
+	Camel        string
+	LowerCamel   string
+	Human        string
+	HumanPlural  string
+	TitlePlural  string
+	Table        string
+	Path         string
+	Key          *fieldData
+	Fields       []*fieldData
+	Filters      []*fieldData
+	Relations    []*fieldData
+	UniqueKeys   []*uniqueKeyData
+	UniqueFields []*fieldData
+	HasTime      bool
+}
+
+// fieldData Field of an entity. Relations are set by the key of the related entity, so their value is the key, like
+// user_type_name, while their column is the ID, like user_type_id.
+type fieldData struct {
+	Name            string
+	Camel           string
+	Human           string
+	Type            string
+	ValueName       string
+	ValueCamel      string
+	ValueLowerCamel string
+	IDCamel         string
+	IDLowerCamel    string
+	GoType          string
+	NullType        string
+	ScanValue       string
+	Column          string
+	SQLType         string
+	Validate        string
+	Example         string
+	TestValue       string
+	IsKey           bool
+	IsString        bool
+	IsTime          bool
+	Relation        *relationData
+}
+
+// relationData Entity a field points to.
+type relationData struct {
+	Entity     string
+	Camel      string
+	LowerCamel string
+	Key        string
+	KeyCamel   string
+	Table      string
+	Alias      string
+	Example    string
+}
+
+type uniqueKeyData struct {
+	Fields      []*fieldData
+	ReportField *fieldData
+}
+
+// Static functions
+
+func NewGenerator(dir string, migrationsDir string, force bool) *Generator {
+	return &Generator{
+		dir:           dir,
+		migrationsDir: migrationsDir,
+		force:         force,
+	}
+}
+
+// NextMigrationVersion Returns the version a new migration of the given directory should have.
+func NextMigrationVersion(dir string) (int, error) {
+	entries, err := ioutil.ReadDir(dir)
+
+	if err != nil {
+		return 0, err
+	}
+
+	version := 0
+
+	for _, entry := range entries {
+		matches := migrationFileRegexp.FindStringSubmatch(entry.Name())
+
+		if matches == nil {
+			continue
+		}
+
+		if fileVersion, _ := strconv.Atoi(matches[1]); fileVersion > version {
+			version = fileVersion
+		}
+	}
+
+	return version + 1, nil
+}
+
+func render(name string, data *entityData) ([]byte, error) {
+	tmpl, err := template.New(name).Funcs(template.FuncMap{"quote": strconv.Quote}).ParseFS(templates, "templates/"+name)
+
+	if err != nil {
+		return nil, fmt.Errorf("could NOT parse template '%s': %s", name, err)
+	}
+
+	buf := &bytes.Buffer{}
+
+	if err := tmpl.Execute(buf, data); err != nil {
+		return nil, fmt.Errorf("could NOT execute template '%s': %s", name, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func newEntityData(definition *Definition, modulePath string) *entityData {
+	data := &entityData{
+		ModulePath:   modulePath,
+		Name:         definition.Name,
+		Camel:        toCamel(definition.Name),
+		LowerCamel:   toLowerCamel(definition.Name),
+		Human:        toHuman(definition.Name),
+		HumanPlural:  toHuman(pluralize(definition.Name)),
+		TitlePlural:  toTitle(pluralize(definition.Name)),
+		Table:        definition.GetTable(),
+		Path:         "/" + definition.Name,
+		Fields:       make([]*fieldData, 0, len(definition.Fields)),
+		Filters:      make([]*fieldData, 0),
+		Relations:    make([]*fieldData, 0),
+		UniqueKeys:   make([]*uniqueKeyData, 0),
+		UniqueFields: make([]*fieldData, 0),
+	}
+	fieldsByName := make(map[string]*fieldData, len(definition.Fields))
+	uniqueFieldNames := make(map[string]bool)
+
+	for _, uniqueKey := range definition.GetUniqueKeys() {
+		for _, name := range uniqueKey {
+			uniqueFieldNames[name] = true
+		}
+	}
+
+	for _, fieldDefinition := range definition.Fields {
+		field := newFieldData(definition, fieldDefinition, uniqueFieldNames[fieldDefinition.Name], len(data.Relations)+1)
+
+		data.Fields = append(data.Fields, field)
+		fieldsByName[field.Name] = field
+
+		if field.IsKey {
+			data.Key = field
+		}
+
+		if field.IsKey || fieldDefinition.Filter {
+			data.Filters = append(data.Filters, field)
+		}
+
+		if field.Relation != nil {
+			data.Relations = append(data.Relations, field)
+		}
+
+		if field.IsTime {
+			data.HasTime = true
+		}
+	}
+
+	for _, field := range data.Fields {
+		if uniqueFieldNames[field.Name] {
+			data.UniqueFields = append(data.UniqueFields, field)
+		}
+	}
+
+	for _, uniqueKey := range definition.GetUniqueKeys() {
+		uniqueKeyData := &uniqueKeyData{}
+
+		for _, name := range uniqueKey {
+			field := fieldsByName[name]
+
+			uniqueKeyData.Fields = append(uniqueKeyData.Fields, field)
+
+			if uniqueKeyData.ReportField == nil && field.IsString {
+				uniqueKeyData.ReportField = field
+			}
+		}
+
+		data.UniqueKeys = append(data.UniqueKeys, uniqueKeyData)
+	}
+
+	return data
+}
+
+func newFieldData(definition *Definition, fieldDefinition *FieldDefinition, unique bool, relationIndex int) *fieldData {
+	field := &fieldData{
+		Name:       fieldDefinition.Name,
+		Camel:      toCamel(fieldDefinition.Name),
+		Human:      toHuman(fieldDefinition.Name),
+		Type:       fieldDefinition.Type,
+		ValueName:  fieldDefinition.Name,
+		Column:     fieldDefinition.Name,
+		Validate:   fieldDefinition.Validate,
+		Example:    fieldDefinition.Example,
+		IsKey:      fieldDefinition.Name == definition.Key,
+		IsString:   fieldDefinition.Type == FieldTypeString,
+		IsTime:     fieldDefinition.Type == FieldTypeTime,
+		ValueCamel: toCamel(fieldDefinition.Name),
+	}
+
+	switch fieldDefinition.Type {
+	case FieldTypeString:
+		length := DefaultStringLength
+
+		if matches := maxLengthRegexp.FindStringSubmatch(field.Validate); matches != nil {
+			length, _ = strconv.Atoi(matches[1])
+		}
+
+		field.GoType = "string"
+		field.NullType = "sql.NullString"
+		field.ScanValue = "%s.String"
+		field.SQLType = fmt.Sprintf("VARCHAR(%d) NOT NULL", length)
+
+		if field.IsKey {
+			if field.Example == "" {
+				field.Example = "test-" + strings.ReplaceAll(definition.Name, "_", "-")
+			}
+
+			if !strings.Contains(","+field.Validate+",", ",required,") {
+				field.Validate = strings.TrimSuffix("required,"+field.Validate, ",")
+			}
+
+			field.TestValue = toLowerCamel(definition.Key)
+		} else {
+			if field.Example == "" {
+				field.Example = "test-" + strings.ReplaceAll(field.Name, "_", "-")
+			}
+
+			field.TestValue = strconv.Quote(field.Example)
+
+			// Entities created by the tests must not break the unique keys
+
+			if unique {
+				field.TestValue += " + \"-\" + " + toLowerCamel(definition.Key)
+			}
+		}
+	case FieldTypeInt, FieldTypeInt64:
+		if field.Example == "" {
+			field.Example = "1"
+		}
+
+		field.GoType = fieldDefinition.Type
+		field.NullType = "sql.NullInt64"
+		field.ScanValue = "%s.Int64"
+		field.SQLType = "INTEGER NOT NULL DEFAULT 0"
+		field.TestValue = field.Example
+
+		if fieldDefinition.Type == FieldTypeInt {
+			field.ScanValue = "int(%s.Int64)"
+		}
+	case FieldTypeFloat64:
+		if field.Example == "" {
+			field.Example = "1.5"
+		}
+
+		field.GoType = "float64"
+		field.NullType = "sql.NullFloat64"
+		field.ScanValue = "%s.Float64"
+		field.SQLType = "REAL NOT NULL DEFAULT 0"
+		field.TestValue = field.Example
+	case FieldTypeBool:
+		if field.Example == "" {
+			field.Example = "false"
+		}
+
+		field.GoType = "bool"
+		field.NullType = "sql.NullBool"
+		field.ScanValue = "%s.Bool"
+		field.SQLType = "TINYINT NOT NULL DEFAULT 0"
+		field.TestValue = field.Example
+	case FieldTypeTime:
+		example, err := time.Parse(time.RFC3339, field.Example)
+
+		if err != nil {
+			example = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		}
+
+		example = example.UTC()
+
+		field.Example = example.Format(time.RFC3339)
+		field.GoType = "time.Time"
+		field.NullType = "sql.NullTime"
+		field.ScanValue = "%s.Time"
+		field.SQLType = "DATETIME NOT NULL"
+		field.TestValue = fmt.Sprintf(
+			"time.Date(%d, %d, %d, %d, %d, %d, 0, time.UTC)",
+			example.Year(), example.Month(), example.Day(), example.Hour(), example.Minute(), example.Second(),
+		)
+	case FieldTypeRelation:
+		relation := fieldDefinition.Relation
+		field.Relation = &relationData{
+			Entity:     relation.Entity,
+			Camel:      toCamel(relation.Entity),
+			LowerCamel: toLowerCamel(relation.Entity),
+			Key:        relation.GetKey(),
+			KeyCamel:   toCamel(relation.GetKey()),
+			Table:      relation.GetTable(),
+			Alias:      fmt.Sprintf("r%d", relationIndex),
+			Example:    field.Example,
+		}
+
+		if field.Relation.Example == "" {
+			field.Relation.Example = "test-" + strings.ReplaceAll(relation.Entity, "_", "-")
+		}
+
+		field.Example = field.Relation.Example
+		field.ValueName = fieldDefinition.Name + "_" + relation.GetKey()
+		field.ValueCamel = toCamel(field.ValueName)
+		field.Column = fieldDefinition.Name + "_id"
+		field.IDCamel = toCamel(field.Column)
+		field.IDLowerCamel = toLowerCamel(field.Column)
+		field.GoType = "string"
+		field.NullType = "sql.NullString"
+		field.ScanValue = "%s.String"
+		field.SQLType = "INTEGER NOT NULL"
+		field.Validate = strings.TrimPrefix(field.Validate+","+relation.Entity, ",")
+		field.TestValue = strconv.Quote(field.Relation.Example)
+
+		if !strings.Contains(","+field.Validate+",", ",required,") {
+			field.Validate = "required," + field.Validate
+		}
+	}
+
+	field.ValueLowerCamel = toLowerCamel(field.ValueName)
+	field.ScanValue = fmt.Sprintf(field.ScanValue, field.ValueLowerCamel)
+
+	return field
+}
@@ -0,0 +1,77 @@
+package generator
+
+import (
+	"go/token"
+	"strings"
+)
+
+// Variables
+
+var (
+	initialisms = map[string]string{"id": "ID", "url": "URL", "uri": "URI", "api": "API", "ip": "IP", "http": "HTTP"}
+)
+
+// Static functions
+
+// toCamel Converts a snake case name to camel case, like user_type to UserType.
+func toCamel(name string) string {
+	res := ""
+
+	for _, part := range strings.Split(name, "_") {
+		if initialism, found := initialisms[part]; found {
+			res += initialism
+		} else if part != "" {
+			res += strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+
+	return res
+}
+
+// toLowerCamel Converts a snake case name to lower camel case, like user_type to userType. Go keywords get a suffix,
+// like type to typeValue, so they can be used as variables.
+func toLowerCamel(name string) string {
+	parts := strings.SplitN(name, "_", 2)
+	res := parts[0]
+
+	if len(parts) > 1 {
+		res += toCamel(parts[1])
+	}
+
+	if token.IsKeyword(res) {
+		res += "Value"
+	}
+
+	return res
+}
+
+// toHuman Converts a snake case name to words, like user_type to "user type".
+func toHuman(name string) string {
+	return strings.ReplaceAll(name, "_", " ")
+}
+
+// toTitle Converts a snake case name to capitalized words, like user_type to "User Type".
+func toTitle(name string) string {
+	words := strings.Split(name, "_")
+
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// pluralize Returns the plural of a snake case name, like user_type to user_types or category to categories.
+func pluralize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsAny(name[len(name)-2:len(name)-1], "aeiou"):
+		return name[:len(name)-1] + "ies"
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "z"),
+		strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	default:
+		return name + "s"
+	}
+}
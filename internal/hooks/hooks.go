@@ -1,12 +1,143 @@
 package hooks
 
+import (
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// Interfaces
+
+// RouterHook Customizes the Gin router, or returns a completely new one. Called before the modules register their
+// routes.
+type RouterHook interface {
+	SetupRouter(router *gin.Engine) *gin.Engine
+}
+
+// ComponentRegistryHook Customizes the component registry, or returns a completely new one. Called before the modules
+// register their components.
+type ComponentRegistryHook interface {
+	SetupComponentRegistry(componentRegistry *componentregistry.ComponentRegistry) *componentregistry.ComponentRegistry
+}
+
+// ValidatorHook Customizes the validator, or returns a completely new one. Called before the modules register their
+// validations.
+type ValidatorHook interface {
+	SetupValidator(v *validator.Validate) *validator.Validate
+}
+
+// LoggerHook Customizes the logger, or returns a completely new one.
+type LoggerHook interface {
+	SetupLogger(logger *zerolog.Logger) *zerolog.Logger
+}
+
 // Structs
 
+// RouterHookFunc Function implementing RouterHook.
+type RouterHookFunc func(router *gin.Engine) *gin.Engine
+
+func (f RouterHookFunc) SetupRouter(router *gin.Engine) *gin.Engine {
+	return f(router)
+}
+
+// ComponentRegistryHookFunc Function implementing ComponentRegistryHook.
+type ComponentRegistryHookFunc func(componentRegistry *componentregistry.ComponentRegistry) *componentregistry.ComponentRegistry
+
+func (f ComponentRegistryHookFunc) SetupComponentRegistry(componentRegistry *componentregistry.ComponentRegistry) *componentregistry.ComponentRegistry {
+	return f(componentRegistry)
+}
+
+// ValidatorHookFunc Function implementing ValidatorHook.
+type ValidatorHookFunc func(v *validator.Validate) *validator.Validate
+
+func (f ValidatorHookFunc) SetupValidator(v *validator.Validate) *validator.Validate {
+	return f(v)
+}
+
+// LoggerHookFunc Function implementing LoggerHook.
+type LoggerHookFunc func(logger *zerolog.Logger) *zerolog.Logger
+
+func (f LoggerHookFunc) SetupLogger(logger *zerolog.Logger) *zerolog.Logger {
+	return f(logger)
+}
+
+// Hooks Customizations of the components created by the app. Hooks of the same kind are called in the order in which
+// they were added, each one receiving the component returned by the previous one.
 type Hooks struct {
+	routerHooks            []RouterHook
+	componentRegistryHooks []ComponentRegistryHook
+	validatorHooks         []ValidatorHook
+	loggerHooks            []LoggerHook
+}
+
+func (h *Hooks) AddRouterHook(hook RouterHook) *Hooks {
+	h.routerHooks = append(h.routerHooks, hook)
+
+	return h
+}
+
+func (h *Hooks) AddComponentRegistryHook(hook ComponentRegistryHook) *Hooks {
+	h.componentRegistryHooks = append(h.componentRegistryHooks, hook)
+
+	return h
+}
+
+func (h *Hooks) AddValidatorHook(hook ValidatorHook) *Hooks {
+	h.validatorHooks = append(h.validatorHooks, hook)
+
+	return h
+}
+
+func (h *Hooks) AddLoggerHook(hook LoggerHook) *Hooks {
+	h.loggerHooks = append(h.loggerHooks, hook)
+
+	return h
+}
+
+// SetupRouter Calls the router hooks.
+func (h *Hooks) SetupRouter(router *gin.Engine) *gin.Engine {
+	for _, hook := range h.routerHooks {
+		router = hook.SetupRouter(router)
+	}
+
+	return router
+}
+
+// SetupComponentRegistry Calls the component registry hooks.
+func (h *Hooks) SetupComponentRegistry(componentRegistry *componentregistry.ComponentRegistry) *componentregistry.ComponentRegistry {
+	for _, hook := range h.componentRegistryHooks {
+		componentRegistry = hook.SetupComponentRegistry(componentRegistry)
+	}
+
+	return componentRegistry
+}
+
+// SetupValidator Calls the validator hooks.
+func (h *Hooks) SetupValidator(v *validator.Validate) *validator.Validate {
+	for _, hook := range h.validatorHooks {
+		v = hook.SetupValidator(v)
+	}
+
+	return v
+}
+
+// SetupLogger Calls the logger hooks.
+func (h *Hooks) SetupLogger(logger *zerolog.Logger) *zerolog.Logger {
+	for _, hook := range h.loggerHooks {
+		logger = hook.SetupLogger(logger)
+	}
+
+	return logger
 }
 
 // Static functions
 
 func NewHooks() *Hooks {
-	return &Hooks{}
+	return &Hooks{
+		routerHooks:            make([]RouterHook, 0),
+		componentRegistryHooks: make([]ComponentRegistryHook, 0),
+		validatorHooks:         make([]ValidatorHook, 0),
+		loggerHooks:            make([]LoggerHook, 0),
+	}
 }
@@ -0,0 +1,280 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	ut "github.com/go-playground/universal-translator"
+	"gopkg.in/go-playground/validator.v9"
+	entranslations "gopkg.in/go-playground/validator.v9/translations/en"
+)
+
+// Constants
+
+const (
+	EnglishLocale = "en"
+	SpanishLocale = "es"
+)
+
+// Structs
+
+// localeTranslator Translator of a single locale. Messages are always added overriding the existing ones, so the
+// validation messages can be registered on more than one validator (like the one used by gin for bindings).
+type localeTranslator struct {
+	ut.Translator
+}
+
+func (t *localeTranslator) Add(key interface{}, text string, override bool) error {
+	return t.Translator.Add(key, text, true)
+}
+
+func (t *localeTranslator) AddCardinal(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return t.Translator.AddCardinal(key, text, rule, true)
+}
+
+func (t *localeTranslator) AddOrdinal(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return t.Translator.AddOrdinal(key, text, rule, true)
+}
+
+func (t *localeTranslator) AddRange(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return t.Translator.AddRange(key, text, rule, true)
+}
+
+// Translator Translators of the supported locales. The one of a request is negotiated from its Accept-Language
+// header, falling back to the default locale.
+type Translator struct {
+	defaultLocale      string
+	locales            []string
+	translators        map[string]*localeTranslator
+	validationMessages map[string]map[string]string
+}
+
+func (t *Translator) GetDefaultLocale() string {
+	return t.defaultLocale
+}
+
+func (t *Translator) GetLocales() []string {
+	return t.locales
+}
+
+// GetTranslator Returns the translator of the preferred locale of the Accept-Language header. Language ranges are
+// tried by their q-value, first with the full tag (like "es-ar") and then with its primary language ("es"). If none
+// of them is supported, the translator of the default locale is returned.
+func (t *Translator) GetTranslator(acceptLanguage string) ut.Translator {
+	for _, tag := range ParseAcceptLanguage(acceptLanguage) {
+		if tag == "*" {
+			break
+		}
+
+		if trans, found := t.translators[tag]; found {
+			return trans
+		}
+
+		if idx := strings.IndexAny(tag, "-_"); idx != -1 {
+			if trans, found := t.translators[tag[:idx]]; found {
+				return trans
+			}
+		}
+	}
+
+	return t.translators[t.defaultLocale]
+}
+
+// AddMessages Adds messages to the catalog of a locale, by key.
+func (t *Translator) AddMessages(locale string, messages map[string]string) error {
+	trans, found := t.translators[locale]
+
+	if !found {
+		return fmt.Errorf("locale '%s' is not supported", locale)
+	}
+
+	for key, message := range messages {
+		if err := trans.Add(key, message, true); err != nil {
+			return fmt.Errorf("could NOT add message '%s' for locale '%s': %s", key, locale, err)
+		}
+	}
+
+	return nil
+}
+
+// Translate Returns the message of key in the given locale (or the default one, if it's not supported), or fallback
+// if it has no message for it.
+func (t *Translator) Translate(locale string, key string, fallback string, params ...string) string {
+	message, err := t.GetTranslator(locale).T(key, params...)
+
+	if err != nil {
+		return fallback
+	}
+
+	return message
+}
+
+// AddValidationMessages Adds the messages of a validation tag, by locale. They are registered on the validators by
+// RegisterValidator, so modules must add them before the validators are set up. Messages receive the field as {0}
+// and the param of the tag as {1}.
+func (t *Translator) AddValidationMessages(tag string, messages map[string]string) {
+	for locale, message := range messages {
+		if _, found := t.validationMessages[locale]; !found {
+			t.validationMessages[locale] = make(map[string]string)
+		}
+
+		t.validationMessages[locale][tag] = message
+	}
+}
+
+// RegisterValidator Registers the messages of the validation tags, for every supported locale, on a validator.
+func (t *Translator) RegisterValidator(validate *validator.Validate) error {
+	for _, locale := range t.locales {
+		trans := t.translators[locale]
+
+		if locale == EnglishLocale {
+			if err := entranslations.RegisterDefaultTranslations(validate, trans); err != nil {
+				return fmt.Errorf("could NOT register the default validation messages for locale '%s': %s", locale, err)
+			}
+		}
+
+		for tag, messages := range groupValidationMessages(validationMessages[locale], t.validationMessages[locale]) {
+			if err := validate.RegisterTranslation(tag, trans, registerValidationMessages(messages), translateValidationError); err != nil {
+				return fmt.Errorf("could NOT register the validation messages of tag '%s' for locale '%s': %s", tag, locale, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Static functions
+
+// NewTranslator Creates the translators of the supported locales. defaultLocale must be one of them.
+func NewTranslator(defaultLocale string) (*Translator, error) {
+	supportedLocales := []locales.Translator{en.New(), es.New()}
+	universalTranslator := ut.New(supportedLocales[0], supportedLocales...)
+	translator := &Translator{
+		defaultLocale:      defaultLocale,
+		locales:            make([]string, 0, len(supportedLocales)),
+		translators:        make(map[string]*localeTranslator),
+		validationMessages: make(map[string]map[string]string),
+	}
+
+	for _, supportedLocale := range supportedLocales {
+		trans, _ := universalTranslator.GetTranslator(supportedLocale.Locale())
+
+		translator.locales = append(translator.locales, supportedLocale.Locale())
+		translator.translators[supportedLocale.Locale()] = &localeTranslator{Translator: trans}
+	}
+
+	if _, found := translator.translators[defaultLocale]; !found {
+		return nil, fmt.Errorf("default locale '%s' is not supported. Supported locales: %s", defaultLocale, strings.Join(translator.locales, ", "))
+	}
+
+	return translator, nil
+}
+
+// ParseAcceptLanguage Returns the language ranges of an Accept-Language header, lowercased and sorted by their
+// q-value. Ranges with a q-value of 0 (or an invalid one) are not acceptable, so they are left out.
+func ParseAcceptLanguage(header string) []string {
+	type languageRange struct {
+		tag     string
+		quality float64
+	}
+
+	ranges := make([]languageRange, 0)
+
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(params[0]))
+		quality := 1.0
+
+		if tag == "" {
+			continue
+		}
+
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+
+			if _, err := fmt.Sscanf(param[2:], "%g", &quality); err != nil || quality > 1 {
+				quality = 0
+			}
+		}
+
+		if quality <= 0 {
+			continue
+		}
+
+		ranges = append(ranges, languageRange{tag: tag, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	res := make([]string, 0, len(ranges))
+
+	for _, r := range ranges {
+		res = append(res, r.tag)
+	}
+
+	return res
+}
+
+// groupValidationMessages Groups the messages of a locale by their tag. Keys of messages which depend on the kind of
+// the field (like "min-string") belong to the tag before the kind suffix.
+func groupValidationMessages(catalogs ...map[string]string) map[string]map[string]string {
+	res := make(map[string]map[string]string)
+
+	for _, catalog := range catalogs {
+		for key, message := range catalog {
+			tag := key
+
+			for _, kind := range []string{kindString, kindNumber, kindItems} {
+				tag = strings.TrimSuffix(tag, "-"+kind)
+			}
+
+			if _, found := res[tag]; !found {
+				res[tag] = make(map[string]string)
+			}
+
+			res[tag][key] = message
+		}
+	}
+
+	return res
+}
+
+func registerValidationMessages(messages map[string]string) validator.RegisterTranslationsFunc {
+	return func(trans ut.Translator) error {
+		for key, message := range messages {
+			if err := trans.Add(key, message, true); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// translateValidationError Uses the message of the tag for the kind of the field, if there's one, or the message of
+// the tag otherwise.
+func translateValidationError(trans ut.Translator, fieldError validator.FieldError) string {
+	keys := []string{fieldError.Tag()}
+
+	if kind := getKind(fieldError); kind != "" {
+		keys = append([]string{fieldError.Tag() + "-" + kind}, keys...)
+	}
+
+	for _, key := range keys {
+		if message, err := trans.T(key, fieldError.Field(), fieldError.Param()); err == nil {
+			return message
+		}
+	}
+
+	return fieldError.(error).Error()
+}
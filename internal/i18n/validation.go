@@ -0,0 +1,88 @@
+package i18n
+
+import (
+	"reflect"
+
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// Constants
+
+const (
+	kindString = "string"
+	kindNumber = "number"
+	kindItems  = "items"
+)
+
+// Variables
+
+// validationMessages Messages of the built-in validation tags, by locale. English ones are provided by the validator.
+// Messages receive the field as {0} and the param of the tag as {1}, in that order.
+var validationMessages = map[string]map[string]string{
+	SpanishLocale: {
+		"required":     "{0} es un campo obligatorio",
+		"len-string":   "{0} debe tener {1} caracteres",
+		"len-number":   "{0} debe ser igual a {1}",
+		"len-items":    "{0} debe contener {1} elementos",
+		"min-string":   "{0} debe tener al menos {1} caracteres",
+		"min-number":   "{0} debe ser {1} o mayor",
+		"min-items":    "{0} debe contener al menos {1} elementos",
+		"max-string":   "{0} debe tener como máximo {1} caracteres",
+		"max-number":   "{0} debe ser {1} o menor",
+		"max-items":    "{0} debe contener como máximo {1} elementos",
+		"eq":           "{0} no es igual a {1}",
+		"ne":           "{0} no debe ser igual a {1}",
+		"lt-string":    "{0} debe tener menos de {1} caracteres",
+		"lt-number":    "{0} debe ser menor que {1}",
+		"lt-items":     "{0} debe contener menos de {1} elementos",
+		"lte-string":   "{0} debe tener como máximo {1} caracteres",
+		"lte-number":   "{0} debe ser {1} o menor",
+		"lte-items":    "{0} debe contener como máximo {1} elementos",
+		"gt-string":    "{0} debe tener más de {1} caracteres",
+		"gt-number":    "{0} debe ser mayor que {1}",
+		"gt-items":     "{0} debe contener más de {1} elementos",
+		"gte-string":   "{0} debe tener al menos {1} caracteres",
+		"gte-number":   "{0} debe ser {1} o mayor",
+		"gte-items":    "{0} debe contener al menos {1} elementos",
+		"eqfield":      "{0} debe ser igual a {1}",
+		"nefield":      "{0} no puede ser igual a {1}",
+		"oneof":        "{0} debe ser uno de [{1}]",
+		"alpha":        "{0} solo puede contener caracteres alfabéticos",
+		"alphanum":     "{0} solo puede contener caracteres alfanuméricos",
+		"numeric":      "{0} debe ser un valor numérico válido",
+		"email":        "{0} debe ser una dirección de correo electrónico válida",
+		"url":          "{0} debe ser una URL válida",
+		"uri":          "{0} debe ser una URI válida",
+		"uuid":         "{0} debe ser un UUID válido",
+		"ip":           "{0} debe ser una dirección IP válida",
+		"contains":     "{0} debe contener el texto '{1}'",
+		"excludes":     "{0} no puede contener el texto '{1}'",
+		"unique":       "{0} debe contener valores únicos",
+		"hexadecimal":  "{0} debe ser un hexadecimal válido",
+		"datauri":      "{0} debe contener un Data URI válido",
+		"base64":       "{0} debe ser una cadena Base64 válida",
+		"ascii":        "{0} solo puede contener caracteres ASCII",
+		"containsany":  "{0} debe contener al menos uno de los siguientes caracteres '{1}'",
+		"excludesall":  "{0} no puede contener ninguno de los siguientes caracteres '{1}'",
+		"excludesrune": "{0} no puede contener lo siguiente '{1}'",
+	},
+}
+
+// Static functions
+
+// getKind Returns the kind of the field of a validation error, as used by the keys of the messages which depend on
+// it. It's empty for the rest of kinds.
+func getKind(fieldError validator.FieldError) string {
+	switch fieldError.Kind() {
+	case reflect.String:
+		return kindString
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return kindItems
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return kindNumber
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,254 @@
+package jobs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Constants
+
+const (
+	EverySchedulePrefix = "@every "
+
+	// cronMaxSearch How far ahead a cron schedule looks for its next time, so impossible dates (like "0 0 31 2 *")
+	// don't loop forever.
+	cronMaxSearch = 5 * 366 * 24 * time.Hour
+)
+
+// Variables
+
+var (
+	cronDescriptors = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// Interfaces
+
+// Schedule Tells when a scheduled job runs.
+type Schedule interface {
+	// Next Returns the first time the job runs after the given one.
+	Next(after time.Time) time.Time
+}
+
+// Structs
+
+// IntervalSchedule Runs the job every interval.
+type IntervalSchedule struct {
+	Interval time.Duration
+}
+
+func (s *IntervalSchedule) Next(after time.Time) time.Time {
+	return after.Add(s.Interval)
+}
+
+func (s *IntervalSchedule) String() string {
+	return EverySchedulePrefix + s.Interval.String()
+}
+
+// CronSchedule Runs the job at the minutes matching a cron expression ("minute hour day-of-month month day-of-week"),
+// in UTC. Like in cron, if both days are restricted, the job runs on the days matching either of them.
+type CronSchedule struct {
+	expression string
+	minutes    uint64
+	hours      uint64
+	days       uint64
+	months     uint64
+	weekdays   uint64
+	anyDay     bool
+	anyWeekday bool
+}
+
+func (s *CronSchedule) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronMaxSearch)
+
+	for t.Before(limit) {
+		if !hasBit(s.months, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+
+			continue
+		}
+
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+
+			continue
+		}
+
+		if !hasBit(s.hours, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+
+			continue
+		}
+
+		if !hasBit(s.minutes, t.Minute()) {
+			t = t.Add(time.Minute)
+
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s *CronSchedule) String() string {
+	return s.expression
+}
+
+func (s *CronSchedule) matchesDay(t time.Time) bool {
+	day := hasBit(s.days, t.Day())
+	weekday := hasBit(s.weekdays, int(t.Weekday()))
+
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
+
+// Static functions
+
+// Every Returns a schedule which runs the job every interval.
+func Every(interval time.Duration) Schedule {
+	return &IntervalSchedule{Interval: interval}
+}
+
+// ParseSchedule Parses a schedule: "@every <duration>" (like "@every 5m"), a cron expression (like "*/15 * * * *") or
+// one of the cron descriptors (@yearly, @monthly, @weekly, @daily, @hourly).
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if strings.HasPrefix(spec, EverySchedulePrefix) {
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, EverySchedulePrefix)))
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule '%s': %s", spec, err)
+		}
+
+		if interval <= 0 {
+			return nil, fmt.Errorf("invalid schedule '%s': the interval must be positive", spec)
+		}
+
+		return Every(interval), nil
+	}
+
+	return ParseCronSchedule(spec)
+}
+
+// ParseCronSchedule Parses a cron expression with five fields. Each one can be "*", a value, a range ("1-5"), a list
+// ("1,15") and have a step ("*/15", "0-30/10"). Sunday is 0 (or 7) on the day of the week.
+func ParseCronSchedule(expression string) (*CronSchedule, error) {
+	fields := strings.Fields(expression)
+
+	if descriptor, ok := cronDescriptors[expression]; ok {
+		fields = strings.Fields(descriptor)
+	}
+
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule '%s': cron expressions must have 5 fields", expression)
+	}
+
+	res := &CronSchedule{
+		expression: expression,
+		anyDay:     fields[2] == "*",
+		anyWeekday: fields[4] == "*",
+	}
+
+	for _, field := range []struct {
+		value  string
+		bits   *uint64
+		minVal int
+		maxVal int
+	}{
+		{value: fields[0], bits: &res.minutes, minVal: 0, maxVal: 59},
+		{value: fields[1], bits: &res.hours, minVal: 0, maxVal: 23},
+		{value: fields[2], bits: &res.days, minVal: 1, maxVal: 31},
+		{value: fields[3], bits: &res.months, minVal: 1, maxVal: 12},
+		{value: fields[4], bits: &res.weekdays, minVal: 0, maxVal: 7},
+	} {
+		bits, err := parseCronField(field.value, field.minVal, field.maxVal)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule '%s': %s", expression, err)
+		}
+
+		*field.bits = bits
+	}
+
+	// Sunday can be written as 7 too
+
+	if hasBit(res.weekdays, 7) {
+		res.weekdays |= 1
+	}
+
+	return res, nil
+}
+
+func parseCronField(field string, minVal int, maxVal int) (uint64, error) {
+	res := uint64(0)
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+
+		if i := strings.Index(part, "/"); i >= 0 {
+			parsedStep, err := strconv.Atoi(part[i+1:])
+
+			if err != nil || parsedStep < 1 {
+				return 0, fmt.Errorf("invalid step in '%s'", part)
+			}
+
+			rangePart, step = part[:i], parsedStep
+		}
+
+		start, end := minVal, maxVal
+
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			parsedStart, err := strconv.Atoi(bounds[0])
+
+			if err != nil {
+				return 0, fmt.Errorf("invalid value in '%s'", part)
+			}
+
+			start, end = parsedStart, parsedStart
+
+			if len(bounds) > 1 {
+				if end, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("invalid value in '%s'", part)
+				}
+			} else if step > 1 {
+				end = maxVal
+			}
+		}
+
+		if start < minVal || end > maxVal || start > end {
+			return 0, fmt.Errorf("'%s' is out of range (%d-%d)", part, minVal, maxVal)
+		}
+
+		for value := start; value <= end; value += step {
+			res |= 1 << uint(value)
+		}
+	}
+
+	return res, nil
+}
+
+func hasBit(bits uint64, value int) bool {
+	return bits&(1<<uint(value)) != 0
+}
@@ -0,0 +1,561 @@
+package jobs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/metrics"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/repository"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/rs/zerolog"
+)
+
+// Constants
+
+const (
+	SchedulerSourceName = "Scheduler"
+	LeaderLockName      = "scheduler"
+	DueJobsBatchSize    = 100
+
+	RunsMetricName     = "job_runs_total"
+	RunResultSucceeded = "succeeded"
+	RunResultRetried   = "retried"
+	RunResultFailed    = "failed"
+
+	DefaultPollInterval   = time.Second
+	DefaultConcurrency    = 4
+	DefaultMaxAttempts    = 5
+	DefaultRetryBaseDelay = 10 * time.Second
+	DefaultRetryMaxDelay  = time.Hour
+	DefaultLockTimeout    = 5 * time.Minute
+	DefaultLeaderLockTTL  = 30 * time.Second
+)
+
+// Structs
+
+// Handler Runs a job. Returning an error (or panicking) makes the job fail.
+type Handler func(ctx *context.RequestContext, job *model.Job) error
+
+// Definition A job registered on the scheduler. Jobs with a Schedule run periodically, and any job can be enqueued.
+type Definition struct {
+	Name    string
+	Handler Handler
+
+	// Schedule Runs the job periodically on the leader instance only. Scheduled runs are not persisted: if one fails,
+	// the job just runs again on its next time. Runs are skipped while the previous one is still running.
+	Schedule Schedule
+
+	// MaxAttempts Attempts of the enqueued jobs, after which they fail. Defaults to the one of the scheduler.
+	MaxAttempts int
+
+	// Concurrency Maximum runs of the job at the same time on each instance. Zero means only the limit of the
+	// scheduler applies.
+	Concurrency int
+}
+
+type Options struct {
+	PollInterval   time.Duration
+	Concurrency    int
+	MaxAttempts    int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	LockTimeout    time.Duration
+	LeaderLockTTL  time.Duration
+}
+
+// Scheduler Background process which runs the scheduled jobs and the enqueued ones every PollInterval, up to
+// Concurrency jobs at the same time. Enqueued jobs are persisted, so every instance of the app can run them. Failed
+// ones are retried with exponential backoff until MaxAttempts is reached. Scheduled jobs only run on the instance
+// holding the leader lock, so they are not run more than once.
+type Scheduler struct {
+	jobRepository         repository.JobRepository
+	jobLockRepository     repository.JobLockRepository
+	requestContextFactory *context.RequestContextFactory
+	timeService           service.TimeService
+	logger                *zerolog.Logger
+	runs                  *metrics.Counter
+	options               Options
+	owner                 string
+	definitions           map[string]*Definition
+	names                 []string
+	nextRunAt             map[string]time.Time
+	running               map[string]int
+	runningCount          int
+	isLeader              bool
+	mutex                 sync.Mutex
+	tickMutex             sync.Mutex
+	wg                    sync.WaitGroup
+	stop                  chan struct{}
+	done                  chan struct{}
+}
+
+// Register Adds a job. Modules register theirs when the app is set up, before it starts.
+func (s *Scheduler) Register(definition Definition) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if definition.Name == "" || definition.Handler == nil {
+		return errors.New("jobs must have a name and a handler")
+	}
+
+	if _, found := s.definitions[definition.Name]; found {
+		return fmt.Errorf("job '%s' was registered more than once", definition.Name)
+	}
+
+	if definition.MaxAttempts < 1 {
+		definition.MaxAttempts = s.options.MaxAttempts
+	}
+
+	s.definitions[definition.Name] = &definition
+	s.names = append(s.names, definition.Name)
+
+	return nil
+}
+
+// GetOwner Returns the identifier of this instance, used to lock the jobs it runs.
+func (s *Scheduler) GetOwner() string {
+	return s.owner
+}
+
+// IsLeader Returns true if this instance held the leader lock on the last tick.
+func (s *Scheduler) IsLeader() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.isLeader
+}
+
+// Enqueue Persists a job to be run as soon as possible. The payload is stored as JSON.
+func (s *Scheduler) Enqueue(ctx *context.RequestContext, name string, payload interface{}) (*model.Job, *apperror.AppError) {
+	return s.EnqueueAt(ctx, name, payload, s.timeService.GetCurrentUtcTime())
+}
+
+// EnqueueAt Persists a job to be run from the given time on. The payload is stored as JSON. If the job is enqueued
+// within a transaction, it only runs if the transaction is committed.
+func (s *Scheduler) EnqueueAt(ctx *context.RequestContext, name string, payload interface{}, runAt time.Time) (*model.Job, *apperror.AppError) {
+	s.mutex.Lock()
+	definition, found := s.definitions[name]
+	s.mutex.Unlock()
+
+	if !found {
+		return nil, apperror.NewAppError(ctx, fmt.Errorf("job '%s' is not registered", name), SchedulerSourceName, apperror.InternalErrorCode, apperror.InternalErrorMessage, nil)
+	}
+
+	payloadJson, err := json.Marshal(payload)
+
+	if err != nil {
+		return nil, apperror.NewAppError(ctx, err, SchedulerSourceName, apperror.InternalErrorCode, apperror.InternalErrorMessage, nil)
+	}
+
+	now := s.timeService.GetCurrentUtcTime()
+	job := model.NewJobBuilder().
+		WithName(name).
+		WithPayload(string(payloadJson)).
+		WithStatus(model.JobStatusPending).
+		WithMaxAttempts(definition.MaxAttempts).
+		WithRunAt(runAt).
+		WithCreatedAt(now).
+		WithUpdatedAt(now).
+		Build()
+
+	if appErr := s.jobRepository.Create(ctx, job); appErr != nil {
+		return nil, appErr
+	}
+
+	return job, nil
+}
+
+// DeleteFinishedBefore Deletes the enqueued jobs which finished (successfully or not) before the given time. Returns
+// the amount of deleted jobs.
+func (s *Scheduler) DeleteFinishedBefore(ctx *context.RequestContext, before time.Time) (int64, *apperror.AppError) {
+	return s.jobRepository.DeleteFinishedBefore(ctx, before)
+}
+
+// Tick Runs the scheduled jobs which are due (if this instance is the leader) and starts the enqueued jobs which are
+// due, up to the concurrency limits. Returns how many runs were started, without waiting for them to finish (see
+// Wait). The background process calls it every PollInterval.
+func (s *Scheduler) Tick() (int, error) {
+	s.tickMutex.Lock()
+	defer s.tickMutex.Unlock()
+
+	ctx := s.requestContextFactory.NewBackgroundRequestContext()
+	now := s.timeService.GetCurrentUtcTime()
+
+	started, err := s.runScheduled(ctx, now)
+
+	if err != nil {
+		return started, err
+	}
+
+	startedEnqueued, err := s.runEnqueued(ctx, now)
+
+	return started + startedEnqueued, err
+}
+
+// Wait Waits for the runs started so far to finish.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// Start Starts ticking every PollInterval, until Stop is called.
+func (s *Scheduler) Start() {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := s.timeService.NewTicker(s.options.PollInterval)
+
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C():
+				if _, err := s.Tick(); err != nil {
+					s.logger.Error().Msgf("[Scheduler] %s", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop Stops ticking (if it was started) and waits for the running jobs to finish. Then, the leader lock is released, so another instance
+// can take over the scheduled jobs right away.
+func (s *Scheduler) Stop() {
+	if s.stop != nil {
+		close(s.stop)
+
+		<-s.done
+
+		s.stop = nil
+	}
+
+	s.Wait()
+
+	s.mutex.Lock()
+	isLeader := s.isLeader
+	s.isLeader = false
+	s.mutex.Unlock()
+
+	if isLeader {
+		ctx := s.requestContextFactory.NewBackgroundRequestContext()
+
+		if appErr := s.jobLockRepository.Release(ctx, LeaderLockName, s.owner); appErr != nil {
+			s.logger.Error().Msgf("[Scheduler] Could NOT release the leader lock: %s", appErr)
+		}
+	}
+}
+
+// runScheduled The first time an instance becomes the leader, the scheduled jobs are not run right away, but on their
+// next time.
+func (s *Scheduler) runScheduled(ctx *context.RequestContext, now time.Time) (int, error) {
+	scheduled := s.getScheduledDefinitions()
+
+	if len(scheduled) < 1 {
+		return 0, nil
+	}
+
+	isLeader, appErr := s.jobLockRepository.Acquire(ctx, LeaderLockName, s.owner, now, now.Add(s.options.LeaderLockTTL))
+
+	if appErr != nil {
+		return 0, fmt.Errorf("could NOT acquire the leader lock: %s", appErr)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if isLeader != s.isLeader {
+		s.logger.Info().Msgf("[Scheduler] Instance '%s' leader: %t.", s.owner, isLeader)
+
+		s.isLeader = isLeader
+		s.nextRunAt = make(map[string]time.Time)
+	}
+
+	if !isLeader {
+		return 0, nil
+	}
+
+	started := 0
+
+	for _, definition := range scheduled {
+		nextRunAt, found := s.nextRunAt[definition.Name]
+
+		if found && nextRunAt.After(now) {
+			continue
+		}
+
+		s.nextRunAt[definition.Name] = definition.Schedule.Next(now)
+
+		if !found {
+			continue
+		}
+
+		if s.running[definition.Name] > 0 || s.runningCount >= s.options.Concurrency {
+			s.logger.Warn().Msgf("[Scheduler] Skipping scheduled run of job '%s', as it's still running or the scheduler is busy.", definition.Name)
+
+			continue
+		}
+
+		s.start(definition, model.NewJobBuilder().
+			WithName(definition.Name).
+			WithStatus(model.JobStatusRunning).
+			WithAttempts(1).
+			WithMaxAttempts(1).
+			WithRunAt(nextRunAt).
+			WithCreatedAt(now).
+			WithUpdatedAt(now).
+			Build(), false)
+
+		started++
+	}
+
+	return started, nil
+}
+
+func (s *Scheduler) runEnqueued(ctx *context.RequestContext, now time.Time) (int, error) {
+	s.mutex.Lock()
+	free := s.options.Concurrency - s.runningCount
+	names := append([]string{}, s.names...)
+	s.mutex.Unlock()
+
+	if free < 1 {
+		return 0, nil
+	}
+
+	jobs, appErr := s.jobRepository.FindDue(ctx, names, now, DueJobsBatchSize)
+
+	if appErr != nil {
+		return 0, fmt.Errorf("could NOT find the due jobs: %s", appErr)
+	}
+
+	started := 0
+
+	for _, job := range jobs {
+		s.mutex.Lock()
+		definition := s.definitions[job.Name]
+		available := s.runningCount < s.options.Concurrency &&
+			(definition.Concurrency < 1 || s.running[definition.Name] < definition.Concurrency)
+		s.mutex.Unlock()
+
+		if !available {
+			continue
+		}
+
+		claimed, appErr := s.jobRepository.Claim(ctx, job, s.owner, now, now.Add(s.options.LockTimeout))
+
+		if appErr != nil {
+			return started, fmt.Errorf("could NOT claim job %d: %s", job.ID, appErr)
+		}
+
+		if !claimed {
+			continue
+		}
+
+		s.mutex.Lock()
+		s.start(definition, job, true)
+		s.mutex.Unlock()
+
+		started++
+	}
+
+	return started, nil
+}
+
+// start Runs the job in the background. Must be called with the mutex locked.
+func (s *Scheduler) start(definition *Definition, job *model.Job, persisted bool) {
+	s.running[definition.Name]++
+	s.runningCount++
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		ctx := s.requestContextFactory.NewBackgroundRequestContext()
+		err := s.call(ctx, definition, job)
+
+		if persisted {
+			s.finish(ctx, job, err)
+		} else if err != nil {
+			s.runs.Inc(job.Name, RunResultFailed)
+
+			s.logger.Error().Msgf("[Scheduler] Scheduled run of job '%s' failed: %s", job.Name, err)
+		} else {
+			s.runs.Inc(job.Name, RunResultSucceeded)
+		}
+
+		s.mutex.Lock()
+		s.running[definition.Name]--
+		s.runningCount--
+		s.mutex.Unlock()
+	}()
+}
+
+func (s *Scheduler) call(ctx *context.RequestContext, definition *Definition, job *model.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return definition.Handler(ctx, job)
+}
+
+// finish Stores the result of the run of an enqueued job. Failed jobs are run again after the backoff, unless they
+// reached their max attempts.
+func (s *Scheduler) finish(ctx *context.RequestContext, job *model.Job, err error) {
+	now := s.timeService.GetCurrentUtcTime()
+	lockedBy := job.LockedBy
+	result := RunResultSucceeded
+
+	job.LastError = ""
+
+	switch {
+	case err == nil:
+		job.Status = model.JobStatusSucceeded
+		job.FinishedAt = &now
+	case job.Attempts < job.MaxAttempts:
+		result = RunResultRetried
+		job.Status = model.JobStatusPending
+		job.LastError = err.Error()
+		job.RunAt = now.Add(s.backoff(job.Attempts))
+	default:
+		result = RunResultFailed
+		job.Status = model.JobStatusFailed
+		job.LastError = err.Error()
+		job.FinishedAt = &now
+	}
+
+	if err != nil {
+		s.logger.Warn().Msgf("[Scheduler] Job %d (%s) failed on attempt %d/%d: %s", job.ID, job.Name, job.Attempts, job.MaxAttempts, err)
+	}
+
+	job.LockedBy = ""
+	job.LockedUntil = nil
+	job.UpdatedAt = now
+
+	s.runs.Inc(job.Name, result)
+
+	updated, appErr := s.jobRepository.Update(ctx, job, lockedBy)
+
+	if appErr != nil {
+		s.logger.Error().Msgf("[Scheduler] Could NOT update job %d (%s): %s", job.ID, job.Name, appErr)
+	} else if !updated {
+		s.logger.Warn().Msgf("[Scheduler] Job %d (%s) ran for longer than its lock, and was claimed again.", job.ID, job.Name)
+	}
+}
+
+func (s *Scheduler) backoff(attempts int) time.Duration {
+	delay := s.options.RetryBaseDelay
+
+	for i := 1; i < attempts && delay < s.options.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > s.options.RetryMaxDelay {
+		delay = s.options.RetryMaxDelay
+	}
+
+	return delay
+}
+
+func (s *Scheduler) getScheduledDefinitions() []*Definition {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	res := make([]*Definition, 0)
+
+	for _, name := range s.names {
+		if definition := s.definitions[name]; definition.Schedule != nil {
+			res = append(res, definition)
+		}
+	}
+
+	return res
+}
+
+// Static functions
+
+// UnmarshalPayload Decodes the JSON payload of the job into v.
+func UnmarshalPayload(job *model.Job, v interface{}) error {
+	return json.Unmarshal([]byte(job.Payload), v)
+}
+
+func NewScheduler(
+	jobRepository repository.JobRepository,
+	jobLockRepository repository.JobLockRepository,
+	requestContextFactory *context.RequestContextFactory,
+	timeService service.TimeService,
+	logger *zerolog.Logger,
+	metricsRegistry *metrics.Registry,
+	options Options,
+) *Scheduler {
+	if options.PollInterval <= 0 {
+		options.PollInterval = DefaultPollInterval
+	}
+
+	if options.Concurrency <= 0 {
+		options.Concurrency = DefaultConcurrency
+	}
+
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = DefaultMaxAttempts
+	}
+
+	if options.RetryBaseDelay <= 0 {
+		options.RetryBaseDelay = DefaultRetryBaseDelay
+	}
+
+	if options.RetryMaxDelay <= 0 {
+		options.RetryMaxDelay = DefaultRetryMaxDelay
+	}
+
+	if options.LockTimeout <= 0 {
+		options.LockTimeout = DefaultLockTimeout
+	}
+
+	if options.LeaderLockTTL <= 0 {
+		options.LeaderLockTTL = DefaultLeaderLockTTL
+	}
+
+	return &Scheduler{
+		jobRepository:         jobRepository,
+		jobLockRepository:     jobLockRepository,
+		requestContextFactory: requestContextFactory,
+		timeService:           timeService,
+		logger:                logger,
+		runs:                  metricsRegistry.Counter(RunsMetricName, "Job runs, by job and result.", "job", "result"),
+		options:               options,
+		owner:                 newOwner(),
+		definitions:           make(map[string]*Definition),
+		names:                 make([]string, 0),
+		nextRunAt:             make(map[string]time.Time),
+		running:               make(map[string]int),
+	}
+}
+
+// newOwner Returns an identifier of this instance, unique even if several instances run on the same host.
+func newOwner() string {
+	hostname, err := os.Hostname()
+
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	suffix := make([]byte, 4)
+
+	_, _ = rand.Read(suffix)
+
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(suffix))
+}
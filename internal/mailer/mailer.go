@@ -0,0 +1,176 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Constants
+
+const (
+	OutboxMailerName = "outbox"
+	LogMailerName    = "log"
+	MemoryMailerName = "memory"
+
+	OutboxFileExtension = ".eml"
+)
+
+// Variables
+
+var (
+	unsafeFileNameCharacters = regexp.MustCompile(`[^a-zA-Z0-9@._-]`)
+)
+
+// Interfaces
+
+// Mailer Sends the emails of the app. Send must return an error if the message could not be sent.
+type Mailer interface {
+	GetName() string
+	Send(ctx context.Context, message *Message) error
+}
+
+// Structs
+
+// Message
+
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+}
+
+// String Returns the message in the format of the files of the outbox.
+func (m *Message) String() string {
+	return fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n", m.From, m.To, m.Subject, m.Body)
+}
+
+// OutboxMailer Writes every message as a file on a local directory, to be read when testing the app offline. Files
+// are named after the time they were sent, so they are listed in order.
+
+type OutboxMailer struct {
+	mutex    sync.Mutex
+	dir      string
+	sequence int
+}
+
+func (m *OutboxMailer) GetName() string {
+	return OutboxMailerName
+}
+
+func (m *OutboxMailer) Send(ctx context.Context, message *Message) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return err
+	}
+
+	m.sequence++
+
+	name := fmt.Sprintf(
+		"%s-%06d-%s%s",
+		time.Now().UTC().Format("20060102T150405.000000000"),
+		m.sequence,
+		unsafeFileNameCharacters.ReplaceAllString(message.To, "_"),
+		OutboxFileExtension,
+	)
+
+	return os.WriteFile(filepath.Join(m.dir, name), []byte(message.String()), 0644)
+}
+
+func (m *OutboxMailer) GetDir() string {
+	return m.dir
+}
+
+// LogMailer Logs every message, including its body.
+
+type LogMailer struct {
+	logger *zerolog.Logger
+}
+
+func (m *LogMailer) GetName() string {
+	return LogMailerName
+}
+
+func (m *LogMailer) Send(ctx context.Context, message *Message) error {
+	m.logger.Info().
+		Str("from", message.From).
+		Str("to", message.To).
+		Str("subject", message.Subject).
+		Str("body", message.Body).
+		Msg("[LogMailer] Email sent.")
+
+	return nil
+}
+
+// MemoryMailer Keeps every message in memory. Useful for tests.
+
+type MemoryMailer struct {
+	mutex    sync.RWMutex
+	messages []*Message
+}
+
+func (m *MemoryMailer) GetName() string {
+	return MemoryMailerName
+}
+
+func (m *MemoryMailer) Send(ctx context.Context, message *Message) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.messages = append(m.messages, message)
+
+	return nil
+}
+
+func (m *MemoryMailer) GetMessages() []*Message {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	res := make([]*Message, len(m.messages))
+
+	copy(res, m.messages)
+
+	return res
+}
+
+// GetLastMessageTo Returns the last message sent to the given address, or nil if there's none.
+func (m *MemoryMailer) GetLastMessageTo(to string) *Message {
+	messages := m.GetMessages()
+
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].To == to {
+			return messages[i]
+		}
+	}
+
+	return nil
+}
+
+// Static functions
+
+func NewOutboxMailer(dir string) *OutboxMailer {
+	return &OutboxMailer{
+		dir: dir,
+	}
+}
+
+func NewLogMailer(logger *zerolog.Logger) *LogMailer {
+	return &LogMailer{
+		logger: logger,
+	}
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{
+		messages: make([]*Message, 0),
+	}
+}
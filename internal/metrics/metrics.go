@@ -0,0 +1,168 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Constants
+
+const (
+	ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+	labelValuesSeparator = "\xff"
+)
+
+// Structs
+
+// Registry Holds the metrics of the app, exposed in the Prometheus text format.
+type Registry struct {
+	mutex    sync.RWMutex
+	counters map[string]*Counter
+}
+
+// Counter Returns the counter with the given name, creating it if it doesn't exist yet. Counters created by several
+// components with the same name are shared.
+func (r *Registry) Counter(name string, help string, labels ...string) *Counter {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if counter, found := r.counters[name]; found {
+		return counter
+	}
+
+	counter := &Counter{
+		name:   name,
+		help:   help,
+		labels: labels,
+		values: make(map[string]float64),
+	}
+
+	r.counters[name] = counter
+
+	return counter
+}
+
+// WriteTo Writes every metric, sorted by name, in the Prometheus text format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mutex.RLock()
+
+	names := make([]string, 0, len(r.counters))
+
+	for name := range r.counters {
+		names = append(names, name)
+	}
+
+	r.mutex.RUnlock()
+
+	sort.Strings(names)
+
+	var written int64
+
+	for _, name := range names {
+		r.mutex.RLock()
+		counter := r.counters[name]
+		r.mutex.RUnlock()
+
+		n, err := counter.writeTo(w)
+
+		written += n
+
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+// Handler Returns the handler of the endpoint scraped by Prometheus.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+
+		_, _ = r.WriteTo(w)
+	})
+}
+
+// Counter
+
+type Counter struct {
+	name   string
+	help   string
+	labels []string
+	mutex  sync.Mutex
+	values map[string]float64
+}
+
+// Inc Increments the counter of the given label values, which must be as many as the labels of the counter.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *Counter) Add(value float64, labelValues ...string) {
+	if len(labelValues) != len(c.labels) {
+		panic(fmt.Sprintf("metric '%s' expects %d label values, got %d", c.name, len(c.labels), len(labelValues)))
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.values[strings.Join(labelValues, labelValuesSeparator)] += value
+}
+
+func (c *Counter) Get(labelValues ...string) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.values[strings.Join(labelValues, labelValuesSeparator)]
+}
+
+func (c *Counter) writeTo(w io.Writer) (int64, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	keys := make([]string, 0, len(c.values))
+
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+
+	for _, key := range keys {
+		sb.WriteString(c.name)
+
+		if len(c.labels) > 0 {
+			pairs := make([]string, 0, len(c.labels))
+
+			for i, labelValue := range strings.Split(key, labelValuesSeparator) {
+				pairs = append(pairs, fmt.Sprintf("%s=%q", c.labels[i], labelValue))
+			}
+
+			sb.WriteString("{" + strings.Join(pairs, ",") + "}")
+		}
+
+		fmt.Fprintf(&sb, " %v\n", c.values[key])
+	}
+
+	n, err := io.WriteString(w, sb.String())
+
+	return int64(n), err
+}
+
+// Static functions
+
+func NewRegistry() *Registry {
+	return &Registry{
+		counters: make(map[string]*Counter),
+	}
+}
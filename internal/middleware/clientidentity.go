@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"crypto/x509"
+
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/gin-gonic/gin"
+)
+
+// Constants
+
+const (
+	ClientIdentityCommonName = "common_name"
+	ClientIdentityEmail      = "email"
+	ClientIdentityURI        = "uri"
+	ClientIdentityDNS        = "dns"
+)
+
+// Static functions
+
+// ClientIdentity Maps the verified TLS client certificate of the request (if any) to an identity, using the given
+// field of the certificate. Unverified certificates are ignored.
+func ClientIdentity(source string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS != nil && len(c.Request.TLS.VerifiedChains) > 0 && len(c.Request.TLS.VerifiedChains[0]) > 0 {
+			if identity := getClientIdentity(c.Request.TLS.VerifiedChains[0][0], source); identity != "" {
+				c.Set(context.ClientIdentityKey, identity)
+			}
+		}
+
+		c.Next()
+	}
+}
+
+func getClientIdentity(certificate *x509.Certificate, source string) string {
+	switch source {
+	case ClientIdentityEmail:
+		if len(certificate.EmailAddresses) > 0 {
+			return certificate.EmailAddresses[0]
+		}
+	case ClientIdentityURI:
+		if len(certificate.URIs) > 0 {
+			return certificate.URIs[0].String()
+		}
+	case ClientIdentityDNS:
+		if len(certificate.DNSNames) > 0 {
+			return certificate.DNSNames[0]
+		}
+	default:
+		return certificate.Subject.CommonName
+	}
+
+	return ""
+}
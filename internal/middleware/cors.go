@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/gin-gonic/gin"
+)
+
+// Constants
+
+const (
+	CorsAllowedMethods = "GET, POST, PUT, DELETE, OPTIONS"
+	CorsAnyOrigin      = "*"
+)
+
+// Variables
+
+var (
+	// CorsExposedHeaders Response headers which can be read by the clients.
+	CorsExposedHeaders = []string{
+		context.RequestIDHeader,
+		IdempotentReplayedHeader,
+		RateLimitLimitHeader,
+		RateLimitRemainingHeader,
+		RateLimitResetHeader,
+		RetryAfterHeader,
+	}
+)
+
+// Static functions
+
+// Cors Adds the CORS headers to the responses of requests made from the allowed origins, and answers their preflight
+// requests. The config is read on every request, so the allowed origins can be reloaded.
+func Cors(runtime *config.Runtime) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+
+		if origin == "" {
+			c.Next()
+
+			return
+		}
+
+		corsConfig := runtime.Get().Cors
+
+		if !isCorsOriginAllowed(corsConfig.AllowedOrigins, origin) {
+			c.Next()
+
+			return
+		}
+
+		c.Header("Access-Control-Allow-Origin", origin)
+		c.Header("Access-Control-Expose-Headers", strings.Join(CorsExposedHeaders, ", "))
+		c.Writer.Header().Add("Vary", "Origin")
+
+		if c.Request.Method != http.MethodOptions || c.GetHeader("Access-Control-Request-Method") == "" {
+			c.Next()
+
+			return
+		}
+
+		c.Header("Access-Control-Allow-Methods", CorsAllowedMethods)
+		c.Header("Access-Control-Allow-Headers", strings.Join(corsConfig.AllowedHeaders, ", "))
+		c.Header("Access-Control-Max-Age", strconv.Itoa(int(corsConfig.MaxAge.Seconds())))
+
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+func isCorsOriginAllowed(allowedOrigins []string, origin string) bool {
+	for _, allowedOrigin := range allowedOrigins {
+		if allowedOrigin == CorsAnyOrigin || strings.EqualFold(allowedOrigin, origin) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -23,12 +23,13 @@ func ErrorHandler(
 		}
 
 		err := detectedErrors[0].Err
+		requestContext := requestContextFactory.NewRequestContext(c)
 
 		languages := c.GetHeader("Accept-Language")
 
-		controllerError := errorHandler.CreateHttpErrorFromErr(requestContextFactory.NewRequestContext(c), err, languages)
+		controllerError := errorHandler.CreateHttpErrorFromErr(requestContext, err, languages)
 
-		c.AbortWithStatusJSON(controllerError.HttpStatus, controllerError)
+		errorHandler.Render(c, requestContext, controllerError)
 
 		return
 	}
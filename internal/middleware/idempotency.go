@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// Constants
+
+const (
+	IdempotencySourceName = "Idempotency"
+
+	IdempotencyKeyHeader          = "Idempotency-Key"
+	IdempotentReplayedHeader      = "Idempotent-Replayed"
+	IdempotentReplayedHeaderValue = "true"
+)
+
+// Structs
+
+type idempotencyWriter struct {
+	gin.ResponseWriter
+
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Static functions
+
+// Idempotency Makes POST requests with an "Idempotency-Key" header safe to retry. The first response is stored by key,
+// route and caller (tenant and actor), and replayed on every retry. Retries with a different body are rejected, as
+// well as retries sent while the first request is still being processed. Failed requests (including the ones which
+// panicked) are not stored, so they can be retried. Bodies bigger than maxBodyBytes are rejected.
+func Idempotency(
+	requestContextFactory *context.RequestContextFactory,
+	idempotencyService service.IdempotencyService,
+	maxBodyBytes int64,
+	logger *zerolog.Logger,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+
+			return
+		}
+
+		requestContext := requestContextFactory.NewRequestContext(c)
+		body, err := ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes))
+
+		if err != nil {
+			c.Error(apperror.NewBindingHttpError(requestContext, err, IdempotencySourceName, nil))
+			c.Abort()
+
+			return
+		}
+
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.Sum256(body)
+		route := c.Request.Method + " " + c.FullPath()
+
+		// Keys are shared by the whole deployment, so the caller includes the tenant. Otherwise, a tenant could replay the
+		// responses of another one.
+
+		caller := requestContext.GetTenant() + "/" + requestContext.GetActor()
+		idempotencyKey, appErr := idempotencyService.Begin(requestContext, key, route, caller, hex.EncodeToString(hash[:]))
+
+		if appErr != nil {
+			c.Error(appErr)
+			c.Abort()
+
+			return
+		}
+
+		if idempotencyKey.IsCompleted() {
+			c.Header(IdempotentReplayedHeader, IdempotentReplayedHeaderValue)
+			c.Data(idempotencyKey.ResponseStatus, idempotencyKey.ResponseContentType, idempotencyKey.ResponseBody)
+			c.Abort()
+
+			return
+		}
+
+		release := func() {
+			if appErr := idempotencyService.Release(requestContext, idempotencyKey); appErr != nil {
+				logger.Error().Msgf("[Idempotency] Could NOT release key '%s': %s", key, appErr)
+			}
+		}
+
+		// If a handler panics, the key is released while the panic goes up to the recovery middleware. Otherwise, it
+		// would stay in progress until the lock timeout.
+
+		finished := false
+
+		defer func() {
+			if !finished {
+				release()
+			}
+		}()
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer}
+
+		c.Writer = writer
+
+		c.Next()
+
+		finished = true
+
+		// Errors are rendered once every handler has returned, so they are never stored. Clients can retry them with
+		// the same key.
+
+		if len(c.Errors) > 0 || writer.Status() >= http.StatusInternalServerError {
+			release()
+
+			return
+		}
+
+		appErr = idempotencyService.Complete(requestContext, idempotencyKey, writer.Status(), writer.Header().Get("Content-Type"), writer.body.Bytes())
+
+		if appErr != nil {
+			logger.Error().Msgf("[Idempotency] Could NOT store the response of key '%s': %s", key, appErr)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// Constants
+
+const (
+	RateLimitSourceName = "RateLimit"
+
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RetryAfterHeader         = "Retry-After"
+	APIKeyHeader             = "X-API-Key"
+)
+
+// Static functions
+
+// RateLimit Limits the requests made to a route group, counting them separately for every client as identified by
+// the key of the policy. Clients are only identified by the API keys on apiKeys (keyed by the name of the client) or by
+// the identity of their verified client certificate, as anyone can send anything else. If the store fails, requests
+// are allowed.
+func RateLimit(
+	requestContextFactory *context.RequestContextFactory,
+	group string,
+	policy ratelimit.Policy,
+	apiKeys map[string]string,
+	limiter ratelimit.Limiter,
+	logger *zerolog.Logger,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestContext := requestContextFactory.NewRequestContext(c)
+		key := group + ":" + getRateLimitKey(requestContext, c, policy.Key, apiKeys)
+
+		result, err := limiter.Allow(key)
+
+		if err != nil {
+			logger.Error().Msgf("[RateLimit] Could NOT check the rate limit of key '%s': %s", key, err)
+
+			c.Next()
+
+			return
+		}
+
+		c.Header(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+		c.Header(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+		c.Header(RateLimitResetHeader, strconv.Itoa(toSeconds(result.Reset)))
+
+		if !result.Allowed {
+			retryAfter := toSeconds(result.RetryAfter)
+
+			c.Header(RetryAfterHeader, strconv.Itoa(retryAfter))
+			c.Error(apperror.NewTooManyRequestsHttpError(requestContext, nil, RateLimitSourceName, map[string]interface{}{
+				"retry_after": retryAfter,
+			}))
+			c.Abort()
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// getRateLimitKey Clients without a known API key, or without a verified client certificate, are identified by their
+// IP.
+func getRateLimitKey(
+	requestContext *context.RequestContext,
+	c *gin.Context,
+	keyKind string,
+	apiKeys map[string]string,
+) string {
+	switch keyKind {
+	case ratelimit.KeyByAPIKey:
+		if client := getAPIKeyClient(c.GetHeader(APIKeyHeader), apiKeys); client != "" {
+			return ratelimit.KeyByAPIKey + ":" + client
+		}
+	case ratelimit.KeyByUser:
+		if identity := requestContext.GetClientIdentity(); identity != "" {
+			return ratelimit.KeyByUser + ":" + identity
+		}
+	}
+
+	return ratelimit.KeyByIP + ":" + c.ClientIP()
+}
+
+// getAPIKeyClient Returns the name of the client of the API key, or an empty string if it's not known.
+func getAPIKeyClient(apiKey string, apiKeys map[string]string) string {
+	if apiKey == "" {
+		return ""
+	}
+
+	for client, knownAPIKey := range apiKeys {
+		if subtle.ConstantTimeCompare([]byte(apiKey), []byte(knownAPIKey)) == 1 {
+			return client
+		}
+	}
+
+	return ""
+}
+
+// toSeconds Rounds up, so clients never retry too early.
+func toSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/errorhandler"
+	"github.com/comfortablynumb/goginrestapi/internal/errorreport"
+	"github.com/comfortablynumb/goginrestapi/internal/metrics"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// Constants
+
+const (
+	RecoverySourceName = "Recovery"
+
+	PanicsMetricName = "http_panics_total"
+)
+
+// Static functions
+
+// Recovery Recovers from the panics of the next handlers, responding with an internal error (with the request id on
+// its data) rendered by the error handler, like the rest of the errors. Panics are logged with their stack, counted
+// by route and sent to the error reporter. If the response was already written, it's just aborted. Panics raised
+// with http.ErrAbortHandler are not recovered, as they are meant to abort the response.
+func Recovery(
+	requestContextFactory *context.RequestContextFactory,
+	errorHandler *errorhandler.ErrorHandler,
+	logger *zerolog.Logger,
+	metricsRegistry *metrics.Registry,
+	reporter *errorreport.Reporter,
+	timeService service.TimeService,
+) gin.HandlerFunc {
+	panics := metricsRegistry.Counter(PanicsMetricName, "Panics recovered while serving requests, by route.", "route")
+
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+
+			if recovered == nil {
+				return
+			}
+
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			err, ok := recovered.(error)
+
+			if !ok {
+				err = fmt.Errorf("%v", recovered)
+			}
+
+			requestContext := requestContextFactory.NewRequestContext(c)
+			stack := string(debug.Stack())
+
+			logger.Error().
+				Str("request_id", requestContext.GetRequestID()).
+				Str("stack", stack).
+				Msgf("[Recovery] Panic recovered on %s %s: %s", c.Request.Method, c.Request.URL.Path, err)
+
+			panics.Inc(c.FullPath())
+
+			reporter.Report(c.Request.Context(), &errorreport.Report{
+				Time:      timeService.GetCurrentUtcTime(),
+				Source:    RecoverySourceName,
+				Message:   err.Error(),
+				Stack:     stack,
+				RequestID: requestContext.GetRequestID(),
+				Method:    c.Request.Method,
+				Route:     c.FullPath(),
+			})
+
+			if c.Writer.Written() {
+				c.Abort()
+
+				return
+			}
+
+			errorHandler.Render(c, requestContext, apperror.NewInternalServerHttpError(requestContext, err, RecoverySourceName, map[string]interface{}{
+				"request_id": requestContext.GetRequestID(),
+			}))
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/gin-gonic/gin"
+)
+
+// Static functions
+
+// RequestID Reuses the request ID sent by the client (if any) or generates a new one, and sends it back on the response.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(context.RequestIDHeader)
+
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+
+		c.Set(context.RequestIDKey, requestID)
+		c.Header(context.RequestIDHeader, requestID)
+
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
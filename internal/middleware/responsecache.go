@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/cache"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/gin-gonic/gin"
+)
+
+// Constants
+
+const (
+	CacheStatusHeader = "X-Cache"
+	CacheStatusHit    = "HIT"
+	CacheStatusMiss   = "MISS"
+)
+
+// Structs
+
+type cachedResponse struct {
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+type responseCacheWriter struct {
+	gin.ResponseWriter
+
+	body bytes.Buffer
+}
+
+func (w *responseCacheWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseCacheWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Static functions
+
+// ResponseCache Caches the successful responses of GET requests, using the tag, the tenant and the request URI as
+// key. The entries are invalidated along with the rest of the entries with the same tag. Clients can skip the cache
+// sending "Cache-Control: no-cache".
+func ResponseCache(responseCache cache.Cache, tag string, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+
+			return
+		}
+
+		key := cache.Key(tag, c.GetString(context.TenantKey), c.Request.URL.RequestURI())
+
+		if c.GetHeader("Cache-Control") != "no-cache" {
+			if value, found := responseCache.Get(key); found {
+				response := &cachedResponse{}
+
+				if err := json.Unmarshal(value, response); err == nil {
+					c.Header(CacheStatusHeader, CacheStatusHit)
+					c.Data(http.StatusOK, response.ContentType, response.Body)
+					c.Abort()
+
+					return
+				}
+			}
+		}
+
+		writer := &responseCacheWriter{ResponseWriter: c.Writer}
+
+		c.Writer = writer
+
+		c.Header(CacheStatusHeader, CacheStatusMiss)
+
+		c.Next()
+
+		if writer.Status() != http.StatusOK || len(c.Errors) > 0 {
+			return
+		}
+
+		value, err := json.Marshal(&cachedResponse{ContentType: writer.Header().Get("Content-Type"), Body: writer.body.Bytes()})
+
+		if err == nil {
+			responseCache.Set(key, value, ttl)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Constants
+
+const (
+	TenantSourceName = "Tenant"
+
+	TenantSourceToken     = "token"
+	TenantSourceHeader    = "header"
+	TenantSourceSubdomain = "subdomain"
+
+	AuthorizationHeader = "Authorization"
+	BearerPrefix        = "Bearer "
+)
+
+// Static functions
+
+// Tenant Resolves the tenant of the request from the configured sources (see config.TenancyConfig) and stores it on
+// the gin context, so every request context created afterwards (and every repository query made with it) belongs to
+// it. Requests with an unknown or disabled tenant, an invalid tenant token, sources resolving different tenants, or
+// without a tenant when there's no default one, are rejected.
+func Tenant(
+	tenancyConfig config.TenancyConfig,
+	requestContextFactory *context.RequestContextFactory,
+	tenantService service.TenantService,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestContext := requestContextFactory.NewRequestContext(c)
+		name := ""
+
+		for _, source := range tenancyConfig.Sources {
+			sourceName, appErr := getTenantFromSource(c, requestContext, tenancyConfig, tenantService, source)
+
+			if appErr != nil {
+				c.Error(appErr)
+				c.Abort()
+
+				return
+			}
+
+			if sourceName == "" {
+				continue
+			}
+
+			if name != "" && name != sourceName {
+				c.Error(apperror.NewInvalidTenantAppError(
+					requestContext,
+					fmt.Errorf("the request belongs to tenants '%s' and '%s' at the same time", name, sourceName),
+					TenantSourceName,
+				))
+				c.Abort()
+
+				return
+			}
+
+			name = sourceName
+		}
+
+		if name == "" {
+			name = tenancyConfig.DefaultTenant
+		}
+
+		if name == "" {
+			c.Error(apperror.NewInvalidTenantAppError(requestContext, errors.New("the request has no tenant"), TenantSourceName))
+			c.Abort()
+
+			return
+		}
+
+		tenant, appErr := tenantService.Resolve(requestContext, name)
+
+		if appErr != nil {
+			c.Error(appErr)
+			c.Abort()
+
+			return
+		}
+
+		c.Set(context.TenantIDKey, tenant.ID)
+		c.Set(context.TenantKey, tenant.Name)
+
+		c.Next()
+	}
+}
+
+// getTenantFromSource Returns the name of the tenant sent on the given source, or an empty string if the request
+// doesn't use it.
+func getTenantFromSource(
+	c *gin.Context,
+	requestContext *context.RequestContext,
+	tenancyConfig config.TenancyConfig,
+	tenantService service.TenantService,
+	source string,
+) (string, *apperror.AppError) {
+	switch source {
+	case TenantSourceToken:
+		authorization := c.GetHeader(AuthorizationHeader)
+
+		if !strings.HasPrefix(authorization, BearerPrefix) {
+			return "", nil
+		}
+
+		return tenantService.VerifyToken(requestContext, strings.TrimSpace(strings.TrimPrefix(authorization, BearerPrefix)))
+	case TenantSourceHeader:
+		return strings.TrimSpace(c.GetHeader(tenancyConfig.Header)), nil
+	case TenantSourceSubdomain:
+		return getSubdomain(c.Request.Host, tenancyConfig.BaseDomain), nil
+	default:
+		return "", nil
+	}
+}
+
+// getSubdomain Returns the label right before the base domain on the host, like "acme" on "acme.example.com:8080".
+func getSubdomain(host string, baseDomain string) string {
+	if baseDomain == "" {
+		return ""
+	}
+
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+
+	host = strings.ToLower(host)
+	suffix := "." + strings.ToLower(strings.TrimPrefix(baseDomain, "."))
+
+	if !strings.HasSuffix(host, suffix) {
+		return ""
+	}
+
+	labels := strings.Split(strings.TrimSuffix(host, suffix), ".")
+
+	return labels[len(labels)-1]
+}
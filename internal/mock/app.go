@@ -10,7 +10,10 @@ import (
 	"strings"
 
 	"github.com/comfortablynumb/goginrestapi/internal/app"
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
 	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/fixtures"
+	"github.com/comfortablynumb/goginrestapi/internal/module"
 	jsoniter "github.com/json-iterator/go"
 )
 
@@ -48,6 +51,24 @@ type MockApp struct {
 	App app.App
 }
 
+// LoadFixtures Loads the fixture files, relative to the directory of the environment of the app (by default, "test").
+func (m *MockApp) LoadFixtures(files ...string) error {
+	appConfig := m.App.GetConfig()
+	loader, err := componentregistry.Get[fixtures.Loader](m.App.GetComponentRegistry(), module.FixturesLoaderComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if _, err := loader.LoadFile(filepath.Join(appConfig.Fixtures.Path, appConfig.Environment, file)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (m *MockApp) NewGetRequest(uri string, options *MockAppOptions) (*httptest.ResponseRecorder, error) {
 	return m.NewRequest(http.MethodGet, uri, options)
 }
@@ -67,7 +88,6 @@ func (m *MockApp) NewDeleteRequest(uri string, options *MockAppOptions) (*httpte
 func (m *MockApp) NewRequest(method string, uri string, options *MockAppOptions) (*httptest.ResponseRecorder, error) {
 	w := httptest.NewRecorder()
 	var bodyReader io.Reader
-	var headers http.Header
 
 	if options == nil {
 		options = NewMockAppOptions()
@@ -75,16 +95,10 @@ func (m *MockApp) NewRequest(method string, uri string, options *MockAppOptions)
 
 	// Headers
 
-	if method != http.MethodGet {
-		contentType := options.Headers.Get("Content-Type")
-
-		if contentType == "" {
-			contentType = "application/json"
-		}
+	headers := options.Headers.Clone()
 
-		headers = make(map[string][]string)
-
-		headers.Add("Content-Type", contentType)
+	if method != http.MethodGet && headers.Get("Content-Type") == "" {
+		headers.Set("Content-Type", "application/json")
 	}
 
 	// Body
@@ -133,33 +147,70 @@ func (m *MockApp) NewRequest(method string, uri string, options *MockAppOptions)
 
 // Static functions
 
-func NewMockApp(config *config.AppConfig) *MockApp {
+// NewMockApp Creates the app with the given config and options, and sets it up.
+func NewMockApp(config *config.AppConfig, options ...app.Option) *MockApp {
 	mockApp := &MockApp{
-		App: app.NewApp(config),
+		App: app.NewApp(config, options...),
 	}
 
-	mockApp.App.SetUp()
+	if err := mockApp.App.SetUp(); err != nil {
+		panic(err)
+	}
 
 	return mockApp
 }
 
 func NewMockAppWithDefaultConfig() *MockApp {
-	return NewMockApp(
-		&config.AppConfig{
-			Port:             8080,
-			LogLevel:         "DEBUG",
-			DbUri:            "file:test.db?cache=shared&mode=memory",
-			DbMigrationsPath: fmt.Sprintf("file://%s", GetMigrationsAbsolutePath()),
-			DefaultLocale:    "en",
-			DefaultLimit:     50,
-		},
-	)
+	return NewMockApp(NewDefaultConfig())
+}
+
+// NewDefaultConfig Returns the config used by NewMockAppWithDefaultConfig, so tests can tweak it before creating the
+// app. Config sections of the modules can be changed with AppConfig.Set.
+func NewDefaultConfig() *config.AppConfig {
+	appConfig, err := NewDefaultConfigLoader().Load()
+
+	if err != nil {
+		panic(err)
+	}
+
+	return appConfig
+}
+
+// NewDefaultConfigLoader Returns the loader of the config used by NewDefaultConfig. Environment variables are ignored,
+// so they can't affect the tests. The tenant header is trusted, so tests can choose the tenant of each request with it.
+func NewDefaultConfigLoader() *config.Loader {
+	return config.NewLoader().
+		WithEnviron([]string{}).
+		WithOverrides(
+			"environment=test",
+			fmt.Sprintf("db.migrations_path=file://%s", GetMigrationsAbsolutePath()),
+			fmt.Sprintf("fixtures.path=%s", GetFixturesAbsolutePath()),
+			"rate_limits=",
+			"cache.max_entries=1000",
+			"cache.repository_ttl=1m",
+			"cache.response_ttl=1m",
+			"webhook.max_attempts=3",
+			"webhook.timeout=5s",
+			"webhook.retry_base_delay=1m",
+			"stream.heartbeat_interval=50ms",
+			"stream.replay_buffer_size=100",
+			"tenancy.sources=token,header",
+		)
 }
 
 // GetMigrationsAbsolutePath We need to determine the migrations path. For this, the only way to do it from the tests
 // is to get the current directory from os.Getwd() and go up until we find the folder which contains "database/migrations"
 // directory.
 func GetMigrationsAbsolutePath() string {
+	return getAbsolutePath("database/migrations")
+}
+
+// GetFixturesAbsolutePath Same as GetMigrationsAbsolutePath, for the "database/fixtures" directory.
+func GetFixturesAbsolutePath() string {
+	return getAbsolutePath("database/fixtures")
+}
+
+func getAbsolutePath(relPath string) string {
 	workingDirectory, err := os.Getwd()
 
 	if err != nil {
@@ -167,10 +218,9 @@ func GetMigrationsAbsolutePath() string {
 	}
 
 	lastDir := workingDirectory
-	migrationsRelPath := "database/migrations"
 
 	for {
-		currentPath := fmt.Sprintf("%s/%s", lastDir, migrationsRelPath)
+		currentPath := fmt.Sprintf("%s/%s", lastDir, relPath)
 
 		fi, err := os.Stat(currentPath)
 
@@ -0,0 +1,235 @@
+package mock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/repository/utils"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+)
+
+// Constants
+
+const (
+	FakeTimeServiceWaitInterval = 5 * time.Millisecond
+)
+
+// Structs
+
+// FakeTimeService TimeService whose clock only moves when Set or Advance are called. Timers and tickers fire (in
+// order) when the clock reaches them. Pass it to NewMockApp with app.WithTimeService.
+type FakeTimeService struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+func (s *FakeTimeService) GetCurrentUtcTime() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.now
+}
+
+func (s *FakeTimeService) NewTimer(duration time.Duration) service.Timer {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.addTimer(duration, 0)
+}
+
+func (s *FakeTimeService) NewTicker(interval time.Duration) service.Ticker {
+	if interval <= 0 {
+		panic("non-positive interval for FakeTimeService.NewTicker")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return &fakeTicker{fakeTimer: s.addTimer(interval, interval)}
+}
+
+// Set Moves the clock to the given time, firing the timers and tickers due until then.
+func (s *FakeTimeService) Set(now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.set(utils.NormalizeTime(now))
+}
+
+// Advance Moves the clock forward by the given duration, firing the timers and tickers due until then.
+func (s *FakeTimeService) Advance(duration time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.set(utils.NormalizeTime(s.now.Add(duration)))
+}
+
+// GetTimerCount Returns how many timers and tickers are waiting to fire.
+func (s *FakeTimeService) GetTimerCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.timers)
+}
+
+// WaitForTimers Waits until at least count timers and tickers are waiting to fire, so the clock isn't advanced before
+// background goroutines create theirs. Returns false if the timeout (in real time) elapses first.
+func (s *FakeTimeService) WaitForTimers(count int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+
+	for s.GetTimerCount() < count {
+		if time.Now().After(deadline) {
+			return false
+		}
+
+		time.Sleep(FakeTimeServiceWaitInterval)
+	}
+
+	return true
+}
+
+func (s *FakeTimeService) set(now time.Time) {
+	for {
+		next := s.getNextTimer(now)
+
+		if next == nil {
+			break
+		}
+
+		if next.deadline.After(s.now) {
+			s.now = next.deadline
+		}
+
+		next.fire(s.now)
+
+		if next.interval > 0 {
+			next.deadline = next.deadline.Add(next.interval)
+		} else {
+			s.removeTimer(next)
+		}
+	}
+
+	s.now = now
+}
+
+// getNextTimer Returns the timer or ticker due first, if it's due until the given time.
+func (s *FakeTimeService) getNextTimer(until time.Time) *fakeTimer {
+	var res *fakeTimer
+
+	for _, timer := range s.timers {
+		if timer.deadline.After(until) {
+			continue
+		}
+
+		if res == nil || timer.deadline.Before(res.deadline) {
+			res = timer
+		}
+	}
+
+	return res
+}
+
+func (s *FakeTimeService) addTimer(duration time.Duration, interval time.Duration) *fakeTimer {
+	timer := &fakeTimer{
+		timeService: s,
+		deadline:    s.now.Add(duration),
+		interval:    interval,
+		channel:     make(chan time.Time, 1),
+	}
+
+	s.timers = append(s.timers, timer)
+
+	// Fire it right away if it's already due
+
+	s.set(s.now)
+
+	return timer
+}
+
+// removeTimer Returns true if the timer was waiting to fire.
+func (s *FakeTimeService) removeTimer(timer *fakeTimer) bool {
+	for i, current := range s.timers {
+		if current == timer {
+			s.timers = append(s.timers[:i], s.timers[i+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// fakeTimer Timer or ticker (if it has an interval) of the FakeTimeService.
+type fakeTimer struct {
+	timeService *FakeTimeService
+	deadline    time.Time
+	interval    time.Duration
+	channel     chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.channel
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.timeService.mutex.Lock()
+	defer t.timeService.mutex.Unlock()
+
+	return t.timeService.removeTimer(t)
+}
+
+// Reset Restarts the timer. Tickers keep the duration as their new interval.
+func (t *fakeTimer) Reset(duration time.Duration) bool {
+	t.timeService.mutex.Lock()
+	defer t.timeService.mutex.Unlock()
+
+	active := t.timeService.removeTimer(t)
+
+	t.deadline = t.timeService.now.Add(duration)
+
+	if t.interval > 0 {
+		t.interval = duration
+	}
+
+	t.timeService.timers = append(t.timeService.timers, t)
+
+	t.timeService.set(t.timeService.now)
+
+	return active
+}
+
+// fire Sends the time on the channel, dropping it if the previous one wasn't received yet (like time.Ticker does).
+func (t *fakeTimer) fire(now time.Time) {
+	select {
+	case t.channel <- now:
+	default:
+	}
+}
+
+// fakeTicker Adapts the fakeTimer to the Ticker interface.
+type fakeTicker struct {
+	*fakeTimer
+}
+
+func (t *fakeTicker) Stop() {
+	t.fakeTimer.Stop()
+}
+
+func (t *fakeTicker) Reset(interval time.Duration) {
+	if interval <= 0 {
+		panic("non-positive interval for fakeTicker.Reset")
+	}
+
+	t.fakeTimer.Reset(interval)
+}
+
+// Static functions
+
+// NewFakeTimeService Returns a FakeTimeService whose clock starts at the given time.
+func NewFakeTimeService(now time.Time) *FakeTimeService {
+	return &FakeTimeService{
+		now:    utils.NormalizeTime(now),
+		timers: make([]*fakeTimer, 0),
+	}
+}
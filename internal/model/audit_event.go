@@ -0,0 +1,114 @@
+package model
+
+import "time"
+
+// Constants
+
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+
+	AuditEntityTypeUser     = "user"
+	AuditEntityTypeUserType = "user_type"
+)
+
+// Structs
+
+type AuditEvent struct {
+	ID         int64
+	Actor      string
+	RequestID  string
+	EntityType string
+	EntityID   int64
+	EntityKey  string
+	Action     string
+	Changes    string
+	CreatedAt  time.Time
+}
+
+type AuditEventBuilder struct {
+	id         int64
+	actor      string
+	requestID  string
+	entityType string
+	entityID   int64
+	entityKey  string
+	action     string
+	changes    string
+	createdAt  time.Time
+}
+
+func (b *AuditEventBuilder) WithID(ID int64) *AuditEventBuilder {
+	b.id = ID
+
+	return b
+}
+
+func (b *AuditEventBuilder) WithActor(actor string) *AuditEventBuilder {
+	b.actor = actor
+
+	return b
+}
+
+func (b *AuditEventBuilder) WithRequestID(requestID string) *AuditEventBuilder {
+	b.requestID = requestID
+
+	return b
+}
+
+func (b *AuditEventBuilder) WithEntityType(entityType string) *AuditEventBuilder {
+	b.entityType = entityType
+
+	return b
+}
+
+func (b *AuditEventBuilder) WithEntityID(entityID int64) *AuditEventBuilder {
+	b.entityID = entityID
+
+	return b
+}
+
+func (b *AuditEventBuilder) WithEntityKey(entityKey string) *AuditEventBuilder {
+	b.entityKey = entityKey
+
+	return b
+}
+
+func (b *AuditEventBuilder) WithAction(action string) *AuditEventBuilder {
+	b.action = action
+
+	return b
+}
+
+func (b *AuditEventBuilder) WithChanges(changes string) *AuditEventBuilder {
+	b.changes = changes
+
+	return b
+}
+
+func (b *AuditEventBuilder) WithCreatedAt(createdAt time.Time) *AuditEventBuilder {
+	b.createdAt = createdAt
+
+	return b
+}
+
+func (b *AuditEventBuilder) Build() *AuditEvent {
+	return &AuditEvent{
+		ID:         b.id,
+		Actor:      b.actor,
+		RequestID:  b.requestID,
+		EntityType: b.entityType,
+		EntityID:   b.entityID,
+		EntityKey:  b.entityKey,
+		Action:     b.action,
+		Changes:    b.changes,
+		CreatedAt:  b.createdAt,
+	}
+}
+
+// Static functions
+
+func NewAuditEventBuilder() *AuditEventBuilder {
+	return &AuditEventBuilder{}
+}
@@ -0,0 +1,135 @@
+package model
+
+import "time"
+
+// Constants
+
+const (
+	IdempotencyKeyStatusInProgress = "in_progress"
+	IdempotencyKeyStatusCompleted  = "completed"
+)
+
+// Structs
+
+// IdempotencyKey The response of a request made with an Idempotency-Key header. While the request is being processed
+// it's in progress, and it has no response yet.
+
+type IdempotencyKey struct {
+	ID                  int64
+	Key                 string
+	Route               string
+	Caller              string
+	RequestHash         string
+	Status              string
+	ResponseStatus      int
+	ResponseContentType string
+	ResponseBody        []byte
+	CreatedAt           time.Time
+	ExpiresAt           time.Time
+}
+
+func (k *IdempotencyKey) IsCompleted() bool {
+	return k.Status == IdempotencyKeyStatusCompleted
+}
+
+type IdempotencyKeyBuilder struct {
+	id                  int64
+	key                 string
+	route               string
+	caller              string
+	requestHash         string
+	status              string
+	responseStatus      int
+	responseContentType string
+	responseBody        []byte
+	createdAt           time.Time
+	expiresAt           time.Time
+}
+
+func (b *IdempotencyKeyBuilder) WithID(ID int64) *IdempotencyKeyBuilder {
+	b.id = ID
+
+	return b
+}
+
+func (b *IdempotencyKeyBuilder) WithKey(key string) *IdempotencyKeyBuilder {
+	b.key = key
+
+	return b
+}
+
+func (b *IdempotencyKeyBuilder) WithRoute(route string) *IdempotencyKeyBuilder {
+	b.route = route
+
+	return b
+}
+
+func (b *IdempotencyKeyBuilder) WithCaller(caller string) *IdempotencyKeyBuilder {
+	b.caller = caller
+
+	return b
+}
+
+func (b *IdempotencyKeyBuilder) WithRequestHash(requestHash string) *IdempotencyKeyBuilder {
+	b.requestHash = requestHash
+
+	return b
+}
+
+func (b *IdempotencyKeyBuilder) WithStatus(status string) *IdempotencyKeyBuilder {
+	b.status = status
+
+	return b
+}
+
+func (b *IdempotencyKeyBuilder) WithResponseStatus(responseStatus int) *IdempotencyKeyBuilder {
+	b.responseStatus = responseStatus
+
+	return b
+}
+
+func (b *IdempotencyKeyBuilder) WithResponseContentType(responseContentType string) *IdempotencyKeyBuilder {
+	b.responseContentType = responseContentType
+
+	return b
+}
+
+func (b *IdempotencyKeyBuilder) WithResponseBody(responseBody []byte) *IdempotencyKeyBuilder {
+	b.responseBody = responseBody
+
+	return b
+}
+
+func (b *IdempotencyKeyBuilder) WithCreatedAt(createdAt time.Time) *IdempotencyKeyBuilder {
+	b.createdAt = createdAt
+
+	return b
+}
+
+func (b *IdempotencyKeyBuilder) WithExpiresAt(expiresAt time.Time) *IdempotencyKeyBuilder {
+	b.expiresAt = expiresAt
+
+	return b
+}
+
+func (b *IdempotencyKeyBuilder) Build() *IdempotencyKey {
+	return &IdempotencyKey{
+		ID:                  b.id,
+		Key:                 b.key,
+		Route:               b.route,
+		Caller:              b.caller,
+		RequestHash:         b.requestHash,
+		Status:              b.status,
+		ResponseStatus:      b.responseStatus,
+		ResponseContentType: b.responseContentType,
+		ResponseBody:        b.responseBody,
+		CreatedAt:           b.createdAt,
+		ExpiresAt:           b.expiresAt,
+	}
+}
+
+// Static functions
+
+func NewIdempotencyKeyBuilder() *IdempotencyKeyBuilder {
+	return &IdempotencyKeyBuilder{}
+}
@@ -0,0 +1,154 @@
+package model
+
+import "time"
+
+// Constants
+
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+)
+
+// Structs
+
+// Job Work enqueued to be run in the background. While it runs, it's locked by the instance running it until
+// LockedUntil, so it's run again if the instance dies. Failed jobs are retried until MaxAttempts is reached.
+type Job struct {
+	ID          int64
+	Name        string
+	Payload     string
+	Status      string
+	Attempts    int
+	MaxAttempts int
+	LastError   string
+	RunAt       time.Time
+	LockedBy    string
+	LockedUntil *time.Time
+	FinishedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (j *Job) IsFinished() bool {
+	return j.Status == JobStatusSucceeded || j.Status == JobStatusFailed
+}
+
+type JobBuilder struct {
+	id          int64
+	name        string
+	payload     string
+	status      string
+	attempts    int
+	maxAttempts int
+	lastError   string
+	runAt       time.Time
+	lockedBy    string
+	lockedUntil *time.Time
+	finishedAt  *time.Time
+	createdAt   time.Time
+	updatedAt   time.Time
+}
+
+func (b *JobBuilder) WithID(ID int64) *JobBuilder {
+	b.id = ID
+
+	return b
+}
+
+func (b *JobBuilder) WithName(name string) *JobBuilder {
+	b.name = name
+
+	return b
+}
+
+func (b *JobBuilder) WithPayload(payload string) *JobBuilder {
+	b.payload = payload
+
+	return b
+}
+
+func (b *JobBuilder) WithStatus(status string) *JobBuilder {
+	b.status = status
+
+	return b
+}
+
+func (b *JobBuilder) WithAttempts(attempts int) *JobBuilder {
+	b.attempts = attempts
+
+	return b
+}
+
+func (b *JobBuilder) WithMaxAttempts(maxAttempts int) *JobBuilder {
+	b.maxAttempts = maxAttempts
+
+	return b
+}
+
+func (b *JobBuilder) WithLastError(lastError string) *JobBuilder {
+	b.lastError = lastError
+
+	return b
+}
+
+func (b *JobBuilder) WithRunAt(runAt time.Time) *JobBuilder {
+	b.runAt = runAt
+
+	return b
+}
+
+func (b *JobBuilder) WithLockedBy(lockedBy string) *JobBuilder {
+	b.lockedBy = lockedBy
+
+	return b
+}
+
+func (b *JobBuilder) WithLockedUntil(lockedUntil *time.Time) *JobBuilder {
+	b.lockedUntil = lockedUntil
+
+	return b
+}
+
+func (b *JobBuilder) WithFinishedAt(finishedAt *time.Time) *JobBuilder {
+	b.finishedAt = finishedAt
+
+	return b
+}
+
+func (b *JobBuilder) WithCreatedAt(createdAt time.Time) *JobBuilder {
+	b.createdAt = createdAt
+
+	return b
+}
+
+func (b *JobBuilder) WithUpdatedAt(updatedAt time.Time) *JobBuilder {
+	b.updatedAt = updatedAt
+
+	return b
+}
+
+func (b *JobBuilder) Build() *Job {
+	return &Job{
+		ID:          b.id,
+		Name:        b.name,
+		Payload:     b.payload,
+		Status:      b.status,
+		Attempts:    b.attempts,
+		MaxAttempts: b.maxAttempts,
+		LastError:   b.lastError,
+		RunAt:       b.runAt,
+		LockedBy:    b.lockedBy,
+		LockedUntil: b.lockedUntil,
+		FinishedAt:  b.finishedAt,
+		CreatedAt:   b.createdAt,
+		UpdatedAt:   b.updatedAt,
+	}
+}
+
+// Static functions
+
+func NewJobBuilder() *JobBuilder {
+	return &JobBuilder{}
+}
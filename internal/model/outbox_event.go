@@ -0,0 +1,135 @@
+package model
+
+import "time"
+
+// Structs
+
+// OutboxEvent Events belong to the tenant of the context which published them. Tenant holds its name.
+type OutboxEvent struct {
+	ID            int64
+	TenantID      int64
+	Tenant        string
+	Name          string
+	Payload       string
+	Actor         string
+	RequestID     string
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	DeliveredAt   *time.Time
+	CreatedAt     time.Time
+}
+
+func (o *OutboxEvent) IsDelivered() bool {
+	return o.DeliveredAt != nil
+}
+
+type OutboxEventBuilder struct {
+	id            int64
+	tenantID      int64
+	tenant        string
+	name          string
+	payload       string
+	actor         string
+	requestID     string
+	attempts      int
+	lastError     string
+	nextAttemptAt time.Time
+	deliveredAt   *time.Time
+	createdAt     time.Time
+}
+
+func (b *OutboxEventBuilder) WithID(ID int64) *OutboxEventBuilder {
+	b.id = ID
+
+	return b
+}
+
+func (b *OutboxEventBuilder) WithTenantID(tenantID int64) *OutboxEventBuilder {
+	b.tenantID = tenantID
+
+	return b
+}
+
+func (b *OutboxEventBuilder) WithTenant(tenant string) *OutboxEventBuilder {
+	b.tenant = tenant
+
+	return b
+}
+
+func (b *OutboxEventBuilder) WithName(name string) *OutboxEventBuilder {
+	b.name = name
+
+	return b
+}
+
+func (b *OutboxEventBuilder) WithPayload(payload string) *OutboxEventBuilder {
+	b.payload = payload
+
+	return b
+}
+
+func (b *OutboxEventBuilder) WithActor(actor string) *OutboxEventBuilder {
+	b.actor = actor
+
+	return b
+}
+
+func (b *OutboxEventBuilder) WithRequestID(requestID string) *OutboxEventBuilder {
+	b.requestID = requestID
+
+	return b
+}
+
+func (b *OutboxEventBuilder) WithAttempts(attempts int) *OutboxEventBuilder {
+	b.attempts = attempts
+
+	return b
+}
+
+func (b *OutboxEventBuilder) WithLastError(lastError string) *OutboxEventBuilder {
+	b.lastError = lastError
+
+	return b
+}
+
+func (b *OutboxEventBuilder) WithNextAttemptAt(nextAttemptAt time.Time) *OutboxEventBuilder {
+	b.nextAttemptAt = nextAttemptAt
+
+	return b
+}
+
+func (b *OutboxEventBuilder) WithDeliveredAt(deliveredAt *time.Time) *OutboxEventBuilder {
+	b.deliveredAt = deliveredAt
+
+	return b
+}
+
+func (b *OutboxEventBuilder) WithCreatedAt(createdAt time.Time) *OutboxEventBuilder {
+	b.createdAt = createdAt
+
+	return b
+}
+
+func (b *OutboxEventBuilder) Build() *OutboxEvent {
+	return &OutboxEvent{
+		ID:            b.id,
+		TenantID:      b.tenantID,
+		Tenant:        b.tenant,
+		Name:          b.name,
+		Payload:       b.payload,
+		Actor:         b.actor,
+		RequestID:     b.requestID,
+		Attempts:      b.attempts,
+		LastError:     b.lastError,
+		NextAttemptAt: b.nextAttemptAt,
+		DeliveredAt:   b.deliveredAt,
+		CreatedAt:     b.createdAt,
+	}
+}
+
+// Static functions
+
+func NewOutboxEventBuilder() *OutboxEventBuilder {
+	return &OutboxEventBuilder{}
+}
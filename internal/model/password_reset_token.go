@@ -0,0 +1,82 @@
+package model
+
+import "time"
+
+// Structs
+
+// PasswordResetToken Token sent to a user to choose a new password. Only its hash is stored. It can be used once,
+// until it expires.
+type PasswordResetToken struct {
+	ID        int64
+	UserID    int64
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+func (t *PasswordResetToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
+
+type PasswordResetTokenBuilder struct {
+	id        int64
+	userID    int64
+	tokenHash string
+	expiresAt time.Time
+	usedAt    *time.Time
+	createdAt time.Time
+}
+
+func (b *PasswordResetTokenBuilder) WithID(ID int64) *PasswordResetTokenBuilder {
+	b.id = ID
+
+	return b
+}
+
+func (b *PasswordResetTokenBuilder) WithUserID(userID int64) *PasswordResetTokenBuilder {
+	b.userID = userID
+
+	return b
+}
+
+func (b *PasswordResetTokenBuilder) WithTokenHash(tokenHash string) *PasswordResetTokenBuilder {
+	b.tokenHash = tokenHash
+
+	return b
+}
+
+func (b *PasswordResetTokenBuilder) WithExpiresAt(expiresAt time.Time) *PasswordResetTokenBuilder {
+	b.expiresAt = expiresAt
+
+	return b
+}
+
+func (b *PasswordResetTokenBuilder) WithUsedAt(usedAt *time.Time) *PasswordResetTokenBuilder {
+	b.usedAt = usedAt
+
+	return b
+}
+
+func (b *PasswordResetTokenBuilder) WithCreatedAt(createdAt time.Time) *PasswordResetTokenBuilder {
+	b.createdAt = createdAt
+
+	return b
+}
+
+func (b *PasswordResetTokenBuilder) Build() *PasswordResetToken {
+	return &PasswordResetToken{
+		ID:        b.id,
+		UserID:    b.userID,
+		TokenHash: b.tokenHash,
+		ExpiresAt: b.expiresAt,
+		UsedAt:    b.usedAt,
+		CreatedAt: b.createdAt,
+	}
+}
+
+// Static functions
+
+func NewPasswordResetTokenBuilder() *PasswordResetTokenBuilder {
+	return &PasswordResetTokenBuilder{}
+}
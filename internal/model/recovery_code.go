@@ -0,0 +1,73 @@
+package model
+
+import "time"
+
+// Structs
+
+// RecoveryCode Code a user can log in with instead of a TOTP code, if they lose their second factor. Only its hash is
+// stored. It can be used once.
+type RecoveryCode struct {
+	ID        int64
+	UserID    int64
+	CodeHash  string
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+func (c *RecoveryCode) IsUsed() bool {
+	return c.UsedAt != nil
+}
+
+type RecoveryCodeBuilder struct {
+	id        int64
+	userID    int64
+	codeHash  string
+	usedAt    *time.Time
+	createdAt time.Time
+}
+
+func (b *RecoveryCodeBuilder) WithID(ID int64) *RecoveryCodeBuilder {
+	b.id = ID
+
+	return b
+}
+
+func (b *RecoveryCodeBuilder) WithUserID(userID int64) *RecoveryCodeBuilder {
+	b.userID = userID
+
+	return b
+}
+
+func (b *RecoveryCodeBuilder) WithCodeHash(codeHash string) *RecoveryCodeBuilder {
+	b.codeHash = codeHash
+
+	return b
+}
+
+func (b *RecoveryCodeBuilder) WithUsedAt(usedAt *time.Time) *RecoveryCodeBuilder {
+	b.usedAt = usedAt
+
+	return b
+}
+
+func (b *RecoveryCodeBuilder) WithCreatedAt(createdAt time.Time) *RecoveryCodeBuilder {
+	b.createdAt = createdAt
+
+	return b
+}
+
+func (b *RecoveryCodeBuilder) Build() *RecoveryCode {
+	return &RecoveryCode{
+		ID:        b.id,
+		UserID:    b.userID,
+		CodeHash:  b.codeHash,
+		UsedAt:    b.usedAt,
+		CreatedAt: b.createdAt,
+	}
+}
+
+// Static functions
+
+func NewRecoveryCodeBuilder() *RecoveryCodeBuilder {
+	return &RecoveryCodeBuilder{}
+}
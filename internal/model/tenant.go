@@ -0,0 +1,69 @@
+package model
+
+import "time"
+
+// Structs
+
+// Tenant Organization served by the app. Users, user types and their audit events belong to one, and can't be seen
+// from any other. Requests to disabled tenants are rejected.
+type Tenant struct {
+	ID        int64
+	Name      string
+	Disabled  bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type TenantBuilder struct {
+	id        int64
+	name      string
+	disabled  bool
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+func (b *TenantBuilder) WithID(ID int64) *TenantBuilder {
+	b.id = ID
+
+	return b
+}
+
+func (b *TenantBuilder) WithName(name string) *TenantBuilder {
+	b.name = name
+
+	return b
+}
+
+func (b *TenantBuilder) WithDisabled(disabled bool) *TenantBuilder {
+	b.disabled = disabled
+
+	return b
+}
+
+func (b *TenantBuilder) WithCreatedAt(createdAt time.Time) *TenantBuilder {
+	b.createdAt = createdAt
+
+	return b
+}
+
+func (b *TenantBuilder) WithUpdatedAt(updatedAt time.Time) *TenantBuilder {
+	b.updatedAt = updatedAt
+
+	return b
+}
+
+func (b *TenantBuilder) Build() *Tenant {
+	return &Tenant{
+		ID:        b.id,
+		Name:      b.name,
+		Disabled:  b.disabled,
+		CreatedAt: b.createdAt,
+		UpdatedAt: b.updatedAt,
+	}
+}
+
+// Static functions
+
+func NewTenantBuilder() *TenantBuilder {
+	return &TenantBuilder{}
+}
@@ -4,13 +4,63 @@ import "time"
 
 // Structs
 
+// User EmailVerifiedAt is nil until the current email of the user is verified. Users without PasswordHash can't log
+// in. LockedUntil is set when the user fails to log in too many times in a row. TotpSecret is set when the user starts
+// enrolling a TOTP second factor, and TotpEnabledAt once the enrollment is confirmed. TotpLastUsedStep is the time step
+// of the last TOTP code used, so codes can't be used twice. Users belong to the tenant of their type, and their
+// usernames and emails are unique per tenant.
 type User struct {
-	ID        int64
-	Username  string
-	UserType  UserType
-	Disabled  bool
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID                  int64
+	TenantID            int64
+	Username            string
+	UserType            UserType
+	Disabled            bool
+	Email               string
+	DisplayName         string
+	Locale              string
+	Timezone            string
+	EmailVerifiedAt     *time.Time
+	PasswordHash        string
+	PasswordChangedAt   *time.Time
+	FailedLoginAttempts int
+	LockedUntil         *time.Time
+	TotpSecret          string
+	TotpEnabledAt       *time.Time
+	TotpLastUsedStep    int64
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+func (u *User) HasPassword() bool {
+	return u.PasswordHash != ""
+}
+
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// ClearExpiredLockout Clears the lockout of the user if it has already expired. Returns true if it was cleared.
+func (u *User) ClearExpiredLockout(now time.Time) bool {
+	if u.LockedUntil == nil || u.IsLocked(now) {
+		return false
+	}
+
+	u.LockedUntil = nil
+
+	return true
+}
+
+func (u *User) IsMfaEnabled() bool {
+	return u.TotpEnabledAt != nil
+}
+
+// IsMfaRequired Users must log in with a second factor if they enabled it, or if their user type requires it.
+func (u *User) IsMfaRequired() bool {
+	return u.IsMfaEnabled() || u.UserType.RequiresMfa
 }
 
 func (u *User) SetUserType(userType UserType) {
@@ -18,12 +68,25 @@ func (u *User) SetUserType(userType UserType) {
 }
 
 type UserBuilder struct {
-	id        int64
-	username  string
-	userType  UserType
-	disabled  bool
-	createdAt time.Time
-	updatedAt time.Time
+	id                  int64
+	tenantID            int64
+	username            string
+	userType            UserType
+	disabled            bool
+	email               string
+	displayName         string
+	locale              string
+	timezone            string
+	emailVerifiedAt     *time.Time
+	passwordHash        string
+	passwordChangedAt   *time.Time
+	failedLoginAttempts int
+	lockedUntil         *time.Time
+	totpSecret          string
+	totpEnabledAt       *time.Time
+	totpLastUsedStep    int64
+	createdAt           time.Time
+	updatedAt           time.Time
 }
 
 func (b *UserBuilder) WithID(ID int64) *UserBuilder {
@@ -32,6 +95,12 @@ func (b *UserBuilder) WithID(ID int64) *UserBuilder {
 	return b
 }
 
+func (b *UserBuilder) WithTenantID(tenantID int64) *UserBuilder {
+	b.tenantID = tenantID
+
+	return b
+}
+
 func (b *UserBuilder) WithUsername(username string) *UserBuilder {
 	b.username = username
 
@@ -50,6 +119,78 @@ func (b *UserBuilder) WithDisabled(disabled bool) *UserBuilder {
 	return b
 }
 
+func (b *UserBuilder) WithEmail(email string) *UserBuilder {
+	b.email = email
+
+	return b
+}
+
+func (b *UserBuilder) WithDisplayName(displayName string) *UserBuilder {
+	b.displayName = displayName
+
+	return b
+}
+
+func (b *UserBuilder) WithLocale(locale string) *UserBuilder {
+	b.locale = locale
+
+	return b
+}
+
+func (b *UserBuilder) WithTimezone(timezone string) *UserBuilder {
+	b.timezone = timezone
+
+	return b
+}
+
+func (b *UserBuilder) WithEmailVerifiedAt(emailVerifiedAt *time.Time) *UserBuilder {
+	b.emailVerifiedAt = emailVerifiedAt
+
+	return b
+}
+
+func (b *UserBuilder) WithPasswordHash(passwordHash string) *UserBuilder {
+	b.passwordHash = passwordHash
+
+	return b
+}
+
+func (b *UserBuilder) WithPasswordChangedAt(passwordChangedAt *time.Time) *UserBuilder {
+	b.passwordChangedAt = passwordChangedAt
+
+	return b
+}
+
+func (b *UserBuilder) WithFailedLoginAttempts(failedLoginAttempts int) *UserBuilder {
+	b.failedLoginAttempts = failedLoginAttempts
+
+	return b
+}
+
+func (b *UserBuilder) WithLockedUntil(lockedUntil *time.Time) *UserBuilder {
+	b.lockedUntil = lockedUntil
+
+	return b
+}
+
+func (b *UserBuilder) WithTotpSecret(totpSecret string) *UserBuilder {
+	b.totpSecret = totpSecret
+
+	return b
+}
+
+func (b *UserBuilder) WithTotpEnabledAt(totpEnabledAt *time.Time) *UserBuilder {
+	b.totpEnabledAt = totpEnabledAt
+
+	return b
+}
+
+func (b *UserBuilder) WithTotpLastUsedStep(totpLastUsedStep int64) *UserBuilder {
+	b.totpLastUsedStep = totpLastUsedStep
+
+	return b
+}
+
 func (b *UserBuilder) WithCreatedAt(createdAt time.Time) *UserBuilder {
 	b.createdAt = createdAt
 
@@ -64,12 +205,25 @@ func (b *UserBuilder) WithUpdatedAt(updatedAt time.Time) *UserBuilder {
 
 func (b *UserBuilder) Build() *User {
 	return &User{
-		ID:        b.id,
-		Username:  b.username,
-		UserType:  b.userType,
-		Disabled:  b.disabled,
-		CreatedAt: b.createdAt,
-		UpdatedAt: b.updatedAt,
+		ID:                  b.id,
+		TenantID:            b.tenantID,
+		Username:            b.username,
+		UserType:            b.userType,
+		Disabled:            b.disabled,
+		Email:               b.email,
+		DisplayName:         b.displayName,
+		Locale:              b.locale,
+		Timezone:            b.timezone,
+		EmailVerifiedAt:     b.emailVerifiedAt,
+		PasswordHash:        b.passwordHash,
+		PasswordChangedAt:   b.passwordChangedAt,
+		FailedLoginAttempts: b.failedLoginAttempts,
+		LockedUntil:         b.lockedUntil,
+		TotpSecret:          b.totpSecret,
+		TotpEnabledAt:       b.totpEnabledAt,
+		TotpLastUsedStep:    b.totpLastUsedStep,
+		CreatedAt:           b.createdAt,
+		UpdatedAt:           b.updatedAt,
 	}
 }
 
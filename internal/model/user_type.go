@@ -10,20 +10,25 @@ type EntityWithUserType interface {
 
 // Structs
 
+// UserType Users of types with RequiresMfa must log in with a second factor. Names are unique per tenant.
 type UserType struct {
-	ID        int64
-	Name      string
-	Disabled  bool
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID          int64
+	TenantID    int64
+	Name        string
+	Disabled    bool
+	RequiresMfa bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 }
 
 type UserTypeBuilder struct {
-	id        int64
-	name      string
-	disabled  bool
-	createdAt time.Time
-	updatedAt time.Time
+	id          int64
+	tenantID    int64
+	name        string
+	disabled    bool
+	requiresMfa bool
+	createdAt   time.Time
+	updatedAt   time.Time
 }
 
 func (b *UserTypeBuilder) WithID(ID int64) *UserTypeBuilder {
@@ -32,6 +37,12 @@ func (b *UserTypeBuilder) WithID(ID int64) *UserTypeBuilder {
 	return b
 }
 
+func (b *UserTypeBuilder) WithTenantID(tenantID int64) *UserTypeBuilder {
+	b.tenantID = tenantID
+
+	return b
+}
+
 func (b *UserTypeBuilder) WithName(name string) *UserTypeBuilder {
 	b.name = name
 
@@ -44,6 +55,12 @@ func (b *UserTypeBuilder) WithDisabled(disabled bool) *UserTypeBuilder {
 	return b
 }
 
+func (b *UserTypeBuilder) WithRequiresMfa(requiresMfa bool) *UserTypeBuilder {
+	b.requiresMfa = requiresMfa
+
+	return b
+}
+
 func (b *UserTypeBuilder) WithCreatedAt(createdAt time.Time) *UserTypeBuilder {
 	b.createdAt = createdAt
 
@@ -58,11 +75,13 @@ func (b *UserTypeBuilder) WithUpdatedAt(updatedAt time.Time) *UserTypeBuilder {
 
 func (b *UserTypeBuilder) Build() *UserType {
 	return &UserType{
-		ID:        b.id,
-		Name:      b.name,
-		Disabled:  b.disabled,
-		CreatedAt: b.createdAt,
-		UpdatedAt: b.updatedAt,
+		ID:          b.id,
+		TenantID:    b.tenantID,
+		Name:        b.name,
+		Disabled:    b.disabled,
+		RequiresMfa: b.requiresMfa,
+		CreatedAt:   b.createdAt,
+		UpdatedAt:   b.updatedAt,
 	}
 }
 
@@ -0,0 +1,136 @@
+package model
+
+import "time"
+
+// Constants
+
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusSucceeded = "succeeded"
+	WebhookDeliveryStatusFailed    = "failed"
+)
+
+// Structs
+
+// WebhookDelivery
+
+type WebhookDelivery struct {
+	ID             int64
+	SubscriptionID int64
+	EventID        int64
+	EventName      string
+	Payload        string
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	AttemptLog     []*WebhookDeliveryAttempt
+}
+
+type WebhookDeliveryBuilder struct {
+	id             int64
+	subscriptionID int64
+	eventID        int64
+	eventName      string
+	payload        string
+	status         string
+	attempts       int
+	nextAttemptAt  time.Time
+	createdAt      time.Time
+	updatedAt      time.Time
+}
+
+func (b *WebhookDeliveryBuilder) WithID(ID int64) *WebhookDeliveryBuilder {
+	b.id = ID
+
+	return b
+}
+
+func (b *WebhookDeliveryBuilder) WithSubscriptionID(subscriptionID int64) *WebhookDeliveryBuilder {
+	b.subscriptionID = subscriptionID
+
+	return b
+}
+
+func (b *WebhookDeliveryBuilder) WithEventID(eventID int64) *WebhookDeliveryBuilder {
+	b.eventID = eventID
+
+	return b
+}
+
+func (b *WebhookDeliveryBuilder) WithEventName(eventName string) *WebhookDeliveryBuilder {
+	b.eventName = eventName
+
+	return b
+}
+
+func (b *WebhookDeliveryBuilder) WithPayload(payload string) *WebhookDeliveryBuilder {
+	b.payload = payload
+
+	return b
+}
+
+func (b *WebhookDeliveryBuilder) WithStatus(status string) *WebhookDeliveryBuilder {
+	b.status = status
+
+	return b
+}
+
+func (b *WebhookDeliveryBuilder) WithAttempts(attempts int) *WebhookDeliveryBuilder {
+	b.attempts = attempts
+
+	return b
+}
+
+func (b *WebhookDeliveryBuilder) WithNextAttemptAt(nextAttemptAt time.Time) *WebhookDeliveryBuilder {
+	b.nextAttemptAt = nextAttemptAt
+
+	return b
+}
+
+func (b *WebhookDeliveryBuilder) WithCreatedAt(createdAt time.Time) *WebhookDeliveryBuilder {
+	b.createdAt = createdAt
+
+	return b
+}
+
+func (b *WebhookDeliveryBuilder) WithUpdatedAt(updatedAt time.Time) *WebhookDeliveryBuilder {
+	b.updatedAt = updatedAt
+
+	return b
+}
+
+func (b *WebhookDeliveryBuilder) Build() *WebhookDelivery {
+	return &WebhookDelivery{
+		ID:             b.id,
+		SubscriptionID: b.subscriptionID,
+		EventID:        b.eventID,
+		EventName:      b.eventName,
+		Payload:        b.payload,
+		Status:         b.status,
+		Attempts:       b.attempts,
+		NextAttemptAt:  b.nextAttemptAt,
+		CreatedAt:      b.createdAt,
+		UpdatedAt:      b.updatedAt,
+		AttemptLog:     make([]*WebhookDeliveryAttempt, 0),
+	}
+}
+
+// WebhookDeliveryAttempt
+
+type WebhookDeliveryAttempt struct {
+	ID         int64
+	DeliveryID int64
+	Attempt    int
+	StatusCode int
+	Error      string
+	Duration   time.Duration
+	CreatedAt  time.Time
+}
+
+// Static functions
+
+func NewWebhookDeliveryBuilder() *WebhookDeliveryBuilder {
+	return &WebhookDeliveryBuilder{}
+}
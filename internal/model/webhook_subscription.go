@@ -0,0 +1,117 @@
+package model
+
+import "time"
+
+// Constants
+
+const (
+	WebhookAllEventTypes = "*"
+)
+
+// Structs
+
+type WebhookSubscription struct {
+	ID         int64
+	TenantID   int64
+	URL        string
+	EventTypes []string
+	Secret     string
+	Disabled   bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// IsSubscribedTo Returns true if the subscription is enabled and wants to receive events with the given name.
+func (w *WebhookSubscription) IsSubscribedTo(eventName string) bool {
+	if w.Disabled {
+		return false
+	}
+
+	for _, eventType := range w.EventTypes {
+		if eventType == WebhookAllEventTypes || eventType == eventName {
+			return true
+		}
+	}
+
+	return false
+}
+
+type WebhookSubscriptionBuilder struct {
+	id         int64
+	tenantID   int64
+	url        string
+	eventTypes []string
+	secret     string
+	disabled   bool
+	createdAt  time.Time
+	updatedAt  time.Time
+}
+
+func (b *WebhookSubscriptionBuilder) WithID(ID int64) *WebhookSubscriptionBuilder {
+	b.id = ID
+
+	return b
+}
+
+func (b *WebhookSubscriptionBuilder) WithTenantID(tenantID int64) *WebhookSubscriptionBuilder {
+	b.tenantID = tenantID
+
+	return b
+}
+
+func (b *WebhookSubscriptionBuilder) WithURL(url string) *WebhookSubscriptionBuilder {
+	b.url = url
+
+	return b
+}
+
+func (b *WebhookSubscriptionBuilder) WithEventTypes(eventTypes []string) *WebhookSubscriptionBuilder {
+	b.eventTypes = eventTypes
+
+	return b
+}
+
+func (b *WebhookSubscriptionBuilder) WithSecret(secret string) *WebhookSubscriptionBuilder {
+	b.secret = secret
+
+	return b
+}
+
+func (b *WebhookSubscriptionBuilder) WithDisabled(disabled bool) *WebhookSubscriptionBuilder {
+	b.disabled = disabled
+
+	return b
+}
+
+func (b *WebhookSubscriptionBuilder) WithCreatedAt(createdAt time.Time) *WebhookSubscriptionBuilder {
+	b.createdAt = createdAt
+
+	return b
+}
+
+func (b *WebhookSubscriptionBuilder) WithUpdatedAt(updatedAt time.Time) *WebhookSubscriptionBuilder {
+	b.updatedAt = updatedAt
+
+	return b
+}
+
+func (b *WebhookSubscriptionBuilder) Build() *WebhookSubscription {
+	return &WebhookSubscription{
+		ID:         b.id,
+		TenantID:   b.tenantID,
+		URL:        b.url,
+		EventTypes: b.eventTypes,
+		Secret:     b.secret,
+		Disabled:   b.disabled,
+		CreatedAt:  b.createdAt,
+		UpdatedAt:  b.updatedAt,
+	}
+}
+
+// Static functions
+
+func NewWebhookSubscriptionBuilder() *WebhookSubscriptionBuilder {
+	return &WebhookSubscriptionBuilder{
+		eventTypes: make([]string, 0),
+	}
+}
@@ -0,0 +1,94 @@
+package module
+
+import (
+	"context"
+
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/controller"
+	"github.com/comfortablynumb/goginrestapi/internal/errorhandler"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/jobs"
+	"github.com/comfortablynumb/goginrestapi/internal/repository"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// Constants
+
+const (
+	AuditModuleName                   = "audit"
+	AuditEventRepositoryComponentName = "AuditEventRepository"
+	AuditServiceComponentName         = "AuditService"
+	AuditControllerComponentName      = "AuditController"
+)
+
+// Structs
+
+type AuditModule struct {
+}
+
+func (m *AuditModule) GetName() string {
+	return AuditModuleName
+}
+
+func (m *AuditModule) GetDependencies() []string {
+	return nil
+}
+
+func (m *AuditModule) SetUpComponents(
+	appConfig config.AppConfig,
+	errorHandler *errorhandler.ErrorHandler,
+	componentRegistry *componentregistry.ComponentRegistry,
+) error {
+	repo := repository.NewAuditEventRepository(appConfig, componentRegistry.Db, componentRegistry.Logger)
+	serv := service.NewAuditService(
+		appConfig,
+		componentRegistry.Logger,
+		componentRegistry.Validator,
+		componentRegistry.TimeService,
+		repo,
+	)
+	cont := controller.NewAuditController(serv, componentRegistry.RequestContextFactory)
+
+	componentRegistry.Set(AuditEventRepositoryComponentName, repo).
+		Set(AuditServiceComponentName, serv).
+		Set(AuditControllerComponentName, cont)
+
+	return nil
+}
+
+func (m *AuditModule) SetUpRouter(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, router *gin.Engine) error {
+	auditController, err := componentregistry.Get[*controller.AuditController](componentRegistry, AuditControllerComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	audit := router.Group("/audit", componentRegistry.GetRateLimiter(AuditModuleName))
+
+	audit.GET("", auditController.Find)
+
+	return nil
+}
+
+func (m *AuditModule) SetUpValidator(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, validator *validator.Validate) error {
+	return nil
+}
+
+func (m *AuditModule) SetUpEventSubscribers(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, bus *events.Bus) error {
+	return nil
+}
+
+func (m *AuditModule) SetUpJobs(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, scheduler *jobs.Scheduler) error {
+	return nil
+}
+
+func (m *AuditModule) OnStart(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
+}
+
+func (m *AuditModule) OnStop(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
+}
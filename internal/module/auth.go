@@ -0,0 +1,158 @@
+package module
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	context2 "github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/controller"
+	"github.com/comfortablynumb/goginrestapi/internal/errorhandler"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/jobs"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/repository"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// Constants
+
+const (
+	AuthModuleName                            = "auth"
+	PasswordResetTokenRepositoryComponentName = "PasswordResetTokenRepository"
+	RecoveryCodeRepositoryComponentName       = "RecoveryCodeRepository"
+	AuthServiceComponentName                  = "AuthService"
+	AuthControllerComponentName               = "AuthController"
+	AuthTokenPurgeJobName                     = "auth.purge_expired_tokens"
+)
+
+// Structs
+
+type AuthModule struct {
+	purgeSchedule string
+}
+
+func (m *AuthModule) GetName() string {
+	return AuthModuleName
+}
+
+func (m *AuthModule) GetDependencies() []string {
+	return []string{UserModuleName, AuditModuleName}
+}
+
+func (m *AuthModule) SetUpComponents(
+	appConfig config.AppConfig,
+	errorHandler *errorhandler.ErrorHandler,
+	componentRegistry *componentregistry.ComponentRegistry,
+) error {
+	auditService, err := componentregistry.Get[service.AuditService](componentRegistry, AuditServiceComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	userRepository, err := componentregistry.Get[repository.UserRepository](componentRegistry, UserRepositoryComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	m.purgeSchedule = appConfig.Auth.PurgeSchedule
+
+	repo := repository.NewPasswordResetTokenRepository(appConfig, componentRegistry.Db, componentRegistry.Logger)
+	recoveryCodeRepository := repository.NewRecoveryCodeRepository(appConfig, componentRegistry.Db, componentRegistry.Logger)
+	serv := service.NewAuthService(
+		appConfig,
+		componentRegistry.Logger,
+		componentRegistry.Validator,
+		componentRegistry.TimeService,
+		componentRegistry.TransactionService,
+		auditService,
+		componentRegistry.EventService,
+		componentRegistry.CacheService,
+		userRepository,
+		repo,
+		recoveryCodeRepository,
+		componentRegistry.Translator,
+		componentRegistry.Mailer,
+	)
+
+	for locale, messages := range service.AuthMessages {
+		if err := componentRegistry.Translator.AddMessages(locale, messages); err != nil {
+			return fmt.Errorf("could NOT add the auth messages: %s", err)
+		}
+	}
+
+	cont := controller.NewAuthController(serv, componentRegistry.RequestContextFactory)
+
+	componentRegistry.Set(PasswordResetTokenRepositoryComponentName, repo).
+		Set(RecoveryCodeRepositoryComponentName, recoveryCodeRepository).
+		Set(AuthServiceComponentName, serv).
+		Set(AuthControllerComponentName, cont)
+
+	return nil
+}
+
+func (m *AuthModule) SetUpRouter(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, router *gin.Engine) error {
+	authController, err := componentregistry.Get[*controller.AuthController](componentRegistry, AuthControllerComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	auth := router.Group("/auth", componentRegistry.GetRateLimiter(AuthModuleName))
+
+	auth.POST("/login", authController.Login)
+	auth.POST("/password-reset/request", authController.RequestPasswordReset)
+	auth.POST("/password-reset/confirm", authController.ConfirmPasswordReset)
+	auth.POST("/mfa/enroll", authController.EnrollMfa)
+	auth.POST("/mfa/confirm", authController.ConfirmMfa)
+
+	return nil
+}
+
+func (m *AuthModule) SetUpValidator(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, validator *validator.Validate) error {
+	return nil
+}
+
+func (m *AuthModule) SetUpEventSubscribers(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, bus *events.Bus) error {
+	return nil
+}
+
+// SetUpJobs Password reset tokens which can't be used anymore are deleted on the purge schedule.
+func (m *AuthModule) SetUpJobs(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, scheduler *jobs.Scheduler) error {
+	authService, err := componentregistry.Get[service.AuthService](componentRegistry, AuthServiceComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	schedule, err := jobs.ParseSchedule(m.purgeSchedule)
+
+	if err != nil {
+		return fmt.Errorf("invalid auth.purge_schedule: %s", err)
+	}
+
+	return scheduler.Register(jobs.Definition{
+		Name:     AuthTokenPurgeJobName,
+		Schedule: schedule,
+		Handler: func(ctx *context2.RequestContext, job *model.Job) error {
+			if _, err := authService.DeleteExpiredTokens(ctx); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	})
+}
+
+func (m *AuthModule) OnStart(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
+}
+
+func (m *AuthModule) OnStop(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
+}
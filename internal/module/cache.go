@@ -0,0 +1,78 @@
+package module
+
+import (
+	"context"
+
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/controller"
+	"github.com/comfortablynumb/goginrestapi/internal/errorhandler"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/jobs"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// Constants
+
+const (
+	CacheModuleName              = "cache"
+	CacheControllerComponentName = "CacheController"
+)
+
+// Structs
+
+type CacheModule struct {
+}
+
+func (m *CacheModule) GetName() string {
+	return CacheModuleName
+}
+
+func (m *CacheModule) GetDependencies() []string {
+	return nil
+}
+
+func (m *CacheModule) SetUpComponents(
+	appConfig config.AppConfig,
+	errorHandler *errorhandler.ErrorHandler,
+	componentRegistry *componentregistry.ComponentRegistry,
+) error {
+	cont := controller.NewCacheController(componentRegistry.CacheService, componentRegistry.RequestContextFactory)
+
+	componentRegistry.Set(CacheControllerComponentName, cont)
+
+	return nil
+}
+
+func (m *CacheModule) SetUpRouter(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, router *gin.Engine) error {
+	cacheController, err := componentregistry.Get[*controller.CacheController](componentRegistry, CacheControllerComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	router.GET("/cache/stats", componentRegistry.GetRateLimiter(CacheModuleName), cacheController.Stats)
+
+	return nil
+}
+
+func (m *CacheModule) SetUpValidator(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, validator *validator.Validate) error {
+	return nil
+}
+
+func (m *CacheModule) SetUpEventSubscribers(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, bus *events.Bus) error {
+	return nil
+}
+
+func (m *CacheModule) SetUpJobs(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, scheduler *jobs.Scheduler) error {
+	return nil
+}
+
+func (m *CacheModule) OnStart(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
+}
+
+func (m *CacheModule) OnStop(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
+}
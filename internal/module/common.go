@@ -1,9 +1,13 @@
 package module
 
 import (
+	"context"
+
 	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
 	"github.com/comfortablynumb/goginrestapi/internal/config"
 	"github.com/comfortablynumb/goginrestapi/internal/errorhandler"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/jobs"
 	"github.com/gin-gonic/gin"
 	"gopkg.in/go-playground/validator.v9"
 )
@@ -12,7 +16,73 @@ import (
 
 type Module interface {
 	GetName() string
-	SetUpComponents(appConfig config.AppConfig, errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry)
-	SetUpRouter(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, router *gin.Engine)
-	SetUpValidator(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, validator *validator.Validate)
+
+	// GetDependencies Returns the names of the modules which must be set up before this one, usually because their
+	// components are used by it.
+	GetDependencies() []string
+
+	SetUpComponents(appConfig config.AppConfig, errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry) error
+	SetUpRouter(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, router *gin.Engine) error
+	SetUpValidator(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, validator *validator.Validate) error
+	SetUpEventSubscribers(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, bus *events.Bus) error
+
+	// SetUpJobs Registers the background jobs of the module: the scheduled ones, and the ones it enqueues.
+	SetUpJobs(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, scheduler *jobs.Scheduler) error
+
+	// OnStart Called in order when the app starts running, before it serves requests. Start background work here.
+	OnStart(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error
+
+	// OnStop Called in reverse order when the app stops, after the servers shut down. Stop background work here.
+	OnStop(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error
+}
+
+// ConfigurableModule Module with its own config section, named after the module. GetConfig returns a pointer to the
+// section, declared with the same tags as config.AppConfig, which is loaded and validated before the module sets up
+// its components.
+type ConfigurableModule interface {
+	Module
+	GetConfig() interface{}
+}
+
+// Structs
+
+// BaseModule Implements every method of Module but GetName, doing nothing. Embed it in modules which only need some of
+// them.
+type BaseModule struct {
+}
+
+func (m *BaseModule) GetDependencies() []string {
+	return nil
+}
+
+func (m *BaseModule) SetUpComponents(
+	appConfig config.AppConfig,
+	errorHandler *errorhandler.ErrorHandler,
+	componentRegistry *componentregistry.ComponentRegistry,
+) error {
+	return nil
+}
+
+func (m *BaseModule) SetUpRouter(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, router *gin.Engine) error {
+	return nil
+}
+
+func (m *BaseModule) SetUpValidator(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, validator *validator.Validate) error {
+	return nil
+}
+
+func (m *BaseModule) SetUpEventSubscribers(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, bus *events.Bus) error {
+	return nil
+}
+
+func (m *BaseModule) SetUpJobs(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, scheduler *jobs.Scheduler) error {
+	return nil
+}
+
+func (m *BaseModule) OnStart(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
+}
+
+func (m *BaseModule) OnStop(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
 }
@@ -0,0 +1,92 @@
+package module
+
+import (
+	"context"
+
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/errorhandler"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/fixtures"
+	"github.com/comfortablynumb/goginrestapi/internal/jobs"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// Constants
+
+const (
+	FixturesModuleName          = "fixtures"
+	FixturesLoaderComponentName = "FixturesLoader"
+)
+
+// Structs
+
+// FixturesModule Provides the loader used by the "seed" command and the tests. It must be added after the modules
+// whose services it uses.
+type FixturesModule struct {
+}
+
+func (m *FixturesModule) GetName() string {
+	return FixturesModuleName
+}
+
+func (m *FixturesModule) GetDependencies() []string {
+	return []string{UserTypeModuleName, UserModuleName}
+}
+
+func (m *FixturesModule) SetUpComponents(
+	appConfig config.AppConfig,
+	errorHandler *errorhandler.ErrorHandler,
+	componentRegistry *componentregistry.ComponentRegistry,
+) error {
+	userTypeService, err := componentregistry.Get[service.UserTypeService](componentRegistry, UserTypeServiceComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	userService, err := componentregistry.Get[service.UserService](componentRegistry, UserServiceComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	loader := fixtures.NewLoader(
+		appConfig,
+		componentRegistry.Logger,
+		componentRegistry.RequestContextFactory,
+		componentRegistry.TransactionService,
+		userTypeService,
+		userService,
+	)
+
+	componentRegistry.Set(FixturesLoaderComponentName, loader)
+
+	return nil
+}
+
+func (m *FixturesModule) SetUpRouter(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, router *gin.Engine) error {
+	return nil
+}
+
+func (m *FixturesModule) SetUpValidator(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, validator *validator.Validate) error {
+	return nil
+}
+
+func (m *FixturesModule) SetUpEventSubscribers(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, bus *events.Bus) error {
+	return nil
+}
+
+func (m *FixturesModule) SetUpJobs(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, scheduler *jobs.Scheduler) error {
+	return nil
+}
+
+func (m *FixturesModule) OnStart(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
+}
+
+func (m *FixturesModule) OnStop(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
+}
@@ -0,0 +1,115 @@
+package module
+
+import (
+	"context"
+
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	context2 "github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/errorhandler"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/jobs"
+	"github.com/comfortablynumb/goginrestapi/internal/middleware"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/repository"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// Constants
+
+const (
+	IdempotencyModuleName                 = "idempotency"
+	IdempotencyKeyRepositoryComponentName = "IdempotencyKeyRepository"
+	IdempotencyServiceComponentName       = "IdempotencyService"
+	IdempotencyMiddlewareComponentName    = "IdempotencyMiddleware"
+	IdempotencyKeyPurgeJobName            = "idempotency.purge_expired_keys"
+)
+
+// Structs
+
+// IdempotencyModule Provides the middleware other modules use to make their POST routes idempotent.
+type IdempotencyModule struct {
+	config service.IdempotencyConfig
+}
+
+func (m *IdempotencyModule) GetName() string {
+	return IdempotencyModuleName
+}
+
+func (m *IdempotencyModule) GetDependencies() []string {
+	return nil
+}
+
+func (m *IdempotencyModule) GetConfig() interface{} {
+	return &m.config
+}
+
+func (m *IdempotencyModule) SetUpComponents(
+	appConfig config.AppConfig,
+	errorHandler *errorhandler.ErrorHandler,
+	componentRegistry *componentregistry.ComponentRegistry,
+) error {
+	repo := repository.NewIdempotencyKeyRepository(appConfig, componentRegistry.Db, componentRegistry.Logger)
+	serv := service.NewIdempotencyService(
+		appConfig,
+		m.config,
+		componentRegistry.Logger,
+		componentRegistry.Validator,
+		componentRegistry.TimeService,
+		repo,
+	)
+
+	componentRegistry.Set(IdempotencyKeyRepositoryComponentName, repo).
+		Set(IdempotencyServiceComponentName, serv).
+		Set(IdempotencyMiddlewareComponentName, middleware.Idempotency(
+			componentRegistry.RequestContextFactory,
+			serv,
+			m.config.MaxBodyBytes,
+			componentRegistry.Logger,
+		))
+
+	return nil
+}
+
+func (m *IdempotencyModule) SetUpRouter(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, router *gin.Engine) error {
+	return nil
+}
+
+func (m *IdempotencyModule) SetUpValidator(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, validator *validator.Validate) error {
+	return nil
+}
+
+func (m *IdempotencyModule) SetUpEventSubscribers(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, bus *events.Bus) error {
+	return nil
+}
+
+// SetUpJobs Expired keys are deleted every GCInterval.
+func (m *IdempotencyModule) SetUpJobs(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, scheduler *jobs.Scheduler) error {
+	idempotencyService, err := componentregistry.Get[service.IdempotencyService](componentRegistry, IdempotencyServiceComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	return scheduler.Register(jobs.Definition{
+		Name:     IdempotencyKeyPurgeJobName,
+		Schedule: jobs.Every(m.config.GCInterval),
+		Handler: func(ctx *context2.RequestContext, job *model.Job) error {
+			if _, err := idempotencyService.DeleteExpired(ctx); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	})
+}
+
+func (m *IdempotencyModule) OnStart(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
+}
+
+func (m *IdempotencyModule) OnStop(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
+}
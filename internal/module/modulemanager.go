@@ -1,5 +1,10 @@
 package module
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Structs
 
 type ModuleManager struct {
@@ -16,6 +21,71 @@ func (m *ModuleManager) AddModule(module Module) *ModuleManager {
 	return m
 }
 
+// Sort Orders the modules so every module comes after its dependencies. Otherwise, the order in which they were added
+// is kept. Duplicated modules, dependencies on modules which were not added and dependency cycles are returned as
+// errors, so the app fails before setting up any module.
+func (m *ModuleManager) Sort() error {
+	modulesByName := make(map[string]Module, len(m.Modules))
+
+	for _, module := range m.Modules {
+		if _, found := modulesByName[module.GetName()]; found {
+			return fmt.Errorf("module '%s' was added more than once", module.GetName())
+		}
+
+		modulesByName[module.GetName()] = module
+	}
+
+	sorted := make([]Module, 0, len(m.Modules))
+	visited := make(map[string]bool, len(m.Modules))
+	path := make([]string, 0)
+
+	var visit func(module Module) error
+
+	visit = func(module Module) error {
+		name := module.GetName()
+
+		for i, pathName := range path {
+			if pathName == name {
+				return fmt.Errorf("modules have a dependency cycle: %s -> %s", strings.Join(path[i:], " -> "), name)
+			}
+		}
+
+		if visited[name] {
+			return nil
+		}
+
+		path = append(path, name)
+
+		for _, dependencyName := range module.GetDependencies() {
+			dependency, found := modulesByName[dependencyName]
+
+			if !found {
+				return fmt.Errorf("module '%s' depends on module '%s', which was not added", name, dependencyName)
+			}
+
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		visited[name] = true
+		sorted = append(sorted, module)
+
+		return nil
+	}
+
+	for _, module := range m.Modules {
+		if err := visit(module); err != nil {
+			return err
+		}
+	}
+
+	m.Modules = sorted
+
+	return nil
+}
+
 // Static Functions
 
 func NewModuleManager() *ModuleManager {
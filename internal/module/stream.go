@@ -0,0 +1,97 @@
+package module
+
+import (
+	"context"
+
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/controller"
+	"github.com/comfortablynumb/goginrestapi/internal/errorhandler"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/jobs"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// Constants
+
+const (
+	StreamModuleName              = "stream"
+	StreamServiceComponentName    = "StreamService"
+	StreamControllerComponentName = "StreamController"
+)
+
+// Structs
+
+type StreamModule struct {
+	config service.StreamConfig
+}
+
+func (m *StreamModule) GetName() string {
+	return StreamModuleName
+}
+
+func (m *StreamModule) GetDependencies() []string {
+	return nil
+}
+
+func (m *StreamModule) GetConfig() interface{} {
+	return &m.config
+}
+
+func (m *StreamModule) SetUpComponents(
+	appConfig config.AppConfig,
+	errorHandler *errorhandler.ErrorHandler,
+	componentRegistry *componentregistry.ComponentRegistry,
+) error {
+	serv := service.NewStreamService(appConfig, m.config, componentRegistry.Logger, componentRegistry.Validator)
+	cont := controller.NewStreamController(serv, componentRegistry.RequestContextFactory, m.config.HeartbeatInterval)
+
+	componentRegistry.Set(StreamServiceComponentName, serv).
+		Set(StreamControllerComponentName, cont)
+
+	componentRegistry.AddShutdownHook(serv.Close)
+
+	return nil
+}
+
+func (m *StreamModule) SetUpRouter(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, router *gin.Engine) error {
+	streamController, err := componentregistry.Get[*controller.StreamController](componentRegistry, StreamControllerComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	router.GET("/stream", componentRegistry.GetRateLimiter(StreamModuleName), streamController.Stream)
+
+	return nil
+}
+
+func (m *StreamModule) SetUpValidator(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, validator *validator.Validate) error {
+	return nil
+}
+
+func (m *StreamModule) SetUpEventSubscribers(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, bus *events.Bus) error {
+	streamService, err := componentregistry.Get[service.StreamService](componentRegistry, StreamServiceComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	bus.SubscribeAll(streamService.Publish)
+
+	return nil
+}
+
+func (m *StreamModule) SetUpJobs(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, scheduler *jobs.Scheduler) error {
+	return nil
+}
+
+func (m *StreamModule) OnStart(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
+}
+
+func (m *StreamModule) OnStop(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
+}
@@ -1,11 +1,20 @@
 package module
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/comfortablynumb/goginrestapi/internal/cache"
 	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
 	"github.com/comfortablynumb/goginrestapi/internal/config"
 	"github.com/comfortablynumb/goginrestapi/internal/controller"
 	"github.com/comfortablynumb/goginrestapi/internal/errorhandler"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/i18n"
+	"github.com/comfortablynumb/goginrestapi/internal/jobs"
+	"github.com/comfortablynumb/goginrestapi/internal/middleware"
 	repository2 "github.com/comfortablynumb/goginrestapi/internal/repository"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
 	"github.com/comfortablynumb/goginrestapi/internal/service"
 	"github.com/gin-gonic/gin"
 	"gopkg.in/go-playground/validator.v9"
@@ -14,10 +23,11 @@ import (
 // Constants
 
 const (
-	UserModuleName              = "user"
-	UserRepositoryComponentName = "UserRepository"
-	UserServiceComponentName    = "UserService"
-	UserControllerComponentName = "UserController"
+	UserModuleName                 = "user"
+	UserRepositoryComponentName    = "UserRepository"
+	UserServiceComponentName       = "UserService"
+	UserControllerComponentName    = "UserController"
+	UserResponseCacheComponentName = "UserResponseCache"
 )
 
 // Structs
@@ -29,12 +39,26 @@ func (m *UserModule) GetName() string {
 	return UserModuleName
 }
 
+func (m *UserModule) GetDependencies() []string {
+	return []string{UserTypeModuleName, AuditModuleName, IdempotencyModuleName}
+}
+
 func (m *UserModule) SetUpComponents(
 	appConfig config.AppConfig,
 	errorHandler *errorhandler.ErrorHandler,
 	componentRegistry *componentregistry.ComponentRegistry,
-) {
-	userTypeService := componentRegistry.GetOrPanic(UserTypeServiceComponentName).(service.UserTypeService)
+) error {
+	userTypeService, err := componentregistry.Get[service.UserTypeService](componentRegistry, UserTypeServiceComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	auditService, err := componentregistry.Get[service.AuditService](componentRegistry, AuditServiceComponentName)
+
+	if err != nil {
+		return err
+	}
 
 	repo := repository2.NewUserRepository(appConfig, componentRegistry.Db, componentRegistry.Logger)
 	serv := service.NewUserService(
@@ -42,27 +66,111 @@ func (m *UserModule) SetUpComponents(
 		componentRegistry.Logger,
 		componentRegistry.Validator,
 		componentRegistry.TimeService,
+		componentRegistry.TransactionService,
+		auditService,
+		componentRegistry.EventService,
+		componentRegistry.CacheService,
 		repo,
 		userTypeService,
+		componentRegistry.Translator,
+		componentRegistry.Mailer,
+		componentRegistry.TokenSigner,
 	)
+	for locale, messages := range service.UserMessages {
+		if err := componentRegistry.Translator.AddMessages(locale, messages); err != nil {
+			return fmt.Errorf("could NOT add the user messages: %s", err)
+		}
+	}
+
 	cont := controller.NewUserController(serv, componentRegistry.RequestContextFactory)
 
 	componentRegistry.Set(UserRepositoryComponentName, repo).
 		Set(UserServiceComponentName, serv).
-		Set(UserControllerComponentName, cont)
+		Set(UserControllerComponentName, cont).
+		Set(UserResponseCacheComponentName, middleware.ResponseCache(
+			componentRegistry.CacheManager.Get(cache.ResponseCacheName),
+			cache.UserTag,
+			appConfig.Cache.ResponseTTL,
+		))
+
+	return nil
 }
 
-func (m *UserModule) SetUpRouter(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, router *gin.Engine) {
-	userController := componentRegistry.GetOrPanic(UserControllerComponentName).(*controller.UserController)
+func (m *UserModule) SetUpRouter(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, router *gin.Engine) error {
+	userController, err := componentregistry.Get[*controller.UserController](componentRegistry, UserControllerComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	responseCache, err := componentregistry.Get[gin.HandlerFunc](componentRegistry, UserResponseCacheComponentName)
+
+	if err != nil {
+		return err
+	}
 
-	users := router.Group("/user")
+	idempotency, err := componentregistry.Get[gin.HandlerFunc](componentRegistry, IdempotencyMiddlewareComponentName)
 
-	users.GET("", userController.Find)
-	users.POST("", userController.Create)
+	if err != nil {
+		return err
+	}
+
+	users := router.Group("/user", componentRegistry.GetRateLimiter(UserModuleName))
+
+	users.GET("", responseCache, userController.Find)
+	users.POST("", idempotency, userController.Create)
 	users.PUT("/:username", userController.Update)
 	users.DELETE("/:username", userController.Delete)
+	users.GET("/:username/history", userController.History)
+	users.POST("/:username/verify-email", userController.VerifyEmail)
+	users.POST("/:username/verification-email", userController.SendVerificationEmail)
+	users.POST("/:username/unlock", userController.Unlock)
+	users.DELETE("/:username/mfa", userController.DisableMfa)
+
+	return nil
 }
 
-func (m *UserModule) SetUpValidator(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, validator *validator.Validate) {
+func (m *UserModule) SetUpValidator(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, validator *validator.Validate) error {
+	userService, err := componentregistry.Get[service.UserService](componentRegistry, UserServiceComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	if err := validator.RegisterValidation("locale", userService.ValidateLocale); err != nil {
+		return fmt.Errorf("could NOT register locale validation: %s", err)
+	}
+
+	if err := validator.RegisterValidation("timezone", userService.ValidateTimezone); err != nil {
+		return fmt.Errorf("could NOT register timezone validation: %s", err)
+	}
+
+	validator.RegisterStructValidationCtx(userService.ValidateUserUnique, resource.UserCreateResource{}, resource.UserUpdateResource{})
+
+	componentRegistry.Translator.AddValidationMessages("locale", map[string]string{
+		i18n.EnglishLocale: "{0} must be a supported locale",
+		i18n.SpanishLocale: "{0} debe ser un idioma soportado",
+	})
+	componentRegistry.Translator.AddValidationMessages("timezone", map[string]string{
+		i18n.EnglishLocale: "{0} must be a valid timezone",
+		i18n.SpanishLocale: "{0} debe ser una zona horaria válida",
+	})
+
+	return nil
+}
+
+func (m *UserModule) SetUpEventSubscribers(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, bus *events.Bus) error {
+	return nil
+}
+
+func (m *UserModule) SetUpJobs(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, scheduler *jobs.Scheduler) error {
+	return nil
+}
+
+func (m *UserModule) OnStart(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
+}
 
+func (m *UserModule) OnStop(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
 }
@@ -1,10 +1,18 @@
 package module
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/comfortablynumb/goginrestapi/internal/cache"
 	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
 	"github.com/comfortablynumb/goginrestapi/internal/config"
 	"github.com/comfortablynumb/goginrestapi/internal/controller"
 	"github.com/comfortablynumb/goginrestapi/internal/errorhandler"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/i18n"
+	"github.com/comfortablynumb/goginrestapi/internal/jobs"
+	"github.com/comfortablynumb/goginrestapi/internal/middleware"
 	"github.com/comfortablynumb/goginrestapi/internal/repository"
 	"github.com/comfortablynumb/goginrestapi/internal/resource"
 	"github.com/comfortablynumb/goginrestapi/internal/service"
@@ -15,10 +23,11 @@ import (
 // Constants
 
 const (
-	UserTypeModuleName              = "user_type"
-	UserTypeRepositoryComponentName = "UserTypeRepository"
-	UserTypeServiceComponentName    = "UserTypeService"
-	UserTypeControllerComponentName = "UserTypeController"
+	UserTypeModuleName                 = "user_type"
+	UserTypeRepositoryComponentName    = "UserTypeRepository"
+	UserTypeServiceComponentName       = "UserTypeService"
+	UserTypeControllerComponentName    = "UserTypeController"
+	UserTypeResponseCacheComponentName = "UserTypeResponseCache"
 )
 
 // Structs
@@ -30,45 +39,120 @@ func (m *UserTypeModule) GetName() string {
 	return UserTypeModuleName
 }
 
+func (m *UserTypeModule) GetDependencies() []string {
+	return []string{AuditModuleName, IdempotencyModuleName}
+}
+
 func (m *UserTypeModule) SetUpComponents(
 	appConfig config.AppConfig,
 	errorHandler *errorhandler.ErrorHandler,
 	componentRegistry *componentregistry.ComponentRegistry,
-) {
-	repo := repository.NewUserTypeRepository(appConfig, componentRegistry.Db, componentRegistry.Logger)
+) error {
+	auditService, err := componentregistry.Get[service.AuditService](componentRegistry, AuditServiceComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	repo := repository.NewCachingUserTypeRepository(
+		repository.NewUserTypeRepository(appConfig, componentRegistry.Db, componentRegistry.Logger),
+		componentRegistry.CacheManager.Get(cache.RepositoryCacheName),
+		appConfig.Cache.RepositoryTTL,
+		componentRegistry.Logger,
+	)
 	serv := service.NewUserTypeService(
 		appConfig,
 		componentRegistry.Logger,
 		componentRegistry.Validator,
 		componentRegistry.TimeService,
+		componentRegistry.TransactionService,
+		auditService,
+		componentRegistry.EventService,
+		componentRegistry.CacheService,
 		repo,
 	)
 	cont := controller.NewUserTypeController(serv, componentRegistry.RequestContextFactory)
 
 	componentRegistry.Set(UserTypeRepositoryComponentName, repo).
 		Set(UserTypeServiceComponentName, serv).
-		Set(UserTypeControllerComponentName, cont)
+		Set(UserTypeControllerComponentName, cont).
+		Set(UserTypeResponseCacheComponentName, middleware.ResponseCache(
+			componentRegistry.CacheManager.Get(cache.ResponseCacheName),
+			cache.UserTypeTag,
+			appConfig.Cache.ResponseTTL,
+		))
+
+	return nil
 }
 
-func (m *UserTypeModule) SetUpRouter(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, router *gin.Engine) {
-	userTypeController := componentRegistry.GetOrPanic(UserTypeControllerComponentName).(*controller.UserTypeController)
+func (m *UserTypeModule) SetUpRouter(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, router *gin.Engine) error {
+	userTypeController, err := componentregistry.Get[*controller.UserTypeController](componentRegistry, UserTypeControllerComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	responseCache, err := componentregistry.Get[gin.HandlerFunc](componentRegistry, UserTypeResponseCacheComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	idempotency, err := componentregistry.Get[gin.HandlerFunc](componentRegistry, IdempotencyMiddlewareComponentName)
+
+	if err != nil {
+		return err
+	}
 
-	userTypes := router.Group("/user_type")
+	userTypes := router.Group("/user_type", componentRegistry.GetRateLimiter(UserTypeModuleName))
 
-	userTypes.GET("", userTypeController.Find)
+	userTypes.GET("", responseCache, userTypeController.Find)
 	userTypes.GET("/:name", userTypeController.FindOneByName)
-	userTypes.POST("", userTypeController.Create)
+	userTypes.POST("", idempotency, userTypeController.Create)
 	userTypes.PUT("/:name", userTypeController.Update)
 	userTypes.DELETE("/:name", userTypeController.Delete)
+	userTypes.GET("/:name/history", userTypeController.History)
+
+	return nil
 }
 
-func (m *UserTypeModule) SetUpValidator(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, validator *validator.Validate) {
-	userTypeService := componentRegistry.GetOrPanic(UserTypeServiceComponentName).(service.UserTypeService)
+func (m *UserTypeModule) SetUpValidator(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, validator *validator.Validate) error {
+	userTypeService, err := componentregistry.Get[service.UserTypeService](componentRegistry, UserTypeServiceComponentName)
 
-	errorHandler.HandleFatalIfError(
-		validator.RegisterValidationCtx("user_type", userTypeService.ValidateUserTypeByName),
-		"Could NOT register user type validation.",
-	)
+	if err != nil {
+		return err
+	}
+
+	if err := validator.RegisterValidationCtx("user_type", userTypeService.ValidateUserTypeByName); err != nil {
+		return fmt.Errorf("could NOT register user type validation: %s", err)
+	}
 
 	validator.RegisterStructValidationCtx(userTypeService.ValidateUserTypeUnique, resource.UserTypeCreateResource{}, resource.UserTypeUpdateResource{})
+
+	componentRegistry.Translator.AddValidationMessages("user_type", map[string]string{
+		i18n.EnglishLocale: "{0} must be an existing user type",
+		i18n.SpanishLocale: "{0} debe ser un tipo de usuario existente",
+	})
+	componentRegistry.Translator.AddValidationMessages("unique", map[string]string{
+		i18n.EnglishLocale: "{0} is already in use",
+		i18n.SpanishLocale: "{0} ya está en uso",
+	})
+
+	return nil
+}
+
+func (m *UserTypeModule) SetUpEventSubscribers(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, bus *events.Bus) error {
+	return nil
+}
+
+func (m *UserTypeModule) SetUpJobs(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, scheduler *jobs.Scheduler) error {
+	return nil
+}
+
+func (m *UserTypeModule) OnStart(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
+}
+
+func (m *UserTypeModule) OnStop(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
 }
@@ -0,0 +1,174 @@
+package module
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	context2 "github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/controller"
+	"github.com/comfortablynumb/goginrestapi/internal/errorhandler"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/i18n"
+	"github.com/comfortablynumb/goginrestapi/internal/jobs"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/repository"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// Constants
+
+const (
+	WebhookModuleName                          = "webhook"
+	WebhookSubscriptionRepositoryComponentName = "WebhookSubscriptionRepository"
+	WebhookDeliveryRepositoryComponentName     = "WebhookDeliveryRepository"
+	WebhookServiceComponentName                = "WebhookService"
+	WebhookControllerComponentName             = "WebhookController"
+	WebhookDeliveryJobName                     = "webhook.deliver_pending"
+)
+
+// Structs
+
+type WebhookModule struct {
+	config service.WebhookConfig
+}
+
+func (m *WebhookModule) GetName() string {
+	return WebhookModuleName
+}
+
+func (m *WebhookModule) GetDependencies() []string {
+	return []string{IdempotencyModuleName}
+}
+
+func (m *WebhookModule) GetConfig() interface{} {
+	return &m.config
+}
+
+func (m *WebhookModule) SetUpComponents(
+	appConfig config.AppConfig,
+	errorHandler *errorhandler.ErrorHandler,
+	componentRegistry *componentregistry.ComponentRegistry,
+) error {
+	subscriptionRepo := repository.NewWebhookSubscriptionRepository(appConfig, componentRegistry.Db, componentRegistry.Logger)
+	deliveryRepo := repository.NewWebhookDeliveryRepository(appConfig, componentRegistry.Db, componentRegistry.Logger)
+	serv := service.NewWebhookService(
+		appConfig,
+		m.config,
+		componentRegistry.Logger,
+		componentRegistry.Validator,
+		componentRegistry.TimeService,
+		componentRegistry.TransactionService,
+		subscriptionRepo,
+		deliveryRepo,
+	)
+	cont := controller.NewWebhookController(serv, componentRegistry.RequestContextFactory)
+
+	componentRegistry.Set(WebhookSubscriptionRepositoryComponentName, subscriptionRepo).
+		Set(WebhookDeliveryRepositoryComponentName, deliveryRepo).
+		Set(WebhookServiceComponentName, serv).
+		Set(WebhookControllerComponentName, cont)
+
+	return nil
+}
+
+func (m *WebhookModule) SetUpRouter(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, router *gin.Engine) error {
+	webhookController, err := componentregistry.Get[*controller.WebhookController](componentRegistry, WebhookControllerComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	idempotency, err := componentregistry.Get[gin.HandlerFunc](componentRegistry, IdempotencyMiddlewareComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	webhooks := router.Group("/webhooks", componentRegistry.GetRateLimiter(WebhookModuleName))
+
+	webhooks.GET("", webhookController.Find)
+	webhooks.GET("/:id", webhookController.FindOneByID)
+	webhooks.POST("", idempotency, webhookController.Create)
+	webhooks.PUT("/:id", webhookController.Update)
+	webhooks.DELETE("/:id", webhookController.Delete)
+	webhooks.GET("/:id/deliveries", webhookController.FindDeliveries)
+	webhooks.POST("/:id/deliveries/:delivery_id/redeliver", webhookController.Redeliver)
+
+	return nil
+}
+
+func (m *WebhookModule) SetUpValidator(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, validator *validator.Validate) error {
+	webhookService, err := componentregistry.Get[service.WebhookService](componentRegistry, WebhookServiceComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	if err := validator.RegisterValidationCtx("event_type", webhookService.ValidateEventType); err != nil {
+		return fmt.Errorf("could NOT register event type validation: %s", err)
+	}
+
+	componentRegistry.Translator.AddValidationMessages("event_type", map[string]string{
+		i18n.EnglishLocale: "{0} must be a registered event type",
+		i18n.SpanishLocale: "{0} debe ser un tipo de evento registrado",
+	})
+
+	return nil
+}
+
+func (m *WebhookModule) SetUpEventSubscribers(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, bus *events.Bus) error {
+	webhookService, err := componentregistry.Get[service.WebhookService](componentRegistry, WebhookServiceComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	// Every event is turned into pending deliveries for the interested subscriptions of its tenant. They are sent by
+	// the delivery job, so a slow or failing receiver never blocks the dispatcher.
+
+	bus.SubscribeAll(func(envelope *events.Envelope, event events.Event) error {
+		ctx := componentRegistry.RequestContextFactory.NewBackgroundRequestContext().SetTenant(envelope.TenantID, envelope.Tenant)
+
+		if err := webhookService.Enqueue(ctx, envelope); err != nil {
+			return err
+		}
+
+		return nil
+	})
+
+	return nil
+}
+
+// SetUpJobs Pending deliveries (including the retries of the failed ones) are sent every DeliveryInterval, by the
+// leader instance only, so they are not sent twice.
+func (m *WebhookModule) SetUpJobs(errorHandler *errorhandler.ErrorHandler, componentRegistry *componentregistry.ComponentRegistry, scheduler *jobs.Scheduler) error {
+	webhookService, err := componentregistry.Get[service.WebhookService](componentRegistry, WebhookServiceComponentName)
+
+	if err != nil {
+		return err
+	}
+
+	return scheduler.Register(jobs.Definition{
+		Name:     WebhookDeliveryJobName,
+		Schedule: jobs.Every(m.config.DeliveryInterval),
+		Handler: func(ctx *context2.RequestContext, job *model.Job) error {
+			if _, err := webhookService.DeliverPending(ctx); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	})
+}
+
+func (m *WebhookModule) OnStart(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
+}
+
+func (m *WebhookModule) OnStop(ctx context.Context, componentRegistry *componentregistry.ComponentRegistry) error {
+	return nil
+}
@@ -0,0 +1,121 @@
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"strconv"
+	"strings"
+)
+
+// Constants
+
+const (
+	Algorithm  = "pbkdf2_sha256"
+	Iterations = 100000
+	SaltSize   = 16
+	KeySize    = 32
+
+	separator = "$"
+)
+
+// Static functions
+
+// Hash Returns the hash of the password, with its algorithm, iterations and salt, so it can be verified even if the
+// defaults change.
+func Hash(password string) (string, error) {
+	salt := make([]byte, SaltSize)
+
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := pbkdf2([]byte(password), salt, Iterations, KeySize)
+
+	return strings.Join([]string{
+		Algorithm,
+		strconv.Itoa(Iterations),
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	}, separator), nil
+}
+
+// Verify Returns whether the password matches the hash. Malformed hashes match no password.
+func Verify(password string, hash string) bool {
+	parts := strings.Split(hash, separator)
+
+	if len(parts) != 4 || parts[0] != Algorithm {
+		return false
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+
+	if err != nil || iterations < 1 {
+		return false
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+
+	if err != nil {
+		return false
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[3])
+
+	if err != nil || len(key) == 0 {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(key, pbkdf2([]byte(password), salt, iterations, len(key))) == 1
+}
+
+// NewDummyHash Returns the hash of a random password. Verifying passwords against it takes as long as against a real
+// one, so the response times don't reveal which users exist.
+func NewDummyHash() (string, error) {
+	random := make([]byte, SaltSize)
+
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	return Hash(base64.RawStdEncoding.EncodeToString(random))
+}
+
+// pbkdf2 PBKDF2 (RFC 8018) with HMAC-SHA256.
+func pbkdf2(password []byte, salt []byte, iterations int, keySize int) []byte {
+	prf := hmac.New(sha256.New, password)
+	blocks := (keySize + prf.Size() - 1) / prf.Size()
+	res := make([]byte, 0, blocks*prf.Size())
+	counter := make([]byte, 4)
+
+	for block := 1; block <= blocks; block++ {
+		binary.BigEndian.PutUint32(counter, uint32(block))
+
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(counter)
+
+		u := prf.Sum(nil)
+		t := make([]byte, len(u))
+
+		copy(t, u)
+
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+
+			u = prf.Sum(u[:0])
+
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+
+		res = append(res, t...)
+	}
+
+	return res[:keySize]
+}
@@ -0,0 +1,170 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// Structs
+
+// TokenBucketLimiter Every key has a bucket holding up to limit tokens, refilled at a rate of limit tokens per period.
+// Every request takes a token, so bursts of up to limit requests are allowed.
+type TokenBucketLimiter struct {
+	store  Store
+	limit  int
+	period time.Duration
+	now    func() time.Time
+}
+
+func (l *TokenBucketLimiter) Allow(key string) (*Result, error) {
+	now := l.now()
+	ratePerSecond := float64(l.limit) / l.period.Seconds()
+	allowed := false
+
+	state, err := l.store.Update(key, l.period, func(state *State) *State {
+		tokens := float64(l.limit)
+
+		if state != nil {
+			tokens = math.Min(float64(l.limit), state.Tokens+now.Sub(state.Timestamp).Seconds()*ratePerSecond)
+		}
+
+		allowed = tokens >= 1
+
+		if allowed {
+			tokens--
+		}
+
+		return &State{Tokens: tokens, Timestamp: now}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Allowed:   allowed,
+		Limit:     l.limit,
+		Remaining: int(math.Floor(state.Tokens)),
+		Reset:     secondsToDuration((float64(l.limit) - state.Tokens) / ratePerSecond),
+	}
+
+	if !allowed {
+		result.RetryAfter = secondsToDuration((1 - state.Tokens) / ratePerSecond)
+	}
+
+	return result, nil
+}
+
+// SlidingWindowLimiter Counts the requests of the current fixed window, and estimates the ones made during the last
+// period weighting the count of the previous window by how much it overlaps with it.
+type SlidingWindowLimiter struct {
+	store  Store
+	limit  int
+	period time.Duration
+	now    func() time.Time
+}
+
+func (l *SlidingWindowLimiter) Allow(key string) (*Result, error) {
+	now := l.now()
+	windowStart := now.Truncate(l.period)
+	allowed := false
+
+	state, err := l.store.Update(key, 2*l.period, func(state *State) *State {
+		res := &State{Timestamp: windowStart}
+
+		if state != nil && state.Timestamp.Equal(windowStart) {
+			res.Count, res.PrevCount = state.Count, state.PrevCount
+		} else if state != nil && state.Timestamp.Equal(windowStart.Add(-l.period)) {
+			res.PrevCount = state.Count
+		}
+
+		allowed = l.estimate(res, now)+1 <= float64(l.limit)
+
+		if allowed {
+			res.Count++
+		}
+
+		return res
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	estimated := l.estimate(state, now)
+	reset := windowStart.Add(l.period).Sub(now)
+	result := &Result{
+		Allowed:   allowed,
+		Limit:     l.limit,
+		Remaining: int(math.Max(0, float64(l.limit)-math.Ceil(estimated))),
+		Reset:     reset,
+	}
+
+	if !allowed {
+		result.RetryAfter = l.retryAfter(state, now, reset)
+	}
+
+	return result, nil
+}
+
+func (l *SlidingWindowLimiter) estimate(state *State, now time.Time) float64 {
+	elapsed := now.Sub(state.Timestamp).Seconds() / l.period.Seconds()
+
+	return float64(state.PrevCount)*(1-elapsed) + float64(state.Count)
+}
+
+// retryAfter Returns the time until the estimate drops enough to allow one more request.
+func (l *SlidingWindowLimiter) retryAfter(state *State, now time.Time, reset time.Duration) time.Duration {
+	allowedCount := float64(l.limit - 1)
+
+	// The previous window must lose weight during the current one
+
+	if float64(state.Count) <= allowedCount && state.PrevCount > 0 {
+		elapsed := now.Sub(state.Timestamp).Seconds()
+		required := l.period.Seconds() * (1 - (allowedCount-float64(state.Count))/float64(state.PrevCount))
+
+		return secondsToDuration(required - elapsed)
+	}
+
+	// The current window must lose weight during the next one
+
+	required := l.period.Seconds() * (1 - allowedCount/float64(state.Count))
+
+	return reset + secondsToDuration(required)
+}
+
+// Static functions
+
+func secondsToDuration(seconds float64) time.Duration {
+	if seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func NewTokenBucketLimiter(store Store, limit int, period time.Duration, now func() time.Time) *TokenBucketLimiter {
+	if now == nil {
+		now = time.Now
+	}
+
+	return &TokenBucketLimiter{
+		store:  store,
+		limit:  limit,
+		period: period,
+		now:    now,
+	}
+}
+
+func NewSlidingWindowLimiter(store Store, limit int, period time.Duration, now func() time.Time) *SlidingWindowLimiter {
+	if now == nil {
+		now = time.Now
+	}
+
+	return &SlidingWindowLimiter{
+		store:  store,
+		limit:  limit,
+		period: period,
+		now:    now,
+	}
+}
@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Constants
+
+const (
+	TokenBucketAlgorithm   = "token_bucket"
+	SlidingWindowAlgorithm = "sliding_window"
+
+	KeyByIP     = "ip"
+	KeyByAPIKey = "api_key"
+	KeyByUser   = "user"
+)
+
+// Interfaces
+
+type Limiter interface {
+	// Allow Consumes one request from the quota of the key, returning whether it was allowed.
+	Allow(key string) (*Result, error)
+}
+
+// Structs
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset Time until the quota is fully available again.
+	Reset time.Duration
+	// RetryAfter Time until the next request will be allowed. Only set if this one was not.
+	RetryAfter time.Duration
+}
+
+// Policy Allows Limit requests every Period, counted with the given algorithm for every key of the given kind.
+type Policy struct {
+	Limit     int
+	Period    time.Duration
+	Algorithm string
+	Key       string
+}
+
+// Static functions
+
+// ParsePolicy Parses policies like "100/1m", "100/1m sliding_window" or "100/1m sliding_window api_key". The algorithm
+// defaults to token_bucket and the key to ip.
+func ParsePolicy(spec string) (Policy, error) {
+	policy := Policy{Algorithm: TokenBucketAlgorithm, Key: KeyByIP}
+	parts := strings.Fields(spec)
+
+	if len(parts) < 1 || len(parts) > 3 {
+		return policy, fmt.Errorf("invalid rate limit policy %q: expected <limit>/<period> [algorithm] [key]", spec)
+	}
+
+	rate := strings.SplitN(parts[0], "/", 2)
+
+	if len(rate) != 2 {
+		return policy, fmt.Errorf("invalid rate limit policy %q: expected <limit>/<period> [algorithm] [key]", spec)
+	}
+
+	limit, err := strconv.Atoi(rate[0])
+
+	if err != nil || limit < 1 {
+		return policy, fmt.Errorf("invalid limit on rate limit policy %q", spec)
+	}
+
+	period, err := time.ParseDuration(rate[1])
+
+	if err != nil || period <= 0 {
+		return policy, fmt.Errorf("invalid period on rate limit policy %q", spec)
+	}
+
+	policy.Limit = limit
+	policy.Period = period
+
+	if len(parts) > 1 {
+		policy.Algorithm = parts[1]
+	}
+
+	if len(parts) > 2 {
+		policy.Key = parts[2]
+	}
+
+	if policy.Algorithm != TokenBucketAlgorithm && policy.Algorithm != SlidingWindowAlgorithm {
+		return policy, fmt.Errorf("unknown rate limit algorithm %q", policy.Algorithm)
+	}
+
+	if policy.Key != KeyByIP && policy.Key != KeyByAPIKey && policy.Key != KeyByUser {
+		return policy, fmt.Errorf("unknown rate limit key %q", policy.Key)
+	}
+
+	return policy, nil
+}
+
+// NewLimiter Creates the limiter for the algorithm of the policy.
+func NewLimiter(policy Policy, store Store, now func() time.Time) Limiter {
+	if policy.Algorithm == SlidingWindowAlgorithm {
+		return NewSlidingWindowLimiter(store, policy.Limit, policy.Period, now)
+	}
+
+	return NewTokenBucketLimiter(store, policy.Limit, policy.Period, now)
+}
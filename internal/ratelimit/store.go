@@ -0,0 +1,167 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Constants
+
+const (
+	memoryStoreSweepEvery = 1000
+	redisStoreMaxRetries  = 10
+
+	// RedisStorePrefix Prefix of the keys of the rate limits, so they can share a database with other data.
+	RedisStorePrefix = "rate_limit:"
+
+	// redisCompareAndSetScript Replaces the value of KEYS[1] with ARGV[2] (expiring in ARGV[3] milliseconds) only if
+	// its current value is ARGV[1] (an empty string meaning it doesn't exist).
+	redisCompareAndSetScript = `
+local current = redis.call('GET', KEYS[1])
+if (current == false and ARGV[1] == '') or current == ARGV[1] then
+	redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+	return 1
+end
+return 0
+`
+)
+
+// Interfaces
+
+// Store Keeps the state of every key.
+type Store interface {
+	// Update Atomically replaces the state of the key with the one returned by fn, which receives nil if the key
+	// doesn't exist or expired. fn may be called more than once, so it must not have side effects.
+	Update(key string, ttl time.Duration, fn func(state *State) *State) (*State, error)
+}
+
+// RedisClient The subset of a Redis client used by RedisStore, so the app doesn't depend on a specific client library.
+// Get must return false (and no error) if the key doesn't exist.
+type RedisClient interface {
+	Get(key string) ([]byte, bool, error)
+	Eval(script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// Structs
+
+// State Every algorithm uses the fields it needs.
+type State struct {
+	Tokens    float64   `json:"tokens,omitempty"`
+	Count     int64     `json:"count,omitempty"`
+	PrevCount int64     `json:"prev_count,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type memoryStoreEntry struct {
+	state     *State
+	expiresAt time.Time
+}
+
+type MemoryStore struct {
+	mutex   sync.Mutex
+	now     func() time.Time
+	entries map[string]*memoryStoreEntry
+	updates int
+}
+
+func (s *MemoryStore) Update(key string, ttl time.Duration, fn func(state *State) *State) (*State, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+
+	s.updates++
+
+	if s.updates%memoryStoreSweepEvery == 0 {
+		for k, entry := range s.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+	}
+
+	var current *State
+
+	if entry, found := s.entries[key]; found && now.Before(entry.expiresAt) {
+		stateCopy := *entry.state
+		current = &stateCopy
+	}
+
+	state := fn(current)
+
+	s.entries[key] = &memoryStoreEntry{state: state, expiresAt: now.Add(ttl)}
+
+	return state, nil
+}
+
+// RedisStore Shares the state between instances of the app. Updates use optimistic concurrency: the new state is only
+// stored if the key was not modified since it was read, retrying otherwise.
+type RedisStore struct {
+	client RedisClient
+	prefix string
+}
+
+func (s *RedisStore) Update(key string, ttl time.Duration, fn func(state *State) *State) (*State, error) {
+	key = s.prefix + key
+
+	for i := 0; i < redisStoreMaxRetries; i++ {
+		raw, found, err := s.client.Get(key)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var current *State
+
+		if found {
+			current = &State{}
+
+			if err := json.Unmarshal(raw, current); err != nil {
+				current = nil
+			}
+		} else {
+			raw = []byte{}
+		}
+
+		state := fn(current)
+		value, err := json.Marshal(state)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := s.client.Eval(redisCompareAndSetScript, []string{key}, string(raw), string(value), ttl.Milliseconds())
+
+		if err != nil {
+			return nil, err
+		}
+
+		if swapped, ok := res.(int64); ok && swapped == 1 {
+			return state, nil
+		}
+	}
+
+	return nil, fmt.Errorf("could NOT update rate limit key '%s' after %d retries", key, redisStoreMaxRetries)
+}
+
+// Static functions
+
+func NewMemoryStore(now func() time.Time) *MemoryStore {
+	if now == nil {
+		now = time.Now
+	}
+
+	return &MemoryStore{
+		now:     now,
+		entries: make(map[string]*memoryStoreEntry),
+	}
+}
+
+func NewRedisStore(client RedisClient, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
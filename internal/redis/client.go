@@ -0,0 +1,160 @@
+package redis
+
+import (
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	redigo "github.com/gomodule/redigo/redis"
+)
+
+// Constants
+
+const (
+	scanCount = 1000
+)
+
+// Structs
+
+// Client Redis client used by the Redis backends of the app (see cache.RedisClient and ratelimit.RedisClient). Every
+// key is prefixed with the configured prefix, so several apps can share a database. Connections are taken from a pool.
+type Client struct {
+	pool   *redigo.Pool
+	prefix string
+}
+
+// Get Returns false (and no error) if the key doesn't exist.
+func (c *Client) Get(key string) ([]byte, bool, error) {
+	conn := c.pool.Get()
+	defer conn.Close()
+
+	value, err := redigo.Bytes(conn.Do("GET", c.prefix+key))
+
+	if err == redigo.ErrNil {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+// Set Stores the value without expiration if ttl is not positive.
+func (c *Client) Set(key string, value []byte, ttl time.Duration) error {
+	conn := c.pool.Get()
+	defer conn.Close()
+
+	args := redigo.Args{c.prefix + key, value}
+
+	if ttl > 0 {
+		args = args.Add("PX", ttl.Milliseconds())
+	}
+
+	_, err := conn.Do("SET", args...)
+
+	return err
+}
+
+func (c *Client) Del(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	conn := c.pool.Get()
+	defer conn.Close()
+
+	args := make(redigo.Args, 0, len(keys))
+
+	for _, key := range keys {
+		args = args.Add(c.prefix + key)
+	}
+
+	_, err := conn.Do("DEL", args...)
+
+	return err
+}
+
+// Scan Returns every key matching the pattern, without the prefix of the client.
+func (c *Client) Scan(pattern string) ([]string, error) {
+	conn := c.pool.Get()
+	defer conn.Close()
+
+	res := make([]string, 0)
+	cursor := 0
+
+	for {
+		values, err := redigo.Values(conn.Do("SCAN", cursor, "MATCH", c.prefix+pattern, "COUNT", scanCount))
+
+		if err != nil {
+			return nil, err
+		}
+
+		var keys []string
+
+		if _, err := redigo.Scan(values, &cursor, &keys); err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			res = append(res, key[len(c.prefix):])
+		}
+
+		if cursor == 0 {
+			return res, nil
+		}
+	}
+}
+
+// Eval Runs the Lua script with the given keys (prefixed with the prefix of the client) and arguments.
+func (c *Client) Eval(script string, keys []string, args ...interface{}) (interface{}, error) {
+	conn := c.pool.Get()
+	defer conn.Close()
+
+	scriptArgs := make(redigo.Args, 0, len(keys)+len(args))
+
+	for _, key := range keys {
+		scriptArgs = scriptArgs.Add(c.prefix + key)
+	}
+
+	return redigo.NewScript(len(keys), script).Do(conn, scriptArgs.Add(args...)...)
+}
+
+// Ping Checks that Redis can be reached.
+func (c *Client) Ping() error {
+	conn := c.pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("PING")
+
+	return err
+}
+
+func (c *Client) Close() error {
+	return c.pool.Close()
+}
+
+// Static functions
+
+func NewClient(redisConfig config.RedisConfig) *Client {
+	options := []redigo.DialOption{
+		redigo.DialDatabase(redisConfig.DB),
+		redigo.DialPassword(redisConfig.Password),
+		redigo.DialConnectTimeout(redisConfig.Timeout),
+		redigo.DialReadTimeout(redisConfig.Timeout),
+		redigo.DialWriteTimeout(redisConfig.Timeout),
+	}
+
+	return &Client{
+		pool: &redigo.Pool{
+			MaxIdle:     redisConfig.PoolSize,
+			MaxActive:   redisConfig.PoolSize,
+			IdleTimeout: redisConfig.IdleTimeout,
+			Wait:        true,
+			Dial: func() (redigo.Conn, error) {
+				return redigo.Dial("tcp", redisConfig.Address, options...)
+			},
+		},
+		prefix: redisConfig.Prefix,
+	}
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/repository/utils"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/rs/zerolog"
+)
+
+// Constants
+
+const (
+	AuditEventRepositorySourceName = "AuditEventRepository"
+)
+
+// Interfaces
+
+// AuditEventRepository Events are stored on, and found in, the tenant of the context.
+type AuditEventRepository interface {
+	Count(ctx *context.RequestContext, filters *utils.AuditEventFindFilters, options *utils.AuditEventFindOptions) (int64, *apperror.AppError)
+	Find(ctx *context.RequestContext, filters *utils.AuditEventFindFilters, options *utils.AuditEventFindOptions) ([]*model.AuditEvent, *apperror.AppError)
+	FindLastEntityID(ctx *context.RequestContext, entityType string, entityKey string) (int64, *apperror.AppError)
+	Create(ctx *context.RequestContext, auditEvent *model.AuditEvent) *apperror.AppError
+}
+
+// Structs
+
+type auditEventRepository struct {
+	appConfig config.AppConfig
+	db        *sql.DB
+	logger    *zerolog.Logger
+}
+
+func (r *auditEventRepository) Count(ctx *context.RequestContext, filters *utils.AuditEventFindFilters, options *utils.AuditEventFindOptions) (int64, *apperror.AppError) {
+	countOptions := *options
+
+	countOptions.WithCount(true)
+
+	query, bindings := r.createSelectQuery(ctx, filters, &countOptions)
+
+	row := GetExecutor(ctx, r.db).QueryRow(query, bindings...)
+	count := int64(0)
+
+	err := row.Scan(&count)
+
+	if err != nil {
+		return count, apperror.NewDbAppError(ctx, err, AuditEventRepositorySourceName)
+	}
+
+	return count, nil
+}
+
+func (r *auditEventRepository) Find(ctx *context.RequestContext, filters *utils.AuditEventFindFilters, options *utils.AuditEventFindOptions) ([]*model.AuditEvent, *apperror.AppError) {
+	query, bindings := r.createSelectQuery(ctx, filters, options)
+
+	rows, err := GetExecutor(ctx, r.db).Query(query, bindings...)
+
+	if err != nil {
+		return nil, apperror.NewDbAppError(ctx, err, AuditEventRepositorySourceName)
+	}
+
+	defer rows.Close()
+
+	res := make([]*model.AuditEvent, 0)
+
+	for rows.Next() {
+		builder := model.NewAuditEventBuilder()
+
+		ID := sql.NullInt64{}
+		actor := sql.NullString{}
+		requestID := sql.NullString{}
+		entityType := sql.NullString{}
+		entityID := sql.NullInt64{}
+		entityKey := sql.NullString{}
+		action := sql.NullString{}
+		changes := sql.NullString{}
+		createdAt := sql.NullTime{}
+
+		err = rows.Scan(&ID, &actor, &requestID, &entityType, &entityID, &entityKey, &action, &changes, &createdAt)
+
+		if err != nil {
+			return nil, apperror.NewDbAppError(ctx, err, AuditEventRepositorySourceName)
+		}
+
+		if ID.Valid {
+			builder.WithID(ID.Int64)
+		}
+
+		if actor.Valid {
+			builder.WithActor(actor.String)
+		}
+
+		if requestID.Valid {
+			builder.WithRequestID(requestID.String)
+		}
+
+		if entityType.Valid {
+			builder.WithEntityType(entityType.String)
+		}
+
+		if entityID.Valid {
+			builder.WithEntityID(entityID.Int64)
+		}
+
+		if entityKey.Valid {
+			builder.WithEntityKey(entityKey.String)
+		}
+
+		if action.Valid {
+			builder.WithAction(action.String)
+		}
+
+		if changes.Valid {
+			builder.WithChanges(changes.String)
+		}
+
+		if createdAt.Valid {
+			builder.WithCreatedAt(createdAt.Time)
+		}
+
+		res = append(res, builder.Build())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDbAppError(ctx, err, AuditEventRepositorySourceName)
+	}
+
+	return res, nil
+}
+
+// FindLastEntityID Returns the ID of the last entity of the given type whose events were recorded with the given key,
+// or 0 if there's none. It's used to find the events of deleted entities, as their key may already be used by another.
+func (r *auditEventRepository) FindLastEntityID(ctx *context.RequestContext, entityType string, entityKey string) (int64, *apperror.AppError) {
+	sb := sqlbuilder.NewSelectBuilder()
+
+	sb.Select("a.entity_id").
+		From(sb.As("audit_events", "a")).
+		Where(
+			sb.Equal("a.tenant_id", ctx.GetTenantID()),
+			sb.Equal("a.entity_type", entityType),
+			sb.Equal("a.entity_key", entityKey),
+		).
+		OrderBy("a.id").
+		Desc().
+		Limit(1)
+
+	query, bindings := sb.Build()
+
+	entityID := int64(0)
+
+	err := GetExecutor(ctx, r.db).QueryRow(query, bindings...).Scan(&entityID)
+
+	if err != nil && err != sql.ErrNoRows {
+		return 0, apperror.NewDbAppError(ctx, err, AuditEventRepositorySourceName)
+	}
+
+	return entityID, nil
+}
+
+func (r *auditEventRepository) Create(ctx *context.RequestContext, auditEvent *model.AuditEvent) *apperror.AppError {
+	qb := sqlbuilder.NewInsertBuilder()
+
+	qb.InsertInto("audit_events").
+		Cols("tenant_id", "actor", "request_id", "entity_type", "entity_id", "entity_key", "action", "changes", "created_at").
+		Values(
+			ctx.GetTenantID(),
+			auditEvent.Actor,
+			auditEvent.RequestID,
+			auditEvent.EntityType,
+			auditEvent.EntityID,
+			auditEvent.EntityKey,
+			auditEvent.Action,
+			auditEvent.Changes,
+			auditEvent.CreatedAt,
+		)
+
+	query, bindings := qb.Build()
+
+	res, err := GetExecutor(ctx, r.db).Exec(query, bindings...)
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, AuditEventRepositorySourceName)
+	}
+
+	lastInsertId, err := res.LastInsertId()
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, AuditEventRepositorySourceName)
+	}
+
+	auditEvent.ID = lastInsertId
+
+	return nil
+}
+
+func (r *auditEventRepository) createSelectQuery(
+	ctx *context.RequestContext,
+	filters *utils.AuditEventFindFilters,
+	options *utils.AuditEventFindOptions,
+) (string, []interface{}) {
+	sb := sqlbuilder.NewSelectBuilder()
+
+	if options.IsCount() {
+		sb.Select("COUNT(a.id)")
+	} else {
+		sb.Select(
+			"a.id",
+			"a.actor",
+			"a.request_id",
+			"a.entity_type",
+			"a.entity_id",
+			"a.entity_key",
+			"a.action",
+			"a.changes",
+			"a.created_at",
+		)
+	}
+
+	sb.From(sb.As("audit_events", "a")).
+		Where(sb.Equal("a.tenant_id", ctx.GetTenantID()))
+
+	if filters.GetEntityType() != nil {
+		sb.Where(sb.Equal("a.entity_type", filters.GetEntityTypeValue()))
+	}
+
+	if filters.GetEntityID() != nil {
+		sb.Where(sb.Equal("a.entity_id", filters.GetEntityIDValue()))
+	}
+
+	if filters.GetEntityKey() != nil {
+		sb.Where(sb.Equal("a.entity_key", filters.GetEntityKeyValue()))
+	}
+
+	if filters.GetActor() != nil {
+		sb.Where(sb.Equal("a.actor", filters.GetActorValue()))
+	}
+
+	if filters.GetFrom() != nil {
+		sb.Where(sb.GreaterEqualThan("a.created_at", filters.GetFromValue().UTC()))
+	}
+
+	if filters.GetTo() != nil {
+		sb.Where(sb.LessEqualThan("a.created_at", filters.GetToValue().UTC()))
+	}
+
+	if !options.IsCount() {
+		if options.GetSortBy() != nil && options.GetSortDir() != nil {
+			sb.OrderBy(options.GetSortByValue())
+
+			if options.IsAsc() {
+				sb.Asc()
+			} else {
+				sb.Desc()
+			}
+		} else {
+			sb.OrderBy("a.id").Asc()
+		}
+
+		if options.GetOffset() != nil && options.GetLimit() != nil {
+			sb.Offset(options.GetOffsetValue()).Limit(options.GetLimitValue())
+		} else if options.GetLimit() != nil {
+			sb.Limit(options.GetLimitValue())
+		}
+	}
+
+	return sb.Build()
+}
+
+// Static functions
+
+func NewAuditEventRepository(appConfig config.AppConfig, db *sql.DB, logger *zerolog.Logger) AuditEventRepository {
+	return &auditEventRepository{
+		appConfig: appConfig,
+		db:        db,
+		logger:    logger,
+	}
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/repository/utils"
+)
+
+// Interfaces
+
+// Executor Common interface of *sql.DB and *sql.Tx, so repositories can transparently join the transaction (if any)
+// started on the request context.
+type Executor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Structs
+
+// normalizingExecutor Normalizes the times bound to the queries (see utils.NormalizeTime), so every time is stored
+// with the same precision and time zone.
+type normalizingExecutor struct {
+	executor Executor
+}
+
+func (e *normalizingExecutor) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return e.executor.Exec(query, normalizeArgs(args)...)
+}
+
+func (e *normalizingExecutor) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return e.executor.Query(query, normalizeArgs(args)...)
+}
+
+func (e *normalizingExecutor) QueryRow(query string, args ...interface{}) *sql.Row {
+	return e.executor.QueryRow(query, normalizeArgs(args)...)
+}
+
+// Static functions
+
+func GetExecutor(ctx *context.RequestContext, db *sql.DB) Executor {
+	if ctx.HasTx() {
+		return &normalizingExecutor{executor: ctx.GetTx()}
+	}
+
+	return &normalizingExecutor{executor: db}
+}
+
+func normalizeArgs(args []interface{}) []interface{} {
+	res := make([]interface{}, len(args))
+
+	for i, arg := range args {
+		switch value := arg.(type) {
+		case time.Time:
+			res[i] = utils.NormalizeTime(value)
+		case *time.Time:
+			if value != nil {
+				res[i] = utils.NormalizeTime(*value)
+			} else {
+				res[i] = arg
+			}
+		case sql.NullTime:
+			if value.Valid {
+				res[i] = utils.NormalizeTime(value.Time)
+			} else {
+				res[i] = arg
+			}
+		default:
+			res[i] = arg
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/rs/zerolog"
+)
+
+// Constants
+
+const (
+	IdempotencyKeyRepositorySourceName = "IdempotencyKeyRepository"
+)
+
+// Interfaces
+
+type IdempotencyKeyRepository interface {
+	FindOne(ctx *context.RequestContext, key string, route string, caller string) (*model.IdempotencyKey, *apperror.AppError)
+	Create(ctx *context.RequestContext, idempotencyKey *model.IdempotencyKey) (bool, *apperror.AppError)
+	Update(ctx *context.RequestContext, idempotencyKey *model.IdempotencyKey) (bool, *apperror.AppError)
+	TakeOver(ctx *context.RequestContext, idempotencyKey *model.IdempotencyKey, createdAt time.Time, expiresAt time.Time) (bool, *apperror.AppError)
+	Delete(ctx *context.RequestContext, idempotencyKey *model.IdempotencyKey) (bool, *apperror.AppError)
+	DeleteExpired(ctx *context.RequestContext, now time.Time) (int64, *apperror.AppError)
+}
+
+// Structs
+
+type idempotencyKeyRepository struct {
+	appConfig config.AppConfig
+	db        *sql.DB
+	logger    *zerolog.Logger
+}
+
+func (r *idempotencyKeyRepository) FindOne(ctx *context.RequestContext, key string, route string, caller string) (*model.IdempotencyKey, *apperror.AppError) {
+	sb := sqlbuilder.NewSelectBuilder()
+
+	sb.Select(
+		"k.id",
+		"k.idempotency_key",
+		"k.route",
+		"k.caller",
+		"k.request_hash",
+		"k.status",
+		"k.response_status",
+		"k.response_content_type",
+		"k.response_body",
+		"k.created_at",
+		"k.expires_at",
+	).
+		From(sb.As("idempotency_keys", "k")).
+		Where(
+			sb.Equal("k.idempotency_key", key),
+			sb.Equal("k.route", route),
+			sb.Equal("k.caller", caller),
+		).
+		Limit(1)
+
+	query, bindings := sb.Build()
+
+	row := GetExecutor(ctx, r.db).QueryRow(query, bindings...)
+	res := &model.IdempotencyKey{}
+
+	err := row.Scan(
+		&res.ID,
+		&res.Key,
+		&res.Route,
+		&res.Caller,
+		&res.RequestHash,
+		&res.Status,
+		&res.ResponseStatus,
+		&res.ResponseContentType,
+		&res.ResponseBody,
+		&res.CreatedAt,
+		&res.ExpiresAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, apperror.NewDbAppError(ctx, err, IdempotencyKeyRepositorySourceName)
+	}
+
+	return res, nil
+}
+
+// Create Creates the key, unless the same key was already created for the same route and caller. Returns true if the
+// key was created, so concurrent requests with the same key can tell which one of them must be processed.
+func (r *idempotencyKeyRepository) Create(ctx *context.RequestContext, idempotencyKey *model.IdempotencyKey) (bool, *apperror.AppError) {
+	query := `INSERT OR IGNORE INTO idempotency_keys
+	(idempotency_key, route, caller, request_hash, status, response_status, response_content_type, response_body, created_at, expires_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := GetExecutor(ctx, r.db).Exec(
+		query,
+		idempotencyKey.Key,
+		idempotencyKey.Route,
+		idempotencyKey.Caller,
+		idempotencyKey.RequestHash,
+		idempotencyKey.Status,
+		idempotencyKey.ResponseStatus,
+		idempotencyKey.ResponseContentType,
+		idempotencyKey.ResponseBody,
+		idempotencyKey.CreatedAt,
+		idempotencyKey.ExpiresAt,
+	)
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, IdempotencyKeyRepositorySourceName)
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, IdempotencyKeyRepositorySourceName)
+	}
+
+	if affected < 1 {
+		return false, nil
+	}
+
+	lastInsertId, err := res.LastInsertId()
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, IdempotencyKeyRepositorySourceName)
+	}
+
+	idempotencyKey.ID = lastInsertId
+
+	return true, nil
+}
+
+// Update Stores the key, only if it's still in progress and owned by the request which reserved it (i.e. it was not
+// taken over by another request since, which changes its creation time). Returns whether it was updated.
+func (r *idempotencyKeyRepository) Update(ctx *context.RequestContext, idempotencyKey *model.IdempotencyKey) (bool, *apperror.AppError) {
+	qb := sqlbuilder.NewUpdateBuilder()
+
+	qb.Update("idempotency_keys").
+		Set(
+			qb.Assign("request_hash", idempotencyKey.RequestHash),
+			qb.Assign("status", idempotencyKey.Status),
+			qb.Assign("response_status", idempotencyKey.ResponseStatus),
+			qb.Assign("response_content_type", idempotencyKey.ResponseContentType),
+			qb.Assign("response_body", idempotencyKey.ResponseBody),
+			qb.Assign("expires_at", idempotencyKey.ExpiresAt),
+		).
+		Where(
+			qb.Equal("id", idempotencyKey.ID),
+			qb.Equal("status", model.IdempotencyKeyStatusInProgress),
+			qb.Equal("created_at", idempotencyKey.CreatedAt),
+		)
+
+	query, bindings := qb.Build()
+
+	return r.execConditional(ctx, query, bindings...)
+}
+
+// TakeOver Reserves again a key whose request is still in progress, setting its creation and expiration times. The
+// key is only updated if it was not completed nor taken over by another request since it was read (i.e. its status and
+// creation time didn't change). Returns whether it was updated.
+func (r *idempotencyKeyRepository) TakeOver(
+	ctx *context.RequestContext,
+	idempotencyKey *model.IdempotencyKey,
+	createdAt time.Time,
+	expiresAt time.Time,
+) (bool, *apperror.AppError) {
+	qb := sqlbuilder.NewUpdateBuilder()
+
+	qb.Update("idempotency_keys").
+		Set(
+			qb.Assign("created_at", createdAt),
+			qb.Assign("expires_at", expiresAt),
+		).
+		Where(
+			qb.Equal("id", idempotencyKey.ID),
+			qb.Equal("status", model.IdempotencyKeyStatusInProgress),
+			qb.Equal("created_at", idempotencyKey.CreatedAt),
+		)
+
+	query, bindings := qb.Build()
+
+	res, err := GetExecutor(ctx, r.db).Exec(query, bindings...)
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, IdempotencyKeyRepositorySourceName)
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, IdempotencyKeyRepositorySourceName)
+	}
+
+	if affected == 0 {
+		return false, nil
+	}
+
+	idempotencyKey.CreatedAt = createdAt
+	idempotencyKey.ExpiresAt = expiresAt
+
+	return true, nil
+}
+
+// Delete Deletes the key, only if its status and creation time didn't change since it was read, so requests can't
+// delete a key which was completed or taken over by another request since. Returns whether it was deleted.
+func (r *idempotencyKeyRepository) Delete(ctx *context.RequestContext, idempotencyKey *model.IdempotencyKey) (bool, *apperror.AppError) {
+	qb := sqlbuilder.NewDeleteBuilder()
+
+	qb.DeleteFrom("idempotency_keys").
+		Where(
+			qb.Equal("id", idempotencyKey.ID),
+			qb.Equal("status", idempotencyKey.Status),
+			qb.Equal("created_at", idempotencyKey.CreatedAt),
+		)
+
+	query, bindings := qb.Build()
+
+	return r.execConditional(ctx, query, bindings...)
+}
+
+// DeleteExpired Deletes every key which expired before the given time. Returns the amount of deleted keys.
+func (r *idempotencyKeyRepository) DeleteExpired(ctx *context.RequestContext, now time.Time) (int64, *apperror.AppError) {
+	qb := sqlbuilder.NewDeleteBuilder()
+
+	qb.DeleteFrom("idempotency_keys").
+		Where(qb.LessEqualThan("expires_at", now))
+
+	query, bindings := qb.Build()
+
+	res, err := GetExecutor(ctx, r.db).Exec(query, bindings...)
+
+	if err != nil {
+		return 0, apperror.NewDbAppError(ctx, err, IdempotencyKeyRepositorySourceName)
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return 0, apperror.NewDbAppError(ctx, err, IdempotencyKeyRepositorySourceName)
+	}
+
+	return affected, nil
+}
+
+// execConditional Runs a statement which only matches the key if it didn't change since it was read. Returns whether it
+// matched it.
+func (r *idempotencyKeyRepository) execConditional(ctx *context.RequestContext, query string, bindings ...interface{}) (bool, *apperror.AppError) {
+	res, err := GetExecutor(ctx, r.db).Exec(query, bindings...)
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, IdempotencyKeyRepositorySourceName)
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, IdempotencyKeyRepositorySourceName)
+	}
+
+	return affected > 0, nil
+}
+
+// Static functions
+
+func NewIdempotencyKeyRepository(appConfig config.AppConfig, db *sql.DB, logger *zerolog.Logger) IdempotencyKeyRepository {
+	return &idempotencyKeyRepository{
+		appConfig: appConfig,
+		db:        db,
+		logger:    logger,
+	}
+}
@@ -0,0 +1,398 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/rs/zerolog"
+)
+
+// Constants
+
+const (
+	JobRepositorySourceName = "JobRepository"
+)
+
+// Interfaces
+
+type JobRepository interface {
+	FindOneByID(ctx *context.RequestContext, ID int64) (*model.Job, *apperror.AppError)
+	FindDue(ctx *context.RequestContext, names []string, now time.Time, limit int) ([]*model.Job, *apperror.AppError)
+	Create(ctx *context.RequestContext, job *model.Job) *apperror.AppError
+	Claim(ctx *context.RequestContext, job *model.Job, owner string, now time.Time, lockedUntil time.Time) (bool, *apperror.AppError)
+	Update(ctx *context.RequestContext, job *model.Job, lockedBy string) (bool, *apperror.AppError)
+	DeleteFinishedBefore(ctx *context.RequestContext, before time.Time) (int64, *apperror.AppError)
+}
+
+// Structs
+
+type jobRepository struct {
+	appConfig config.AppConfig
+	db        *sql.DB
+	logger    *zerolog.Logger
+}
+
+func (r *jobRepository) FindOneByID(ctx *context.RequestContext, ID int64) (*model.Job, *apperror.AppError) {
+	sb := r.createSelectBuilder()
+
+	sb.Where(sb.Equal("j.id", ID)).
+		Limit(1)
+
+	res, err := r.find(ctx, sb)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) > 0 {
+		return res[0], nil
+	}
+
+	return nil, nil
+}
+
+// FindDue Returns the jobs with the given names which are due: the pending ones whose time to run has come, and the
+// running ones whose lock expired (because the instance running them died). The ones which have been waiting the
+// longest come first.
+func (r *jobRepository) FindDue(ctx *context.RequestContext, names []string, now time.Time, limit int) ([]*model.Job, *apperror.AppError) {
+	if len(names) < 1 {
+		return make([]*model.Job, 0), nil
+	}
+
+	nameArgs := make([]interface{}, len(names))
+
+	for i, name := range names {
+		nameArgs[i] = name
+	}
+
+	sb := r.createSelectBuilder()
+
+	sb.Where(
+		sb.In("j.name", nameArgs...),
+		sb.Or(
+			sb.And(sb.Equal("j.status", model.JobStatusPending), sb.LessEqualThan("j.run_at", now)),
+			sb.And(sb.Equal("j.status", model.JobStatusRunning), sb.LessThan("j.locked_until", now)),
+		),
+	).
+		OrderBy("j.run_at", "j.id").
+		Asc().
+		Limit(limit)
+
+	return r.find(ctx, sb)
+}
+
+func (r *jobRepository) Create(ctx *context.RequestContext, job *model.Job) *apperror.AppError {
+	qb := sqlbuilder.NewInsertBuilder()
+
+	qb.InsertInto("jobs").
+		Cols(
+			"name",
+			"payload",
+			"status",
+			"attempts",
+			"max_attempts",
+			"last_error",
+			"run_at",
+			"locked_by",
+			"locked_until",
+			"finished_at",
+			"created_at",
+			"updated_at",
+		).
+		Values(
+			job.Name,
+			job.Payload,
+			job.Status,
+			job.Attempts,
+			job.MaxAttempts,
+			job.LastError,
+			job.RunAt,
+			job.LockedBy,
+			job.LockedUntil,
+			job.FinishedAt,
+			job.CreatedAt,
+			job.UpdatedAt,
+		)
+
+	query, bindings := qb.Build()
+
+	res, err := GetExecutor(ctx, r.db).Exec(query, bindings...)
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, JobRepositorySourceName)
+	}
+
+	lastInsertId, err := res.LastInsertId()
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, JobRepositorySourceName)
+	}
+
+	job.ID = lastInsertId
+
+	return nil
+}
+
+// Claim Locks the job for the owner until lockedUntil and counts a new attempt, unless another instance claimed it
+// first. Returns true if the job was claimed, in which case the job is updated too.
+func (r *jobRepository) Claim(ctx *context.RequestContext, job *model.Job, owner string, now time.Time, lockedUntil time.Time) (bool, *apperror.AppError) {
+	query := `UPDATE jobs
+	SET status = ?, attempts = attempts + 1, locked_by = ?, locked_until = ?, updated_at = ?
+	WHERE id = ? AND (
+		(status = ? AND run_at <= ?) OR
+		(status = ? AND locked_until < ?)
+	)`
+
+	res, err := GetExecutor(ctx, r.db).Exec(
+		query,
+		model.JobStatusRunning,
+		owner,
+		lockedUntil,
+		now,
+		job.ID,
+		model.JobStatusPending,
+		now,
+		model.JobStatusRunning,
+		now,
+	)
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, JobRepositorySourceName)
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, JobRepositorySourceName)
+	}
+
+	if affected < 1 {
+		return false, nil
+	}
+
+	job.Status = model.JobStatusRunning
+	job.Attempts++
+	job.LockedBy = owner
+	job.LockedUntil = &lockedUntil
+	job.UpdatedAt = now
+
+	return true, nil
+}
+
+// Update Updates the job, unless it's not locked by lockedBy anymore (because its lock expired and another instance
+// claimed it). Returns true if the job was updated.
+func (r *jobRepository) Update(ctx *context.RequestContext, job *model.Job, lockedBy string) (bool, *apperror.AppError) {
+	qb := sqlbuilder.NewUpdateBuilder()
+
+	qb.Update("jobs").
+		Set(
+			qb.Assign("payload", job.Payload),
+			qb.Assign("status", job.Status),
+			qb.Assign("attempts", job.Attempts),
+			qb.Assign("max_attempts", job.MaxAttempts),
+			qb.Assign("last_error", job.LastError),
+			qb.Assign("run_at", job.RunAt),
+			qb.Assign("locked_by", job.LockedBy),
+			qb.Assign("locked_until", job.LockedUntil),
+			qb.Assign("finished_at", job.FinishedAt),
+			qb.Assign("updated_at", job.UpdatedAt),
+		).
+		Where(
+			qb.Equal("id", job.ID),
+			qb.Equal("locked_by", lockedBy),
+		)
+
+	query, bindings := qb.Build()
+
+	res, err := GetExecutor(ctx, r.db).Exec(query, bindings...)
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, JobRepositorySourceName)
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, JobRepositorySourceName)
+	}
+
+	return affected > 0, nil
+}
+
+// DeleteFinishedBefore Deletes every job which finished (successfully or not) before the given time. Returns the
+// amount of deleted jobs.
+func (r *jobRepository) DeleteFinishedBefore(ctx *context.RequestContext, before time.Time) (int64, *apperror.AppError) {
+	qb := sqlbuilder.NewDeleteBuilder()
+
+	qb.DeleteFrom("jobs").
+		Where(
+			qb.In("status", model.JobStatusSucceeded, model.JobStatusFailed),
+			qb.LessThan("finished_at", before),
+		)
+
+	query, bindings := qb.Build()
+
+	res, err := GetExecutor(ctx, r.db).Exec(query, bindings...)
+
+	if err != nil {
+		return 0, apperror.NewDbAppError(ctx, err, JobRepositorySourceName)
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return 0, apperror.NewDbAppError(ctx, err, JobRepositorySourceName)
+	}
+
+	return affected, nil
+}
+
+func (r *jobRepository) createSelectBuilder() *sqlbuilder.SelectBuilder {
+	sb := sqlbuilder.NewSelectBuilder()
+
+	sb.Select(
+		"j.id",
+		"j.name",
+		"j.payload",
+		"j.status",
+		"j.attempts",
+		"j.max_attempts",
+		"j.last_error",
+		"j.run_at",
+		"j.locked_by",
+		"j.locked_until",
+		"j.finished_at",
+		"j.created_at",
+		"j.updated_at",
+	).
+		From(sb.As("jobs", "j"))
+
+	return sb
+}
+
+func (r *jobRepository) find(ctx *context.RequestContext, sb *sqlbuilder.SelectBuilder) ([]*model.Job, *apperror.AppError) {
+	query, bindings := sb.Build()
+
+	rows, err := GetExecutor(ctx, r.db).Query(query, bindings...)
+
+	if err != nil {
+		return nil, apperror.NewDbAppError(ctx, err, JobRepositorySourceName)
+	}
+
+	defer rows.Close()
+
+	res := make([]*model.Job, 0)
+
+	for rows.Next() {
+		builder := model.NewJobBuilder()
+
+		ID := sql.NullInt64{}
+		name := sql.NullString{}
+		payload := sql.NullString{}
+		status := sql.NullString{}
+		attempts := sql.NullInt64{}
+		maxAttempts := sql.NullInt64{}
+		lastError := sql.NullString{}
+		runAt := sql.NullTime{}
+		lockedBy := sql.NullString{}
+		lockedUntil := sql.NullTime{}
+		finishedAt := sql.NullTime{}
+		createdAt := sql.NullTime{}
+		updatedAt := sql.NullTime{}
+
+		err = rows.Scan(
+			&ID,
+			&name,
+			&payload,
+			&status,
+			&attempts,
+			&maxAttempts,
+			&lastError,
+			&runAt,
+			&lockedBy,
+			&lockedUntil,
+			&finishedAt,
+			&createdAt,
+			&updatedAt,
+		)
+
+		if err != nil {
+			return nil, apperror.NewDbAppError(ctx, err, JobRepositorySourceName)
+		}
+
+		if ID.Valid {
+			builder.WithID(ID.Int64)
+		}
+
+		if name.Valid {
+			builder.WithName(name.String)
+		}
+
+		if payload.Valid {
+			builder.WithPayload(payload.String)
+		}
+
+		if status.Valid {
+			builder.WithStatus(status.String)
+		}
+
+		if attempts.Valid {
+			builder.WithAttempts(int(attempts.Int64))
+		}
+
+		if maxAttempts.Valid {
+			builder.WithMaxAttempts(int(maxAttempts.Int64))
+		}
+
+		if lastError.Valid {
+			builder.WithLastError(lastError.String)
+		}
+
+		if runAt.Valid {
+			builder.WithRunAt(runAt.Time)
+		}
+
+		if lockedBy.Valid {
+			builder.WithLockedBy(lockedBy.String)
+		}
+
+		if lockedUntil.Valid {
+			builder.WithLockedUntil(&lockedUntil.Time)
+		}
+
+		if finishedAt.Valid {
+			builder.WithFinishedAt(&finishedAt.Time)
+		}
+
+		if createdAt.Valid {
+			builder.WithCreatedAt(createdAt.Time)
+		}
+
+		if updatedAt.Valid {
+			builder.WithUpdatedAt(updatedAt.Time)
+		}
+
+		res = append(res, builder.Build())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDbAppError(ctx, err, JobRepositorySourceName)
+	}
+
+	return res, nil
+}
+
+// Static functions
+
+func NewJobRepository(appConfig config.AppConfig, db *sql.DB, logger *zerolog.Logger) JobRepository {
+	return &jobRepository{
+		appConfig: appConfig,
+		db:        db,
+		logger:    logger,
+	}
+}
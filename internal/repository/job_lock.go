@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/rs/zerolog"
+)
+
+// Constants
+
+const (
+	JobLockRepositorySourceName = "JobLockRepository"
+)
+
+// Interfaces
+
+// JobLockRepository Locks shared by every instance of the app, like the one held by the instance which runs the
+// scheduled jobs. Locks expire, so they're taken over when the instance holding them dies.
+type JobLockRepository interface {
+	Acquire(ctx *context.RequestContext, name string, owner string, now time.Time, expiresAt time.Time) (bool, *apperror.AppError)
+	Release(ctx *context.RequestContext, name string, owner string) *apperror.AppError
+}
+
+// Structs
+
+type jobLockRepository struct {
+	appConfig config.AppConfig
+	db        *sql.DB
+	logger    *zerolog.Logger
+}
+
+// Acquire Takes the lock for the owner until expiresAt, if it's free, expired or already held by the owner (in which
+// case it's extended). Returns true if the owner holds the lock.
+func (r *jobLockRepository) Acquire(ctx *context.RequestContext, name string, owner string, now time.Time, expiresAt time.Time) (bool, *apperror.AppError) {
+	query := `INSERT INTO job_locks (name, owner, expires_at) VALUES (?, ?, ?)
+	ON CONFLICT (name) DO UPDATE SET owner = excluded.owner, expires_at = excluded.expires_at
+	WHERE job_locks.owner = excluded.owner OR job_locks.expires_at < ?`
+
+	res, err := GetExecutor(ctx, r.db).Exec(query, name, owner, expiresAt, now)
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, JobLockRepositorySourceName)
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, JobLockRepositorySourceName)
+	}
+
+	return affected > 0, nil
+}
+
+// Release Frees the lock, if it's held by the owner.
+func (r *jobLockRepository) Release(ctx *context.RequestContext, name string, owner string) *apperror.AppError {
+	qb := sqlbuilder.NewDeleteBuilder()
+
+	qb.DeleteFrom("job_locks").
+		Where(
+			qb.Equal("name", name),
+			qb.Equal("owner", owner),
+		)
+
+	query, bindings := qb.Build()
+
+	_, err := GetExecutor(ctx, r.db).Exec(query, bindings...)
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, JobLockRepositorySourceName)
+	}
+
+	return nil
+}
+
+// Static functions
+
+func NewJobLockRepository(appConfig config.AppConfig, db *sql.DB, logger *zerolog.Logger) JobLockRepository {
+	return &jobLockRepository{
+		appConfig: appConfig,
+		db:        db,
+		logger:    logger,
+	}
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/rs/zerolog"
+)
+
+// Constants
+
+const (
+	OutboxEventRepositorySourceName = "OutboxEventRepository"
+)
+
+// Interfaces
+
+type OutboxEventRepository interface {
+	FindPending(ctx *context.RequestContext, now time.Time, maxAttempts int, limit int) ([]*model.OutboxEvent, *apperror.AppError)
+	Create(ctx *context.RequestContext, outboxEvent *model.OutboxEvent) *apperror.AppError
+	Update(ctx *context.RequestContext, outboxEvent *model.OutboxEvent) *apperror.AppError
+}
+
+// Structs
+
+type outboxEventRepository struct {
+	appConfig config.AppConfig
+	db        *sql.DB
+	logger    *zerolog.Logger
+}
+
+// FindPending Returns the undelivered events whose next attempt is due, oldest first. As they are delivered by a
+// background process, the events of every tenant are returned, with the name of their tenant.
+func (r *outboxEventRepository) FindPending(ctx *context.RequestContext, now time.Time, maxAttempts int, limit int) ([]*model.OutboxEvent, *apperror.AppError) {
+	sb := sqlbuilder.NewSelectBuilder()
+
+	sb.Select(
+		"o.id",
+		"o.tenant_id",
+		"t.name",
+		"o.name",
+		"o.payload",
+		"o.actor",
+		"o.request_id",
+		"o.attempts",
+		"o.last_error",
+		"o.next_attempt_at",
+		"o.delivered_at",
+		"o.created_at",
+	).
+		From(sb.As("outbox_events", "o")).
+		JoinWithOption(sqlbuilder.LeftJoin, sb.As("tenants", "t"), "t.id = o.tenant_id").
+		Where(
+			sb.IsNull("o.delivered_at"),
+			sb.LessEqualThan("o.next_attempt_at", now),
+			sb.LessThan("o.attempts", maxAttempts),
+		).
+		OrderBy("o.id").
+		Asc().
+		Limit(limit)
+
+	query, bindings := sb.Build()
+
+	rows, err := GetExecutor(ctx, r.db).Query(query, bindings...)
+
+	if err != nil {
+		return nil, apperror.NewDbAppError(ctx, err, OutboxEventRepositorySourceName)
+	}
+
+	defer rows.Close()
+
+	res := make([]*model.OutboxEvent, 0)
+
+	for rows.Next() {
+		builder := model.NewOutboxEventBuilder()
+
+		ID := sql.NullInt64{}
+		tenantID := sql.NullInt64{}
+		tenant := sql.NullString{}
+		name := sql.NullString{}
+		payload := sql.NullString{}
+		actor := sql.NullString{}
+		requestID := sql.NullString{}
+		attempts := sql.NullInt64{}
+		lastError := sql.NullString{}
+		nextAttemptAt := sql.NullTime{}
+		deliveredAt := sql.NullTime{}
+		createdAt := sql.NullTime{}
+
+		err = rows.Scan(&ID, &tenantID, &tenant, &name, &payload, &actor, &requestID, &attempts, &lastError, &nextAttemptAt, &deliveredAt, &createdAt)
+
+		if err != nil {
+			return nil, apperror.NewDbAppError(ctx, err, OutboxEventRepositorySourceName)
+		}
+
+		if ID.Valid {
+			builder.WithID(ID.Int64)
+		}
+
+		if tenantID.Valid {
+			builder.WithTenantID(tenantID.Int64)
+		}
+
+		if tenant.Valid {
+			builder.WithTenant(tenant.String)
+		}
+
+		if name.Valid {
+			builder.WithName(name.String)
+		}
+
+		if payload.Valid {
+			builder.WithPayload(payload.String)
+		}
+
+		if actor.Valid {
+			builder.WithActor(actor.String)
+		}
+
+		if requestID.Valid {
+			builder.WithRequestID(requestID.String)
+		}
+
+		if attempts.Valid {
+			builder.WithAttempts(int(attempts.Int64))
+		}
+
+		if lastError.Valid {
+			builder.WithLastError(lastError.String)
+		}
+
+		if nextAttemptAt.Valid {
+			builder.WithNextAttemptAt(nextAttemptAt.Time)
+		}
+
+		if deliveredAt.Valid {
+			builder.WithDeliveredAt(&deliveredAt.Time)
+		}
+
+		if createdAt.Valid {
+			builder.WithCreatedAt(createdAt.Time)
+		}
+
+		res = append(res, builder.Build())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDbAppError(ctx, err, OutboxEventRepositorySourceName)
+	}
+
+	return res, nil
+}
+
+// Create The event is stored on the tenant of the context.
+func (r *outboxEventRepository) Create(ctx *context.RequestContext, outboxEvent *model.OutboxEvent) *apperror.AppError {
+	qb := sqlbuilder.NewInsertBuilder()
+
+	qb.InsertInto("outbox_events").
+		Cols("tenant_id", "name", "payload", "actor", "request_id", "attempts", "last_error", "next_attempt_at", "delivered_at", "created_at").
+		Values(
+			ctx.GetTenantID(),
+			outboxEvent.Name,
+			outboxEvent.Payload,
+			outboxEvent.Actor,
+			outboxEvent.RequestID,
+			outboxEvent.Attempts,
+			outboxEvent.LastError,
+			outboxEvent.NextAttemptAt,
+			outboxEvent.DeliveredAt,
+			outboxEvent.CreatedAt,
+		)
+
+	query, bindings := qb.Build()
+
+	res, err := GetExecutor(ctx, r.db).Exec(query, bindings...)
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, OutboxEventRepositorySourceName)
+	}
+
+	lastInsertId, err := res.LastInsertId()
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, OutboxEventRepositorySourceName)
+	}
+
+	outboxEvent.ID = lastInsertId
+	outboxEvent.TenantID = ctx.GetTenantID()
+
+	return nil
+}
+
+func (r *outboxEventRepository) Update(ctx *context.RequestContext, outboxEvent *model.OutboxEvent) *apperror.AppError {
+	qb := sqlbuilder.NewUpdateBuilder()
+
+	qb.Update("outbox_events").
+		Set(
+			qb.Assign("attempts", outboxEvent.Attempts),
+			qb.Assign("last_error", outboxEvent.LastError),
+			qb.Assign("next_attempt_at", outboxEvent.NextAttemptAt),
+			qb.Assign("delivered_at", outboxEvent.DeliveredAt),
+		).
+		Where(qb.Equal("id", outboxEvent.ID))
+
+	query, bindings := qb.Build()
+
+	_, err := GetExecutor(ctx, r.db).Exec(query, bindings...)
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, OutboxEventRepositorySourceName)
+	}
+
+	return nil
+}
+
+// Static functions
+
+func NewOutboxEventRepository(appConfig config.AppConfig, db *sql.DB, logger *zerolog.Logger) OutboxEventRepository {
+	return &outboxEventRepository{
+		appConfig: appConfig,
+		db:        db,
+		logger:    logger,
+	}
+}
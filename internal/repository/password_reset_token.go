@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/rs/zerolog"
+)
+
+// Constants
+
+const (
+	PasswordResetTokenRepositorySourceName = "PasswordResetTokenRepository"
+)
+
+// Interfaces
+
+// PasswordResetTokenRepository Tokens are scoped by the tenant of their user.
+type PasswordResetTokenRepository interface {
+	FindOneByTokenHash(ctx *context.RequestContext, tokenHash string) (*model.PasswordResetToken, *apperror.AppError)
+	Create(ctx *context.RequestContext, passwordResetToken *model.PasswordResetToken) *apperror.AppError
+	MarkUsed(ctx *context.RequestContext, passwordResetToken *model.PasswordResetToken, usedAt time.Time) (bool, *apperror.AppError)
+	MarkUsedByUserID(ctx *context.RequestContext, userID int64, usedAt time.Time) *apperror.AppError
+	DeleteExpired(ctx *context.RequestContext, now time.Time) (int64, *apperror.AppError)
+}
+
+// Structs
+
+type passwordResetTokenRepository struct {
+	appConfig config.AppConfig
+	db        *sql.DB
+	logger    *zerolog.Logger
+}
+
+func (r *passwordResetTokenRepository) FindOneByTokenHash(ctx *context.RequestContext, tokenHash string) (*model.PasswordResetToken, *apperror.AppError) {
+	query := `SELECT id, user_id, token_hash, expires_at, used_at, created_at
+	FROM password_reset_tokens
+	WHERE token_hash = ? AND ` + userOfTenantCondition
+
+	res := &model.PasswordResetToken{}
+	usedAt := sql.NullTime{}
+
+	err := GetExecutor(ctx, r.db).QueryRow(query, tokenHash, ctx.GetTenantID()).Scan(
+		&res.ID,
+		&res.UserID,
+		&res.TokenHash,
+		&res.ExpiresAt,
+		&usedAt,
+		&res.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, apperror.NewDbAppError(ctx, err, PasswordResetTokenRepositorySourceName)
+	}
+
+	if usedAt.Valid {
+		res.UsedAt = &usedAt.Time
+	}
+
+	return res, nil
+}
+
+// Create The user of the token must belong to the tenant of the context, or a model not found error is returned.
+func (r *passwordResetTokenRepository) Create(ctx *context.RequestContext, passwordResetToken *model.PasswordResetToken) *apperror.AppError {
+	query := `INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, used_at, created_at)
+	SELECT u.id, ?, ?, ?, ?
+	FROM users u
+	WHERE u.id = ? AND u.tenant_id = ?`
+
+	res, err := GetExecutor(ctx, r.db).Exec(
+		query,
+		passwordResetToken.TokenHash,
+		passwordResetToken.ExpiresAt,
+		passwordResetToken.UsedAt,
+		passwordResetToken.CreatedAt,
+		passwordResetToken.UserID,
+		ctx.GetTenantID(),
+	)
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, PasswordResetTokenRepositorySourceName)
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, PasswordResetTokenRepositorySourceName)
+	}
+
+	if affected == 0 {
+		return apperror.NewModelNotFoundAppError(
+			ctx,
+			fmt.Errorf("user %d was not found on tenant %d", passwordResetToken.UserID, ctx.GetTenantID()),
+			PasswordResetTokenRepositorySourceName,
+		)
+	}
+
+	lastInsertId, err := res.LastInsertId()
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, PasswordResetTokenRepositorySourceName)
+	}
+
+	passwordResetToken.ID = lastInsertId
+
+	return nil
+}
+
+// MarkUsed Marks the token as used, only if it's still unused and not expired at usedAt. Returns false if it's not,
+// meaning it was used in the meantime, for example by a concurrent request.
+func (r *passwordResetTokenRepository) MarkUsed(
+	ctx *context.RequestContext,
+	passwordResetToken *model.PasswordResetToken,
+	usedAt time.Time,
+) (bool, *apperror.AppError) {
+	query := `UPDATE password_reset_tokens
+	SET used_at = ?
+	WHERE id = ? AND used_at IS NULL AND expires_at > ? AND ` + userOfTenantCondition
+
+	res, err := GetExecutor(ctx, r.db).Exec(query, usedAt, passwordResetToken.ID, usedAt, ctx.GetTenantID())
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, PasswordResetTokenRepositorySourceName)
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, PasswordResetTokenRepositorySourceName)
+	}
+
+	if affected == 0 {
+		return false, nil
+	}
+
+	passwordResetToken.UsedAt = &usedAt
+
+	return true, nil
+}
+
+// MarkUsedByUserID Marks every unused token of the user as used, so none of them can be used anymore.
+func (r *passwordResetTokenRepository) MarkUsedByUserID(ctx *context.RequestContext, userID int64, usedAt time.Time) *apperror.AppError {
+	query := `UPDATE password_reset_tokens
+	SET used_at = ?
+	WHERE user_id = ? AND used_at IS NULL AND ` + userOfTenantCondition
+
+	_, err := GetExecutor(ctx, r.db).Exec(query, usedAt, userID, ctx.GetTenantID())
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, PasswordResetTokenRepositorySourceName)
+	}
+
+	return nil
+}
+
+// DeleteExpired Deletes every token which expired before the given time or was already used, as none of them can be
+// used anymore. Returns the amount of deleted tokens. As it is maintenance work, the tokens of every tenant are deleted.
+func (r *passwordResetTokenRepository) DeleteExpired(ctx *context.RequestContext, now time.Time) (int64, *apperror.AppError) {
+	qb := sqlbuilder.NewDeleteBuilder()
+
+	qb.DeleteFrom("password_reset_tokens").
+		Where(qb.Or(
+			qb.LessEqualThan("expires_at", now),
+			qb.IsNotNull("used_at"),
+		))
+
+	query, bindings := qb.Build()
+
+	res, err := GetExecutor(ctx, r.db).Exec(query, bindings...)
+
+	if err != nil {
+		return 0, apperror.NewDbAppError(ctx, err, PasswordResetTokenRepositorySourceName)
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return 0, apperror.NewDbAppError(ctx, err, PasswordResetTokenRepositorySourceName)
+	}
+
+	return affected, nil
+}
+
+// Static functions
+
+func NewPasswordResetTokenRepository(appConfig config.AppConfig, db *sql.DB, logger *zerolog.Logger) PasswordResetTokenRepository {
+	return &passwordResetTokenRepository{
+		appConfig: appConfig,
+		db:        db,
+		logger:    logger,
+	}
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/rs/zerolog"
+)
+
+// Constants
+
+const (
+	RecoveryCodeRepositorySourceName = "RecoveryCodeRepository"
+)
+
+// Interfaces
+
+// RecoveryCodeRepository Codes are scoped by the tenant of their user.
+type RecoveryCodeRepository interface {
+	FindOneUnusedByUserIDAndCodeHash(ctx *context.RequestContext, userID int64, codeHash string) (*model.RecoveryCode, *apperror.AppError)
+	CountUnusedByUserID(ctx *context.RequestContext, userID int64) (int64, *apperror.AppError)
+	Create(ctx *context.RequestContext, recoveryCode *model.RecoveryCode) *apperror.AppError
+	MarkUsed(ctx *context.RequestContext, recoveryCode *model.RecoveryCode, usedAt time.Time) (bool, *apperror.AppError)
+	DeleteByUserID(ctx *context.RequestContext, userID int64) *apperror.AppError
+}
+
+// Structs
+
+type recoveryCodeRepository struct {
+	appConfig config.AppConfig
+	db        *sql.DB
+	logger    *zerolog.Logger
+}
+
+func (r *recoveryCodeRepository) FindOneUnusedByUserIDAndCodeHash(ctx *context.RequestContext, userID int64, codeHash string) (*model.RecoveryCode, *apperror.AppError) {
+	query := `SELECT id, user_id, code_hash, created_at
+	FROM recovery_codes
+	WHERE user_id = ? AND code_hash = ? AND used_at IS NULL AND ` + userOfTenantCondition + `
+	LIMIT 1`
+
+	res := &model.RecoveryCode{}
+
+	err := GetExecutor(ctx, r.db).QueryRow(query, userID, codeHash, ctx.GetTenantID()).Scan(
+		&res.ID,
+		&res.UserID,
+		&res.CodeHash,
+		&res.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, apperror.NewDbAppError(ctx, err, RecoveryCodeRepositorySourceName)
+	}
+
+	return res, nil
+}
+
+func (r *recoveryCodeRepository) CountUnusedByUserID(ctx *context.RequestContext, userID int64) (int64, *apperror.AppError) {
+	query := `SELECT COUNT(id)
+	FROM recovery_codes
+	WHERE user_id = ? AND used_at IS NULL AND ` + userOfTenantCondition
+
+	count := int64(0)
+
+	if err := GetExecutor(ctx, r.db).QueryRow(query, userID, ctx.GetTenantID()).Scan(&count); err != nil {
+		return count, apperror.NewDbAppError(ctx, err, RecoveryCodeRepositorySourceName)
+	}
+
+	return count, nil
+}
+
+// Create The user of the code must belong to the tenant of the context, or a model not found error is returned.
+func (r *recoveryCodeRepository) Create(ctx *context.RequestContext, recoveryCode *model.RecoveryCode) *apperror.AppError {
+	query := `INSERT INTO recovery_codes (user_id, code_hash, used_at, created_at)
+	SELECT u.id, ?, ?, ?
+	FROM users u
+	WHERE u.id = ? AND u.tenant_id = ?`
+
+	res, err := GetExecutor(ctx, r.db).Exec(
+		query,
+		recoveryCode.CodeHash,
+		recoveryCode.UsedAt,
+		recoveryCode.CreatedAt,
+		recoveryCode.UserID,
+		ctx.GetTenantID(),
+	)
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, RecoveryCodeRepositorySourceName)
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, RecoveryCodeRepositorySourceName)
+	}
+
+	if affected == 0 {
+		return apperror.NewModelNotFoundAppError(
+			ctx,
+			fmt.Errorf("user %d was not found on tenant %d", recoveryCode.UserID, ctx.GetTenantID()),
+			RecoveryCodeRepositorySourceName,
+		)
+	}
+
+	lastInsertId, err := res.LastInsertId()
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, RecoveryCodeRepositorySourceName)
+	}
+
+	recoveryCode.ID = lastInsertId
+
+	return nil
+}
+
+// MarkUsed Marks the code as used, unless it was used in the meantime. Returns false in that case, so the same code
+// can't be used by two concurrent logins.
+func (r *recoveryCodeRepository) MarkUsed(ctx *context.RequestContext, recoveryCode *model.RecoveryCode, usedAt time.Time) (bool, *apperror.AppError) {
+	query := `UPDATE recovery_codes
+	SET used_at = ?
+	WHERE id = ? AND used_at IS NULL AND ` + userOfTenantCondition
+
+	res, err := GetExecutor(ctx, r.db).Exec(query, usedAt, recoveryCode.ID, ctx.GetTenantID())
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, RecoveryCodeRepositorySourceName)
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, RecoveryCodeRepositorySourceName)
+	}
+
+	if affected == 0 {
+		return false, nil
+	}
+
+	recoveryCode.UsedAt = &usedAt
+
+	return true, nil
+}
+
+func (r *recoveryCodeRepository) DeleteByUserID(ctx *context.RequestContext, userID int64) *apperror.AppError {
+	query := `DELETE FROM recovery_codes
+	WHERE user_id = ? AND ` + userOfTenantCondition
+
+	_, err := GetExecutor(ctx, r.db).Exec(query, userID, ctx.GetTenantID())
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, RecoveryCodeRepositorySourceName)
+	}
+
+	return nil
+}
+
+// Static functions
+
+func NewRecoveryCodeRepository(appConfig config.AppConfig, db *sql.DB, logger *zerolog.Logger) RecoveryCodeRepository {
+	return &recoveryCodeRepository{
+		appConfig: appConfig,
+		db:        db,
+		logger:    logger,
+	}
+}
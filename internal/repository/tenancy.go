@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+)
+
+// Constants
+
+const (
+	// userOfTenantCondition Scopes the rows owned by a user (like password reset tokens and recovery codes) by the
+	// tenant of their user.
+	userOfTenantCondition = "user_id IN (SELECT id FROM users WHERE tenant_id = ?)"
+
+	// subscriptionOfTenantCondition Scopes the webhook deliveries by the tenant of their subscription.
+	subscriptionOfTenantCondition = "subscription_id IN (SELECT id FROM webhook_subscriptions WHERE tenant_id = ?)"
+)
+
+// Static functions
+
+// execScoped Runs a statement which is scoped by the tenant of the context and must affect a single row. As it only
+// matches rows of that tenant, affecting none means the row doesn't exist or belongs to another tenant, and a model
+// not found error is returned in both cases.
+func execScoped(ctx *context.RequestContext, db *sql.DB, source string, query string, bindings ...interface{}) *apperror.AppError {
+	res, err := GetExecutor(ctx, db).Exec(query, bindings...)
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, source)
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, source)
+	}
+
+	if affected == 0 {
+		return apperror.NewModelNotFoundAppError(
+			ctx,
+			fmt.Errorf("no row was found on tenant %d", ctx.GetTenantID()),
+			source,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/rs/zerolog"
+)
+
+// Constants
+
+const (
+	TenantRepositorySourceName = "TenantRepository"
+)
+
+// Interfaces
+
+// TenantRepository Tenants are not scoped by the tenant of the context, as they are needed to resolve it.
+type TenantRepository interface {
+	Find(ctx *context.RequestContext) ([]*model.Tenant, *apperror.AppError)
+	FindOneByName(ctx *context.RequestContext, name string) (*model.Tenant, *apperror.AppError)
+	Create(ctx *context.RequestContext, tenant *model.Tenant) *apperror.AppError
+	Update(ctx *context.RequestContext, tenant *model.Tenant) *apperror.AppError
+}
+
+// Structs
+
+type tenantRepository struct {
+	appConfig config.AppConfig
+	db        *sql.DB
+	logger    *zerolog.Logger
+}
+
+func (r *tenantRepository) Find(ctx *context.RequestContext) ([]*model.Tenant, *apperror.AppError) {
+	return r.find(ctx, "")
+}
+
+func (r *tenantRepository) FindOneByName(ctx *context.RequestContext, name string) (*model.Tenant, *apperror.AppError) {
+	if name == "" {
+		return nil, nil
+	}
+
+	res, err := r.find(ctx, name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) > 0 {
+		return res[0], nil
+	}
+
+	return nil, nil
+}
+
+func (r *tenantRepository) Create(ctx *context.RequestContext, tenant *model.Tenant) *apperror.AppError {
+	qb := sqlbuilder.NewInsertBuilder()
+
+	qb.InsertInto("tenants").
+		Cols("name", "disabled", "created_at", "updated_at").
+		Values(tenant.Name, tenant.Disabled, tenant.CreatedAt, tenant.UpdatedAt)
+
+	query, bindings := qb.Build()
+
+	res, err := GetExecutor(ctx, r.db).Exec(query, bindings...)
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, TenantRepositorySourceName)
+	}
+
+	lastInsertId, err := res.LastInsertId()
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, TenantRepositorySourceName)
+	}
+
+	tenant.ID = lastInsertId
+
+	return nil
+}
+
+func (r *tenantRepository) Update(ctx *context.RequestContext, tenant *model.Tenant) *apperror.AppError {
+	qb := sqlbuilder.NewUpdateBuilder()
+
+	qb.Update("tenants").
+		Set(
+			qb.Assign("name", tenant.Name),
+			qb.Assign("disabled", tenant.Disabled),
+			qb.Assign("updated_at", tenant.UpdatedAt),
+		).
+		Where(qb.Equal("id", tenant.ID))
+
+	query, bindings := qb.Build()
+
+	_, err := GetExecutor(ctx, r.db).Exec(query, bindings...)
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, TenantRepositorySourceName)
+	}
+
+	return nil
+}
+
+func (r *tenantRepository) find(ctx *context.RequestContext, name string) ([]*model.Tenant, *apperror.AppError) {
+	sb := sqlbuilder.NewSelectBuilder()
+
+	sb.Select("t.id", "t.name", "t.disabled", "t.created_at", "t.updated_at").
+		From(sb.As("tenants", "t")).
+		OrderBy("t.name").
+		Asc()
+
+	if name != "" {
+		sb.Where(sb.Equal("t.name", name))
+	}
+
+	query, bindings := sb.Build()
+
+	rows, err := GetExecutor(ctx, r.db).Query(query, bindings...)
+
+	if err != nil {
+		return nil, apperror.NewDbAppError(ctx, err, TenantRepositorySourceName)
+	}
+
+	defer rows.Close()
+
+	res := make([]*model.Tenant, 0)
+
+	for rows.Next() {
+		builder := model.NewTenantBuilder()
+
+		ID := sql.NullInt64{}
+		tenantName := sql.NullString{}
+		disabled := sql.NullBool{}
+		createdAt := sql.NullTime{}
+		updatedAt := sql.NullTime{}
+
+		err = rows.Scan(&ID, &tenantName, &disabled, &createdAt, &updatedAt)
+
+		if err != nil {
+			return nil, apperror.NewDbAppError(ctx, err, TenantRepositorySourceName)
+		}
+
+		if ID.Valid {
+			builder.WithID(ID.Int64)
+		}
+
+		if tenantName.Valid {
+			builder.WithName(tenantName.String)
+		}
+
+		if disabled.Valid {
+			builder.WithDisabled(disabled.Bool)
+		}
+
+		if createdAt.Valid {
+			builder.WithCreatedAt(createdAt.Time)
+		}
+
+		if updatedAt.Valid {
+			builder.WithUpdatedAt(updatedAt.Time)
+		}
+
+		res = append(res, builder.Build())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDbAppError(ctx, err, TenantRepositorySourceName)
+	}
+
+	return res, nil
+}
+
+// Static functions
+
+func NewTenantRepository(appConfig config.AppConfig, db *sql.DB, logger *zerolog.Logger) TenantRepository {
+	return &tenantRepository{
+		appConfig: appConfig,
+		db:        db,
+		logger:    logger,
+	}
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
+	"time"
 
 	"github.com/comfortablynumb/goginrestapi/internal/apperror"
 	"github.com/comfortablynumb/goginrestapi/internal/config"
@@ -21,9 +23,12 @@ const (
 
 type UserRepository interface {
 	Find(ctx *context.RequestContext, filters *utils.UserFindFilters, options *utils.UserFindOptions) ([]*model.User, *apperror.AppError)
+	FindOneByID(ctx *context.RequestContext, id int64) (*model.User, *apperror.AppError)
 	FindOneByUsername(ctx *context.RequestContext, username string) (*model.User, *apperror.AppError)
 	Create(ctx *context.RequestContext, user *model.User) *apperror.AppError
 	Update(ctx *context.RequestContext, user *model.User) *apperror.AppError
+	RecordFailedLogin(ctx *context.RequestContext, user *model.User, maxFailedLogins int, now time.Time, lockedUntil time.Time) (bool, *apperror.AppError)
+	UseTotpStep(ctx *context.RequestContext, user *model.User, step int64) (bool, *apperror.AppError)
 	Delete(ctx *context.RequestContext, user *model.User) *apperror.AppError
 }
 
@@ -35,34 +40,62 @@ type userRepository struct {
 	logger    *zerolog.Logger
 }
 
+// Find Only users of the tenant of the context are found.
 func (r *userRepository) Find(ctx *context.RequestContext, filters *utils.UserFindFilters, options *utils.UserFindOptions) ([]*model.User, *apperror.AppError) {
 	query := `SELECT
 	u.id,
+	u.tenant_id,
 	u.username,
 	u.disabled,
+	u.email,
+	u.display_name,
+	u.locale,
+	u.timezone,
+	u.email_verified_at,
+	u.password_hash,
+	u.password_changed_at,
+	u.failed_login_attempts,
+	u.locked_until,
+	u.totp_secret,
+	u.totp_enabled_at,
+	u.totp_last_used_step,
 	u.created_at,
 	u.updated_at,
 	ut.id AS user_type_id,
+	ut.tenant_id AS user_type_tenant_id,
 	ut.name AS user_type_name,
 	ut.disabled AS user_type_disabled,
+	ut.requires_mfa AS user_type_requires_mfa,
 	ut.created_at AS user_type_created_at,
 	ut.updated_at AS user_type_updated_at
 FROM users u
-INNER JOIN user_types ut ON ut.id = u.user_type_id
-WHERE 1 = 1 `
-	bindings := make([]interface{}, 0)
+INNER JOIN user_types ut ON ut.id = u.user_type_id AND ut.tenant_id = u.tenant_id
+WHERE u.tenant_id = ? `
+	bindings := []interface{}{ctx.GetTenantID()}
+
+	if filters.GetID() != nil {
+		query += "AND u.id = ? "
+		bindings = append(bindings, filters.GetIDValue())
+	}
 
 	if filters.GetUsername() != nil {
 		query += "AND u.username = ? "
 		bindings = append(bindings, filters.GetUsernameValue())
 	}
 
-	rows, err := r.db.Query(query, bindings...)
+	if filters.GetEmail() != nil {
+		query += "AND u.email = ? "
+		bindings = append(bindings, filters.GetEmailValue())
+	}
+
+	rows, err := GetExecutor(ctx, r.db).Query(query, bindings...)
 
 	if err != nil {
 		return nil, apperror.NewDbAppError(ctx, err, UserRepositorySourceName)
 	}
 
+	defer rows.Close()
+
 	res := make([]*model.User, 0)
 
 	for rows.Next() {
@@ -70,17 +103,58 @@ WHERE 1 = 1 `
 		userTypeBuilder := model.NewUserTypeBuilder()
 
 		ID := sql.NullInt64{}
+		tenantID := sql.NullInt64{}
 		username := sql.NullString{}
 		disabled := sql.NullBool{}
+		email := sql.NullString{}
+		displayName := sql.NullString{}
+		locale := sql.NullString{}
+		timezone := sql.NullString{}
+		emailVerifiedAt := sql.NullTime{}
+		passwordHash := sql.NullString{}
+		passwordChangedAt := sql.NullTime{}
+		failedLoginAttempts := sql.NullInt64{}
+		lockedUntil := sql.NullTime{}
+		totpSecret := sql.NullString{}
+		totpEnabledAt := sql.NullTime{}
+		totpLastUsedStep := sql.NullInt64{}
 		createdAt := sql.NullTime{}
 		updatedAt := sql.NullTime{}
 		userTypeID := sql.NullInt64{}
+		userTypeTenantID := sql.NullInt64{}
 		userTypeName := sql.NullString{}
 		userTypeDisabled := sql.NullBool{}
+		userTypeRequiresMfa := sql.NullBool{}
 		userTypeCreatedAt := sql.NullTime{}
 		userTypeUpdatedAt := sql.NullTime{}
 
-		err = rows.Scan(&ID, &username, &disabled, &createdAt, &updatedAt, &userTypeID, &userTypeName, &userTypeDisabled, &userTypeCreatedAt, &userTypeUpdatedAt)
+		err = rows.Scan(
+			&ID,
+			&tenantID,
+			&username,
+			&disabled,
+			&email,
+			&displayName,
+			&locale,
+			&timezone,
+			&emailVerifiedAt,
+			&passwordHash,
+			&passwordChangedAt,
+			&failedLoginAttempts,
+			&lockedUntil,
+			&totpSecret,
+			&totpEnabledAt,
+			&totpLastUsedStep,
+			&createdAt,
+			&updatedAt,
+			&userTypeID,
+			&userTypeTenantID,
+			&userTypeName,
+			&userTypeDisabled,
+			&userTypeRequiresMfa,
+			&userTypeCreatedAt,
+			&userTypeUpdatedAt,
+		)
 
 		if err != nil {
 			return nil, apperror.NewDbAppError(ctx, err, UserRepositorySourceName)
@@ -90,6 +164,10 @@ WHERE 1 = 1 `
 			userBuilder.WithID(ID.Int64)
 		}
 
+		if tenantID.Valid {
+			userBuilder.WithTenantID(tenantID.Int64)
+		}
+
 		if username.Valid {
 			userBuilder.WithUsername(username.String)
 		}
@@ -98,6 +176,54 @@ WHERE 1 = 1 `
 			userBuilder.WithDisabled(disabled.Bool)
 		}
 
+		if email.Valid {
+			userBuilder.WithEmail(email.String)
+		}
+
+		if displayName.Valid {
+			userBuilder.WithDisplayName(displayName.String)
+		}
+
+		if locale.Valid {
+			userBuilder.WithLocale(locale.String)
+		}
+
+		if timezone.Valid {
+			userBuilder.WithTimezone(timezone.String)
+		}
+
+		if emailVerifiedAt.Valid {
+			userBuilder.WithEmailVerifiedAt(&emailVerifiedAt.Time)
+		}
+
+		if passwordHash.Valid {
+			userBuilder.WithPasswordHash(passwordHash.String)
+		}
+
+		if passwordChangedAt.Valid {
+			userBuilder.WithPasswordChangedAt(&passwordChangedAt.Time)
+		}
+
+		if failedLoginAttempts.Valid {
+			userBuilder.WithFailedLoginAttempts(int(failedLoginAttempts.Int64))
+		}
+
+		if lockedUntil.Valid {
+			userBuilder.WithLockedUntil(&lockedUntil.Time)
+		}
+
+		if totpSecret.Valid {
+			userBuilder.WithTotpSecret(totpSecret.String)
+		}
+
+		if totpEnabledAt.Valid {
+			userBuilder.WithTotpEnabledAt(&totpEnabledAt.Time)
+		}
+
+		if totpLastUsedStep.Valid {
+			userBuilder.WithTotpLastUsedStep(totpLastUsedStep.Int64)
+		}
+
 		if createdAt.Valid {
 			userBuilder.WithCreatedAt(createdAt.Time)
 		}
@@ -110,6 +236,10 @@ WHERE 1 = 1 `
 			userTypeBuilder.WithID(userTypeID.Int64)
 		}
 
+		if userTypeTenantID.Valid {
+			userTypeBuilder.WithTenantID(userTypeTenantID.Int64)
+		}
+
 		if userTypeName.Valid {
 			userTypeBuilder.WithName(userTypeName.String)
 		}
@@ -118,6 +248,10 @@ WHERE 1 = 1 `
 			userTypeBuilder.WithDisabled(userTypeDisabled.Bool)
 		}
 
+		if userTypeRequiresMfa.Valid {
+			userTypeBuilder.WithRequiresMfa(userTypeRequiresMfa.Bool)
+		}
+
 		if userTypeCreatedAt.Valid {
 			userTypeBuilder.WithCreatedAt(userTypeCreatedAt.Time)
 		}
@@ -138,6 +272,24 @@ WHERE 1 = 1 `
 	return res, nil
 }
 
+func (r *userRepository) FindOneByID(ctx *context.RequestContext, id int64) (*model.User, *apperror.AppError) {
+	res, err := r.Find(
+		ctx,
+		utils.NewUserFindFilters().WithIDValue(id),
+		utils.NewUserFindOptions().WithOffsetValue(0).WithLimitValue(1),
+	)
+
+	if err != nil {
+		return nil, apperror.NewDbAppError(ctx, err, UserRepositorySourceName)
+	}
+
+	if len(res) > 0 {
+		return res[0], nil
+	}
+
+	return nil, nil
+}
+
 func (r *userRepository) FindOneByUsername(ctx *context.RequestContext, username string) (*model.User, *apperror.AppError) {
 	res, err := r.Find(
 		ctx,
@@ -156,15 +308,72 @@ func (r *userRepository) FindOneByUsername(ctx *context.RequestContext, username
 	return nil, nil
 }
 
+// Create The user is created on the tenant of the context. Its user type must belong to that tenant too, or a model
+// not found error is returned.
 func (r *userRepository) Create(ctx *context.RequestContext, user *model.User) *apperror.AppError {
-	query := `INSERT INTO users (username, user_type_id, disabled, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`
+	query := `INSERT INTO users (
+		tenant_id,
+		username,
+		user_type_id,
+		disabled,
+		email,
+		display_name,
+		locale,
+		timezone,
+		email_verified_at,
+		password_hash,
+		password_changed_at,
+		failed_login_attempts,
+		locked_until,
+		totp_secret,
+		totp_enabled_at,
+		totp_last_used_step,
+		created_at,
+		updated_at
+	) SELECT ut.tenant_id, ?, ut.id, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+	FROM user_types ut
+	WHERE ut.id = ? AND ut.tenant_id = ?`
+
+	res, err := GetExecutor(ctx, r.db).Exec(
+		query,
+		user.Username,
+		user.Disabled,
+		user.Email,
+		user.DisplayName,
+		user.Locale,
+		user.Timezone,
+		user.EmailVerifiedAt,
+		user.PasswordHash,
+		user.PasswordChangedAt,
+		user.FailedLoginAttempts,
+		user.LockedUntil,
+		user.TotpSecret,
+		user.TotpEnabledAt,
+		user.TotpLastUsedStep,
+		user.CreatedAt,
+		user.UpdatedAt,
+		user.UserType.ID,
+		ctx.GetTenantID(),
+	)
 
-	res, err := r.db.Exec(query, user.Username, user.UserType.ID, user.Disabled, user.CreatedAt, user.UpdatedAt)
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, UserRepositorySourceName)
+	}
+
+	affected, err := res.RowsAffected()
 
 	if err != nil {
 		return apperror.NewDbAppError(ctx, err, UserRepositorySourceName)
 	}
 
+	if affected == 0 {
+		return apperror.NewModelNotFoundAppError(
+			ctx,
+			fmt.Errorf("user type %d was not found on tenant %d", user.UserType.ID, ctx.GetTenantID()),
+			UserRepositorySourceName,
+		)
+	}
+
 	lastInsertId, err := res.LastInsertId()
 
 	if err != nil {
@@ -172,38 +381,164 @@ func (r *userRepository) Create(ctx *context.RequestContext, user *model.User) *
 	}
 
 	user.ID = lastInsertId
+	user.TenantID = ctx.GetTenantID()
 
 	return nil
 }
 
+// Update Only users of the tenant of the context can be updated, and only with user types of that tenant.
 func (r *userRepository) Update(ctx *context.RequestContext, user *model.User) *apperror.AppError {
 	query := `UPDATE users
 	SET username = ?,
 		user_type_id = ?,
 		disabled = ?,
+		email = ?,
+		display_name = ?,
+		locale = ?,
+		timezone = ?,
+		email_verified_at = ?,
+		password_hash = ?,
+		password_changed_at = ?,
+		failed_login_attempts = ?,
+		locked_until = ?,
+		totp_secret = ?,
+		totp_enabled_at = ?,
+		totp_last_used_step = ?,
+		updated_at = ?
+	WHERE id = ?
+		AND tenant_id = ?
+		AND EXISTS (SELECT 1 FROM user_types ut WHERE ut.id = ? AND ut.tenant_id = users.tenant_id)`
+
+	return execScoped(
+		ctx,
+		r.db,
+		UserRepositorySourceName,
+		query,
+		user.Username,
+		user.UserType.ID,
+		user.Disabled,
+		user.Email,
+		user.DisplayName,
+		user.Locale,
+		user.Timezone,
+		user.EmailVerifiedAt,
+		user.PasswordHash,
+		user.PasswordChangedAt,
+		user.FailedLoginAttempts,
+		user.LockedUntil,
+		user.TotpSecret,
+		user.TotpEnabledAt,
+		user.TotpLastUsedStep,
+		user.UpdatedAt,
+		user.ID,
+		ctx.GetTenantID(),
+		user.UserType.ID,
+	)
+}
+
+// RecordFailedLogin Counts a failed login of the user. If its lockout expired before now, the lockout is cleared and the
+// counter restarts from this failed login, so the user gets every attempt again. The counter is incremented by the
+// database, so concurrent failed logins are all counted. Once it reaches maxFailedLogins, the user is locked out until
+// lockedUntil, unless it's already locked out. The lockout fields of the user are refreshed with the stored ones. Returns whether this failed login locked the user out. It must run within a transaction.
+func (r *userRepository) RecordFailedLogin(
+	ctx *context.RequestContext,
+	user *model.User,
+	maxFailedLogins int,
+	now time.Time,
+	lockedUntil time.Time,
+) (bool, *apperror.AppError) {
+	query := `UPDATE users
+	SET failed_login_attempts = CASE WHEN locked_until <= ? THEN 1 ELSE failed_login_attempts + 1 END,
+		locked_until = CASE WHEN locked_until <= ? THEN NULL ELSE locked_until END,
 		updated_at = ?
-	WHERE id = ?`
+	WHERE id = ? AND tenant_id = ?`
 
-	_, err := r.db.Exec(query, user.Username, user.UserType.ID, user.Disabled, user.UpdatedAt, user.ID)
+	if err := execScoped(ctx, r.db, UserRepositorySourceName, query, now, now, now, user.ID, ctx.GetTenantID()); err != nil {
+		return false, err
+	}
+
+	query = `UPDATE users
+	SET locked_until = ?
+	WHERE id = ? AND tenant_id = ? AND failed_login_attempts >= ? AND locked_until IS NULL`
+
+	res, err := GetExecutor(ctx, r.db).Exec(query, lockedUntil, user.ID, ctx.GetTenantID(), maxFailedLogins)
 
 	if err != nil {
-		return apperror.NewDbAppError(ctx, err, UserRepositorySourceName)
+		return false, apperror.NewDbAppError(ctx, err, UserRepositorySourceName)
 	}
 
-	return nil
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, UserRepositorySourceName)
+	}
+
+	var failedLoginAttempts sql.NullInt64
+	var storedLockedUntil sql.NullTime
+
+	query = `SELECT failed_login_attempts, locked_until FROM users WHERE id = ? AND tenant_id = ?`
+
+	err = GetExecutor(ctx, r.db).QueryRow(query, user.ID, ctx.GetTenantID()).Scan(&failedLoginAttempts, &storedLockedUntil)
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, UserRepositorySourceName)
+	}
+
+	user.FailedLoginAttempts = int(failedLoginAttempts.Int64)
+	user.LockedUntil = nil
+	user.UpdatedAt = now
+
+	if storedLockedUntil.Valid {
+		user.LockedUntil = &storedLockedUntil.Time
+	}
+
+	return affected > 0, nil
 }
 
-func (r *userRepository) Delete(ctx *context.RequestContext, user *model.User) *apperror.AppError {
-	query := `DELETE FROM users
-	WHERE id = ?`
+// UseTotpStep Stores the time step of the TOTP code used by the user, only if it's newer than the stored one. Returns
+// false if it's not, meaning the code (or a newer one) was already used, for example by a concurrent login.
+func (r *userRepository) UseTotpStep(ctx *context.RequestContext, user *model.User, step int64) (bool, *apperror.AppError) {
+	query := `UPDATE users
+	SET totp_last_used_step = ?
+	WHERE id = ? AND tenant_id = ? AND totp_last_used_step < ?`
 
-	_, err := r.db.Exec(query, user.ID)
+	res, err := GetExecutor(ctx, r.db).Exec(query, step, user.ID, ctx.GetTenantID(), step)
 
 	if err != nil {
-		return apperror.NewDbAppError(ctx, err, UserRepositorySourceName)
+		return false, apperror.NewDbAppError(ctx, err, UserRepositorySourceName)
 	}
 
-	return nil
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, UserRepositorySourceName)
+	}
+
+	if affected == 0 {
+		return false, nil
+	}
+
+	user.TotpLastUsedStep = step
+
+	return true, nil
+}
+
+// Delete Password reset tokens and recovery codes of the user are deleted too. Only users of the tenant of the context
+// can be deleted.
+func (r *userRepository) Delete(ctx *context.RequestContext, user *model.User) *apperror.AppError {
+	for _, query := range []string{
+		`DELETE FROM password_reset_tokens WHERE user_id = ? AND ` + userOfTenantCondition,
+		`DELETE FROM recovery_codes WHERE user_id = ? AND ` + userOfTenantCondition,
+	} {
+		if _, err := GetExecutor(ctx, r.db).Exec(query, user.ID, ctx.GetTenantID()); err != nil {
+			return apperror.NewDbAppError(ctx, err, UserRepositorySourceName)
+		}
+	}
+
+	query := `DELETE FROM users
+	WHERE id = ? AND tenant_id = ?`
+
+	return execScoped(ctx, r.db, UserRepositorySourceName, query, user.ID, ctx.GetTenantID())
 }
 
 // Static functions
@@ -42,9 +42,9 @@ func (r *userTypeRepository) Count(ctx *context.RequestContext, filters *utils.U
 
 	countOptions.WithCount(true)
 
-	query, bindings := r.createSelectQuery(filters, &countOptions)
+	query, bindings := r.createSelectQuery(ctx, filters, &countOptions)
 
-	row := r.db.QueryRow(query, bindings...)
+	row := GetExecutor(ctx, r.db).QueryRow(query, bindings...)
 	count := int64(0)
 
 	err := row.Scan(&count)
@@ -57,26 +57,30 @@ func (r *userTypeRepository) Count(ctx *context.RequestContext, filters *utils.U
 }
 
 func (r *userTypeRepository) Find(ctx *context.RequestContext, filters *utils.UserTypeFindFilters, options *utils.UserTypeFindOptions) ([]*model.UserType, *apperror.AppError) {
-	query, bindings := r.createSelectQuery(filters, options)
+	query, bindings := r.createSelectQuery(ctx, filters, options)
 
-	rows, err := r.db.Query(query, bindings...)
+	rows, err := GetExecutor(ctx, r.db).Query(query, bindings...)
 
 	if err != nil {
 		return nil, apperror.NewDbAppError(ctx, err, UserTypeRepositorySourceName)
 	}
 
+	defer rows.Close()
+
 	res := make([]*model.UserType, 0)
 
 	for rows.Next() {
 		builder := model.NewUserTypeBuilder()
 
 		ID := sql.NullInt64{}
+		tenantID := sql.NullInt64{}
 		name := sql.NullString{}
 		disabled := sql.NullBool{}
+		requiresMfa := sql.NullBool{}
 		createdAt := sql.NullTime{}
 		updatedAt := sql.NullTime{}
 
-		err = rows.Scan(&ID, &name, &disabled, &createdAt, &updatedAt)
+		err = rows.Scan(&ID, &tenantID, &name, &disabled, &requiresMfa, &createdAt, &updatedAt)
 
 		if err != nil {
 			return nil, apperror.NewDbAppError(ctx, err, UserTypeRepositorySourceName)
@@ -86,6 +90,10 @@ func (r *userTypeRepository) Find(ctx *context.RequestContext, filters *utils.Us
 			builder.WithID(ID.Int64)
 		}
 
+		if tenantID.Valid {
+			builder.WithTenantID(tenantID.Int64)
+		}
+
 		if name.Valid {
 			builder.WithName(name.String)
 		}
@@ -94,6 +102,10 @@ func (r *userTypeRepository) Find(ctx *context.RequestContext, filters *utils.Us
 			builder.WithDisabled(disabled.Bool)
 		}
 
+		if requiresMfa.Valid {
+			builder.WithRequiresMfa(requiresMfa.Bool)
+		}
+
 		if createdAt.Valid {
 			builder.WithCreatedAt(createdAt.Time)
 		}
@@ -134,16 +146,17 @@ func (r *userTypeRepository) FindOneByName(ctx *context.RequestContext, name str
 	return nil, nil
 }
 
+// Create The user type is created on the tenant of the context.
 func (r *userTypeRepository) Create(ctx *context.RequestContext, userType *model.UserType) *apperror.AppError {
 	qb := sqlbuilder.NewInsertBuilder()
 
 	qb.InsertInto("user_types").
-		Cols("name", "disabled", "created_at", "updated_at").
-		Values(userType.Name, userType.Disabled, userType.CreatedAt, userType.UpdatedAt)
+		Cols("tenant_id", "name", "disabled", "requires_mfa", "created_at", "updated_at").
+		Values(ctx.GetTenantID(), userType.Name, userType.Disabled, userType.RequiresMfa, userType.CreatedAt, userType.UpdatedAt)
 
 	query, bindings := qb.Build()
 
-	res, err := r.db.Exec(query, bindings...)
+	res, err := GetExecutor(ctx, r.db).Exec(query, bindings...)
 
 	if err != nil {
 		return apperror.NewDbAppError(ctx, err, UserTypeRepositorySourceName)
@@ -156,49 +169,48 @@ func (r *userTypeRepository) Create(ctx *context.RequestContext, userType *model
 	}
 
 	userType.ID = lastInsertId
+	userType.TenantID = ctx.GetTenantID()
 
 	return nil
 }
 
+// Update Only user types of the tenant of the context can be updated.
 func (r *userTypeRepository) Update(ctx *context.RequestContext, userType *model.UserType) *apperror.AppError {
 	qb := sqlbuilder.NewUpdateBuilder()
 
 	qb.Update("user_types").
-		Set(qb.Assign("name", userType.Name)).
-		Set(qb.Assign("disabled", userType.Disabled)).
-		Set(qb.Assign("created_at", userType.CreatedAt)).
-		Set(qb.Assign("updated_at", userType.UpdatedAt)).
-		Where(qb.Equal("id", userType.ID))
+		Set(
+			qb.Assign("name", userType.Name),
+			qb.Assign("disabled", userType.Disabled),
+			qb.Assign("requires_mfa", userType.RequiresMfa),
+			qb.Assign("created_at", userType.CreatedAt),
+			qb.Assign("updated_at", userType.UpdatedAt),
+		).
+		Where(qb.Equal("id", userType.ID), qb.Equal("tenant_id", ctx.GetTenantID()))
 
 	query, bindings := qb.Build()
 
-	_, err := r.db.Exec(query, bindings...)
-
-	if err != nil {
-		return apperror.NewDbAppError(ctx, err, UserTypeRepositorySourceName)
-	}
-
-	return nil
+	return execScoped(ctx, r.db, UserTypeRepositorySourceName, query, bindings...)
 }
 
+// Delete Only user types of the tenant of the context can be deleted.
 func (r *userTypeRepository) Delete(ctx *context.RequestContext, userType *model.UserType) *apperror.AppError {
 	qb := sqlbuilder.NewDeleteBuilder()
 
 	qb.DeleteFrom("user_types").
-		Where(qb.Equal("id", userType.ID))
+		Where(qb.Equal("id", userType.ID), qb.Equal("tenant_id", ctx.GetTenantID()))
 
 	query, bindings := qb.Build()
 
-	_, err := r.db.Exec(query, bindings...)
-
-	if err != nil {
-		return apperror.NewDbAppError(ctx, err, UserTypeRepositorySourceName)
-	}
-
-	return nil
+	return execScoped(ctx, r.db, UserTypeRepositorySourceName, query, bindings...)
 }
 
-func (r *userTypeRepository) createSelectQuery(filters *utils.UserTypeFindFilters, options *utils.UserTypeFindOptions) (string, []interface{}) {
+// createSelectQuery Only user types of the tenant of the context are selected.
+func (r *userTypeRepository) createSelectQuery(
+	ctx *context.RequestContext,
+	filters *utils.UserTypeFindFilters,
+	options *utils.UserTypeFindOptions,
+) (string, []interface{}) {
 	sb := sqlbuilder.NewSelectBuilder()
 
 	if options.IsCount() {
@@ -206,14 +218,17 @@ func (r *userTypeRepository) createSelectQuery(filters *utils.UserTypeFindFilter
 	} else {
 		sb.Select(
 			"u.id",
+			"u.tenant_id",
 			"u.name",
 			"u.disabled",
+			"u.requires_mfa",
 			"u.created_at",
 			"u.updated_at",
 		)
 	}
 
-	sb.From(sb.As("user_types", "u"))
+	sb.From(sb.As("user_types", "u")).
+		Where(sb.Equal("u.tenant_id", ctx.GetTenantID()))
 
 	if filters.GetName() != nil {
 		sb.Where(sb.Equal("u.name", filters.GetNameValue()))
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/cache"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/rs/zerolog"
+)
+
+// Structs
+
+// cachingUserTypeRepository Caches the user types found by name, as they are read by the "user_type" validator on every
+// user creation and update. Entries are keyed by tenant too, as names are only unique per tenant. Entries are invalidated by the user type service once its mutations are committed, so
+// reads made inside a transaction always go to the database.
+type cachingUserTypeRepository struct {
+	UserTypeRepository
+
+	cache  cache.Cache
+	ttl    time.Duration
+	logger *zerolog.Logger
+}
+
+func (r *cachingUserTypeRepository) FindOneByName(ctx *context.RequestContext, name string) (*model.UserType, *apperror.AppError) {
+	if ctx.HasTx() {
+		return r.UserTypeRepository.FindOneByName(ctx, name)
+	}
+
+	key := cache.Key(cache.UserTypeTag, strconv.FormatInt(ctx.GetTenantID(), 10), name)
+
+	if value, found := r.cache.Get(key); found {
+		userType := &model.UserType{}
+
+		if err := json.Unmarshal(value, userType); err == nil {
+			return userType, nil
+		}
+	}
+
+	userType, err := r.UserTypeRepository.FindOneByName(ctx, name)
+
+	if err != nil || userType == nil {
+		return userType, err
+	}
+
+	if value, err := json.Marshal(userType); err == nil {
+		r.cache.Set(key, value, r.ttl)
+	} else {
+		r.logger.Warn().Msgf("[CachingUserTypeRepository] Could NOT cache user type '%s': %s", name, err)
+	}
+
+	return userType, nil
+}
+
+// Static functions
+
+func NewCachingUserTypeRepository(
+	userTypeRepository UserTypeRepository,
+	cache cache.Cache,
+	ttl time.Duration,
+	logger *zerolog.Logger,
+) UserTypeRepository {
+	return &cachingUserTypeRepository{
+		UserTypeRepository: userTypeRepository,
+		cache:              cache,
+		ttl:                ttl,
+		logger:             logger,
+	}
+}
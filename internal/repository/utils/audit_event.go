@@ -0,0 +1,231 @@
+package utils
+
+import (
+	"strings"
+	"time"
+)
+
+// Structs
+
+// AuditEventFindFilters
+
+type AuditEventFindFilters struct {
+	entityType *string
+	entityID   *int64
+	entityKey  *string
+	actor      *string
+	from       *time.Time
+	to         *time.Time
+}
+
+func (f *AuditEventFindFilters) WithEntityType(entityType *string) *AuditEventFindFilters {
+	f.entityType = entityType
+
+	return f
+}
+
+func (f *AuditEventFindFilters) WithEntityTypeValue(entityType string) *AuditEventFindFilters {
+	return f.WithEntityType(&entityType)
+}
+
+func (f *AuditEventFindFilters) WithEntityID(entityID *int64) *AuditEventFindFilters {
+	f.entityID = entityID
+
+	return f
+}
+
+func (f *AuditEventFindFilters) WithEntityIDValue(entityID int64) *AuditEventFindFilters {
+	return f.WithEntityID(&entityID)
+}
+
+func (f *AuditEventFindFilters) WithEntityKey(entityKey *string) *AuditEventFindFilters {
+	f.entityKey = entityKey
+
+	return f
+}
+
+func (f *AuditEventFindFilters) WithEntityKeyValue(entityKey string) *AuditEventFindFilters {
+	return f.WithEntityKey(&entityKey)
+}
+
+func (f *AuditEventFindFilters) WithActor(actor *string) *AuditEventFindFilters {
+	f.actor = actor
+
+	return f
+}
+
+func (f *AuditEventFindFilters) WithActorValue(actor string) *AuditEventFindFilters {
+	return f.WithActor(&actor)
+}
+
+func (f *AuditEventFindFilters) WithFrom(from *time.Time) *AuditEventFindFilters {
+	f.from = from
+
+	return f
+}
+
+func (f *AuditEventFindFilters) WithTo(to *time.Time) *AuditEventFindFilters {
+	f.to = to
+
+	return f
+}
+
+func (f *AuditEventFindFilters) GetEntityType() *string {
+	return f.entityType
+}
+
+func (f *AuditEventFindFilters) GetEntityTypeValue() string {
+	return *f.entityType
+}
+
+func (f *AuditEventFindFilters) GetEntityID() *int64 {
+	return f.entityID
+}
+
+func (f *AuditEventFindFilters) GetEntityIDValue() int64 {
+	return *f.entityID
+}
+
+func (f *AuditEventFindFilters) GetEntityKey() *string {
+	return f.entityKey
+}
+
+func (f *AuditEventFindFilters) GetEntityKeyValue() string {
+	return *f.entityKey
+}
+
+func (f *AuditEventFindFilters) GetActor() *string {
+	return f.actor
+}
+
+func (f *AuditEventFindFilters) GetActorValue() string {
+	return *f.actor
+}
+
+func (f *AuditEventFindFilters) GetFrom() *time.Time {
+	return f.from
+}
+
+func (f *AuditEventFindFilters) GetFromValue() time.Time {
+	return *f.from
+}
+
+func (f *AuditEventFindFilters) GetTo() *time.Time {
+	return f.to
+}
+
+func (f *AuditEventFindFilters) GetToValue() time.Time {
+	return *f.to
+}
+
+// Options
+
+// AuditEventFindOptions
+
+type AuditEventFindOptions struct {
+	FindOptions
+}
+
+func (f *AuditEventFindOptions) WithSortBy(sortBy *string) *AuditEventFindOptions {
+	f.sortBy = sortBy
+
+	return f
+}
+
+func (f *AuditEventFindOptions) WithSortByValue(sortBy string) *AuditEventFindOptions {
+	return f.WithSortBy(&sortBy)
+}
+
+func (f *AuditEventFindOptions) WithSortDir(sortDir *string) *AuditEventFindOptions {
+	if sortDir != nil {
+		*sortDir = strings.ToUpper(*sortDir)
+	}
+
+	f.sortDir = sortDir
+
+	return f
+}
+
+func (f *AuditEventFindOptions) WithSortDirValue(sortDir string) *AuditEventFindOptions {
+	return f.WithSortDir(&sortDir)
+}
+
+func (f *AuditEventFindOptions) WithOffset(offset *int) *AuditEventFindOptions {
+	f.offset = offset
+
+	return f
+}
+
+func (f *AuditEventFindOptions) WithOffsetValue(offset int) *AuditEventFindOptions {
+	return f.WithOffset(&offset)
+}
+
+func (f *AuditEventFindOptions) WithLimit(limit *int) *AuditEventFindOptions {
+	f.limit = limit
+
+	return f
+}
+
+func (f *AuditEventFindOptions) WithLimitValue(limit int) *AuditEventFindOptions {
+	return f.WithLimit(&limit)
+}
+
+func (f *AuditEventFindOptions) WithCount(count bool) *AuditEventFindOptions {
+	f.count = count
+
+	return f
+}
+
+func (f *AuditEventFindOptions) GetSortBy() *string {
+	return f.sortBy
+}
+
+func (f *AuditEventFindOptions) GetSortByValue() string {
+	return *f.sortBy
+}
+
+func (f *AuditEventFindOptions) GetSortDir() *string {
+	return f.sortDir
+}
+
+func (f *AuditEventFindOptions) GetSortDirValue() string {
+	return *f.sortDir
+}
+
+func (f *AuditEventFindOptions) GetOffset() *int {
+	return f.offset
+}
+
+func (f *AuditEventFindOptions) GetOffsetValue() int {
+	return *f.offset
+}
+
+func (f *AuditEventFindOptions) GetLimit() *int {
+	return f.limit
+}
+
+func (f *AuditEventFindOptions) GetLimitValue() int {
+	return *f.limit
+}
+
+func (f *AuditEventFindOptions) IsCount() bool {
+	return f.count
+}
+
+func (f *AuditEventFindOptions) IsAsc() bool {
+	return f.GetSortDir() != nil && f.GetSortDirValue() == SortDirAsc
+}
+
+func (f *AuditEventFindOptions) IsDesc() bool {
+	return f.GetSortDir() != nil && f.GetSortDirValue() == SortDirDesc
+}
+
+// Static functions
+
+func NewAuditEventFindFilters() *AuditEventFindFilters {
+	return &AuditEventFindFilters{}
+}
+
+func NewAuditEventFindOptions() *AuditEventFindOptions {
+	return &AuditEventFindOptions{}
+}
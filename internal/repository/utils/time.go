@@ -0,0 +1,23 @@
+package utils
+
+import "time"
+
+// Constants
+
+// TimePrecision Precision times are stored with. SQLite stores them as text, so times with different precisions or
+// time zones would neither compare as equal once read back, nor sort correctly in queries.
+const (
+	TimePrecision = time.Microsecond
+)
+
+// Static functions
+
+// NormalizeTime Returns the time in UTC, truncated to TimePrecision and without the monotonic clock reading, so it's
+// equal to the time read back from the DB.
+func NormalizeTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+
+	return t.UTC().Truncate(TimePrecision)
+}
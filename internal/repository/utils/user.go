@@ -5,7 +5,27 @@ package utils
 // UserFindFilters
 
 type UserFindFilters struct {
+	id       *int64
 	username *string
+	email    *string
+}
+
+func (u *UserFindFilters) WithID(id *int64) *UserFindFilters {
+	u.id = id
+
+	return u
+}
+
+func (u *UserFindFilters) WithIDValue(id int64) *UserFindFilters {
+	return u.WithID(&id)
+}
+
+func (u *UserFindFilters) GetID() *int64 {
+	return u.id
+}
+
+func (u *UserFindFilters) GetIDValue() int64 {
+	return *u.id
 }
 
 func (u *UserFindFilters) WithUsername(username *string) *UserFindFilters {
@@ -26,6 +46,24 @@ func (u *UserFindFilters) GetUsernameValue() string {
 	return *u.username
 }
 
+func (u *UserFindFilters) WithEmail(email *string) *UserFindFilters {
+	u.email = email
+
+	return u
+}
+
+func (u *UserFindFilters) WithEmailValue(email string) *UserFindFilters {
+	return u.WithEmail(&email)
+}
+
+func (u *UserFindFilters) GetEmail() *string {
+	return u.email
+}
+
+func (u *UserFindFilters) GetEmailValue() string {
+	return *u.email
+}
+
 // Options
 
 // UserFindOptions
@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"strings"
+	"time"
+)
+
+// Structs
+
+// WebhookDeliveryFindFilters
+
+type WebhookDeliveryFindFilters struct {
+	id             *int64
+	subscriptionID *int64
+	status         *string
+	dueAt          *time.Time
+}
+
+func (f *WebhookDeliveryFindFilters) WithID(ID *int64) *WebhookDeliveryFindFilters {
+	f.id = ID
+
+	return f
+}
+
+func (f *WebhookDeliveryFindFilters) WithIDValue(ID int64) *WebhookDeliveryFindFilters {
+	return f.WithID(&ID)
+}
+
+func (f *WebhookDeliveryFindFilters) WithSubscriptionID(subscriptionID *int64) *WebhookDeliveryFindFilters {
+	f.subscriptionID = subscriptionID
+
+	return f
+}
+
+func (f *WebhookDeliveryFindFilters) WithSubscriptionIDValue(subscriptionID int64) *WebhookDeliveryFindFilters {
+	return f.WithSubscriptionID(&subscriptionID)
+}
+
+func (f *WebhookDeliveryFindFilters) WithStatus(status *string) *WebhookDeliveryFindFilters {
+	f.status = status
+
+	return f
+}
+
+func (f *WebhookDeliveryFindFilters) WithStatusValue(status string) *WebhookDeliveryFindFilters {
+	return f.WithStatus(&status)
+}
+
+// WithDueAt Only returns deliveries whose next attempt is due at the given time.
+func (f *WebhookDeliveryFindFilters) WithDueAt(dueAt *time.Time) *WebhookDeliveryFindFilters {
+	f.dueAt = dueAt
+
+	return f
+}
+
+func (f *WebhookDeliveryFindFilters) WithDueAtValue(dueAt time.Time) *WebhookDeliveryFindFilters {
+	return f.WithDueAt(&dueAt)
+}
+
+func (f *WebhookDeliveryFindFilters) GetID() *int64 {
+	return f.id
+}
+
+func (f *WebhookDeliveryFindFilters) GetIDValue() int64 {
+	return *f.id
+}
+
+func (f *WebhookDeliveryFindFilters) GetSubscriptionID() *int64 {
+	return f.subscriptionID
+}
+
+func (f *WebhookDeliveryFindFilters) GetSubscriptionIDValue() int64 {
+	return *f.subscriptionID
+}
+
+func (f *WebhookDeliveryFindFilters) GetStatus() *string {
+	return f.status
+}
+
+func (f *WebhookDeliveryFindFilters) GetStatusValue() string {
+	return *f.status
+}
+
+func (f *WebhookDeliveryFindFilters) GetDueAt() *time.Time {
+	return f.dueAt
+}
+
+func (f *WebhookDeliveryFindFilters) GetDueAtValue() time.Time {
+	return *f.dueAt
+}
+
+// Options
+
+// WebhookDeliveryFindOptions
+
+type WebhookDeliveryFindOptions struct {
+	FindOptions
+}
+
+func (f *WebhookDeliveryFindOptions) WithSortBy(sortBy *string) *WebhookDeliveryFindOptions {
+	f.sortBy = sortBy
+
+	return f
+}
+
+func (f *WebhookDeliveryFindOptions) WithSortByValue(sortBy string) *WebhookDeliveryFindOptions {
+	return f.WithSortBy(&sortBy)
+}
+
+func (f *WebhookDeliveryFindOptions) WithSortDir(sortDir *string) *WebhookDeliveryFindOptions {
+	if sortDir != nil {
+		*sortDir = strings.ToUpper(*sortDir)
+	}
+
+	f.sortDir = sortDir
+
+	return f
+}
+
+func (f *WebhookDeliveryFindOptions) WithSortDirValue(sortDir string) *WebhookDeliveryFindOptions {
+	return f.WithSortDir(&sortDir)
+}
+
+func (f *WebhookDeliveryFindOptions) WithOffset(offset *int) *WebhookDeliveryFindOptions {
+	f.offset = offset
+
+	return f
+}
+
+func (f *WebhookDeliveryFindOptions) WithOffsetValue(offset int) *WebhookDeliveryFindOptions {
+	return f.WithOffset(&offset)
+}
+
+func (f *WebhookDeliveryFindOptions) WithLimit(limit *int) *WebhookDeliveryFindOptions {
+	f.limit = limit
+
+	return f
+}
+
+func (f *WebhookDeliveryFindOptions) WithLimitValue(limit int) *WebhookDeliveryFindOptions {
+	return f.WithLimit(&limit)
+}
+
+func (f *WebhookDeliveryFindOptions) WithCount(count bool) *WebhookDeliveryFindOptions {
+	f.count = count
+
+	return f
+}
+
+func (f *WebhookDeliveryFindOptions) GetSortBy() *string {
+	return f.sortBy
+}
+
+func (f *WebhookDeliveryFindOptions) GetSortByValue() string {
+	return *f.sortBy
+}
+
+func (f *WebhookDeliveryFindOptions) GetSortDir() *string {
+	return f.sortDir
+}
+
+func (f *WebhookDeliveryFindOptions) GetSortDirValue() string {
+	return *f.sortDir
+}
+
+func (f *WebhookDeliveryFindOptions) GetOffset() *int {
+	return f.offset
+}
+
+func (f *WebhookDeliveryFindOptions) GetOffsetValue() int {
+	return *f.offset
+}
+
+func (f *WebhookDeliveryFindOptions) GetLimit() *int {
+	return f.limit
+}
+
+func (f *WebhookDeliveryFindOptions) GetLimitValue() int {
+	return *f.limit
+}
+
+func (f *WebhookDeliveryFindOptions) IsCount() bool {
+	return f.count
+}
+
+func (f *WebhookDeliveryFindOptions) IsAsc() bool {
+	return f.GetSortDir() != nil && f.GetSortDirValue() == SortDirAsc
+}
+
+func (f *WebhookDeliveryFindOptions) IsDesc() bool {
+	return f.GetSortDir() != nil && f.GetSortDirValue() == SortDirDesc
+}
+
+// Static functions
+
+func NewWebhookDeliveryFindFilters() *WebhookDeliveryFindFilters {
+	return &WebhookDeliveryFindFilters{}
+}
+
+func NewWebhookDeliveryFindOptions() *WebhookDeliveryFindOptions {
+	return &WebhookDeliveryFindOptions{}
+}
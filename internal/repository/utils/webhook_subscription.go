@@ -0,0 +1,160 @@
+package utils
+
+import "strings"
+
+// Structs
+
+// WebhookSubscriptionFindFilters
+
+type WebhookSubscriptionFindFilters struct {
+	id       *int64
+	disabled *bool
+}
+
+func (f *WebhookSubscriptionFindFilters) WithID(ID *int64) *WebhookSubscriptionFindFilters {
+	f.id = ID
+
+	return f
+}
+
+func (f *WebhookSubscriptionFindFilters) WithIDValue(ID int64) *WebhookSubscriptionFindFilters {
+	return f.WithID(&ID)
+}
+
+func (f *WebhookSubscriptionFindFilters) WithDisabled(disabled *bool) *WebhookSubscriptionFindFilters {
+	f.disabled = disabled
+
+	return f
+}
+
+func (f *WebhookSubscriptionFindFilters) WithDisabledValue(disabled bool) *WebhookSubscriptionFindFilters {
+	return f.WithDisabled(&disabled)
+}
+
+func (f *WebhookSubscriptionFindFilters) GetID() *int64 {
+	return f.id
+}
+
+func (f *WebhookSubscriptionFindFilters) GetIDValue() int64 {
+	return *f.id
+}
+
+func (f *WebhookSubscriptionFindFilters) GetDisabled() *bool {
+	return f.disabled
+}
+
+func (f *WebhookSubscriptionFindFilters) GetDisabledValue() bool {
+	return *f.disabled
+}
+
+// Options
+
+// WebhookSubscriptionFindOptions
+
+type WebhookSubscriptionFindOptions struct {
+	FindOptions
+}
+
+func (f *WebhookSubscriptionFindOptions) WithSortBy(sortBy *string) *WebhookSubscriptionFindOptions {
+	f.sortBy = sortBy
+
+	return f
+}
+
+func (f *WebhookSubscriptionFindOptions) WithSortByValue(sortBy string) *WebhookSubscriptionFindOptions {
+	return f.WithSortBy(&sortBy)
+}
+
+func (f *WebhookSubscriptionFindOptions) WithSortDir(sortDir *string) *WebhookSubscriptionFindOptions {
+	if sortDir != nil {
+		*sortDir = strings.ToUpper(*sortDir)
+	}
+
+	f.sortDir = sortDir
+
+	return f
+}
+
+func (f *WebhookSubscriptionFindOptions) WithSortDirValue(sortDir string) *WebhookSubscriptionFindOptions {
+	return f.WithSortDir(&sortDir)
+}
+
+func (f *WebhookSubscriptionFindOptions) WithOffset(offset *int) *WebhookSubscriptionFindOptions {
+	f.offset = offset
+
+	return f
+}
+
+func (f *WebhookSubscriptionFindOptions) WithOffsetValue(offset int) *WebhookSubscriptionFindOptions {
+	return f.WithOffset(&offset)
+}
+
+func (f *WebhookSubscriptionFindOptions) WithLimit(limit *int) *WebhookSubscriptionFindOptions {
+	f.limit = limit
+
+	return f
+}
+
+func (f *WebhookSubscriptionFindOptions) WithLimitValue(limit int) *WebhookSubscriptionFindOptions {
+	return f.WithLimit(&limit)
+}
+
+func (f *WebhookSubscriptionFindOptions) WithCount(count bool) *WebhookSubscriptionFindOptions {
+	f.count = count
+
+	return f
+}
+
+func (f *WebhookSubscriptionFindOptions) GetSortBy() *string {
+	return f.sortBy
+}
+
+func (f *WebhookSubscriptionFindOptions) GetSortByValue() string {
+	return *f.sortBy
+}
+
+func (f *WebhookSubscriptionFindOptions) GetSortDir() *string {
+	return f.sortDir
+}
+
+func (f *WebhookSubscriptionFindOptions) GetSortDirValue() string {
+	return *f.sortDir
+}
+
+func (f *WebhookSubscriptionFindOptions) GetOffset() *int {
+	return f.offset
+}
+
+func (f *WebhookSubscriptionFindOptions) GetOffsetValue() int {
+	return *f.offset
+}
+
+func (f *WebhookSubscriptionFindOptions) GetLimit() *int {
+	return f.limit
+}
+
+func (f *WebhookSubscriptionFindOptions) GetLimitValue() int {
+	return *f.limit
+}
+
+func (f *WebhookSubscriptionFindOptions) IsCount() bool {
+	return f.count
+}
+
+func (f *WebhookSubscriptionFindOptions) IsAsc() bool {
+	return f.GetSortDir() != nil && f.GetSortDirValue() == SortDirAsc
+}
+
+func (f *WebhookSubscriptionFindOptions) IsDesc() bool {
+	return f.GetSortDir() != nil && f.GetSortDirValue() == SortDirDesc
+}
+
+// Static functions
+
+func NewWebhookSubscriptionFindFilters() *WebhookSubscriptionFindFilters {
+	return &WebhookSubscriptionFindFilters{}
+}
+
+func NewWebhookSubscriptionFindOptions() *WebhookSubscriptionFindOptions {
+	return &WebhookSubscriptionFindOptions{}
+}
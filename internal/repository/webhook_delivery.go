@@ -0,0 +1,386 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/repository/utils"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/rs/zerolog"
+)
+
+// Constants
+
+const (
+	WebhookDeliveryRepositorySourceName = "WebhookDeliveryRepository"
+)
+
+// Interfaces
+
+type WebhookDeliveryRepository interface {
+	Count(ctx *context.RequestContext, filters *utils.WebhookDeliveryFindFilters, options *utils.WebhookDeliveryFindOptions) (int64, *apperror.AppError)
+	Find(ctx *context.RequestContext, filters *utils.WebhookDeliveryFindFilters, options *utils.WebhookDeliveryFindOptions) ([]*model.WebhookDelivery, *apperror.AppError)
+	FindOneByID(ctx *context.RequestContext, ID int64) (*model.WebhookDelivery, *apperror.AppError)
+	Create(ctx *context.RequestContext, webhookDelivery *model.WebhookDelivery) (bool, *apperror.AppError)
+	Update(ctx *context.RequestContext, webhookDelivery *model.WebhookDelivery) *apperror.AppError
+	CreateAttempt(ctx *context.RequestContext, webhookDeliveryAttempt *model.WebhookDeliveryAttempt) *apperror.AppError
+	LoadAttempts(ctx *context.RequestContext, webhookDeliveries []*model.WebhookDelivery) *apperror.AppError
+}
+
+// Structs
+
+type webhookDeliveryRepository struct {
+	appConfig config.AppConfig
+	db        *sql.DB
+	logger    *zerolog.Logger
+}
+
+func (r *webhookDeliveryRepository) Count(ctx *context.RequestContext, filters *utils.WebhookDeliveryFindFilters, options *utils.WebhookDeliveryFindOptions) (int64, *apperror.AppError) {
+	countOptions := *options
+
+	countOptions.WithCount(true)
+
+	query, bindings := r.createSelectQuery(filters, &countOptions)
+
+	row := GetExecutor(ctx, r.db).QueryRow(query, bindings...)
+	count := int64(0)
+
+	err := row.Scan(&count)
+
+	if err != nil {
+		return count, apperror.NewDbAppError(ctx, err, WebhookDeliveryRepositorySourceName)
+	}
+
+	return count, nil
+}
+
+func (r *webhookDeliveryRepository) Find(ctx *context.RequestContext, filters *utils.WebhookDeliveryFindFilters, options *utils.WebhookDeliveryFindOptions) ([]*model.WebhookDelivery, *apperror.AppError) {
+	query, bindings := r.createSelectQuery(filters, options)
+
+	rows, err := GetExecutor(ctx, r.db).Query(query, bindings...)
+
+	if err != nil {
+		return nil, apperror.NewDbAppError(ctx, err, WebhookDeliveryRepositorySourceName)
+	}
+
+	defer rows.Close()
+
+	res := make([]*model.WebhookDelivery, 0)
+
+	for rows.Next() {
+		builder := model.NewWebhookDeliveryBuilder()
+
+		ID := sql.NullInt64{}
+		subscriptionID := sql.NullInt64{}
+		eventID := sql.NullInt64{}
+		eventName := sql.NullString{}
+		payload := sql.NullString{}
+		status := sql.NullString{}
+		attempts := sql.NullInt64{}
+		nextAttemptAt := sql.NullTime{}
+		createdAt := sql.NullTime{}
+		updatedAt := sql.NullTime{}
+
+		err = rows.Scan(&ID, &subscriptionID, &eventID, &eventName, &payload, &status, &attempts, &nextAttemptAt, &createdAt, &updatedAt)
+
+		if err != nil {
+			return nil, apperror.NewDbAppError(ctx, err, WebhookDeliveryRepositorySourceName)
+		}
+
+		if ID.Valid {
+			builder.WithID(ID.Int64)
+		}
+
+		if subscriptionID.Valid {
+			builder.WithSubscriptionID(subscriptionID.Int64)
+		}
+
+		if eventID.Valid {
+			builder.WithEventID(eventID.Int64)
+		}
+
+		if eventName.Valid {
+			builder.WithEventName(eventName.String)
+		}
+
+		if payload.Valid {
+			builder.WithPayload(payload.String)
+		}
+
+		if status.Valid {
+			builder.WithStatus(status.String)
+		}
+
+		if attempts.Valid {
+			builder.WithAttempts(int(attempts.Int64))
+		}
+
+		if nextAttemptAt.Valid {
+			builder.WithNextAttemptAt(nextAttemptAt.Time)
+		}
+
+		if createdAt.Valid {
+			builder.WithCreatedAt(createdAt.Time)
+		}
+
+		if updatedAt.Valid {
+			builder.WithUpdatedAt(updatedAt.Time)
+		}
+
+		res = append(res, builder.Build())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDbAppError(ctx, err, WebhookDeliveryRepositorySourceName)
+	}
+
+	return res, nil
+}
+
+func (r *webhookDeliveryRepository) FindOneByID(ctx *context.RequestContext, ID int64) (*model.WebhookDelivery, *apperror.AppError) {
+	res, err := r.Find(
+		ctx,
+		utils.NewWebhookDeliveryFindFilters().WithIDValue(ID),
+		utils.NewWebhookDeliveryFindOptions().WithOffsetValue(0).WithLimitValue(1),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) > 0 {
+		return res[0], nil
+	}
+
+	return nil, nil
+}
+
+// Create Creates the delivery, unless the subscription already has a delivery for the same event (events are delivered
+// at-least-once, so we may receive the same one more than once). Returns true if the delivery was created.
+func (r *webhookDeliveryRepository) Create(ctx *context.RequestContext, webhookDelivery *model.WebhookDelivery) (bool, *apperror.AppError) {
+	query := `INSERT OR IGNORE INTO webhook_deliveries
+	(subscription_id, event_id, event_name, payload, status, attempts, next_attempt_at, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := GetExecutor(ctx, r.db).Exec(
+		query,
+		webhookDelivery.SubscriptionID,
+		webhookDelivery.EventID,
+		webhookDelivery.EventName,
+		webhookDelivery.Payload,
+		webhookDelivery.Status,
+		webhookDelivery.Attempts,
+		webhookDelivery.NextAttemptAt,
+		webhookDelivery.CreatedAt,
+		webhookDelivery.UpdatedAt,
+	)
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, WebhookDeliveryRepositorySourceName)
+	}
+
+	affected, err := res.RowsAffected()
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, WebhookDeliveryRepositorySourceName)
+	}
+
+	if affected < 1 {
+		return false, nil
+	}
+
+	lastInsertId, err := res.LastInsertId()
+
+	if err != nil {
+		return false, apperror.NewDbAppError(ctx, err, WebhookDeliveryRepositorySourceName)
+	}
+
+	webhookDelivery.ID = lastInsertId
+
+	return true, nil
+}
+
+func (r *webhookDeliveryRepository) Update(ctx *context.RequestContext, webhookDelivery *model.WebhookDelivery) *apperror.AppError {
+	qb := sqlbuilder.NewUpdateBuilder()
+
+	qb.Update("webhook_deliveries").
+		Set(
+			qb.Assign("status", webhookDelivery.Status),
+			qb.Assign("attempts", webhookDelivery.Attempts),
+			qb.Assign("next_attempt_at", webhookDelivery.NextAttemptAt),
+			qb.Assign("updated_at", webhookDelivery.UpdatedAt),
+		).
+		Where(qb.Equal("id", webhookDelivery.ID))
+
+	query, bindings := qb.Build()
+
+	_, err := GetExecutor(ctx, r.db).Exec(query, bindings...)
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, WebhookDeliveryRepositorySourceName)
+	}
+
+	return nil
+}
+
+func (r *webhookDeliveryRepository) CreateAttempt(ctx *context.RequestContext, webhookDeliveryAttempt *model.WebhookDeliveryAttempt) *apperror.AppError {
+	qb := sqlbuilder.NewInsertBuilder()
+
+	qb.InsertInto("webhook_delivery_attempts").
+		Cols("delivery_id", "attempt", "status_code", "error", "duration_ms", "created_at").
+		Values(
+			webhookDeliveryAttempt.DeliveryID,
+			webhookDeliveryAttempt.Attempt,
+			webhookDeliveryAttempt.StatusCode,
+			webhookDeliveryAttempt.Error,
+			webhookDeliveryAttempt.Duration.Milliseconds(),
+			webhookDeliveryAttempt.CreatedAt,
+		)
+
+	query, bindings := qb.Build()
+
+	res, err := GetExecutor(ctx, r.db).Exec(query, bindings...)
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, WebhookDeliveryRepositorySourceName)
+	}
+
+	lastInsertId, err := res.LastInsertId()
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, WebhookDeliveryRepositorySourceName)
+	}
+
+	webhookDeliveryAttempt.ID = lastInsertId
+
+	return nil
+}
+
+// LoadAttempts Sets the attempt log of every delivery, oldest attempt first.
+func (r *webhookDeliveryRepository) LoadAttempts(ctx *context.RequestContext, webhookDeliveries []*model.WebhookDelivery) *apperror.AppError {
+	if len(webhookDeliveries) < 1 {
+		return nil
+	}
+
+	deliveriesByID := make(map[int64]*model.WebhookDelivery)
+	deliveryIDs := make([]interface{}, 0, len(webhookDeliveries))
+
+	for _, webhookDelivery := range webhookDeliveries {
+		deliveriesByID[webhookDelivery.ID] = webhookDelivery
+		deliveryIDs = append(deliveryIDs, webhookDelivery.ID)
+	}
+
+	sb := sqlbuilder.NewSelectBuilder()
+
+	sb.Select("a.id", "a.delivery_id", "a.attempt", "a.status_code", "a.error", "a.duration_ms", "a.created_at").
+		From(sb.As("webhook_delivery_attempts", "a")).
+		Where(sb.In("a.delivery_id", deliveryIDs...)).
+		OrderBy("a.id").
+		Asc()
+
+	query, bindings := sb.Build()
+
+	rows, err := GetExecutor(ctx, r.db).Query(query, bindings...)
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, WebhookDeliveryRepositorySourceName)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		attempt := &model.WebhookDeliveryAttempt{}
+		durationMs := int64(0)
+
+		err = rows.Scan(&attempt.ID, &attempt.DeliveryID, &attempt.Attempt, &attempt.StatusCode, &attempt.Error, &durationMs, &attempt.CreatedAt)
+
+		if err != nil {
+			return apperror.NewDbAppError(ctx, err, WebhookDeliveryRepositorySourceName)
+		}
+
+		attempt.Duration = time.Duration(durationMs) * time.Millisecond
+
+		if webhookDelivery, found := deliveriesByID[attempt.DeliveryID]; found {
+			webhookDelivery.AttemptLog = append(webhookDelivery.AttemptLog, attempt)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return apperror.NewDbAppError(ctx, err, WebhookDeliveryRepositorySourceName)
+	}
+
+	return nil
+}
+
+func (r *webhookDeliveryRepository) createSelectQuery(filters *utils.WebhookDeliveryFindFilters, options *utils.WebhookDeliveryFindOptions) (string, []interface{}) {
+	sb := sqlbuilder.NewSelectBuilder()
+
+	if options.IsCount() {
+		sb.Select("COUNT(d.id)")
+	} else {
+		sb.Select(
+			"d.id",
+			"d.subscription_id",
+			"d.event_id",
+			"d.event_name",
+			"d.payload",
+			"d.status",
+			"d.attempts",
+			"d.next_attempt_at",
+			"d.created_at",
+			"d.updated_at",
+		)
+	}
+
+	sb.From(sb.As("webhook_deliveries", "d"))
+
+	if filters.GetID() != nil {
+		sb.Where(sb.Equal("d.id", filters.GetIDValue()))
+	}
+
+	if filters.GetSubscriptionID() != nil {
+		sb.Where(sb.Equal("d.subscription_id", filters.GetSubscriptionIDValue()))
+	}
+
+	if filters.GetStatus() != nil {
+		sb.Where(sb.Equal("d.status", filters.GetStatusValue()))
+	}
+
+	if filters.GetDueAt() != nil {
+		sb.Where(sb.LessEqualThan("d.next_attempt_at", filters.GetDueAtValue()))
+	}
+
+	if !options.IsCount() {
+		if options.GetSortBy() != nil && options.GetSortDir() != nil {
+			sb.OrderBy(options.GetSortByValue())
+
+			if options.IsAsc() {
+				sb.Asc()
+			} else {
+				sb.Desc()
+			}
+		} else {
+			sb.OrderBy("d.id").Asc()
+		}
+
+		if options.GetOffset() != nil && options.GetLimit() != nil {
+			sb.Offset(options.GetOffsetValue()).Limit(options.GetLimitValue())
+		}
+	}
+
+	return sb.Build()
+}
+
+// Static functions
+
+func NewWebhookDeliveryRepository(appConfig config.AppConfig, db *sql.DB, logger *zerolog.Logger) WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{
+		appConfig: appConfig,
+		db:        db,
+		logger:    logger,
+	}
+}
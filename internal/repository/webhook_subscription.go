@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/repository/utils"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/rs/zerolog"
+)
+
+// Constants
+
+const (
+	WebhookSubscriptionRepositorySourceName = "WebhookSubscriptionRepository"
+
+	webhookEventTypesSeparator = ","
+)
+
+// Interfaces
+
+// WebhookSubscriptionRepository Subscriptions are created on, and found in, the tenant of the context, so they only
+// receive the events of their tenant.
+type WebhookSubscriptionRepository interface {
+	Count(ctx *context.RequestContext, filters *utils.WebhookSubscriptionFindFilters, options *utils.WebhookSubscriptionFindOptions) (int64, *apperror.AppError)
+	Find(ctx *context.RequestContext, filters *utils.WebhookSubscriptionFindFilters, options *utils.WebhookSubscriptionFindOptions) ([]*model.WebhookSubscription, *apperror.AppError)
+	FindOneByID(ctx *context.RequestContext, ID int64) (*model.WebhookSubscription, *apperror.AppError)
+	FindOneByIDOfAnyTenant(ctx *context.RequestContext, ID int64) (*model.WebhookSubscription, *apperror.AppError)
+	Create(ctx *context.RequestContext, webhookSubscription *model.WebhookSubscription) *apperror.AppError
+	Update(ctx *context.RequestContext, webhookSubscription *model.WebhookSubscription) *apperror.AppError
+	Delete(ctx *context.RequestContext, webhookSubscription *model.WebhookSubscription) *apperror.AppError
+}
+
+// Structs
+
+type webhookSubscriptionRepository struct {
+	appConfig config.AppConfig
+	db        *sql.DB
+	logger    *zerolog.Logger
+}
+
+func (r *webhookSubscriptionRepository) Count(ctx *context.RequestContext, filters *utils.WebhookSubscriptionFindFilters, options *utils.WebhookSubscriptionFindOptions) (int64, *apperror.AppError) {
+	countOptions := *options
+
+	countOptions.WithCount(true)
+
+	query, bindings := r.createSelectQuery(ctx.GetTenantID(), filters, &countOptions)
+
+	row := GetExecutor(ctx, r.db).QueryRow(query, bindings...)
+	count := int64(0)
+
+	err := row.Scan(&count)
+
+	if err != nil {
+		return count, apperror.NewDbAppError(ctx, err, WebhookSubscriptionRepositorySourceName)
+	}
+
+	return count, nil
+}
+
+func (r *webhookSubscriptionRepository) Find(ctx *context.RequestContext, filters *utils.WebhookSubscriptionFindFilters, options *utils.WebhookSubscriptionFindOptions) ([]*model.WebhookSubscription, *apperror.AppError) {
+	return r.find(ctx, ctx.GetTenantID(), filters, options)
+}
+
+func (r *webhookSubscriptionRepository) FindOneByID(ctx *context.RequestContext, ID int64) (*model.WebhookSubscription, *apperror.AppError) {
+	return r.findOneByID(ctx, ctx.GetTenantID(), ID)
+}
+
+// FindOneByIDOfAnyTenant Finds the subscription whatever its tenant is. Only meant for background work which spans
+// every tenant, like sending the pending deliveries.
+func (r *webhookSubscriptionRepository) FindOneByIDOfAnyTenant(ctx *context.RequestContext, ID int64) (*model.WebhookSubscription, *apperror.AppError) {
+	return r.findOneByID(ctx, 0, ID)
+}
+
+// Create The subscription is created on the tenant of the context.
+func (r *webhookSubscriptionRepository) Create(ctx *context.RequestContext, webhookSubscription *model.WebhookSubscription) *apperror.AppError {
+	qb := sqlbuilder.NewInsertBuilder()
+
+	qb.InsertInto("webhook_subscriptions").
+		Cols("tenant_id", "url", "event_types", "secret", "disabled", "created_at", "updated_at").
+		Values(
+			ctx.GetTenantID(),
+			webhookSubscription.URL,
+			strings.Join(webhookSubscription.EventTypes, webhookEventTypesSeparator),
+			webhookSubscription.Secret,
+			webhookSubscription.Disabled,
+			webhookSubscription.CreatedAt,
+			webhookSubscription.UpdatedAt,
+		)
+
+	query, bindings := qb.Build()
+
+	res, err := GetExecutor(ctx, r.db).Exec(query, bindings...)
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, WebhookSubscriptionRepositorySourceName)
+	}
+
+	lastInsertId, err := res.LastInsertId()
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, WebhookSubscriptionRepositorySourceName)
+	}
+
+	webhookSubscription.ID = lastInsertId
+	webhookSubscription.TenantID = ctx.GetTenantID()
+
+	return nil
+}
+
+// Update Only subscriptions of the tenant of the context can be updated.
+func (r *webhookSubscriptionRepository) Update(ctx *context.RequestContext, webhookSubscription *model.WebhookSubscription) *apperror.AppError {
+	qb := sqlbuilder.NewUpdateBuilder()
+
+	qb.Update("webhook_subscriptions").
+		Set(
+			qb.Assign("url", webhookSubscription.URL),
+			qb.Assign("event_types", strings.Join(webhookSubscription.EventTypes, webhookEventTypesSeparator)),
+			qb.Assign("secret", webhookSubscription.Secret),
+			qb.Assign("disabled", webhookSubscription.Disabled),
+			qb.Assign("updated_at", webhookSubscription.UpdatedAt),
+		).
+		Where(qb.Equal("id", webhookSubscription.ID), qb.Equal("tenant_id", ctx.GetTenantID()))
+
+	query, bindings := qb.Build()
+
+	return execScoped(ctx, r.db, WebhookSubscriptionRepositorySourceName, query, bindings...)
+}
+
+// Delete Only subscriptions of the tenant of the context (and their deliveries) can be deleted.
+func (r *webhookSubscriptionRepository) Delete(ctx *context.RequestContext, webhookSubscription *model.WebhookSubscription) *apperror.AppError {
+	executor := GetExecutor(ctx, r.db)
+
+	for _, query := range []string{
+		`DELETE FROM webhook_delivery_attempts WHERE delivery_id IN (
+			SELECT d.id FROM webhook_deliveries d WHERE d.subscription_id = ? AND ` + subscriptionOfTenantCondition + `
+		)`,
+		`DELETE FROM webhook_deliveries WHERE subscription_id = ? AND ` + subscriptionOfTenantCondition,
+	} {
+		if _, err := executor.Exec(query, webhookSubscription.ID, ctx.GetTenantID()); err != nil {
+			return apperror.NewDbAppError(ctx, err, WebhookSubscriptionRepositorySourceName)
+		}
+	}
+
+	query := `DELETE FROM webhook_subscriptions
+	WHERE id = ? AND tenant_id = ?`
+
+	return execScoped(ctx, r.db, WebhookSubscriptionRepositorySourceName, query, webhookSubscription.ID, ctx.GetTenantID())
+}
+
+// find Only subscriptions of the given tenant are found, or the ones of every tenant if it's 0.
+func (r *webhookSubscriptionRepository) find(
+	ctx *context.RequestContext,
+	tenantID int64,
+	filters *utils.WebhookSubscriptionFindFilters,
+	options *utils.WebhookSubscriptionFindOptions,
+) ([]*model.WebhookSubscription, *apperror.AppError) {
+	query, bindings := r.createSelectQuery(tenantID, filters, options)
+
+	rows, err := GetExecutor(ctx, r.db).Query(query, bindings...)
+
+	if err != nil {
+		return nil, apperror.NewDbAppError(ctx, err, WebhookSubscriptionRepositorySourceName)
+	}
+
+	defer rows.Close()
+
+	res := make([]*model.WebhookSubscription, 0)
+
+	for rows.Next() {
+		builder := model.NewWebhookSubscriptionBuilder()
+
+		ID := sql.NullInt64{}
+		tenantID := sql.NullInt64{}
+		url := sql.NullString{}
+		eventTypes := sql.NullString{}
+		secret := sql.NullString{}
+		disabled := sql.NullBool{}
+		createdAt := sql.NullTime{}
+		updatedAt := sql.NullTime{}
+
+		err = rows.Scan(&ID, &tenantID, &url, &eventTypes, &secret, &disabled, &createdAt, &updatedAt)
+
+		if err != nil {
+			return nil, apperror.NewDbAppError(ctx, err, WebhookSubscriptionRepositorySourceName)
+		}
+
+		if ID.Valid {
+			builder.WithID(ID.Int64)
+		}
+
+		if tenantID.Valid {
+			builder.WithTenantID(tenantID.Int64)
+		}
+
+		if url.Valid {
+			builder.WithURL(url.String)
+		}
+
+		if eventTypes.Valid && eventTypes.String != "" {
+			builder.WithEventTypes(strings.Split(eventTypes.String, webhookEventTypesSeparator))
+		}
+
+		if secret.Valid {
+			builder.WithSecret(secret.String)
+		}
+
+		if disabled.Valid {
+			builder.WithDisabled(disabled.Bool)
+		}
+
+		if createdAt.Valid {
+			builder.WithCreatedAt(createdAt.Time)
+		}
+
+		if updatedAt.Valid {
+			builder.WithUpdatedAt(updatedAt.Time)
+		}
+
+		res = append(res, builder.Build())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDbAppError(ctx, err, WebhookSubscriptionRepositorySourceName)
+	}
+
+	return res, nil
+}
+
+func (r *webhookSubscriptionRepository) findOneByID(ctx *context.RequestContext, tenantID int64, ID int64) (*model.WebhookSubscription, *apperror.AppError) {
+	res, err := r.find(
+		ctx,
+		tenantID,
+		utils.NewWebhookSubscriptionFindFilters().WithIDValue(ID),
+		utils.NewWebhookSubscriptionFindOptions().WithOffsetValue(0).WithLimitValue(1),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) > 0 {
+		return res[0], nil
+	}
+
+	return nil, nil
+}
+
+func (r *webhookSubscriptionRepository) createSelectQuery(
+	tenantID int64,
+	filters *utils.WebhookSubscriptionFindFilters,
+	options *utils.WebhookSubscriptionFindOptions,
+) (string, []interface{}) {
+	sb := sqlbuilder.NewSelectBuilder()
+
+	if options.IsCount() {
+		sb.Select("COUNT(w.id)")
+	} else {
+		sb.Select(
+			"w.id",
+			"w.tenant_id",
+			"w.url",
+			"w.event_types",
+			"w.secret",
+			"w.disabled",
+			"w.created_at",
+			"w.updated_at",
+		)
+	}
+
+	sb.From(sb.As("webhook_subscriptions", "w"))
+
+	if tenantID != 0 {
+		sb.Where(sb.Equal("w.tenant_id", tenantID))
+	}
+
+	if filters.GetID() != nil {
+		sb.Where(sb.Equal("w.id", filters.GetIDValue()))
+	}
+
+	if filters.GetDisabled() != nil {
+		sb.Where(sb.Equal("w.disabled", filters.GetDisabledValue()))
+	}
+
+	if !options.IsCount() {
+		if options.GetSortBy() != nil && options.GetSortDir() != nil {
+			sb.OrderBy(options.GetSortByValue())
+
+			if options.IsAsc() {
+				sb.Asc()
+			} else {
+				sb.Desc()
+			}
+		} else {
+			sb.OrderBy("w.id").Asc()
+		}
+
+		if options.GetOffset() != nil && options.GetLimit() != nil {
+			sb.Offset(options.GetOffsetValue()).Limit(options.GetLimitValue())
+		}
+	}
+
+	return sb.Build()
+}
+
+// Static functions
+
+func NewWebhookSubscriptionRepository(appConfig config.AppConfig, db *sql.DB, logger *zerolog.Logger) WebhookSubscriptionRepository {
+	return &webhookSubscriptionRepository{
+		appConfig: appConfig,
+		db:        db,
+		logger:    logger,
+	}
+}
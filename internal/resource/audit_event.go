@@ -0,0 +1,80 @@
+package resource
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+)
+
+// Structs
+
+// AuditEventFindResource Only the sortable fields are accepted on sort_by, as it's used on the ORDER BY clause.
+type AuditEventFindResource struct {
+	SortBy  *string `form:"sort_by" validate:"omitempty,oneof=id created_at"`
+	SortDir *string `form:"sort_dir"`
+	Offset  *int    `form:"offset"`
+	Limit   *int    `form:"limit"`
+
+	EntityType *string    `form:"entity_type" validate:"omitempty,min=1,max=50"`
+	EntityKey  *string    `form:"entity_key" validate:"omitempty,min=1,max=100"`
+	Actor      *string    `form:"actor" validate:"omitempty,min=1,max=100"`
+	From       *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// AuditEventResourceList
+
+type AuditEventResourceList struct {
+	TotalCount int64                 `json:"total_count"`
+	PageCount  int64                 `json:"page_count"`
+	Data       []*AuditEventResource `json:"data"`
+}
+
+// AuditEventResource
+
+type AuditEventResource struct {
+	Actor      string                  `json:"actor"`
+	RequestID  string                  `json:"request_id"`
+	EntityType string                  `json:"entity_type"`
+	EntityKey  string                  `json:"entity_key"`
+	Action     string                  `json:"action"`
+	Changes    map[string]*AuditChange `json:"changes"`
+	CreatedAt  time.Time               `json:"created_at"`
+}
+
+// AuditChange
+
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Static functions
+
+func NewAuditEventResourceList(list []*AuditEventResource, totalCount int64) *AuditEventResourceList {
+	return &AuditEventResourceList{
+		TotalCount: totalCount,
+		PageCount:  int64(len(list)),
+		Data:       list,
+	}
+}
+
+func FromAuditEvent(auditEvent model.AuditEvent) *AuditEventResource {
+	changes := make(map[string]*AuditChange)
+
+	// Changes are always written by the AuditService, so a decoding error would only mean a manually edited row.
+	// In that case we just return no changes.
+
+	_ = json.Unmarshal([]byte(auditEvent.Changes), &changes)
+
+	return &AuditEventResource{
+		Actor:      auditEvent.Actor,
+		RequestID:  auditEvent.RequestID,
+		EntityType: auditEvent.EntityType,
+		EntityKey:  auditEvent.EntityKey,
+		Action:     auditEvent.Action,
+		Changes:    changes,
+		CreatedAt:  auditEvent.CreatedAt,
+	}
+}
@@ -0,0 +1,56 @@
+package resource
+
+// Structs
+
+// LoginResource Users with a second factor must send a TOTP Code or, if they lost it, one of their recovery codes.
+
+type LoginResource struct {
+	Username     string `json:"username" binding:"required" validate:"required,max=50"`
+	Password     string `json:"password" binding:"required" validate:"required,max=128"`
+	Code         string `json:"code" validate:"omitempty,numeric,len=6"`
+	RecoveryCode string `json:"recovery_code" validate:"omitempty,max=32"`
+}
+
+// PasswordResetRequestResource The user is looked up by its username or, if it's not set, by its email.
+
+type PasswordResetRequestResource struct {
+	Username string `json:"username" validate:"required_without=Email,max=50"`
+	Email    string `json:"email" validate:"omitempty,email,max=254"`
+}
+
+// PasswordResetConfirmResource
+
+type PasswordResetConfirmResource struct {
+	Token    string `json:"token" binding:"required" validate:"required"`
+	Password string `json:"password" binding:"required" validate:"required,min=8,max=128"`
+}
+
+// MfaEnrollResource
+
+type MfaEnrollResource struct {
+	Username string `json:"username" binding:"required" validate:"required,max=50"`
+	Password string `json:"password" binding:"required" validate:"required,max=128"`
+}
+
+// MfaConfirmResource Code is the first TOTP code generated by the authenticator app with the enrolled secret.
+
+type MfaConfirmResource struct {
+	Username string `json:"username" binding:"required" validate:"required,max=50"`
+	Password string `json:"password" binding:"required" validate:"required,max=128"`
+	Code     string `json:"code" binding:"required" validate:"required,numeric,len=6"`
+}
+
+// MfaEnrollmentResource The secret is shown once: authenticator apps add it from the otpauth:// URI, usually shown as
+// a QR code, or from the secret itself.
+
+type MfaEnrollmentResource struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// MfaRecoveryCodesResource Recovery codes are shown once, as only their hashes are stored. Each of them can be used
+// once.
+
+type MfaRecoveryCodesResource struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
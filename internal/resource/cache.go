@@ -0,0 +1,33 @@
+package resource
+
+import "github.com/comfortablynumb/goginrestapi/internal/cache"
+
+// Structs
+
+// CacheStatsResourceList
+
+type CacheStatsResourceList struct {
+	Data []*CacheStatsResource `json:"data"`
+}
+
+// CacheStatsResource Entries is -1 when the cache can't count them cheaply (like Redis).
+
+type CacheStatsResource struct {
+	Name     string  `json:"name"`
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	HitRatio float64 `json:"hit_ratio"`
+	Entries  int64   `json:"entries"`
+}
+
+// Static functions
+
+func FromCacheStats(stats cache.Stats) *CacheStatsResource {
+	return &CacheStatsResource{
+		Name:     stats.Name,
+		Hits:     stats.Hits,
+		Misses:   stats.Misses,
+		HitRatio: stats.HitRatio(),
+		Entries:  stats.Entries,
+	}
+}
@@ -0,0 +1,20 @@
+package resource
+
+// Structs
+
+// ErrorCodeResourceList
+
+type ErrorCodeResourceList struct {
+	Data []*ErrorCodeResource `json:"data"`
+}
+
+// ErrorCodeResource Type is the one of the problem details of the errors with the code. Title is translated to the
+// locale of the request.
+
+type ErrorCodeResource struct {
+	Code        string `json:"code" example:"000005"`
+	Type        string `json:"type" example:"/errors/000005"`
+	Title       string `json:"title" example:"The element you referenced was not found"`
+	Status      int    `json:"status" example:"404"`
+	Description string `json:"description" example:"The element referenced by the path of the request does not exist."`
+}
@@ -0,0 +1,31 @@
+package resource
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Structs
+
+// StreamSubscribeResource The last event ID is read from the Last-Event-ID header, which browsers send automatically
+// when an EventSource reconnects. It can also be sent as a query param for the first connection.
+
+type StreamSubscribeResource struct {
+	EntityTypes []string `form:"entity_types" validate:"omitempty,dive,oneof=user user_type"`
+	LastEventID int64    `form:"last_event_id" validate:"min=0"`
+}
+
+// ChangeNotificationResource Notifications are only sent to the subscriptions of their tenant.
+
+type ChangeNotificationResource struct {
+	ID         int64           `json:"id"`
+	TenantID   int64           `json:"-"`
+	Event      string          `json:"event"`
+	EntityType string          `json:"entity_type"`
+	EntityKey  string          `json:"entity_key"`
+	Action     string          `json:"action"`
+	Data       json.RawMessage `json:"data"`
+	Actor      string          `json:"actor"`
+	RequestID  string          `json:"request_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
@@ -0,0 +1,46 @@
+package resource
+
+import (
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+)
+
+// Structs
+
+// TenantCreateResource Names are used as subdomains too, so they must be lowercase DNS labels.
+type TenantCreateResource struct {
+	Name     string `json:"name" validate:"required,max=63,hostname_rfc1123,excludesall=ABCDEFGHIJKLMNOPQRSTUVWXYZ._"`
+	Disabled bool   `json:"disabled"`
+}
+
+// TenantUpdateResource
+
+type TenantUpdateResource struct {
+	Name     string `json:"name" validate:"required,max=63"`
+	Disabled bool   `json:"disabled"`
+}
+
+// TenantResource
+
+type TenantResource struct {
+	Name      string    `json:"name"`
+	Disabled  bool      `json:"disabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Static functions
+
+func NewTenantResource(name string, disabled bool, createdAt time.Time, updatedAt time.Time) *TenantResource {
+	return &TenantResource{
+		Name:      name,
+		Disabled:  disabled,
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+	}
+}
+
+func FromTenant(tenant model.Tenant) *TenantResource {
+	return NewTenantResource(tenant.Name, tenant.Disabled, tenant.CreatedAt, tenant.UpdatedAt)
+}
@@ -6,6 +6,13 @@ import (
 	"github.com/comfortablynumb/goginrestapi/internal/model"
 )
 
+// Interfaces
+
+type UserUniqueValidator interface {
+	GetUsername() string
+	GetEmail() string
+}
+
 // Structs
 
 // UserFindResource
@@ -16,20 +23,47 @@ type UserFindResource struct {
 	Username *string `form:"username" validate:"omitempty,min=1,max=50"`
 }
 
-// UserCreateResource
+// UserCreateResource Locale and timezone default to the default locale of the app and UTC. Users without password
+// can't log in.
 
 type UserCreateResource struct {
 	Username     string `json:"username" binding:"required" validate:"required,min=1,max=50"`
 	UserTypeName string `json:"user_type_name" validate:"required,user_type"`
 	Disabled     bool   `json:"disabled"`
+	Email        string `json:"email" validate:"omitempty,email,max=254" example:"jane@example.com"`
+	DisplayName  string `json:"display_name" validate:"omitempty,max=100" example:"Jane Doe"`
+	Locale       string `json:"locale" validate:"omitempty,locale" example:"en"`
+	Timezone     string `json:"timezone" validate:"omitempty,timezone" example:"Europe/Madrid"`
+	Password     string `json:"password" validate:"omitempty,min=8,max=128"`
 }
 
-// UserUpdateResource
+func (u UserCreateResource) GetUsername() string {
+	return u.Username
+}
+
+func (u UserCreateResource) GetEmail() string {
+	return u.Email
+}
+
+// UserUpdateResource The password is only changed if it's set.
 
 type UserUpdateResource struct {
 	Username     string `uri:"username" json:"-" binding:"required" validate:"required,min=1,max=50"`
 	UserTypeName string `json:"user_type_name" validate:"required,user_type"`
 	Disabled     bool   `json:"disabled"`
+	Email        string `json:"email" validate:"omitempty,email,max=254" example:"jane@example.com"`
+	DisplayName  string `json:"display_name" validate:"omitempty,max=100" example:"Jane Doe"`
+	Locale       string `json:"locale" validate:"omitempty,locale" example:"en"`
+	Timezone     string `json:"timezone" validate:"omitempty,timezone" example:"Europe/Madrid"`
+	Password     string `json:"password" validate:"omitempty,min=8,max=128"`
+}
+
+func (u UserUpdateResource) GetUsername() string {
+	return u.Username
+}
+
+func (u UserUpdateResource) GetEmail() string {
+	return u.Email
 }
 
 // UserDeleteResource
@@ -38,24 +72,72 @@ type UserDeleteResource struct {
 	Username string `uri:"username" json:"-" binding:"required" validate:"required,min=1,max=50"`
 }
 
-// UserResource
+// UserVerifyEmailResource
+
+type UserVerifyEmailResource struct {
+	Username string `uri:"username" json:"-" binding:"required" validate:"required,min=1,max=50"`
+	Token    string `json:"token" validate:"required"`
+}
+
+// UserDisableMfaResource
+
+type UserDisableMfaResource struct {
+	Username string `uri:"username" json:"-" binding:"required" validate:"required,min=1,max=50"`
+}
+
+// UserUnlockResource
+
+type UserUnlockResource struct {
+	Username string `uri:"username" json:"-" binding:"required" validate:"required,min=1,max=50"`
+}
+
+// UserVerificationEmailResource
+
+type UserVerificationEmailResource struct {
+	Username string `uri:"username" json:"-" binding:"required" validate:"required,min=1,max=50"`
+}
+
+// UserResource Locked is true while the user is locked out, until LockedUntil.
 
 type UserResource struct {
-	Username  string           `json:"username"`
-	UserType  UserTypeResource `json:"user_type"`
-	Disabled  bool             `json:"disabled"`
-	CreatedAt time.Time        `json:"created_at"`
-	UpdatedAt time.Time        `json:"updated_at"`
+	Username            string           `json:"username"`
+	UserType            UserTypeResource `json:"user_type"`
+	Disabled            bool             `json:"disabled"`
+	Email               string           `json:"email"`
+	DisplayName         string           `json:"display_name"`
+	Locale              string           `json:"locale"`
+	Timezone            string           `json:"timezone"`
+	EmailVerified       bool             `json:"email_verified"`
+	EmailVerifiedAt     *time.Time       `json:"email_verified_at"`
+	HasPassword         bool             `json:"has_password"`
+	PasswordChangedAt   *time.Time       `json:"password_changed_at"`
+	FailedLoginAttempts int              `json:"failed_login_attempts"`
+	Locked              bool             `json:"locked"`
+	LockedUntil         *time.Time       `json:"locked_until"`
+	MfaEnabled          bool             `json:"mfa_enabled"`
+	MfaEnabledAt        *time.Time       `json:"mfa_enabled_at"`
+	CreatedAt           time.Time        `json:"created_at"`
+	UpdatedAt           time.Time        `json:"updated_at"`
 }
 
 // UserResourceBuilder
 
 type UserResourceBuilder struct {
-	username  string
-	userType  UserTypeResource
-	disabled  bool
-	createdAt time.Time
-	updatedAt time.Time
+	username            string
+	userType            UserTypeResource
+	disabled            bool
+	email               string
+	displayName         string
+	locale              string
+	timezone            string
+	emailVerifiedAt     *time.Time
+	hasPassword         bool
+	passwordChangedAt   *time.Time
+	failedLoginAttempts int
+	lockedUntil         *time.Time
+	mfaEnabledAt        *time.Time
+	createdAt           time.Time
+	updatedAt           time.Time
 }
 
 func (b *UserResourceBuilder) WithUsername(username string) *UserResourceBuilder {
@@ -76,6 +158,66 @@ func (b *UserResourceBuilder) WithDisabled(disabled bool) *UserResourceBuilder {
 	return b
 }
 
+func (b *UserResourceBuilder) WithEmail(email string) *UserResourceBuilder {
+	b.email = email
+
+	return b
+}
+
+func (b *UserResourceBuilder) WithDisplayName(displayName string) *UserResourceBuilder {
+	b.displayName = displayName
+
+	return b
+}
+
+func (b *UserResourceBuilder) WithLocale(locale string) *UserResourceBuilder {
+	b.locale = locale
+
+	return b
+}
+
+func (b *UserResourceBuilder) WithTimezone(timezone string) *UserResourceBuilder {
+	b.timezone = timezone
+
+	return b
+}
+
+func (b *UserResourceBuilder) WithEmailVerifiedAt(emailVerifiedAt *time.Time) *UserResourceBuilder {
+	b.emailVerifiedAt = emailVerifiedAt
+
+	return b
+}
+
+func (b *UserResourceBuilder) WithHasPassword(hasPassword bool) *UserResourceBuilder {
+	b.hasPassword = hasPassword
+
+	return b
+}
+
+func (b *UserResourceBuilder) WithPasswordChangedAt(passwordChangedAt *time.Time) *UserResourceBuilder {
+	b.passwordChangedAt = passwordChangedAt
+
+	return b
+}
+
+func (b *UserResourceBuilder) WithFailedLoginAttempts(failedLoginAttempts int) *UserResourceBuilder {
+	b.failedLoginAttempts = failedLoginAttempts
+
+	return b
+}
+
+func (b *UserResourceBuilder) WithLockedUntil(lockedUntil *time.Time) *UserResourceBuilder {
+	b.lockedUntil = lockedUntil
+
+	return b
+}
+
+func (b *UserResourceBuilder) WithMfaEnabledAt(mfaEnabledAt *time.Time) *UserResourceBuilder {
+	b.mfaEnabledAt = mfaEnabledAt
+
+	return b
+}
+
 func (b *UserResourceBuilder) WithCreatedAt(createdAt time.Time) *UserResourceBuilder {
 	b.createdAt = createdAt
 
@@ -89,7 +231,23 @@ func (b *UserResourceBuilder) WithUpdatedAt(updatedAt time.Time) *UserResourceBu
 }
 
 func (b *UserResourceBuilder) Build() *UserResource {
-	return NewUserResource(b.username, b.userType, b.disabled, b.createdAt, b.updatedAt)
+	return NewUserResource(
+		b.username,
+		b.userType,
+		b.disabled,
+		b.email,
+		b.displayName,
+		b.locale,
+		b.timezone,
+		b.emailVerifiedAt,
+		b.hasPassword,
+		b.passwordChangedAt,
+		b.failedLoginAttempts,
+		b.lockedUntil,
+		b.mfaEnabledAt,
+		b.createdAt,
+		b.updatedAt,
+	)
 }
 
 // Static functions
@@ -102,23 +260,58 @@ func NewUserResource(
 	username string,
 	userType UserTypeResource,
 	disabled bool,
+	email string,
+	displayName string,
+	locale string,
+	timezone string,
+	emailVerifiedAt *time.Time,
+	hasPassword bool,
+	passwordChangedAt *time.Time,
+	failedLoginAttempts int,
+	lockedUntil *time.Time,
+	mfaEnabledAt *time.Time,
 	createdAt time.Time,
 	updatedAt time.Time,
 ) *UserResource {
 	return &UserResource{
-		Username:  username,
-		UserType:  userType,
-		Disabled:  disabled,
-		CreatedAt: createdAt,
-		UpdatedAt: updatedAt,
+		Username:            username,
+		UserType:            userType,
+		Disabled:            disabled,
+		Email:               email,
+		DisplayName:         displayName,
+		Locale:              locale,
+		Timezone:            timezone,
+		EmailVerified:       emailVerifiedAt != nil,
+		EmailVerifiedAt:     emailVerifiedAt,
+		HasPassword:         hasPassword,
+		PasswordChangedAt:   passwordChangedAt,
+		FailedLoginAttempts: failedLoginAttempts,
+		Locked:              lockedUntil != nil,
+		LockedUntil:         lockedUntil,
+		MfaEnabled:          mfaEnabledAt != nil,
+		MfaEnabledAt:        mfaEnabledAt,
+		CreatedAt:           createdAt,
+		UpdatedAt:           updatedAt,
 	}
 }
 
+// FromUser Expired lockouts must be cleared first (see model.User.ClearExpiredLockout), or the user is shown as
+// locked.
 func FromUser(user model.User) *UserResource {
 	return NewUserResourceBuilder().
 		WithUsername(user.Username).
 		WithUserType(user.UserType).
 		WithDisabled(user.Disabled).
+		WithEmail(user.Email).
+		WithDisplayName(user.DisplayName).
+		WithLocale(user.Locale).
+		WithTimezone(user.Timezone).
+		WithEmailVerifiedAt(user.EmailVerifiedAt).
+		WithHasPassword(user.HasPassword()).
+		WithPasswordChangedAt(user.PasswordChangedAt).
+		WithFailedLoginAttempts(user.FailedLoginAttempts).
+		WithLockedUntil(user.LockedUntil).
+		WithMfaEnabledAt(user.TotpEnabledAt).
 		WithCreatedAt(user.CreatedAt).
 		WithUpdatedAt(user.UpdatedAt).
 		Build()
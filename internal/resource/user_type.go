@@ -15,10 +15,13 @@ type UserTypeUniqueValidator interface {
 
 // Structs
 
-// UserTypeFindResource
+// UserTypeFindResource Only the sortable fields are accepted on sort_by, as it's used on the ORDER BY clause.
 
 type UserTypeFindResource struct {
-	CommonFindResource
+	SortBy  *string `form:"sort_by" validate:"omitempty,oneof=id name created_at updated_at"`
+	SortDir *string `form:"sort_dir"`
+	Offset  *int    `form:"offset"`
+	Limit   *int    `form:"limit"`
 
 	Name *string `form:"name" validate:"omitempty,min=1,max=50"`
 }
@@ -26,9 +29,10 @@ type UserTypeFindResource struct {
 // UserTypeCreateResource
 
 type UserTypeCreateResource struct {
-	ID       int64  `json:"-"`
-	Name     string `json:"name" binding:"required" validate:"required,min=1,max=50"`
-	Disabled bool   `json:"disabled"`
+	ID          int64  `json:"-"`
+	Name        string `json:"name" binding:"required" validate:"required,min=1,max=50"`
+	Disabled    bool   `json:"disabled"`
+	RequiresMfa bool   `json:"requires_mfa"`
 }
 
 func (u UserTypeCreateResource) GetID() int64 {
@@ -46,6 +50,7 @@ type UserTypeUpdateResource struct {
 	OriginalName string `uri:"name" json:"-" binding:"required" validate:"required,min=1,max=50"`
 	Name         string `json:"name" validate:"required,min=1,max=50"`
 	Disabled     bool   `json:"disabled"`
+	RequiresMfa  bool   `json:"requires_mfa"`
 }
 
 func (u UserTypeUpdateResource) GetID() int64 {
@@ -73,19 +78,21 @@ type UserTypeResourceList struct {
 // UserTypeResource
 
 type UserTypeResource struct {
-	Name      string    `json:"name"`
-	Disabled  bool      `json:"disabled"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Name        string    `json:"name"`
+	Disabled    bool      `json:"disabled"`
+	RequiresMfa bool      `json:"requires_mfa"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 // UserTypeResourceBuilder
 
 type UserTypeResourceBuilder struct {
-	name      string
-	disabled  bool
-	createdAt time.Time
-	updatedAt time.Time
+	name        string
+	disabled    bool
+	requiresMfa bool
+	createdAt   time.Time
+	updatedAt   time.Time
 }
 
 func (b *UserTypeResourceBuilder) WithName(name string) *UserTypeResourceBuilder {
@@ -100,6 +107,12 @@ func (b *UserTypeResourceBuilder) WithDisabled(disabled bool) *UserTypeResourceB
 	return b
 }
 
+func (b *UserTypeResourceBuilder) WithRequiresMfa(requiresMfa bool) *UserTypeResourceBuilder {
+	b.requiresMfa = requiresMfa
+
+	return b
+}
+
 func (b *UserTypeResourceBuilder) WithCreatedAt(createdAt time.Time) *UserTypeResourceBuilder {
 	b.createdAt = createdAt
 
@@ -113,7 +126,7 @@ func (b *UserTypeResourceBuilder) WithUpdatedAt(updatedAt time.Time) *UserTypeRe
 }
 
 func (b *UserTypeResourceBuilder) Build() *UserTypeResource {
-	return NewUserTypeResource(b.name, b.disabled, b.createdAt, b.updatedAt)
+	return NewUserTypeResource(b.name, b.disabled, b.requiresMfa, b.createdAt, b.updatedAt)
 }
 
 // Static functions
@@ -130,12 +143,13 @@ func NewUserTypeResourceList(list []*UserTypeResource, totalCount int64) *UserTy
 	}
 }
 
-func NewUserTypeResource(name string, disabled bool, createdAt time.Time, updatedAt time.Time) *UserTypeResource {
+func NewUserTypeResource(name string, disabled bool, requiresMfa bool, createdAt time.Time, updatedAt time.Time) *UserTypeResource {
 	return &UserTypeResource{
-		Name:      name,
-		Disabled:  disabled,
-		CreatedAt: createdAt,
-		UpdatedAt: updatedAt,
+		Name:        name,
+		Disabled:    disabled,
+		RequiresMfa: requiresMfa,
+		CreatedAt:   createdAt,
+		UpdatedAt:   updatedAt,
 	}
 }
 
@@ -143,6 +157,7 @@ func FromUserType(userType model.UserType) *UserTypeResource {
 	return NewUserTypeResourceBuilder().
 		WithName(userType.Name).
 		WithDisabled(userType.Disabled).
+		WithRequiresMfa(userType.RequiresMfa).
 		WithCreatedAt(userType.CreatedAt).
 		WithUpdatedAt(userType.UpdatedAt).
 		Build()
@@ -0,0 +1,170 @@
+package resource
+
+import (
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+)
+
+// Structs
+
+// WebhookFindResource
+
+type WebhookFindResource struct {
+	SortBy  *string `form:"sort_by" validate:"omitempty,oneof=id url created_at"`
+	SortDir *string `form:"sort_dir"`
+	Offset  *int    `form:"offset"`
+	Limit   *int    `form:"limit"`
+
+	Disabled *bool `form:"disabled"`
+}
+
+// WebhookCreateResource
+
+type WebhookCreateResource struct {
+	URL        string   `json:"url" binding:"required" validate:"required,url,max=500"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,event_type"`
+	Secret     string   `json:"secret" validate:"required,min=16,max=100"`
+	Disabled   bool     `json:"disabled"`
+}
+
+// WebhookUpdateResource
+
+type WebhookUpdateResource struct {
+	ID         int64    `uri:"id" json:"-" binding:"required" validate:"required,min=1"`
+	URL        string   `json:"url" validate:"required,url,max=500"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,event_type"`
+	Secret     string   `json:"secret" validate:"omitempty,min=16,max=100"`
+	Disabled   bool     `json:"disabled"`
+}
+
+// WebhookDeleteResource
+
+type WebhookDeleteResource struct {
+	ID int64 `uri:"id" json:"-" binding:"required" validate:"required,min=1"`
+}
+
+// WebhookResourceList
+
+type WebhookResourceList struct {
+	TotalCount int64              `json:"total_count"`
+	PageCount  int64              `json:"page_count"`
+	Data       []*WebhookResource `json:"data"`
+}
+
+// WebhookResource Secrets are write-only, so they are never returned.
+
+type WebhookResource struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Disabled   bool      `json:"disabled"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// WebhookDeliveryFindResource
+
+type WebhookDeliveryFindResource struct {
+	SortBy  *string `form:"sort_by" validate:"omitempty,oneof=id created_at"`
+	SortDir *string `form:"sort_dir"`
+	Offset  *int    `form:"offset"`
+	Limit   *int    `form:"limit"`
+
+	ID     int64   `uri:"id" form:"-" binding:"required" validate:"required,min=1"`
+	Status *string `form:"status" validate:"omitempty,oneof=pending succeeded failed"`
+}
+
+// WebhookRedeliverResource
+
+type WebhookRedeliverResource struct {
+	ID         int64 `uri:"id" binding:"required" validate:"required,min=1"`
+	DeliveryID int64 `uri:"delivery_id" binding:"required" validate:"required,min=1"`
+}
+
+// WebhookDeliveryResourceList
+
+type WebhookDeliveryResourceList struct {
+	TotalCount int64                      `json:"total_count"`
+	PageCount  int64                      `json:"page_count"`
+	Data       []*WebhookDeliveryResource `json:"data"`
+}
+
+// WebhookDeliveryResource
+
+type WebhookDeliveryResource struct {
+	ID            int64                             `json:"id"`
+	EventID       int64                             `json:"event_id"`
+	EventName     string                            `json:"event_name"`
+	Status        string                            `json:"status"`
+	Attempts      int                               `json:"attempts"`
+	NextAttemptAt time.Time                         `json:"next_attempt_at"`
+	CreatedAt     time.Time                         `json:"created_at"`
+	UpdatedAt     time.Time                         `json:"updated_at"`
+	AttemptLog    []*WebhookDeliveryAttemptResource `json:"attempt_log"`
+}
+
+// WebhookDeliveryAttemptResource
+
+type WebhookDeliveryAttemptResource struct {
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Static functions
+
+func NewWebhookResourceList(list []*WebhookResource, totalCount int64) *WebhookResourceList {
+	return &WebhookResourceList{
+		TotalCount: totalCount,
+		PageCount:  int64(len(list)),
+		Data:       list,
+	}
+}
+
+func NewWebhookDeliveryResourceList(list []*WebhookDeliveryResource, totalCount int64) *WebhookDeliveryResourceList {
+	return &WebhookDeliveryResourceList{
+		TotalCount: totalCount,
+		PageCount:  int64(len(list)),
+		Data:       list,
+	}
+}
+
+func FromWebhookSubscription(webhookSubscription model.WebhookSubscription) *WebhookResource {
+	return &WebhookResource{
+		ID:         webhookSubscription.ID,
+		URL:        webhookSubscription.URL,
+		EventTypes: webhookSubscription.EventTypes,
+		Disabled:   webhookSubscription.Disabled,
+		CreatedAt:  webhookSubscription.CreatedAt,
+		UpdatedAt:  webhookSubscription.UpdatedAt,
+	}
+}
+
+func FromWebhookDelivery(webhookDelivery model.WebhookDelivery) *WebhookDeliveryResource {
+	attemptLog := make([]*WebhookDeliveryAttemptResource, 0, len(webhookDelivery.AttemptLog))
+
+	for _, attempt := range webhookDelivery.AttemptLog {
+		attemptLog = append(attemptLog, &WebhookDeliveryAttemptResource{
+			Attempt:    attempt.Attempt,
+			StatusCode: attempt.StatusCode,
+			Error:      attempt.Error,
+			DurationMs: attempt.Duration.Milliseconds(),
+			CreatedAt:  attempt.CreatedAt,
+		})
+	}
+
+	return &WebhookDeliveryResource{
+		ID:            webhookDelivery.ID,
+		EventID:       webhookDelivery.EventID,
+		EventName:     webhookDelivery.EventName,
+		Status:        webhookDelivery.Status,
+		Attempts:      webhookDelivery.Attempts,
+		NextAttemptAt: webhookDelivery.NextAttemptAt,
+		CreatedAt:     webhookDelivery.CreatedAt,
+		UpdatedAt:     webhookDelivery.UpdatedAt,
+		AttemptLog:    attemptLog,
+	}
+}
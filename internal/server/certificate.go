@@ -0,0 +1,97 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Structs
+
+// CertificateLoader Serves the certificate of its files, loading it again when they change. Files are checked at most
+// once every check interval, during the TLS handshakes. If the new files can't be loaded (for example, because only
+// one of them was replaced yet), the previous certificate keeps being served.
+type CertificateLoader struct {
+	certFile      string
+	keyFile       string
+	checkInterval time.Duration
+	mutex         sync.Mutex
+	certificate   *tls.Certificate
+	modTime       time.Time
+	checkedAt     time.Time
+}
+
+func (l *CertificateLoader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+
+	if now.Sub(l.checkedAt) < l.checkInterval {
+		return l.certificate, nil
+	}
+
+	l.checkedAt = now
+
+	modTime, err := l.getModTime()
+
+	if err != nil || !modTime.After(l.modTime) {
+		return l.certificate, nil
+	}
+
+	if certificate, err := tls.LoadX509KeyPair(l.certFile, l.keyFile); err == nil {
+		l.certificate = &certificate
+		l.modTime = modTime
+	}
+
+	return l.certificate, nil
+}
+
+// getModTime Returns the last time any of the files changed.
+func (l *CertificateLoader) getModTime() (time.Time, error) {
+	res := time.Time{}
+
+	for _, file := range []string{l.certFile, l.keyFile} {
+		info, err := os.Stat(file)
+
+		if err != nil {
+			return res, err
+		}
+
+		if info.ModTime().After(res) {
+			res = info.ModTime()
+		}
+	}
+
+	return res, nil
+}
+
+// Static functions
+
+func NewCertificateLoader(certFile string, keyFile string, checkInterval time.Duration) (*CertificateLoader, error) {
+	res := &CertificateLoader{
+		certFile:      certFile,
+		keyFile:       keyFile,
+		checkInterval: checkInterval,
+		checkedAt:     time.Now(),
+	}
+
+	modTime, err := res.getModTime()
+
+	if err != nil {
+		return nil, err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+
+	if err != nil {
+		return nil, fmt.Errorf("could NOT load the TLS certificate: %s", err)
+	}
+
+	res.certificate = &certificate
+	res.modTime = modTime
+
+	return res, nil
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// Constants
+
+const (
+	ClientAuthNone             = "none"
+	ClientAuthRequest          = "request"
+	ClientAuthRequire          = "require"
+	ClientAuthVerifyIfGiven    = "verify_if_given"
+	ClientAuthRequireAndVerify = "require_and_verify"
+)
+
+// Variables
+
+var (
+	clientAuthTypes = map[string]tls.ClientAuthType{
+		ClientAuthNone:             tls.NoClientCert,
+		ClientAuthRequest:          tls.RequestClientCert,
+		ClientAuthRequire:          tls.RequireAnyClientCert,
+		ClientAuthVerifyIfGiven:    tls.VerifyClientCertIfGiven,
+		ClientAuthRequireAndVerify: tls.RequireAndVerifyClientCert,
+	}
+
+	tlsVersions = map[string]uint16{
+		"1.2": tls.VersionTLS12,
+		"1.3": tls.VersionTLS13,
+	}
+)
+
+// Static functions
+
+// NewHttpServer Creates the server of the public listener. If TLS is enabled, HTTP/2 is negotiated with the clients
+// which support it. Without TLS, HTTP/2 is only served if h2c is enabled.
+func NewHttpServer(serverConfig config.ServerConfig, handler http.Handler) (*http.Server, error) {
+	server := newHttpServer(serverConfig, serverConfig.Host, serverConfig.Port, handler)
+
+	if serverConfig.TLS.IsEnabled() {
+		tlsConfig, err := NewTLSConfig(serverConfig.TLS)
+
+		if err != nil {
+			return nil, err
+		}
+
+		server.TLSConfig = tlsConfig
+
+		return server, nil
+	}
+
+	if serverConfig.H2C {
+		server.Handler = h2c.NewHandler(handler, &http2.Server{IdleTimeout: serverConfig.IdleTimeout})
+	}
+
+	return server, nil
+}
+
+// NewAdminHttpServer Creates the server of the admin listener, which is always served without TLS. It's meant to be
+// reachable only from the internal network.
+func NewAdminHttpServer(serverConfig config.ServerConfig, handler http.Handler) *http.Server {
+	return newHttpServer(serverConfig, serverConfig.Admin.Host, serverConfig.Admin.Port, handler)
+}
+
+// NewTLSConfig Creates the TLS config of the public listener. The certificate is reloaded when its files change.
+func NewTLSConfig(tlsConfig config.TLSConfig) (*tls.Config, error) {
+	if tlsConfig.CertFile == "" || tlsConfig.KeyFile == "" {
+		return nil, errors.New("both the certificate and the key files must be set to enable TLS")
+	}
+
+	certificateLoader, err := NewCertificateLoader(tlsConfig.CertFile, tlsConfig.KeyFile, tlsConfig.ReloadInterval)
+
+	if err != nil {
+		return nil, err
+	}
+
+	clientAuth, found := clientAuthTypes[tlsConfig.ClientAuth]
+
+	if !found {
+		return nil, fmt.Errorf("invalid client auth type '%s'", tlsConfig.ClientAuth)
+	}
+
+	minVersion, found := tlsVersions[tlsConfig.MinVersion]
+
+	if !found {
+		return nil, fmt.Errorf("invalid minimum TLS version '%s'", tlsConfig.MinVersion)
+	}
+
+	res := &tls.Config{
+		GetCertificate: certificateLoader.GetCertificate,
+		ClientAuth:     clientAuth,
+		MinVersion:     minVersion,
+	}
+
+	if tlsConfig.ClientCAFile != "" {
+		clientCAs, err := loadCertPool(tlsConfig.ClientCAFile)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res.ClientCAs = clientCAs
+	} else if clientAuth == tls.VerifyClientCertIfGiven || clientAuth == tls.RequireAndVerifyClientCert {
+		return nil, fmt.Errorf("the client CA file must be set to verify client certificates")
+	}
+
+	return res, nil
+}
+
+func newHttpServer(serverConfig config.ServerConfig, host string, port int, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              net.JoinHostPort(host, strconv.Itoa(port)),
+		Handler:           handler,
+		ReadTimeout:       serverConfig.ReadTimeout,
+		ReadHeaderTimeout: serverConfig.ReadHeaderTimeout,
+		WriteTimeout:      serverConfig.WriteTimeout,
+		IdleTimeout:       serverConfig.IdleTimeout,
+		MaxHeaderBytes:    serverConfig.MaxHeaderBytes,
+	}
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	content, err := ioutil.ReadFile(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := x509.NewCertPool()
+
+	if !res.AppendCertsFromPEM(content) {
+		return nil, fmt.Errorf("no certificates found on '%s'", file)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/repository"
+	"github.com/comfortablynumb/goginrestapi/internal/repository/utils"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/rs/zerolog"
+	validator2 "gopkg.in/go-playground/validator.v9"
+)
+
+// Constants
+
+const (
+	AuditServiceSourceName = "AuditService"
+)
+
+// Interfaces
+
+type AuditService interface {
+	Record(ctx *context.RequestContext, entityType string, entityID int64, entityKey string, action string, before interface{}, after interface{}) *apperror.AppError
+	Find(ctx *context.RequestContext, auditEventFindResource *resource.AuditEventFindResource) (*resource.AuditEventResourceList, *apperror.AppError)
+	History(ctx *context.RequestContext, entityType string, entityID int64, entityKey string) (*resource.AuditEventResourceList, *apperror.AppError)
+}
+
+// Structs
+
+type auditService struct {
+	appConfig            config.AppConfig
+	logger               *zerolog.Logger
+	validator            *validator2.Validate
+	timeService          TimeService
+	auditEventRepository repository.AuditEventRepository
+}
+
+// Record Stores an audit event with the fields that changed between before and after. Any of them may be nil (i.e.
+// before is nil on creations, and after is nil on deletions). Events are linked to the entity by its ID, while its
+// key (as it is after the mutation) is kept to find them. If the context holds a transaction, the event is written
+// inside it, so it's only persisted if the mutation is.
+//
+// The actor is the one of the context: the identity of the verified client certificate or, unless the actor header
+// is not trusted (see config.AuthConfig), the value the client sent on it.
+func (s *auditService) Record(
+	ctx *context.RequestContext,
+	entityType string,
+	entityID int64,
+	entityKey string,
+	action string,
+	before interface{},
+	after interface{},
+) *apperror.AppError {
+	changes, err := s.diff(before, after)
+
+	if err != nil {
+		return apperror.NewAppError(ctx, err, AuditServiceSourceName, apperror.InternalErrorCode, apperror.InternalErrorMessage, nil)
+	}
+
+	auditEvent := model.NewAuditEventBuilder().
+		WithActor(ctx.GetActor()).
+		WithRequestID(ctx.GetRequestID()).
+		WithEntityType(entityType).
+		WithEntityID(entityID).
+		WithEntityKey(entityKey).
+		WithAction(action).
+		WithChanges(changes).
+		WithCreatedAt(s.timeService.GetCurrentUtcTime()).
+		Build()
+
+	return s.auditEventRepository.Create(ctx, auditEvent)
+}
+
+func (s *auditService) Find(ctx *context.RequestContext, auditEventFindResource *resource.AuditEventFindResource) (*resource.AuditEventResourceList, *apperror.AppError) {
+	if err := s.validator.StructCtx(ctx, auditEventFindResource); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, AuditServiceSourceName)
+	}
+
+	filters := utils.NewAuditEventFindFilters().
+		WithEntityType(auditEventFindResource.EntityType).
+		WithEntityKey(auditEventFindResource.EntityKey).
+		WithActor(auditEventFindResource.Actor).
+		WithFrom(auditEventFindResource.From).
+		WithTo(auditEventFindResource.To)
+	options := utils.NewAuditEventFindOptions()
+
+	if auditEventFindResource.SortBy != nil && auditEventFindResource.SortDir != nil {
+		options.WithSortBy(auditEventFindResource.SortBy).
+			WithSortDir(auditEventFindResource.SortDir)
+	}
+
+	if auditEventFindResource.Offset != nil && auditEventFindResource.Limit != nil {
+		options.WithOffset(auditEventFindResource.Offset).
+			WithLimit(auditEventFindResource.Limit)
+	} else {
+		options.WithOffsetValue(0).WithLimitValue(s.appConfig.DefaultLimit)
+	}
+
+	return s.find(ctx, filters, options)
+}
+
+// History Returns the events of the entity with the given ID, whatever its key was when they were recorded. The ID is
+// 0 for entities which no longer exist, in which case the events of the last entity recorded with the given key are
+// returned.
+func (s *auditService) History(ctx *context.RequestContext, entityType string, entityID int64, entityKey string) (*resource.AuditEventResourceList, *apperror.AppError) {
+	filters := utils.NewAuditEventFindFilters().
+		WithEntityTypeValue(entityType)
+
+	if entityID == 0 {
+		lastEntityID, err := s.auditEventRepository.FindLastEntityID(ctx, entityType, entityKey)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// Events recorded before they were linked to their entity, which was deleted by then, only have the key
+
+		if lastEntityID == 0 {
+			filters.WithEntityKeyValue(entityKey)
+		}
+
+		entityID = lastEntityID
+	}
+
+	filters.WithEntityIDValue(entityID)
+
+	return s.find(ctx, filters, utils.NewAuditEventFindOptions())
+}
+
+func (s *auditService) find(
+	ctx *context.RequestContext,
+	filters *utils.AuditEventFindFilters,
+	options *utils.AuditEventFindOptions,
+) (*resource.AuditEventResourceList, *apperror.AppError) {
+	count, err := s.auditEventRepository.Count(ctx, filters, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*resource.AuditEventResource, 0)
+
+	if count < 1 {
+		return resource.NewAuditEventResourceList(result, count), nil
+	}
+
+	rows, err := s.auditEventRepository.Find(ctx, filters, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result = append(result, resource.FromAuditEvent(*row))
+	}
+
+	return resource.NewAuditEventResourceList(result, count), nil
+}
+
+// diff Returns a JSON object with an entry per changed field, holding its value before and after the mutation.
+func (s *auditService) diff(before interface{}, after interface{}) (string, error) {
+	beforeFields, err := s.toFields(before)
+
+	if err != nil {
+		return "", err
+	}
+
+	afterFields, err := s.toFields(after)
+
+	if err != nil {
+		return "", err
+	}
+
+	changes := make(map[string]*resource.AuditChange)
+
+	for field, beforeValue := range beforeFields {
+		afterValue := afterFields[field]
+
+		if !reflect.DeepEqual(beforeValue, afterValue) {
+			changes[field] = &resource.AuditChange{Before: beforeValue, After: afterValue}
+		}
+	}
+
+	for field, afterValue := range afterFields {
+		if _, found := beforeFields[field]; !found {
+			changes[field] = &resource.AuditChange{Before: nil, After: afterValue}
+		}
+	}
+
+	res, err := json.Marshal(changes)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(res), nil
+}
+
+func (s *auditService) toFields(value interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil()) {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// Static functions
+
+func NewAuditService(
+	appConfig config.AppConfig,
+	logger *zerolog.Logger,
+	validator *validator2.Validate,
+	timeService TimeService,
+	auditEventRepository repository.AuditEventRepository,
+) AuditService {
+	return &auditService{
+		appConfig:            appConfig,
+		logger:               logger,
+		validator:            validator,
+		timeService:          timeService,
+		auditEventRepository: auditEventRepository,
+	}
+}
@@ -0,0 +1,694 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/cache"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/i18n"
+	"github.com/comfortablynumb/goginrestapi/internal/mailer"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/password"
+	"github.com/comfortablynumb/goginrestapi/internal/repository"
+	"github.com/comfortablynumb/goginrestapi/internal/repository/utils"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/comfortablynumb/goginrestapi/internal/totp"
+	"github.com/rs/zerolog"
+	validator2 "gopkg.in/go-playground/validator.v9"
+)
+
+// Constants
+
+const (
+	AuthServiceSourceName = "AuthService"
+
+	PasswordResetTokenSize = 32
+
+	// Recovery codes have 10 base32 characters (50 bits), shown as two groups of 5
+	RecoveryCodeLength      = 10
+	RecoveryCodeGroupLength = 5
+
+	PasswordResetEmailSubjectKey = "auth.password_reset_email.subject"
+	PasswordResetEmailBodyKey    = "auth.password_reset_email.body"
+)
+
+// Variables
+
+var (
+	// AuthMessages Messages of the emails sent by the auth service, by locale. Bodies receive the name of the user as
+	// {0}, the token as {1} and its expiration time, on the timezone of the user, as {2}.
+	AuthMessages = map[string]map[string]string{
+		i18n.EnglishLocale: {
+			PasswordResetEmailSubjectKey: "Reset your password",
+			PasswordResetEmailBodyKey:    "Hi {0},\n\nUse the following token to choose a new password: {1}\n\nIt expires on {2}. If you didn't ask for it, ignore this email.",
+		},
+		i18n.SpanishLocale: {
+			PasswordResetEmailSubjectKey: "Restablezca su contraseña",
+			PasswordResetEmailBodyKey:    "Hola {0},\n\nUse el siguiente token para elegir una nueva contraseña: {1}\n\nExpira el {2}. Si no lo ha solicitado, ignore este email.",
+		},
+	}
+)
+
+// Interfaces
+
+type AuthService interface {
+	Login(ctx *context.RequestContext, loginResource *resource.LoginResource) (*resource.UserResource, *apperror.AppError)
+	RequestPasswordReset(ctx *context.RequestContext, passwordResetRequestResource *resource.PasswordResetRequestResource) *apperror.AppError
+	ConfirmPasswordReset(ctx *context.RequestContext, passwordResetConfirmResource *resource.PasswordResetConfirmResource) (*resource.UserResource, *apperror.AppError)
+	EnrollMfa(ctx *context.RequestContext, mfaEnrollResource *resource.MfaEnrollResource) (*resource.MfaEnrollmentResource, *apperror.AppError)
+	ConfirmMfa(ctx *context.RequestContext, mfaConfirmResource *resource.MfaConfirmResource) (*resource.MfaRecoveryCodesResource, *apperror.AppError)
+	DeleteExpiredTokens(ctx *context.RequestContext) (int64, *apperror.AppError)
+}
+
+// Structs
+
+type authService struct {
+	appConfig                    config.AppConfig
+	logger                       *zerolog.Logger
+	validator                    *validator2.Validate
+	timeService                  TimeService
+	transactionService           TransactionService
+	auditService                 AuditService
+	eventService                 EventService
+	cacheService                 CacheService
+	userRepository               repository.UserRepository
+	passwordResetTokenRepository repository.PasswordResetTokenRepository
+	recoveryCodeRepository       repository.RecoveryCodeRepository
+	translator                   *i18n.Translator
+	mailer                       mailer.Mailer
+	dummyPasswordHash            string
+	dummyPasswordHashOnce        sync.Once
+}
+
+type checkedCredentials struct {
+	user           *model.User
+	before         *resource.UserResource
+	lockoutExpired bool
+}
+
+// Login Checks the password of the user and, if the user has or must have a second factor, its TOTP code or one of
+// its recovery codes. Failed logins are counted and, after too many of them in a row, the user is locked out for a
+// while.
+func (s *authService) Login(ctx *context.RequestContext, loginResource *resource.LoginResource) (*resource.UserResource, *apperror.AppError) {
+	if err := s.validator.StructCtx(ctx, loginResource); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, AuthServiceSourceName)
+	}
+
+	credentials, err := s.checkCredentials(ctx, loginResource.Username, loginResource.Password)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user := credentials.user
+	now := s.timeService.GetCurrentUtcTime()
+	changed := user.FailedLoginAttempts > 0 || credentials.lockoutExpired
+
+	var recoveryCode *model.RecoveryCode
+	var totpStep int64
+
+	if user.IsMfaRequired() {
+		if !user.IsMfaEnabled() {
+			return nil, apperror.NewMfaEnrollmentRequiredAppError(ctx, errors.New("the user type requires a second factor, and the user has none"), AuthServiceSourceName)
+		}
+
+		if loginResource.Code == "" && loginResource.RecoveryCode == "" {
+			return nil, apperror.NewMfaRequiredAppError(ctx, errors.New("the user has a second factor"), AuthServiceSourceName)
+		}
+
+		if loginResource.Code != "" {
+			step, valid := s.validateTotpCode(user, loginResource.Code, now)
+
+			if !valid {
+				return nil, s.recordFailedLogin(ctx, user, credentials.before, apperror.NewInvalidMfaCodeAppError(ctx, errors.New("the TOTP code is not valid or was already used"), AuthServiceSourceName))
+			}
+
+			totpStep = step
+		} else {
+			recoveryCode, err = s.recoveryCodeRepository.FindOneUnusedByUserIDAndCodeHash(ctx, user.ID, hashRecoveryCode(loginResource.RecoveryCode))
+
+			if err != nil {
+				return nil, err
+			}
+
+			if recoveryCode == nil {
+				return nil, s.recordFailedLogin(ctx, user, credentials.before, apperror.NewInvalidMfaCodeAppError(ctx, errors.New("the recovery code is not valid or was already used"), AuthServiceSourceName))
+			}
+		}
+
+		changed = true
+	}
+
+	if changed {
+		user.FailedLoginAttempts = 0
+		user.UpdatedAt = now
+
+		err = s.transactionService.RunInTransaction(ctx, func() *apperror.AppError {
+			s.cacheService.Invalidate(ctx, cache.UserTag)
+
+			if recoveryCode != nil {
+				marked, err := s.recoveryCodeRepository.MarkUsed(ctx, recoveryCode, now)
+
+				if err != nil {
+					return err
+				}
+
+				if !marked {
+					return apperror.NewInvalidMfaCodeAppError(ctx, errors.New("the recovery code was used by another login"), AuthServiceSourceName)
+				}
+			}
+
+			if totpStep > 0 {
+				used, err := s.userRepository.UseTotpStep(ctx, user, totpStep)
+
+				if err != nil {
+					return err
+				}
+
+				if !used {
+					return apperror.NewInvalidMfaCodeAppError(ctx, errors.New("the TOTP code was used by another login"), AuthServiceSourceName)
+				}
+			}
+
+			return s.userRepository.Update(ctx, user)
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resource.FromUser(*user), nil
+}
+
+// EnrollMfa Creates a new TOTP secret for the user. The second factor is not enabled until the first code generated
+// with it is confirmed with ConfirmMfa. Enrolling again before that replaces the secret.
+func (s *authService) EnrollMfa(ctx *context.RequestContext, mfaEnrollResource *resource.MfaEnrollResource) (*resource.MfaEnrollmentResource, *apperror.AppError) {
+	if err := s.validator.StructCtx(ctx, mfaEnrollResource); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, AuthServiceSourceName)
+	}
+
+	credentials, err := s.checkCredentials(ctx, mfaEnrollResource.Username, mfaEnrollResource.Password)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user := credentials.user
+
+	if user.IsMfaEnabled() {
+		return nil, apperror.NewMfaAlreadyEnabledAppError(ctx, errors.New("the user already has a second factor"), AuthServiceSourceName)
+	}
+
+	secret, secretErr := totp.NewSecret()
+
+	if secretErr != nil {
+		return nil, apperror.NewAppError(ctx, secretErr, AuthServiceSourceName, apperror.InternalErrorCode, apperror.InternalErrorMessage, nil)
+	}
+
+	user.TotpSecret = secret
+	user.TotpLastUsedStep = 0
+	user.FailedLoginAttempts = 0
+	user.UpdatedAt = s.timeService.GetCurrentUtcTime()
+
+	err = s.transactionService.RunInTransaction(ctx, func() *apperror.AppError {
+		s.cacheService.Invalidate(ctx, cache.UserTag)
+
+		return s.userRepository.Update(ctx, user)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &resource.MfaEnrollmentResource{
+		Secret: secret,
+		URI:    totp.NewURI(s.appConfig.Auth.MfaIssuer, user.Username, secret),
+	}, nil
+}
+
+// ConfirmMfa Enables the second factor of the user, if the code was generated with the enrolled secret. Returns new
+// recovery codes, which replace any previous ones.
+func (s *authService) ConfirmMfa(ctx *context.RequestContext, mfaConfirmResource *resource.MfaConfirmResource) (*resource.MfaRecoveryCodesResource, *apperror.AppError) {
+	if err := s.validator.StructCtx(ctx, mfaConfirmResource); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, AuthServiceSourceName)
+	}
+
+	credentials, err := s.checkCredentials(ctx, mfaConfirmResource.Username, mfaConfirmResource.Password)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user := credentials.user
+
+	if user.IsMfaEnabled() {
+		return nil, apperror.NewMfaAlreadyEnabledAppError(ctx, errors.New("the user already has a second factor"), AuthServiceSourceName)
+	}
+
+	now := s.timeService.GetCurrentUtcTime()
+	step, valid := s.validateTotpCode(user, mfaConfirmResource.Code, now)
+
+	if !valid {
+		return nil, s.recordFailedLogin(ctx, user, credentials.before, apperror.NewInvalidMfaCodeAppError(ctx, errors.New("the TOTP code is not valid, or the user did not enroll a second factor"), AuthServiceSourceName))
+	}
+
+	plainRecoveryCodes := make([]string, 0, s.appConfig.Auth.RecoveryCodes)
+	recoveryCodes := make([]*model.RecoveryCode, 0, s.appConfig.Auth.RecoveryCodes)
+
+	for i := 0; i < s.appConfig.Auth.RecoveryCodes; i++ {
+		plainRecoveryCode, codeErr := newRecoveryCode()
+
+		if codeErr != nil {
+			return nil, apperror.NewAppError(ctx, codeErr, AuthServiceSourceName, apperror.InternalErrorCode, apperror.InternalErrorMessage, nil)
+		}
+
+		plainRecoveryCodes = append(plainRecoveryCodes, plainRecoveryCode)
+		recoveryCodes = append(recoveryCodes, model.NewRecoveryCodeBuilder().
+			WithUserID(user.ID).
+			WithCodeHash(hashRecoveryCode(plainRecoveryCode)).
+			WithCreatedAt(now).
+			Build())
+	}
+
+	user.TotpEnabledAt = &now
+	user.FailedLoginAttempts = 0
+	user.UpdatedAt = now
+
+	err = s.transactionService.RunInTransaction(ctx, func() *apperror.AppError {
+		s.cacheService.Invalidate(ctx, cache.UserTag)
+
+		used, err := s.userRepository.UseTotpStep(ctx, user, step)
+
+		if err != nil {
+			return err
+		}
+
+		if !used {
+			return apperror.NewInvalidMfaCodeAppError(ctx, errors.New("the TOTP code was used by another request"), AuthServiceSourceName)
+		}
+
+		if err := s.recoveryCodeRepository.DeleteByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+
+		for _, recoveryCode := range recoveryCodes {
+			if err := s.recoveryCodeRepository.Create(ctx, recoveryCode); err != nil {
+				return err
+			}
+		}
+
+		if err := s.userRepository.Update(ctx, user); err != nil {
+			return err
+		}
+
+		after := resource.FromUser(*user)
+
+		if err := s.auditService.Record(ctx, model.AuditEntityTypeUser, user.ID, user.Username, model.AuditActionUpdate, credentials.before, after); err != nil {
+			return err
+		}
+
+		return s.eventService.Publish(ctx, &events.UserMfaEnabled{User: after})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &resource.MfaRecoveryCodesResource{RecoveryCodes: plainRecoveryCodes}, nil
+}
+
+// RequestPasswordReset Sends a single-use token to the email of the user, to choose a new password. Nothing is sent to
+// unknown users, or to users without email, but the request succeeds anyway so it doesn't tell which users exist.
+func (s *authService) RequestPasswordReset(ctx *context.RequestContext, passwordResetRequestResource *resource.PasswordResetRequestResource) *apperror.AppError {
+	if err := s.validator.StructCtx(ctx, passwordResetRequestResource); err != nil {
+		return apperror.NewValidationAppError(ctx, err, AuthServiceSourceName)
+	}
+
+	filters := utils.NewUserFindFilters()
+
+	if passwordResetRequestResource.Username != "" {
+		filters.WithUsernameValue(passwordResetRequestResource.Username)
+	} else {
+		filters.WithEmailValue(normalizeEmail(passwordResetRequestResource.Email))
+	}
+
+	users, err := s.userRepository.Find(ctx, filters, utils.NewUserFindOptions().WithOffsetValue(0).WithLimitValue(1))
+
+	if err != nil {
+		return err
+	}
+
+	if len(users) < 1 || users[0].Email == "" || users[0].Disabled {
+		return nil
+	}
+
+	user := users[0]
+	plainToken, tokenErr := newPasswordResetToken()
+
+	if tokenErr != nil {
+		return apperror.NewAppError(ctx, tokenErr, AuthServiceSourceName, apperror.InternalErrorCode, apperror.InternalErrorMessage, nil)
+	}
+
+	now := s.timeService.GetCurrentUtcTime()
+	passwordResetToken := model.NewPasswordResetTokenBuilder().
+		WithUserID(user.ID).
+		WithTokenHash(hashPasswordResetToken(plainToken)).
+		WithExpiresAt(now.Add(s.appConfig.Auth.ResetTokenTTL)).
+		WithCreatedAt(now).
+		Build()
+
+	return s.transactionService.RunInTransaction(ctx, func() *apperror.AppError {
+		if err := s.passwordResetTokenRepository.Create(ctx, passwordResetToken); err != nil {
+			return err
+		}
+
+		// As the token is stored by then, failures are just logged: the user can ask for a new one
+
+		s.transactionService.AfterCommit(ctx, func() {
+			if err := s.sendPasswordResetEmail(ctx, user, plainToken, passwordResetToken); err != nil {
+				s.logger.Error().Msgf("[AuthService] Could NOT send the password reset email to user '%s': %s", user.Username, err)
+			}
+		})
+
+		return nil
+	})
+}
+
+// ConfirmPasswordReset Sets the new password of the user, if the token is valid. Every pending token of the user is
+// used up, and its lockout ends.
+func (s *authService) ConfirmPasswordReset(ctx *context.RequestContext, passwordResetConfirmResource *resource.PasswordResetConfirmResource) (*resource.UserResource, *apperror.AppError) {
+	if err := s.validator.StructCtx(ctx, passwordResetConfirmResource); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, AuthServiceSourceName)
+	}
+
+	now := s.timeService.GetCurrentUtcTime()
+	passwordResetToken, err := s.passwordResetTokenRepository.FindOneByTokenHash(ctx, hashPasswordResetToken(passwordResetConfirmResource.Token))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if passwordResetToken == nil || !passwordResetToken.IsUsable(now) {
+		return nil, apperror.NewInvalidTokenAppError(ctx, errors.New("the password reset token does not exist, was used or has expired"), AuthServiceSourceName)
+	}
+
+	user, err := s.userRepository.FindOneByID(ctx, passwordResetToken.UserID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil || user.Disabled {
+		return nil, apperror.NewInvalidTokenAppError(ctx, errors.New("the user of the password reset token does not exist or is disabled"), AuthServiceSourceName)
+	}
+
+	passwordHash, hashErr := password.Hash(passwordResetConfirmResource.Password)
+
+	if hashErr != nil {
+		return nil, apperror.NewAppError(ctx, hashErr, AuthServiceSourceName, apperror.InternalErrorCode, apperror.InternalErrorMessage, nil)
+	}
+
+	user.ClearExpiredLockout(now)
+
+	before := resource.FromUser(*user)
+
+	user.PasswordHash = passwordHash
+	user.PasswordChangedAt = &now
+	user.FailedLoginAttempts = 0
+	user.LockedUntil = nil
+	user.UpdatedAt = now
+
+	err = s.transactionService.RunInTransaction(ctx, func() *apperror.AppError {
+		s.cacheService.Invalidate(ctx, cache.UserTag)
+
+		// The token is marked as used first, and only if it's still unused, so concurrent requests with the same token
+		// can't both change the password. Then, the other tokens of the user are invalidated too
+
+		used, err := s.passwordResetTokenRepository.MarkUsed(ctx, passwordResetToken, now)
+
+		if err != nil {
+			return err
+		}
+
+		if !used {
+			return apperror.NewInvalidTokenAppError(ctx, errors.New("the password reset token was used in the meantime"), AuthServiceSourceName)
+		}
+
+		if err := s.passwordResetTokenRepository.MarkUsedByUserID(ctx, user.ID, now); err != nil {
+			return err
+		}
+
+		if err := s.userRepository.Update(ctx, user); err != nil {
+			return err
+		}
+
+		return s.auditService.Record(ctx, model.AuditEntityTypeUser, user.ID, user.Username, model.AuditActionUpdate, before, resource.FromUser(*user))
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource.FromUser(*user), nil
+}
+
+// DeleteExpiredTokens Deletes the password reset tokens which can't be used anymore.
+func (s *authService) DeleteExpiredTokens(ctx *context.RequestContext) (int64, *apperror.AppError) {
+	deleted, err := s.passwordResetTokenRepository.DeleteExpired(ctx, s.timeService.GetCurrentUtcTime())
+
+	if err != nil {
+		return 0, err
+	}
+
+	if deleted > 0 {
+		s.logger.Debug().Msgf("[AuthService] Deleted %d expired password reset tokens.", deleted)
+	}
+
+	return deleted, nil
+}
+
+// checkCredentials Checks the password of the user. Unknown users, users without password and disabled users fail
+// like a wrong password does, so the response doesn't tell which users exist. Expired lockouts are cleared, but not
+// stored: callers must update the user if lockoutExpired is set.
+func (s *authService) checkCredentials(ctx *context.RequestContext, username string, plainPassword string) (*checkedCredentials, *apperror.AppError) {
+	user, err := s.userRepository.FindOneByUsername(ctx, username)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil || !user.HasPassword() {
+		password.Verify(plainPassword, s.getDummyPasswordHash())
+
+		return nil, apperror.NewInvalidCredentialsAppError(ctx, errors.New("the user does not exist or has no password"), AuthServiceSourceName)
+	}
+
+	now := s.timeService.GetCurrentUtcTime()
+
+	if user.IsLocked(now) {
+		return nil, s.newAccountLockedAppError(ctx, user)
+	}
+
+	lockoutExpired := user.ClearExpiredLockout(now)
+	before := resource.FromUser(*user)
+
+	if !password.Verify(plainPassword, user.PasswordHash) || user.Disabled {
+		return nil, s.recordFailedLogin(ctx, user, before, apperror.NewInvalidCredentialsAppError(ctx, errors.New("the password is not valid or the user is disabled"), AuthServiceSourceName))
+	}
+
+	return &checkedCredentials{user: user, before: before, lockoutExpired: lockoutExpired}, nil
+}
+
+// validateTotpCode Returns the time step of the code, if it's valid for the secret of the user and newer than the last
+// one used.
+func (s *authService) validateTotpCode(user *model.User, code string, now time.Time) (int64, bool) {
+	if user.TotpSecret == "" {
+		return 0, false
+	}
+
+	step, valid := totp.Validate(user.TotpSecret, code, now, s.appConfig.Auth.MfaSkew)
+
+	if !valid || step <= user.TotpLastUsedStep {
+		return 0, false
+	}
+
+	return step, true
+}
+
+// recordFailedLogin Counts the failed login and locks the user out once it reaches the limit. The counter is
+// incremented by the database and the lockout is decided from the stored value, so concurrent failed logins can't skip
+// it. Lockouts are audited, and published as events. The changes are committed even if the login fails. Returns
+// failure, or the lockout error if the user was locked out.
+func (s *authService) recordFailedLogin(ctx *context.RequestContext, user *model.User, before *resource.UserResource, failure *apperror.AppError) *apperror.AppError {
+	now := s.timeService.GetCurrentUtcTime()
+	locked := false
+
+	err := s.transactionService.RunInTransaction(ctx, func() *apperror.AppError {
+		var err *apperror.AppError
+
+		s.cacheService.Invalidate(ctx, cache.UserTag)
+
+		locked, err = s.userRepository.RecordFailedLogin(ctx, user, s.appConfig.Auth.MaxFailedLogins, now, now.Add(s.appConfig.Auth.LockoutDuration))
+
+		if err != nil {
+			return err
+		}
+
+		if !locked {
+			return nil
+		}
+
+		after := resource.FromUser(*user)
+
+		if err := s.auditService.Record(ctx, model.AuditEntityTypeUser, user.ID, user.Username, model.AuditActionUpdate, before, after); err != nil {
+			return err
+		}
+
+		return s.eventService.Publish(ctx, &events.UserLocked{User: after})
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// Another failed login may have locked the user out in the meantime
+
+	if locked || user.IsLocked(now) {
+		return s.newAccountLockedAppError(ctx, user)
+	}
+
+	return failure
+}
+
+func (s *authService) newAccountLockedAppError(ctx *context.RequestContext, user *model.User) *apperror.AppError {
+	return apperror.NewAccountLockedAppError(ctx, errors.New("the user is locked out"), AuthServiceSourceName, map[string]interface{}{
+		"locked_until": user.LockedUntil,
+	})
+}
+
+// sendPasswordResetEmail The email is written in the locale of the user, and its expiration time is shown on their
+// timezone.
+func (s *authService) sendPasswordResetEmail(ctx *context.RequestContext, user *model.User, plainToken string, passwordResetToken *model.PasswordResetToken) error {
+	name := user.DisplayName
+
+	if name == "" {
+		name = user.Username
+	}
+
+	expiresAt := passwordResetToken.ExpiresAt
+
+	if location, err := time.LoadLocation(user.Timezone); err == nil {
+		expiresAt = expiresAt.In(location)
+	}
+
+	// Without messages for the locale, the email still carries the token
+
+	subject := s.translator.Translate(user.Locale, PasswordResetEmailSubjectKey, PasswordResetEmailSubjectKey)
+	body := s.translator.Translate(user.Locale, PasswordResetEmailBodyKey, plainToken, name, plainToken, expiresAt.Format(EmailTimeFormat))
+
+	return s.mailer.Send(ctx, &mailer.Message{
+		From:    s.appConfig.Mail.From,
+		To:      user.Email,
+		Subject: subject,
+		Body:    body,
+	})
+}
+
+// getDummyPasswordHash The dummy hash is created on the first login of an unknown user, as it takes a while.
+func (s *authService) getDummyPasswordHash() string {
+	s.dummyPasswordHashOnce.Do(func() {
+		dummyPasswordHash, err := password.NewDummyHash()
+
+		if err != nil {
+			s.logger.Error().Msgf("[AuthService] Could NOT create the dummy password hash: %s", err)
+		}
+
+		s.dummyPasswordHash = dummyPasswordHash
+	})
+
+	return s.dummyPasswordHash
+}
+
+// Static functions
+
+func NewAuthService(
+	appConfig config.AppConfig,
+	logger *zerolog.Logger,
+	validator *validator2.Validate,
+	timeService TimeService,
+	transactionService TransactionService,
+	auditService AuditService,
+	eventService EventService,
+	cacheService CacheService,
+	userRepository repository.UserRepository,
+	passwordResetTokenRepository repository.PasswordResetTokenRepository,
+	recoveryCodeRepository repository.RecoveryCodeRepository,
+	translator *i18n.Translator,
+	mailer mailer.Mailer,
+) AuthService {
+	return &authService{
+		appConfig:                    appConfig,
+		logger:                       logger,
+		validator:                    validator,
+		timeService:                  timeService,
+		transactionService:           transactionService,
+		auditService:                 auditService,
+		eventService:                 eventService,
+		cacheService:                 cacheService,
+		userRepository:               userRepository,
+		passwordResetTokenRepository: passwordResetTokenRepository,
+		recoveryCodeRepository:       recoveryCodeRepository,
+		translator:                   translator,
+		mailer:                       mailer,
+	}
+}
+
+func newPasswordResetToken() (string, error) {
+	token := make([]byte, PasswordResetTokenSize)
+
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// hashPasswordResetToken Tokens are random enough to be stored with a fast hash: a leaked hash can't be used as a
+// token, and the token can't be guessed from it.
+func hashPasswordResetToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
+}
+
+func newRecoveryCode() (string, error) {
+	random := make([]byte, RecoveryCodeLength)
+
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(random))[:RecoveryCodeLength]
+
+	return code[:RecoveryCodeGroupLength] + "-" + code[RecoveryCodeGroupLength:], nil
+}
+
+// hashRecoveryCode Codes are hashed without their separators and case, so users can type them either way.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	hash := sha256.Sum256([]byte(normalized))
+
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"github.com/comfortablynumb/goginrestapi/internal/cache"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/rs/zerolog"
+)
+
+// Interfaces
+
+type CacheService interface {
+	Invalidate(ctx *context.RequestContext, tags ...string)
+	GetStats(ctx *context.RequestContext) *resource.CacheStatsResourceList
+}
+
+// Structs
+
+type cacheService struct {
+	logger             *zerolog.Logger
+	transactionService TransactionService
+	cacheManager       *cache.Manager
+}
+
+// Invalidate Deletes the cached entries with the given tags once the transaction of the context is committed, so
+// they can't be cached again with the data being replaced.
+func (s *cacheService) Invalidate(ctx *context.RequestContext, tags ...string) {
+	s.transactionService.AfterCommit(ctx, func() {
+		s.logger.Debug().Msgf("[CacheService] Invalidating tags: %v", tags)
+
+		s.cacheManager.Invalidate(tags...)
+	})
+}
+
+func (s *cacheService) GetStats(ctx *context.RequestContext) *resource.CacheStatsResourceList {
+	res := &resource.CacheStatsResourceList{
+		Data: make([]*resource.CacheStatsResource, 0),
+	}
+
+	for _, stats := range s.cacheManager.GetStats() {
+		res.Data = append(res.Data, resource.FromCacheStats(stats))
+	}
+
+	return res
+}
+
+// Static functions
+
+func NewCacheService(logger *zerolog.Logger, transactionService TransactionService, cacheManager *cache.Manager) CacheService {
+	return &cacheService{
+		logger:             logger,
+		transactionService: transactionService,
+		cacheManager:       cacheManager,
+	}
+}
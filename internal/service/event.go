@@ -0,0 +1,65 @@
+package service
+
+import (
+	"encoding/json"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+// Constants
+
+const (
+	EventServiceSourceName = "EventService"
+)
+
+// Interfaces
+
+type EventService interface {
+	Publish(ctx *context.RequestContext, event events.Event) *apperror.AppError
+}
+
+// Structs
+
+type eventService struct {
+	logger                *zerolog.Logger
+	timeService           TimeService
+	outboxEventRepository repository.OutboxEventRepository
+}
+
+// Publish Writes the event on the outbox. If the context holds a transaction, the event is written inside it, so it's
+// only dispatched if the mutation which generated it is committed.
+func (s *eventService) Publish(ctx *context.RequestContext, event events.Event) *apperror.AppError {
+	payload, err := json.Marshal(event)
+
+	if err != nil {
+		return apperror.NewAppError(ctx, err, EventServiceSourceName, apperror.InternalErrorCode, apperror.InternalErrorMessage, nil)
+	}
+
+	now := s.timeService.GetCurrentUtcTime()
+
+	outboxEvent := model.NewOutboxEventBuilder().
+		WithName(event.GetName()).
+		WithPayload(string(payload)).
+		WithActor(ctx.GetActor()).
+		WithRequestID(ctx.GetRequestID()).
+		WithNextAttemptAt(now).
+		WithCreatedAt(now).
+		Build()
+
+	return s.outboxEventRepository.Create(ctx, outboxEvent)
+}
+
+// Static functions
+
+func NewEventService(logger *zerolog.Logger, timeService TimeService, outboxEventRepository repository.OutboxEventRepository) EventService {
+	return &eventService{
+		logger:                logger,
+		timeService:           timeService,
+		outboxEventRepository: outboxEventRepository,
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/repository"
+	"github.com/rs/zerolog"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// Constants
+
+const (
+	IdempotencyServiceSourceName = "IdempotencyService"
+)
+
+// Interfaces
+
+type IdempotencyService interface {
+	Begin(ctx *context.RequestContext, key string, route string, caller string, requestHash string) (*model.IdempotencyKey, *apperror.AppError)
+	Complete(ctx *context.RequestContext, idempotencyKey *model.IdempotencyKey, status int, contentType string, body []byte) *apperror.AppError
+	Release(ctx *context.RequestContext, idempotencyKey *model.IdempotencyKey) *apperror.AppError
+	DeleteExpired(ctx *context.RequestContext) (int64, *apperror.AppError)
+}
+
+// Structs
+
+// IdempotencyConfig Config section of the idempotency module. Requests with a key are read in full to hash them, so
+// their bodies can't be bigger than MaxBodyBytes.
+type IdempotencyConfig struct {
+	KeyTTL       time.Duration `yaml:"key_ttl" default:"24h" validate:"gt=0"`
+	LockTimeout  time.Duration `yaml:"lock_timeout" default:"1m" validate:"gt=0"`
+	GCInterval   time.Duration `yaml:"gc_interval" default:"1h" validate:"gt=0"`
+	MaxBodyBytes int64         `yaml:"max_body_bytes" default:"1048576" validate:"min=1"`
+}
+
+// idempotencyService
+
+type idempotencyService struct {
+	appConfig         config.AppConfig
+	idempotencyConfig IdempotencyConfig
+	logger            *zerolog.Logger
+	validator         *validator.Validate
+	timeService       TimeService
+	repository        repository.IdempotencyKeyRepository
+}
+
+// Begin Reserves the key for the request. If the key was already used for the same route and caller, and its response
+// was stored, it's returned completed so it can be replayed. Keys used with a different request, or whose request is
+// still being processed, return an error. Requests which didn't finish within the lock timeout (i.e. the process died)
+// are taken over by the new request. If several requests try to take it over at the same time, only one of them does:
+// the rest get the same error as if it was still in progress.
+func (s *idempotencyService) Begin(ctx *context.RequestContext, key string, route string, caller string, requestHash string) (*model.IdempotencyKey, *apperror.AppError) {
+	if err := s.validator.Var(key, "required,max=255"); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, IdempotencyServiceSourceName)
+	}
+
+	now := s.timeService.GetCurrentUtcTime()
+	idempotencyKey := model.NewIdempotencyKeyBuilder().
+		WithKey(key).
+		WithRoute(route).
+		WithCaller(caller).
+		WithRequestHash(requestHash).
+		WithStatus(model.IdempotencyKeyStatusInProgress).
+		WithCreatedAt(now).
+		WithExpiresAt(now.Add(s.idempotencyConfig.KeyTTL)).
+		Build()
+
+	created, err := s.repository.Create(ctx, idempotencyKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if created {
+		return idempotencyKey, nil
+	}
+
+	existing, err := s.repository.FindOne(ctx, key, route, caller)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if existing == nil {
+		// It was deleted in the meantime (i.e. it expired, or its request failed), so we can try again
+
+		return s.Begin(ctx, key, route, caller, requestHash)
+	}
+
+	if !existing.ExpiresAt.After(now) {
+		// If it was not deleted, another request changed it in the meantime, so we just try again
+
+		if _, err := s.repository.Delete(ctx, existing); err != nil {
+			return nil, err
+		}
+
+		return s.Begin(ctx, key, route, caller, requestHash)
+	}
+
+	if existing.RequestHash != requestHash {
+		return nil, apperror.NewIdempotencyKeyMismatchAppError(ctx, nil, IdempotencyServiceSourceName)
+	}
+
+	if existing.IsCompleted() {
+		return existing, nil
+	}
+
+	if existing.CreatedAt.Add(s.idempotencyConfig.LockTimeout).After(now) {
+		return nil, apperror.NewIdempotencyKeyInProgressAppError(ctx, nil, IdempotencyServiceSourceName)
+	}
+
+	takenOver, err := s.repository.TakeOver(ctx, existing, now, idempotencyKey.ExpiresAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !takenOver {
+		return nil, apperror.NewIdempotencyKeyInProgressAppError(ctx, nil, IdempotencyServiceSourceName)
+	}
+
+	s.logger.Warn().Msgf("[IdempotencyService] Took over key '%s' of route '%s', as its request did not finish in time.", key, route)
+
+	return existing, nil
+}
+
+// Complete Stores the response of the request, so it's replayed on retries. If the key was taken over by another
+// request in the meantime, it's left untouched, as it belongs to that request now.
+func (s *idempotencyService) Complete(ctx *context.RequestContext, idempotencyKey *model.IdempotencyKey, status int, contentType string, body []byte) *apperror.AppError {
+	idempotencyKey.Status = model.IdempotencyKeyStatusCompleted
+	idempotencyKey.ResponseStatus = status
+	idempotencyKey.ResponseContentType = contentType
+	idempotencyKey.ResponseBody = body
+
+	updated, err := s.repository.Update(ctx, idempotencyKey)
+
+	if err != nil {
+		return err
+	}
+
+	if !updated {
+		s.logger.Warn().Msgf("[IdempotencyService] Could NOT complete key '%s' of route '%s', as it was taken over by another request.", idempotencyKey.Key, idempotencyKey.Route)
+	}
+
+	return nil
+}
+
+// Release Deletes the key, so the request can be retried with it. Used when the request failed, and its response
+// should not be replayed. If the key was taken over by another request in the meantime, it's left untouched.
+func (s *idempotencyService) Release(ctx *context.RequestContext, idempotencyKey *model.IdempotencyKey) *apperror.AppError {
+	deleted, err := s.repository.Delete(ctx, idempotencyKey)
+
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		s.logger.Warn().Msgf("[IdempotencyService] Could NOT release key '%s' of route '%s', as it was taken over by another request.", idempotencyKey.Key, idempotencyKey.Route)
+	}
+
+	return nil
+}
+
+func (s *idempotencyService) DeleteExpired(ctx *context.RequestContext) (int64, *apperror.AppError) {
+	deleted, err := s.repository.DeleteExpired(ctx, s.timeService.GetCurrentUtcTime())
+
+	if err != nil {
+		return 0, err
+	}
+
+	if deleted > 0 {
+		s.logger.Debug().Msgf("[IdempotencyService] Deleted %d expired keys.", deleted)
+	}
+
+	return deleted, nil
+}
+
+// Static functions
+
+func NewIdempotencyService(
+	appConfig config.AppConfig,
+	idempotencyConfig IdempotencyConfig,
+	logger *zerolog.Logger,
+	validator *validator.Validate,
+	timeService TimeService,
+	repository repository.IdempotencyKeyRepository,
+) IdempotencyService {
+	return &idempotencyService{
+		appConfig:         appConfig,
+		idempotencyConfig: idempotencyConfig,
+		logger:            logger,
+		validator:         validator,
+		timeService:       timeService,
+		repository:        repository,
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/comfortablynumb/goginrestapi/internal/stream"
+	"github.com/rs/zerolog"
+	validator2 "gopkg.in/go-playground/validator.v9"
+)
+
+// Constants
+
+const (
+	StreamServiceSourceName = "StreamService"
+
+	// StreamAnyActor Key of the stream permissions of the actors without their own.
+	StreamAnyActor = "*"
+)
+
+// Types
+
+// StreamAuthorizer Returns true if the caller is allowed to see the notification. It's called for every notification
+// sent to a subscription, from the goroutine which publishes it, so it only receives the caller as it was when the
+// subscription was created.
+type StreamAuthorizer func(caller *StreamCaller, notification *resource.ChangeNotificationResource) bool
+
+// Interfaces
+
+type StreamService interface {
+	Subscribe(ctx *context.RequestContext, streamSubscribeResource *resource.StreamSubscribeResource) (*stream.Subscription, []*resource.ChangeNotificationResource, *apperror.AppError)
+	Unsubscribe(subscription *stream.Subscription)
+	Publish(envelope *events.Envelope, event events.Event) error
+	SetAuthorizer(authorizer StreamAuthorizer)
+	Close()
+}
+
+// Structs
+
+// StreamConfig Config section of the stream module. Permissions holds the entity types each actor (identified as on
+// the audit log, see config.AuthConfig) can receive changes of. The "*" entry applies to the actors without their own.
+// If it's empty, every actor receives every change.
+type StreamConfig struct {
+	HeartbeatInterval time.Duration       `yaml:"heartbeat_interval" default:"15s" validate:"gt=0"`
+	ReplayBufferSize  int                 `yaml:"replay_buffer_size" default:"1000" validate:"min=0"`
+	Permissions       map[string][]string `yaml:"permissions" validate:"dive,keys,required,endkeys,dive,oneof=user user_type"`
+}
+
+// StreamCaller Identity of the client of a stream, captured when it subscribes.
+type StreamCaller struct {
+	TenantID int64
+	Actor    string
+}
+
+// streamService
+
+type streamService struct {
+	appConfig  config.AppConfig
+	logger     *zerolog.Logger
+	validator  *validator2.Validate
+	broker     *stream.Broker
+	authorizer StreamAuthorizer
+}
+
+func (s *streamService) Subscribe(
+	ctx *context.RequestContext,
+	streamSubscribeResource *resource.StreamSubscribeResource,
+) (*stream.Subscription, []*resource.ChangeNotificationResource, *apperror.AppError) {
+	if err := s.validator.StructCtx(ctx, streamSubscribeResource); err != nil {
+		return nil, nil, apperror.NewValidationAppError(ctx, err, StreamServiceSourceName)
+	}
+
+	entityTypes := make(map[string]bool)
+
+	for _, entityType := range streamSubscribeResource.EntityTypes {
+		entityTypes[entityType] = true
+	}
+
+	// The request context is bound to the request, so the filter (which runs on the publisher goroutine) gets a copy
+	// of the caller instead. The broker shares the replay buffer between tenants, so the filter must also reject the
+	// notifications of other tenants, replayed or not, whatever the authorizer says
+
+	caller := &StreamCaller{
+		TenantID: ctx.GetTenantID(),
+		Actor:    ctx.GetActor(),
+	}
+	authorizer := s.authorizer
+	filter := func(notification *resource.ChangeNotificationResource) bool {
+		if notification.TenantID != caller.TenantID {
+			return false
+		}
+
+		if len(entityTypes) > 0 && !entityTypes[notification.EntityType] {
+			return false
+		}
+
+		return authorizer(caller, notification)
+	}
+
+	subscription, replay, err := s.broker.Subscribe(filter, streamSubscribeResource.LastEventID)
+
+	if err != nil {
+		return nil, nil, apperror.NewAppError(ctx, err, StreamServiceSourceName, apperror.InternalErrorCode, apperror.InternalErrorMessage, nil)
+	}
+
+	return subscription, replay, nil
+}
+
+func (s *streamService) Unsubscribe(subscription *stream.Subscription) {
+	s.broker.Unsubscribe(subscription)
+}
+
+// Publish Turns an event into a change notification. It's subscribed to every event on the bus, so only committed
+// changes are streamed.
+func (s *streamService) Publish(envelope *events.Envelope, event events.Event) error {
+	entityType, action := envelope.Name, ""
+
+	if i := strings.LastIndex(envelope.Name, "."); i > -1 {
+		entityType, action = envelope.Name[:i], envelope.Name[i+1:]
+	}
+
+	s.broker.Publish(&resource.ChangeNotificationResource{
+		ID:         envelope.ID,
+		TenantID:   envelope.TenantID,
+		Event:      envelope.Name,
+		EntityType: entityType,
+		EntityKey:  getEventEntityKey(event),
+		Action:     action,
+		Data:       envelope.Payload,
+		Actor:      envelope.Actor,
+		RequestID:  envelope.RequestID,
+		OccurredAt: envelope.OccurredAt,
+	})
+
+	return nil
+}
+
+func (s *streamService) SetAuthorizer(authorizer StreamAuthorizer) {
+	s.authorizer = authorizer
+}
+
+// Close Closes every open stream. It must be called when the server starts shutting down, as streams never end
+// on their own.
+func (s *streamService) Close() {
+	s.logger.Debug().Msg("[StreamService] Closing change streams.")
+
+	s.broker.Close()
+}
+
+// Static functions
+
+func getEventEntityKey(event events.Event) string {
+	switch e := event.(type) {
+	case *events.UserCreated:
+		return e.User.Username
+	case *events.UserUpdated:
+		return e.User.Username
+	case *events.UserDisabled:
+		return e.User.Username
+	case *events.UserDeleted:
+		return e.User.Username
+	case *events.UserTypeCreated:
+		return e.UserType.Name
+	case *events.UserTypeUpdated:
+		return e.UserType.Name
+	case *events.UserTypeDisabled:
+		return e.UserType.Name
+	case *events.UserTypeDeleted:
+		return e.UserType.Name
+	default:
+		return ""
+	}
+}
+
+// NewPermissionsStreamAuthorizer Returns the default authorizer, which lets callers see the changes of the entity
+// types they have permission for (see StreamConfig).
+func NewPermissionsStreamAuthorizer(permissions map[string][]string) StreamAuthorizer {
+	allowed := make(map[string]map[string]bool, len(permissions))
+
+	for actor, entityTypes := range permissions {
+		allowed[actor] = make(map[string]bool, len(entityTypes))
+
+		for _, entityType := range entityTypes {
+			allowed[actor][entityType] = true
+		}
+	}
+
+	return func(caller *StreamCaller, notification *resource.ChangeNotificationResource) bool {
+		if len(allowed) == 0 {
+			return true
+		}
+
+		entityTypes, found := allowed[caller.Actor]
+
+		if !found {
+			entityTypes = allowed[StreamAnyActor]
+		}
+
+		return entityTypes[notification.EntityType]
+	}
+}
+
+func NewStreamService(appConfig config.AppConfig, streamConfig StreamConfig, logger *zerolog.Logger, validator *validator2.Validate) StreamService {
+	return &streamService{
+		appConfig:  appConfig,
+		logger:     logger,
+		validator:  validator,
+		broker:     stream.NewBroker(streamConfig.ReplayBufferSize, 0),
+		authorizer: NewPermissionsStreamAuthorizer(streamConfig.Permissions),
+	}
+}
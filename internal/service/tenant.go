@@ -0,0 +1,204 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/repository"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/comfortablynumb/goginrestapi/internal/token"
+	"github.com/rs/zerolog"
+	validator2 "gopkg.in/go-playground/validator.v9"
+)
+
+// Constants
+
+const (
+	TenantServiceSourceName = "TenantService"
+
+	TenantTokenPurpose = "tenant"
+)
+
+// Interfaces
+
+type TenantService interface {
+	Find(ctx *context.RequestContext) ([]*resource.TenantResource, *apperror.AppError)
+	FindOneByName(ctx *context.RequestContext, name string) (*resource.TenantResource, *apperror.AppError)
+	Create(ctx *context.RequestContext, tenantCreateResource *resource.TenantCreateResource) (*resource.TenantResource, *apperror.AppError)
+	Update(ctx *context.RequestContext, tenantUpdateResource *resource.TenantUpdateResource) (*resource.TenantResource, *apperror.AppError)
+	Resolve(ctx *context.RequestContext, name string) (*model.Tenant, *apperror.AppError)
+	IssueToken(ctx *context.RequestContext, name string, ttl time.Duration) (string, *apperror.AppError)
+	VerifyToken(ctx *context.RequestContext, tenantToken string) (string, *apperror.AppError)
+}
+
+// Structs
+
+type tenantService struct {
+	appConfig        config.AppConfig
+	logger           *zerolog.Logger
+	validator        *validator2.Validate
+	timeService      TimeService
+	tokenSigner      *token.Signer
+	tenantRepository repository.TenantRepository
+}
+
+func (s *tenantService) Find(ctx *context.RequestContext) ([]*resource.TenantResource, *apperror.AppError) {
+	tenants, err := s.tenantRepository.Find(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*resource.TenantResource, 0, len(tenants))
+
+	for _, tenant := range tenants {
+		res = append(res, resource.FromTenant(*tenant))
+	}
+
+	return res, nil
+}
+
+func (s *tenantService) FindOneByName(ctx *context.RequestContext, name string) (*resource.TenantResource, *apperror.AppError) {
+	if err := s.validator.VarCtx(ctx, name, "required"); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, TenantServiceSourceName)
+	}
+
+	tenant, err := s.tenantRepository.FindOneByName(ctx, name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tenant == nil {
+		return nil, apperror.NewModelNotFoundAppError(ctx, fmt.Errorf("tenant '%s' was not found", name), TenantServiceSourceName)
+	}
+
+	return resource.FromTenant(*tenant), nil
+}
+
+func (s *tenantService) Create(ctx *context.RequestContext, tenantCreateResource *resource.TenantCreateResource) (*resource.TenantResource, *apperror.AppError) {
+	if err := s.validator.StructCtx(ctx, tenantCreateResource); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, TenantServiceSourceName)
+	}
+
+	existing, err := s.tenantRepository.FindOneByName(ctx, tenantCreateResource.Name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		return nil, apperror.NewValidationAppError(ctx, fmt.Errorf("tenant '%s' already exists", existing.Name), TenantServiceSourceName)
+	}
+
+	tenant := model.NewTenantBuilder().
+		WithName(tenantCreateResource.Name).
+		WithDisabled(tenantCreateResource.Disabled).
+		WithCreatedAt(s.timeService.GetCurrentUtcTime()).
+		WithUpdatedAt(s.timeService.GetCurrentUtcTime()).
+		Build()
+
+	if err := s.tenantRepository.Create(ctx, tenant); err != nil {
+		return nil, err
+	}
+
+	return resource.FromTenant(*tenant), nil
+}
+
+// Update Only the disabled flag of a tenant can be changed, as its name may already be used as subdomain and in tenant
+// tokens.
+func (s *tenantService) Update(ctx *context.RequestContext, tenantUpdateResource *resource.TenantUpdateResource) (*resource.TenantResource, *apperror.AppError) {
+	if err := s.validator.StructCtx(ctx, tenantUpdateResource); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, TenantServiceSourceName)
+	}
+
+	tenant, err := s.tenantRepository.FindOneByName(ctx, tenantUpdateResource.Name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tenant == nil {
+		return nil, apperror.NewModelNotFoundAppError(ctx, fmt.Errorf("tenant '%s' was not found", tenantUpdateResource.Name), TenantServiceSourceName)
+	}
+
+	tenant.Disabled = tenantUpdateResource.Disabled
+	tenant.UpdatedAt = s.timeService.GetCurrentUtcTime()
+
+	if err := s.tenantRepository.Update(ctx, tenant); err != nil {
+		return nil, err
+	}
+
+	return resource.FromTenant(*tenant), nil
+}
+
+// Resolve Returns the tenant with the given name, or an invalid tenant error if it doesn't exist or is disabled.
+func (s *tenantService) Resolve(ctx *context.RequestContext, name string) (*model.Tenant, *apperror.AppError) {
+	tenant, err := s.tenantRepository.FindOneByName(ctx, name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tenant == nil {
+		return nil, apperror.NewInvalidTenantAppError(ctx, fmt.Errorf("tenant '%s' doesn't exist", name), TenantServiceSourceName)
+	}
+
+	if tenant.Disabled {
+		return nil, apperror.NewInvalidTenantAppError(ctx, fmt.Errorf("tenant '%s' is disabled", name), TenantServiceSourceName)
+	}
+
+	return tenant, nil
+}
+
+// IssueToken Returns a token which resolves the given tenant, valid for ttl. Tokens are signed with the auth token
+// secret, so they are only valid on other instances (or after a restart) if it's configured.
+func (s *tenantService) IssueToken(ctx *context.RequestContext, name string, ttl time.Duration) (string, *apperror.AppError) {
+	if err := s.validator.VarCtx(ctx, ttl, "gt=0"); err != nil {
+		return "", apperror.NewValidationAppError(ctx, err, TenantServiceSourceName)
+	}
+
+	tenant, err := s.Resolve(ctx, name)
+
+	if err != nil {
+		return "", err
+	}
+
+	return s.tokenSigner.SignClaim(TenantTokenPurpose, tenant.Name, s.timeService.GetCurrentUtcTime().Add(ttl)), nil
+}
+
+// VerifyToken Returns the name of the tenant of a token returned by IssueToken, or an invalid tenant error if the token
+// is not valid or has expired.
+func (s *tenantService) VerifyToken(ctx *context.RequestContext, tenantToken string) (string, *apperror.AppError) {
+	name, err := s.tokenSigner.VerifyClaim(tenantToken, TenantTokenPurpose, s.timeService.GetCurrentUtcTime())
+
+	if err != nil {
+		return "", apperror.NewInvalidTenantAppError(ctx, err, TenantServiceSourceName)
+	}
+
+	return name, nil
+}
+
+// Static functions
+
+func NewTenantService(
+	appConfig config.AppConfig,
+	logger *zerolog.Logger,
+	validator *validator2.Validate,
+	timeService TimeService,
+	tokenSigner *token.Signer,
+	tenantRepository repository.TenantRepository,
+) TenantService {
+	return &tenantService{
+		appConfig:        appConfig,
+		logger:           logger,
+		validator:        validator,
+		timeService:      timeService,
+		tokenSigner:      tokenSigner,
+		tenantRepository: tenantRepository,
+	}
+}
@@ -1,11 +1,36 @@
 package service
 
-import "time"
+import (
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/repository/utils"
+)
 
 // Interfaces
 
+// TimeService Source of the current time and of the timers used by background processes, so tests can control the
+// clock.
 type TimeService interface {
+	// GetCurrentUtcTime Returns the current time in UTC, with the precision times are stored with.
 	GetCurrentUtcTime() time.Time
+	// NewTimer Returns a timer which sends the current time on its channel once the duration elapses.
+	NewTimer(duration time.Duration) Timer
+	// NewTicker Returns a ticker which sends the current time on its channel every interval, until it's stopped.
+	NewTicker(interval time.Duration) Ticker
+}
+
+// Timer Like time.Timer, but its channel is returned by a method, so it can be implemented by fake clocks.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(duration time.Duration) bool
+}
+
+// Ticker Like time.Ticker, but its channel is returned by a method, so it can be implemented by fake clocks.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+	Reset(interval time.Duration)
 }
 
 // Structs
@@ -14,7 +39,47 @@ type timeService struct {
 }
 
 func (s *timeService) GetCurrentUtcTime() time.Time {
-	return time.Now().UTC()
+	return utils.NormalizeTime(time.Now())
+}
+
+func (s *timeService) NewTimer(duration time.Duration) Timer {
+	return &timer{timer: time.NewTimer(duration)}
+}
+
+func (s *timeService) NewTicker(interval time.Duration) Ticker {
+	return &ticker{ticker: time.NewTicker(interval)}
+}
+
+type timer struct {
+	timer *time.Timer
+}
+
+func (t *timer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t *timer) Stop() bool {
+	return t.timer.Stop()
+}
+
+func (t *timer) Reset(duration time.Duration) bool {
+	return t.timer.Reset(duration)
+}
+
+type ticker struct {
+	ticker *time.Ticker
+}
+
+func (t *ticker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+func (t *ticker) Stop() {
+	t.ticker.Stop()
+}
+
+func (t *ticker) Reset(interval time.Duration) {
+	t.ticker.Reset(interval)
 }
 
 // Static functions
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"database/sql"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/rs/zerolog"
+)
+
+// Constants
+
+const (
+	TransactionServiceSourceName = "TransactionService"
+)
+
+// Interfaces
+
+type TransactionService interface {
+	RunInTransaction(ctx *context.RequestContext, fn func() *apperror.AppError) *apperror.AppError
+	AfterCommit(ctx *context.RequestContext, fn func())
+}
+
+// Structs
+
+type transactionService struct {
+	db     *sql.DB
+	logger *zerolog.Logger
+}
+
+// RunInTransaction Executes fn inside a DB transaction stored on the request context. If the context already has a
+// transaction, fn simply joins it and the outermost call is the one which commits or rolls back.
+func (s *transactionService) RunInTransaction(ctx *context.RequestContext, fn func() *apperror.AppError) *apperror.AppError {
+	if ctx.HasTx() {
+		return fn()
+	}
+
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		return apperror.NewDbAppError(ctx, err, TransactionServiceSourceName)
+	}
+
+	ctx.SetTx(tx)
+
+	committed := false
+
+	defer func() {
+		ctx.SetTx(nil)
+
+		afterCommit := ctx.TakeAfterTx()
+
+		if r := recover(); r != nil {
+			s.rollback(tx)
+
+			panic(r)
+		}
+
+		if committed {
+			for _, fn := range afterCommit {
+				fn()
+			}
+		}
+	}()
+
+	appErr := fn()
+
+	if appErr != nil {
+		s.rollback(tx)
+
+		return appErr
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperror.NewDbAppError(ctx, err, TransactionServiceSourceName)
+	}
+
+	committed = true
+
+	return nil
+}
+
+// AfterCommit Calls fn once the transaction of the context is committed, or right away if there's no transaction. It's
+// never called if the transaction is rolled back.
+func (s *transactionService) AfterCommit(ctx *context.RequestContext, fn func()) {
+	if !ctx.HasTx() {
+		fn()
+
+		return
+	}
+
+	ctx.AddAfterTx(fn)
+}
+
+func (s *transactionService) rollback(tx *sql.Tx) {
+	if err := tx.Rollback(); err != nil {
+		s.logger.Error().Msgf("[TransactionService] Could NOT rollback transaction: %s", err)
+	}
+}
+
+// Static functions
+
+func NewTransactionService(db *sql.DB, logger *zerolog.Logger) TransactionService {
+	return &transactionService{
+		db:     db,
+		logger: logger,
+	}
+}
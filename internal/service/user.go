@@ -1,13 +1,27 @@
 package service
 
 import (
+	context2 "context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	// Timezones of the users are validated and used even if the system has no timezone database
+	_ "time/tzdata"
+
 	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/cache"
 	"github.com/comfortablynumb/goginrestapi/internal/config"
 	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/i18n"
+	"github.com/comfortablynumb/goginrestapi/internal/mailer"
 	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/password"
 	repository2 "github.com/comfortablynumb/goginrestapi/internal/repository"
 	"github.com/comfortablynumb/goginrestapi/internal/repository/utils"
 	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/comfortablynumb/goginrestapi/internal/token"
 	"github.com/docker/docker/registry"
 	"github.com/rs/zerolog"
 	validator2 "gopkg.in/go-playground/validator.v9"
@@ -17,6 +31,31 @@ import (
 
 const (
 	UserServiceSourceName = "UserService"
+
+	DefaultUserTimezone = "UTC"
+	EmailTimeFormat     = "2006-01-02 15:04 MST"
+
+	VerifyEmailTokenPurpose = "verify_email"
+
+	UserVerificationEmailSubjectKey = "user.verification_email.subject"
+	UserVerificationEmailBodyKey    = "user.verification_email.body"
+)
+
+// Variables
+
+var (
+	// UserMessages Messages of the emails sent to the users, by locale. Bodies receive the name of the user as {0}, the
+	// token as {1} and its expiration time, on the timezone of the user, as {2}.
+	UserMessages = map[string]map[string]string{
+		i18n.EnglishLocale: {
+			UserVerificationEmailSubjectKey: "Verify your email",
+			UserVerificationEmailBodyKey:    "Hi {0},\n\nUse the following token to verify your email: {1}\n\nIt expires on {2}.",
+		},
+		i18n.SpanishLocale: {
+			UserVerificationEmailSubjectKey: "Verifique su email",
+			UserVerificationEmailBodyKey:    "Hola {0},\n\nUse el siguiente token para verificar su email: {1}\n\nExpira el {2}.",
+		},
+	}
 )
 
 // Interfaces
@@ -26,17 +65,32 @@ type UserService interface {
 	Create(ctx *context.RequestContext, userCreateResource *resource.UserCreateResource) (*resource.UserResource, *apperror.AppError)
 	Update(ctx *context.RequestContext, userUpdateResource *resource.UserUpdateResource) (*resource.UserResource, *apperror.AppError)
 	Delete(ctx *context.RequestContext, userDeleteResource *resource.UserDeleteResource) (*resource.UserResource, *apperror.AppError)
+	History(ctx *context.RequestContext, username string) (*resource.AuditEventResourceList, *apperror.AppError)
+	VerifyEmail(ctx *context.RequestContext, userVerifyEmailResource *resource.UserVerifyEmailResource) (*resource.UserResource, *apperror.AppError)
+	SendVerificationEmail(ctx *context.RequestContext, userVerificationEmailResource *resource.UserVerificationEmailResource) (*resource.UserResource, *apperror.AppError)
+	Unlock(ctx *context.RequestContext, userUnlockResource *resource.UserUnlockResource) (*resource.UserResource, *apperror.AppError)
+	DisableMfa(ctx *context.RequestContext, userDisableMfaResource *resource.UserDisableMfaResource) (*resource.UserResource, *apperror.AppError)
+	ValidateUserUnique(ctx context2.Context, sl validator2.StructLevel)
+	ValidateLocale(fl validator2.FieldLevel) bool
+	ValidateTimezone(fl validator2.FieldLevel) bool
 }
 
 // Structs
 
 type userService struct {
-	appConfig       config.AppConfig
-	logger          *zerolog.Logger
-	validator       *validator2.Validate
-	timeService     TimeService
-	userRepository  repository2.UserRepository
-	userTypeService UserTypeService
+	appConfig          config.AppConfig
+	logger             *zerolog.Logger
+	validator          *validator2.Validate
+	timeService        TimeService
+	transactionService TransactionService
+	auditService       AuditService
+	eventService       EventService
+	cacheService       CacheService
+	userRepository     repository2.UserRepository
+	userTypeService    UserTypeService
+	translator         *i18n.Translator
+	mailer             mailer.Mailer
+	tokenSigner        *token.Signer
 }
 
 func (s *userService) Find(ctx *context.RequestContext, userFindResource *resource.UserFindResource) ([]*resource.UserResource, *apperror.AppError) {
@@ -67,7 +121,11 @@ func (s *userService) Find(ctx *context.RequestContext, userFindResource *resour
 
 	result := make([]*resource.UserResource, 0)
 
+	now := s.timeService.GetCurrentUtcTime()
+
 	for _, row := range rows {
+		row.ClearExpiredLockout(now)
+
 		result = append(result, resource.FromUser(*row))
 	}
 
@@ -85,11 +143,35 @@ func (s *userService) Create(ctx *context.RequestContext, userCreateResource *re
 		WithUsername(userCreateResource.Username).
 		WithUserType(*userType).
 		WithDisabled(userCreateResource.Disabled).
+		WithEmail(normalizeEmail(userCreateResource.Email)).
+		WithDisplayName(userCreateResource.DisplayName).
+		WithLocale(s.getLocale(userCreateResource.Locale)).
+		WithTimezone(s.getTimezone(userCreateResource.Timezone)).
 		WithCreatedAt(s.timeService.GetCurrentUtcTime()).
 		WithUpdatedAt(s.timeService.GetCurrentUtcTime()).
 		Build()
 
-	err := s.userRepository.Create(ctx, user)
+	if userCreateResource.Password != "" {
+		if err := s.setPassword(ctx, user, userCreateResource.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.transactionService.RunInTransaction(ctx, func() *apperror.AppError {
+		s.cacheService.Invalidate(ctx, cache.UserTag)
+
+		if err := s.userRepository.Create(ctx, user); err != nil {
+			return err
+		}
+
+		if err := s.auditService.Record(ctx, model.AuditEntityTypeUser, user.ID, user.Username, model.AuditActionCreate, nil, resource.FromUser(*user)); err != nil {
+			return err
+		}
+
+		s.sendVerificationEmailAfterCommit(ctx, user)
+
+		return s.eventService.Publish(ctx, &events.UserCreated{User: resource.FromUser(*user)})
+	})
 
 	if err != nil {
 		return nil, err
@@ -113,14 +195,61 @@ func (s *userService) Update(ctx *context.RequestContext, userUpdateResource *re
 		return nil, apperror.NewModelNotFoundAppError(ctx, err, UserServiceSourceName)
 	}
 
+	user.ClearExpiredLockout(s.timeService.GetCurrentUtcTime())
+
+	before := resource.FromUser(*user)
 	userType := ctx.Get("user_type").(*model.UserType)
 
 	user.Username = userUpdateResource.Username
 	user.UserType = *userType
 	user.Disabled = userUpdateResource.Disabled
+	user.DisplayName = userUpdateResource.DisplayName
+	user.Locale = s.getLocale(userUpdateResource.Locale)
+	user.Timezone = s.getTimezone(userUpdateResource.Timezone)
 	user.UpdatedAt = s.timeService.GetCurrentUtcTime()
 
-	err = s.userRepository.Update(ctx, user)
+	// A new email must be verified again
+
+	emailChanged := normalizeEmail(userUpdateResource.Email) != user.Email
+
+	if emailChanged {
+		user.Email = normalizeEmail(userUpdateResource.Email)
+		user.EmailVerifiedAt = nil
+	}
+
+	if userUpdateResource.Password != "" {
+		if err := s.setPassword(ctx, user, userUpdateResource.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.transactionService.RunInTransaction(ctx, func() *apperror.AppError {
+		s.cacheService.Invalidate(ctx, cache.UserTag)
+
+		if err := s.userRepository.Update(ctx, user); err != nil {
+			return err
+		}
+
+		if emailChanged {
+			s.sendVerificationEmailAfterCommit(ctx, user)
+		}
+
+		after := resource.FromUser(*user)
+
+		if err := s.auditService.Record(ctx, model.AuditEntityTypeUser, user.ID, user.Username, model.AuditActionUpdate, before, after); err != nil {
+			return err
+		}
+
+		if err := s.eventService.Publish(ctx, &events.UserUpdated{Before: before, User: after}); err != nil {
+			return err
+		}
+
+		if !before.Disabled && after.Disabled {
+			return s.eventService.Publish(ctx, &events.UserDisabled{User: after})
+		}
+
+		return nil
+	})
 
 	if err != nil {
 		return nil, err
@@ -144,7 +273,98 @@ func (s *userService) Delete(ctx *context.RequestContext, userDeleteResource *re
 		return nil, nil
 	}
 
-	err = s.userRepository.Delete(ctx, user)
+	user.ClearExpiredLockout(s.timeService.GetCurrentUtcTime())
+
+	err = s.transactionService.RunInTransaction(ctx, func() *apperror.AppError {
+		s.cacheService.Invalidate(ctx, cache.UserTag)
+
+		if err := s.userRepository.Delete(ctx, user); err != nil {
+			return err
+		}
+
+		if err := s.auditService.Record(ctx, model.AuditEntityTypeUser, user.ID, user.Username, model.AuditActionDelete, resource.FromUser(*user), nil); err != nil {
+			return err
+		}
+
+		return s.eventService.Publish(ctx, &events.UserDeleted{User: resource.FromUser(*user)})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource.FromUser(*user), nil
+}
+
+func (s *userService) History(ctx *context.RequestContext, username string) (*resource.AuditEventResourceList, *apperror.AppError) {
+	if err := s.validator.VarCtx(ctx, username, "required"); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, UserServiceSourceName)
+	}
+
+	user, err := s.userRepository.FindOneByUsername(ctx, username)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Deleted users are found by their username
+
+	userID := int64(0)
+
+	if user != nil {
+		userID = user.ID
+	}
+
+	return s.auditService.History(ctx, model.AuditEntityTypeUser, userID, username)
+}
+
+// VerifyEmail Marks the email of the user as verified, if the token was issued for it and it has not expired.
+// Verifying an already verified email does nothing.
+func (s *userService) VerifyEmail(ctx *context.RequestContext, userVerifyEmailResource *resource.UserVerifyEmailResource) (*resource.UserResource, *apperror.AppError) {
+	if err := s.validator.StructCtx(ctx, userVerifyEmailResource); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, UserServiceSourceName)
+	}
+
+	user, err := s.findOneByUsername(ctx, userVerifyEmailResource.Username)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Email == "" {
+		return nil, apperror.NewInvalidTokenAppError(ctx, errors.New("the user has no email to verify"), UserServiceSourceName)
+	}
+
+	now := s.timeService.GetCurrentUtcTime()
+
+	if err := s.tokenSigner.Verify(userVerifyEmailResource.Token, VerifyEmailTokenPurpose, getVerifyEmailTokenSubject(user), now); err != nil {
+		return nil, apperror.NewInvalidTokenAppError(ctx, err, UserServiceSourceName)
+	}
+
+	if user.IsEmailVerified() {
+		return resource.FromUser(*user), nil
+	}
+
+	before := resource.FromUser(*user)
+
+	user.EmailVerifiedAt = &now
+	user.UpdatedAt = now
+
+	err = s.transactionService.RunInTransaction(ctx, func() *apperror.AppError {
+		s.cacheService.Invalidate(ctx, cache.UserTag)
+
+		if err := s.userRepository.Update(ctx, user); err != nil {
+			return err
+		}
+
+		after := resource.FromUser(*user)
+
+		if err := s.auditService.Record(ctx, model.AuditEntityTypeUser, user.ID, user.Username, model.AuditActionUpdate, before, after); err != nil {
+			return err
+		}
+
+		return s.eventService.Publish(ctx, &events.UserEmailVerified{User: after})
+	})
 
 	if err != nil {
 		return nil, err
@@ -153,22 +373,326 @@ func (s *userService) Delete(ctx *context.RequestContext, userDeleteResource *re
 	return resource.FromUser(*user), nil
 }
 
+// SendVerificationEmail Sends a new verification token to the email of the user, unless it has no email or it's
+// already verified.
+func (s *userService) SendVerificationEmail(ctx *context.RequestContext, userVerificationEmailResource *resource.UserVerificationEmailResource) (*resource.UserResource, *apperror.AppError) {
+	if err := s.validator.StructCtx(ctx, userVerificationEmailResource); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, UserServiceSourceName)
+	}
+
+	user, err := s.findOneByUsername(ctx, userVerificationEmailResource.Username)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Email != "" && !user.IsEmailVerified() {
+		if err := s.sendVerificationEmail(ctx, user); err != nil {
+			return nil, apperror.NewAppError(ctx, err, UserServiceSourceName, apperror.InternalErrorCode, apperror.InternalErrorMessage, nil)
+		}
+	}
+
+	return resource.FromUser(*user), nil
+}
+
+// Unlock Ends the lockout of the user, and resets its count of failed logins.
+func (s *userService) Unlock(ctx *context.RequestContext, userUnlockResource *resource.UserUnlockResource) (*resource.UserResource, *apperror.AppError) {
+	if err := s.validator.StructCtx(ctx, userUnlockResource); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, UserServiceSourceName)
+	}
+
+	user, err := s.findOneByUsername(ctx, userUnlockResource.Username)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.LockedUntil == nil && user.FailedLoginAttempts == 0 {
+		return resource.FromUser(*user), nil
+	}
+
+	before := resource.FromUser(*user)
+
+	user.LockedUntil = nil
+	user.FailedLoginAttempts = 0
+	user.UpdatedAt = s.timeService.GetCurrentUtcTime()
+
+	err = s.transactionService.RunInTransaction(ctx, func() *apperror.AppError {
+		s.cacheService.Invalidate(ctx, cache.UserTag)
+
+		if err := s.userRepository.Update(ctx, user); err != nil {
+			return err
+		}
+
+		return s.auditService.Record(ctx, model.AuditEntityTypeUser, user.ID, user.Username, model.AuditActionUpdate, before, resource.FromUser(*user))
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource.FromUser(*user), nil
+}
+
+// DisableMfa Disables the second factor of the user, for instance if they lost it along with their recovery codes. Its
+// recovery codes can't be used anymore, and are replaced when a new second factor is enabled. If the type of the user
+// requires a second factor, the user must enroll a new one before logging in again.
+func (s *userService) DisableMfa(ctx *context.RequestContext, userDisableMfaResource *resource.UserDisableMfaResource) (*resource.UserResource, *apperror.AppError) {
+	if err := s.validator.StructCtx(ctx, userDisableMfaResource); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, UserServiceSourceName)
+	}
+
+	user, err := s.findOneByUsername(ctx, userDisableMfaResource.Username)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TotpSecret == "" && !user.IsMfaEnabled() {
+		return resource.FromUser(*user), nil
+	}
+
+	before := resource.FromUser(*user)
+
+	user.TotpSecret = ""
+	user.TotpEnabledAt = nil
+	user.TotpLastUsedStep = 0
+	user.UpdatedAt = s.timeService.GetCurrentUtcTime()
+
+	err = s.transactionService.RunInTransaction(ctx, func() *apperror.AppError {
+		s.cacheService.Invalidate(ctx, cache.UserTag)
+
+		if err := s.userRepository.Update(ctx, user); err != nil {
+			return err
+		}
+
+		after := resource.FromUser(*user)
+
+		if err := s.auditService.Record(ctx, model.AuditEntityTypeUser, user.ID, user.Username, model.AuditActionUpdate, before, after); err != nil {
+			return err
+		}
+
+		if !before.MfaEnabled {
+			return nil
+		}
+
+		return s.eventService.Publish(ctx, &events.UserMfaDisabled{User: after})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource.FromUser(*user), nil
+}
+
+// ValidateUserUnique Usernames and emails are unique per tenant, as only the users of the tenant of the context are
+// found.
+func (s *userService) ValidateUserUnique(ctx context2.Context, sl validator2.StructLevel) {
+	requestCtx := ctx.(*context.RequestContext)
+	user := sl.Current().Interface().(resource.UserUniqueValidator)
+	email := normalizeEmail(user.GetEmail())
+
+	// Usernames can't be changed, so they are only checked on creation
+
+	if _, creating := user.(resource.UserCreateResource); creating && len(user.GetUsername()) > 0 {
+		currentUser, err := s.userRepository.FindOneByUsername(requestCtx, user.GetUsername())
+
+		if err != nil {
+			s.logger.Err(err)
+
+			sl.ReportError(user.GetUsername(), "username", "Username", "unique", "")
+
+			return
+		}
+
+		if currentUser != nil {
+			sl.ReportError(user.GetUsername(), "username", "Username", "unique", "")
+
+			return
+		}
+	}
+
+	if len(email) > 0 {
+		currentUsers, err := s.userRepository.Find(
+			requestCtx,
+			utils.NewUserFindFilters().WithEmailValue(email),
+			utils.NewUserFindOptions().WithOffsetValue(0).WithLimitValue(1),
+		)
+
+		if err != nil {
+			s.logger.Err(err)
+
+			sl.ReportError(user.GetEmail(), "email", "Email", "unique", "")
+
+			return
+		}
+
+		if len(currentUsers) > 0 && currentUsers[0].Username != user.GetUsername() {
+			sl.ReportError(user.GetEmail(), "email", "Email", "unique", "")
+
+			return
+		}
+	}
+}
+
+// ValidateLocale Locales of the users must be supported by the translator, as their emails are written on them.
+func (s *userService) ValidateLocale(fl validator2.FieldLevel) bool {
+	for _, locale := range s.translator.GetLocales() {
+		if fl.Field().String() == locale {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateTimezone Timezones are IANA names (like "Europe/Madrid"). "Local" depends on the system the app runs on, so
+// it's not valid.
+func (s *userService) ValidateTimezone(fl validator2.FieldLevel) bool {
+	timezone := fl.Field().String()
+
+	if timezone == "" || timezone == "Local" {
+		return false
+	}
+
+	_, err := time.LoadLocation(timezone)
+
+	return err == nil
+}
+
+func (s *userService) findOneByUsername(ctx *context.RequestContext, username string) (*model.User, *apperror.AppError) {
+	user, err := s.userRepository.FindOneByUsername(ctx, username)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, apperror.NewModelNotFoundAppError(ctx, fmt.Errorf("user '%s' was not found", username), UserServiceSourceName)
+	}
+
+	user.ClearExpiredLockout(s.timeService.GetCurrentUtcTime())
+
+	return user, nil
+}
+
+// sendVerificationEmailAfterCommit Emails are only sent if the changes of the user are committed. As the changes
+// can't be undone by then, failures are just logged: the user can request a new email.
+func (s *userService) sendVerificationEmailAfterCommit(ctx *context.RequestContext, user *model.User) {
+	if user.Email == "" {
+		return
+	}
+
+	recipient := *user
+
+	s.transactionService.AfterCommit(ctx, func() {
+		if err := s.sendVerificationEmail(ctx, &recipient); err != nil {
+			s.logger.Error().Msgf("[UserService] Could NOT send the verification email to user '%s': %s", recipient.Username, err)
+		}
+	})
+}
+
+// sendVerificationEmail The email is written in the locale of the user, and its expiration time is shown on their
+// timezone.
+func (s *userService) sendVerificationEmail(ctx *context.RequestContext, user *model.User) error {
+	expiresAt := s.timeService.GetCurrentUtcTime().Add(s.appConfig.Auth.EmailVerificationTTL)
+	verificationToken := s.tokenSigner.Sign(VerifyEmailTokenPurpose, getVerifyEmailTokenSubject(user), expiresAt)
+
+	if location, err := time.LoadLocation(user.Timezone); err == nil {
+		expiresAt = expiresAt.In(location)
+	}
+
+	name := user.DisplayName
+
+	if name == "" {
+		name = user.Username
+	}
+
+	// Without messages for the locale, the email still carries the token
+
+	subject := s.translator.Translate(user.Locale, UserVerificationEmailSubjectKey, UserVerificationEmailSubjectKey)
+	body := s.translator.Translate(user.Locale, UserVerificationEmailBodyKey, verificationToken, name, verificationToken, expiresAt.Format(EmailTimeFormat))
+
+	return s.mailer.Send(ctx, &mailer.Message{
+		From:    s.appConfig.Mail.From,
+		To:      user.Email,
+		Subject: subject,
+		Body:    body,
+	})
+}
+
+func (s *userService) setPassword(ctx *context.RequestContext, user *model.User, plainPassword string) *apperror.AppError {
+	passwordHash, err := password.Hash(plainPassword)
+
+	if err != nil {
+		return apperror.NewAppError(ctx, err, UserServiceSourceName, apperror.InternalErrorCode, apperror.InternalErrorMessage, nil)
+	}
+
+	now := s.timeService.GetCurrentUtcTime()
+
+	user.PasswordHash = passwordHash
+	user.PasswordChangedAt = &now
+
+	return nil
+}
+
+func (s *userService) getLocale(locale string) string {
+	if locale == "" {
+		return s.appConfig.DefaultLocale
+	}
+
+	return locale
+}
+
+func (s *userService) getTimezone(timezone string) string {
+	if timezone == "" {
+		return DefaultUserTimezone
+	}
+
+	return timezone
+}
+
 // Static functions
 
+// getVerifyEmailTokenSubject Tokens are issued for a user and an email, so they are no longer valid once the email
+// changes.
+func getVerifyEmailTokenSubject(user *model.User) string {
+	return fmt.Sprintf("%d:%s", user.ID, user.Email)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 func NewUserService(
 	appConfig config.AppConfig,
 	logger *zerolog.Logger,
 	validator *validator2.Validate,
 	timeService TimeService,
+	transactionService TransactionService,
+	auditService AuditService,
+	eventService EventService,
+	cacheService CacheService,
 	userRepository repository2.UserRepository,
 	userTypeService UserTypeService,
+	translator *i18n.Translator,
+	mailer mailer.Mailer,
+	tokenSigner *token.Signer,
 ) UserService {
 	return &userService{
-		appConfig:       appConfig,
-		logger:          logger,
-		validator:       validator,
-		timeService:     timeService,
-		userRepository:  userRepository,
-		userTypeService: userTypeService,
+		appConfig:          appConfig,
+		logger:             logger,
+		validator:          validator,
+		timeService:        timeService,
+		transactionService: transactionService,
+		auditService:       auditService,
+		eventService:       eventService,
+		cacheService:       cacheService,
+		userRepository:     userRepository,
+		userTypeService:    userTypeService,
+		translator:         translator,
+		mailer:             mailer,
+		tokenSigner:        tokenSigner,
 	}
 }
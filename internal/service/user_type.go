@@ -2,10 +2,12 @@ package service
 
 import (
 	context2 "context"
+	"github.com/comfortablynumb/goginrestapi/internal/cache"
 
 	"github.com/comfortablynumb/goginrestapi/internal/apperror"
 	"github.com/comfortablynumb/goginrestapi/internal/config"
 	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
 	"github.com/comfortablynumb/goginrestapi/internal/model"
 	"github.com/comfortablynumb/goginrestapi/internal/repository"
 	"github.com/comfortablynumb/goginrestapi/internal/repository/utils"
@@ -30,6 +32,7 @@ type UserTypeService interface {
 	Create(ctx *context.RequestContext, userCreateResource *resource.UserTypeCreateResource) (*resource.UserTypeResource, *apperror.AppError)
 	Update(ctx *context.RequestContext, userUpdateResource *resource.UserTypeUpdateResource) (*resource.UserTypeResource, *apperror.AppError)
 	Delete(ctx *context.RequestContext, userDeleteResource *resource.UserTypeDeleteResource) (*resource.UserTypeResource, *apperror.AppError)
+	History(ctx *context.RequestContext, name string) (*resource.AuditEventResourceList, *apperror.AppError)
 	ValidateUserTypeByName(ctx context2.Context, fl validator2.FieldLevel) bool
 	ValidateUserTypeUnique(ctx context2.Context, sl validator2.StructLevel)
 }
@@ -41,6 +44,10 @@ type userTypeService struct {
 	logger             *zerolog.Logger
 	validator          *validator2.Validate
 	timeService        TimeService
+	transactionService TransactionService
+	auditService       AuditService
+	eventService       EventService
+	cacheService       CacheService
 	userTypeRepository repository.UserTypeRepository
 }
 
@@ -122,11 +129,24 @@ func (s *userTypeService) Create(ctx *context.RequestContext, userCreateResource
 	userType := model.NewUserTypeBuilder().
 		WithName(userCreateResource.Name).
 		WithDisabled(userCreateResource.Disabled).
+		WithRequiresMfa(userCreateResource.RequiresMfa).
 		WithCreatedAt(s.timeService.GetCurrentUtcTime()).
 		WithUpdatedAt(s.timeService.GetCurrentUtcTime()).
 		Build()
 
-	err := s.userTypeRepository.Create(ctx, userType)
+	err := s.transactionService.RunInTransaction(ctx, func() *apperror.AppError {
+		s.cacheService.Invalidate(ctx, cache.UserTypeTag, cache.UserTag)
+
+		if err := s.userTypeRepository.Create(ctx, userType); err != nil {
+			return err
+		}
+
+		if err := s.auditService.Record(ctx, model.AuditEntityTypeUserType, userType.ID, userType.Name, model.AuditActionCreate, nil, resource.FromUserType(*userType)); err != nil {
+			return err
+		}
+
+		return s.eventService.Publish(ctx, &events.UserTypeCreated{UserType: resource.FromUserType(*userType)})
+	})
 
 	if err != nil {
 		return nil, err
@@ -152,11 +172,36 @@ func (s *userTypeService) Update(ctx *context.RequestContext, userUpdateResource
 		return nil, apperror.NewValidationAppError(ctx, err, UserTypeServiceSourceName)
 	}
 
+	before := resource.FromUserType(*userType)
+
 	userType.Name = userUpdateResource.Name
 	userType.Disabled = userUpdateResource.Disabled
+	userType.RequiresMfa = userUpdateResource.RequiresMfa
 	userType.UpdatedAt = s.timeService.GetCurrentUtcTime()
 
-	err = s.userTypeRepository.Update(ctx, userType)
+	err = s.transactionService.RunInTransaction(ctx, func() *apperror.AppError {
+		s.cacheService.Invalidate(ctx, cache.UserTypeTag, cache.UserTag)
+
+		if err := s.userTypeRepository.Update(ctx, userType); err != nil {
+			return err
+		}
+
+		after := resource.FromUserType(*userType)
+
+		if err := s.auditService.Record(ctx, model.AuditEntityTypeUserType, userType.ID, userType.Name, model.AuditActionUpdate, before, after); err != nil {
+			return err
+		}
+
+		if err := s.eventService.Publish(ctx, &events.UserTypeUpdated{Before: before, UserType: after}); err != nil {
+			return err
+		}
+
+		if !before.Disabled && after.Disabled {
+			return s.eventService.Publish(ctx, &events.UserTypeDisabled{UserType: after})
+		}
+
+		return nil
+	})
 
 	if err != nil {
 		return nil, err
@@ -180,7 +225,19 @@ func (s *userTypeService) Delete(ctx *context.RequestContext, userTypeDeleteReso
 		return nil, nil
 	}
 
-	err = s.userTypeRepository.Delete(ctx, userType)
+	err = s.transactionService.RunInTransaction(ctx, func() *apperror.AppError {
+		s.cacheService.Invalidate(ctx, cache.UserTypeTag, cache.UserTag)
+
+		if err := s.userTypeRepository.Delete(ctx, userType); err != nil {
+			return err
+		}
+
+		if err := s.auditService.Record(ctx, model.AuditEntityTypeUserType, userType.ID, userType.Name, model.AuditActionDelete, resource.FromUserType(*userType), nil); err != nil {
+			return err
+		}
+
+		return s.eventService.Publish(ctx, &events.UserTypeDeleted{UserType: resource.FromUserType(*userType)})
+	})
 
 	if err != nil {
 		return nil, err
@@ -189,6 +246,28 @@ func (s *userTypeService) Delete(ctx *context.RequestContext, userTypeDeleteReso
 	return resource.FromUserType(*userType), nil
 }
 
+func (s *userTypeService) History(ctx *context.RequestContext, name string) (*resource.AuditEventResourceList, *apperror.AppError) {
+	if err := s.validator.VarCtx(ctx, name, "required"); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, UserTypeServiceSourceName)
+	}
+
+	userType, err := s.userTypeRepository.FindOneByName(ctx, name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Deleted user types are found by their name
+
+	userTypeID := int64(0)
+
+	if userType != nil {
+		userTypeID = userType.ID
+	}
+
+	return s.auditService.History(ctx, model.AuditEntityTypeUserType, userTypeID, name)
+}
+
 func (s *userTypeService) ValidateUserTypeByName(ctx context2.Context, fl validator2.FieldLevel) bool {
 	requestCtx := ctx.(*context.RequestContext)
 	userTypeName := fl.Field().String()
@@ -219,13 +298,13 @@ func (s *userTypeService) ValidateUserTypeUnique(ctx context2.Context, sl valida
 		if err != nil {
 			s.logger.Err(err)
 
-			sl.ReportError(userType.GetName(), "Name", "Name", "unique", "")
+			sl.ReportError(userType.GetName(), "name", "Name", "unique", "")
 
 			return
 		}
 
 		if currentUserType != nil && currentUserType.ID != userType.GetID() {
-			sl.ReportError(userType.GetName(), "Name", "Name", "unique", "")
+			sl.ReportError(userType.GetName(), "name", "Name", "unique", "")
 
 			return
 		}
@@ -239,6 +318,10 @@ func NewUserTypeService(
 	logger *zerolog.Logger,
 	validator *validator2.Validate,
 	timeService TimeService,
+	transactionService TransactionService,
+	auditService AuditService,
+	eventService EventService,
+	cacheService CacheService,
 	userTypeRepository repository.UserTypeRepository,
 ) UserTypeService {
 	return &userTypeService{
@@ -246,6 +329,10 @@ func NewUserTypeService(
 		logger:             logger,
 		validator:          validator,
 		timeService:        timeService,
+		transactionService: transactionService,
+		auditService:       auditService,
+		eventService:       eventService,
+		cacheService:       cacheService,
 		userTypeRepository: userTypeRepository,
 	}
 }
@@ -0,0 +1,546 @@
+package service
+
+import (
+	"bytes"
+	context2 "context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/repository"
+	"github.com/comfortablynumb/goginrestapi/internal/repository/utils"
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+	"github.com/rs/zerolog"
+	validator2 "gopkg.in/go-playground/validator.v9"
+)
+
+// Constants
+
+const (
+	WebhookServiceSourceName = "WebhookService"
+
+	WebhookSignatureHeader   = "X-Webhook-Signature"
+	WebhookEventHeader       = "X-Webhook-Event"
+	WebhookDeliveryHeader    = "X-Webhook-Delivery"
+	WebhookSignaturePrefix   = "sha256="
+	WebhookDeliveryBatchSize = 100
+)
+
+// Interfaces
+
+type WebhookService interface {
+	Find(ctx *context.RequestContext, webhookFindResource *resource.WebhookFindResource) (*resource.WebhookResourceList, *apperror.AppError)
+	FindOneByID(ctx *context.RequestContext, ID int64) (*resource.WebhookResource, *apperror.AppError)
+	Create(ctx *context.RequestContext, webhookCreateResource *resource.WebhookCreateResource) (*resource.WebhookResource, *apperror.AppError)
+	Update(ctx *context.RequestContext, webhookUpdateResource *resource.WebhookUpdateResource) (*resource.WebhookResource, *apperror.AppError)
+	Delete(ctx *context.RequestContext, webhookDeleteResource *resource.WebhookDeleteResource) (*resource.WebhookResource, *apperror.AppError)
+	FindDeliveries(ctx *context.RequestContext, webhookDeliveryFindResource *resource.WebhookDeliveryFindResource) (*resource.WebhookDeliveryResourceList, *apperror.AppError)
+	Redeliver(ctx *context.RequestContext, webhookRedeliverResource *resource.WebhookRedeliverResource) (*resource.WebhookDeliveryResource, *apperror.AppError)
+	Enqueue(ctx *context.RequestContext, envelope *events.Envelope) *apperror.AppError
+	DeliverPending(ctx *context.RequestContext) (int, *apperror.AppError)
+	ValidateEventType(ctx context2.Context, fl validator2.FieldLevel) bool
+}
+
+// Structs
+
+// WebhookConfig Config section of the webhook module.
+type WebhookConfig struct {
+	DeliveryInterval time.Duration `yaml:"delivery_interval" default:"1s" validate:"gt=0"`
+	MaxAttempts      int           `yaml:"max_attempts" default:"8" validate:"min=1"`
+	Timeout          time.Duration `yaml:"timeout" default:"10s" validate:"gt=0"`
+	RetryBaseDelay   time.Duration `yaml:"retry_base_delay" default:"10s" validate:"gt=0"`
+	RetryMaxDelay    time.Duration `yaml:"retry_max_delay" default:"1h" validate:"gt=0"`
+}
+
+// webhookService
+
+type webhookService struct {
+	appConfig                     config.AppConfig
+	webhookConfig                 WebhookConfig
+	logger                        *zerolog.Logger
+	validator                     *validator2.Validate
+	timeService                   TimeService
+	transactionService            TransactionService
+	webhookSubscriptionRepository repository.WebhookSubscriptionRepository
+	webhookDeliveryRepository     repository.WebhookDeliveryRepository
+	httpClient                    *http.Client
+}
+
+func (s *webhookService) Find(ctx *context.RequestContext, webhookFindResource *resource.WebhookFindResource) (*resource.WebhookResourceList, *apperror.AppError) {
+	if err := s.validator.StructCtx(ctx, webhookFindResource); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, WebhookServiceSourceName)
+	}
+
+	filters := utils.NewWebhookSubscriptionFindFilters().WithDisabled(webhookFindResource.Disabled)
+	options := utils.NewWebhookSubscriptionFindOptions()
+
+	count, err := s.webhookSubscriptionRepository.Count(ctx, filters, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*resource.WebhookResource, 0)
+
+	if count < 1 {
+		return resource.NewWebhookResourceList(result, count), nil
+	}
+
+	if webhookFindResource.SortBy != nil && webhookFindResource.SortDir != nil {
+		options.WithSortBy(webhookFindResource.SortBy).
+			WithSortDir(webhookFindResource.SortDir)
+	}
+
+	if webhookFindResource.Offset != nil && webhookFindResource.Limit != nil {
+		options.WithOffset(webhookFindResource.Offset).
+			WithLimit(webhookFindResource.Limit)
+	} else {
+		options.WithOffsetValue(0).WithLimitValue(s.appConfig.DefaultLimit)
+	}
+
+	rows, err := s.webhookSubscriptionRepository.Find(ctx, filters, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result = append(result, resource.FromWebhookSubscription(*row))
+	}
+
+	return resource.NewWebhookResourceList(result, count), nil
+}
+
+func (s *webhookService) FindOneByID(ctx *context.RequestContext, ID int64) (*resource.WebhookResource, *apperror.AppError) {
+	webhookSubscription, err := s.webhookSubscriptionRepository.FindOneByID(ctx, ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if webhookSubscription == nil {
+		return nil, apperror.NewModelNotFoundAppError(ctx, nil, WebhookServiceSourceName)
+	}
+
+	return resource.FromWebhookSubscription(*webhookSubscription), nil
+}
+
+func (s *webhookService) Create(ctx *context.RequestContext, webhookCreateResource *resource.WebhookCreateResource) (*resource.WebhookResource, *apperror.AppError) {
+	if err := s.validator.StructCtx(ctx, webhookCreateResource); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, WebhookServiceSourceName)
+	}
+
+	webhookSubscription := model.NewWebhookSubscriptionBuilder().
+		WithURL(webhookCreateResource.URL).
+		WithEventTypes(webhookCreateResource.EventTypes).
+		WithSecret(webhookCreateResource.Secret).
+		WithDisabled(webhookCreateResource.Disabled).
+		WithCreatedAt(s.timeService.GetCurrentUtcTime()).
+		WithUpdatedAt(s.timeService.GetCurrentUtcTime()).
+		Build()
+
+	if err := s.webhookSubscriptionRepository.Create(ctx, webhookSubscription); err != nil {
+		return nil, err
+	}
+
+	return resource.FromWebhookSubscription(*webhookSubscription), nil
+}
+
+func (s *webhookService) Update(ctx *context.RequestContext, webhookUpdateResource *resource.WebhookUpdateResource) (*resource.WebhookResource, *apperror.AppError) {
+	if err := s.validator.StructCtx(ctx, webhookUpdateResource); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, WebhookServiceSourceName)
+	}
+
+	webhookSubscription, err := s.webhookSubscriptionRepository.FindOneByID(ctx, webhookUpdateResource.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if webhookSubscription == nil {
+		return nil, apperror.NewModelNotFoundAppError(ctx, nil, WebhookServiceSourceName)
+	}
+
+	webhookSubscription.URL = webhookUpdateResource.URL
+	webhookSubscription.EventTypes = webhookUpdateResource.EventTypes
+	webhookSubscription.Disabled = webhookUpdateResource.Disabled
+	webhookSubscription.UpdatedAt = s.timeService.GetCurrentUtcTime()
+
+	// The secret is only rotated if a new one is sent
+
+	if webhookUpdateResource.Secret != "" {
+		webhookSubscription.Secret = webhookUpdateResource.Secret
+	}
+
+	if err := s.webhookSubscriptionRepository.Update(ctx, webhookSubscription); err != nil {
+		return nil, err
+	}
+
+	return resource.FromWebhookSubscription(*webhookSubscription), nil
+}
+
+func (s *webhookService) Delete(ctx *context.RequestContext, webhookDeleteResource *resource.WebhookDeleteResource) (*resource.WebhookResource, *apperror.AppError) {
+	if err := s.validator.StructCtx(ctx, webhookDeleteResource); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, WebhookServiceSourceName)
+	}
+
+	webhookSubscription, err := s.webhookSubscriptionRepository.FindOneByID(ctx, webhookDeleteResource.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if webhookSubscription == nil {
+		return nil, nil
+	}
+
+	err = s.transactionService.RunInTransaction(ctx, func() *apperror.AppError {
+		return s.webhookSubscriptionRepository.Delete(ctx, webhookSubscription)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource.FromWebhookSubscription(*webhookSubscription), nil
+}
+
+func (s *webhookService) FindDeliveries(
+	ctx *context.RequestContext,
+	webhookDeliveryFindResource *resource.WebhookDeliveryFindResource,
+) (*resource.WebhookDeliveryResourceList, *apperror.AppError) {
+	if err := s.validator.StructCtx(ctx, webhookDeliveryFindResource); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, WebhookServiceSourceName)
+	}
+
+	if _, err := s.FindOneByID(ctx, webhookDeliveryFindResource.ID); err != nil {
+		return nil, err
+	}
+
+	filters := utils.NewWebhookDeliveryFindFilters().
+		WithSubscriptionIDValue(webhookDeliveryFindResource.ID).
+		WithStatus(webhookDeliveryFindResource.Status)
+	options := utils.NewWebhookDeliveryFindOptions()
+
+	count, err := s.webhookDeliveryRepository.Count(ctx, filters, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*resource.WebhookDeliveryResource, 0)
+
+	if count < 1 {
+		return resource.NewWebhookDeliveryResourceList(result, count), nil
+	}
+
+	if webhookDeliveryFindResource.SortBy != nil && webhookDeliveryFindResource.SortDir != nil {
+		options.WithSortBy(webhookDeliveryFindResource.SortBy).
+			WithSortDir(webhookDeliveryFindResource.SortDir)
+	}
+
+	if webhookDeliveryFindResource.Offset != nil && webhookDeliveryFindResource.Limit != nil {
+		options.WithOffset(webhookDeliveryFindResource.Offset).
+			WithLimit(webhookDeliveryFindResource.Limit)
+	} else {
+		options.WithOffsetValue(0).WithLimitValue(s.appConfig.DefaultLimit)
+	}
+
+	rows, err := s.webhookDeliveryRepository.Find(ctx, filters, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.webhookDeliveryRepository.LoadAttempts(ctx, rows); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result = append(result, resource.FromWebhookDelivery(*row))
+	}
+
+	return resource.NewWebhookDeliveryResourceList(result, count), nil
+}
+
+// Redeliver Attempts to deliver the event again right away, whatever the current status of the delivery is.
+func (s *webhookService) Redeliver(
+	ctx *context.RequestContext,
+	webhookRedeliverResource *resource.WebhookRedeliverResource,
+) (*resource.WebhookDeliveryResource, *apperror.AppError) {
+	if err := s.validator.StructCtx(ctx, webhookRedeliverResource); err != nil {
+		return nil, apperror.NewValidationAppError(ctx, err, WebhookServiceSourceName)
+	}
+
+	webhookSubscription, err := s.webhookSubscriptionRepository.FindOneByID(ctx, webhookRedeliverResource.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	webhookDelivery, err := s.webhookDeliveryRepository.FindOneByID(ctx, webhookRedeliverResource.DeliveryID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if webhookSubscription == nil || webhookDelivery == nil || webhookDelivery.SubscriptionID != webhookSubscription.ID {
+		return nil, apperror.NewModelNotFoundAppError(ctx, nil, WebhookServiceSourceName)
+	}
+
+	if err := s.deliver(ctx, webhookSubscription, webhookDelivery); err != nil {
+		return nil, err
+	}
+
+	if err := s.webhookDeliveryRepository.LoadAttempts(ctx, []*model.WebhookDelivery{webhookDelivery}); err != nil {
+		return nil, err
+	}
+
+	return resource.FromWebhookDelivery(*webhookDelivery), nil
+}
+
+// Enqueue Creates a pending delivery of the event for every enabled subscription interested in it. Only the
+// subscriptions of the tenant of the context are considered, so it must be the tenant of the event.
+func (s *webhookService) Enqueue(ctx *context.RequestContext, envelope *events.Envelope) *apperror.AppError {
+	webhookSubscriptions, err := s.webhookSubscriptionRepository.Find(
+		ctx,
+		utils.NewWebhookSubscriptionFindFilters().WithDisabledValue(false),
+		utils.NewWebhookSubscriptionFindOptions(),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	payload, jsonErr := json.Marshal(envelope)
+
+	if jsonErr != nil {
+		return apperror.NewAppError(ctx, jsonErr, WebhookServiceSourceName, apperror.InternalErrorCode, apperror.InternalErrorMessage, nil)
+	}
+
+	now := s.timeService.GetCurrentUtcTime()
+
+	for _, webhookSubscription := range webhookSubscriptions {
+		if !webhookSubscription.IsSubscribedTo(envelope.Name) {
+			continue
+		}
+
+		webhookDelivery := model.NewWebhookDeliveryBuilder().
+			WithSubscriptionID(webhookSubscription.ID).
+			WithEventID(envelope.ID).
+			WithEventName(envelope.Name).
+			WithPayload(string(payload)).
+			WithStatus(model.WebhookDeliveryStatusPending).
+			WithNextAttemptAt(now).
+			WithCreatedAt(now).
+			WithUpdatedAt(now).
+			Build()
+
+		if _, err := s.webhookDeliveryRepository.Create(ctx, webhookDelivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeliverPending Attempts a batch of pending deliveries whose next attempt is due, and returns how many of them
+// succeeded. Deliveries of every tenant are attempted, whatever the tenant of the context is.
+func (s *webhookService) DeliverPending(ctx *context.RequestContext) (int, *apperror.AppError) {
+	webhookDeliveries, err := s.webhookDeliveryRepository.Find(
+		ctx,
+		utils.NewWebhookDeliveryFindFilters().
+			WithStatusValue(model.WebhookDeliveryStatusPending).
+			WithDueAtValue(s.timeService.GetCurrentUtcTime()),
+		utils.NewWebhookDeliveryFindOptions().WithOffsetValue(0).WithLimitValue(WebhookDeliveryBatchSize),
+	)
+
+	if err != nil {
+		return 0, err
+	}
+
+	succeeded := 0
+	webhookSubscriptions := make(map[int64]*model.WebhookSubscription)
+
+	for _, webhookDelivery := range webhookDeliveries {
+		webhookSubscription, found := webhookSubscriptions[webhookDelivery.SubscriptionID]
+
+		if !found {
+			webhookSubscription, err = s.webhookSubscriptionRepository.FindOneByIDOfAnyTenant(ctx, webhookDelivery.SubscriptionID)
+
+			if err != nil {
+				return succeeded, err
+			}
+
+			webhookSubscriptions[webhookDelivery.SubscriptionID] = webhookSubscription
+		}
+
+		if webhookSubscription == nil || webhookSubscription.Disabled {
+			webhookDelivery.Status = model.WebhookDeliveryStatusFailed
+			webhookDelivery.UpdatedAt = s.timeService.GetCurrentUtcTime()
+
+			if err := s.webhookDeliveryRepository.Update(ctx, webhookDelivery); err != nil {
+				return succeeded, err
+			}
+
+			continue
+		}
+
+		if err := s.deliver(ctx, webhookSubscription, webhookDelivery); err != nil {
+			return succeeded, err
+		}
+
+		if webhookDelivery.Status == model.WebhookDeliveryStatusSucceeded {
+			succeeded++
+		}
+	}
+
+	return succeeded, nil
+}
+
+func (s *webhookService) ValidateEventType(ctx context2.Context, fl validator2.FieldLevel) bool {
+	eventType := fl.Field().String()
+
+	return eventType == model.WebhookAllEventTypes || events.IsRegistered(eventType)
+}
+
+// deliver Sends the delivery payload to the subscription URL, and records the attempt and the resulting status of the
+// delivery.
+func (s *webhookService) deliver(
+	ctx *context.RequestContext,
+	webhookSubscription *model.WebhookSubscription,
+	webhookDelivery *model.WebhookDelivery,
+) *apperror.AppError {
+	startedAt := s.timeService.GetCurrentUtcTime()
+	statusCode, deliveryErr := s.post(webhookSubscription, webhookDelivery)
+	now := s.timeService.GetCurrentUtcTime()
+
+	webhookDelivery.Attempts++
+	webhookDelivery.UpdatedAt = now
+
+	attempt := &model.WebhookDeliveryAttempt{
+		DeliveryID: webhookDelivery.ID,
+		Attempt:    webhookDelivery.Attempts,
+		StatusCode: statusCode,
+		Duration:   now.Sub(startedAt),
+		CreatedAt:  now,
+	}
+
+	switch {
+	case deliveryErr == nil:
+		webhookDelivery.Status = model.WebhookDeliveryStatusSucceeded
+	case webhookDelivery.Attempts >= s.webhookConfig.MaxAttempts:
+		attempt.Error = deliveryErr.Error()
+		webhookDelivery.Status = model.WebhookDeliveryStatusFailed
+	default:
+		attempt.Error = deliveryErr.Error()
+		webhookDelivery.Status = model.WebhookDeliveryStatusPending
+		webhookDelivery.NextAttemptAt = now.Add(s.backoff(webhookDelivery.Attempts))
+	}
+
+	if deliveryErr != nil {
+		s.logger.Warn().Msgf(
+			"[WebhookService] Delivery %d of event %d to '%s' failed on attempt %d: %s",
+			webhookDelivery.ID,
+			webhookDelivery.EventID,
+			webhookSubscription.URL,
+			webhookDelivery.Attempts,
+			deliveryErr,
+		)
+	}
+
+	return s.transactionService.RunInTransaction(ctx, func() *apperror.AppError {
+		if err := s.webhookDeliveryRepository.CreateAttempt(ctx, attempt); err != nil {
+			return err
+		}
+
+		return s.webhookDeliveryRepository.Update(ctx, webhookDelivery)
+	})
+}
+
+func (s *webhookService) post(webhookSubscription *model.WebhookSubscription, webhookDelivery *model.WebhookDelivery) (int, error) {
+	payload := []byte(webhookDelivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, webhookSubscription.URL, bytes.NewReader(payload))
+
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, webhookDelivery.EventName)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(webhookDelivery.ID, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhookSubscription.Secret, payload))
+
+	res, err := s.httpClient.Do(req)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected response status: %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+func (s *webhookService) backoff(attempts int) time.Duration {
+	delay := s.webhookConfig.RetryBaseDelay
+
+	for i := 1; i < attempts && delay < s.webhookConfig.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > s.webhookConfig.RetryMaxDelay {
+		delay = s.webhookConfig.RetryMaxDelay
+	}
+
+	return delay
+}
+
+// Static functions
+
+// SignWebhookPayload Returns the value of the signature header sent with every webhook: the hex encoded HMAC-SHA256 of
+// the request body, using the subscription secret as key.
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+
+	mac.Write(payload)
+
+	return WebhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+func NewWebhookService(
+	appConfig config.AppConfig,
+	webhookConfig WebhookConfig,
+	logger *zerolog.Logger,
+	validator *validator2.Validate,
+	timeService TimeService,
+	transactionService TransactionService,
+	webhookSubscriptionRepository repository.WebhookSubscriptionRepository,
+	webhookDeliveryRepository repository.WebhookDeliveryRepository,
+) WebhookService {
+	return &webhookService{
+		appConfig:                     appConfig,
+		webhookConfig:                 webhookConfig,
+		logger:                        logger,
+		validator:                     validator,
+		timeService:                   timeService,
+		transactionService:            transactionService,
+		webhookSubscriptionRepository: webhookSubscriptionRepository,
+		webhookDeliveryRepository:     webhookDeliveryRepository,
+		httpClient:                    &http.Client{Timeout: webhookConfig.Timeout},
+	}
+}
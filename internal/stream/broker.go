@@ -0,0 +1,170 @@
+package stream
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/comfortablynumb/goginrestapi/internal/resource"
+)
+
+// Constants
+
+const (
+	DefaultReplayBufferSize       = 1000
+	DefaultSubscriptionBufferSize = 100
+)
+
+// Variables
+
+var (
+	ErrBrokerClosed = errors.New("change stream broker is closed")
+)
+
+// Types
+
+// Filter Returns true if the notification must be sent to the subscription.
+type Filter func(notification *resource.ChangeNotificationResource) bool
+
+// Structs
+
+// Subscription Notifications are sent through a buffered channel. If a subscriber can't keep up and its buffer gets
+// full, the subscription is closed: the client is expected to reconnect sending the ID of the last notification it
+// received, so the missed ones are replayed.
+type Subscription struct {
+	filter        Filter
+	notifications chan *resource.ChangeNotificationResource
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+func (s *Subscription) Notifications() <-chan *resource.ChangeNotificationResource {
+	return s.notifications
+}
+
+// Done Is closed when the subscription is closed by the broker.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *Subscription) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+// Broker Fans out change notifications to the subscriptions, keeping the latest ones on a bounded buffer so clients
+// can resume a stream after a reconnection.
+type Broker struct {
+	mutex                  sync.Mutex
+	replayBufferSize       int
+	subscriptionBufferSize int
+	buffer                 []*resource.ChangeNotificationResource
+	lastID                 int64
+	subscriptions          map[*Subscription]struct{}
+	closed                 bool
+}
+
+// Publish Sends the notification to every interested subscription. Notifications come from the outbox, which delivers
+// them at-least-once and in order, so the ones with an ID lower or equal than the last published one are discarded.
+func (b *Broker) Publish(notification *resource.ChangeNotificationResource) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed || notification.ID <= b.lastID {
+		return
+	}
+
+	b.lastID = notification.ID
+	b.buffer = append(b.buffer, notification)
+
+	if len(b.buffer) > b.replayBufferSize {
+		b.buffer = b.buffer[len(b.buffer)-b.replayBufferSize:]
+	}
+
+	for subscription := range b.subscriptions {
+		if !subscription.filter(notification) {
+			continue
+		}
+
+		select {
+		case subscription.notifications <- notification:
+		default:
+			delete(b.subscriptions, subscription)
+
+			subscription.close()
+		}
+	}
+}
+
+// Subscribe Creates a new subscription. It also returns the buffered notifications published after lastID which pass
+// the filter. Use 0 as lastID to skip the replay.
+func (b *Broker) Subscribe(filter Filter, lastID int64) (*Subscription, []*resource.ChangeNotificationResource, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return nil, nil, ErrBrokerClosed
+	}
+
+	replay := make([]*resource.ChangeNotificationResource, 0)
+
+	if lastID > 0 {
+		for _, notification := range b.buffer {
+			if notification.ID > lastID && filter(notification) {
+				replay = append(replay, notification)
+			}
+		}
+	}
+
+	subscription := &Subscription{
+		filter:        filter,
+		notifications: make(chan *resource.ChangeNotificationResource, b.subscriptionBufferSize),
+		done:          make(chan struct{}),
+	}
+
+	b.subscriptions[subscription] = struct{}{}
+
+	return subscription, replay, nil
+}
+
+func (b *Broker) Unsubscribe(subscription *Subscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	delete(b.subscriptions, subscription)
+
+	subscription.close()
+}
+
+// Close Closes every subscription and rejects new ones.
+func (b *Broker) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.closed = true
+
+	for subscription := range b.subscriptions {
+		subscription.close()
+	}
+
+	b.subscriptions = make(map[*Subscription]struct{})
+}
+
+// Static functions
+
+func NewBroker(replayBufferSize int, subscriptionBufferSize int) *Broker {
+	if replayBufferSize < 1 {
+		replayBufferSize = DefaultReplayBufferSize
+	}
+
+	if subscriptionBufferSize < 1 {
+		subscriptionBufferSize = DefaultSubscriptionBufferSize
+	}
+
+	return &Broker{
+		replayBufferSize:       replayBufferSize,
+		subscriptionBufferSize: subscriptionBufferSize,
+		buffer:                 make([]*resource.ChangeNotificationResource, 0),
+		subscriptions:          make(map[*Subscription]struct{}),
+	}
+}
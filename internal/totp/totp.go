@@ -0,0 +1,124 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Constants
+
+// TOTP (RFC 6238) with the parameters every authenticator app supports.
+const (
+	Algorithm  = "SHA1"
+	Digits     = 6
+	Period     = 30 * time.Second
+	SecretSize = 20
+)
+
+// Variables
+
+var (
+	encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+// Static functions
+
+// NewSecret Returns a random secret, encoded in base32 as authenticator apps expect it.
+func NewSecret() (string, error) {
+	secret := make([]byte, SecretSize)
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return encoding.EncodeToString(secret), nil
+}
+
+// NewURI Returns the otpauth:// URI of the secret, usually shown as a QR code so authenticator apps can add it.
+func NewURI(issuer string, accountName string, secret string) string {
+	params := url.Values{}
+
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", Algorithm)
+	params.Set("digits", fmt.Sprintf("%d", Digits))
+	params.Set("period", fmt.Sprintf("%d", int(Period/time.Second)))
+
+	label := url.PathEscape(issuer + ":" + accountName)
+
+	// Spaces are encoded as %20, as some apps show "+" literally
+
+	return "otpauth://totp/" + label + "?" + strings.ReplaceAll(params.Encode(), "+", "%20")
+}
+
+// GetStep Returns the time step of the given time.
+func GetStep(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// GenerateCode Returns the code of the secret at the given time.
+func GenerateCode(secret string, t time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+
+	if err != nil {
+		return "", err
+	}
+
+	return generateCode(key, GetStep(t)), nil
+}
+
+// Validate Returns the time step the code belongs to, if it's valid at the given time. Codes of up to skew steps
+// before or after it are accepted too, to allow for clock drift. Callers must reject steps already used, so each code
+// can be used once.
+func Validate(secret string, code string, t time.Time, skew int) (int64, bool) {
+	key, err := decodeSecret(secret)
+
+	if err != nil || len(code) != Digits {
+		return 0, false
+	}
+
+	step := GetStep(t)
+
+	for offset := -int64(skew); offset <= int64(skew); offset++ {
+		if subtle.ConstantTimeCompare([]byte(generateCode(key, step+offset)), []byte(code)) == 1 {
+			return step + offset, true
+		}
+	}
+
+	return 0, false
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	return encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+}
+
+// generateCode HOTP (RFC 4226) of the step.
+func generateCode(key []byte, step int64) string {
+	counter := make([]byte, 8)
+
+	binary.BigEndian.PutUint64(counter, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+
+	mac.Write(counter)
+
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+
+	for i := 0; i < Digits; i++ {
+		modulo *= 10
+	}
+
+	return fmt.Sprintf("%0*d", Digits, value%modulo)
+}
@@ -0,0 +1,33 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Static functions
+
+// GetFieldName Returns the name of a field as seen by API clients, to be registered as the tag name function of the
+// validators. It's the name on its json tag or, for fields not read from the body, the one on its uri or form tag.
+// The name of the field is used if it has none of them.
+func GetFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "uri", "form"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+
+	return field.Name
+}
+
+// GetFieldPath Returns the path of a field from the namespace of a validation error (like "UserCreateResource.name"),
+// which starts with the name of the validated struct.
+func GetFieldPath(namespace string) string {
+	if idx := strings.Index(namespace, "."); idx != -1 {
+		return namespace[idx+1:]
+	}
+
+	return namespace
+}
@@ -0,0 +1,193 @@
+package worker
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog"
+)
+
+// Constants
+
+const (
+	FileWatcherDebounce = 100 * time.Millisecond
+)
+
+// Structs
+
+// FileWatcherWorker Executes a function every time any of the files changes, or the process receives any of the
+// signals. Directories are watched instead of the files, so files replaced by editors are detected too. Files which
+// are symlinks are resolved again on every change of their directory, so swapping the target of a link on it (like a
+// Kubernetes ConfigMap update does with its "..data" link) is detected as well. Bursts of changes trigger a single
+// execution.
+type FileWatcherWorker struct {
+	name    string
+	files   []string
+	signals []os.Signal
+	fn      func() error
+	logger  *zerolog.Logger
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func (w *FileWatcherWorker) Start() {
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+
+	watcher, events := w.watch()
+	signals := make(chan os.Signal, 1)
+
+	if len(w.signals) > 0 {
+		signal.Notify(signals, w.signals...)
+	}
+
+	go func() {
+		defer close(w.done)
+		defer signal.Stop(signals)
+
+		if watcher != nil {
+			defer watcher.Close()
+		}
+
+		debounce := time.NewTimer(FileWatcherDebounce)
+
+		debounce.Stop()
+
+		for {
+			select {
+			case <-w.stop:
+				debounce.Stop()
+
+				return
+			case sig := <-signals:
+				w.logger.Info().Msgf("[%s] Received signal '%s'.", w.name, sig)
+
+				w.execute()
+			case <-events:
+				debounce.Reset(FileWatcherDebounce)
+			case <-debounce.C:
+				w.execute()
+			}
+		}
+	}()
+}
+
+// Stop Stops the worker, waiting for the current execution (if any) to finish.
+func (w *FileWatcherWorker) Stop() {
+	if w.stop == nil {
+		return
+	}
+
+	close(w.stop)
+
+	<-w.done
+
+	w.stop = nil
+}
+
+func (w *FileWatcherWorker) execute() {
+	if err := w.fn(); err != nil {
+		w.logger.Error().Msgf("[%s] %s", w.name, err)
+	}
+}
+
+// watch Returns the channel which receives the changes of the files. If they can't be watched, the worker only reacts
+// to signals.
+func (w *FileWatcherWorker) watch() (*fsnotify.Watcher, <-chan struct{}) {
+	events := make(chan struct{}, 1)
+
+	if len(w.files) < 1 {
+		return nil, events
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+
+	if err != nil {
+		w.logger.Error().Msgf("[%s] Could NOT watch the files: %s", w.name, err)
+
+		return nil, events
+	}
+
+	// targets Path every file resolves to, once its symlinks are followed.
+
+	targets := make(map[string]string)
+
+	for _, file := range w.files {
+		file = filepath.Clean(file)
+		targets[file] = resolveSymlinks(file)
+
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			w.logger.Error().Msgf("[%s] Could NOT watch file '%s': %s", w.name, file, err)
+		}
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				_, changed := targets[filepath.Clean(event.Name)]
+				changed = changed && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0
+
+				for file, target := range targets {
+					if newTarget := resolveSymlinks(file); newTarget != target {
+						targets[file] = newTarget
+						changed = true
+					}
+				}
+
+				if !changed {
+					continue
+				}
+
+				select {
+				case events <- struct{}{}:
+				default:
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				w.logger.Error().Msgf("[%s] Error while watching the files: %s", w.name, err)
+			}
+		}
+	}()
+
+	return watcher, events
+}
+
+// Static functions
+
+func NewFileWatcherWorker(
+	name string,
+	files []string,
+	signals []os.Signal,
+	fn func() error,
+	logger *zerolog.Logger,
+) *FileWatcherWorker {
+	return &FileWatcherWorker{
+		name:    name,
+		files:   files,
+		signals: signals,
+		fn:      fn,
+		logger:  logger,
+	}
+}
+
+// resolveSymlinks Returns an empty string if the file can't be resolved (for example, while it's being replaced).
+func resolveSymlinks(file string) string {
+	res, err := filepath.EvalSymlinks(file)
+
+	if err != nil {
+		return ""
+	}
+
+	return res
+}
@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Interfaces
+
+// Worker Background process started by the app once it's set up, and stopped when the app shuts down.
+type Worker interface {
+	Start()
+	Stop()
+}
+
+// Structs
+
+// IntervalWorker Executes a function every interval until it's stopped.
+type IntervalWorker struct {
+	name     string
+	interval time.Duration
+	fn       func() error
+	logger   *zerolog.Logger
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func (w *IntervalWorker) Start() {
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+
+		ticker := time.NewTicker(w.interval)
+
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				if err := w.fn(); err != nil {
+					w.logger.Error().Msgf("[%s] %s", w.name, err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop Stops the worker, waiting for the current execution (if any) to finish.
+func (w *IntervalWorker) Stop() {
+	if w.stop == nil {
+		return
+	}
+
+	close(w.stop)
+
+	<-w.done
+
+	w.stop = nil
+}
+
+// Static functions
+
+func NewIntervalWorker(name string, interval time.Duration, fn func() error, logger *zerolog.Logger) *IntervalWorker {
+	return &IntervalWorker{
+		name:     name,
+		interval: interval,
+		fn:       fn,
+		logger:   logger,
+	}
+}
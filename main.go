@@ -1,9 +1,7 @@
 package main
 
 import (
-	"log"
-
-	app "github.com/comfortablynumb/goginrestapi/internal/app"
+	"github.com/comfortablynumb/goginrestapi/pkg/framework"
 )
 
 // @title Users REST API
@@ -17,15 +15,5 @@ import (
 // @host localhost:8080
 // @BasePath /
 func main() {
-	application, err := app.NewAppFromEnv()
-
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = application.Run()
-
-	if err != nil {
-		log.Fatal(err)
-	}
+	framework.Main()
 }
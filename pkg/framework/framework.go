@@ -0,0 +1,139 @@
+// Package framework Public API to build applications on top of this one. Downstream projects import it to add their
+// own modules and hooks to the built-in ones, and build their own binary with the same subcommands:
+//
+//	func main() {
+//		framework.Main(
+//			framework.WithModules(&mymodule.Module{}),
+//			framework.WithRouterHook(framework.RouterHookFunc(func(router *gin.Engine) *gin.Engine {
+//				router.Use(myMiddleware)
+//
+//				return router
+//			})),
+//		)
+//	}
+//
+// The types are aliases of the internal ones, so modules written against this package are the same as the built-in
+// ones.
+package framework
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/comfortablynumb/goginrestapi/internal/app"
+	"github.com/comfortablynumb/goginrestapi/internal/apperror"
+	"github.com/comfortablynumb/goginrestapi/internal/cli"
+	"github.com/comfortablynumb/goginrestapi/internal/componentregistry"
+	"github.com/comfortablynumb/goginrestapi/internal/config"
+	"github.com/comfortablynumb/goginrestapi/internal/context"
+	"github.com/comfortablynumb/goginrestapi/internal/errorhandler"
+	"github.com/comfortablynumb/goginrestapi/internal/errorreport"
+	"github.com/comfortablynumb/goginrestapi/internal/events"
+	"github.com/comfortablynumb/goginrestapi/internal/hooks"
+	"github.com/comfortablynumb/goginrestapi/internal/i18n"
+	"github.com/comfortablynumb/goginrestapi/internal/jobs"
+	"github.com/comfortablynumb/goginrestapi/internal/mailer"
+	"github.com/comfortablynumb/goginrestapi/internal/model"
+	"github.com/comfortablynumb/goginrestapi/internal/module"
+	"github.com/comfortablynumb/goginrestapi/internal/service"
+	"github.com/comfortablynumb/goginrestapi/internal/worker"
+)
+
+// Interfaces
+
+type (
+	App                   = app.App
+	Module                = module.Module
+	ConfigurableModule    = module.ConfigurableModule
+	RouterHook            = hooks.RouterHook
+	ComponentRegistryHook = hooks.ComponentRegistryHook
+	ValidatorHook         = hooks.ValidatorHook
+	LoggerHook            = hooks.LoggerHook
+	Event                 = events.Event
+	Worker                = worker.Worker
+	ErrorReportSink       = errorreport.Sink
+	SentryClient          = errorreport.SentryClient
+	Mailer                = mailer.Mailer
+	JobSchedule           = jobs.Schedule
+	TenantService         = service.TenantService
+)
+
+// Structs
+
+type (
+	Option                    = app.Option
+	BaseModule                = module.BaseModule
+	AppConfig                 = config.AppConfig
+	ConfigLoader              = config.Loader
+	ComponentRegistry         = componentregistry.ComponentRegistry
+	ErrorHandler              = errorhandler.ErrorHandler
+	RequestContext            = context.RequestContext
+	RequestContextFactory     = context.RequestContextFactory
+	AppError                  = apperror.AppError
+	HttpError                 = apperror.HttpError
+	EventBus                  = events.Bus
+	EventEnvelope             = events.Envelope
+	RouterHookFunc            = hooks.RouterHookFunc
+	ComponentRegistryHookFunc = hooks.ComponentRegistryHookFunc
+	ValidatorHookFunc         = hooks.ValidatorHookFunc
+	LoggerHookFunc            = hooks.LoggerHookFunc
+	CLI                       = cli.CLI
+	Translator                = i18n.Translator
+	ErrorReporter             = errorreport.Reporter
+	ErrorReport               = errorreport.Report
+	MailMessage               = mailer.Message
+	JobScheduler              = jobs.Scheduler
+	JobDefinition             = jobs.Definition
+	JobHandler                = jobs.Handler
+	Job                       = model.Job
+	Tenant                    = model.Tenant
+)
+
+// Constants
+
+const (
+	EnglishLocale = i18n.EnglishLocale
+	SpanishLocale = i18n.SpanishLocale
+)
+
+// Variables
+
+var (
+	NewApp                    = app.NewApp
+	WithModules               = app.WithModules
+	WithRouterHook            = app.WithRouterHook
+	WithComponentRegistryHook = app.WithComponentRegistryHook
+	WithValidatorHook         = app.WithValidatorHook
+	WithLoggerHook            = app.WithLoggerHook
+	NewConfigLoader           = config.NewLoader
+	NewCLI                    = cli.NewCLI
+	NewIntervalWorker         = worker.NewIntervalWorker
+	NewFileErrorReportSink    = errorreport.NewFileSink
+	NewSentryErrorReportSink  = errorreport.NewSentrySink
+	NewOutboxMailer           = mailer.NewOutboxMailer
+	ErrUsage                  = cli.ErrUsage
+	Every                     = jobs.Every
+	ParseJobSchedule          = jobs.ParseSchedule
+	UnmarshalJobPayload       = jobs.UnmarshalPayload
+)
+
+// Static functions
+
+// Get Returns the component registered with the given name, which must be of type T.
+func Get[T any](componentRegistry *ComponentRegistry, name string) (T, error) {
+	return componentregistry.Get[T](componentRegistry, name)
+}
+
+// Main Runs the CLI with the arguments of the process, creating the apps with the given options, and exits with a non
+// zero code if it fails. Meant to be the only call of the main function of the binary.
+func Main(options ...Option) {
+	err := NewCLI(NewConfigLoader, os.Stdout, options...).Run(os.Args[1:])
+
+	if err != nil {
+		if err != ErrUsage {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		}
+
+		os.Exit(1)
+	}
+}